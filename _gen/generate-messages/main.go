@@ -102,9 +102,9 @@ func genMessageBuilder(msg *datadictionary.MessageDef, requiredFields []*datadic
 			fileOut += fmt.Sprintf("builder.Header().Set(field.NewBeginString(fix.BeginString_FIXT11))\n")
 			switch fixSpec.ServicePack {
 			case 0:
-				fileOut += fmt.Sprintf("builder.Header().Set(field.NewDefaultApplVerID(enum.ApplVerID_FIX50))\n")
+				fileOut += fmt.Sprintf("builder.Header().Set(field.NewApplVerID(enum.ApplVerID_FIX50))\n")
 			default:
-				fileOut += fmt.Sprintf("builder.Header().Set(field.NewDefaultApplVerID(enum.ApplVerID_FIX50SP%v))\n", fixSpec.ServicePack)
+				fileOut += fmt.Sprintf("builder.Header().Set(field.NewApplVerID(enum.ApplVerID_FIX50SP%v))\n", fixSpec.ServicePack)
 			}
 		} else {
 			fileOut += fmt.Sprintf("builder.Header().Set(field.NewBeginString(fix.BeginString_FIX%v%v))\n", fixSpec.Major, fixSpec.Minor)
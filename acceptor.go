@@ -1,13 +1,22 @@
 package quickfix
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/quickfixgo/quickfix/config"
 	"net"
 	"strconv"
+	"sync"
 )
 
+//listenerSettingNames are the settings configuring the listener of an address.
+var listenerSettingNames = append([]string{config.SocketProxyProtocol}, tlsSettings...)
+
 //Acceptor accepts connections from FIX clients and manages the associated sessions.
+//Sessions are accepted on the SocketAcceptHost and SocketAcceptPort of their settings, a listener is opened for each distinct address.
+//A SocketAcceptHost of unix:<path> accepts sessions on the unix domain socket at path.
+//Sessions configured with AcceptorTemplate=Y are templates, creating a session for each counterparty logging on with CompIDs matching the template.
 type Acceptor struct {
 	app                 Application
 	settings            *Settings
@@ -15,33 +24,234 @@ type Acceptor struct {
 	storeFactory        MessageStoreFactory
 	globalLog           Log
 	qualifiedSessionIDs map[SessionID]SessionID
+	sessionAddresses    map[SessionID]string
+	sessionLock         sync.RWMutex
+	listeners           map[string]net.Listener
+	connections         connectionSet
+	templates           []acceptorTemplate
+	templateSessions    map[SessionID]bool
+
+	//listenerSettings are the settings of the first session accepted on each address, configuring TLS and the PROXY protocol for all sessions on the address
+	listenerSettings map[string]*SessionSettings
+
+	//listenerFactory opens the listeners, nil to listen on the network of the host
+	listenerFactory ListenerFactory
+
+	//listenerTLSConfigs secure the connections accepted on each address listened on with TLS, replaced by ReloadSettings
+	listenerTLSConfigs map[string]*tls.Config
+
+	//addressFilter permits connections by the address of the counterparty, sessionAddressFilters permit the logons of each session
+	addressFilter         *addressFilter
+	sessionAddressFilters map[SessionID]*addressFilter
 }
 
 //Start accepting connections.
-func (a *Acceptor) Start() (e error) {
-	port, err := a.settings.GlobalSettings().IntSetting(config.SocketAcceptPort)
+func (a *Acceptor) Start() error {
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
+
+	addresses := make(map[string]bool)
+	for _, address := range a.sessionAddresses {
+		addresses[address] = true
+	}
+	for _, template := range a.templates {
+		addresses[template.address] = true
+	}
+
+	//without sessions, listen for sessions added later
+	if len(addresses) == 0 {
+		address, err := acceptAddress(a.settings.GlobalSettings())
+		if err != nil {
+			return err
+		}
+		addresses[address] = true
+	}
+
+	a.connections.open()
+	a.listeners = make(map[string]net.Listener)
+	for address := range addresses {
+		if err := a.listen(address); err != nil {
+			a.closeListeners()
+			return err
+		}
+	}
+
+	return nil
+}
+
+//listen opens a listener accepting connections for the sessions on address, secured by TLS if configured for the sessions.
+//With SocketProxyProtocol=Optional or Required, connections from a load balancer start with a PROXY protocol header declaring
+//the address of the client, the remote address logged and passed to the Application from then on.
+func (a *Acceptor) listen(address string) error {
+	settings, ok := a.listenerSettings[address]
+	if !ok {
+		settings = a.settings.GlobalSettings()
+	}
+
+	tlsConfig, err := loadTLSConfig(settings)
 	if err != nil {
-		return fmt.Errorf("error fetching required SocketAcceptPort: %v", err)
+		return err
+	}
+
+	if tlsConfig != nil && len(tlsConfig.Certificates) == 0 {
+		return fmt.Errorf("TLS on %v requires %v", address, config.SocketCertificateFile)
 	}
 
-	server, err := net.Listen("tcp", ":"+strconv.Itoa(port))
-	if server == nil {
+	proxyProtocol := proxyProtocolOff
+	if settings.HasSetting(config.SocketProxyProtocol) {
+		setting, _ := settings.Setting(config.SocketProxyProtocol)
+		if proxyProtocol, err = parseProxyProtocolPolicy(setting); err != nil {
+			return err
+		}
+	}
+
+	listen := net.Listen
+	if a.listenerFactory != nil {
+		listen = a.listenerFactory
+	}
+
+	listener, err := listen(transportAddress(address))
+	if err != nil {
 		return err
 	}
 
-	connections := a.listenForConnections(server)
-	go func() {
-		for {
-			cxn := <-connections
-			go handleAcceptorConnection(cxn, a.qualifiedSessionIDs, a.globalLog)
+	if proxyProtocol != proxyProtocolOff {
+		listener = &proxyListener{Listener: listener, policy: proxyProtocol, log: a.globalLog}
+	}
+
+	if tlsConfig != nil {
+		a.listenerTLSConfigs[address] = tlsConfig
+		listener = tls.NewListener(listener, &tls.Config{GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return a.listenerTLSConfig(address), nil
+		}})
+	}
+
+	a.listeners[address] = listener
+	go a.acceptConnections(listener, address)
+	return nil
+}
+
+//listenerTLSConfig returns the TLS configuration of the connections accepted on address.
+func (a *Acceptor) listenerTLSConfig(address string) *tls.Config {
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+
+	return a.listenerTLSConfigs[address]
+}
+
+//closeListeners stops accepting new connections.
+func (a *Acceptor) closeListeners() {
+	for _, listener := range a.listeners {
+		listener.Close()
+	}
+	a.listeners = nil
+}
+
+//isListening returns false once listener is closed.
+func (a *Acceptor) isListening(address string, listener net.Listener) bool {
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+
+	return a.listeners[address] == listener
+}
+
+//acceptConnections handles the connections accepted by listener for the sessions on address.
+func (a *Acceptor) acceptConnections(listener net.Listener, address string) {
+	qualifiedSessionID := func(sessionID SessionID, logon Message, netConn net.Conn) (SessionID, bool) {
+		if err := a.authenticatePeerCertificate(sessionID, logon, netConn); err != nil {
+			logEventf(a.globalLog, LogLevelWarn, LogCategoryTransport, "Logon of %v from %v refused: %v", sessionID, netConn.RemoteAddr(), err)
+			return SessionID{}, false
 		}
-	}()
 
-	return
+		qualifiedSessionID, ok := a.resolveSession(address, sessionID, logon, netConn.RemoteAddr())
+		if !ok {
+			return qualifiedSessionID, false
+		}
+
+		if err := a.checkSessionAddress(qualifiedSessionID, netConn.RemoteAddr()); err != nil {
+			logEventf(a.globalLog, LogLevelWarn, LogCategoryTransport, "Logon of %v from %v refused: %v", sessionID, netConn.RemoteAddr(), err)
+			return qualifiedSessionID, false
+		}
+
+		return qualifiedSessionID, true
+	}
+
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			if !a.isListening(address, listener) {
+				return
+			}
+
+			a.globalLog.OnEventf("Couldn't Accept: %v", err.Error())
+			continue
+		}
+
+		if !a.connections.add(netConn) {
+			netConn.Close()
+			continue
+		}
+
+		go func() {
+			end := &connEnd{}
+			end.add(func() { a.connections.remove(netConn) })
+
+			//refused before the logon is read
+			if err := a.checkAddress(netConn.RemoteAddr()); err != nil {
+				logEventf(a.globalLog, LogLevelWarn, LogCategoryTransport, "Connection from %v refused: %v", netConn.RemoteAddr(), err)
+				netConn.Close()
+				end.run()
+				return
+			}
+
+			handleAcceptorConnection(netConn, qualifiedSessionID, a.globalLog, end)
+		}()
+	}
+}
+
+//authenticatePeerCertificate approves the certificates presented on a TLS connection with the PeerCertificateAuthenticator of the Application,
+//connections are approved if not implemented or not secured by TLS.
+func (a *Acceptor) authenticatePeerCertificate(sessionID SessionID, logon Message, netConn net.Conn) error {
+	authenticator, ok := a.app.(PeerCertificateAuthenticator)
+	if !ok {
+		return nil
+	}
+
+	tlsConn, ok := netConn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+
+	return authenticator.AuthenticatePeerCertificate(sessionID, logon, tlsConn.ConnectionState().PeerCertificates)
 }
 
 //Stop logs out existing sessions, close their connections, and stop accepting new connections.
-func (a *Acceptor) Stop() {}
+func (a *Acceptor) Stop() {
+	a.Shutdown(context.Background())
+}
+
+//Shutdown stops accepting new connections.
+//Connected sessions send messages delayed by the throttle, then Logout, and are disconnected once the counterparty replies or the logout times out.
+//If ctx is done first, the remaining connections are closed and the error of ctx returned.
+func (a *Acceptor) Shutdown(ctx context.Context) error {
+	a.connections.close()
+
+	a.sessionLock.Lock()
+	a.closeListeners()
+	for _, sessionID := range a.qualifiedSessionIDs {
+		if session, err := LookupSession(sessionID); err == nil {
+			session.requestShutdown()
+		}
+	}
+	a.sessionLock.Unlock()
+
+	err := a.connections.wait(ctx)
+	if flushable, ok := a.logFactory.(FlushableLogFactory); ok {
+		flushable.Flush()
+	}
+
+	return err
+}
 
 //NewAcceptor creates and initializes a new Acceptor.
 func NewAcceptor(app Application, storeFactory MessageStoreFactory, settings *Settings, logFactory LogFactory) (*Acceptor, error) {
@@ -51,6 +261,11 @@ func NewAcceptor(app Application, storeFactory MessageStoreFactory, settings *Se
 	a.settings = settings
 	a.logFactory = logFactory
 	a.qualifiedSessionIDs = make(map[SessionID]SessionID)
+	a.sessionAddresses = make(map[SessionID]string)
+	a.templateSessions = make(map[SessionID]bool)
+	a.listenerSettings = make(map[string]*SessionSettings)
+	a.sessionAddressFilters = make(map[SessionID]*addressFilter)
+	a.listenerTLSConfigs = make(map[string]*tls.Config)
 
 	var err error
 	a.globalLog, err = logFactory.Create()
@@ -58,19 +273,23 @@ func NewAcceptor(app Application, storeFactory MessageStoreFactory, settings *Se
 		return a, err
 	}
 
-	for sessionID, sessionSettings := range settings.SessionSettings() {
-		//unqualified sessionIDs must be unique
-		unqualifiedSessionID := SessionID{
-			BeginString:  sessionID.BeginString,
-			TargetCompID: sessionID.TargetCompID,
-			SenderCompID: sessionID.SenderCompID}
+	if a.addressFilter, err = addressFilterFromSettings(settings.GlobalSettings()); err != nil {
+		return nil, err
+	}
 
-		if _, dup := a.qualifiedSessionIDs[unqualifiedSessionID]; dup {
-			return nil, fmt.Errorf("duplicate SessionID %v", unqualifiedSessionID)
+	for sessionID := range settings.SessionSettings() {
+		isTemplate, err := a.isAcceptorTemplate(sessionID)
+		if err != nil {
+			return nil, err
 		}
-		a.qualifiedSessionIDs[unqualifiedSessionID] = sessionID
 
-		if err = createSession(sessionID, storeFactory, sessionSettings, logFactory, app); err != nil {
+		if isTemplate {
+			err = a.addTemplate(sessionID)
+		} else {
+			err = a.createSession(sessionID)
+		}
+
+		if err != nil {
 			return nil, err
 		}
 	}
@@ -78,20 +297,139 @@ func NewAcceptor(app Application, storeFactory MessageStoreFactory, settings *Se
 	return a, nil
 }
 
-func (a *Acceptor) listenForConnections(listener net.Listener) (ch chan net.Conn) {
-	ch = make(chan net.Conn)
+//AddSession creates a session from sessionSettings, overlaying the global settings of the Acceptor, and accepts connections for it.
+//Sessions may be added while the Acceptor is running.
+func (a *Acceptor) AddSession(sessionSettings *SessionSettings) (SessionID, error) {
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
 
-	go func() {
-		for {
-			netConn, err := listener.Accept()
-			if netConn == nil {
-				a.globalLog.OnEventf("Couldn't Accept: %v", err.Error())
-				continue
-			}
+	sessionID, err := a.settings.AddSession(sessionSettings)
+	if err != nil {
+		return sessionID, err
+	}
+
+	if err := a.createSession(sessionID); err != nil {
+		a.settings.RemoveSession(sessionID)
+		return sessionID, err
+	}
+
+	return sessionID, nil
+}
+
+//RemoveSession stops accepting connections for the session, logging it out if connected.
+//Sessions may be removed while the Acceptor is running.
+func (a *Acceptor) RemoveSession(sessionID SessionID) error {
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
+
+	if err := a.settings.RemoveSession(sessionID); err != nil {
+		return err
+	}
+
+	return a.removeSession(sessionID)
+}
+
+//removeSession stops accepting connections for the session removed from the settings, logging it out if connected.
+func (a *Acceptor) removeSession(sessionID SessionID) error {
+	delete(a.qualifiedSessionIDs, unqualified(sessionID))
+	delete(a.sessionAddresses, sessionID)
+	delete(a.templateSessions, sessionID)
+	delete(a.sessionAddressFilters, sessionID)
+
+	return unregisterSession(sessionID)
+}
+
+//createSession creates the session for sessionID from the Acceptor settings.
+//If the Acceptor is running and no listener is open on the address of the session, one is opened.
+func (a *Acceptor) createSession(sessionID SessionID) error {
+	//unqualified sessionIDs must be unique, the qualifier is not sent on logon
+	unqualifiedSessionID := unqualified(sessionID)
+	if _, dup := a.qualifiedSessionIDs[unqualifiedSessionID]; dup {
+		return fmt.Errorf("duplicate SessionID %v, SessionQualifier does not distinguish accepted sessions", unqualifiedSessionID)
+	}
+
+	sessionSettings := a.settings.sessionSettingsFor(sessionID)
+	address, err := acceptAddress(sessionSettings)
+	if err != nil {
+		return err
+	}
 
-			ch <- netConn
+	if err := a.addListenerSettings(address, sessionSettings); err != nil {
+		return err
+	}
+
+	//the filter of the session itself, the filter of the global settings applies to all connections
+	filter, err := addressFilterFromSettings(a.settings.sessionSettings[sessionID])
+	if err != nil {
+		return err
+	}
+
+	if _, listening := a.listeners[address]; a.listeners != nil && !listening {
+		if err := a.listen(address); err != nil {
+			return err
 		}
-	}()
+	}
+
+	if err := createSession(sessionID, a.storeFactory, sessionSettings, a.logFactory, a.app); err != nil {
+		return err
+	}
+
+	a.qualifiedSessionIDs[unqualifiedSessionID] = sessionID
+	a.sessionAddresses[sessionID] = address
+	if filter != nil {
+		a.sessionAddressFilters[sessionID] = filter
+	}
+	return nil
+}
+
+//addListenerSettings records the listener settings of a session accepted on address, which must match those of the other sessions on the address.
+func (a *Acceptor) addListenerSettings(address string, settings *SessionSettings) error {
+	listenerSettings, ok := a.listenerSettings[address]
+	if !ok {
+		a.listenerSettings[address] = settings
+		return nil
+	}
+
+	if settingsKey(listenerSettings, listenerSettingNames) != settingsKey(settings, listenerSettingNames) {
+		return fmt.Errorf("sessions accepted on %v must share the same TLS and PROXY protocol settings", address)
+	}
+
+	return nil
+}
+
+//acceptAddress returns the address sessions with settings are accepted on, the SocketAcceptHost itself for a unix domain socket.
+func acceptAddress(settings *SessionSettings) (string, error) {
+	if host, _ := settings.Setting(config.SocketAcceptHost); isUnixSocket(host) {
+		return host, nil
+	}
+
+	port, err := settings.IntSetting(config.SocketAcceptPort)
+	if err != nil {
+		return "", fmt.Errorf("error fetching required SocketAcceptPort: %v", err)
+	}
+
+	host, _ := settings.Setting(config.SocketAcceptHost)
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+//qualifiedSessionID returns the configured SessionID for the unqualified sessionID of an incoming connection.
+func (a *Acceptor) qualifiedSessionID(sessionID SessionID) (SessionID, bool) {
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+
+	qualifiedSessionID, ok := a.qualifiedSessionIDs[sessionID]
+	return qualifiedSessionID, ok
+}
+
+//qualifiedSessionIDOn is qualifiedSessionID for a connection accepted on address, the session must be accepted on address.
+func (a *Acceptor) qualifiedSessionIDOn(address string, sessionID SessionID) (SessionID, bool) {
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+
+	qualifiedSessionID, ok := a.qualifiedSessionIDs[sessionID]
+	if !ok || a.sessionAddresses[qualifiedSessionID] != address {
+		return qualifiedSessionID, false
+	}
 
-	return ch
+	return qualifiedSessionID, true
 }
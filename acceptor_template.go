@@ -0,0 +1,144 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"path"
+)
+
+//acceptorTemplate creates sessions for logons from counterparties not configured on the Acceptor.
+//The SenderCompID and TargetCompID of a template are patterns, as matched by path.Match, for example TargetCompID=CLIENT_*.
+type acceptorTemplate struct {
+	sessionID SessionID
+	address   string
+}
+
+//newAcceptorTemplate returns the template configured for sessionID, accepting logons on address.
+func newAcceptorTemplate(sessionID SessionID, address string) (acceptorTemplate, error) {
+	for _, pattern := range []string{sessionID.SenderCompID, sessionID.TargetCompID} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return acceptorTemplate{}, fmt.Errorf("invalid pattern %v in template %v: %v", pattern, sessionID, err)
+		}
+	}
+
+	return acceptorTemplate{sessionID: sessionID, address: address}, nil
+}
+
+//matches returns true if the template creates the session for the unqualified sessionID of a logon accepted on address.
+func (t acceptorTemplate) matches(address string, sessionID SessionID) bool {
+	if t.address != address || t.sessionID.BeginString != sessionID.BeginString {
+		return false
+	}
+
+	senderMatch, _ := path.Match(t.sessionID.SenderCompID, sessionID.SenderCompID)
+	targetMatch, _ := path.Match(t.sessionID.TargetCompID, sessionID.TargetCompID)
+	return senderMatch && targetMatch
+}
+
+//isAcceptorTemplate returns true if the session configured for sessionID is a template.
+func (a *Acceptor) isAcceptorTemplate(sessionID SessionID) (bool, error) {
+	settings := a.settings.sessionSettingsFor(sessionID)
+	if !settings.HasSetting(config.AcceptorTemplate) {
+		return false, nil
+	}
+
+	return settings.BoolSetting(config.AcceptorTemplate)
+}
+
+//addTemplate adds the template configured for sessionID.
+func (a *Acceptor) addTemplate(sessionID SessionID) error {
+	address, err := acceptAddress(a.settings.sessionSettingsFor(sessionID))
+	if err != nil {
+		return err
+	}
+
+	template, err := newAcceptorTemplate(sessionID, address)
+	if err != nil {
+		return err
+	}
+
+	if err := a.addListenerSettings(address, a.settings.sessionSettingsFor(sessionID)); err != nil {
+		return err
+	}
+
+	a.templates = append(a.templates, template)
+	return nil
+}
+
+//resolveSession returns the session for the unqualified sessionID of a logon accepted on address from remoteAddr.
+//Logons of sessions created from a template are authenticated, logons of unknown sessions matching a template create the session.
+func (a *Acceptor) resolveSession(address string, sessionID SessionID, logon Message, remoteAddr net.Addr) (SessionID, bool) {
+	a.sessionLock.RLock()
+	qualifiedSessionID, ok := a.qualifiedSessionIDs[sessionID]
+	fromTemplate := a.templateSessions[qualifiedSessionID]
+	var template *acceptorTemplate
+	for i := range a.templates {
+		if a.templates[i].matches(address, sessionID) {
+			template = &a.templates[i]
+			break
+		}
+	}
+	a.sessionLock.RUnlock()
+
+	if ok && !fromTemplate {
+		return a.qualifiedSessionIDOn(address, sessionID)
+	}
+
+	if !ok && template == nil {
+		return qualifiedSessionID, false
+	}
+
+	if err := a.authenticate(sessionID, logon, remoteAddr); err != nil {
+		a.globalLog.OnEventf("Logon of %v from %v refused: %v", sessionID, remoteAddr, err)
+		return qualifiedSessionID, false
+	}
+
+	if ok {
+		return a.qualifiedSessionIDOn(address, sessionID)
+	}
+
+	return a.createSessionFromTemplate(*template, sessionID)
+}
+
+//authenticate approves the logon of sessionID with the SessionAuthenticator of the Application, logons are approved if not implemented.
+func (a *Acceptor) authenticate(sessionID SessionID, logon Message, remoteAddr net.Addr) error {
+	authenticator, ok := a.app.(SessionAuthenticator)
+	if !ok {
+		return nil
+	}
+
+	return authenticator.AuthenticateLogon(sessionID, logon, remoteAddr)
+}
+
+//createSessionFromTemplate creates the session for sessionID from the settings of template.
+func (a *Acceptor) createSessionFromTemplate(template acceptorTemplate, sessionID SessionID) (SessionID, bool) {
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
+
+	//created by a concurrent logon
+	if qualifiedSessionID, ok := a.qualifiedSessionIDs[sessionID]; ok {
+		return qualifiedSessionID, a.sessionAddresses[qualifiedSessionID] == template.address
+	}
+
+	sessionSettings := a.settings.sessionSettings[template.sessionID].clone()
+	sessionSettings.Set(config.SenderCompID, sessionID.SenderCompID)
+	sessionSettings.Set(config.TargetCompID, sessionID.TargetCompID)
+	sessionSettings.Set(config.AcceptorTemplate, "N")
+
+	qualifiedSessionID, err := a.settings.AddSession(sessionSettings)
+	if err != nil {
+		a.globalLog.OnEventf("Cannot create session %v from template %v: %v", sessionID, template.sessionID, err)
+		return qualifiedSessionID, false
+	}
+
+	if err := a.createSession(qualifiedSessionID); err != nil {
+		a.settings.RemoveSession(qualifiedSessionID)
+		a.globalLog.OnEventf("Cannot create session %v from template %v: %v", sessionID, template.sessionID, err)
+		return qualifiedSessionID, false
+	}
+
+	a.templateSessions[qualifiedSessionID] = true
+	a.globalLog.OnEventf("Created session %v from template %v", qualifiedSessionID, template.sessionID)
+	return qualifiedSessionID, true
+}
@@ -0,0 +1,242 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"strconv"
+	"testing"
+)
+
+func newTestAcceptorSessionSettings(targetCompID string) *SessionSettings {
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "ACCEPTOR")
+	sessionSettings.Set(config.TargetCompID, targetCompID)
+	return sessionSettings
+}
+
+func TestAcceptor_AddRemoveSession(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, "5001")
+	if _, err := settings.AddSession(newTestAcceptorSessionSettings("STATIC")); err != nil {
+		t.Fatal(err)
+	}
+
+	acceptor, err := NewAcceptor(&TestClient{}, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionID, err := acceptor.AddSession(newTestAcceptorSessionSettings("DYNAMIC"))
+	if err != nil {
+		t.Fatalf("Unexpected error adding session: %v", err)
+	}
+
+	if _, err := LookupSession(sessionID); err != nil {
+		t.Errorf("Expected added session to be registered: %v", err)
+	}
+
+	if qualified, ok := acceptor.qualifiedSessionID(sessionID); !ok || qualified != sessionID {
+		t.Errorf("Expected connections to be accepted for %v", sessionID)
+	}
+
+	if _, err := acceptor.AddSession(newTestAcceptorSessionSettings("DYNAMIC")); err == nil {
+		t.Error("Expected error adding duplicate session")
+	}
+
+	if err := acceptor.RemoveSession(sessionID); err != nil {
+		t.Fatalf("Unexpected error removing session: %v", err)
+	}
+
+	if _, err := LookupSession(sessionID); err == nil {
+		t.Error("Expected removed session to be unregistered")
+	}
+
+	if _, ok := acceptor.qualifiedSessionID(sessionID); ok {
+		t.Errorf("Expected connections to be refused for %v", sessionID)
+	}
+
+	if err := acceptor.RemoveSession(sessionID); err == nil {
+		t.Error("Expected error removing unknown session")
+	}
+
+	if _, err := acceptor.AddSession(newTestAcceptorSessionSettings("DYNAMIC")); err != nil {
+		t.Errorf("Expected removed session to be added again: %v", err)
+	}
+}
+
+func TestAcceptor_DuplicateQualifiedSession(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, "5001")
+
+	for _, qualifier := range []string{"ORDERS", "DROPCOPY"} {
+		sessionSettings := newTestAcceptorSessionSettings("QUALIFIED")
+		sessionSettings.Set(config.SessionQualifier, qualifier)
+		if _, err := settings.AddSession(sessionSettings); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := NewAcceptor(&TestClient{}, NewMemoryStoreFactory(), settings, NewNullLogFactory()); err == nil {
+		t.Error("Expected error for accepted sessions distinguished only by qualifier")
+	}
+}
+
+func TestAcceptor_MultipleListeners(t *testing.T) {
+	internalPort, externalPort := strconv.Itoa(freePort(t)), strconv.Itoa(freePort(t))
+
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, internalPort)
+	settings.GlobalSettings().Set(config.SocketAcceptHost, "127.0.0.1")
+
+	internalSessionID, err := settings.AddSession(newTestAcceptorSessionSettings("INTERNAL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	externalSettings := newTestAcceptorSessionSettings("EXTERNAL")
+	externalSettings.Set(config.SocketAcceptPort, externalPort)
+	externalSessionID, err := settings.AddSession(externalSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acceptor, err := NewAcceptor(&TestClient{}, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Stop()
+
+	internal, external := "127.0.0.1:"+internalPort, "127.0.0.1:"+externalPort
+	for _, address := range []string{internal, external} {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			t.Errorf("Expected connections accepted on %v: %v", address, err)
+			continue
+		}
+		conn.Close()
+	}
+
+	var tests = []struct {
+		address   string
+		sessionID SessionID
+		expected  bool
+	}{
+		{internal, internalSessionID, true},
+		{external, internalSessionID, false},
+		{external, externalSessionID, true},
+		{internal, externalSessionID, false},
+	}
+
+	for _, test := range tests {
+		if _, ok := acceptor.qualifiedSessionIDOn(test.address, test.sessionID); ok != test.expected {
+			t.Errorf("Expected %v accepted on %v to be %v", test.sessionID, test.address, test.expected)
+		}
+	}
+
+	addedPort := strconv.Itoa(freePort(t))
+	addedSettings := newTestAcceptorSessionSettings("ADDED")
+	addedSettings.Set(config.SocketAcceptPort, addedPort)
+	if _, err := acceptor.AddSession(addedSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("tcp", "127.0.0.1:"+addedPort)
+	if err != nil {
+		t.Fatalf("Expected listener opened for added session: %v", err)
+	}
+	conn.Close()
+}
+
+type templateAuthenticator struct {
+	TestClient
+	authenticated []SessionID
+}
+
+func (a *templateAuthenticator) AuthenticateLogon(sessionID SessionID, logon Message, remoteAddr net.Addr) error {
+	a.authenticated = append(a.authenticated, sessionID)
+	if sessionID.TargetCompID == "CLIENT_DENIED" {
+		return fmt.Errorf("unknown client %v", sessionID.TargetCompID)
+	}
+	return nil
+}
+
+func TestAcceptor_Template(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, "5001")
+
+	templateSettings := newTestAcceptorSessionSettings("CLIENT_*")
+	templateSettings.Set(config.AcceptorTemplate, "Y")
+	templateSettings.Set(config.HeartBtInt, "45")
+	if _, err := settings.AddSession(templateSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	app := new(templateAuthenticator)
+	acceptor, err := NewAcceptor(app, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := ":5001"
+	remoteAddr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 40000}
+
+	var tests = []struct {
+		address      string
+		targetCompID string
+		expected     bool
+	}{
+		{address, "CLIENT_TEMPLATE", true},
+		{address, "CLIENT_DENIED", false},
+		{address, "OTHER_TEMPLATE", false},
+		{":5002", "CLIENT_OTHERPORT", false},
+	}
+
+	for _, test := range tests {
+		sessionID := SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: test.targetCompID}
+		qualified, ok := acceptor.resolveSession(test.address, sessionID, Message{}, remoteAddr)
+		if ok != test.expected {
+			t.Errorf("Expected %v accepted to be %v", sessionID, test.expected)
+			continue
+		}
+
+		_, err := LookupSession(sessionID)
+		if created := err == nil; created != test.expected {
+			t.Errorf("Expected %v created to be %v", sessionID, test.expected)
+		}
+
+		if ok && qualified != sessionID {
+			t.Errorf("Expected %v got %v", sessionID, qualified)
+		}
+	}
+
+	if len(app.authenticated) != 2 {
+		t.Errorf("Expected 2 logons authenticated got %v", app.authenticated)
+	}
+
+	created := SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "CLIENT_TEMPLATE"}
+	if session, _ := LookupSession(created); session.heartBtInt != 45 {
+		t.Errorf("Expected session created with template settings, HeartBtInt %v", session.heartBtInt)
+	}
+
+	if _, ok := acceptor.resolveSession(address, created, Message{}, remoteAddr); !ok || len(app.authenticated) != 3 {
+		t.Error("Expected each logon of a session created from a template to be authenticated")
+	}
+
+	invalidSettings := NewSettings()
+	invalidSettings.GlobalSettings().Set(config.SocketAcceptPort, "5001")
+	templateSettings = newTestAcceptorSessionSettings("CLIENT_[")
+	templateSettings.Set(config.AcceptorTemplate, "Y")
+	if _, err := invalidSettings.AddSession(templateSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewAcceptor(app, NewMemoryStoreFactory(), invalidSettings, NewNullLogFactory()); err == nil {
+		t.Error("Expected error for invalid template pattern")
+	}
+}
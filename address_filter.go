@@ -0,0 +1,183 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"strings"
+)
+
+//addressFilter permits the connections of counterparties by their IP address.
+type addressFilter struct {
+	//allowed are the networks connections must come from, any if empty
+	allowed []*net.IPNet
+
+	//denied are the networks connections are refused from, even if allowed
+	denied []*net.IPNet
+}
+
+//newAddressFilter returns the filter of the allowed and denied IP addresses and CIDR blocks, nil if both are empty.
+func newAddressFilter(allowed, denied []string) (*addressFilter, error) {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil, nil
+	}
+
+	f := new(addressFilter)
+
+	var err error
+	if f.allowed, err = parseNetworks(allowed); err != nil {
+		return nil, err
+	}
+
+	if f.denied, err = parseNetworks(denied); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+//addressFilterFromSettings returns the filter configured by the comma separated lists of AllowedRemoteAddresses and DeniedRemoteAddresses.
+func addressFilterFromSettings(settings *SessionSettings) (*addressFilter, error) {
+	var lists [2][]string
+	for i, setting := range []string{config.AllowedRemoteAddresses, config.DeniedRemoteAddresses} {
+		if !settings.HasSetting(setting) {
+			continue
+		}
+
+		value, _ := settings.Setting(setting)
+		for _, address := range strings.Split(value, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				lists[i] = append(lists[i], address)
+			}
+		}
+	}
+
+	filter, err := newAddressFilter(lists[0], lists[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote address filter: %v", err)
+	}
+
+	return filter, nil
+}
+
+//parseNetworks parses IP addresses, as single address networks, and CIDR blocks.
+func parseNetworks(addresses []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, address := range addresses {
+		if strings.Contains(address, "/") {
+			_, network, err := net.ParseCIDR(address)
+			if err != nil {
+				return nil, err
+			}
+			networks = append(networks, network)
+			continue
+		}
+
+		ip := net.ParseIP(address)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %v", address)
+		}
+
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+
+	return networks, nil
+}
+
+//check returns an error unless the connection from addr is permitted. A nil filter permits all connections,
+//connections without an IP address, such as over unix domain sockets, are permitted unless addresses are allowed.
+func (f *addressFilter) check(addr net.Addr) error {
+	if f == nil {
+		return nil
+	}
+
+	var ip net.IP
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		ip = addr.IP
+	case *net.UDPAddr:
+		ip = addr.IP
+	case *net.IPAddr:
+		ip = addr.IP
+	}
+
+	if ip == nil {
+		if len(f.allowed) > 0 {
+			return fmt.Errorf("%v is not an allowed address", addr)
+		}
+		return nil
+	}
+
+	for _, network := range f.denied {
+		if network.Contains(ip) {
+			return fmt.Errorf("%v is a denied address", ip)
+		}
+	}
+
+	if len(f.allowed) == 0 {
+		return nil
+	}
+
+	for _, network := range f.allowed {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%v is not an allowed address", ip)
+}
+
+//SetAddressFilter replaces the IP addresses and CIDR blocks the Acceptor accepts connections from, and refuses them from,
+//initially AllowedRemoteAddresses and DeniedRemoteAddresses of the global settings. Connections are allowed from any address
+//if allowed is empty. Connections already accepted are not affected.
+func (a *Acceptor) SetAddressFilter(allowed, denied []string) error {
+	filter, err := newAddressFilter(allowed, denied)
+	if err != nil {
+		return err
+	}
+
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
+
+	a.addressFilter = filter
+	return nil
+}
+
+//SetSessionAddressFilter replaces the IP addresses and CIDR blocks the session with sessionID accepts logons from, and refuses them from,
+//in addition to those of the Acceptor. The filter is initially the AllowedRemoteAddresses and DeniedRemoteAddresses of the session.
+func (a *Acceptor) SetSessionAddressFilter(sessionID SessionID, allowed, denied []string) error {
+	filter, err := newAddressFilter(allowed, denied)
+	if err != nil {
+		return err
+	}
+
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
+
+	if _, ok := a.sessionAddresses[sessionID]; !ok {
+		return fmt.Errorf("session not found")
+	}
+
+	a.sessionAddressFilters[sessionID] = filter
+	return nil
+}
+
+//checkAddress returns an error unless the Acceptor permits the connection from remoteAddr.
+func (a *Acceptor) checkAddress(remoteAddr net.Addr) error {
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+
+	return a.addressFilter.check(remoteAddr)
+}
+
+//checkSessionAddress returns an error unless the session with sessionID permits the logon from remoteAddr.
+func (a *Acceptor) checkSessionAddress(sessionID SessionID, remoteAddr net.Addr) error {
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+
+	return a.sessionAddressFilters[sessionID].check(remoteAddr)
+}
@@ -0,0 +1,165 @@
+package quickfix
+
+import (
+	"context"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAddressFilter_Check(t *testing.T) {
+	filter, err := newAddressFilter([]string{"10.0.0.0/8", "192.0.2.7", "2001:db8::/32"}, []string{"10.1.0.0/16", "2001:db8::bad"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		addr      net.Addr
+		permitted bool
+	}{
+		{&net.TCPAddr{IP: net.ParseIP("10.2.3.4"), Port: 40000}, true},
+		{&net.TCPAddr{IP: net.ParseIP("10.1.3.4"), Port: 40000}, false},
+		{&net.TCPAddr{IP: net.ParseIP("192.0.2.7"), Port: 40000}, true},
+		{&net.TCPAddr{IP: net.ParseIP("192.0.2.8"), Port: 40000}, false},
+		{&net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 40000}, true},
+		{&net.TCPAddr{IP: net.ParseIP("2001:db8::bad"), Port: 40000}, false},
+		{&net.UnixAddr{Name: "/var/run/fix.sock", Net: "unix"}, false},
+	}
+
+	for _, test := range tests {
+		if permitted := filter.check(test.addr) == nil; permitted != test.permitted {
+			t.Errorf("Expected %v permitted to be %v", test.addr, test.permitted)
+		}
+	}
+
+	denyOnly, err := newAddressFilter(nil, []string{"10.1.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if denyOnly.check(&net.TCPAddr{IP: net.ParseIP("192.0.2.8")}) != nil || denyOnly.check(&net.UnixAddr{Name: "/var/run/fix.sock", Net: "unix"}) != nil {
+		t.Error("Expected addresses not denied permitted without an allow list")
+	}
+
+	var none *addressFilter
+	if err := none.check(&net.TCPAddr{IP: net.ParseIP("10.1.3.4")}); err != nil {
+		t.Errorf("Expected all addresses permitted without a filter, got %v", err)
+	}
+
+	for _, invalid := range []string{"10.0.0.0/33", "10.0.0", "fix.example.com"} {
+		if _, err := newAddressFilter([]string{invalid}, nil); err == nil {
+			t.Errorf("Expected error for %v", invalid)
+		}
+	}
+}
+
+func TestAddressFilterFromSettings(t *testing.T) {
+	if filter, err := addressFilterFromSettings(NewSessionSettings()); err != nil || filter != nil {
+		t.Errorf("Expected no filter without settings, got %v %v", filter, err)
+	}
+
+	settings := NewSessionSettings()
+	settings.Set(config.AllowedRemoteAddresses, "10.0.0.0/8, 192.0.2.7")
+	settings.Set(config.DeniedRemoteAddresses, "10.1.0.0/16")
+
+	filter, err := addressFilterFromSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(filter.allowed) != 2 || len(filter.denied) != 1 {
+		t.Errorf("Unexpected filter %+v", filter)
+	}
+
+	settings.Set(config.DeniedRemoteAddresses, "10.1.0.0/16, 10.300.0.0/16")
+	if _, err := addressFilterFromSettings(settings); err == nil {
+		t.Error("Expected error for invalid address")
+	}
+}
+
+func TestAcceptor_AddressFilter(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, "5001")
+	settings.GlobalSettings().Set(config.DeniedRemoteAddresses, "10.1.0.0/16")
+	sessionSettings := newTestAcceptorSessionSettings("FILTERED")
+	sessionSettings.Set(config.AllowedRemoteAddresses, "10.0.0.0/8")
+	sessionID, err := settings.AddSession(sessionSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acceptor, err := NewAcceptor(new(TestClient), NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unregisterSession(sessionID)
+
+	client := &net.TCPAddr{IP: net.ParseIP("192.0.2.7"), Port: 40000}
+	if err := acceptor.checkAddress(&net.TCPAddr{IP: net.ParseIP("10.1.0.1"), Port: 40000}); err == nil {
+		t.Error("Expected connection from a denied address refused")
+	}
+
+	if err := acceptor.checkAddress(client); err != nil {
+		t.Errorf("Expected connection permitted, got %v", err)
+	}
+
+	if err := acceptor.checkSessionAddress(sessionID, client); err == nil {
+		t.Error("Expected logon from an address not allowed for the session refused")
+	}
+
+	if err := acceptor.SetSessionAddressFilter(sessionID, []string{"192.0.2.0/24"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.checkSessionAddress(sessionID, client); err != nil {
+		t.Errorf("Expected logon permitted once allowed, got %v", err)
+	}
+
+	if err := acceptor.SetAddressFilter(nil, []string{"192.0.2.7"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.checkAddress(client); err == nil {
+		t.Error("Expected connection refused once denied")
+	}
+
+	if err := acceptor.SetSessionAddressFilter(SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "UNKNOWN"}, nil, nil); err == nil {
+		t.Error("Expected error for an unknown session")
+	}
+
+	if err := acceptor.SetAddressFilter([]string{"192.0.2"}, nil); err == nil {
+		t.Error("Expected error for an invalid address")
+	}
+}
+
+func TestAcceptor_RefusesDeniedConnection(t *testing.T) {
+	port := strconv.Itoa(freePort(t))
+
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, port)
+	settings.GlobalSettings().Set(config.DeniedRemoteAddresses, "127.0.0.0/8")
+	acceptor, err := NewAcceptor(new(TestClient), NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	conn, err := net.Dial("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	//closed without reading the logon
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := ioutil.ReadAll(conn); err != nil {
+		t.Errorf("Expected connection closed by the acceptor, got %v", err)
+	}
+}
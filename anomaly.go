@@ -0,0 +1,200 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	//defaultResendStormThreshold is the number of ResendRequests received within the ResendStormWindow reported as a resend storm.
+	defaultResendStormThreshold = 5
+
+	//defaultResendStormWindow is the window ResendRequests received are counted over.
+	defaultResendStormWindow = time.Minute
+
+	//defaultLogonRejectThreshold is the number of consecutive logons rejected reported as an anomaly.
+	defaultLogonRejectThreshold = 3
+)
+
+//AnomalyKind is the kind of an Anomaly.
+type AnomalyKind string
+
+const (
+	//AnomalySeqNumGap is a MsgSeqNum received higher than expected, Count messages are missing and requested with a ResendRequest.
+	AnomalySeqNumGap AnomalyKind = "SeqNumGap"
+
+	//AnomalyResendStorm is Count ResendRequests received from the counterparty within ResendStormWindow seconds, reported on each from
+	//ResendStormThreshold on.
+	AnomalyResendStorm AnomalyKind = "ResendStorm"
+
+	//AnomalyLogonRejects is Count consecutive logons rejected, by the counterparty or the session, reported on each from LogonRejectThreshold on.
+	AnomalyLogonRejects AnomalyKind = "LogonRejects"
+
+	//AnomalyCheckSumFailure is a message received with a CheckSum not matching its bytes. The message is processed regardless.
+	AnomalyCheckSumFailure AnomalyKind = "CheckSumFailure"
+
+	//AnomalyHeartbeatTimeout is a counterparty not responding to a TestRequest, the session disconnects.
+	AnomalyHeartbeatTimeout AnomalyKind = "HeartbeatTimeout"
+
+	//AnomalyStoreWriteFailure is a message that could not be saved, or a store that could not be flushed.
+	AnomalyStoreWriteFailure AnomalyKind = "StoreWriteFailure"
+)
+
+//Anomaly is a protocol anomaly of a session worth alerting on.
+type Anomaly struct {
+	Kind      AnomalyKind
+	SessionID SessionID
+	Time      time.Time
+
+	//Count is the number of missing messages, ResendRequests received, or consecutive logons rejected, 1 for other kinds.
+	Count int
+
+	//Err is the error of the anomaly, such as the error of the store or the reason the logon was rejected.
+	Err error
+}
+
+func (a Anomaly) String() string {
+	text := fmt.Sprintf("%v %v", a.SessionID, a.Kind)
+	if a.Count > 1 {
+		text += fmt.Sprintf(" x%d", a.Count)
+	}
+	if a.Err != nil {
+		text += fmt.Sprintf(": %v", a.Err)
+	}
+
+	return text
+}
+
+//AnomalyListener is notified of the protocol anomalies of sessions, those worth paging on, added with AddAnomalyListener.
+//Listeners are called from the session goroutine and must not block.
+type AnomalyListener interface {
+	OnAnomaly(anomaly Anomaly)
+}
+
+//AnomalyListenerFunc is an AnomalyListener calling the func.
+type AnomalyListenerFunc func(anomaly Anomaly)
+
+//OnAnomaly implements AnomalyListener.
+func (f AnomalyListenerFunc) OnAnomaly(anomaly Anomaly) { f(anomaly) }
+
+var globalAnomalyListeners struct {
+	lock      sync.RWMutex
+	listeners []AnomalyListener
+}
+
+//AddAnomalyListener adds listener, notified of the anomalies of every session.
+func AddAnomalyListener(listener AnomalyListener) {
+	globalAnomalyListeners.lock.Lock()
+	defer globalAnomalyListeners.lock.Unlock()
+
+	globalAnomalyListeners.listeners = append(globalAnomalyListeners.listeners, listener)
+}
+
+func anomalyListeners() []AnomalyListener {
+	globalAnomalyListeners.lock.RLock()
+	defer globalAnomalyListeners.lock.RUnlock()
+
+	return globalAnomalyListeners.listeners
+}
+
+//onAnomaly notifies the anomaly listeners of an anomaly of the session.
+func (s *Session) onAnomaly(kind AnomalyKind, count int, err error) {
+	listeners := anomalyListeners()
+	if len(listeners) == 0 {
+		return
+	}
+
+	anomaly := Anomaly{Kind: kind, SessionID: s.sessionID, Time: s.now(), Count: count, Err: err}
+	for _, listener := range listeners {
+		listener.OnAnomaly(anomaly)
+	}
+}
+
+//anomalyDetector counts the events of the session reported as anomalies once repeated, used only by the session goroutine.
+//Thresholds and windows of 0 are the defaults.
+type anomalyDetector struct {
+	resendStormThreshold int
+	resendStormWindow    time.Duration
+	logonRejectThreshold int
+
+	//resendRequests are the times of the ResendRequests received within the window
+	resendRequests []time.Time
+	logonRejects   int
+}
+
+//onResendRequestReceived counts a ResendRequest received, reporting a resend storm from the threshold on.
+func (s *Session) onResendRequestReceived() {
+	now := s.now()
+	detector := &s.anomalies
+
+	window := detector.resendStormWindow
+	if window == 0 {
+		window = defaultResendStormWindow
+	}
+
+	threshold := detector.resendStormThreshold
+	if threshold == 0 {
+		threshold = defaultResendStormThreshold
+	}
+
+	recent := detector.resendRequests[:0]
+	for _, received := range detector.resendRequests {
+		if now.Sub(received) < window {
+			recent = append(recent, received)
+		}
+	}
+	detector.resendRequests = append(recent, now)
+
+	if len(detector.resendRequests) >= threshold {
+		s.onAnomaly(AnomalyResendStorm, len(detector.resendRequests), nil)
+	}
+}
+
+//onLogonRejected counts a logon rejected for err, reporting the consecutive rejects from the threshold on.
+func (s *Session) onLogonRejected(err error) {
+	threshold := s.anomalies.logonRejectThreshold
+	if threshold == 0 {
+		threshold = defaultLogonRejectThreshold
+	}
+
+	s.anomalies.logonRejects++
+	if s.anomalies.logonRejects >= threshold {
+		s.onAnomaly(AnomalyLogonRejects, s.anomalies.logonRejects, err)
+	}
+}
+
+//onLogonAccepted resets the count of consecutive logons rejected.
+func (s *Session) onLogonAccepted() {
+	s.anomalies.logonRejects = 0
+}
+
+//checkCheckSum reports an AnomalyCheckSumFailure if the CheckSum of msg does not match its bytes.
+func (s *Session) checkCheckSum(msg *Message) {
+	if len(anomalyListeners()) == 0 {
+		return
+	}
+
+	end := bytes.LastIndex(msg.rawMessage, []byte("\00110="))
+	if end < 0 {
+		return
+	}
+
+	checkSum := new(fix.StringValue)
+	if err := msg.Trailer.GetField(tag.CheckSum, checkSum); err != nil {
+		return
+	}
+
+	total := 0
+	for _, b := range msg.rawMessage[:end+1] {
+		total += int(b)
+	}
+
+	if received, err := strconv.Atoi(checkSum.Value); err != nil || received != total%256 {
+		s.onAnomaly(AnomalyCheckSumFailure, 1, fmt.Errorf("CheckSum %v, expected %03d", checkSum.Value, total%256))
+	}
+}
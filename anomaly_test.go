@@ -0,0 +1,166 @@
+package quickfix
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+//anomalyRecorder records the anomalies of a session.
+type anomalyRecorder struct {
+	lock      sync.Mutex
+	sessionID SessionID
+	anomalies []Anomaly
+}
+
+func (r *anomalyRecorder) OnAnomaly(anomaly Anomaly) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if anomaly.SessionID == r.sessionID {
+		r.anomalies = append(r.anomalies, anomaly)
+	}
+}
+
+func (r *anomalyRecorder) take() []Anomaly {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	anomalies := r.anomalies
+	r.anomalies = nil
+	return anomalies
+}
+
+//recordAnomalies adds a listener recording the anomalies of session, removed by the func returned.
+func recordAnomalies(session *Session) (*anomalyRecorder, func()) {
+	recorder := &anomalyRecorder{sessionID: session.sessionID}
+	AddAnomalyListener(recorder)
+
+	return recorder, func() {
+		globalAnomalyListeners.lock.Lock()
+		defer globalAnomalyListeners.lock.Unlock()
+
+		globalAnomalyListeners.listeners = nil
+	}
+}
+
+func expectAnomaly(t *testing.T, recorder *anomalyRecorder, kind AnomalyKind, count int) {
+	anomalies := recorder.take()
+	if len(anomalies) != 1 || anomalies[0].Kind != kind || anomalies[0].Count != count {
+		t.Errorf("Expected %v x%v, got %v", kind, count, anomalies)
+	}
+}
+
+func TestSession_AnomalySeqNumGap(t *testing.T) {
+	session := newTestAdminSession(&TestClient{})
+	recorder, remove := recordAnomalies(session)
+	defer remove()
+
+	session.doTargetTooHigh(targetTooHigh{ReceivedTarget: 10, ExpectedTarget: 4})
+	expectAnomaly(t, recorder, AnomalySeqNumGap, 6)
+}
+
+func TestSession_AnomalyResendStorm(t *testing.T) {
+	session := newTestAdminSession(&TestClient{})
+	clock := &manualClock{now: time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)}
+	session.clock = clock
+	session.anomalies.resendStormThreshold = 3
+	recorder, remove := recordAnomalies(session)
+	defer remove()
+
+	session.onResendRequestReceived()
+	session.onResendRequestReceived()
+	if anomalies := recorder.take(); len(anomalies) != 0 {
+		t.Errorf("Expected no anomaly below threshold, got %v", anomalies)
+	}
+
+	session.onResendRequestReceived()
+	expectAnomaly(t, recorder, AnomalyResendStorm, 3)
+
+	clock.Advance(defaultResendStormWindow)
+	session.onResendRequestReceived()
+	if anomalies := recorder.take(); len(anomalies) != 0 {
+		t.Errorf("Expected ResendRequests out of the window not counted, got %v", anomalies)
+	}
+}
+
+func TestSession_AnomalyLogonRejects(t *testing.T) {
+	session := newTestAdminSession(&TestClient{})
+	session.initiateLogon = true
+	recorder, remove := recordAnomalies(session)
+	defer remove()
+
+	msg, err := parseMessage(rawMessage("FIX.4.2", "35=5\00134=1\00149=ISLD\00156=TW\00158=unknown user\001"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i < defaultLogonRejectThreshold; i++ {
+		logonState{}.FixMsgIn(session, *msg)
+	}
+	if anomalies := recorder.take(); len(anomalies) != 0 {
+		t.Errorf("Expected no anomaly below threshold, got %v", anomalies)
+	}
+
+	logonState{}.FixMsgIn(session, *msg)
+	anomalies := recorder.take()
+	if len(anomalies) != 1 || anomalies[0].Kind != AnomalyLogonRejects || anomalies[0].Count != defaultLogonRejectThreshold ||
+		anomalies[0].Err == nil || anomalies[0].Err.Error() != "Received logout while waiting for logon: unknown user" {
+		t.Errorf("Expected logon rejects, got %v", anomalies)
+	}
+
+	session.onLogonAccepted()
+	logonState{}.FixMsgIn(session, *msg)
+	if anomalies := recorder.take(); len(anomalies) != 0 {
+		t.Errorf("Expected count reset on logon, got %v", anomalies)
+	}
+}
+
+func TestSession_AnomalyCheckSumFailure(t *testing.T) {
+	session := newTestAdminSession(&TestClient{})
+	recorder, remove := recordAnomalies(session)
+	defer remove()
+
+	valid := rawMessage("FIX.4.2", "35=0\00134=1\00149=ISLD\00156=TW\001")
+	msg, err := parseMessage(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session.checkCheckSum(msg)
+	if anomalies := recorder.take(); len(anomalies) != 0 {
+		t.Errorf("Expected no anomaly for a valid CheckSum, got %v", anomalies)
+	}
+
+	garbled := append([]byte(nil), valid...)
+	garbled[len(garbled)-2]++
+	if msg, err = parseMessage(garbled); err != nil {
+		t.Fatal(err)
+	}
+
+	session.checkCheckSum(msg)
+	expectAnomaly(t, recorder, AnomalyCheckSumFailure, 1)
+}
+
+func TestSession_AnomalyHeartbeatTimeout(t *testing.T) {
+	session := newTestAdminSession(&TestClient{})
+	recorder, remove := recordAnomalies(session)
+	defer remove()
+
+	pendingTimeout{}.Timeout(session, peerTimeout)
+	expectAnomaly(t, recorder, AnomalyHeartbeatTimeout, 1)
+}
+
+func TestSession_AnomalyStoreWriteFailure(t *testing.T) {
+	memory, _ := NewMemoryStoreFactory().Create(SessionID{})
+	session := &Session{store: &unhealthyStore{memoryStore: memory.(*memoryStore), err: errors.New("disk full")}}
+	recorder, remove := recordAnomalies(session)
+	defer remove()
+
+	session.saveMessage(1, []byte("msg"))
+	anomalies := recorder.take()
+	if len(anomalies) != 1 || anomalies[0].Kind != AnomalyStoreWriteFailure || anomalies[0].Err == nil {
+		t.Errorf("Expected store write failure, got %v", anomalies)
+	}
+}
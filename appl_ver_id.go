@@ -0,0 +1,79 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/enum"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"strings"
+)
+
+//applVerIDs maps application version names, as used in settings, to ApplVerID values.
+var applVerIDs = map[string]string{
+	fix.BeginString_FIX40:         enum.ApplVerID_FIX40,
+	fix.BeginString_FIX41:         enum.ApplVerID_FIX41,
+	fix.BeginString_FIX42:         enum.ApplVerID_FIX42,
+	fix.BeginString_FIX43:         enum.ApplVerID_FIX43,
+	fix.BeginString_FIX44:         enum.ApplVerID_FIX44,
+	fix.BeginString_FIX50:         enum.ApplVerID_FIX50,
+	fix.BeginString_FIX50 + "SP1": enum.ApplVerID_FIX50SP1,
+	fix.BeginString_FIX50 + "SP2": enum.ApplVerID_FIX50SP2,
+}
+
+//parseApplVerID returns the ApplVerID for value, either an ApplVerID or an application version name such as FIX.5.0SP2.
+func parseApplVerID(value string) (string, error) {
+	if applVerID, ok := applVerIDs[value]; ok {
+		return applVerID, nil
+	}
+
+	for _, applVerID := range applVerIDs {
+		if applVerID == value {
+			return applVerID, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown application version %v", value)
+}
+
+//parseAppDataDictionaries parses the application data dictionaries configured per application version,
+//with settings AppDataDictionary.<version>, e.g. AppDataDictionary.FIX.4.4. The result is keyed by ApplVerID.
+func parseAppDataDictionaries(settings *SessionSettings) (map[string]*datadictionary.DataDictionary, error) {
+	prefix := config.AppDataDictionary + "."
+	dictionaries := make(map[string]*datadictionary.DataDictionary)
+
+	for _, setting := range settings.dataDictionarySettings(prefix) {
+		applVerID, err := parseApplVerID(strings.TrimPrefix(setting, prefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid setting %v: %v", setting, err)
+		}
+
+		if dictionaries[applVerID], err = settings.dataDictionary(setting); err != nil {
+			return nil, err
+		}
+	}
+
+	return dictionaries, nil
+}
+
+//applVerID returns the application version of an application message, the ApplVerID header field if set,
+//otherwise the DefaultApplVerID from the Logon of the counterparty.
+func (s *Session) applVerID(msg Message) string {
+	applVerID := new(fix.StringValue)
+	if err := msg.Header.GetField(tag.ApplVerID, applVerID); err == nil {
+		return applVerID.Value
+	}
+
+	return s.targetDefaultApplVerID
+}
+
+//appDataDictionaryFor returns the application data dictionary for the application version of msg,
+//the AppDataDictionary setting if none is configured for the version. Nil if no dictionary applies.
+func (s *Session) appDataDictionaryFor(msg Message) *datadictionary.DataDictionary {
+	if dictionary, ok := s.appDataDictionaries[s.applVerID(msg)]; ok {
+		return dictionary
+	}
+
+	return s.appDataDictionary
+}
@@ -0,0 +1,108 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix/enum"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"testing"
+)
+
+func TestParseApplVerID(t *testing.T) {
+	var testCases = []struct {
+		value             string
+		expectedApplVerID string
+		expectError       bool
+	}{
+		{value: "9", expectedApplVerID: enum.ApplVerID_FIX50SP2},
+		{value: "FIX.5.0SP2", expectedApplVerID: enum.ApplVerID_FIX50SP2},
+		{value: "FIX.4.4", expectedApplVerID: enum.ApplVerID_FIX44},
+		{value: "FIX.5.0SP9", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		applVerID, err := parseApplVerID(tc.value)
+		switch {
+		case tc.expectError && err == nil:
+			t.Errorf("%v: expected error", tc.value)
+		case !tc.expectError && err != nil:
+			t.Errorf("%v: unexpected error %v", tc.value, err)
+		case applVerID != tc.expectedApplVerID:
+			t.Errorf("%v: expected %v got %v", tc.value, tc.expectedApplVerID, applVerID)
+		}
+	}
+}
+
+func TestSession_AppDataDictionaryFor(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set("AppDataDictionary.FIX.4.4", "spec/FIX44.xml")
+	settings.Set("AppDataDictionary.9", "spec/FIX50SP2.xml")
+
+	dictionaries, err := parseAppDataDictionaries(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := &Session{appDataDictionaries: dictionaries, targetDefaultApplVerID: enum.ApplVerID_FIX50SP2}
+
+	defaultVersion, _ := parseMessage(rawMessage("FIXT.1.1", "35=D\00134=2\00149=TW\00156=ISLD\001"))
+	if dictionary := session.appDataDictionaryFor(*defaultVersion); dictionary != dictionaries[enum.ApplVerID_FIX50SP2] {
+		t.Error("Expected the dictionary of the counterparty DefaultApplVerID")
+	}
+
+	fix44, _ := parseMessage(rawMessage("FIXT.1.1", "35=D\00134=2\00149=TW\00156=ISLD\0011128=6\001"))
+	if dictionary := session.appDataDictionaryFor(*fix44); dictionary != dictionaries[enum.ApplVerID_FIX44] {
+		t.Error("Expected the dictionary of the message ApplVerID")
+	}
+
+	fix50, _ := parseMessage(rawMessage("FIXT.1.1", "35=D\00134=2\00149=TW\00156=ISLD\0011128=7\001"))
+	if dictionary := session.appDataDictionaryFor(*fix50); dictionary != nil {
+		t.Error("Expected no dictionary for unconfigured ApplVerID")
+	}
+
+	settings.Set("AppDataDictionary.FIX.9.9", "spec/FIX44.xml")
+	if _, err := parseAppDataDictionaries(settings); err == nil {
+		t.Error("Expected error for unknown application version")
+	}
+}
+
+func TestMessageRouter_RouteApplVerID(t *testing.T) {
+	var routed string
+	router := NewMessageRouter()
+	for _, beginString := range []string{"FIX.4.4", "FIX.5.0", enum.ApplVerID_FIX50SP1, enum.ApplVerID_FIX50SP2} {
+		key := beginString
+		router.AddRoute(key, "D", func(msg Message, sessionID SessionID) MessageRejectError {
+			routed = key
+			return nil
+		})
+	}
+
+	var testCases = []struct {
+		applVerID      string
+		expectedRoute  string
+		expectedReject bool
+	}{
+		{applVerID: enum.ApplVerID_FIX44, expectedRoute: "FIX.4.4"},
+		{applVerID: enum.ApplVerID_FIX50, expectedRoute: "FIX.5.0"},
+		{applVerID: enum.ApplVerID_FIX50SP1, expectedRoute: enum.ApplVerID_FIX50SP1},
+		{applVerID: enum.ApplVerID_FIX50SP2, expectedRoute: enum.ApplVerID_FIX50SP2},
+		{applVerID: "99", expectedReject: true},
+	}
+
+	for _, tc := range testCases {
+		routed = ""
+		builder := NewMessageBuilder()
+		builder.Header().Set(field.NewBeginString("FIXT.1.1"))
+		builder.Header().Set(field.NewMsgType("D"))
+		builder.Header().Set(field.NewApplVerID(tc.applVerID))
+		msg := buildAndParse(t, builder)
+
+		reject := router.Route(*msg, SessionID{})
+		switch {
+		case tc.expectedReject && reject == nil:
+			t.Errorf("ApplVerID %v: expected reject", tc.applVerID)
+		case !tc.expectedReject && reject != nil:
+			t.Errorf("ApplVerID %v: unexpected reject %v", tc.applVerID, reject)
+		case routed != tc.expectedRoute:
+			t.Errorf("ApplVerID %v: expected route %v got %v", tc.applVerID, tc.expectedRoute, routed)
+		}
+	}
+}
@@ -1,5 +1,12 @@
 package quickfix
 
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"time"
+)
+
 //The Application interface should be implemented by FIX Applications.
 //This is the primary interface for processing messages from a FIX Session.
 type Application interface {
@@ -13,14 +20,98 @@ type Application interface {
 	OnLogout(sessionID SessionID)
 
 	//Notification of admin message being sent to target.
+	//msgBuilder may be modified before it is sent, for example to add fields to the Logon. Use an Interceptor to veto admin messages.
 	ToAdmin(msgBuilder MessageBuilder, sessionID SessionID)
 
 	//Notification of app message being sent to target.
 	ToApp(msgBuilder MessageBuilder, sessionID SessionID) error
 
-	//Notification of admin message being received from target.
+	//Notification of admin message being received from target, including the Logon and its response.
+	//Returning NewRejectLogonError, NewDisconnectError, or NewIgnoreMessageError refuses the message with a Logout, a disconnect, or silently, other errors send a Reject.
 	FromAdmin(msg Message, sessionID SessionID) MessageRejectError
 
 	//Notification of app message being received from target.
 	FromApp(msg Message, sessionID SessionID) MessageRejectError
 }
+
+//ResendFilter may be implemented by an Application to veto the resend of stored application messages, for example orders that are stale by the time the counterparty requests a resend.
+type ResendFilter interface {
+	//ToResend is called before msg is resent in response to a ResendRequest.
+	//Returning false replaces msg with a SequenceReset-GapFill.
+	ToResend(msg Message, sessionID SessionID) bool
+}
+
+//SessionStateListener may be implemented by an Application to be notified of each change of SessionState, for example for monitoring.
+type SessionStateListener interface {
+	//OnSessionStateChange is called when a session changes from one state to another.
+	//reason is the cause of a transition not driven by a message from the counterparty, such as a timeout or disconnect, otherwise nil.
+	OnSessionStateChange(sessionID SessionID, from, to SessionState, reason error)
+}
+
+//SendErrorListener may be implemented by an Application to be notified of the messages queued with OutboundQueueDepth that could not be sent,
+//SendToTarget having returned once they were queued.
+type SendErrorListener interface {
+	//OnSendError is called from the goroutine sending the queued messages of the session, with the error msg was not sent for.
+	OnSendError(msg MessageBuilder, sessionID SessionID, err error)
+}
+
+//EndpointListener may be implemented by an Application to be notified of the endpoint an initiated session connects to, for example to report failover to a backup gateway.
+type EndpointListener interface {
+	//OnEndpointConnect is called when an initiated session connects to address, before the Logon is sent.
+	OnEndpointConnect(sessionID SessionID, address string)
+}
+
+//ReconnectListener may be implemented by an Application to be notified when an initiated session is no longer reconnected after MaxReconnectAttempts consecutive failed attempts.
+type ReconnectListener interface {
+	//OnReconnectAbandoned is called once the session stops reconnecting, err is the error of the last attempt.
+	OnReconnectAbandoned(sessionID SessionID, attempts int, err error)
+}
+
+//SessionAuthenticator may be implemented by an Application to approve logons of sessions created from an acceptor template, for example to check the counterparty against an onboarding database.
+type SessionAuthenticator interface {
+	//AuthenticateLogon is called with the first message of each connection matching a template, before the session is created or the Logon processed.
+	//logon carries the CompIDs and any Username, Password, or RawData of the counterparty. Returning an error refuses the connection.
+	AuthenticateLogon(sessionID SessionID, logon Message, remoteAddr net.Addr) error
+}
+
+//HolidayCalendar may be implemented by an Application to keep scheduled sessions inactive on venue holidays.
+//Initiated sessions are not connected and accepted logons are refused on holidays, but a holiday does not end the schedule period, sequence numbers are not reset.
+type HolidayCalendar interface {
+	//IsHoliday returns true if date, midnight in the TimeZone of the session schedule, is a holiday for the session.
+	//It is called each time the schedule is checked, about once a second while connected, and should not block.
+	IsHoliday(sessionID SessionID, date time.Time) bool
+}
+
+//DuplicateLogonListener may be implemented by an Application to be alerted when a connection logs on for an accepted session that is already connected, with DuplicateLogonPolicy set to Alert.
+type DuplicateLogonListener interface {
+	//OnDuplicateLogon is called before the duplicate connection from remoteAddr is refused.
+	OnDuplicateLogon(sessionID SessionID, remoteAddr net.Addr)
+}
+
+//SeqNumResyncApprover may be implemented by an Application to approve adopting the MsgSeqNum of the counterparty when lower than expected, with SeqNumTooLowPolicy set to Resync.
+//If not implemented, the MsgSeqNum is adopted.
+type SeqNumResyncApprover interface {
+	//ApproveSeqNumResync is called with the MsgSeqNum expected and received. Returning false logs out, as with SeqNumTooLowPolicy set to Strict.
+	ApproveSeqNumResync(sessionID SessionID, expected, received int) bool
+}
+
+//QuarantineListener may be implemented by an Application to be notified of each inbound message quarantined, with PoisonMessageThreshold set.
+type QuarantineListener interface {
+	//OnQuarantine is called once the session has skipped the message, the session remains logged on.
+	OnQuarantine(sessionID SessionID, deadLetter DeadLetter)
+}
+
+//PeerCertificateAuthenticator may be implemented by an Application to bind the identity of counterparties connecting to an acceptor over TLS
+//to the sessions they log on to, for example by matching the subject of their certificate to their CompIDs.
+type PeerCertificateAuthenticator interface {
+	//AuthenticatePeerCertificate is called with the first message of each connection accepted over TLS and the certificates presented, leaf first,
+	//before the session is resolved. Returning an error refuses the connection.
+	AuthenticatePeerCertificate(sessionID SessionID, logon Message, certificates []*x509.Certificate) error
+}
+
+//ClientCertificateProvider may be implemented by an Application to select the certificate each initiated session presents to acceptors
+//requesting one, in place of SocketCertificateFile, for example from a key store.
+type ClientCertificateProvider interface {
+	//ClientCertificate is called during each TLS handshake of the session with sessionID in which the acceptor requests a certificate.
+	ClientCertificate(sessionID SessionID, info *tls.CertificateRequestInfo) (*tls.Certificate, error)
+}
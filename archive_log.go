@@ -0,0 +1,460 @@
+package quickfix
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	defaultArchiveMaxSize    = 8 << 20
+	defaultArchiveInterval   = 5 * time.Minute
+	defaultArchiveQueueDepth = 64
+
+	archiveRetryInterval    = time.Second
+	archiveMaxRetryInterval = time.Minute
+
+	archiveDayLayout       = "2006/01/02"
+	archiveTimestampLayout = "20060102-15:04:05.000000"
+)
+
+//MessageArchiver stores the compressed message logs of an ArchiveLogFactory, e.g. in object storage.
+type MessageArchiver interface {
+	//Archive stores the gzip compressed message log named key. Archive is called from a single goroutine, failures are retried.
+	Archive(key string, log []byte) error
+}
+
+//ArchiveListener may be implemented by a MessageArchiver to observe archival failures.
+type ArchiveListener interface {
+	//OnArchiveError is called each time archiving key fails, before it is retried.
+	OnArchiveError(key string, err error)
+
+	//OnArchiveDropped is called when the log named key is discarded without being archived.
+	OnArchiveDropped(key string, err error)
+}
+
+//ArchiveLag describes the message logs waiting to be archived.
+type ArchiveLag struct {
+	//Pending is the number of logs queued.
+	Pending int
+
+	//Oldest is the time of the first message of the oldest queued log, zero if none are queued.
+	Oldest time.Time
+
+	//Failures is the number of consecutive failures archiving the oldest queued log.
+	Failures int
+}
+
+//archiveOverflow determines how a message log is handled when ArchiveQueueDepth logs are waiting to be archived.
+type archiveOverflow int
+
+const (
+	//archiveOverflowBlock blocks logging until the queue has room, slowing the sessions logging.
+	archiveOverflowBlock archiveOverflow = iota
+
+	//archiveOverflowDrop discards the log.
+	archiveOverflowDrop
+)
+
+//parseArchiveOverflow maps the ArchiveOverflow setting to an archiveOverflow.
+func parseArchiveOverflow(setting string) (archiveOverflow, error) {
+	switch setting {
+	case "Block":
+		return archiveOverflowBlock, nil
+	case "Drop":
+		return archiveOverflowDrop, nil
+	}
+
+	return archiveOverflowBlock, fmt.Errorf("invalid ArchiveOverflow %v, expected Block or Drop", setting)
+}
+
+//archiveFile is a sealed message log waiting to be archived.
+type archiveFile struct {
+	key   string
+	log   []byte
+	first time.Time
+}
+
+//ArchiveLogFactory is a LogFactory archiving the messages sent and received by sessions, independently of the MessageStore.
+//Messages are gathered into gzip compressed logs per session and UTC day, passed to a MessageArchiver in the background.
+type ArchiveLogFactory struct {
+	logFactory LogFactory
+	archiver   MessageArchiver
+
+	prefix     string
+	maxSize    int
+	interval   time.Duration
+	queueDepth int
+	overflow   archiveOverflow
+
+	retryInterval time.Duration
+
+	lock     sync.Mutex
+	changed  *sync.Cond
+	queue    []archiveFile
+	failures int
+	logs     []*archiveLog
+
+	//closing stops retries and queue limits, drained stops the archiver once the queue is empty
+	closing bool
+	drained bool
+	stop    chan struct{}
+	done    chan struct{}
+	err     error
+}
+
+//NewArchiveLogFactory returns a LogFactory whose session logs write to the logs of logFactory and archive messages with archiver.
+//A session's log is sealed and queued for archival at the end of each UTC day, when it holds ArchiveMaxSize bytes of messages,
+//or ArchiveInterval seconds after its first message. Logs are archived under keys ArchivePrefix/YYYY/MM/DD/<session>/<time>.log.gz.
+//At most ArchiveQueueDepth logs are queued, ArchiveOverflow determines whether logging then blocks or logs are dropped.
+//Close must be called to archive the messages logged since the last log was sealed.
+func NewArchiveLogFactory(logFactory LogFactory, archiver MessageArchiver, settings *Settings) (*ArchiveLogFactory, error) {
+	f, err := newArchiveLogFactory(logFactory, archiver, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	f.start()
+	return f, nil
+}
+
+func newArchiveLogFactory(logFactory LogFactory, archiver MessageArchiver, settings *Settings) (*ArchiveLogFactory, error) {
+	f := &ArchiveLogFactory{
+		logFactory:    logFactory,
+		archiver:      archiver,
+		maxSize:       defaultArchiveMaxSize,
+		interval:      defaultArchiveInterval,
+		queueDepth:    defaultArchiveQueueDepth,
+		retryInterval: archiveRetryInterval,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	f.changed = sync.NewCond(&f.lock)
+
+	globalSettings := settings.GlobalSettings()
+	if globalSettings.HasSetting(config.ArchivePrefix) {
+		f.prefix, _ = globalSettings.Setting(config.ArchivePrefix)
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value *int
+	}{
+		{config.ArchiveMaxSize, &f.maxSize},
+		{config.ArchiveQueueDepth, &f.queueDepth},
+	} {
+		if !globalSettings.HasSetting(setting.name) {
+			continue
+		}
+
+		var err error
+		if *setting.value, err = globalSettings.IntSetting(setting.name); err != nil {
+			return nil, err
+		}
+
+		if *setting.value < 1 {
+			return nil, fmt.Errorf("invalid %v %v, expected at least 1", setting.name, *setting.value)
+		}
+	}
+
+	if globalSettings.HasSetting(config.ArchiveInterval) {
+		seconds, err := globalSettings.IntSetting(config.ArchiveInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		if seconds < 1 {
+			return nil, fmt.Errorf("invalid %v %v, expected at least 1", config.ArchiveInterval, seconds)
+		}
+		f.interval = time.Duration(seconds) * time.Second
+	}
+
+	if globalSettings.HasSetting(config.ArchiveOverflow) {
+		overflow, _ := globalSettings.Setting(config.ArchiveOverflow)
+
+		var err error
+		if f.overflow, err = parseArchiveOverflow(overflow); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+//start starts the background archiver and sealing of expired logs.
+func (f *ArchiveLogFactory) start() {
+	go f.run()
+	go f.sealExpired()
+}
+
+//Create returns the global log of the wrapped LogFactory, global events hold no messages to archive.
+func (f *ArchiveLogFactory) Create() (Log, error) {
+	return f.logFactory.Create()
+}
+
+//CreateSessionLog returns a Log archiving the messages of sessionID.
+func (f *ArchiveLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	log, err := f.logFactory.CreateSessionLog(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &archiveLog{Log: log, factory: f, name: sessionFilePrefix(sessionID)}
+
+	f.lock.Lock()
+	f.logs = append(f.logs, l)
+	f.lock.Unlock()
+
+	return l, nil
+}
+
+//Lag returns the message logs waiting to be archived.
+func (f *ArchiveLogFactory) Lag() ArchiveLag {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	lag := ArchiveLag{Pending: len(f.queue), Failures: f.failures}
+	if len(f.queue) > 0 {
+		lag.Oldest = f.queue[0].first
+	}
+
+	return lag
+}
+
+//Close seals the logs of all sessions and waits for the queued logs to be archived.
+//Each queued log is attempted once more, returns the first error of the logs dropped.
+//Messages logged after Close are not archived.
+func (f *ArchiveLogFactory) Close() error {
+	f.lock.Lock()
+	if f.closing {
+		f.lock.Unlock()
+		<-f.done
+		return f.closeErr()
+	}
+
+	f.closing = true
+	logs := f.logs
+	f.changed.Broadcast()
+	f.lock.Unlock()
+
+	close(f.stop)
+	for _, l := range logs {
+		l.seal()
+	}
+
+	f.lock.Lock()
+	f.drained = true
+	f.changed.Broadcast()
+	f.lock.Unlock()
+
+	<-f.done
+	return f.closeErr()
+}
+
+func (f *ArchiveLogFactory) closeErr() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.err
+}
+
+func (f *ArchiveLogFactory) isClosing() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.closing
+}
+
+//enqueue queues file to be archived, subject to ArchiveOverflow.
+func (f *ArchiveLogFactory) enqueue(file archiveFile) {
+	f.lock.Lock()
+	for len(f.queue) >= f.queueDepth && f.overflow == archiveOverflowBlock && !f.closing {
+		f.changed.Wait()
+	}
+
+	if len(f.queue) >= f.queueDepth && !f.closing {
+		f.lock.Unlock()
+		f.dropped(file.key, fmt.Errorf("archive queue full, %d logs pending", f.queueDepth))
+		return
+	}
+
+	f.queue = append(f.queue, file)
+	f.changed.Broadcast()
+	f.lock.Unlock()
+}
+
+//run is the background archiver, archiving queued logs in order and retrying failures with backoff.
+func (f *ArchiveLogFactory) run() {
+	defer close(f.done)
+
+	retryInterval := f.retryInterval
+	for {
+		f.lock.Lock()
+		for len(f.queue) == 0 && !f.drained {
+			f.changed.Wait()
+		}
+
+		if len(f.queue) == 0 {
+			f.lock.Unlock()
+			return
+		}
+
+		file, closing := f.queue[0], f.closing
+		f.lock.Unlock()
+
+		err := f.archiver.Archive(file.key, file.log)
+
+		f.lock.Lock()
+		if err == nil || closing {
+			f.queue = f.queue[1:]
+			f.failures = 0
+			f.changed.Broadcast()
+		} else {
+			f.failures++
+		}
+
+		if err != nil && closing && f.err == nil {
+			f.err = fmt.Errorf("cannot archive %v: %v", file.key, err)
+		}
+		f.lock.Unlock()
+
+		switch {
+		case err == nil:
+			retryInterval = f.retryInterval
+
+		case closing:
+			f.dropped(file.key, err)
+
+		default:
+			if listener, ok := f.archiver.(ArchiveListener); ok {
+				listener.OnArchiveError(file.key, err)
+			}
+
+			select {
+			case <-time.After(retryInterval):
+			case <-f.stop:
+			}
+
+			if retryInterval *= 2; retryInterval > archiveMaxRetryInterval {
+				retryInterval = archiveMaxRetryInterval
+			}
+		}
+	}
+}
+
+func (f *ArchiveLogFactory) dropped(key string, err error) {
+	if listener, ok := f.archiver.(ArchiveListener); ok {
+		listener.OnArchiveDropped(key, err)
+	}
+}
+
+//sealExpired seals the logs holding messages for longer than ArchiveInterval.
+func (f *ArchiveLogFactory) sealExpired() {
+	ticker := time.NewTicker(f.interval / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+
+		case now := <-ticker.C:
+			f.lock.Lock()
+			logs := f.logs
+			f.lock.Unlock()
+
+			for _, l := range logs {
+				l.sealBefore(now.Add(-f.interval))
+			}
+		}
+	}
+}
+
+//archiveLog is a session Log gathering the messages logged into a compressed log for archival.
+type archiveLog struct {
+	Log
+
+	factory *ArchiveLogFactory
+	name    string
+
+	lock  sync.Mutex
+	day   string
+	first time.Time
+	size  int
+	buf   bytes.Buffer
+	gz    *gzip.Writer
+}
+
+func (l *archiveLog) OnIncoming(msg string) {
+	l.Log.OnIncoming(msg)
+	l.archive("IN", msg)
+}
+
+func (l *archiveLog) OnOutgoing(msg string) {
+	l.Log.OnOutgoing(msg)
+	l.archive("OUT", msg)
+}
+
+//archive adds msg to the current log, one line per message prefixed by the time logged and direction.
+func (l *archiveLog) archive(direction, msg string) {
+	now := time.Now().UTC()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.factory.isClosing() {
+		return
+	}
+
+	day := now.Format(archiveDayLayout)
+	if l.gz != nil && day != l.day {
+		l.sealLocked()
+	}
+
+	if l.gz == nil {
+		l.day, l.first, l.size = day, now, 0
+		l.gz = gzip.NewWriter(&l.buf)
+	}
+
+	n, _ := fmt.Fprintf(l.gz, "%v %v %v\n", now.Format(archiveTimestampLayout), direction, msg)
+	if l.size += n; l.size >= l.factory.maxSize {
+		l.sealLocked()
+	}
+}
+
+func (l *archiveLog) seal() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.sealLocked()
+}
+
+//sealBefore seals the current log if its first message was logged before cutoff.
+func (l *archiveLog) sealBefore(cutoff time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.gz != nil && l.first.Before(cutoff) {
+		l.sealLocked()
+	}
+}
+
+//sealLocked completes the current log and queues it for archival, blocking while the queue is full with ArchiveOverflow Block.
+func (l *archiveLog) sealLocked() {
+	if l.gz == nil {
+		return
+	}
+
+	l.gz.Close()
+	file := archiveFile{
+		key:   path.Join(l.factory.prefix, l.day, l.name, l.first.Format("150405.000000000")+".log.gz"),
+		log:   append([]byte(nil), l.buf.Bytes()...),
+		first: l.first,
+	}
+
+	l.gz = nil
+	l.buf.Reset()
+
+	l.factory.enqueue(file)
+}
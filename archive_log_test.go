@@ -0,0 +1,203 @@
+package quickfix
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingArchiver struct {
+	lock     sync.Mutex
+	logs     map[string][]byte
+	failures int
+	errors   []string
+	dropped  []string
+}
+
+func newRecordingArchiver(failures int) *recordingArchiver {
+	return &recordingArchiver{logs: make(map[string][]byte), failures: failures}
+}
+
+func (a *recordingArchiver) Archive(key string, log []byte) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.failures > 0 {
+		a.failures--
+		return errors.New("unavailable")
+	}
+
+	a.logs[key] = log
+	return nil
+}
+
+func (a *recordingArchiver) OnArchiveError(key string, err error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.errors = append(a.errors, key)
+}
+
+func (a *recordingArchiver) OnArchiveDropped(key string, err error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.dropped = append(a.dropped, key)
+}
+
+func gunzipLines(t *testing.T, log []byte) []string {
+	r, err := gzip.NewReader(bytes.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+func newTestArchiveLogFactory(t *testing.T, archiver MessageArchiver, settings map[string]string) *ArchiveLogFactory {
+	s := NewSettings()
+	for k, v := range settings {
+		s.GlobalSettings().Set(k, v)
+	}
+
+	f, err := newArchiveLogFactory(NewNullLogFactory(), archiver, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.retryInterval = time.Millisecond
+	f.start()
+
+	return f
+}
+
+func TestArchiveLogFactory_Settings(t *testing.T) {
+	for _, tc := range []struct{ setting, value string }{
+		{config.ArchiveMaxSize, "0"},
+		{config.ArchiveQueueDepth, "x"},
+		{config.ArchiveInterval, "0"},
+		{config.ArchiveOverflow, "Never"},
+	} {
+		s := NewSettings()
+		s.GlobalSettings().Set(tc.setting, tc.value)
+		if _, err := NewArchiveLogFactory(NewNullLogFactory(), newRecordingArchiver(0), s); err == nil {
+			t.Errorf("Expected error for %v=%v", tc.setting, tc.value)
+		}
+	}
+}
+
+func TestArchiveLogFactory_ArchiveOnClose(t *testing.T) {
+	archiver := newRecordingArchiver(0)
+	f := newTestArchiveLogFactory(t, archiver, map[string]string{config.ArchivePrefix: "fix"})
+
+	log, err := f.CreateSessionLog(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.OnOutgoing("8=FIX.4.2\x0135=A\x01")
+	log.OnIncoming("8=FIX.4.2\x0135=0\x01")
+	log.OnEvent("not archived")
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(archiver.logs) != 1 {
+		t.Fatalf("Expected 1 archived log, got %v", len(archiver.logs))
+	}
+
+	for key, archived := range archiver.logs {
+		if !strings.HasPrefix(key, "fix/"+time.Now().UTC().Format(archiveDayLayout)+"/FIX.4.2-TW-ISLD/") || !strings.HasSuffix(key, ".log.gz") {
+			t.Errorf("Unexpected key %v", key)
+		}
+
+		lines := gunzipLines(t, archived)
+		if len(lines) != 2 || !strings.HasSuffix(lines[0], " OUT 8=FIX.4.2\x0135=A\x01") || !strings.HasSuffix(lines[1], " IN 8=FIX.4.2\x0135=0\x01") {
+			t.Errorf("Unexpected log %q", lines)
+		}
+	}
+
+	log.OnIncoming("8=FIX.4.2\x0135=0\x01")
+	if err := f.Close(); err != nil || len(archiver.logs) != 1 {
+		t.Errorf("Expected no archive after Close, got %v logs, %v", len(archiver.logs), err)
+	}
+}
+
+func TestArchiveLogFactory_SealAtMaxSize(t *testing.T) {
+	archiver := newRecordingArchiver(0)
+	f := newTestArchiveLogFactory(t, archiver, map[string]string{config.ArchiveMaxSize: "1"})
+
+	log, _ := f.CreateSessionLog(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"})
+	for i := 0; i < 3; i++ {
+		log.OnOutgoing("8=FIX.4.2\x01")
+		time.Sleep(time.Millisecond)
+	}
+
+	f.Close()
+	if len(archiver.logs) != 3 {
+		t.Errorf("Expected a log per message, got %v", len(archiver.logs))
+	}
+}
+
+func TestArchiveLogFactory_RetryFailures(t *testing.T) {
+	archiver := newRecordingArchiver(2)
+	f := newTestArchiveLogFactory(t, archiver, map[string]string{config.ArchiveMaxSize: "1"})
+
+	log, _ := f.CreateSessionLog(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"})
+	log.OnOutgoing("8=FIX.4.2\x01")
+
+	for i := 0; f.Lag().Pending > 0 && i < 1000; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(archiver.errors) != 2 || len(archiver.logs) != 1 {
+		t.Errorf("Expected 2 errors then archive, got %v errors and %v logs", len(archiver.errors), len(archiver.logs))
+	}
+
+	if lag := f.Lag(); lag.Pending != 0 || lag.Failures != 0 {
+		t.Errorf("Expected no lag, got %+v", lag)
+	}
+}
+
+func TestArchiveLogFactory_DropWhenFull(t *testing.T) {
+	archiver := newRecordingArchiver(1000000)
+	s := NewSettings()
+	s.GlobalSettings().Set(config.ArchiveMaxSize, "1")
+	s.GlobalSettings().Set(config.ArchiveQueueDepth, "1")
+	s.GlobalSettings().Set(config.ArchiveOverflow, "Drop")
+
+	f, err := NewArchiveLogFactory(NewNullLogFactory(), archiver, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, _ := f.CreateSessionLog(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"})
+	log.OnOutgoing("8=FIX.4.2\x01")
+	time.Sleep(time.Millisecond)
+	log.OnOutgoing("8=FIX.4.2\x01")
+
+	lag := f.Lag()
+	if lag.Pending != 1 || lag.Oldest.IsZero() {
+		t.Errorf("Expected 1 pending log, got %+v", lag)
+	}
+
+	if err := f.Close(); err == nil {
+		t.Error("Expected error closing with unarchived logs")
+	}
+
+	if len(archiver.dropped) != 2 {
+		t.Errorf("Expected both logs dropped, got %v", archiver.dropped)
+	}
+}
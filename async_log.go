@@ -0,0 +1,239 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"sync"
+)
+
+const defaultAsyncLogQueueDepth = 4096
+
+//asyncLogOverflow determines how a record is handled when AsyncLogQueueDepth records are waiting to be written.
+type asyncLogOverflow int
+
+const (
+	//asyncLogOverflowBlock blocks logging until the queue has room, slowing the sessions logging.
+	asyncLogOverflowBlock asyncLogOverflow = iota
+
+	//asyncLogOverflowDrop discards the record, counted by AsyncLogFactory.Dropped.
+	asyncLogOverflowDrop
+)
+
+//parseAsyncLogOverflow maps the AsyncLogOverflow setting to an asyncLogOverflow.
+func parseAsyncLogOverflow(setting string) (asyncLogOverflow, error) {
+	switch setting {
+	case "Block":
+		return asyncLogOverflowBlock, nil
+	case "Drop":
+		return asyncLogOverflowDrop, nil
+	}
+
+	return asyncLogOverflowBlock, fmt.Errorf("invalid AsyncLogOverflow %v, expected Block or Drop", setting)
+}
+
+type asyncLogRecordType int
+
+const (
+	asyncLogIncoming asyncLogRecordType = iota
+	asyncLogOutgoing
+	asyncLogEvent
+	asyncLogLeveledEvent
+)
+
+//asyncLogRecord is a record logged but not yet written, or a flush waiting for the records queued before it.
+type asyncLogRecord struct {
+	log        Log
+	recordType asyncLogRecordType
+	msg        string
+	flushed    chan struct{}
+
+	//level and category of events
+	level    LogLevel
+	category LogCategory
+}
+
+//AsyncLogFactory is a LogFactory whose logs queue records for a background writer, keeping writes to the logs of the wrapped factory
+//off the session goroutines. Records are written in the order logged.
+type AsyncLogFactory struct {
+	logFactory LogFactory
+	overflow   asyncLogOverflow
+	queue      chan asyncLogRecord
+	done       chan struct{}
+
+	//lock guards closed and dropped, held shared while queuing so Close waits for the records being queued
+	lock    sync.RWMutex
+	closed  bool
+	dropped int
+}
+
+//NewAsyncLogFactory returns a LogFactory whose logs write to the logs of logFactory from a background writer.
+//At most AsyncLogQueueDepth records are queued, AsyncLogOverflow determines whether logging then blocks or records are dropped.
+//The time of a record is the time it is written by the logs of logFactory, delayed by the records queued before it.
+//Close must be called to write the queued records and stop the writer.
+func NewAsyncLogFactory(logFactory LogFactory, settings *Settings) (*AsyncLogFactory, error) {
+	queueDepth := defaultAsyncLogQueueDepth
+	overflow := asyncLogOverflowBlock
+
+	globalSettings := settings.GlobalSettings()
+	if globalSettings.HasSetting(config.AsyncLogQueueDepth) {
+		var err error
+		if queueDepth, err = globalSettings.IntSetting(config.AsyncLogQueueDepth); err != nil {
+			return nil, err
+		}
+
+		if queueDepth < 1 {
+			return nil, fmt.Errorf("invalid %v %v, expected at least 1", config.AsyncLogQueueDepth, queueDepth)
+		}
+	}
+
+	if globalSettings.HasSetting(config.AsyncLogOverflow) {
+		setting, _ := globalSettings.Setting(config.AsyncLogOverflow)
+
+		var err error
+		if overflow, err = parseAsyncLogOverflow(setting); err != nil {
+			return nil, err
+		}
+	}
+
+	f := &AsyncLogFactory{
+		logFactory: logFactory,
+		overflow:   overflow,
+		queue:      make(chan asyncLogRecord, queueDepth),
+		done:       make(chan struct{}),
+	}
+	go f.run()
+
+	return f, nil
+}
+
+//Create returns the global log of the wrapped factory, writing in the background.
+func (f *AsyncLogFactory) Create() (Log, error) {
+	log, err := f.logFactory.Create()
+	if err != nil {
+		return nil, err
+	}
+
+	return asyncLog{factory: f, log: log}, nil
+}
+
+//CreateSessionLog returns the log of sessionID of the wrapped factory, writing in the background.
+func (f *AsyncLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	log, err := f.logFactory.CreateSessionLog(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return asyncLog{factory: f, log: log}, nil
+}
+
+//Dropped returns the number of records discarded with AsyncLogOverflow=Drop.
+func (f *AsyncLogFactory) Dropped() int {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.dropped
+}
+
+//Flush returns once the records logged before the call are written.
+func (f *AsyncLogFactory) Flush() {
+	f.lock.RLock()
+	if f.closed {
+		f.lock.RUnlock()
+		return
+	}
+
+	flushed := make(chan struct{})
+	f.queue <- asyncLogRecord{flushed: flushed}
+	f.lock.RUnlock()
+
+	<-flushed
+}
+
+//Close writes the queued records and stops the writer. Records logged after Close are written by the caller.
+func (f *AsyncLogFactory) Close() {
+	f.lock.Lock()
+	if !f.closed {
+		f.closed = true
+		close(f.queue)
+	}
+	f.lock.Unlock()
+
+	<-f.done
+}
+
+//enqueue queues record subject to AsyncLogOverflow, or writes it once the factory is closed.
+func (f *AsyncLogFactory) enqueue(record asyncLogRecord) {
+	f.lock.RLock()
+	if f.closed {
+		f.lock.RUnlock()
+		record.write()
+		return
+	}
+
+	if f.overflow == asyncLogOverflowBlock {
+		f.queue <- record
+		f.lock.RUnlock()
+		return
+	}
+
+	select {
+	case f.queue <- record:
+		f.lock.RUnlock()
+	default:
+		f.lock.RUnlock()
+
+		f.lock.Lock()
+		f.dropped++
+		f.lock.Unlock()
+	}
+}
+
+func (f *AsyncLogFactory) run() {
+	defer close(f.done)
+
+	for record := range f.queue {
+		if record.flushed != nil {
+			close(record.flushed)
+			continue
+		}
+
+		record.write()
+	}
+}
+
+func (r asyncLogRecord) write() {
+	switch r.recordType {
+	case asyncLogIncoming:
+		r.log.OnIncoming(r.msg)
+	case asyncLogOutgoing:
+		r.log.OnOutgoing(r.msg)
+	case asyncLogEvent:
+		r.log.OnEvent(r.msg)
+	case asyncLogLeveledEvent:
+		logEventf(r.log, r.level, r.category, "%s", r.msg)
+	}
+}
+
+type asyncLog struct {
+	factory *AsyncLogFactory
+	log     Log
+}
+
+func (l asyncLog) OnIncoming(msg string) {
+	l.factory.enqueue(asyncLogRecord{log: l.log, recordType: asyncLogIncoming, msg: msg})
+}
+
+func (l asyncLog) OnOutgoing(msg string) {
+	l.factory.enqueue(asyncLogRecord{log: l.log, recordType: asyncLogOutgoing, msg: msg})
+}
+
+func (l asyncLog) OnEvent(msg string) {
+	l.factory.enqueue(asyncLogRecord{log: l.log, recordType: asyncLogEvent, msg: msg})
+}
+
+func (l asyncLog) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+func (l asyncLog) OnLeveledEvent(level LogLevel, category LogCategory, msg string) {
+	l.factory.enqueue(asyncLogRecord{log: l.log, recordType: asyncLogLeveledEvent, msg: msg, level: level, category: category})
+}
@@ -0,0 +1,94 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"testing"
+)
+
+//gatedLog records messages once its gate is opened.
+type gatedLog struct {
+	recordingLog
+	gate chan struct{}
+}
+
+func (l *gatedLog) OnIncoming(s string) {
+	<-l.gate
+	l.recordingLog.OnIncoming(s)
+}
+
+type gatedLogFactory struct {
+	log *gatedLog
+}
+
+func (f gatedLogFactory) Create() (Log, error)                    { return f.log, nil }
+func (f gatedLogFactory) CreateSessionLog(SessionID) (Log, error) { return f.log, nil }
+
+func newTestAsyncLogFactory(t *testing.T, queueDepth, overflow string) (*AsyncLogFactory, *gatedLog) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.AsyncLogQueueDepth, queueDepth)
+	settings.GlobalSettings().Set(config.AsyncLogOverflow, overflow)
+
+	log := &gatedLog{gate: make(chan struct{})}
+	factory, err := NewAsyncLogFactory(gatedLogFactory{log}, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return factory, log
+}
+
+func TestAsyncLog_Settings(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.AsyncLogOverflow, "Spill")
+	if _, err := NewAsyncLogFactory(NewNullLogFactory(), settings); err == nil {
+		t.Error("Expected error for AsyncLogOverflow Spill")
+	}
+
+	settings.GlobalSettings().Set(config.AsyncLogOverflow, "Drop")
+	settings.GlobalSettings().Set(config.AsyncLogQueueDepth, "0")
+	if _, err := NewAsyncLogFactory(NewNullLogFactory(), settings); err == nil {
+		t.Error("Expected error for AsyncLogQueueDepth 0")
+	}
+}
+
+func TestAsyncLog_Flush(t *testing.T) {
+	factory, gated := newTestAsyncLogFactory(t, "10", "Block")
+	log, _ := factory.CreateSessionLog(SessionID{})
+
+	for _, msg := range []string{"hello", "cruel", "world"} {
+		log.OnIncoming(msg)
+	}
+
+	if len(gated.messages) != 0 {
+		t.Fatal("Expected messages written in the background")
+	}
+
+	close(gated.gate)
+	factory.Flush()
+	if len(gated.messages) != 3 || gated.messages[2] != "world" {
+		t.Errorf("Expected messages written in order by Flush, got %v", gated.messages)
+	}
+
+	factory.Close()
+	log.OnIncoming("closed")
+	if len(gated.messages) != 4 {
+		t.Errorf("Expected message written by the caller once closed, got %v", gated.messages)
+	}
+}
+
+func TestAsyncLog_Drop(t *testing.T) {
+	factory, gated := newTestAsyncLogFactory(t, "2", "Drop")
+	log, _ := factory.Create()
+
+	//the writer holds the first message at the gate, the next 2 are queued
+	for i := 0; i < 6; i++ {
+		log.OnIncoming("msg")
+	}
+
+	close(gated.gate)
+	factory.Close()
+
+	if len(gated.messages)+factory.Dropped() != 6 || factory.Dropped() < 3 {
+		t.Errorf("Expected messages beyond the queue dropped, got %v written and %v dropped", len(gated.messages), factory.Dropped())
+	}
+}
@@ -0,0 +1,329 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"time"
+)
+
+const (
+	defaultAsyncStoreQueueDepth = 1024
+	defaultAsyncStoreMaxBatch   = 100
+)
+
+//asyncWrite is a message saved but not yet written to the underlying store.
+type asyncWrite struct {
+	seqNum int
+	msg    []byte
+}
+
+//asyncStore is a MessageStore acknowledging SaveMessage once queued, the messages are written to the underlying store by a background writer.
+//Messages queued but not yet written are lost if the process stops, at most queueDepth messages held for at most flushInterval.
+type asyncStore struct {
+	MessageStore
+
+	queue   chan asyncWrite
+	flushes chan chan error
+
+	//maxBatch messages are written together, followed by a Flush of the underlying store if a FlushableStore
+	maxBatch int
+
+	//flushInterval is the time a write may wait for more writes to batch with, zero to write as soon as the writer is idle
+	flushInterval time.Duration
+
+	//writeErr keeps the error of the first message the writer failed to write, or of a failed flush, refusing the messages saved after it until Reset
+	writeErr ErrorLatch
+}
+
+type asyncStoreFactory struct {
+	storeFactory MessageStoreFactory
+	settings     *Settings
+}
+
+//NewAsyncStoreFactory returns a MessageStoreFactory wrapping the MessageStores of storeFactory with a write-behind queue.
+//SaveMessage returns once the message is queued, blocking only while AsyncStoreQueueDepth messages are queued.
+//A background writer writes up to AsyncStoreMaxBatch queued messages at a time, waiting up to AsyncStoreFlushInterval milliseconds for a batch to fill.
+//The queue is drained before messages are read for resend and each time the session disconnects.
+func NewAsyncStoreFactory(storeFactory MessageStoreFactory, settings *Settings) (MessageStoreFactory, error) {
+	for _, sessionSettings := range settings.SessionSettings() {
+		if _, _, _, err := asyncStoreSettings(sessionSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	return asyncStoreFactory{storeFactory: storeFactory, settings: settings}, nil
+}
+
+func (f asyncStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
+	store, err := f.storeFactory.Create(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	queueDepth, maxBatch, flushInterval := defaultAsyncStoreQueueDepth, defaultAsyncStoreMaxBatch, time.Duration(0)
+	if _, ok := f.settings.sessionSettings[sessionID]; ok {
+		if queueDepth, maxBatch, flushInterval, err = asyncStoreSettings(f.settings.sessionSettingsFor(sessionID)); err != nil {
+			return nil, err
+		}
+	}
+
+	return newAsyncStore(store, queueDepth, maxBatch, flushInterval), nil
+}
+
+//asyncStoreSettings parses AsyncStoreQueueDepth, AsyncStoreMaxBatch, and AsyncStoreFlushInterval.
+func asyncStoreSettings(settings *SessionSettings) (queueDepth, maxBatch int, flushInterval time.Duration, err error) {
+	queueDepth, maxBatch = defaultAsyncStoreQueueDepth, defaultAsyncStoreMaxBatch
+
+	for _, setting := range []struct {
+		name  string
+		value *int
+	}{
+		{config.AsyncStoreQueueDepth, &queueDepth},
+		{config.AsyncStoreMaxBatch, &maxBatch},
+	} {
+		if !settings.HasSetting(setting.name) {
+			continue
+		}
+
+		if *setting.value, err = settings.IntSetting(setting.name); err != nil {
+			return
+		}
+
+		if *setting.value < 1 {
+			err = fmt.Errorf("invalid %v %v, expected at least 1", setting.name, *setting.value)
+			return
+		}
+	}
+
+	if settings.HasSetting(config.AsyncStoreFlushInterval) {
+		var millis int
+		if millis, err = settings.IntSetting(config.AsyncStoreFlushInterval); err != nil {
+			return
+		}
+		flushInterval = time.Duration(millis) * time.Millisecond
+	}
+
+	return
+}
+
+func newAsyncStore(store MessageStore, queueDepth, maxBatch int, flushInterval time.Duration) *asyncStore {
+	s := &asyncStore{
+		MessageStore:  store,
+		queue:         make(chan asyncWrite, queueDepth),
+		flushes:       make(chan chan error),
+		maxBatch:      maxBatch,
+		flushInterval: flushInterval,
+	}
+
+	go s.run()
+	return s
+}
+
+//run is the background writer.
+func (s *asyncStore) run() {
+	batch := make([]asyncWrite, 0, s.maxBatch)
+
+	for {
+		select {
+		case write := <-s.queue:
+			batch = s.fill(append(batch[:0], write))
+			s.write(batch)
+
+		case done := <-s.flushes:
+			for drained := false; !drained; {
+				batch = batch[:0]
+				for len(batch) < s.maxBatch && !drained {
+					select {
+					case write := <-s.queue:
+						batch = append(batch, write)
+					default:
+						drained = true
+					}
+				}
+				s.write(batch)
+			}
+
+			done <- s.writeErr.Err()
+		}
+	}
+}
+
+//fill adds queued writes to batch until it holds maxBatch, waiting up to flushInterval for more.
+func (s *asyncStore) fill(batch []asyncWrite) []asyncWrite {
+	var timeout <-chan time.Time
+	if s.flushInterval > 0 {
+		timer := time.NewTimer(s.flushInterval)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for len(batch) < s.maxBatch {
+		if timeout == nil {
+			select {
+			case write := <-s.queue:
+				batch = append(batch, write)
+				continue
+			default:
+				return batch
+			}
+		}
+
+		select {
+		case write := <-s.queue:
+			batch = append(batch, write)
+		case <-timeout:
+			return batch
+		}
+	}
+
+	return batch
+}
+
+//write saves batch to the underlying store, then flushes it.
+func (s *asyncStore) write(batch []asyncWrite) {
+	if len(batch) == 0 {
+		return
+	}
+
+	for _, write := range batch {
+		if err := saveMessage(s.MessageStore, write.seqNum, write.msg); err != nil {
+			s.writeErr.Set(fmt.Errorf("cannot store message %v: %v", write.seqNum, err))
+		}
+	}
+
+	if flushable, ok := s.MessageStore.(FlushableStore); ok {
+		s.writeErr.Set(flushable.Flush())
+	}
+}
+
+//TrySaveMessage queues msg to be written, failing if a previous write failed.
+func (s *asyncStore) TrySaveMessage(seqNum int, msg []byte) error {
+	if err := s.writeErr.Err(); err != nil {
+		return err
+	}
+
+	s.queue <- asyncWrite{seqNum: seqNum, msg: msg}
+	return nil
+}
+
+//SaveMessage queues msg, dropping it if a previous write failed.
+func (s *asyncStore) SaveMessage(seqNum int, msg []byte) {
+	s.writeErr.Set(s.TrySaveMessage(seqNum, msg))
+}
+
+//Flush waits until the queued messages are written and the underlying store is flushed, returning the first write error.
+func (s *asyncStore) Flush() error {
+	done := make(chan error)
+	s.flushes <- done
+	return <-done
+}
+
+//QueueDepth returns the number of messages queued but not yet written.
+func (s *asyncStore) QueueDepth() int {
+	return len(s.queue)
+}
+
+//queueCapacity returns the number of messages that may be queued, AsyncStoreQueueDepth.
+func (s *asyncStore) queueCapacity() int {
+	return cap(s.queue)
+}
+
+//Health returns the first write error, otherwise the health of the underlying store if a HealthStore.
+func (s *asyncStore) Health() error {
+	if err := s.writeErr.Err(); err != nil {
+		return err
+	}
+
+	if store, ok := s.MessageStore.(HealthStore); ok {
+		return store.Health()
+	}
+
+	return nil
+}
+
+//GetMessages returns the messages of the underlying store, once queued messages are written.
+func (s *asyncStore) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
+	s.Flush()
+	return s.MessageStore.GetMessages(beginSeqNum, endSeqNum)
+}
+
+//Reset resets the underlying store once queued messages are written, clearing any write error.
+func (s *asyncStore) Reset() {
+	s.Flush()
+
+	s.writeErr.Clear()
+
+	s.MessageStore.Reset()
+}
+
+//Refresh refreshes the underlying store once queued messages are written.
+func (s *asyncStore) Refresh() {
+	s.Flush()
+	s.MessageStore.Refresh()
+}
+
+//Compact compacts the underlying store once queued messages are written.
+func (s *asyncStore) Compact(seqNum int) error {
+	store, ok := s.MessageStore.(CompactableStore)
+	if !ok {
+		return fmt.Errorf("underlying store does not support compaction")
+	}
+
+	if err := s.Flush(); err != nil {
+		return err
+	}
+
+	return store.Compact(seqNum)
+}
+
+//SaveReceivedMessage saves msg to the underlying store, received messages are not queued.
+func (s *asyncStore) SaveReceivedMessage(seqNum int, msg []byte) error {
+	store, ok := s.MessageStore.(ReceivedMessageStore)
+	if !ok {
+		return fmt.Errorf("underlying store does not support saving messages received")
+	}
+
+	return store.SaveReceivedMessage(seqNum, msg)
+}
+
+func (s *asyncStore) GetReceivedMessages(beginSeqNum, endSeqNum int) chan []byte {
+	if store, ok := s.MessageStore.(ReceivedMessageStore); ok {
+		return store.GetReceivedMessages(beginSeqNum, endSeqNum)
+	}
+
+	msgs := make(chan []byte)
+	close(msgs)
+	return msgs
+}
+
+func (s *asyncStore) SaveRecoveryState(state RecoveryState) error {
+	if store, ok := s.MessageStore.(RecoveryStore); ok {
+		return store.SaveRecoveryState(state)
+	}
+
+	return nil
+}
+
+func (s *asyncStore) RecoveryState() (RecoveryState, error) {
+	if store, ok := s.MessageStore.(RecoveryStore); ok {
+		return store.RecoveryState()
+	}
+
+	return RecoveryState{}, nil
+}
+
+func (s *asyncStore) SaveDeadLetter(deadLetter DeadLetter) error {
+	if store, ok := s.MessageStore.(DeadLetterStore); ok {
+		return store.SaveDeadLetter(deadLetter)
+	}
+
+	return nil
+}
+
+func (s *asyncStore) DeadLetters() ([]DeadLetter, error) {
+	if store, ok := s.MessageStore.(DeadLetterStore); ok {
+		return store.DeadLetters()
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,126 @@
+package quickfix
+
+import (
+	"errors"
+	"github.com/quickfixgo/quickfix/config"
+	"testing"
+	"time"
+)
+
+//blockingStore is a memoryStore whose writes wait for release, failing with saveErr.
+type blockingStore struct {
+	*memoryStore
+	release chan interface{}
+	saveErr error
+	flushes int
+}
+
+func newBlockingStore() *blockingStore {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	return &blockingStore{memoryStore: store.(*memoryStore), release: make(chan interface{})}
+}
+
+func (s *blockingStore) TrySaveMessage(seqNum int, msg []byte) error {
+	<-s.release
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+
+	s.memoryStore.SaveMessage(seqNum, msg)
+	return nil
+}
+
+func (s *blockingStore) Flush() error {
+	s.flushes++
+	return nil
+}
+
+func TestAsyncStore_WriteBehind(t *testing.T) {
+	underlying := newBlockingStore()
+	store := newAsyncStore(underlying, 10, 5, 0)
+
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		if err := saveMessage(store, seqNum, []byte("msg")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	flushed := make(chan error)
+	go func() { flushed <- store.Flush() }()
+
+	select {
+	case <-flushed:
+		t.Fatal("Expected Flush to wait for queued writes")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(underlying.release)
+	if err := <-flushed; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(underlying.messageMap) != 3 {
+		t.Errorf("Expected 3 messages written got %v", len(underlying.messageMap))
+	}
+
+	if underlying.flushes == 0 {
+		t.Error("Expected underlying store flushed")
+	}
+
+	count := 0
+	for range store.GetMessages(1, 3) {
+		count++
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 messages got %v", count)
+	}
+
+	if _, ok := interface{}(store).(RecoveryStore); !ok {
+		t.Error("Expected RecoveryStore of underlying store")
+	}
+}
+
+func TestAsyncStore_WriteError(t *testing.T) {
+	underlying := newBlockingStore()
+	close(underlying.release)
+	underlying.saveErr = errors.New("disk full")
+	store := newAsyncStore(underlying, 10, 5, time.Millisecond)
+
+	if err := saveMessage(store, 1, []byte("msg")); err != nil {
+		t.Fatal("Expected SaveMessage to succeed before the write fails")
+	}
+
+	if err := store.Flush(); err == nil {
+		t.Error("Expected Flush to return the write error")
+	}
+
+	if err := saveMessage(store, 2, []byte("msg")); err == nil {
+		t.Error("Expected SaveMessage to fail after the write error")
+	}
+
+	underlying.saveErr = nil
+	store.Reset()
+	if err := saveMessage(store, 1, []byte("msg")); err != nil {
+		t.Error("Expected Reset to clear the write error", err)
+	}
+}
+
+func TestAsyncStore_Settings(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set(config.AsyncStoreQueueDepth, "50")
+	settings.Set(config.AsyncStoreFlushInterval, "20")
+
+	queueDepth, maxBatch, flushInterval, err := asyncStoreSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if queueDepth != 50 || maxBatch != defaultAsyncStoreMaxBatch || flushInterval != 20*time.Millisecond {
+		t.Errorf("Unexpected settings %v %v %v", queueDepth, maxBatch, flushInterval)
+	}
+
+	settings.Set(config.AsyncStoreMaxBatch, "0")
+	if _, _, _, err := asyncStoreSettings(settings); err == nil {
+		t.Error("Expected error for AsyncStoreMaxBatch 0")
+	}
+}
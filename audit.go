@@ -0,0 +1,378 @@
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//ReceivedMessageStore may be implemented by a MessageStore to keep the messages received, saved with PersistReceivedMessages=Y
+//for QueryMessages. Messages received are kept until the store is Reset, they are not removed by compaction.
+type ReceivedMessageStore interface {
+	//SaveReceivedMessage saves msg, received with seqNum, replacing a message saved with the same seqNum.
+	SaveReceivedMessage(seqNum int, msg []byte) error
+
+	//GetReceivedMessages returns the messages received saved with seqnums from beginSeqNum to endSeqNum, in order.
+	GetReceivedMessages(beginSeqNum, endSeqNum int) chan []byte
+}
+
+//MessageDirection is whether a message was sent or received by a session.
+type MessageDirection int
+
+const (
+	//MessageSent is a message sent to the counterparty.
+	MessageSent MessageDirection = iota
+
+	//MessageReceived is a message received from the counterparty.
+	MessageReceived
+)
+
+func (d MessageDirection) String() string {
+	if d == MessageReceived {
+		return "received"
+	}
+
+	return "sent"
+}
+
+//MessageQuery selects the messages of a session returned by QueryMessages. The zero MessageQuery selects every message.
+type MessageQuery struct {
+	//Sent and Received select the messages sent or received, both if neither is set.
+	Sent, Received bool
+
+	//From and To bound the SendingTime of the messages, From inclusive and To exclusive, unbounded if zero.
+	From, To time.Time
+
+	//MsgTypes select the messages of any of the MsgTypes, any MsgType if empty.
+	MsgTypes []string
+
+	//Fields select the messages with each field of Fields, such as ClOrdID (11) or ExecID (17). Messages are looked up in the index
+	//of the tags of AuditIndexTags, other fields are matched by reading each message.
+	Fields map[fix.Tag]string
+}
+
+//AuditRecord is a message returned by QueryMessages.
+type AuditRecord struct {
+	SessionID   SessionID
+	Direction   MessageDirection
+	SeqNum      int
+	SendingTime time.Time
+	MsgType     string
+
+	//Message is the message as sent or received.
+	Message []byte
+}
+
+//auditChunk is the number of seqnums read at a time by QueryMessages.
+const auditChunk = 1000
+
+//QueryMessages calls fn with each message of the session with sessionID selected by query, the messages sent then the messages received,
+//each in MsgSeqNum order, without loading them all at once. Messages are read from the MessageStore of the session, sent messages as
+//saved for resend, see PersistMessages, and received messages if saved with PersistReceivedMessages=Y.
+//Stops with the error of fn, or of ctx once done.
+func QueryMessages(ctx context.Context, sessionID SessionID, query MessageQuery, fn func(record AuditRecord) error) error {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return session.queryMessages(ctx, query, fn)
+}
+
+func (s *Session) queryMessages(ctx context.Context, query MessageQuery, fn func(record AuditRecord) error) error {
+	for _, direction := range []MessageDirection{MessageSent, MessageReceived} {
+		if query.Sent != query.Received && (direction == MessageSent) != query.Sent {
+			continue
+		}
+
+		if direction == MessageReceived {
+			if _, ok := s.store.(ReceivedMessageStore); !ok {
+				if query.Received {
+					return fmt.Errorf("store of %v does not save messages received", s.sessionID)
+				}
+				continue
+			}
+		}
+
+		seqNums, indexed := s.indexedSeqNums(direction, query.Fields)
+		if !indexed {
+			seqNums = []int{1, s.lastSeqNum(direction)}
+		}
+
+		for i := 0; i+1 < len(seqNums); i += 2 {
+			for begin, end := seqNums[i], seqNums[i+1]; begin <= end; begin += auditChunk {
+				chunkEnd := begin + auditChunk - 1
+				if chunkEnd > end {
+					chunkEnd = end
+				}
+
+				if err := s.queryChunk(ctx, direction, begin, chunkEnd, query, fn); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+//queryChunk calls fn with the messages of direction from seqnums begin to end selected by query.
+func (s *Session) queryChunk(ctx context.Context, direction MessageDirection, begin, end int, query MessageQuery, fn func(record AuditRecord) error) error {
+	msgs := s.storedMessages(direction, begin, end)
+
+	//the messages of the chunk are drained once stopped, so the store is not left blocked
+	defer func() {
+		for range msgs {
+		}
+	}()
+
+	//the messages are parsed into one Message, not kept once selected
+	var msg Message
+	for msgBytes := range msgs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := msg.Parse(msgBytes); err != nil {
+			continue
+		}
+
+		record, ok := query.selects(&msg)
+		if !ok {
+			continue
+		}
+
+		record.SessionID, record.Direction, record.Message = s.sessionID, direction, msgBytes
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//selects returns the AuditRecord of msg if selected by the query.
+func (q MessageQuery) selects(msg *Message) (AuditRecord, bool) {
+	var record AuditRecord
+
+	msgType, seqNum, sendingTime := new(fix.StringValue), new(fix.IntValue), new(fix.UTCTimestampValue)
+	msg.Header.GetField(tag.MsgType, msgType)
+	msg.Header.GetField(tag.MsgSeqNum, seqNum)
+	msg.Header.GetField(tag.SendingTime, sendingTime)
+	record.MsgType, record.SeqNum, record.SendingTime = msgType.Value, seqNum.Value, sendingTime.Value
+
+	if !q.From.IsZero() && record.SendingTime.Before(q.From) {
+		return record, false
+	}
+
+	if !q.To.IsZero() && !record.SendingTime.Before(q.To) {
+		return record, false
+	}
+
+	if len(q.MsgTypes) > 0 {
+		matched := false
+		for _, t := range q.MsgTypes {
+			matched = matched || t == record.MsgType
+		}
+
+		if !matched {
+			return record, false
+		}
+	}
+
+	for t, value := range q.Fields {
+		if actual, ok := fieldValue(msg, t); !ok || actual != value {
+			return record, false
+		}
+	}
+
+	return record, true
+}
+
+//fieldValue returns the value of the field of msg with tag t, in its header or body.
+func fieldValue(msg *Message, t fix.Tag) (string, bool) {
+	value := new(fix.StringValue)
+	if msg.Body.GetField(t, value) == nil || msg.Header.GetField(t, value) == nil {
+		return value.Value, true
+	}
+
+	return "", false
+}
+
+//storedMessages returns the messages of direction saved in the store with seqnums from begin to end.
+func (s *Session) storedMessages(direction MessageDirection, begin, end int) chan []byte {
+	if direction == MessageReceived {
+		return s.store.(ReceivedMessageStore).GetReceivedMessages(begin, end)
+	}
+
+	return s.store.GetMessages(begin, end)
+}
+
+//lastSeqNum returns the MsgSeqNum of the last message of direction.
+func (s *Session) lastSeqNum(direction MessageDirection) int {
+	if direction == MessageReceived {
+		return s.store.NextTargetMsgSeqNum() - 1
+	}
+
+	return s.store.NextSenderMsgSeqNum() - 1
+}
+
+//saveReceivedMessage saves msgBytes received with PersistReceivedMessages=Y.
+func (s *Session) saveReceivedMessage(msg *Message, msgBytes []byte) {
+	store, ok := s.store.(ReceivedMessageStore)
+	if !ok || !s.persistReceivedMessages {
+		return
+	}
+
+	seqNum := new(fix.IntValue)
+	if msg.Header.GetField(tag.MsgSeqNum, seqNum) != nil {
+		return
+	}
+
+	if err := store.SaveReceivedMessage(seqNum.Value, msgBytes); err != nil {
+		s.storeMetrics.recordError(err, s.now())
+		s.onAnomaly(AnomalyStoreWriteFailure, 1, err)
+		logEventf(s.log, LogLevelError, LogCategoryStore, "Cannot store message received %v: %v", seqNum.Value, err)
+	}
+}
+
+//parseAuditIndexTags parses the AuditIndexTags setting, a comma separated list of tags.
+func parseAuditIndexTags(setting string) ([]fix.Tag, error) {
+	var tags []fix.Tag
+	for _, value := range strings.Split(setting, ",") {
+		t, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || t <= 0 {
+			return nil, fmt.Errorf("invalid %v %v, expected a comma separated list of tags", config.AuditIndexTags, setting)
+		}
+		tags = append(tags, fix.Tag(t))
+	}
+
+	return tags, nil
+}
+
+//auditIndex indexes the seqnums of the messages of a session by the values of the tags of AuditIndexTags.
+//The index is brought up to date with the store by each query, and rebuilt once the store is reset.
+type auditIndex struct {
+	lock         sync.Mutex
+	tags         []fix.Tag
+	creationTime time.Time
+	directions   [2]directionIndex
+}
+
+//directionIndex is the index of the messages sent or received.
+type directionIndex struct {
+	//indexedTo is the last seqnum indexed
+	indexedTo int
+	seqNums   map[fix.Tag]map[string][]int
+}
+
+func newAuditIndex(tags []fix.Tag) *auditIndex {
+	return &auditIndex{tags: tags}
+}
+
+//indexedSeqNums returns the seqnums of the messages of direction with the indexed fields of fields, as pairs of first and last seqnums
+//of ranges, ascending. Returns false if none of fields are indexed.
+func (s *Session) indexedSeqNums(direction MessageDirection, fields map[fix.Tag]string) ([]int, bool) {
+	index := s.auditIndex
+	if index == nil {
+		return nil, false
+	}
+
+	var indexed []fix.Tag
+	for _, t := range index.tags {
+		if _, ok := fields[t]; ok {
+			indexed = append(indexed, t)
+		}
+	}
+
+	if len(indexed) == 0 {
+		return nil, false
+	}
+
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	s.updateAuditIndex(direction)
+
+	var matched []int
+	for i, t := range indexed {
+		seqNums := index.directions[direction].seqNums[t][fields[t]]
+		if i == 0 {
+			matched = append([]int(nil), seqNums...)
+			continue
+		}
+
+		intersection := matched[:0]
+		for _, seqNum := range matched {
+			if j := sort.SearchInts(seqNums, seqNum); j < len(seqNums) && seqNums[j] == seqNum {
+				intersection = append(intersection, seqNum)
+			}
+		}
+		matched = intersection
+	}
+
+	var ranges []int
+	for _, seqNum := range matched {
+		if n := len(ranges); n > 0 && ranges[n-1] == seqNum-1 {
+			ranges[n-1] = seqNum
+			continue
+		}
+		ranges = append(ranges, seqNum, seqNum)
+	}
+
+	return ranges, true
+}
+
+//updateAuditIndex indexes the messages of direction saved since last indexed, with the lock of the index held.
+func (s *Session) updateAuditIndex(direction MessageDirection) {
+	index := s.auditIndex
+	if creationTime := s.store.CreationTime(); !creationTime.Equal(index.creationTime) {
+		index.creationTime = creationTime
+		index.directions = [2]directionIndex{}
+	}
+
+	entries := &index.directions[direction]
+	if entries.seqNums == nil {
+		entries.seqNums = make(map[fix.Tag]map[string][]int)
+		for _, t := range index.tags {
+			entries.seqNums[t] = make(map[string][]int)
+		}
+	}
+
+	last := s.lastSeqNum(direction)
+	for begin := entries.indexedTo + 1; begin <= last; begin += auditChunk {
+		end := begin + auditChunk - 1
+		if end > last {
+			end = last
+		}
+
+		var msg Message
+		for msgBytes := range s.storedMessages(direction, begin, end) {
+			if err := msg.Parse(msgBytes); err != nil {
+				continue
+			}
+
+			seqNum := new(fix.IntValue)
+			if msg.Header.GetField(tag.MsgSeqNum, seqNum) != nil {
+				continue
+			}
+
+			for _, t := range index.tags {
+				if value, ok := fieldValue(&msg, t); ok {
+					entries.seqNums[t][value] = append(entries.seqNums[t][value], seqNum.Value)
+				}
+			}
+		}
+	}
+
+	if last > entries.indexedTo {
+		entries.indexedTo = last
+	}
+}
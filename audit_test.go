@@ -0,0 +1,120 @@
+package quickfix
+
+import (
+	"context"
+	"errors"
+	"github.com/quickfixgo/quickfix/fix"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//newTestAuditSession returns a session with orders 1 to 3 sent and their executions received, a second apart from sendingTime.
+func newTestAuditSession(t *testing.T, sendingTime time.Time) *Session {
+	session := newTestAdminSession(&TestClient{})
+	session.persistReceivedMessages = true
+	session.auditIndex = newAuditIndex([]fix.Tag{11})
+
+	for i := 1; i <= 3; i++ {
+		timestamp := sendingTime.Add(time.Duration(i) * time.Second).Format("20060102-15:04:05.000")
+		order := rawMessage("FIX.4.2", "35=D\00134="+strconv.Itoa(i)+"\00149=TW\00152="+timestamp+"\00156=ISLD\00111=order"+strconv.Itoa(i)+"\001")
+		if err := saveMessage(session.store, i, order); err != nil {
+			t.Fatal(err)
+		}
+		session.store.IncrNextSenderMsgSeqNum()
+
+		execution := rawMessage("FIX.4.2", "35=8\00134="+strconv.Itoa(i)+"\00149=ISLD\00152="+timestamp+"\00156=TW\00111=order"+strconv.Itoa(i)+"\00117=exec"+strconv.Itoa(i)+"\001")
+		msg, err := parseMessage(execution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		session.saveReceivedMessage(msg, execution)
+		session.store.IncrNextTargetMsgSeqNum()
+	}
+
+	return session
+}
+
+func queryRecords(t *testing.T, session *Session, query MessageQuery) (records []AuditRecord) {
+	err := session.queryMessages(context.Background(), query, func(record AuditRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestSession_QueryMessages(t *testing.T) {
+	sendingTime := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	session := newTestAuditSession(t, sendingTime)
+
+	if records := queryRecords(t, session, MessageQuery{}); len(records) != 6 ||
+		records[0].Direction != MessageSent || records[0].SeqNum != 1 || records[5].Direction != MessageReceived || records[5].SeqNum != 3 {
+		t.Errorf("Expected the orders sent then the executions received, got %v", records)
+	}
+
+	records := queryRecords(t, session, MessageQuery{Received: true, MsgTypes: []string{"8"}})
+	if len(records) != 3 || records[0].MsgType != "8" || records[0].Direction != MessageReceived {
+		t.Errorf("Expected the executions received, got %v", records)
+	}
+
+	records = queryRecords(t, session, MessageQuery{Sent: true, From: sendingTime.Add(2 * time.Second), To: sendingTime.Add(3 * time.Second)})
+	if len(records) != 1 || records[0].SeqNum != 2 || !records[0].SendingTime.Equal(sendingTime.Add(2*time.Second)) {
+		t.Errorf("Expected the order sent at %v, got %v", sendingTime.Add(2*time.Second), records)
+	}
+
+	records = queryRecords(t, session, MessageQuery{Fields: map[fix.Tag]string{11: "order2"}})
+	if len(records) != 2 || records[0].MsgType != "D" || records[1].MsgType != "8" || records[1].SeqNum != 2 {
+		t.Errorf("Expected the order and execution of order2, got %v", records)
+	}
+
+	records = queryRecords(t, session, MessageQuery{Fields: map[fix.Tag]string{11: "order3", 17: "exec3"}})
+	if len(records) != 1 || records[0].SeqNum != 3 || records[0].Direction != MessageReceived {
+		t.Errorf("Expected the execution exec3, got %v", records)
+	}
+}
+
+func TestSession_QueryMessagesIndexReset(t *testing.T) {
+	session := newTestAuditSession(t, time.Now())
+	if records := queryRecords(t, session, MessageQuery{Fields: map[fix.Tag]string{11: "order1"}}); len(records) != 2 {
+		t.Errorf("Expected the order and execution of order1, got %v", records)
+	}
+
+	session.store.Reset()
+	if records := queryRecords(t, session, MessageQuery{Fields: map[fix.Tag]string{11: "order1"}}); len(records) != 0 {
+		t.Errorf("Expected the index rebuilt after reset, got %v", records)
+	}
+}
+
+func TestSession_QueryMessagesStop(t *testing.T) {
+	session := newTestAuditSession(t, time.Now())
+
+	stop := errors.New("stop")
+	calls := 0
+	err := session.queryMessages(context.Background(), MessageQuery{}, func(record AuditRecord) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected query stopped by the first record, got %v after %v", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := session.queryMessages(ctx, MessageQuery{}, func(AuditRecord) error { return nil }); err != context.Canceled {
+		t.Errorf("Expected query canceled, got %v", err)
+	}
+}
+
+func TestParseAuditIndexTags(t *testing.T) {
+	if tags, err := parseAuditIndexTags("11, 17"); err != nil || len(tags) != 2 || tags[0] != 11 || tags[1] != 17 {
+		t.Errorf("Expected tags 11 and 17, got %v %v", tags, err)
+	}
+
+	if _, err := parseAuditIndexTags("11,ClOrdID"); err == nil {
+		t.Error("Expected error for a tag that is not a number")
+	}
+}
@@ -0,0 +1,110 @@
+//Package awssecrets provides a QuickFIX/Go SecretsProvider reading secrets from AWS Secrets Manager.
+//A reference secret://<name> is the SecretString of the secret name, and secret://<name>#<key> the key of a secret holding a JSON
+//object, as the secrets Secrets Manager creates for database credentials.
+package awssecrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"os"
+	"strings"
+)
+
+//ProviderType is the SecretsProviderType of the provider, registered with quickfix.RegisterSecretsProvider when the package is imported.
+const ProviderType = "aws"
+
+func init() {
+	quickfix.RegisterSecretsProvider(ProviderType, NewProvider)
+}
+
+//API is the part of *secretsmanager.Client used by the provider.
+type API interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+//Provider is a quickfix.SecretsProvider reading the current version of secrets from Secrets Manager.
+type Provider struct {
+	client API
+}
+
+//NewProvider returns a Provider reading secrets from SecretsManagerRegion, or AWS_REGION if not set, at SecretsManagerEndpoint if set.
+//Credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN, as set in AWS Lambda.
+//Use NewProviderWithClient for other credentials.
+func NewProvider(settings *quickfix.SessionSettings) (quickfix.SecretsProvider, error) {
+	region := os.Getenv("AWS_REGION")
+	if settings.HasSetting(config.SecretsManagerRegion) {
+		region, _ = settings.Setting(config.SecretsManagerRegion)
+	}
+
+	if region == "" {
+		return nil, fmt.Errorf("missing configuration: %v", config.SecretsManagerRegion)
+	}
+
+	options := secretsmanager.Options{Region: region, Credentials: aws.NewCredentialsCache(aws.CredentialsProviderFunc(environmentCredentials))}
+	if settings.HasSetting(config.SecretsManagerEndpoint) {
+		endpoint, _ := settings.Setting(config.SecretsManagerEndpoint)
+		options.BaseEndpoint = aws.String(endpoint)
+	}
+
+	return NewProviderWithClient(secretsmanager.New(options)), nil
+}
+
+//NewProviderWithClient returns a Provider reading secrets with client.
+func NewProviderWithClient(client API) *Provider {
+	return &Provider{client: client}
+}
+
+//environmentCredentials reads the credentials of the process from the environment.
+func environmentCredentials(ctx context.Context) (aws.Credentials, error) {
+	credentials := aws.Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Source:          "Environment",
+	}
+
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return aws.Credentials{}, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY not set")
+	}
+
+	return credentials, nil
+}
+
+//Secret returns the SecretString of the secret of a reference <name>, or the key of its JSON object for a reference <name>#<key>.
+func (p *Provider) Secret(ctx context.Context, reference string) (string, error) {
+	name, key := reference, ""
+	if i := strings.LastIndex(reference, "#"); i >= 0 {
+		name, key = reference[:i], reference[i+1:]
+	}
+
+	output, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		return "", err
+	}
+
+	if output.SecretString == nil {
+		return "", fmt.Errorf("secret %v is binary, expected a SecretString", name)
+	}
+
+	if key == "" {
+		return *output.SecretString, nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(*output.SecretString), &object); err != nil {
+		return "", fmt.Errorf("secret %v is not a JSON object: %v", name, err)
+	}
+
+	value, ok := object[key].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %v has no string key %v", name, key)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,68 @@
+package awssecrets
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"os"
+	"testing"
+)
+
+type fakeSecretsManager struct {
+	secrets map[string]*string
+}
+
+func (f *fakeSecretsManager) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	secret, ok := f.secrets[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+
+	return &secretsmanager.GetSecretValueOutput{Name: params.SecretId, SecretString: secret}, nil
+}
+
+func TestProvider_Secret(t *testing.T) {
+	provider := NewProviderWithClient(&fakeSecretsManager{secrets: map[string]*string{
+		"fix/venueA/password": aws.String("hunter2"),
+		"fix/venueB":          aws.String(`{"username": "client", "password": "swordfish", "attempts": 3}`),
+		"fix/venueC":          nil,
+	}})
+
+	var tests = map[string]string{
+		"fix/venueA/password":  "hunter2",
+		"fix/venueB#password":  "swordfish",
+		"fix/venueB#username":  "client",
+		"fix/venueB":           `{"username": "client", "password": "swordfish", "attempts": 3}`,
+		"fix/venueA/password#": "hunter2",
+	}
+
+	for reference, expected := range tests {
+		if secret, err := provider.Secret(context.Background(), reference); err != nil || secret != expected {
+			t.Errorf("%v: expected %v, got %v %v", reference, expected, secret, err)
+		}
+	}
+
+	for _, reference := range []string{"fix/venueD", "fix/venueA/password#key", "fix/venueB#attempts", "fix/venueB#missing", "fix/venueC"} {
+		if _, err := provider.Secret(context.Background(), reference); err == nil {
+			t.Errorf("Expected error reading %v", reference)
+		}
+	}
+}
+
+func TestNewProvider_Settings(t *testing.T) {
+	os.Unsetenv("AWS_REGION")
+
+	if _, err := NewProvider(quickfix.NewSessionSettings()); err == nil {
+		t.Error("Expected error without SecretsManagerRegion")
+	}
+
+	settings := quickfix.NewSessionSettings()
+	settings.Set(config.SecretsManagerRegion, "us-east-1")
+	settings.Set(config.SecretsManagerEndpoint, "http://localhost:4566")
+	if _, err := NewProvider(settings); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,163 @@
+//Package bench is the benchmark suite of QuickFIX/Go, measuring parsing, validation, serialization, store saves and round trips over
+//the in-process transport across message sizes and repeating group depths, so that performance regressions are caught and optimizations
+//are measured against a baseline.
+//
+//The benchmarks are reproducible: messages are built from fixed values, timestamps included. Run them repeatedly and compare the results
+//of two revisions with benchstat:
+//
+//	go test -run XXX -bench . -benchmem -count 10 ./bench > old.txt
+//	git checkout feature
+//	go test -run XXX -bench . -benchmem -count 10 ./bench > new.txt
+//	benchstat old.txt new.txt
+package bench
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"time"
+)
+
+//Shape is the size and repeating group depth of the messages benchmarked.
+type Shape struct {
+	//Name names the benchmarks of the shape.
+	Name string
+
+	//Orders is the number of entries of the NoOrders group.
+	Orders int
+
+	//Depth is the depth of the repeating groups: 1 for orders only, 2 for orders with parties, 3 for parties with sub IDs.
+	Depth int
+}
+
+//Shapes are the shapes benchmarked, from a single order to a hundred, and from flat orders to parties with sub IDs.
+var Shapes = []Shape{
+	{Name: "Orders1Depth1", Orders: 1, Depth: 1},
+	{Name: "Orders10Depth1", Orders: 10, Depth: 1},
+	{Name: "Orders100Depth1", Orders: 100, Depth: 1},
+	{Name: "Orders10Depth2", Orders: 10, Depth: 2},
+	{Name: "Orders10Depth3", Orders: 10, Depth: 3},
+}
+
+//partiesPerOrder and subIDsPerParty are the number of entries of the nested groups.
+const (
+	partiesPerOrder = 2
+	subIDsPerParty  = 2
+)
+
+//SendingTime is the SendingTime of the messages built.
+var SendingTime = time.Date(2016, time.August, 3, 12, 30, 15, 123000000, time.UTC)
+
+//NewOrderList returns a FIX.4.4 NewOrderList (E) of shape from sender to target, valid against dict, a FIX.4.4 data dictionary.
+func NewOrderList(dict *datadictionary.DataDictionary, shape Shape, sender, target string) (quickfix.MessageBuilder, error) {
+	if shape.Orders < 1 || shape.Depth < 1 || shape.Depth > 3 {
+		return nil, fmt.Errorf("invalid shape %+v, expected at least 1 order and a depth from 1 to 3", shape)
+	}
+
+	msgDef, ok := dict.Messages["E"]
+	if !ok {
+		return nil, fmt.Errorf("NewOrderList not defined by the data dictionary")
+	}
+
+	ordersDef := msgDef.Fields[tag.NoOrders]
+	if ordersDef == nil {
+		return nil, fmt.Errorf("NoOrders not defined for NewOrderList")
+	}
+
+	builder := quickfix.NewMessageBuilder()
+	builder.Header().Set(field.NewBeginString(fix.BeginString_FIX44))
+	builder.Header().Set(field.NewMsgType("E"))
+	builder.Header().Set(field.NewSenderCompID(sender))
+	builder.Header().Set(field.NewTargetCompID(target))
+	builder.Header().Set(field.NewMsgSeqNum(1))
+	builder.Header().SetField(tag.SendingTime, &fix.UTCTimestampValue{Value: SendingTime})
+
+	builder.Body().Set(field.NewListID("LIST-1"))
+	builder.Body().Set(field.NewBidType(3))
+	builder.Body().Set(field.NewTotNoOrders(shape.Orders))
+
+	orders := quickfix.NewRepeatingGroupForDef(ordersDef)
+	orders.SortFields = true
+	for i := 1; i <= shape.Orders; i++ {
+		order := orders.Add()
+		order.Set(field.NewClOrdID(fmt.Sprintf("ORDER-%d", i)))
+		order.Set(field.NewListSeqNo(i))
+		order.Set(field.NewSymbol("MSFT"))
+		order.Set(field.NewSide("1"))
+		order.Set(field.NewOrderQty(float64(100 * i)))
+		order.Set(field.NewPrice(25.5))
+
+		if shape.Depth < 2 {
+			continue
+		}
+
+		parties, err := newParties(ordersDef, shape.Depth)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := order.SetGroup(parties); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := builder.Body().SetGroup(orders); err != nil {
+		return nil, err
+	}
+
+	return builder, nil
+}
+
+//newParties returns the NoPartyIDs group of an order, with the NoPartySubIDs group of each party at depth 3.
+func newParties(ordersDef *datadictionary.FieldDef, depth int) (*quickfix.RepeatingGroup, error) {
+	partiesDef := childDef(ordersDef, tag.NoPartyIDs)
+	if partiesDef == nil {
+		return nil, fmt.Errorf("NoPartyIDs not defined for NoOrders")
+	}
+
+	parties := quickfix.NewRepeatingGroupForDef(partiesDef)
+	parties.SortFields = true
+	for i := 1; i <= partiesPerOrder; i++ {
+		party := parties.Add()
+		party.Set(field.NewPartyID(fmt.Sprintf("PARTY-%d", i)))
+		party.Set(field.NewPartyIDSource("D"))
+		party.Set(field.NewPartyRole(i))
+
+		if depth < 3 {
+			continue
+		}
+
+		subIDsDef := childDef(partiesDef, tag.NoPartySubIDs)
+		if subIDsDef == nil {
+			return nil, fmt.Errorf("NoPartySubIDs not defined for NoPartyIDs")
+		}
+
+		subIDs := quickfix.NewRepeatingGroupForDef(subIDsDef)
+		subIDs.SortFields = true
+		for j := 1; j <= subIDsPerParty; j++ {
+			subID := subIDs.Add()
+			subID.Set(field.NewPartySubID(fmt.Sprintf("SUB-%d", j)))
+			subID.Set(field.NewPartySubIDType(j))
+		}
+
+		if err := party.SetGroup(subIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	return parties, nil
+}
+
+//childDef returns the field of the group defined by def with tag t, nil if not defined.
+func childDef(def *datadictionary.FieldDef, t fix.Tag) *datadictionary.FieldDef {
+	for _, child := range def.ChildFields {
+		if child.Tag == t {
+			return child
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,295 @@
+package bench
+
+import (
+	"context"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func loadDictionary(tb testing.TB) *datadictionary.DataDictionary {
+	dict, err := datadictionary.Parse("../spec/FIX44.xml")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return dict
+}
+
+//buildOrderList returns the bytes of the NewOrderList of shape.
+func buildOrderList(tb testing.TB, dict *datadictionary.DataDictionary, shape Shape) []byte {
+	builder, err := NewOrderList(dict, shape, "BENCH", "ACCEPTOR")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	msgBytes, err := builder.Build()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return msgBytes
+}
+
+func TestNewOrderList(t *testing.T) {
+	dict := loadDictionary(t)
+
+	for _, shape := range Shapes {
+		var msg quickfix.Message
+		if err := msg.Parse(buildOrderList(t, dict, shape)); err != nil {
+			t.Fatalf("%v: %v", shape.Name, err)
+		}
+
+		if err := msg.Validate(dict); err != nil {
+			t.Errorf("Expected %v valid, got %v", shape.Name, err)
+		}
+	}
+
+	if _, err := NewOrderList(dict, Shape{Orders: 1, Depth: 4}, "BENCH", "ACCEPTOR"); err == nil {
+		t.Error("Expected an error for a depth of 4")
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	dict := loadDictionary(b)
+
+	for _, shape := range Shapes {
+		b.Run(shape.Name, func(b *testing.B) {
+			msgBytes := buildOrderList(b, dict, shape)
+
+			var msg quickfix.Message
+			b.SetBytes(int64(len(msgBytes)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := msg.Parse(msgBytes); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	dict := loadDictionary(b)
+
+	for _, shape := range Shapes {
+		b.Run(shape.Name, func(b *testing.B) {
+			var msg quickfix.Message
+			if err := msg.Parse(buildOrderList(b, dict, shape)); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := msg.Validate(dict); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSerialize(b *testing.B) {
+	dict := loadDictionary(b)
+
+	for _, shape := range Shapes {
+		b.Run(shape.Name, func(b *testing.B) {
+			builder, err := NewOrderList(dict, shape, "BENCH", "ACCEPTOR")
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := builder.Build(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStoreSave(b *testing.B) {
+	dict := loadDictionary(b)
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "BENCH", TargetCompID: "ACCEPTOR"}
+
+	dir, err := ioutil.TempDir("", "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	settings := quickfix.NewSettings()
+	settings.GlobalSettings().Set(config.FileStorePath, dir)
+	sessionSettings := quickfix.NewSessionSettings()
+	sessionSettings.Set(config.BeginString, sessionID.BeginString)
+	sessionSettings.Set(config.SenderCompID, sessionID.SenderCompID)
+	sessionSettings.Set(config.TargetCompID, sessionID.TargetCompID)
+	if _, err := settings.AddSession(sessionSettings); err != nil {
+		b.Fatal(err)
+	}
+
+	fileStoreFactory, err := quickfix.NewFileStoreFactory(settings)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	factories := []struct {
+		name    string
+		factory quickfix.MessageStoreFactory
+	}{
+		{"Memory", quickfix.NewMemoryStoreFactory()},
+		{"File", fileStoreFactory},
+	}
+
+	for _, f := range factories {
+		for _, shape := range Shapes {
+			b.Run(f.name+"/"+shape.Name, func(b *testing.B) {
+				store, err := f.factory.Create(sessionID)
+				if err != nil {
+					b.Fatal(err)
+				}
+				store.Reset()
+
+				msgBytes := buildOrderList(b, dict, shape)
+				b.SetBytes(int64(len(msgBytes)))
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if err := store.(quickfix.ErrorSavingMessageStore).TrySaveMessage(i+1, msgBytes); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+//echoApp is the Application of the acceptor of the round trip benchmark, replying to each NewOrderList with reply.
+type echoApp struct {
+	quickfixApp
+	reply quickfix.MessageBuilder
+}
+
+func (a *echoApp) FromApp(msg quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	if err := quickfix.SendToTarget(a.reply, sessionID); err != nil {
+		panic(err)
+	}
+
+	return nil
+}
+
+//quickfixApp is an Application signaling logons on loggedOn and the messages received on received.
+type quickfixApp struct {
+	loggedOn chan struct{}
+	received chan struct{}
+}
+
+func (a *quickfixApp) OnCreate(sessionID quickfix.SessionID) {}
+func (a *quickfixApp) OnLogon(sessionID quickfix.SessionID) {
+	a.loggedOn <- struct{}{}
+}
+func (a *quickfixApp) OnLogout(sessionID quickfix.SessionID)                             {}
+func (a *quickfixApp) ToAdmin(msg quickfix.MessageBuilder, sessionID quickfix.SessionID) {}
+func (a *quickfixApp) ToApp(msg quickfix.MessageBuilder, sessionID quickfix.SessionID) error {
+	return nil
+}
+func (a *quickfixApp) FromAdmin(msg quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	return nil
+}
+func (a *quickfixApp) FromApp(msg quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	a.received <- struct{}{}
+	return nil
+}
+
+//BenchmarkRoundTrip measures a NewOrderList sent by an initiator and the NewOrderList of the same shape replied by an acceptor, over the
+//in-process transport, each message serialized, stored, written, read and parsed by the sessions.
+func BenchmarkRoundTrip(b *testing.B) {
+	dict := loadDictionary(b)
+
+	for _, shape := range Shapes {
+		b.Run(shape.Name, func(b *testing.B) {
+			initiatorID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: shape.Name, TargetCompID: "ACCEPTOR"}
+			transport := quickfix.NewPipeTransport()
+
+			reply, err := NewOrderList(dict, shape, "ACCEPTOR", shape.Name)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			acceptorSettings := quickfix.NewSettings()
+			acceptorSettings.GlobalSettings().Set(config.SocketAcceptHost, "localhost")
+			acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, "5001")
+			acceptorSessionSettings := quickfix.NewSessionSettings()
+			acceptorSessionSettings.Set(config.BeginString, "FIX.4.4")
+			acceptorSessionSettings.Set(config.SenderCompID, "ACCEPTOR")
+			acceptorSessionSettings.Set(config.TargetCompID, shape.Name)
+			if _, err := acceptorSettings.AddSession(acceptorSessionSettings); err != nil {
+				b.Fatal(err)
+			}
+
+			acceptorApp := &echoApp{quickfixApp: quickfixApp{loggedOn: make(chan struct{}, 1)}, reply: reply}
+			acceptor, err := quickfix.NewAcceptor(acceptorApp, quickfix.NewMemoryStoreFactory(), acceptorSettings, quickfix.NewNullLogFactory())
+			if err != nil {
+				b.Fatal(err)
+			}
+			acceptor.SetListenerFactory(transport.Listen)
+
+			if err := acceptor.Start(); err != nil {
+				b.Fatal(err)
+			}
+			defer acceptor.Shutdown(context.Background())
+
+			initiatorSettings := quickfix.NewSettings()
+			initiatorSessionSettings := quickfix.NewSessionSettings()
+			initiatorSessionSettings.Set(config.BeginString, initiatorID.BeginString)
+			initiatorSessionSettings.Set(config.SenderCompID, initiatorID.SenderCompID)
+			initiatorSessionSettings.Set(config.TargetCompID, initiatorID.TargetCompID)
+			initiatorSessionSettings.Set(config.HeartBtInt, "30")
+			initiatorSessionSettings.Set(config.SocketConnectHost, "localhost")
+			initiatorSessionSettings.Set(config.SocketConnectPort, "5001")
+			if _, err := initiatorSettings.AddSession(initiatorSessionSettings); err != nil {
+				b.Fatal(err)
+			}
+
+			initiatorApp := &quickfixApp{loggedOn: make(chan struct{}, 1), received: make(chan struct{}, 1)}
+			initiator, err := quickfix.NewInitiator(initiatorApp, quickfix.NewMemoryStoreFactory(), initiatorSettings, quickfix.NewNullLogFactory())
+			if err != nil {
+				b.Fatal(err)
+			}
+			initiator.SetDialer(transport.Dial)
+
+			if err := initiator.Start(); err != nil {
+				b.Fatal(err)
+			}
+			defer initiator.Stop()
+
+			select {
+			case <-initiatorApp.loggedOn:
+			case <-time.After(5 * time.Second):
+				b.Fatal("Expected the initiator logged on")
+			}
+
+			order, err := NewOrderList(dict, shape, initiatorID.SenderCompID, "ACCEPTOR")
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := quickfix.SendToTarget(order, initiatorID); err != nil {
+					b.Fatal(err)
+				}
+				<-initiatorApp.received
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+//Package boltstore provides a QuickFIX/Go MessageStore persisting sessions to an embedded bbolt database file.
+package boltstore
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	bolt "go.etcd.io/bbolt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//StoreType is the MessageStoreType of the store, registered with quickfix.RegisterStoreFactory when the package is imported.
+const StoreType = "bolt"
+
+func init() {
+	quickfix.RegisterStoreFactory(StoreType, NewStoreFactory)
+}
+
+//Keys of the session bucket.
+var (
+	keySender       = []byte("sender")
+	keyTarget       = []byte("target")
+	keyCreationTime = []byte("creation_time")
+	keyLoggedOn     = []byte("logged_on")
+	keyResendBegin  = []byte("resend_begin")
+	keyResendEnd    = []byte("resend_end")
+
+	//bucketMessages is nested in the session bucket, keyed by big endian seqnum so a cursor reads messages in order
+	bucketMessages = []byte("messages")
+)
+
+type storeFactory struct {
+	settings *quickfix.Settings
+
+	//dbs are shared by the sessions stored in the same file, a file may only be opened once
+	dbLock sync.Mutex
+	dbs    map[string]*bolt.DB
+}
+
+//NewStoreFactory returns a MessageStoreFactory that creates MessageStores persisting sessions to the bbolt database file configured via BoltStorePath.
+//Each session is stored in its own bucket, sessions may share a file.
+//BoltStoreNoSync=Y skips syncing each write to disk, trading durability for throughput.
+func NewStoreFactory(settings *quickfix.Settings) (quickfix.MessageStoreFactory, error) {
+	for _, sessionSettings := range settings.SessionSettings() {
+		if !sessionSettings.HasSetting(config.BoltStorePath) {
+			return nil, fmt.Errorf("missing configuration: %v", config.BoltStorePath)
+		}
+	}
+
+	return &storeFactory{settings: settings, dbs: make(map[string]*bolt.DB)}, nil
+}
+
+func (f *storeFactory) Create(sessionID quickfix.SessionID) (quickfix.MessageStore, error) {
+	settings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("bolt store not defined for %v", sessionID)
+	}
+
+	path, err := settings.Setting(config.BoltStorePath)
+	if err != nil {
+		return nil, fmt.Errorf("missing configuration: %v", config.BoltStorePath)
+	}
+
+	noSync := false
+	if settings.HasSetting(config.BoltStoreNoSync) {
+		if noSync, err = settings.BoolSetting(config.BoltStoreNoSync); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := f.open(path, noSync)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStore(sessionID, db)
+}
+
+//open returns the database at path, opening it if not already open.
+func (f *storeFactory) open(path string, noSync bool) (*bolt.DB, error) {
+	f.dbLock.Lock()
+	defer f.dbLock.Unlock()
+
+	if db, ok := f.dbs[path]; ok {
+		return db, nil
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, NoSync: noSync})
+	if err != nil {
+		return nil, err
+	}
+
+	f.dbs[path] = db
+	return db, nil
+}
+
+func seqNumKey(seqNum int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(seqNum))
+	return key
+}
+
+func putInt(bucket *bolt.Bucket, key []byte, value int64) error {
+	return bucket.Put(key, []byte(strconv.FormatInt(value, 10)))
+}
+
+func getInt(bucket *bolt.Bucket, key []byte) (int64, error) {
+	value := bucket.Get(key)
+	if value == nil {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
+//store is a MessageStore persisting a session to its bucket, each write is a transaction.
+type store struct {
+	db     *bolt.DB
+	bucket []byte
+
+	//lock guards the values cached from the bucket, held across the transaction writing each so that they are committed in the order set
+	lock                             sync.RWMutex
+	senderMsgSeqNum, targetMsgSeqNum int
+	creationTime                     time.Time
+	recoveryState                    quickfix.RecoveryState
+
+	//writeErr keeps the error of the first failed transaction, the transactions of the seqnums are only reported by the next TrySaveMessage and Health
+	writeErr quickfix.ErrorLatch
+}
+
+func newStore(sessionID quickfix.SessionID, db *bolt.DB) (*store, error) {
+	s := &store{db: db, bucket: []byte(sessionID.String())}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+//load reads the session bucket, creating it if the session is new.
+func (s *store) load() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucketIfNotExists(bucketMessages); err != nil {
+			return err
+		}
+
+		if bucket.Get(keyCreationTime) == nil {
+			if err := putInt(bucket, keyCreationTime, time.Now().UnixNano()); err != nil {
+				return err
+			}
+		}
+
+		values := make(map[string]int64)
+		for _, key := range [][]byte{keySender, keyTarget, keyCreationTime, keyLoggedOn, keyResendBegin, keyResendEnd} {
+			if values[string(key)], err = getInt(bucket, key); err != nil {
+				return fmt.Errorf("invalid %s of %s: %v", key, s.bucket, err)
+			}
+		}
+
+		s.senderMsgSeqNum, s.targetMsgSeqNum = int(values[string(keySender)]), int(values[string(keyTarget)])
+		s.creationTime = time.Unix(0, values[string(keyCreationTime)]).UTC()
+		s.recoveryState = quickfix.RecoveryState{
+			LoggedOn:         values[string(keyLoggedOn)] == 1,
+			ResendBeginSeqNo: int(values[string(keyResendBegin)]),
+			ResendEndSeqNo:   int(values[string(keyResendEnd)]),
+		}
+		return nil
+	})
+}
+
+//put writes values to the session bucket in a single transaction.
+func (s *store) put(values map[string]int64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(s.bucket)
+		for key, value := range values {
+			if err := putInt(bucket, []byte(key), value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *store) NextSenderMsgSeqNum() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.senderMsgSeqNum + 1
+}
+
+func (s *store) NextTargetMsgSeqNum() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.targetMsgSeqNum + 1
+}
+
+func (s *store) IncrNextSenderMsgSeqNum() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.senderMsgSeqNum++
+	s.writeErr.Set(s.put(map[string]int64{string(keySender): int64(s.senderMsgSeqNum)}))
+}
+
+func (s *store) IncrNextTargetMsgSeqNum() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.targetMsgSeqNum++
+	s.writeErr.Set(s.put(map[string]int64{string(keyTarget): int64(s.targetMsgSeqNum)}))
+}
+
+func (s *store) SetNextSenderMsgSeqNum(next int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.senderMsgSeqNum = next - 1
+	s.writeErr.Set(s.put(map[string]int64{string(keySender): int64(s.senderMsgSeqNum)}))
+}
+
+func (s *store) SetNextTargetMsgSeqNum(next int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.targetMsgSeqNum = next - 1
+	s.writeErr.Set(s.put(map[string]int64{string(keyTarget): int64(s.targetMsgSeqNum)}))
+}
+
+func (s *store) CreationTime() time.Time {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.creationTime
+}
+
+func (s *store) SaveRecoveryState(state quickfix.RecoveryState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var loggedOn int64
+	if state.LoggedOn {
+		loggedOn = 1
+	}
+
+	err := s.put(map[string]int64{
+		string(keyLoggedOn):    loggedOn,
+		string(keyResendBegin): int64(state.ResendBeginSeqNo),
+		string(keyResendEnd):   int64(state.ResendEndSeqNo),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recoveryState = state
+	return nil
+}
+
+func (s *store) RecoveryState() (quickfix.RecoveryState, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.recoveryState, nil
+}
+
+//Reset deletes the messages of the session and resets the sequence numbers, in a single transaction, starting a new session.
+func (s *store) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.creationTime = time.Now().UTC()
+	s.senderMsgSeqNum, s.targetMsgSeqNum = 0, 0
+	s.recoveryState.ResendBeginSeqNo, s.recoveryState.ResendEndSeqNo = 0, 0
+
+	s.writeErr.Set(s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(s.bucket)
+		if err := bucket.DeleteBucket(bucketMessages); err != nil {
+			return err
+		}
+
+		if _, err := bucket.CreateBucket(bucketMessages); err != nil {
+			return err
+		}
+
+		for key, value := range map[string]int64{
+			string(keySender):       0,
+			string(keyTarget):       0,
+			string(keyCreationTime): s.creationTime.UnixNano(),
+			string(keyResendBegin):  0,
+			string(keyResendEnd):    0,
+		} {
+			if err := putInt(bucket, []byte(key), value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+}
+
+//Refresh reloads the session bucket, clearing any write error.
+func (s *store) Refresh() {
+	s.writeErr.Clear()
+
+	s.writeErr.Set(s.load())
+}
+
+//Health returns the first write error since the store was refreshed.
+func (s *store) Health() error {
+	return s.writeErr.Err()
+}
+
+//TrySaveMessage puts msg as the message of seqNum.
+func (s *store) TrySaveMessage(seqNum int, msg []byte) error {
+	if err := s.writeErr.Err(); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Bucket(bucketMessages).Put(seqNumKey(seqNum), msg)
+	})
+}
+
+//SaveMessage puts msg as TrySaveMessage, a failed transaction kept as the error reported by Health until Refresh.
+func (s *store) SaveMessage(seqNum int, msg []byte) {
+	s.writeErr.Set(s.TrySaveMessage(seqNum, msg))
+}
+
+//Compact deletes the messages with seqnums below seqNum, in a single transaction.
+func (s *store) Compact(seqNum int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(s.bucket).Bucket(bucketMessages).Cursor()
+		end := seqNumKey(seqNum)
+		for key, _ := cursor.First(); key != nil && string(key) < string(end); key, _ = cursor.Next() {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+//GetMessages returns the messages saved with seqnums from beginSeqNum to endSeqNum, in order.
+//The messages are read in a single read transaction.
+func (s *store) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
+	msgs := make(chan []byte)
+
+	go func() {
+		defer close(msgs)
+
+		var found [][]byte
+		err := s.db.View(func(tx *bolt.Tx) error {
+			cursor := tx.Bucket(s.bucket).Bucket(bucketMessages).Cursor()
+			end := seqNumKey(endSeqNum)
+			for key, msg := cursor.Seek(seqNumKey(beginSeqNum)); key != nil && string(key) <= string(end); key, msg = cursor.Next() {
+				//values are only valid for the life of the transaction
+				found = append(found, append([]byte(nil), msg...))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return
+		}
+
+		for _, msg := range found {
+			msgs <- msg
+		}
+	}()
+
+	return msgs
+}
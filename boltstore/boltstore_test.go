@@ -0,0 +1,188 @@
+package boltstore
+
+import (
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/storetest"
+	bolt "go.etcd.io/bbolt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func collectMessages(s quickfix.MessageStore, beginSeqNum, endSeqNum int) (msgs []string) {
+	for msg := range s.GetMessages(beginSeqNum, endSeqNum) {
+		msgs = append(msgs, string(msg))
+	}
+
+	return
+}
+
+func TestStore_Persistence(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "boltstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	cfg := `
+[DEFAULT]
+SenderCompID=TW
+BoltStorePath=` + filepath.Join(dirname, "quickfix.db") + `
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=ISLD
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=ARCA
+`
+	settings, _ := quickfix.ParseSettings(strings.NewReader(cfg))
+	factory, err := NewStoreFactory(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+	sessionStore, err := factory.Create(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//sessions sharing the file have their own bucket
+	other, err := factory.Create(quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ARCA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.SaveMessage(2, []byte("other"))
+
+	sessionStore.SetNextSenderMsgSeqNum(3)
+	sessionStore.IncrNextTargetMsgSeqNum()
+	sessionStore.(quickfix.RecoveryStore).SaveRecoveryState(quickfix.RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2})
+	for seqNum, msg := range []string{"hello", "cruel", "world"} {
+		if err := sessionStore.(quickfix.ErrorSavingMessageStore).TrySaveMessage(seqNum+1, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	restarted, err := newStore(sessionID, sessionStore.(*store).db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restarted.NextSenderMsgSeqNum() != 3 || restarted.NextTargetMsgSeqNum() != 2 {
+		t.Errorf("Expected seqnums 3 and 2 got %v and %v", restarted.NextSenderMsgSeqNum(), restarted.NextTargetMsgSeqNum())
+	}
+
+	if state, _ := restarted.RecoveryState(); state != (quickfix.RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2}) {
+		t.Errorf("Unexpected recovery state %+v", state)
+	}
+
+	if !restarted.CreationTime().Equal(sessionStore.CreationTime()) {
+		t.Errorf("Expected creation time %v got %v", sessionStore.CreationTime(), restarted.CreationTime())
+	}
+
+	msgs := collectMessages(restarted, 2, 5)
+	if len(msgs) != 2 || msgs[0] != "cruel" || msgs[1] != "world" {
+		t.Errorf("Unexpected messages %v", msgs)
+	}
+
+	restarted.Reset()
+	if restarted.NextSenderMsgSeqNum() != 1 || len(collectMessages(restarted, 1, 5)) != 0 {
+		t.Error("Expected empty sessionStore after reset")
+	}
+
+	if msg := <-other.GetMessages(2, 2); string(msg) != "other" {
+		t.Errorf("Expected other session unaffected by reset, got %q", msg)
+	}
+}
+
+func TestStore_Compact(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "boltstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	db, err := bolt.Open(filepath.Join(dirname, "quickfix.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s, err := newStore(quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for seqNum, msg := range []string{"hello", "cruel", "world"} {
+		s.SaveMessage(seqNum+1, []byte(msg))
+	}
+
+	if err := s.Compact(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if msgs := collectMessages(s, 1, 3); len(msgs) != 1 || msgs[0] != "world" {
+		t.Errorf("Expected message 3 retained got %v", msgs)
+	}
+}
+
+func TestNewStoreFactory(t *testing.T) {
+	settings := quickfix.NewSettings()
+	sessionSettings := quickfix.NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "TW")
+	sessionSettings.Set(config.TargetCompID, "ISLD")
+	settings.AddSession(sessionSettings)
+
+	if _, err := NewStoreFactory(settings); err == nil {
+		t.Error("Should expect error when settings have no BoltStorePath")
+	}
+}
+
+func TestStore_Conformance(t *testing.T) {
+	var dirnames []string
+	defer func() {
+		for _, dirname := range dirnames {
+			os.RemoveAll(dirname)
+		}
+	}()
+
+	var factory *storeFactory
+	closeFactory := func() {
+		if factory != nil {
+			for _, db := range factory.dbs {
+				db.Close()
+			}
+		}
+	}
+	defer closeFactory()
+
+	storetest.Run(t, storetest.Harness{
+		NewBackend: func(t *testing.T) func([]quickfix.SessionID) quickfix.MessageStoreFactory {
+			dirname, err := ioutil.TempDir("", "boltstore")
+			if err != nil {
+				t.Fatal(err)
+			}
+			dirnames = append(dirnames, dirname)
+
+			return func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory {
+				//the file lock of the previous process is released as it exits
+				closeFactory()
+
+				created, err := NewStoreFactory(storetest.Settings(sessionIDs, map[string]string{config.BoltStorePath: filepath.Join(dirname, "quickfix.db")}))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				factory = created.(*storeFactory)
+				return factory
+			}
+		},
+		Persistent: true,
+	})
+}
@@ -0,0 +1,71 @@
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+//BroadcastResult is the outcome of a broadcast on one session.
+type BroadcastResult struct {
+	SessionID SessionID
+
+	//Err is the error of sending the message on the session, nil if sent.
+	Err error
+}
+
+//Broadcast sends a copy of msgBuilder on each logged on session for which match returns true, for example a trading halt to every drop copy session.
+//Each copy is stamped with the header of its session, the header of msgBuilder is left unchanged. A nil match selects every logged on session.
+//Returns the result for each session selected, ordered by SessionID.
+func Broadcast(msgBuilder MessageBuilder, match func(SessionID) bool) []BroadcastResult {
+	var results []BroadcastResult
+	for _, session := range allSessions() {
+		if match != nil && !match(session.sessionID) {
+			continue
+		}
+
+		if !canSendApp(session.Info().State) {
+			continue
+		}
+
+		result := BroadcastResult{SessionID: session.sessionID}
+		if msg, err := copyMessageBuilder(msgBuilder); err != nil {
+			result.Err = err
+		} else {
+			result.Err = session.submit(context.Background(), msg)
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].SessionID.String() < results[j].SessionID.String()
+	})
+
+	return results
+}
+
+//copyMessageBuilder returns a copy of msgBuilder, modifying the copy does not affect msgBuilder.
+func copyMessageBuilder(msgBuilder MessageBuilder) (MessageBuilder, error) {
+	msgCopy := NewMessageBuilder()
+
+	var fieldMaps = []struct{ from, to MutableFieldMap }{
+		{msgBuilder.Header(), msgCopy.Header()},
+		{msgBuilder.Body(), msgCopy.Body()},
+		{msgBuilder.Trailer(), msgCopy.Trailer()},
+	}
+
+	for _, fieldMaps := range fieldMaps {
+		from, ok := fieldMaps.from.(fieldMap)
+		if !ok {
+			return nil, fmt.Errorf("cannot copy message fields of type %T", fieldMaps.from)
+		}
+
+		to := fieldMaps.to.(fieldMap)
+		//fields are copied, the storage of the fields of a builder is reused once it is reset
+		for tag, field := range from.fieldLookup {
+			to.fieldLookup[tag] = field.clone()
+		}
+	}
+
+	return msgCopy, nil
+}
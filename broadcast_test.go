@@ -0,0 +1,73 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"strings"
+	"testing"
+)
+
+func TestBroadcast(t *testing.T) {
+	var sessions []*Session
+	for _, targetCompID := range []string{"DROPCOPY_A", "DROPCOPY_B", "DROPCOPY_OFFLINE", "ORDERS"} {
+		sessionID := SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "BROADCAST", TargetCompID: targetCompID}
+		if err := createSession(sessionID, NewMemoryStoreFactory(), NewSessionSettings(), NewNullLogFactory(), &TestClient{}); err != nil {
+			t.Fatal(err)
+		}
+		defer unregisterSession(sessionID)
+
+		session, _ := LookupSession(sessionID)
+		session.messageOut = make(chan []byte, 10)
+		if targetCompID != "DROPCOPY_OFFLINE" {
+			session.transition(inSession{}, nil)
+		}
+		sessions = append(sessions, session)
+	}
+
+	halt := NewMessageBuilder()
+	halt.Header().Set(field.NewMsgType("h"))
+	halt.Body().Set(field.NewTradingSessionID("HALTED"))
+
+	results := Broadcast(halt, func(sessionID SessionID) bool {
+		return sessionID.SenderCompID == "BROADCAST" && strings.HasPrefix(sessionID.TargetCompID, "DROPCOPY")
+	})
+
+	if len(results) != 2 || results[0].SessionID.TargetCompID != "DROPCOPY_A" || results[1].SessionID.TargetCompID != "DROPCOPY_B" {
+		t.Fatalf("Expected results for logged on drop copy sessions got %v", results)
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("Unexpected error sending to %v: %v", result.SessionID, result.Err)
+		}
+	}
+
+	for _, session := range sessions {
+		expected := 0
+		if session.sessionID.TargetCompID == "DROPCOPY_A" || session.sessionID.TargetCompID == "DROPCOPY_B" {
+			expected = 1
+		}
+
+		if len(session.messageOut) != expected {
+			t.Errorf("Expected %v messages sent to %v got %v", expected, session.sessionID, len(session.messageOut))
+			continue
+		}
+
+		if expected == 0 {
+			continue
+		}
+
+		msg, _ := parseMessage(<-session.messageOut)
+		targetCompID, tradingSessionID := new(fix.StringValue), new(fix.StringValue)
+		msg.Header.GetField(tag.TargetCompID, targetCompID)
+		msg.Body.GetField(tag.TradingSessionID, tradingSessionID)
+		if targetCompID.Value != session.sessionID.TargetCompID || tradingSessionID.Value != "HALTED" {
+			t.Errorf("Expected halt stamped for %v got TargetCompID %v", session.sessionID, targetCompID.Value)
+		}
+	}
+
+	if halt.Header().Has(tag.TargetCompID) {
+		t.Error("Expected broadcast message header unchanged")
+	}
+}
@@ -0,0 +1,114 @@
+package quickfix
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	//minBufferClassBits and maxBufferClassBits bound the size classes of the buffer pool, from 64 bytes to 64KiB
+	minBufferClassBits = 6
+	maxBufferClassBits = 16
+
+	bufferClasses = maxBufferClassBits - minBufferClassBits + 1
+)
+
+//BufferPoolStats count the buffers taken from and returned to the buffer pool of the engine, since the process started.
+type BufferPoolStats struct {
+	//Gets counts the buffers taken, Hits those reused from the pool rather than allocated.
+	Gets int64 `json:"gets"`
+	Hits int64 `json:"hits"`
+
+	//Puts counts the buffers returned to the pool.
+	Puts int64 `json:"puts"`
+
+	//Oversized counts the buffers taken or returned larger than the largest size class, 64KiB, which are not pooled.
+	Oversized int64 `json:"oversized"`
+
+	//HitRate is Hits over Gets, 0 before any buffer is taken.
+	HitRate float64 `json:"hitRate"`
+}
+
+//bufferPool pools the byte slices used by the parsers, serializers, stores, logs and connection writers of every session in size classes
+//of powers of two, so that a slice returned by one layer is reused by any layer needing a buffer of about its size. The slices are
+//scratch buffers, the messages read by the parsers and rebuilt for resends being copied out of them at their exact size, to be kept
+//by the application and the stores. Messages built by MessageBuilders are allocated at their exact size without a scratch buffer.
+type bufferPool struct {
+	classes [bufferClasses]sync.Pool
+
+	//boxes pools the pointers slices are pooled by, so that returning a slice does not allocate
+	boxes sync.Pool
+}
+
+var buffers bufferPool
+
+//getBuffer returns an empty slice with a capacity of at least size, reused from the pool if one of its size class was returned.
+func getBuffer(size int) []byte {
+	class := 0
+	if size > 1<<minBufferClassBits {
+		class = bits.Len(uint(size-1)) - minBufferClassBits
+	}
+
+	if class >= bufferClasses {
+		recordBufferGet(false, true)
+		return make([]byte, 0, size)
+	}
+
+	if box, ok := buffers.classes[class].Get().(*[]byte); ok {
+		buf := (*box)[:0]
+		*box = nil
+		buffers.boxes.Put(box)
+
+		recordBufferGet(true, false)
+		return buf
+	}
+
+	recordBufferGet(false, false)
+	return make([]byte, 0, 1<<uint(class+minBufferClassBits))
+}
+
+//putBuffer returns buf to the pool, in the largest size class its capacity holds. buf must no longer be used, nor referenced.
+//Slices smaller than the smallest class are dropped.
+func putBuffer(buf []byte) {
+	class := bits.Len(uint(cap(buf))) - 1 - minBufferClassBits
+	if class < 0 {
+		return
+	}
+
+	if class >= bufferClasses {
+		recordBufferPut(true)
+		return
+	}
+
+	box, ok := buffers.boxes.Get().(*[]byte)
+	if !ok {
+		box = new([]byte)
+	}
+	*box = buf[:0]
+	buffers.classes[class].Put(box)
+
+	recordBufferPut(false)
+}
+
+//recordBufferGet counts a buffer taken from the pool, a hit if reused, oversized if larger than the largest class.
+func recordBufferGet(hit, oversized bool) {
+	stats := &globalDebugCounters.BufferPool
+	atomic.AddInt64(&stats.Gets, 1)
+	if hit {
+		atomic.AddInt64(&stats.Hits, 1)
+	}
+	if oversized {
+		atomic.AddInt64(&stats.Oversized, 1)
+	}
+}
+
+//recordBufferPut counts a buffer returned to the pool, oversized if dropped for being larger than the largest class.
+func recordBufferPut(oversized bool) {
+	stats := &globalDebugCounters.BufferPool
+	if oversized {
+		atomic.AddInt64(&stats.Oversized, 1)
+		return
+	}
+	atomic.AddInt64(&stats.Puts, 1)
+}
@@ -0,0 +1,104 @@
+package quickfix
+
+import (
+	"strings"
+	"testing"
+)
+
+func bufferPoolStats() BufferPoolStats {
+	return loadDebugCounters().BufferPool
+}
+
+func TestGetBuffer_SizeClasses(t *testing.T) {
+	var testCases = []struct {
+		size, capacity int
+	}{
+		{0, 64},
+		{1, 64},
+		{64, 64},
+		{65, 128},
+		{4096, 4096},
+		{5000, 8192},
+		{1 << 16, 1 << 16},
+		{1<<16 + 1, 1<<16 + 1},
+	}
+
+	for _, tc := range testCases {
+		buf := getBuffer(tc.size)
+		if len(buf) != 0 || cap(buf) != tc.capacity {
+			t.Errorf("Expected an empty buffer of %v bytes for %v, got %v of %v", tc.capacity, tc.size, len(buf), cap(buf))
+		}
+	}
+}
+
+func TestPutBuffer_Reused(t *testing.T) {
+	before := bufferPoolStats()
+
+	//a buffer returned is taken again by the next get of its class, unless dropped by the runtime
+	hit := false
+	for attempt := 0; attempt < 10 && !hit; attempt++ {
+		buf := append(getBuffer(1000), "reused"...)
+		putBuffer(buf)
+
+		reused := getBuffer(600)
+		hit = cap(reused) == cap(buf) && &reused[:1][0] == &buf[0]
+		if len(reused) != 0 {
+			t.Fatalf("Expected an empty buffer, got %q", reused)
+		}
+	}
+
+	if !hit {
+		t.Error("Expected a buffer returned to be reused")
+	}
+
+	after := bufferPoolStats()
+	if after.Gets-before.Gets < 2 || after.Hits == before.Hits || after.Puts == before.Puts {
+		t.Errorf("Expected gets, hits and puts counted, got %+v then %+v", before, after)
+	}
+
+	putBuffer(make([]byte, 0, 1<<17))
+	putBuffer(make([]byte, 0, 10))
+	if oversized := bufferPoolStats().Oversized - after.Oversized; oversized != 1 {
+		t.Errorf("Expected 1 oversized buffer, got %v", oversized)
+	}
+
+	if state := CurrentDebugState(); state.Counters.BufferPool.HitRate <= 0 || state.Counters.BufferPool.HitRate > 1 {
+		t.Errorf("Expected a hit rate, got %+v", state.Counters.BufferPool)
+	}
+}
+
+func TestParser_ReturnsReadBuffers(t *testing.T) {
+	large := rawMessage("FIX.4.2", "35=D\00134=1\00158="+strings.Repeat("x", 3*defaultBufSize)+"\001")
+	small := rawMessage("FIX.4.2", "35=0\00134=2\001")
+	parser := newParser(strings.NewReader(string(large) + string(small)))
+
+	before := bufferPoolStats()
+	msg, err := parser.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the read buffers outgrown by the message are returned to the pool
+	if puts := bufferPoolStats().Puts - before.Puts; puts < 2 {
+		t.Errorf("Expected the outgrown buffers returned, got %v puts", puts)
+	}
+
+	next, err := parser.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	puts := bufferPoolStats().Puts
+	parser.release()
+	if bufferPoolStats().Puts != puts+1 {
+		t.Error("Expected the read buffer returned once released")
+	}
+
+	//the messages are copied out of the read buffer, and kept intact once it is reused
+	reused := getBuffer(4 * defaultBufSize)
+	reused = append(reused, strings.Repeat("y", cap(reused))...)
+	if string(msg) != string(large) || string(next) != string(small) {
+		t.Errorf("Expected the messages read kept, got %q and %q", msg, next)
+	}
+	putBuffer(reused)
+}
@@ -0,0 +1,73 @@
+package quickfix
+
+import "time"
+
+//Clock is the source of time for a session: SendingTime, receive times checked for latency, heartbeat and logout timeouts, the session schedule, and the throttle.
+//Sessions use SystemClock unless the Application implements ClockProvider, for example to run sessions against simulated time.
+//The creation time of the MessageStore is not taken from the Clock.
+type Clock interface {
+	Now() time.Time
+
+	//AfterFunc calls f in its own goroutine once d has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+
+	//After sends the current time on the returned channel once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+
+	//NewTicker returns a Ticker sending the current time every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+//Timer is a timer created by Clock.AfterFunc, as time.Timer.
+type Timer interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+//Ticker is a ticker created by Clock.NewTicker, as time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+//ClockProvider may be implemented by an Application to supply the Clock of each session.
+//Returning the same Clock for every session applies it to all sessions of the Acceptor or Initiator.
+type ClockProvider interface {
+	//Clock is called once, when the session is created.
+	Clock(sessionID SessionID) Clock
+}
+
+//SystemClock is the Clock of the real time.
+type SystemClock struct{}
+
+//Now implements Clock.
+func (SystemClock) Now() time.Time { return time.Now() }
+
+//AfterFunc implements Clock.
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+//After implements Clock.
+func (SystemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+//NewTicker implements Clock.
+func (SystemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
+
+//sessionClock returns the Clock of the session, SystemClock if not set.
+func (s *Session) sessionClock() Clock {
+	if s.clock == nil {
+		return SystemClock{}
+	}
+
+	return s.clock
+}
+
+//now returns the current time of the Clock of the session.
+func (s *Session) now() time.Time {
+	return s.sessionClock().Now()
+}
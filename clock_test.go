@@ -0,0 +1,148 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"sync"
+	"testing"
+	"time"
+)
+
+//manualClock is a Clock advanced only by Advance.
+type manualClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	clock    *manualClock
+	deadline time.Time
+	f        func()
+	active   bool
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+
+	active := t.active
+	t.active = false
+	return active
+}
+
+func (t *manualTimer) Reset(d time.Duration) bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+
+	active := t.active
+	t.deadline, t.active = t.clock.now.Add(d), true
+	return active
+}
+
+func (c *manualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *manualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	timer := &manualTimer{clock: c, deadline: c.now.Add(d), f: f, active: true}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.AfterFunc(d, func() { ch <- c.Now() })
+	return ch
+}
+
+func (c *manualClock) NewTicker(d time.Duration) Ticker {
+	panic("not implemented")
+}
+
+//Advance moves the clock forward by d, calling the functions of the timers due.
+func (c *manualClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	c.now = c.now.Add(d)
+
+	var due []func()
+	for _, timer := range c.timers {
+		if timer.active && !timer.deadline.After(c.now) {
+			timer.active = false
+			due = append(due, timer.f)
+		}
+	}
+	c.lock.Unlock()
+
+	for _, f := range due {
+		f()
+	}
+}
+
+type clockClient struct {
+	TestClient
+	clock Clock
+}
+
+func (c *clockClient) Clock(sessionID SessionID) Clock {
+	return c.clock
+}
+
+func TestSession_Clock(t *testing.T) {
+	clock := &manualClock{now: time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)}
+	sessionID := SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "SIMULATED"}
+	if err := createSession(sessionID, NewMemoryStoreFactory(), NewSessionSettings(), NewNullLogFactory(), &clockClient{clock: clock}); err != nil {
+		t.Fatal(err)
+	}
+	defer unregisterSession(sessionID)
+
+	session, _ := LookupSession(sessionID)
+	session.messageOut = make(chan []byte, 10)
+	session.sessionEvent = make(chan event, 10)
+	session.setHeartBtInt(30 * time.Second)
+
+	if err := session.send(newSendQueueTestMessage("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, _ := parseMessage(<-session.messageOut)
+	sendingTime := new(field.SendingTimeField)
+	if err := msg.Header.Get(sendingTime); err != nil || !sendingTime.Value.Equal(clock.Now()) {
+		t.Errorf("Expected SendingTime %v got %v", clock.Now(), sendingTime.Value)
+	}
+
+	clock.Advance(29 * time.Second)
+	if len(session.sessionEvent) != 0 {
+		t.Fatal("Unexpected heartbeat before HeartBtInt elapsed")
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case evt := <-session.sessionEvent:
+		if evt != needHeartbeat {
+			t.Errorf("Expected heartbeat needed got %v", evt)
+		}
+	default:
+		t.Error("Expected heartbeat needed once HeartBtInt elapsed on the clock")
+	}
+
+	//SendingTime is checked against the clock, not the real time
+	builder := NewMessageBuilder()
+	builder.Header().Set(field.NewBeginString(fix.BeginString_FIX42))
+	builder.Header().Set(field.NewMsgType("0"))
+	builder.Header().Set(field.NewSenderCompID("SIMULATED"))
+	builder.Header().Set(field.NewTargetCompID("TW"))
+	builder.Header().Set(fix.NewUTCTimestampField(tag.SendingTime, clock.Now()))
+	msgBytes, _ := builder.Build()
+	heartbeat, _ := parseMessage(msgBytes)
+
+	if reject := session.checkSendingTime(*heartbeat); reject != nil {
+		t.Errorf("Unexpected latency reject against the clock: %v", reject)
+	}
+}
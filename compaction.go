@@ -0,0 +1,111 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"time"
+)
+
+//compactChunk is the number of seqnums read at a time by CompactStore.
+const compactChunk = 1000
+
+//ArchiveSink receives the messages removed from a MessageStore by CompactStore.
+type ArchiveSink interface {
+	//Archive is called for each message removed, in seqnum order. If an error is returned the message and those after it are kept.
+	Archive(sessionID SessionID, seqNum int, msg []byte) error
+}
+
+//CompactableStore may be implemented by a MessageStore to remove messages no longer needed for resend, without a Reset.
+//Compact may be called outside the session goroutine, concurrently with the session saving messages.
+type CompactableStore interface {
+	//Compact removes the messages with seqnums below seqNum.
+	Compact(seqNum int) error
+}
+
+//CompactStore archives to sink, then removes from the store of the session, the messages sent more than retention ago, by SendingTime.
+//The session is not stopped, messages removed can no longer be resent and are gap filled if requested.
+//Returns the number of messages removed, sink may be nil to discard them.
+func CompactStore(sessionID SessionID, retention time.Duration, sink ArchiveSink) (int, error) {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return session.compactStore(session.now().Add(-retention), sink)
+}
+
+//compactStore archives and removes the messages sent before cutoff.
+func (s *Session) compactStore(cutoff time.Time, sink ArchiveSink) (int, error) {
+	store, ok := s.store.(CompactableStore)
+	if !ok {
+		return 0, fmt.Errorf("store of %v does not support compaction", s.sessionID)
+	}
+
+	compactTo, archived := 0, 0
+	var archiveErr error
+	lastSeqNum := s.store.NextSenderMsgSeqNum() - 1
+
+	for begin := 1; begin <= lastSeqNum && compactTo == 0; begin += compactChunk {
+		for msg := range s.store.GetMessages(begin, begin+compactChunk-1) {
+			//messages after the first retained are drained
+			if compactTo != 0 || archiveErr != nil {
+				continue
+			}
+
+			seqNum, sendingTime, err := storedMessageHeader(msg)
+			if err != nil {
+				archiveErr = fmt.Errorf("cannot read stored message: %v", err)
+				continue
+			}
+
+			if !sendingTime.Before(cutoff) {
+				compactTo = seqNum
+				continue
+			}
+
+			if sink != nil {
+				if archiveErr = sink.Archive(s.sessionID, seqNum, msg); archiveErr != nil {
+					compactTo = seqNum
+					continue
+				}
+			}
+			archived++
+		}
+
+		if archiveErr != nil && compactTo == 0 {
+			return 0, archiveErr
+		}
+	}
+
+	if compactTo == 0 {
+		compactTo = lastSeqNum + 1
+	}
+
+	if err := store.Compact(compactTo); err != nil {
+		return 0, err
+	}
+
+	if archived > 0 {
+		logEventf(s.log, LogLevelInfo, LogCategoryStore, "Compacted store, removed %d messages before seqnum %d", archived, compactTo)
+	}
+
+	return archived, archiveErr
+}
+
+//storedMessageHeader returns the MsgSeqNum and SendingTime of a stored message.
+func storedMessageHeader(msg []byte) (int, time.Time, error) {
+	header := parseHeader(msg)
+
+	seqNum := new(fix.IntValue)
+	if err := header.GetField(tag.MsgSeqNum, seqNum); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	sendingTime := new(fix.UTCTimestampValue)
+	if err := header.GetField(tag.SendingTime, sendingTime); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return seqNum.Value, sendingTime.Value, nil
+}
@@ -0,0 +1,165 @@
+package quickfix
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+//recordingSink records the seqnums archived, failing with err from failAt.
+type recordingSink struct {
+	archived []int
+	failAt   int
+}
+
+func (s *recordingSink) Archive(sessionID SessionID, seqNum int, msg []byte) error {
+	if s.failAt != 0 && seqNum >= s.failAt {
+		return errors.New("sink unavailable")
+	}
+
+	s.archived = append(s.archived, seqNum)
+	return nil
+}
+
+//newStoredMessage returns a stored message sent at sendingTime.
+func newStoredMessage(seqNum int, sendingTime time.Time) []byte {
+	return rawMessage("FIX.4.2", fmt.Sprintf("35=D\00134=%d\00149=TW\00152=%v\00156=ISLD\001", seqNum, sendingTime.UTC().Format("20060102-15:04:05.000")))
+}
+
+func newTestCompactionSession(store MessageStore) *Session {
+	session := newTestAdminSession(nil)
+	session.store = store
+
+	now := time.Now()
+	for seqNum := 1; seqNum <= 5; seqNum++ {
+		//messages 1 to 3 are two days old
+		sendingTime := now
+		if seqNum <= 3 {
+			sendingTime = now.Add(-48 * time.Hour)
+		}
+
+		store.SaveMessage(seqNum, newStoredMessage(seqNum, sendingTime))
+		store.IncrNextSenderMsgSeqNum()
+	}
+
+	return session
+}
+
+func storedSeqNums(store MessageStore) (seqNums []int) {
+	for msg := range store.GetMessages(1, 5) {
+		seqNum, _, _ := storedMessageHeader(msg)
+		seqNums = append(seqNums, seqNum)
+	}
+
+	return
+}
+
+func TestSession_CompactStore(t *testing.T) {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	session := newTestCompactionSession(store)
+
+	sink := new(recordingSink)
+	archived, err := session.compactStore(time.Now().Add(-24*time.Hour), sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if archived != 3 || len(sink.archived) != 3 || sink.archived[2] != 3 {
+		t.Errorf("Expected messages 1 to 3 archived got %v", sink.archived)
+	}
+
+	if seqNums := storedSeqNums(store); len(seqNums) != 2 || seqNums[0] != 4 {
+		t.Errorf("Expected messages 4 and 5 retained got %v", seqNums)
+	}
+
+	if store.NextSenderMsgSeqNum() != 6 {
+		t.Error("Expected sequence numbers unaffected by compaction")
+	}
+}
+
+func TestSession_CompactStoreArchiveError(t *testing.T) {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	session := newTestCompactionSession(store)
+
+	archived, err := session.compactStore(time.Now().Add(-24*time.Hour), &recordingSink{failAt: 2})
+	if err == nil {
+		t.Error("Expected archive error")
+	}
+
+	if seqNums := storedSeqNums(store); archived != 1 || len(seqNums) != 4 || seqNums[0] != 2 {
+		t.Errorf("Expected only message 1 removed, got %v retained", seqNums)
+	}
+}
+
+func TestFileStore_Compact(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "filestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	store := newTestFileStore(t, dirname)
+	session := newTestCompactionSession(store)
+	if _, err := session.compactStore(time.Now().Add(-24*time.Hour), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveMessage(store, 6, []byte("after")); err != nil {
+		t.Fatal(err)
+	}
+	store.close()
+
+	store = newTestFileStore(t, dirname)
+	defer store.close()
+
+	if seqNums := storedSeqNums(store); len(seqNums) != 2 || seqNums[0] != 4 {
+		t.Errorf("Expected messages 4 and 5 retained got %v", seqNums)
+	}
+
+	if msgs := collectMessages(store, 6, 6); len(msgs) != 1 || msgs[0] != "after" {
+		t.Errorf("Expected message saved after compaction, got %v", msgs)
+	}
+
+	if store.NextSenderMsgSeqNum() != 6 {
+		t.Errorf("Expected NextSenderMsgSeqNum 6 got %v", store.NextSenderMsgSeqNum())
+	}
+}
+
+func TestFileStore_RecoverCompaction(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "filestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	store := newTestFileStore(t, dirname)
+	store.SaveMessage(1, []byte("hello"))
+	store.SaveMessage(2, []byte("world"))
+
+	//interrupted after the compaction committed, before the body file was replaced
+	ioutil.WriteFile(store.bodyFname+".tmp", []byte("world"), os.ModePerm)
+	ioutil.WriteFile(store.headerFname+".compact", []byte("2,0,5\n"), os.ModePerm)
+	store.close()
+
+	store = newTestFileStore(t, dirname)
+	if msgs := collectMessages(store, 1, 2); len(msgs) != 1 || msgs[0] != "world" {
+		t.Errorf("Expected compaction completed, got %v", msgs)
+	}
+	store.close()
+
+	//interrupted before the compaction committed
+	ioutil.WriteFile(store.bodyFname+".tmp", []byte("partial"), os.ModePerm)
+	store = newTestFileStore(t, dirname)
+	defer store.close()
+
+	if msgs := collectMessages(store, 1, 2); len(msgs) != 1 || msgs[0] != "world" {
+		t.Errorf("Expected store unchanged, got %v", msgs)
+	}
+
+	if _, err := os.Stat(store.bodyFname + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Expected uncommitted compaction discarded")
+	}
+}
@@ -2,18 +2,178 @@
 package config
 
 const (
-	BeginString             string = "BeginString"
-	SenderCompID            string = "SenderCompID"
-	TargetCompID            string = "TargetCompID"
-	SessionQualifier        string = "SessionQualifier"
-	SocketAcceptPort        string = "SocketAcceptPort"
-	SocketConnectHost       string = "SocketConnectHost"
-	SocketConnectPort       string = "SocketConnectPort"
-	DefaultApplVerID        string = "DefaultApplVerID"
-	DataDictionary          string = "DataDictionary"
-	TransportDataDictionary string = "TransportDataDictionary"
-	AppDataDictionary       string = "AppDataDictionary"
-	ResetOnLogon            string = "ResetOnLogon"
-	HeartBtInt              string = "HeartBtInt"
-	FileLogPath             string = "FileLogPath"
+	ConnectionType                  string = "ConnectionType"
+	BeginString                     string = "BeginString"
+	SenderCompID                    string = "SenderCompID"
+	TargetCompID                    string = "TargetCompID"
+	SessionQualifier                string = "SessionQualifier"
+	SocketAcceptHost                string = "SocketAcceptHost"
+	SocketAcceptPort                string = "SocketAcceptPort"
+	AcceptorTemplate                string = "AcceptorTemplate"
+	SocketConnectHost               string = "SocketConnectHost"
+	SocketConnectPort               string = "SocketConnectPort"
+	SocketConnectFailover           string = "SocketConnectFailover"
+	SocketConnectResolveEachAttempt string = "SocketConnectResolveEachAttempt"
+	SocketConnectAddressPreference  string = "SocketConnectAddressPreference"
+	SocketConnectAttemptDelay       string = "SocketConnectAttemptDelay"
+	SocketConnectAttemptTimeout     string = "SocketConnectAttemptTimeout"
+	SocketUseSSL                    string = "SocketUseSSL"
+	SocketCertificateFile           string = "SocketCertificateFile"
+	SocketPrivateKeyFile            string = "SocketPrivateKeyFile"
+	SocketCAFile                    string = "SocketCAFile"
+	SocketMinimumTLSVersion         string = "SocketMinimumTLSVersion"
+	SocketCipherSuites              string = "SocketCipherSuites"
+	SocketServerName                string = "SocketServerName"
+	SocketInsecureSkipVerify        string = "SocketInsecureSkipVerify"
+	SocketClientAuth                string = "SocketClientAuth"
+	SocketPinnedCertificates        string = "SocketPinnedCertificates"
+	SocketCRLFile                   string = "SocketCRLFile"
+	SocketOCSPPolicy                string = "SocketOCSPPolicy"
+	SocketNodelay                   string = "SocketNodelay"
+	SocketKeepAliveInterval         string = "SocketKeepAliveInterval"
+	SocketReceiveBufferSize         string = "SocketReceiveBufferSize"
+	SocketSendBufferSize            string = "SocketSendBufferSize"
+	SocketTrafficClass              string = "SocketTrafficClass"
+	SocketWriteTimeout              string = "SocketWriteTimeout"
+	SocketProxyProtocol             string = "SocketProxyProtocol"
+	AllowedRemoteAddresses          string = "AllowedRemoteAddresses"
+	DeniedRemoteAddresses           string = "DeniedRemoteAddresses"
+	ReconnectInterval               string = "ReconnectInterval"
+	ReconnectBackoffMultiplier      string = "ReconnectBackoffMultiplier"
+	MaxReconnectInterval            string = "MaxReconnectInterval"
+	ReconnectJitter                 string = "ReconnectJitter"
+	MaxReconnectAttempts            string = "MaxReconnectAttempts"
+	DefaultApplVerID                string = "DefaultApplVerID"
+	DataDictionary                  string = "DataDictionary"
+	TransportDataDictionary         string = "TransportDataDictionary"
+	AppDataDictionary               string = "AppDataDictionary"
+	CounterpartyDataDictionary      string = "CounterpartyDataDictionary"
+	ResetOnLogon                    string = "ResetOnLogon"
+	ResetOnLogout                   string = "ResetOnLogout"
+	ResetOnDisconnect               string = "ResetOnDisconnect"
+	HeartBtInt                      string = "HeartBtInt"
+	EnforceHeartBtInt               string = "EnforceHeartBtInt"
+	TestRequestDelayMultiplier      string = "TestRequestDelayMultiplier"
+	HeartBeatTimeoutMultiplier      string = "HeartBeatTimeoutMultiplier"
+	LogType                         string = "LogType"
+	FileLogPath                     string = "FileLogPath"
+	FileLogRotateDaily              string = "FileLogRotateDaily"
+	FileLogMaxSize                  string = "FileLogMaxSize"
+	FileLogMaxAge                   string = "FileLogMaxAge"
+	FileLogCompress                 string = "FileLogCompress"
+	LogMaskTags                     string = "LogMaskTags"
+	LogEventLevel                   string = "LogEventLevel"
+	LogEventCategories              string = "LogEventCategories"
+	LogSampleRate                   string = "LogSampleRate"
+	LogSampleMsgTypes               string = "LogSampleMsgTypes"
+	LogSummaryInterval              string = "LogSummaryInterval"
+	AsyncLogQueueDepth              string = "AsyncLogQueueDepth"
+	AsyncLogOverflow                string = "AsyncLogOverflow"
+	KafkaLogBrokers                 string = "KafkaLogBrokers"
+	KafkaLogMessageTopic            string = "KafkaLogMessageTopic"
+	KafkaLogEventTopic              string = "KafkaLogEventTopic"
+	KafkaLogFormat                  string = "KafkaLogFormat"
+	SyslogNetwork                   string = "SyslogNetwork"
+	SyslogAddress                   string = "SyslogAddress"
+	SyslogFacility                  string = "SyslogFacility"
+	SyslogAppName                   string = "SyslogAppName"
+	JournaldSocket                  string = "JournaldSocket"
+	JournaldIdentifier              string = "JournaldIdentifier"
+	WireCapturePath                 string = "WireCapturePath"
+	WireCapture                     string = "WireCapture"
+	ArchivePrefix                   string = "ArchivePrefix"
+	ArchiveMaxSize                  string = "ArchiveMaxSize"
+	ArchiveInterval                 string = "ArchiveInterval"
+	ArchiveQueueDepth               string = "ArchiveQueueDepth"
+	ArchiveOverflow                 string = "ArchiveOverflow"
+	MessageStoreType                string = "MessageStoreType"
+	FileStorePath                   string = "FileStorePath"
+	FileStoreSync                   string = "FileStoreSync"
+	AsyncStoreQueueDepth            string = "AsyncStoreQueueDepth"
+	AsyncStoreMaxBatch              string = "AsyncStoreMaxBatch"
+	AsyncStoreFlushInterval         string = "AsyncStoreFlushInterval"
+	SQLStoreDriver                  string = "SQLStoreDriver"
+	SQLStoreDataSourceName          string = "SQLStoreDataSourceName"
+	SQLStoreMaxOpenConns            string = "SQLStoreMaxOpenConns"
+	SQLStoreMaxIdleConns            string = "SQLStoreMaxIdleConns"
+	SQLStoreConnMaxLifetime         string = "SQLStoreConnMaxLifetime"
+	SQLStoreBatchSize               string = "SQLStoreBatchSize"
+	MongoStoreConnection            string = "MongoStoreConnection"
+	MongoStoreDatabase              string = "MongoStoreDatabase"
+	MongoStoreEngine                string = "MongoStoreEngine"
+	MongoStoreRetention             string = "MongoStoreRetention"
+	MongoStoreWriteConcern          string = "MongoStoreWriteConcern"
+	MongoStoreJournal               string = "MongoStoreJournal"
+	RedisStoreAddrs                 string = "RedisStoreAddrs"
+	RedisStoreCluster               string = "RedisStoreCluster"
+	RedisStorePassword              string = "RedisStorePassword"
+	RedisStoreDB                    string = "RedisStoreDB"
+	RedisStoreKeyPrefix             string = "RedisStoreKeyPrefix"
+	BoltStorePath                   string = "BoltStorePath"
+	BoltStoreNoSync                 string = "BoltStoreNoSync"
+	SQLiteStorePath                 string = "SQLiteStorePath"
+	SQLiteStoreSynchronous          string = "SQLiteStoreSynchronous"
+	SQLiteStoreBusyTimeout          string = "SQLiteStoreBusyTimeout"
+	DynamoStoreTable                string = "DynamoStoreTable"
+	DynamoStoreRegion               string = "DynamoStoreRegion"
+	DynamoStoreEndpoint             string = "DynamoStoreEndpoint"
+	DynamoStoreMaxBatch             string = "DynamoStoreMaxBatch"
+	DuplicateTagPolicy              string = "DuplicateTagPolicy"
+	DuplicateLogonPolicy            string = "DuplicateLogonPolicy"
+	DeliverPossDup                  string = "DeliverPossDup"
+	PersistMessages                 string = "PersistMessages"
+	ResendRequestChunkSize          string = "ResendRequestChunkSize"
+	SeqNumTooLowPolicy              string = "SeqNumTooLowPolicy"
+	PoisonMessageThreshold          string = "PoisonMessageThreshold"
+	PoisonMessagePolicy             string = "PoisonMessagePolicy"
+	SeqNumResetGraceWindow          string = "SeqNumResetGraceWindow"
+	MaxSendQueueDepth               string = "MaxSendQueueDepth"
+	SendQueueOverflow               string = "SendQueueOverflow"
+	SlowConsumerMaxMessages         string = "SlowConsumerMaxMessages"
+	SlowConsumerMaxBytes            string = "SlowConsumerMaxBytes"
+	ThrottleRate                    string = "ThrottleRate"
+	ThrottleBurst                   string = "ThrottleBurst"
+	ThrottlePolicy                  string = "ThrottlePolicy"
+	MaxMessageSize                  string = "MaxMessageSize"
+	CheckLatency                    string = "CheckLatency"
+	MaxLatency                      string = "MaxLatency"
+	LogoutOnMaxLatency              string = "LogoutOnMaxLatency"
+	StartTime                       string = "StartTime"
+	EndTime                         string = "EndTime"
+	StartDay                        string = "StartDay"
+	EndDay                          string = "EndDay"
+	Weekdays                        string = "Weekdays"
+	TimeZone                        string = "TimeZone"
+	Username                        string = "Username"
+	Password                        string = "Password"
+	RawData                         string = "RawData"
+	SendNextExpectedMsgSeqNum       string = "SendNextExpectedMsgSeqNum"
+	SecretsProviderType             string = "SecretsProviderType"
+	SecretsCacheTTL                 string = "SecretsCacheTTL"
+	VaultAddress                    string = "VaultAddress"
+	VaultMount                      string = "VaultMount"
+	VaultNamespace                  string = "VaultNamespace"
+	VaultTokenFile                  string = "VaultTokenFile"
+	SecretsManagerRegion            string = "SecretsManagerRegion"
+	SecretsManagerEndpoint          string = "SecretsManagerEndpoint"
+	Profile                         string = "Profile"
+	RecordLatency                   string = "RecordLatency"
+	MessageTimestamps               string = "MessageTimestamps"
+	ResendStormThreshold            string = "ResendStormThreshold"
+	ResendStormWindow               string = "ResendStormWindow"
+	LogonRejectThreshold            string = "LogonRejectThreshold"
+	PersistReceivedMessages         string = "PersistReceivedMessages"
+	AuditIndexTags                  string = "AuditIndexTags"
+	WebhookURLs                     string = "WebhookURLs"
+	WebhookSecret                   string = "WebhookSecret"
+	WebhookMaxRetries               string = "WebhookMaxRetries"
+	WebhookRetryInterval            string = "WebhookRetryInterval"
+	WebhookQueueDepth               string = "WebhookQueueDepth"
+	OutboundQueueDepth              string = "OutboundQueueDepth"
+	OutboundQueueOverflow           string = "OutboundQueueOverflow"
+	WriteBatching                   string = "WriteBatching"
+	WriteBatchMaxBytes              string = "WriteBatchMaxBytes"
+	WriteBatchInterval              string = "WriteBatchInterval"
+	ThreadingModel                  string = "ThreadingModel"
+	WorkerPoolSize                  string = "WorkerPoolSize"
 )
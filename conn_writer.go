@@ -0,0 +1,504 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"sync"
+	"time"
+)
+
+//writeLimits protect a session from a counterparty not reading the messages sent.
+type writeLimits struct {
+	//timeout bounds each write to the connection, 0 for no limit
+	timeout time.Duration
+
+	//maxMessages and maxBytes bound the messages buffered while the connection is written, 0 for no limit.
+	//Messages are written unbuffered, each before the next is sent, without either limit.
+	maxMessages, maxBytes int
+}
+
+//newWriteLimits returns the limits configured by SocketWriteTimeout, in seconds, SlowConsumerMaxMessages and SlowConsumerMaxBytes.
+func newWriteLimits(settings *SessionSettings) (writeLimits, error) {
+	var limits writeLimits
+
+	if settings.HasSetting(config.SocketWriteTimeout) {
+		seconds, err := settings.IntSetting(config.SocketWriteTimeout)
+		if err != nil {
+			return limits, err
+		}
+
+		if seconds <= 0 {
+			return limits, fmt.Errorf("%v must be a positive number", config.SocketWriteTimeout)
+		}
+		limits.timeout = time.Duration(seconds) * time.Second
+	}
+
+	for _, limit := range []struct {
+		setting string
+		max     *int
+	}{
+		{config.SlowConsumerMaxMessages, &limits.maxMessages},
+		{config.SlowConsumerMaxBytes, &limits.maxBytes},
+	} {
+		if !settings.HasSetting(limit.setting) {
+			continue
+		}
+
+		max, err := settings.IntSetting(limit.setting)
+		if err != nil {
+			return limits, err
+		}
+
+		if max <= 0 {
+			return limits, fmt.Errorf("%v must be a positive number", limit.setting)
+		}
+		*limit.max = max
+	}
+
+	return limits, nil
+}
+
+//defaultWriteBatchMaxBytes bounds the bytes of the messages coalesced into a single write.
+const defaultWriteBatchMaxBytes = 64 * 1024
+
+//writeBatching coalesces the messages queued while the connection is written into a single write, reducing the writes of bursts.
+//The zero writeBatching, the default, writes each message on its own as it is sent.
+type writeBatching struct {
+	enabled bool
+
+	//maxBytes bounds the bytes of a batch, a message larger than maxBytes is written on its own
+	maxBytes int
+
+	//interval is waited for more messages once one is queued, 0 to write the messages queued at once
+	interval time.Duration
+}
+
+//newWriteBatching returns the batching configured by WriteBatching, disabled by default, WriteBatchMaxBytes, and WriteBatchInterval in milliseconds.
+func newWriteBatching(settings *SessionSettings) (writeBatching, error) {
+	batching := writeBatching{maxBytes: defaultWriteBatchMaxBytes}
+
+	if settings.HasSetting(config.WriteBatching) {
+		var err error
+		if batching.enabled, err = settings.BoolSetting(config.WriteBatching); err != nil {
+			return batching, err
+		}
+	}
+
+	if settings.HasSetting(config.WriteBatchMaxBytes) {
+		maxBytes, err := settings.IntSetting(config.WriteBatchMaxBytes)
+		if err != nil {
+			return batching, err
+		}
+
+		if maxBytes <= 0 {
+			return batching, fmt.Errorf("%v must be a positive number", config.WriteBatchMaxBytes)
+		}
+		batching.maxBytes = maxBytes
+	}
+
+	if settings.HasSetting(config.WriteBatchInterval) {
+		millis, err := settings.IntSetting(config.WriteBatchInterval)
+		if err != nil {
+			return batching, err
+		}
+
+		if millis < 0 {
+			return batching, fmt.Errorf("%v must not be negative", config.WriteBatchInterval)
+		}
+		batching.interval = time.Duration(millis) * time.Millisecond
+	}
+
+	return batching, nil
+}
+
+//connWriter writes the messages sent by a session to its connection. The connection is closed, disconnecting the session,
+//once a write fails or times out, or more messages are buffered than the limits allow. Messages are discarded from then on.
+type connWriter struct {
+	conn     net.Conn
+	log      Log
+	limits   writeLimits
+	batching writeBatching
+
+	//writeFailed is set once a write fails, accessed by the writing goroutine only
+	writeFailed bool
+
+	lock  sync.Mutex
+	ready *sync.Cond
+	queue [][]byte
+	//drained is signaled as batches are taken from the queue, waking a session waiting for the queue to hold less than a batch
+	drained     *sync.Cond
+	queuedBytes int
+	overflowed  bool
+
+	//closed is set once the session sends nil
+	closed bool
+
+	//done is closed once the messages sent are written or discarded
+	done chan struct{}
+	//written, if not nil, is called once done is closed, with ThreadingModel=Pool
+	written func()
+
+	//pool writes the queued messages on its workers with ThreadingModel=Pool, as much as polled takes without blocking,
+	//the rest once it is writable, batched without waiting the batch interval. Connections not polled are written on a
+	//goroutine of the writer.
+	pool   *workerPool
+	polled *polledConn
+	//nonblocking writes a polled connection
+	nonblocking *pollConn
+	//scheduled is set while the writer is queued on the pool, writing, or waiting for the connection to be writable
+	scheduled, waiting bool
+	//pending is the rest of the bytes being written by the pool, of pendingMessages messages, in pendingBuffer if batched
+	pending         []byte
+	pendingMessages int
+	pendingBuffer   []byte
+	//stalled is when the connection last stopped taking the bytes written by the pool, zero while it takes them
+	stalled time.Time
+
+	//debug counts the goroutines of the writer for the DebugState of the session
+	debug *sessionDebug
+}
+
+func newConnWriter(conn net.Conn, session *Session) *connWriter {
+	w := &connWriter{conn: conn, log: session.log, limits: session.writeLimits, batching: session.writeBatching, done: make(chan struct{}), debug: &session.debug}
+	w.ready = sync.NewCond(&w.lock)
+	w.drained = sync.NewCond(&w.lock)
+	return w
+}
+
+//run writes the messages of messageOut until sent nil, then closes messageOut.
+func (w *connWriter) run(messageOut chan []byte) {
+	defer w.debug.goroutine()()
+	defer close(messageOut)
+
+	if w.limits.maxMessages == 0 && w.limits.maxBytes == 0 && !w.batching.enabled {
+		defer close(w.done)
+
+		for msg := <-messageOut; msg != nil; msg = <-messageOut {
+			w.write(msg, 1)
+		}
+		return
+	}
+
+	go w.writeBuffered()
+
+	for msg := <-messageOut; msg != nil; msg = <-messageOut {
+		w.buffer(msg)
+	}
+
+	w.lock.Lock()
+	w.closed = true
+	w.ready.Signal()
+	w.lock.Unlock()
+}
+
+//wait returns once the messages sent before nil are written or discarded.
+func (w *connWriter) wait() {
+	<-w.done
+}
+
+//buffer queues msg to be written, disconnecting a slow consumer once the queue exceeds the limits.
+//Without limits, waits while a batch is queued, so the session is held back by the connection as when writing unbatched.
+func (w *connWriter) buffer(msg []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.overflowed {
+		return
+	}
+
+	for w.limits.maxMessages == 0 && w.limits.maxBytes == 0 && w.queuedBytes >= w.batching.maxBytes {
+		w.drained.Wait()
+	}
+
+	w.queueLocked(msg)
+	w.ready.Signal()
+}
+
+//queueLocked appends msg to the queue, with the lock held, disconnecting a slow consumer once the queue exceeds the limits.
+func (w *connWriter) queueLocked(msg []byte) {
+	w.queue = append(w.queue, msg)
+	w.queuedBytes += len(msg)
+
+	if (w.limits.maxMessages > 0 && len(w.queue) > w.limits.maxMessages) || (w.limits.maxBytes > 0 && w.queuedBytes > w.limits.maxBytes) {
+		logEventf(w.log, LogLevelError, LogCategoryTransport, "Slow consumer, %v messages of %v bytes not written, disconnecting", len(w.queue), w.queuedBytes)
+
+		w.overflowed = true
+		w.queue, w.queuedBytes = nil, 0
+		w.conn.Close()
+	}
+}
+
+//startPooled writes the messages posted with ThreadingModel=Pool, on the workers of pool if the connection is polled,
+//on a goroutine of the writer otherwise. written is called once the messages posted before end are written or discarded.
+func (w *connWriter) startPooled(pool *workerPool, polled *polledConn, nonblocking *pollConn, written func()) {
+	w.written = written
+	if polled == nil {
+		go w.writeBuffered()
+		return
+	}
+
+	w.pool, w.polled, w.nonblocking = pool, polled, nonblocking
+}
+
+//post queues msg to be written with ThreadingModel=Pool, returning without waiting for the connection, so that the
+//worker running the session is never held by it. The queue is bounded by the slow consumer limits only.
+func (w *connWriter) post(msg []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.overflowed || w.closed {
+		return
+	}
+
+	w.queueLocked(msg)
+	w.wakeLocked()
+}
+
+//end stops the writer once the messages posted are written or discarded, with ThreadingModel=Pool.
+func (w *connWriter) end() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.closed = true
+	w.wakeLocked()
+}
+
+//wakeLocked wakes the goroutine of the writer, or schedules the writer on the pool unless already scheduled.
+func (w *connWriter) wakeLocked() {
+	if w.pool == nil {
+		w.ready.Signal()
+		return
+	}
+
+	if !w.scheduled {
+		w.scheduled = true
+		w.pool.schedule(w)
+	}
+}
+
+//finish closes done, once the messages sent are written or discarded.
+func (w *connWriter) finish() {
+	close(w.done)
+	if w.written != nil {
+		w.written()
+	}
+}
+
+//turn writes the queued messages the connection takes without blocking, on a worker of the pool, rescheduling the writer
+//after pooledTurnTasks writes. The rest is written once the connection is writable.
+func (w *connWriter) turn() {
+	for i := 0; i < pooledTurnTasks; i++ {
+		if len(w.pending) == 0 && !w.takePending() {
+			return
+		}
+
+		if !w.writePending() {
+			return
+		}
+	}
+
+	w.pool.schedule(w)
+}
+
+//takePending takes the messages at the head of the queue to be written, up to maxBytes if batching.
+//Returns false once the queue is empty, finishing the writer once ended.
+func (w *connWriter) takePending() bool {
+	w.lock.Lock()
+
+	if w.writeFailed {
+		w.queue, w.queuedBytes = nil, 0
+	}
+
+	if len(w.queue) == 0 {
+		w.scheduled = false
+		finished := w.closed
+		w.lock.Unlock()
+
+		if finished {
+			w.finish()
+		}
+		return false
+	}
+
+	n, size := 1, len(w.queue[0])
+	for w.batching.enabled && n < len(w.queue) && size+len(w.queue[n]) <= w.batching.maxBytes {
+		size += len(w.queue[n])
+		n++
+	}
+
+	batch := w.queue[:n]
+	w.queue = w.queue[n:]
+	w.queuedBytes -= size
+	w.lock.Unlock()
+
+	w.pendingMessages = n
+	if n == 1 {
+		w.pending = batch[0]
+		return true
+	}
+
+	w.pendingBuffer = getBuffer(size)
+	for _, msg := range batch {
+		w.pendingBuffer = append(w.pendingBuffer, msg...)
+	}
+	w.pending = w.pendingBuffer
+	return true
+}
+
+//writePending writes the pending bytes the connection takes, closing the connection if the write fails, or if the connection
+//takes none of them within the write timeout. Returns false if the connection is full, waiting for it to be writable.
+func (w *connWriter) writePending() bool {
+	n, err := w.nonblocking.Write(w.pending)
+	w.pending = w.pending[n:]
+
+	if err == errWouldBlock {
+		now := time.Now()
+
+		w.lock.Lock()
+		if n > 0 || w.stalled.IsZero() {
+			w.stalled = now
+		}
+		expired := w.limits.timeout > 0 && now.Sub(w.stalled) >= w.limits.timeout
+		w.waiting = !expired
+		w.lock.Unlock()
+
+		if !expired {
+			w.polled.arm(pollWritable)
+			return false
+		}
+		logEventf(w.log, LogLevelError, LogCategoryTransport, "Write timed out after %v, disconnecting", w.limits.timeout)
+	}
+
+	if err != nil {
+		w.writeFailed = true
+		w.conn.Close()
+		w.pending = nil
+	} else if !w.stalled.IsZero() {
+		w.lock.Lock()
+		w.stalled = time.Time{}
+		w.lock.Unlock()
+	}
+
+	if len(w.pending) == 0 {
+		recordWrite(w.pendingMessages)
+		if w.pendingBuffer != nil {
+			putBuffer(w.pendingBuffer)
+			w.pendingBuffer = nil
+		}
+	}
+	return true
+}
+
+//resume schedules the writer waiting for the connection, once writable.
+func (w *connWriter) resume() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.waiting {
+		w.waiting = false
+		w.pool.schedule(w)
+	}
+}
+
+//checkStalled resumes the writer waiting for the connection once the write timeout expires, disconnecting the session.
+func (w *connWriter) checkStalled(now time.Time) {
+	if w.pool == nil || w.limits.timeout == 0 {
+		return
+	}
+
+	w.lock.Lock()
+	expired := w.waiting && now.Sub(w.stalled) >= w.limits.timeout
+	w.lock.Unlock()
+
+	if expired {
+		w.resume()
+	}
+}
+
+//writeBuffered writes the queued messages until the session sends nil.
+func (w *connWriter) writeBuffered() {
+	defer w.debug.goroutine()()
+	defer w.finish()
+
+	w.lock.Lock()
+	for {
+		for len(w.queue) == 0 && !w.closed {
+			w.ready.Wait()
+		}
+
+		if len(w.queue) == 0 {
+			w.lock.Unlock()
+			return
+		}
+
+		if !w.batching.enabled {
+			msg := w.queue[0]
+			w.queue = w.queue[1:]
+			w.queuedBytes -= len(msg)
+			w.lock.Unlock()
+
+			w.write(msg, 1)
+			w.lock.Lock()
+			continue
+		}
+
+		if w.batching.interval > 0 && w.queuedBytes < w.batching.maxBytes && !w.closed {
+			w.lock.Unlock()
+			time.Sleep(w.batching.interval)
+			w.lock.Lock()
+		}
+
+		w.writeBatch()
+	}
+}
+
+//writeBatch writes the messages at the head of the queue up to maxBytes in a single write, with the lock held but while writing.
+func (w *connWriter) writeBatch() {
+	n, size := 1, len(w.queue[0])
+	for n < len(w.queue) && size+len(w.queue[n]) <= w.batching.maxBytes {
+		size += len(w.queue[n])
+		n++
+	}
+
+	batch := w.queue[:n]
+	w.queue = w.queue[n:]
+	w.queuedBytes -= size
+	w.drained.Broadcast()
+	w.lock.Unlock()
+
+	if n == 1 {
+		w.write(batch[0], 1)
+	} else {
+		buf := getBuffer(size)
+		for _, msg := range batch {
+			buf = append(buf, msg...)
+		}
+
+		w.write(buf, n)
+		putBuffer(buf)
+	}
+
+	w.lock.Lock()
+}
+
+//write writes msg, the bytes of messages messages, within the write timeout, closing the connection if the write fails.
+func (w *connWriter) write(msg []byte, messages int) {
+	if w.writeFailed {
+		return
+	}
+	recordWrite(messages)
+
+	if w.limits.timeout > 0 {
+		w.conn.SetWriteDeadline(time.Now().Add(w.limits.timeout))
+	}
+
+	if _, err := w.conn.Write(msg); err != nil {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			logEventf(w.log, LogLevelError, LogCategoryTransport, "Write timed out after %v, disconnecting", w.limits.timeout)
+		}
+
+		w.writeFailed = true
+		w.conn.Close()
+	}
+}
@@ -0,0 +1,253 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewWriteLimits(t *testing.T) {
+	if limits, err := newWriteLimits(NewSessionSettings()); err != nil || limits != (writeLimits{}) {
+		t.Errorf("Expected no limits without settings, got %v %v", limits, err)
+	}
+
+	settings := NewSessionSettings()
+	settings.Set(config.SocketWriteTimeout, "5")
+	settings.Set(config.SlowConsumerMaxMessages, "1000")
+	settings.Set(config.SlowConsumerMaxBytes, "1048576")
+
+	limits, err := newWriteLimits(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (writeLimits{timeout: 5 * time.Second, maxMessages: 1000, maxBytes: 1048576}); limits != expected {
+		t.Errorf("Expected %+v, got %+v", expected, limits)
+	}
+
+	for key, value := range map[string]string{
+		config.SocketWriteTimeout:      "0",
+		config.SlowConsumerMaxMessages: "-1",
+		config.SlowConsumerMaxBytes:    "lots",
+	} {
+		invalid := settings.clone()
+		invalid.Set(key, value)
+		if _, err := newWriteLimits(invalid); err == nil {
+			t.Errorf("Expected error for %v=%v", key, value)
+		}
+	}
+}
+
+func TestConnWriter_Unbuffered(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	w := &connWriter{conn: local, log: nullLog{}, done: make(chan struct{})}
+	messageOut := make(chan []byte)
+	go w.run(messageOut)
+
+	read := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(remote)
+		read <- string(b)
+	}()
+
+	messageOut <- []byte("8=FIX.4.2|35=0|")
+	messageOut <- []byte("8=FIX.4.2|35=5|")
+	messageOut <- nil
+	w.wait()
+	local.Close()
+
+	if b := <-read; b != "8=FIX.4.2|35=0|8=FIX.4.2|35=5|" {
+		t.Errorf("Expected messages written in order, got %q", b)
+	}
+
+	if _, open := <-messageOut; open {
+		t.Error("Expected messageOut closed")
+	}
+}
+
+func TestConnWriter_WriteTimeout(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	log := new(leveledRecordingLog)
+	w := &connWriter{conn: local, log: log, limits: writeLimits{timeout: 20 * time.Millisecond}, done: make(chan struct{})}
+	messageOut := make(chan []byte)
+	go w.run(messageOut)
+
+	//the counterparty never reads
+	messageOut <- []byte("8=FIX.4.2|35=0|")
+	messageOut <- []byte("8=FIX.4.2|35=0|")
+	messageOut <- nil
+	w.wait()
+
+	if len(log.events) != 1 || !strings.HasPrefix(log.events[0].msg, "Write timed out") {
+		t.Errorf("Expected write timeout reported, got %v", log.events)
+	}
+
+	if _, err := remote.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Expected connection closed, got %v", err)
+	}
+}
+
+func TestConnWriter_SlowConsumer(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	log := new(leveledRecordingLog)
+	w := newConnWriter(local, &Session{log: log, writeLimits: writeLimits{maxMessages: 2}})
+	messageOut := make(chan []byte)
+	go w.run(messageOut)
+
+	//the counterparty never reads, the first message is written and the rest buffered until the limit
+	for i := 0; i < 4; i++ {
+		messageOut <- []byte("8=FIX.4.2|35=0|")
+	}
+	messageOut <- nil
+	w.wait()
+
+	if len(log.events) != 1 || log.events[0].level != LogLevelError || !strings.HasPrefix(log.events[0].msg, "Slow consumer, 3 messages") {
+		t.Errorf("Expected slow consumer reported, got %v", log.events)
+	}
+
+	if _, err := remote.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Expected connection closed, got %v", err)
+	}
+}
+
+func TestNewWriteBatching(t *testing.T) {
+	if batching, err := newWriteBatching(NewSessionSettings()); err != nil || batching != (writeBatching{maxBytes: defaultWriteBatchMaxBytes}) {
+		t.Errorf("Expected batching disabled by default, got %+v %v", batching, err)
+	}
+
+	settings := NewSessionSettings()
+	settings.Set(config.WriteBatching, "Y")
+	settings.Set(config.WriteBatchMaxBytes, "1024")
+	settings.Set(config.WriteBatchInterval, "2")
+
+	batching, err := newWriteBatching(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (writeBatching{enabled: true, maxBytes: 1024, interval: 2 * time.Millisecond}); batching != expected {
+		t.Errorf("Expected %+v, got %+v", expected, batching)
+	}
+
+	for key, value := range map[string]string{
+		config.WriteBatching:      "maybe",
+		config.WriteBatchMaxBytes: "0",
+		config.WriteBatchInterval: "-1",
+	} {
+		invalid := settings.clone()
+		invalid.Set(key, value)
+		if _, err := newWriteBatching(invalid); err == nil {
+			t.Errorf("Expected error for %v=%v", key, value)
+		}
+	}
+}
+
+//awaitQueueEmpty waits until the writer has taken the messages queued.
+func awaitQueueEmpty(t *testing.T, w *connWriter) {
+	for i := 0; i < 100; i++ {
+		w.lock.Lock()
+		empty := len(w.queue) == 0
+		w.lock.Unlock()
+
+		if empty {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("Expected the queue taken by the writer")
+}
+
+func TestConnWriter_Batching(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	w := newConnWriter(local, &Session{log: nullLog{}, writeBatching: writeBatching{enabled: true, maxBytes: 30}})
+	messageOut := make(chan []byte)
+	go w.run(messageOut)
+
+	//the first message is written while the next are queued, then written together up to maxBytes
+	messageOut <- []byte("8=FIX.4.2|35=0|")
+	awaitQueueEmpty(t, w)
+	messageOut <- []byte("8=FIX.4.2|35=1|")
+	messageOut <- []byte("8=FIX.4.2|35=2|")
+
+	//the session waits while a batch is queued
+	sent := make(chan bool)
+	go func() {
+		messageOut <- []byte("8=FIX.4.2|35=3|")
+		messageOut <- nil
+		sent <- true
+	}()
+
+	select {
+	case <-sent:
+		t.Fatal("Expected the session held back while a batch is queued")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	var writes []string
+	buf := make([]byte, 1024)
+	for len(writes) < 3 {
+		n, err := remote.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writes = append(writes, string(buf[:n]))
+	}
+	<-sent
+	w.wait()
+
+	expected := []string{"8=FIX.4.2|35=0|", "8=FIX.4.2|35=1|8=FIX.4.2|35=2|", "8=FIX.4.2|35=3|"}
+	for i := range expected {
+		if writes[i] != expected[i] {
+			t.Errorf("Expected write %q, got %q", expected[i], writes[i])
+		}
+	}
+}
+
+func TestConnWriter_DefaultWritesEachMessage(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	batching, err := newWriteBatching(NewSessionSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := newConnWriter(local, &Session{log: nullLog{}, writeBatching: batching})
+	messageOut := make(chan []byte)
+	go w.run(messageOut)
+
+	//the messages are sent back to back, each written on its own as the session sends it
+	expected := []string{"8=FIX.4.2|35=0|", "8=FIX.4.2|35=1|", "8=FIX.4.2|35=2|"}
+	go func() {
+		for _, msg := range expected {
+			messageOut <- []byte(msg)
+		}
+		messageOut <- nil
+	}()
+
+	buf := make([]byte, 1024)
+	remote.SetReadDeadline(time.Now().Add(time.Second))
+	for i := range expected {
+		n, err := remote.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(buf[:n]) != expected[i] {
+			t.Errorf("Expected write %q, got %q", expected[i], buf[:n])
+		}
+	}
+	w.wait()
+}
@@ -6,24 +6,63 @@ import (
 	"net"
 )
 
+//connEnd holds the funcs ending a connection, run in the reverse order added once the goroutine handling the connection returns,
+//or once the session ends with ThreadingModel=Pool, the connection handed to the worker pool.
+type connEnd struct {
+	funcs     []func()
+	handedOff bool
+}
+
+func (e *connEnd) add(f func()) {
+	e.funcs = append(e.funcs, f)
+}
+
+//run runs the funcs, unless the connection was handed off.
+func (e *connEnd) run() {
+	if !e.handedOff {
+		e.end()
+	}
+}
+
+//handOff returns the func ending the connection, once handed to a worker pool.
+func (e *connEnd) handOff() func() {
+	e.handedOff = true
+	return e.end
+}
+
+func (e *connEnd) end() {
+	for i := len(e.funcs) - 1; i >= 0; i-- {
+		e.funcs[i]()
+	}
+	e.funcs = nil
+}
+
 //Picks up session from net.Conn Initiator
 func handleInitiatorConnection(netConn net.Conn, log Log, sessID SessionID) {
+	end := &connEnd{}
 	defer func() {
 		if err := recover(); err != nil {
 			log.OnEventf("Connection Terminated: %v", err)
 		}
 
-		netConn.Close()
+		end.run()
 	}()
+	end.add(func() { netConn.Close() })
 
 	session := activate(sessID)
 	if session == nil {
 		log.OnEventf("Session not found for SessionID: %v", sessID)
 		return
 	}
-	defer func() {
-		deactivate(sessID)
-	}()
+	end.add(func() { deactivate(sessID) })
+
+	if err := session.socketOptions.apply(netConn); err != nil {
+		logEventf(session.log, LogLevelWarn, LogCategoryTransport, "Cannot set socket options: %v", err)
+	}
+
+	if session.wireCapture != nil {
+		netConn = newCaptureConn(netConn, session.wireCapture)
+	}
 
 	var msgOut chan []byte
 	var err error
@@ -34,27 +73,45 @@ func handleInitiatorConnection(netConn net.Conn, log Log, sessID SessionID) {
 
 	reader := bufio.NewReader(netConn)
 	parser := newParser(reader)
+	parser.maxMessageSize = session.maxMessageSize
+	parser.clock = session.clock
+	parser.debug = &session.debug
 
+	writer := newConnWriter(netConn, session)
+	session.debug.setWriter(writer)
+	end.add(func() { session.debug.setWriter(nil) })
+
+	//reconnects once the connection handed to the pool ends
+	if session.workers != nil {
+		<-session.runPooled(parser, reader, netConn, writer, nil, end)
+		return
+	}
+
+	go writer.run(msgOut)
 	msgIn := make(chan fixIn)
-	go writeLoop(netConn, msgOut)
 	go func() {
 		readLoop(parser, msgIn)
 	}()
 
 	session.run(msgIn)
+	writer.wait()
 }
 
-//Picks up session from net.Conn Acceptor
-func handleAcceptorConnection(netConn net.Conn, qualifiedSessionIDs map[SessionID]SessionID, log Log) {
+//Picks up session from net.Conn Acceptor. end holds the funcs run once the connection is closed.
+func handleAcceptorConnection(netConn net.Conn, qualifiedSessionID func(sessionID SessionID, logon Message, netConn net.Conn) (SessionID, bool), log Log, end *connEnd) {
 	defer func() {
 		if err := recover(); err != nil {
 			log.OnEventf("Connection Terminated: %v", err)
 		}
 
-		netConn.Close()
+		end.run()
 	}()
+	end.add(func() { netConn.Close() })
 
-	reader := bufio.NewReader(netConn)
+	//the session, and its capture, are known once the logon is read
+	captureConn := newCaptureConn(netConn, nil)
+
+	reader := bufio.NewReader(captureConn)
 	parser := newParser(reader)
 
 	msgBytes, err := parser.ReadMessage()
@@ -79,7 +136,7 @@ func handleAcceptorConnection(netConn net.Conn, qualifiedSessionIDs map[SessionI
 	msg.Header.Get(targetCompID)
 
 	sessID := SessionID{BeginString: beginString.Value, SenderCompID: targetCompID.Value, TargetCompID: senderCompID.Value}
-	qualifiedSessID, validID := qualifiedSessionIDs[sessID]
+	qualifiedSessID, validID := qualifiedSessionID(sessID, *msg, netConn)
 
 	if !validID {
 		log.OnEventf("Session %v not found for incoming message: %v", sessID, msg.String())
@@ -89,12 +146,30 @@ func handleAcceptorConnection(netConn net.Conn, qualifiedSessionIDs map[SessionI
 	session := activate(qualifiedSessID)
 
 	if session == nil {
-		log.OnEventf("Cannot activate session for incoming message: %v", msg.String())
-		return
+		if session = activateDuplicate(qualifiedSessID, netConn.RemoteAddr(), log); session == nil {
+			return
+		}
+	}
+	end.add(func() { deactivate(qualifiedSessID) })
+
+	if err := session.socketOptions.apply(netConn); err != nil {
+		logEventf(session.log, LogLevelWarn, LogCategoryTransport, "Cannot set socket options: %v", err)
+	}
+
+	captureConn.attach(session.wireCapture)
+	session.setConnection(captureConn)
+	end.add(func() { session.setConnection(nil) })
+
+	parser.maxMessageSize = session.maxMessageSize
+	parser.clock = session.clock
+	parser.debug = &session.debug
+	session.debug.setParserBuffer(cap(parser.readBuffer))
+
+	//the logon was read before the session, and its clock, was known
+	receiveTime := parser.lastRead
+	if session.clock != nil {
+		receiveTime = session.now()
 	}
-	defer func() {
-		deactivate(qualifiedSessID)
-	}()
 
 	var msgOut chan []byte
 	if msgOut, err = session.accept(); err != nil {
@@ -102,31 +177,29 @@ func handleAcceptorConnection(netConn net.Conn, qualifiedSessionIDs map[SessionI
 		return
 	}
 
+	writer := newConnWriter(captureConn, session)
+	session.debug.setWriter(writer)
+	end.add(func() { session.debug.setWriter(nil) })
+
+	if session.workers != nil {
+		session.runPooled(parser, reader, captureConn, writer, &fixIn{msgBytes, receiveTime, nil}, end)
+		return
+	}
+
+	go writer.run(msgOut)
 	msgIn := make(chan fixIn)
-	go writeLoop(netConn, msgOut)
 	go func() {
-		msgIn <- fixIn{msgBytes, parser.lastRead}
+		msgIn <- fixIn{msgBytes, receiveTime, nil}
 		readLoop(parser, msgIn)
 	}()
 
 	session.run(msgIn)
-}
-
-func writeLoop(connection net.Conn, messageOut chan []byte) {
-	defer func() {
-		close(messageOut)
-	}()
-
-	var msg []byte
-	for {
-		if msg = <-messageOut; msg == nil {
-			return
-		}
-		connection.Write(msg)
-	}
+	writer.wait()
 }
 
 func readLoop(parser *parser, msgIn chan fixIn) {
+	defer parser.debug.goroutine()()
+	defer parser.release()
 	defer func() {
 		close(msgIn)
 	}()
@@ -135,15 +208,17 @@ func readLoop(parser *parser, msgIn chan fixIn) {
 		msg, err := parser.ReadMessage()
 
 		if err != nil {
-			switch err.(type) {
+			switch typedErr := err.(type) {
 			//ignore message parser errors
 			case parseError:
 				continue
+			case MessageTooLargeError:
+				msgIn <- fixIn{receiveTime: parser.lastRead, tooLarge: &typedErr}
 			default:
 				return
 			}
 		} else {
-			msgIn <- fixIn{msg, parser.lastRead}
+			msgIn <- fixIn{msg, parser.lastRead, nil}
 		}
 	}
 }
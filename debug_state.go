@@ -0,0 +1,289 @@
+package quickfix
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//DebugCounters count the allocations of the parsers and serializers of every session, since the process started.
+type DebugCounters struct {
+	//ParserBuffers and ParserBufferBytes count the read buffers allocated by parsers as messages are read, and their bytes.
+	ParserBuffers     int64 `json:"parserBuffers"`
+	ParserBufferBytes int64 `json:"parserBufferBytes"`
+
+	//MessagesParsed and ParsedFields count the messages parsed, and the fields allocated for them.
+	MessagesParsed int64 `json:"messagesParsed"`
+	ParsedFields   int64 `json:"parsedFields"`
+
+	//MessagesSerialized and SerializedBytes count the messages serialized to be sent, and their bytes.
+	MessagesSerialized int64 `json:"messagesSerialized"`
+	SerializedBytes    int64 `json:"serializedBytes"`
+
+	//Writes and MessagesWritten count the writes to connections, and the messages they wrote, more than one each with WriteBatching.
+	Writes          int64 `json:"writes"`
+	MessagesWritten int64 `json:"messagesWritten"`
+
+	//BufferPool counts the buffers of the pool shared by the parsers, serializers, stores, logs and connection writers.
+	BufferPool BufferPoolStats `json:"bufferPool"`
+}
+
+//globalDebugCounters are updated and read with atomic operations, so that the parsers, serializers and buffer pool of every session
+//count without contending on a lock.
+var globalDebugCounters DebugCounters
+
+//recordParserBuffer counts a read buffer of size bytes taken by a parser.
+func recordParserBuffer(size int) {
+	atomic.AddInt64(&globalDebugCounters.ParserBuffers, 1)
+	atomic.AddInt64(&globalDebugCounters.ParserBufferBytes, int64(size))
+}
+
+//recordParsed counts a message parsed into fields.
+func recordParsed(fields int) {
+	atomic.AddInt64(&globalDebugCounters.MessagesParsed, 1)
+	atomic.AddInt64(&globalDebugCounters.ParsedFields, int64(fields))
+}
+
+//recordSerialized counts a message serialized into size bytes.
+func recordSerialized(size int) {
+	atomic.AddInt64(&globalDebugCounters.MessagesSerialized, 1)
+	atomic.AddInt64(&globalDebugCounters.SerializedBytes, int64(size))
+}
+
+//recordWrite counts a write of messages messages to a connection.
+func recordWrite(messages int) {
+	atomic.AddInt64(&globalDebugCounters.Writes, 1)
+	atomic.AddInt64(&globalDebugCounters.MessagesWritten, int64(messages))
+}
+
+//loadDebugCounters returns the counters counted so far. Each counter is read atomically, not all of them at once.
+func loadDebugCounters() DebugCounters {
+	c := &globalDebugCounters
+	return DebugCounters{
+		ParserBuffers:      atomic.LoadInt64(&c.ParserBuffers),
+		ParserBufferBytes:  atomic.LoadInt64(&c.ParserBufferBytes),
+		MessagesParsed:     atomic.LoadInt64(&c.MessagesParsed),
+		ParsedFields:       atomic.LoadInt64(&c.ParsedFields),
+		MessagesSerialized: atomic.LoadInt64(&c.MessagesSerialized),
+		SerializedBytes:    atomic.LoadInt64(&c.SerializedBytes),
+		Writes:             atomic.LoadInt64(&c.Writes),
+		MessagesWritten:    atomic.LoadInt64(&c.MessagesWritten),
+		BufferPool: BufferPoolStats{
+			Gets:      atomic.LoadInt64(&c.BufferPool.Gets),
+			Hits:      atomic.LoadInt64(&c.BufferPool.Hits),
+			Puts:      atomic.LoadInt64(&c.BufferPool.Puts),
+			Oversized: atomic.LoadInt64(&c.BufferPool.Oversized),
+		},
+	}
+}
+
+//SessionDebugState is the internal state of a session, its goroutines and the depth of its queues against their capacities.
+//Capacities are 0 for unbounded queues.
+type SessionDebugState struct {
+	SessionID SessionID
+	State     SessionState
+
+	//Goroutines are the goroutines of the current connection, reading and writing it and running the session.
+	Goroutines int
+
+	//SendQueueDepth is the number of application messages queued to be sent once logged on, bounded by MaxSendQueueDepth.
+	SendQueueDepth, SendQueueCapacity int
+
+	//ThrottleDelayed is the number of application messages delayed by ThrottleMaxMessages.
+	ThrottleDelayed int
+
+	//WriteQueueDepth and WriteQueueBytes are the messages buffered while the connection is written, bounded by
+	//SlowConsumerMaxMessages and SlowConsumerMaxBytes.
+	WriteQueueDepth, WriteQueueCapacity     int
+	WriteQueueBytes, WriteQueueByteCapacity int
+
+	//OutboundQueueDepth is the number of messages queued by the Application to be sent, bounded by OutboundQueueDepth.
+	OutboundQueueDepth, OutboundQueueCapacity int
+
+	//StoreQueueDepth is the number of messages queued to be written by an async store, bounded by AsyncStoreQueueDepth.
+	StoreQueueDepth, StoreQueueCapacity int
+
+	//ParserBufferBytes is the size of the read buffer of the connection.
+	ParserBufferBytes int
+}
+
+//DebugState is the internal state of the engine, for diagnosing performance issues in production.
+type DebugState struct {
+	Time time.Time
+
+	//Goroutines is the number of goroutines of the process.
+	Goroutines int
+
+	Counters DebugCounters
+	Sessions []SessionDebugState
+}
+
+//sessionDebug tracks the goroutines and connection of a session for its SessionDebugState.
+type sessionDebug struct {
+	lock              sync.Mutex
+	goroutines        int
+	writer            *connWriter
+	parserBufferBytes int
+}
+
+//goroutine counts a goroutine of the session started, returning the func counting it stopped. A nil sessionDebug counts nothing.
+func (d *sessionDebug) goroutine() func() {
+	if d == nil {
+		return func() {}
+	}
+
+	d.lock.Lock()
+	d.goroutines++
+	d.lock.Unlock()
+
+	return func() {
+		d.lock.Lock()
+		d.goroutines--
+		d.lock.Unlock()
+	}
+}
+
+//setWriter sets the writer of the current connection, nil once disconnected.
+func (d *sessionDebug) setWriter(writer *connWriter) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.writer = writer
+}
+
+//setParserBuffer records the size of the read buffer of the current connection.
+func (d *sessionDebug) setParserBuffer(size int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.parserBufferBytes = size
+}
+
+//DebugState returns the internal state of the session. Safe to call outside the session goroutine.
+func (s *Session) DebugState() SessionDebugState {
+	state := SessionDebugState{SessionID: s.sessionID}
+
+	s.trafficLock.RLock()
+	state.State = s.traffic.state
+	s.trafficLock.RUnlock()
+
+	s.sendLock.Lock()
+	state.SendQueueDepth, state.SendQueueCapacity = len(s.sendQueue), s.maxSendQueueDepth
+	state.ThrottleDelayed = len(s.delayed)
+	s.sendLock.Unlock()
+
+	s.debug.lock.Lock()
+	state.Goroutines, state.ParserBufferBytes = s.debug.goroutines, s.debug.parserBufferBytes
+	writer := s.debug.writer
+	s.debug.lock.Unlock()
+
+	if writer != nil {
+		writer.lock.Lock()
+		state.WriteQueueDepth, state.WriteQueueBytes = len(writer.queue), writer.queuedBytes
+		state.WriteQueueCapacity, state.WriteQueueByteCapacity = writer.limits.maxMessages, writer.limits.maxBytes
+		writer.lock.Unlock()
+	}
+
+	if s.outbound != nil {
+		state.OutboundQueueDepth, state.OutboundQueueCapacity = s.outbound.depth(), s.outbound.capacity()
+	}
+
+	if store, ok := s.store.(QueuedStore); ok {
+		state.StoreQueueDepth = store.QueueDepth()
+	}
+	if store, ok := s.store.(interface{ queueCapacity() int }); ok {
+		state.StoreQueueCapacity = store.queueCapacity()
+	}
+
+	return state
+}
+
+//CurrentDebugState returns the internal state of the engine and each of its sessions, ordered by SessionID.
+func CurrentDebugState() DebugState {
+	state := DebugState{Time: time.Now(), Goroutines: runtime.NumGoroutine()}
+
+	state.Counters = loadDebugCounters()
+
+	if pool := &state.Counters.BufferPool; pool.Gets > 0 {
+		pool.HitRate = float64(pool.Hits) / float64(pool.Gets)
+	}
+
+	for _, session := range allSessions() {
+		state.Sessions = append(state.Sessions, session.DebugState())
+	}
+	sort.Slice(state.Sessions, func(i, j int) bool {
+		return state.Sessions[i].SessionID.String() < state.Sessions[j].SessionID.String()
+	})
+
+	return state
+}
+
+//debugStateResponse is the JSON of a DebugState, published by PublishDebugState and written by NewDebugHandler.
+type debugStateResponse struct {
+	Time       time.Time                   `json:"time"`
+	Goroutines int                         `json:"goroutines"`
+	Counters   DebugCounters               `json:"counters"`
+	Sessions   []sessionDebugStateResponse `json:"sessions"`
+}
+
+type sessionDebugStateResponse struct {
+	SessionID              string `json:"sessionID"`
+	State                  string `json:"state"`
+	Goroutines             int    `json:"goroutines"`
+	SendQueueDepth         int    `json:"sendQueueDepth"`
+	SendQueueCapacity      int    `json:"sendQueueCapacity"`
+	ThrottleDelayed        int    `json:"throttleDelayed"`
+	WriteQueueDepth        int    `json:"writeQueueDepth"`
+	WriteQueueCapacity     int    `json:"writeQueueCapacity"`
+	WriteQueueBytes        int    `json:"writeQueueBytes"`
+	WriteQueueByteCapacity int    `json:"writeQueueByteCapacity"`
+	OutboundQueueDepth     int    `json:"outboundQueueDepth"`
+	OutboundQueueCapacity  int    `json:"outboundQueueCapacity"`
+	StoreQueueDepth        int    `json:"storeQueueDepth"`
+	StoreQueueCapacity     int    `json:"storeQueueCapacity"`
+	ParserBufferBytes      int    `json:"parserBufferBytes"`
+}
+
+func newDebugStateResponse(state DebugState) debugStateResponse {
+	response := debugStateResponse{Time: state.Time, Goroutines: state.Goroutines, Counters: state.Counters, Sessions: []sessionDebugStateResponse{}}
+	for _, session := range state.Sessions {
+		response.Sessions = append(response.Sessions, sessionDebugStateResponse{
+			SessionID:              session.SessionID.String(),
+			State:                  session.State.String(),
+			Goroutines:             session.Goroutines,
+			SendQueueDepth:         session.SendQueueDepth,
+			SendQueueCapacity:      session.SendQueueCapacity,
+			ThrottleDelayed:        session.ThrottleDelayed,
+			WriteQueueDepth:        session.WriteQueueDepth,
+			WriteQueueCapacity:     session.WriteQueueCapacity,
+			WriteQueueBytes:        session.WriteQueueBytes,
+			WriteQueueByteCapacity: session.WriteQueueByteCapacity,
+			OutboundQueueDepth:     session.OutboundQueueDepth,
+			OutboundQueueCapacity:  session.OutboundQueueCapacity,
+			StoreQueueDepth:        session.StoreQueueDepth,
+			StoreQueueCapacity:     session.StoreQueueCapacity,
+			ParserBufferBytes:      session.ParserBufferBytes,
+		})
+	}
+
+	return response
+}
+
+//PublishDebugState publishes the CurrentDebugState as the expvar name, served as JSON on /debug/vars with the expvar handler.
+//Like expvar.Publish, panics if name is already published.
+func PublishDebugState(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} { return newDebugStateResponse(CurrentDebugState()) }))
+}
+
+//NewDebugHandler returns an http.Handler responding with the CurrentDebugState as a JSON body, to be served alongside net/http/pprof.
+func NewDebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(newDebugStateResponse(CurrentDebugState()))
+	})
+}
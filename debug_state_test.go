@@ -0,0 +1,98 @@
+package quickfix
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"github.com/quickfixgo/quickfix/config"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestDebugState(t *testing.T) {
+	port := strconv.Itoa(freePort(t))
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, port)
+	acceptorSessionSettings := newTestAcceptorSessionSettings("DEBUG")
+	acceptorSessionSettings.Set(config.SlowConsumerMaxMessages, "10")
+	acceptorSessionID, err := acceptorSettings.AddSession(acceptorSessionSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acceptorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorSettings := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "DEBUG")
+	sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+	sessionSettings.Set(config.HeartBtInt, "30")
+	sessionSettings.Set(config.SocketConnectHost, "127.0.0.1")
+	sessionSettings.Set(config.SocketConnectPort, port)
+	if _, err := initiatorSettings.AddSession(sessionSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer initiator.Shutdown(context.Background())
+
+	awaitState(t, initiatorApp.states, StateLoggedOn)
+	awaitState(t, acceptorApp.states, StateLoggedOn)
+
+	session, err := LookupSession(acceptorSessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the session loop, the reader, and the writer buffering and writing the connection
+	state := session.DebugState()
+	if state.State != StateLoggedOn || state.Goroutines != 4 || state.WriteQueueCapacity != 10 || state.ParserBufferBytes == 0 {
+		t.Errorf("Unexpected state of logged on session %+v", state)
+	}
+
+	debugState := CurrentDebugState()
+	if debugState.Counters.MessagesParsed == 0 || debugState.Counters.MessagesSerialized == 0 || debugState.Counters.ParserBuffers == 0 {
+		t.Errorf("Expected parser and serializer counted, got %+v", debugState.Counters)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewDebugHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/quickfix", nil))
+
+	var response debugStateResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, session := range response.Sessions {
+		found = found || (session.SessionID == acceptorSessionID.String() && session.State == StateLoggedOn.String())
+	}
+	if !found || response.Goroutines == 0 {
+		t.Errorf("Expected the logged on session in %+v", response)
+	}
+
+	PublishDebugState("quickfix_test")
+	if variable := expvar.Get("quickfix_test"); variable == nil || json.Unmarshal([]byte(variable.String()), &response) != nil {
+		t.Errorf("Expected the debug state published")
+	}
+}
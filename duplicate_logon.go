@@ -0,0 +1,89 @@
+package quickfix
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+//duplicateLogonTimeout bounds the wait for the current connection of a session to close when replaced by a duplicate logon.
+const duplicateLogonTimeout = 5 * time.Second
+
+//duplicateLogonPolicy determines how an acceptor treats a connection logging on for a session that is already connected.
+type duplicateLogonPolicy int
+
+const (
+	//duplicateLogonReject refuses the new connection, the current connection is unaffected.
+	duplicateLogonReject duplicateLogonPolicy = iota
+
+	//duplicateLogonReplace disconnects the current connection and logs on the new connection, recovering from the sequence numbers of the store.
+	duplicateLogonReplace
+
+	//duplicateLogonAlert refuses the new connection and notifies the Application if it implements DuplicateLogonListener.
+	duplicateLogonAlert
+)
+
+//parseDuplicateLogonPolicy maps the DuplicateLogonPolicy setting to a duplicateLogonPolicy.
+func parseDuplicateLogonPolicy(setting string) (duplicateLogonPolicy, error) {
+	switch setting {
+	case "Reject":
+		return duplicateLogonReject, nil
+	case "Replace":
+		return duplicateLogonReplace, nil
+	case "Alert":
+		return duplicateLogonAlert, nil
+	}
+
+	return duplicateLogonReject, fmt.Errorf("invalid DuplicateLogonPolicy %v, expected Reject, Replace, or Alert", setting)
+}
+
+//setConnection records the connection of the session, nil once disconnected.
+func (s *Session) setConnection(conn net.Conn) {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	s.conn = conn
+}
+
+//closeConnection closes the connection of the session, if connected.
+func (s *Session) closeConnection() {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+//activateDuplicate applies the DuplicateLogonPolicy of the session with sessionID to a connection from remoteAddr logging on while the session is connected.
+//Returns the activated session if the connection replaces the current connection, otherwise nil.
+func activateDuplicate(sessionID SessionID, remoteAddr net.Addr, log Log) *Session {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		logEventf(log, LogLevelError, LogCategorySession, "Cannot activate session %v: %v", sessionID, err)
+		return nil
+	}
+
+	switch session.duplicateLogonPolicy {
+	case duplicateLogonReplace:
+		logEventf(session.log, LogLevelWarn, LogCategorySession, "Duplicate logon from %v, disconnecting the current connection", remoteAddr)
+		session.closeConnection()
+
+		for deadline := time.Now().Add(duplicateLogonTimeout); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if session := activate(sessionID); session != nil {
+				return session
+			}
+		}
+
+		logEventf(session.log, LogLevelWarn, LogCategorySession, "Duplicate logon from %v refused, the current connection did not close", remoteAddr)
+		return nil
+
+	case duplicateLogonAlert:
+		if listener, ok := session.application.(DuplicateLogonListener); ok {
+			listener.OnDuplicateLogon(sessionID, remoteAddr)
+		}
+	}
+
+	logEventf(session.log, LogLevelWarn, LogCategorySession, "Duplicate logon from %v refused, session already connected", remoteAddr)
+	return nil
+}
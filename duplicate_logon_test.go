@@ -0,0 +1,112 @@
+package quickfix
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//duplicateLogonClient records the duplicate logons alerted.
+type duplicateLogonClient struct {
+	TestClient
+	alerts chan SessionID
+}
+
+func (c *duplicateLogonClient) OnDuplicateLogon(sessionID SessionID, remoteAddr net.Addr) {
+	c.alerts <- sessionID
+}
+
+//testLogon connects to port and logs on as targetCompID with seqNum, returning the connection and whether a Logon response was received.
+func testLogon(t *testing.T, port, targetCompID string, seqNum int) (net.Conn, bool) {
+	conn, err := net.Dial("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := fmt.Sprintf("35=A\00134=%v\00149=%v\00152=%v\00156=ACCEPTOR\00198=0\001108=30\001", seqNum, targetCompID, time.Now().UTC().Format("20060102-15:04:05.000"))
+	if _, err := conn.Write(rawMessage("FIX.4.2", body)); err != nil {
+		t.Fatal(err)
+	}
+
+	return conn, testConnected(conn)
+}
+
+//testConnected returns true if a message is read from conn before it closes.
+func testConnected(conn net.Conn) bool {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err := newParser(bufio.NewReader(conn)).ReadMessage()
+	return err == nil
+}
+
+func TestAcceptor_DuplicateLogonPolicy(t *testing.T) {
+	var tests = []struct {
+		policy           string
+		expectedReplaced bool
+		expectedAlert    bool
+	}{
+		{"Reject", false, false},
+		{"Replace", true, false},
+		{"Alert", false, true},
+	}
+
+	for _, test := range tests {
+		port := strconv.Itoa(freePort(t))
+		targetCompID := "DUPLICATE" + test.policy
+
+		settings := NewSettings()
+		settings.GlobalSettings().Set(config.SocketAcceptPort, port)
+		settings.GlobalSettings().Set(config.SocketAcceptHost, "127.0.0.1")
+		sessionSettings := newTestAcceptorSessionSettings(targetCompID)
+		sessionSettings.Set(config.DuplicateLogonPolicy, test.policy)
+		if _, err := settings.AddSession(sessionSettings); err != nil {
+			t.Fatal(err)
+		}
+
+		app := &duplicateLogonClient{alerts: make(chan SessionID, 1)}
+		acceptor, err := NewAcceptor(app, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := acceptor.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		first, ok := testLogon(t, port, targetCompID, 1)
+		if !ok {
+			t.Fatalf("%v: expected first logon accepted", test.policy)
+		}
+
+		second, replaced := testLogon(t, port, targetCompID, 2)
+		if replaced != test.expectedReplaced {
+			t.Errorf("%v: expected duplicate logon accepted %v", test.policy, test.expectedReplaced)
+		}
+
+		//the first connection is closed if replaced, otherwise the read times out
+		first.SetReadDeadline(time.Now().Add(time.Second))
+		_, err = first.Read(make([]byte, 1))
+		timeout, _ := err.(net.Error)
+		if closed := timeout == nil || !timeout.Timeout(); closed != test.expectedReplaced {
+			t.Errorf("%v: expected first connection closed %v", test.policy, test.expectedReplaced)
+		}
+
+		select {
+		case <-app.alerts:
+			if !test.expectedAlert {
+				t.Errorf("%v: unexpected alert", test.policy)
+			}
+		default:
+			if test.expectedAlert {
+				t.Errorf("%v: expected alert", test.policy)
+			}
+		}
+
+		first.Close()
+		second.Close()
+		acceptor.Stop()
+	}
+}
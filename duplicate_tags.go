@@ -0,0 +1,174 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+)
+
+//duplicateTagPolicy determines how a session treats a non-group tag that appears more than once in an inbound message.
+type duplicateTagPolicy int
+
+const (
+	//duplicateTagPolicyNone leaves repeated tags to the parser and data dictionary validation.
+	duplicateTagPolicyNone duplicateTagPolicy = iota
+
+	//duplicateTagReject rejects the message with SessionRejectReason TagAppearsMoreThanOnce.
+	duplicateTagReject
+
+	//duplicateTagKeepFirst keeps the first occurrence of the tag and discards the rest.
+	duplicateTagKeepFirst
+
+	//duplicateTagKeepLast keeps the last occurrence of the tag and discards the rest.
+	duplicateTagKeepLast
+)
+
+//parseDuplicateTagPolicy maps the DuplicateTagPolicy setting to a duplicateTagPolicy.
+func parseDuplicateTagPolicy(setting string) (duplicateTagPolicy, error) {
+	switch setting {
+	case "Reject":
+		return duplicateTagReject, nil
+	case "KeepFirst":
+		return duplicateTagKeepFirst, nil
+	case "KeepLast":
+		return duplicateTagKeepLast, nil
+	}
+
+	return duplicateTagPolicyNone, fmt.Errorf("invalid DuplicateTagPolicy %v, expected Reject, KeepFirst, or KeepLast", setting)
+}
+
+//repeatedTags returns the tags that occur more than once in the message, in order of first repetition.
+//Tags in groupTags belong to repeating groups and are expected to repeat.
+func (m *Message) repeatedTags(groupTags datadictionary.TagSet) []fix.Tag {
+	seen := make(map[fix.Tag]int)
+	var repeated []fix.Tag
+
+	for _, f := range m.fields {
+		if _, inGroup := groupTags[f.Tag]; inGroup {
+			continue
+		}
+
+		seen[f.Tag]++
+		if seen[f.Tag] == 2 {
+			repeated = append(repeated, f.Tag)
+		}
+	}
+
+	return repeated
+}
+
+//keepOccurrence discards all but one occurrence of t from the message. If first is true, the first occurrence is kept, otherwise the last.
+func (m *Message) keepOccurrence(t fix.Tag, first bool) {
+	keep := -1
+	for i, f := range m.fields {
+		if f.Tag != t {
+			continue
+		}
+
+		if keep == -1 || !first {
+			keep = i
+		}
+	}
+
+	if keep == -1 {
+		return
+	}
+
+	fields := make([]fieldBytes, 0, len(m.fields))
+	for i, f := range m.fields {
+		if f.Tag != t || i == keep {
+			fields = append(fields, f)
+		}
+	}
+	m.fields = fields
+
+	//fieldLookup entries point into the fields slice, rebind all sections to the compacted slice
+	for i := range m.fields {
+		f := &m.fields[i]
+		switch {
+		case tag.IsHeader(f.Tag):
+			m.Header.(fieldMap).fieldLookup[f.Tag] = f
+		case tag.IsTrailer(f.Tag):
+			m.Trailer.(fieldMap).fieldLookup[f.Tag] = f
+		default:
+			m.Body.(fieldMap).fieldLookup[f.Tag] = f
+		}
+	}
+}
+
+//groupTags returns the tags declared inside repeating groups for the message type, as known by the session data dictionaries.
+func (s *Session) groupTags(msg Message) datadictionary.TagSet {
+	groupTags := make(datadictionary.TagSet)
+
+	msgType := new(fix.StringValue)
+	if err := msg.Header.GetField(tag.MsgType, msgType); err != nil {
+		return groupTags
+	}
+
+	collect := func(def *datadictionary.MessageDef) {
+		if def == nil {
+			return
+		}
+
+		for t := range def.Tags {
+			if _, topLevel := def.Fields[t]; !topLevel {
+				groupTags.Add(t)
+			}
+		}
+	}
+
+	switch {
+	case s.dataDictionary != nil:
+		collect(s.dataDictionary.Header)
+		collect(s.dataDictionary.Messages[msgType.Value])
+		collect(s.dataDictionary.Trailer)
+	case s.transportDataDictionary != nil:
+		collect(s.transportDataDictionary.Header)
+		collect(s.transportDataDictionary.Trailer)
+		if fix.IsAdminMessageType(msgType.Value) {
+			collect(s.transportDataDictionary.Messages[msgType.Value])
+		} else if appDataDictionary := s.appDataDictionaryFor(msg); appDataDictionary != nil {
+			collect(appDataDictionary.Messages[msgType.Value])
+		}
+	}
+
+	return groupTags
+}
+
+//checkDuplicateTags returns a TagAppearsMoreThanOnce reject for msg if the session DuplicateTagPolicy is Reject and msg repeats a non-group tag.
+func (s *Session) checkDuplicateTags(msg Message) MessageRejectError {
+	if s.duplicateTagPolicy != duplicateTagReject {
+		return nil
+	}
+
+	if repeated := msg.repeatedTags(s.groupTags(msg)); len(repeated) != 0 {
+		return tagAppearsMoreThanOnce(repeated[0])
+	}
+
+	return nil
+}
+
+//resolveDuplicateTags discards repeated non-group tags in msg according to the session KeepFirst or KeepLast DuplicateTagPolicy.
+func (s *Session) resolveDuplicateTags(msg *Message) {
+	var keepFirst bool
+	switch s.duplicateTagPolicy {
+	case duplicateTagKeepFirst:
+		keepFirst = true
+	case duplicateTagKeepLast:
+		keepFirst = false
+	default:
+		return
+	}
+
+	repeated := msg.repeatedTags(s.groupTags(*msg))
+	if len(repeated) == 0 {
+		return
+	}
+
+	for _, t := range repeated {
+		msg.keepOccurrence(t, keepFirst)
+	}
+
+	logEventf(s.log, LogLevelWarn, LogCategoryValidation, "Message contains repeated tags %v", repeated)
+}
@@ -0,0 +1,168 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+)
+
+//rawMessage frames body with BeginString, BodyLength, and CheckSum
+func rawMessage(beginString, body string) []byte {
+	msg := fmt.Sprintf("8=%v\0019=%v\001%v", beginString, len(body), body)
+	checkSum := 0
+	for _, b := range []byte(msg) {
+		checkSum += int(b)
+	}
+
+	return []byte(fmt.Sprintf("%v10=%03d\001", msg, checkSum%256))
+}
+
+func TestParseDuplicateTagPolicy(t *testing.T) {
+	var testCases = []struct {
+		setting   string
+		expected  duplicateTagPolicy
+		expectErr bool
+	}{
+		{setting: "Reject", expected: duplicateTagReject},
+		{setting: "KeepFirst", expected: duplicateTagKeepFirst},
+		{setting: "KeepLast", expected: duplicateTagKeepLast},
+		{setting: "keeplast", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		policy, err := parseDuplicateTagPolicy(tc.setting)
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("expected error for %v", tc.setting)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error for %v: %v", tc.setting, err)
+		}
+
+		if policy != tc.expected {
+			t.Errorf("expected %v got %v", tc.expected, policy)
+		}
+	}
+}
+
+func TestSession_ResolveDuplicateTags(t *testing.T) {
+	raw := rawMessage("FIX.4.2", "35=D\00134=2\00149=TW\00156=ISLD\00138=100\00111=ID\00138=200\001")
+
+	var testCases = []struct {
+		policy   duplicateTagPolicy
+		expected int
+	}{
+		{duplicateTagKeepFirst, 100},
+		{duplicateTagKeepLast, 200},
+	}
+
+	for _, tc := range testCases {
+		msg, err := parseMessage(raw)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		session := Session{duplicateTagPolicy: tc.policy, log: nullLog{}}
+		session.resolveDuplicateTags(msg)
+
+		orderQty := new(fix.IntValue)
+		if err := msg.Body.GetField(tag.OrderQty, orderQty); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if orderQty.Value != tc.expected {
+			t.Errorf("expected OrderQty %v got %v", tc.expected, orderQty.Value)
+		}
+
+		if repeated := msg.repeatedTags(nil); len(repeated) != 0 {
+			t.Errorf("expected repeated tags to be discarded, got %v", repeated)
+		}
+
+		clOrdID := new(fix.StringValue)
+		if err := msg.Body.GetField(tag.ClOrdID, clOrdID); err != nil || clOrdID.Value != "ID" {
+			t.Errorf("expected ClOrdID to be preserved, got %v %v", clOrdID.Value, err)
+		}
+	}
+}
+
+func TestSession_CheckDuplicateTags(t *testing.T) {
+	msg, _ := parseMessage(rawMessage("FIX.4.2", "35=D\00134=2\00149=TW\00156=ISLD\00138=100\00138=200\001"))
+
+	session := Session{duplicateTagPolicy: duplicateTagKeepLast}
+	if reject := session.checkDuplicateTags(*msg); reject != nil {
+		t.Error("unexpected reject", reject)
+	}
+
+	session.duplicateTagPolicy = duplicateTagReject
+	reject := session.checkDuplicateTags(*msg)
+	if reject == nil {
+		t.Fatal("expected reject")
+	}
+
+	if reject.RejectReason() != rejectReasonTagAppearsMoreThanOnce {
+		t.Errorf("expected reject reason %v got %v", rejectReasonTagAppearsMoreThanOnce, reject.RejectReason())
+	}
+
+	if *reject.RefTagID() != tag.OrderQty {
+		t.Errorf("expected ref tag %v got %v", tag.OrderQty, *reject.RefTagID())
+	}
+}
+
+func TestSession_CheckDuplicateTagsIgnoresGroups(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX42.xml")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	//NoAllocs group on NewOrderSingle repeats AllocAccount and AllocShares
+	msg, _ := parseMessage(rawMessage("FIX.4.2", "35=D\00134=2\00149=TW\00156=ISLD\00111=ID\00178=2\00179=A\00180=10\00179=B\00180=20\001"))
+
+	session := Session{duplicateTagPolicy: duplicateTagReject, dataDictionary: dict}
+	if reject := session.checkDuplicateTags(*msg); reject != nil {
+		t.Error("unexpected reject", reject)
+	}
+
+	session.dataDictionary = nil
+	if reject := session.checkDuplicateTags(*msg); reject == nil {
+		t.Error("expected reject without data dictionary")
+	}
+}
+
+func TestCreateSession_DuplicateTagPolicyRequiresDataDictionary(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "DUPS"}
+
+	//NoAllocs group on NewOrderSingle repeats AllocAccount and AllocShares
+	msg, _ := parseMessage(rawMessage("FIX.4.2", "35=D\00134=2\00149=DUPS\00156=TW\00111=ID\00178=2\00179=A\00180=10\00179=B\00180=20\001"))
+
+	for _, policy := range []string{"Reject", "KeepFirst", "KeepLast"} {
+		settings := NewSessionSettings()
+		settings.Set(config.DuplicateTagPolicy, policy)
+		if err := createSession(sessionID, NewMemoryStoreFactory(), settings, NewNullLogFactory(), &TestClient{}); err == nil {
+			unregisterSession(sessionID)
+			t.Errorf("%v: expected error without data dictionary, groups would be rejected as repeated tags", policy)
+		}
+	}
+
+	settings := NewSessionSettings()
+	settings.Set(config.DuplicateTagPolicy, "Reject")
+	settings.Set(config.DataDictionary, "spec/FIX42.xml")
+	if err := createSession(sessionID, NewMemoryStoreFactory(), settings, NewNullLogFactory(), &TestClient{}); err != nil {
+		t.Fatal(err)
+	}
+	defer unregisterSession(sessionID)
+
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reject := session.checkDuplicateTags(*msg); reject != nil {
+		t.Error("unexpected reject of group message", reject)
+	}
+}
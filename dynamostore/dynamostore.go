@@ -0,0 +1,651 @@
+//Package dynamostore provides a QuickFIX/Go MessageStore persisting sessions to an Amazon DynamoDB table.
+//The table is keyed by the string partition key "session" and the number sort key "seqnum". Each session has an item with seqnum 0
+//holding its sequence numbers, followed by an item per message stored. Sequence numbers are updated with conditional writes,
+//a session written by another engine fails the next TrySaveMessage rather than corrupting the sequence.
+package dynamostore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//StoreType is the MessageStoreType of the store, registered with quickfix.RegisterStoreFactory when the package is imported.
+const StoreType = "dynamo"
+
+func init() {
+	quickfix.RegisterStoreFactory(StoreType, NewStoreFactory)
+}
+
+const (
+	//maxBatch is the limit of items written by a BatchWriteItem
+	maxBatch = 25
+
+	//maxBatchAttempts is the number of attempts to write the unprocessed items of a batch, backing off from batchRetryInterval
+	maxBatchAttempts   = 8
+	batchRetryInterval = 50 * time.Millisecond
+
+	//healthTimeout limits the read of the session item by Health
+	healthTimeout = 5 * time.Second
+)
+
+//Attributes of the items of the table.
+const (
+	attrSession      = "session"
+	attrSeqNum       = "seqnum"
+	attrSender       = "sender"
+	attrTarget       = "target"
+	attrCreationTime = "creation_time"
+	attrLoggedOn     = "logged_on"
+	attrResendBegin  = "resend_begin"
+	attrResendEnd    = "resend_end"
+	attrMessage      = "msg"
+)
+
+//API is the part of *dynamodb.Client used by the store.
+type API interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+type storeFactory struct {
+	settings *quickfix.Settings
+
+	//client is set by NewStoreFactoryWithClient, otherwise clients are created per region and endpoint and shared by sessions
+	client     API
+	clientLock sync.Mutex
+	clients    map[string]API
+}
+
+//NewStoreFactory returns a MessageStoreFactory that creates MessageStores persisting sessions to the DynamoDB table DynamoStoreTable.
+//The client connects to DynamoStoreRegion, or AWS_REGION if not set, at DynamoStoreEndpoint if set, e.g. for DynamoDB local.
+//Credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN, as set in AWS Lambda.
+//Use NewStoreFactoryWithClient for other credentials.
+//With DynamoStoreMaxBatch above 1, up to that many messages are saved together, see NewStoreFactoryWithClient.
+func NewStoreFactory(settings *quickfix.Settings) (quickfix.MessageStoreFactory, error) {
+	for _, sessionSettings := range settings.SessionSettings() {
+		if _, _, err := clientSettingsFor(sessionSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	return newStoreFactory(nil, settings)
+}
+
+//NewStoreFactoryWithClient returns a MessageStoreFactory that creates MessageStores persisting sessions to DynamoDB with client.
+//DynamoStoreTable is the table of a session. Messages are written as saved by default. With DynamoStoreMaxBatch, at most 25,
+//messages are saved in batches, written once a batch fills, before messages are read for resend, and each time the session disconnects.
+//The batch size adapts to the capacity of the table, halving each time DynamoDB throttles a batch and growing again as batches are written.
+func NewStoreFactoryWithClient(client API, settings *quickfix.Settings) (quickfix.MessageStoreFactory, error) {
+	return newStoreFactory(client, settings)
+}
+
+func newStoreFactory(client API, settings *quickfix.Settings) (*storeFactory, error) {
+	for _, sessionSettings := range settings.SessionSettings() {
+		if _, _, err := storeSettingsFor(sessionSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	return &storeFactory{settings: settings, client: client, clients: make(map[string]API)}, nil
+}
+
+func (f *storeFactory) Create(sessionID quickfix.SessionID) (quickfix.MessageStore, error) {
+	settings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("dynamo store not defined for %v", sessionID)
+	}
+
+	table, batchSize, err := storeSettingsFor(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	client := f.client
+	if client == nil {
+		region, endpoint, err := clientSettingsFor(settings)
+		if err != nil {
+			return nil, err
+		}
+		client = f.clientFor(region, endpoint)
+	}
+
+	return newStore(sessionID, client, table, batchSize)
+}
+
+//clientFor returns the client for region and endpoint, creating it if not already created.
+func (f *storeFactory) clientFor(region, endpoint string) API {
+	f.clientLock.Lock()
+	defer f.clientLock.Unlock()
+
+	key := region + " " + endpoint
+	if client, ok := f.clients[key]; ok {
+		return client
+	}
+
+	options := dynamodb.Options{Region: region, Credentials: aws.NewCredentialsCache(aws.CredentialsProviderFunc(environmentCredentials))}
+	if endpoint != "" {
+		options.BaseEndpoint = aws.String(endpoint)
+	}
+
+	client := dynamodb.New(options)
+	f.clients[key] = client
+	return client
+}
+
+//environmentCredentials reads the credentials of the process from the environment.
+func environmentCredentials(ctx context.Context) (aws.Credentials, error) {
+	credentials := aws.Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Source:          "Environment",
+	}
+
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return aws.Credentials{}, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY not set")
+	}
+
+	return credentials, nil
+}
+
+//storeSettingsFor parses DynamoStoreTable and DynamoStoreMaxBatch.
+func storeSettingsFor(settings *quickfix.SessionSettings) (table string, batchSize int, err error) {
+	if table, err = settings.Setting(config.DynamoStoreTable); err != nil {
+		return "", 0, fmt.Errorf("missing configuration: %v", config.DynamoStoreTable)
+	}
+
+	batchSize = 1
+	if settings.HasSetting(config.DynamoStoreMaxBatch) {
+		if batchSize, err = settings.IntSetting(config.DynamoStoreMaxBatch); err != nil {
+			return "", 0, err
+		}
+
+		if batchSize < 1 || batchSize > maxBatch {
+			return "", 0, fmt.Errorf("invalid DynamoStoreMaxBatch %v, expected 1 to %v", batchSize, maxBatch)
+		}
+	}
+
+	return table, batchSize, nil
+}
+
+//clientSettingsFor parses DynamoStoreRegion and DynamoStoreEndpoint.
+func clientSettingsFor(settings *quickfix.SessionSettings) (region, endpoint string, err error) {
+	region = os.Getenv("AWS_REGION")
+	if settings.HasSetting(config.DynamoStoreRegion) {
+		region, _ = settings.Setting(config.DynamoStoreRegion)
+	}
+
+	if region == "" {
+		return "", "", fmt.Errorf("missing configuration: %v", config.DynamoStoreRegion)
+	}
+
+	if settings.HasSetting(config.DynamoStoreEndpoint) {
+		endpoint, _ = settings.Setting(config.DynamoStoreEndpoint)
+	}
+
+	return region, endpoint, nil
+}
+
+type store struct {
+	client    API
+	table     string
+	sessionID string
+
+	//lock guards the values cached from the session item, held across each conditional update so that the value expected is the value last set
+	lock                             sync.RWMutex
+	senderMsgSeqNum, targetMsgSeqNum int
+	creationTime                     time.Time
+	recoveryState                    quickfix.RecoveryState
+
+	//pending messages are written together once batchSize are saved, batchSize adapting between 1 and maxBatchSize
+	batchLock    sync.Mutex
+	pending      []types.WriteRequest
+	batchSize    int
+	maxBatchSize int
+
+	//writeErr keeps the first failed write, such as a sequence number update refused because another engine moved it, failing the next TrySaveMessage
+	writeErr quickfix.ErrorLatch
+}
+
+func newStore(sessionID quickfix.SessionID, client API, table string, batchSize int) (*store, error) {
+	s := &store{client: client, table: table, sessionID: sessionID.String(), batchSize: batchSize, maxBatchSize: batchSize}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+//key is the primary key of the item of seqNum, 0 for the session item.
+func (s *store) key(seqNum int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		attrSession: &types.AttributeValueMemberS{Value: s.sessionID},
+		attrSeqNum:  number(int64(seqNum)),
+	}
+}
+
+func number(n int64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
+
+//sessionItem is the session item holding the state of the session, with lock held.
+func (s *store) sessionItem() map[string]types.AttributeValue {
+	loggedOn := int64(0)
+	if s.recoveryState.LoggedOn {
+		loggedOn = 1
+	}
+
+	item := s.key(0)
+	item[attrSender] = number(int64(s.senderMsgSeqNum))
+	item[attrTarget] = number(int64(s.targetMsgSeqNum))
+	item[attrCreationTime] = number(s.creationTime.UnixNano())
+	item[attrLoggedOn] = number(loggedOn)
+	item[attrResendBegin] = number(int64(s.recoveryState.ResendBeginSeqNo))
+	item[attrResendEnd] = number(int64(s.recoveryState.ResendEndSeqNo))
+	return item
+}
+
+//load reads the session item, creating it if the session is new.
+func (s *store) load() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ctx := context.Background()
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(s.table), Key: s.key(0), ConsistentRead: aws.Bool(true)})
+	if err != nil {
+		return err
+	}
+
+	if len(out.Item) == 0 {
+		s.creationTime = time.Now().UTC()
+		s.senderMsgSeqNum, s.targetMsgSeqNum, s.recoveryState = 0, 0, quickfix.RecoveryState{}
+
+		_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:                aws.String(s.table),
+			Item:                     s.sessionItem(),
+			ConditionExpression:      aws.String("attribute_not_exists(#session)"),
+			ExpressionAttributeNames: map[string]string{"#session": attrSession},
+		})
+		return err
+	}
+
+	values := make(map[string]int64)
+	for _, attr := range []string{attrSender, attrTarget, attrCreationTime, attrLoggedOn, attrResendBegin, attrResendEnd} {
+		n, ok := out.Item[attr].(*types.AttributeValueMemberN)
+		if !ok {
+			return fmt.Errorf("missing %v of %v", attr, s.sessionID)
+		}
+
+		if values[attr], err = strconv.ParseInt(n.Value, 10, 64); err != nil {
+			return fmt.Errorf("invalid %v of %v: %v", attr, s.sessionID, err)
+		}
+	}
+
+	s.senderMsgSeqNum, s.targetMsgSeqNum = int(values[attrSender]), int(values[attrTarget])
+	s.creationTime = time.Unix(0, values[attrCreationTime]).UTC()
+	s.recoveryState = quickfix.RecoveryState{
+		LoggedOn:         values[attrLoggedOn] == 1,
+		ResendBeginSeqNo: int(values[attrResendBegin]),
+		ResendEndSeqNo:   int(values[attrResendEnd]),
+	}
+	return nil
+}
+
+//setSeqNum sets the seqnum attr from current to next, on condition it is still current, with lock held.
+func (s *store) setSeqNum(attr string, current, next int) {
+	_, err := s.client.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       s.key(0),
+		UpdateExpression:          aws.String("SET #seqnum = :next"),
+		ConditionExpression:       aws.String("#seqnum = :current"),
+		ExpressionAttributeNames:  map[string]string{"#seqnum": attr},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":current": number(int64(current)), ":next": number(int64(next))},
+	})
+
+	var conflict *types.ConditionalCheckFailedException
+	if errors.As(err, &conflict) {
+		err = fmt.Errorf("sequence number %v of %v is not %v, written by another engine", attr, s.sessionID, current)
+	}
+
+	s.writeErr.Set(err)
+}
+
+func (s *store) NextSenderMsgSeqNum() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.senderMsgSeqNum + 1
+}
+
+func (s *store) NextTargetMsgSeqNum() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.targetMsgSeqNum + 1
+}
+
+func (s *store) IncrNextSenderMsgSeqNum() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setSeqNum(attrSender, s.senderMsgSeqNum, s.senderMsgSeqNum+1)
+	s.senderMsgSeqNum++
+}
+
+func (s *store) IncrNextTargetMsgSeqNum() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setSeqNum(attrTarget, s.targetMsgSeqNum, s.targetMsgSeqNum+1)
+	s.targetMsgSeqNum++
+}
+
+func (s *store) SetNextSenderMsgSeqNum(next int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setSeqNum(attrSender, s.senderMsgSeqNum, next-1)
+	s.senderMsgSeqNum = next - 1
+}
+
+func (s *store) SetNextTargetMsgSeqNum(next int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setSeqNum(attrTarget, s.targetMsgSeqNum, next-1)
+	s.targetMsgSeqNum = next - 1
+}
+
+func (s *store) CreationTime() time.Time {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.creationTime
+}
+
+func (s *store) SaveRecoveryState(state quickfix.RecoveryState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	loggedOn := int64(0)
+	if state.LoggedOn {
+		loggedOn = 1
+	}
+
+	_, err := s.client.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:        aws.String(s.table),
+		Key:              s.key(0),
+		UpdateExpression: aws.String("SET #logged_on = :logged_on, #resend_begin = :resend_begin, #resend_end = :resend_end"),
+		ExpressionAttributeNames: map[string]string{
+			"#logged_on":    attrLoggedOn,
+			"#resend_begin": attrResendBegin,
+			"#resend_end":   attrResendEnd,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":logged_on":    number(loggedOn),
+			":resend_begin": number(int64(state.ResendBeginSeqNo)),
+			":resend_end":   number(int64(state.ResendEndSeqNo)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recoveryState = state
+	return nil
+}
+
+func (s *store) RecoveryState() (quickfix.RecoveryState, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.recoveryState, nil
+}
+
+//Reset deletes the messages of the session and resets the sequence numbers, starting a new session.
+//The session item is replaced first, so an interrupted reset leaves messages to be replaced rather than resent.
+func (s *store) Reset() {
+	s.batchLock.Lock()
+	s.pending = nil
+	s.batchLock.Unlock()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.creationTime = time.Now().UTC()
+	s.senderMsgSeqNum, s.targetMsgSeqNum = 0, 0
+	s.recoveryState.ResendBeginSeqNo, s.recoveryState.ResendEndSeqNo = 0, 0
+
+	if _, err := s.client.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String(s.table), Item: s.sessionItem()}); err != nil {
+		s.writeErr.Set(err)
+		return
+	}
+
+	s.writeErr.Set(s.deleteMessages(1, 0))
+}
+
+//Refresh reloads the session item, clearing any write error.
+func (s *store) Refresh() {
+	s.writeErr.Clear()
+
+	s.writeErr.Set(s.load())
+}
+
+//Health returns the first write error since the store was refreshed, or the failure to read the session item.
+func (s *store) Health() error {
+	if err := s.writeErr.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+	_, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(s.table), Key: s.key(0)})
+	return err
+}
+
+//TrySaveMessage puts msg as the item of seqNum, once a batch of messages is saved if batching.
+func (s *store) TrySaveMessage(seqNum int, msg []byte) error {
+	if err := s.writeErr.Err(); err != nil {
+		return err
+	}
+
+	item := s.key(seqNum)
+	item[attrMessage] = &types.AttributeValueMemberB{Value: msg}
+
+	if s.maxBatchSize == 1 {
+		_, err := s.client.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String(s.table), Item: item})
+		return err
+	}
+
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+
+	s.pending = append(s.pending, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	if len(s.pending) < s.batchSize {
+		return nil
+	}
+
+	return s.flushLocked()
+}
+
+//SaveMessage puts or batches msg as TrySaveMessage, a failed write, or the session taken by another engine, kept as the error reported by Health.
+func (s *store) SaveMessage(seqNum int, msg []byte) {
+	s.writeErr.Set(s.TrySaveMessage(seqNum, msg))
+}
+
+//flushLocked writes the pending messages, adapting the batch size, with batchLock held.
+func (s *store) flushLocked() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	throttled, err := s.writeBatch(s.pending)
+	if err != nil {
+		return err
+	}
+	s.pending = nil
+
+	switch {
+	case throttled:
+		s.batchSize = (s.batchSize + 1) / 2
+	case s.batchSize < s.maxBatchSize:
+		s.batchSize++
+	}
+
+	return nil
+}
+
+//Flush writes the messages of an incomplete batch.
+func (s *store) Flush() error {
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+
+	if err := s.flushLocked(); err != nil {
+		return err
+	}
+
+	return s.writeErr.Err()
+}
+
+//writeBatch writes requests maxBatch at a time, retrying the items left unprocessed when throttled.
+//Returns true if any items were throttled.
+func (s *store) writeBatch(requests []types.WriteRequest) (throttled bool, err error) {
+	ctx := context.Background()
+
+	for len(requests) > 0 {
+		n := len(requests)
+		if n > maxBatch {
+			n = maxBatch
+		}
+
+		batch := requests[:n]
+		requests = requests[n:]
+
+		retryInterval := batchRetryInterval
+		for attempt := 1; len(batch) > 0; attempt++ {
+			out, err := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{s.table: batch}})
+			if err != nil {
+				return throttled, err
+			}
+
+			if batch = out.UnprocessedItems[s.table]; len(batch) == 0 {
+				break
+			}
+
+			throttled = true
+			if attempt == maxBatchAttempts {
+				return throttled, fmt.Errorf("%d items of %v unprocessed after %d attempts", len(batch), s.sessionID, attempt)
+			}
+
+			time.Sleep(retryInterval)
+			retryInterval *= 2
+		}
+	}
+
+	return throttled, nil
+}
+
+//queryKeys returns the keys of the message items with seqnums from beginSeqNum to endSeqNum, to the last message if endSeqNum is 0.
+func (s *store) queryKeys(beginSeqNum, endSeqNum int) ([]map[string]types.AttributeValue, error) {
+	var keys []map[string]types.AttributeValue
+	err := s.query(beginSeqNum, endSeqNum, true, func(item map[string]types.AttributeValue) {
+		keys = append(keys, item)
+	})
+
+	return keys, err
+}
+
+//query reads the message items with seqnums from beginSeqNum to endSeqNum in order, to the last message if endSeqNum is 0.
+func (s *store) query(beginSeqNum, endSeqNum int, keysOnly bool, f func(item map[string]types.AttributeValue)) error {
+	if beginSeqNum < 1 {
+		beginSeqNum = 1
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(s.table),
+		ConsistentRead:            aws.Bool(true),
+		KeyConditionExpression:    aws.String("#session = :session AND #seqnum >= :begin"),
+		ExpressionAttributeNames:  map[string]string{"#session": attrSession, "#seqnum": attrSeqNum},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":session": &types.AttributeValueMemberS{Value: s.sessionID}, ":begin": number(int64(beginSeqNum))},
+	}
+
+	if endSeqNum != 0 {
+		input.KeyConditionExpression = aws.String("#session = :session AND #seqnum BETWEEN :begin AND :end")
+		input.ExpressionAttributeValues[":end"] = number(int64(endSeqNum))
+	}
+
+	if keysOnly {
+		input.ProjectionExpression = aws.String("#session, #seqnum")
+	}
+
+	for {
+		out, err := s.client.Query(context.Background(), input)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range out.Items {
+			f(item)
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+//deleteMessages deletes the message items with seqnums from beginSeqNum to endSeqNum, to the last message if endSeqNum is 0.
+func (s *store) deleteMessages(beginSeqNum, endSeqNum int) error {
+	keys, err := s.queryKeys(beginSeqNum, endSeqNum)
+	if err != nil {
+		return err
+	}
+
+	requests := make([]types.WriteRequest, 0, len(keys))
+	for _, key := range keys {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+	}
+
+	_, err = s.writeBatch(requests)
+	return err
+}
+
+//Compact deletes the messages with seqnums below seqNum.
+func (s *store) Compact(seqNum int) error {
+	if seqNum <= 1 {
+		return nil
+	}
+
+	return s.deleteMessages(1, seqNum-1)
+}
+
+//GetMessages returns the messages saved with seqnums from beginSeqNum to endSeqNum, in order, once pending messages are written.
+func (s *store) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
+	msgs := make(chan []byte)
+
+	s.batchLock.Lock()
+	flushErr := s.flushLocked()
+	s.batchLock.Unlock()
+
+	go func() {
+		defer close(msgs)
+
+		if flushErr != nil || endSeqNum < 1 {
+			return
+		}
+
+		s.query(beginSeqNum, endSeqNum, false, func(item map[string]types.AttributeValue) {
+			if msg, ok := item[attrMessage].(*types.AttributeValueMemberB); ok {
+				msgs <- msg.Value
+			}
+		})
+	}()
+
+	return msgs
+}
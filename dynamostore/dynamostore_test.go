@@ -0,0 +1,330 @@
+package dynamostore
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/storetest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var testSessionID = quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+
+//fakeDynamo is an in memory table interpreting the expressions written by the store.
+type fakeDynamo struct {
+	lock  sync.Mutex
+	items map[string]map[int]map[string]types.AttributeValue
+
+	//throttle is the number of items of the next batch left unprocessed
+	throttle int
+	batches  int
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: make(map[string]map[int]map[string]types.AttributeValue)}
+}
+
+func keyOf(key map[string]types.AttributeValue) (string, int) {
+	seqNum, _ := strconv.Atoi(key[attrSeqNum].(*types.AttributeValueMemberN).Value)
+	return key[attrSession].(*types.AttributeValueMemberS).Value, seqNum
+}
+
+func (d *fakeDynamo) get(key map[string]types.AttributeValue) map[string]types.AttributeValue {
+	session, seqNum := keyOf(key)
+	return d.items[session][seqNum]
+}
+
+func (d *fakeDynamo) put(item map[string]types.AttributeValue) {
+	session, seqNum := keyOf(item)
+	if d.items[session] == nil {
+		d.items[session] = make(map[int]map[string]types.AttributeValue)
+	}
+	d.items[session][seqNum] = item
+}
+
+func (d *fakeDynamo) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return &dynamodb.GetItemOutput{Item: d.get(params.Key)}, nil
+}
+
+func (d *fakeDynamo) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if aws.ToString(params.ConditionExpression) == "attribute_not_exists(#session)" && d.get(params.Item) != nil {
+		return nil, &types.ConditionalCheckFailedException{}
+	}
+
+	d.put(params.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (d *fakeDynamo) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	item := d.get(params.Key)
+	if condition := aws.ToString(params.ConditionExpression); condition != "" {
+		operands := strings.Split(condition, " = ")
+		if item[params.ExpressionAttributeNames[operands[0]]].(*types.AttributeValueMemberN).Value != params.ExpressionAttributeValues[operands[1]].(*types.AttributeValueMemberN).Value {
+			return nil, &types.ConditionalCheckFailedException{}
+		}
+	}
+
+	for _, assignment := range strings.Split(strings.TrimPrefix(aws.ToString(params.UpdateExpression), "SET "), ", ") {
+		operands := strings.Split(assignment, " = ")
+		item[params.ExpressionAttributeNames[operands[0]]] = params.ExpressionAttributeValues[operands[1]]
+	}
+
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (d *fakeDynamo) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.batches++
+	out := &dynamodb.BatchWriteItemOutput{UnprocessedItems: make(map[string][]types.WriteRequest)}
+	for table, requests := range params.RequestItems {
+		for i, request := range requests {
+			if i >= len(requests)-d.throttle {
+				out.UnprocessedItems[table] = append(out.UnprocessedItems[table], request)
+				continue
+			}
+
+			if request.PutRequest != nil {
+				d.put(request.PutRequest.Item)
+			} else {
+				session, seqNum := keyOf(request.DeleteRequest.Key)
+				delete(d.items[session], seqNum)
+			}
+		}
+	}
+	d.throttle = 0
+
+	return out, nil
+}
+
+//Query returns at most 2 items per page, to read across pages.
+func (d *fakeDynamo) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	values := params.ExpressionAttributeValues
+	begin, _ := strconv.Atoi(values[":begin"].(*types.AttributeValueMemberN).Value)
+	end := int(^uint(0) >> 1)
+	if value, ok := values[":end"]; ok {
+		end, _ = strconv.Atoi(value.(*types.AttributeValueMemberN).Value)
+	}
+
+	if params.ExclusiveStartKey != nil {
+		_, last := keyOf(params.ExclusiveStartKey)
+		begin = last + 1
+	}
+
+	items := d.items[values[":session"].(*types.AttributeValueMemberS).Value]
+	var seqNums []int
+	for seqNum := range items {
+		if seqNum >= begin && seqNum <= end {
+			seqNums = append(seqNums, seqNum)
+		}
+	}
+	sort.Ints(seqNums)
+
+	out := &dynamodb.QueryOutput{}
+	for _, seqNum := range seqNums {
+		if len(out.Items) == 2 {
+			out.LastEvaluatedKey = out.Items[1]
+			break
+		}
+		out.Items = append(out.Items, items[seqNum])
+	}
+
+	return out, nil
+}
+
+func (d *fakeDynamo) messages(s *store) []int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var seqNums []int
+	for seqNum := range d.items[s.sessionID] {
+		if seqNum > 0 {
+			seqNums = append(seqNums, seqNum)
+		}
+	}
+	sort.Ints(seqNums)
+	return seqNums
+}
+
+func readMessages(s *store, beginSeqNum, endSeqNum int) []string {
+	var msgs []string
+	for msg := range s.GetMessages(beginSeqNum, endSeqNum) {
+		msgs = append(msgs, string(msg))
+	}
+	return msgs
+}
+
+func TestStoreSettingsFor(t *testing.T) {
+	settings := quickfix.NewSessionSettings()
+	if _, _, err := storeSettingsFor(settings); err == nil {
+		t.Error("Expected error without DynamoStoreTable")
+	}
+
+	settings.Set(config.DynamoStoreTable, "quickfix")
+	settings.Set(config.DynamoStoreMaxBatch, "26")
+	if _, _, err := storeSettingsFor(settings); err == nil {
+		t.Error("Expected error for DynamoStoreMaxBatch above 25")
+	}
+
+	settings.Set(config.DynamoStoreMaxBatch, "10")
+	if table, batchSize, err := storeSettingsFor(settings); err != nil || table != "quickfix" || batchSize != 10 {
+		t.Errorf("Unexpected settings %v %v %v", table, batchSize, err)
+	}
+
+	settings.Set(config.DynamoStoreRegion, "eu-west-1")
+	settings.Set(config.DynamoStoreEndpoint, "http://localhost:8000")
+	if region, endpoint, err := clientSettingsFor(settings); err != nil || region != "eu-west-1" || endpoint != "http://localhost:8000" {
+		t.Errorf("Unexpected client settings %v %v %v", region, endpoint, err)
+	}
+}
+
+func TestStore_Persistence(t *testing.T) {
+	client := newFakeDynamo()
+	s, err := newStore(testSessionID, client, "quickfix", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.SetNextSenderMsgSeqNum(4)
+	s.IncrNextTargetMsgSeqNum()
+	s.SaveRecoveryState(quickfix.RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2})
+	for seqNum, msg := range []string{"hello", "cruel", "world"} {
+		if err := s.TrySaveMessage(seqNum+1, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	restarted, err := newStore(testSessionID, client, "quickfix", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restarted.NextSenderMsgSeqNum() != 4 || restarted.NextTargetMsgSeqNum() != 2 {
+		t.Errorf("Expected seqnums 4 and 2 got %v and %v", restarted.NextSenderMsgSeqNum(), restarted.NextTargetMsgSeqNum())
+	}
+
+	if state, _ := restarted.RecoveryState(); state != (quickfix.RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2}) {
+		t.Errorf("Unexpected recovery state %+v", state)
+	}
+
+	if !restarted.CreationTime().Equal(s.CreationTime()) {
+		t.Errorf("Expected creation time %v got %v", s.CreationTime(), restarted.CreationTime())
+	}
+
+	if msgs := readMessages(restarted, 2, 5); len(msgs) != 2 || msgs[0] != "cruel" || msgs[1] != "world" {
+		t.Errorf("Unexpected messages %v", msgs)
+	}
+
+	restarted.Reset()
+	if restarted.NextSenderMsgSeqNum() != 1 || len(client.messages(restarted)) != 0 {
+		t.Error("Expected empty store after reset")
+	}
+
+	if err := restarted.Health(); err != nil {
+		t.Error("Did not expect error", err)
+	}
+}
+
+func TestStore_SeqNumConflict(t *testing.T) {
+	client := newFakeDynamo()
+	s, _ := newStore(testSessionID, client, "quickfix", 1)
+	other, _ := newStore(testSessionID, client, "quickfix", 1)
+
+	s.IncrNextSenderMsgSeqNum()
+	other.IncrNextSenderMsgSeqNum()
+
+	if err := other.TrySaveMessage(1, []byte("hello")); err == nil {
+		t.Error("Expected conflicting write of sender seqnum to fail the next save")
+	}
+
+	if other.Health() == nil {
+		t.Error("Expected conflict reported by health")
+	}
+
+	if err := s.TrySaveMessage(1, []byte("hello")); err != nil {
+		t.Error("Did not expect error", err)
+	}
+}
+
+func TestStore_AdaptiveBatch(t *testing.T) {
+	client := newFakeDynamo()
+	s, _ := newStore(testSessionID, client, "quickfix", 4)
+
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		s.SaveMessage(seqNum, []byte("msg"))
+	}
+
+	if client.batches != 0 {
+		t.Fatal("Expected messages held until the batch fills")
+	}
+
+	client.throttle = 1
+	s.SaveMessage(4, []byte("msg"))
+
+	if client.batches != 2 || s.batchSize != 2 {
+		t.Errorf("Expected throttled item retried and batch size halved, got %v batches, batch size %v", client.batches, s.batchSize)
+	}
+
+	s.SaveMessage(5, []byte("msg"))
+	s.SaveMessage(6, []byte("msg"))
+	if s.batchSize != 3 {
+		t.Errorf("Expected batch size to grow once written, got %v", s.batchSize)
+	}
+
+	s.SaveMessage(7, []byte("msg"))
+	if msgs := readMessages(s, 1, 10); len(msgs) != 7 {
+		t.Errorf("Expected pending messages written before read, got %v", msgs)
+	}
+}
+
+func TestStore_Compact(t *testing.T) {
+	client := newFakeDynamo()
+	s, _ := newStore(testSessionID, client, "quickfix", 1)
+
+	for seqNum := 1; seqNum <= 5; seqNum++ {
+		s.SaveMessage(seqNum, []byte("msg"))
+	}
+
+	if err := s.Compact(4); err != nil {
+		t.Fatal(err)
+	}
+
+	if seqNums := client.messages(s); len(seqNums) != 2 || seqNums[0] != 4 {
+		t.Errorf("Expected messages 4 and 5 kept, got %v", seqNums)
+	}
+}
+
+func TestStore_Conformance(t *testing.T) {
+	storetest.Run(t, storetest.Harness{
+		NewBackend: func(t *testing.T) func([]quickfix.SessionID) quickfix.MessageStoreFactory {
+			client := newFakeDynamo()
+			return func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory {
+				factory, err := NewStoreFactoryWithClient(client, storetest.Settings(sessionIDs, map[string]string{config.DynamoStoreTable: "quickfix"}))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return factory
+			}
+		},
+		Persistent: true,
+	})
+}
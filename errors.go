@@ -1,8 +1,10 @@
 package quickfix
 
 import (
+	"errors"
 	"fmt"
 	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
 )
 
 //rejectReason enum values.
@@ -21,6 +23,8 @@ const (
 	rejectReasonTagAppearsMoreThanOnce                    = 13
 	rejectReasonTagSpecifiedOutOfRequiredOrder            = 14
 	rejectReasonIncorrectNumInGroupCountForRepeatingGroup = 16
+	rejectReasonInvalidUnsupportedApplicationVersion      = 18
+	rejectReasonOther                                     = 99
 )
 
 //MessageRejectError is a type of error that can correlate to a message reject.
@@ -56,6 +60,72 @@ func NewBusinessMessageRejectError(err string, rejectReason int, refTagID *fix.T
 	return messageRejectError{text: err, rejectReason: rejectReason, refTagID: refTagID, isBusinessReject: true}
 }
 
+//OutboundRejectError is returned when sending an application message that fails validation against the counterparty data dictionary.
+//The message is not sent and does not consume a sequence number.
+type OutboundRejectError struct {
+	MessageRejectError
+}
+
+func (e OutboundRejectError) Error() string {
+	return "counterparty validation failed: " + e.MessageRejectError.Error()
+}
+
+//adminRejectAction is the response of a session to a message refused with an admin reject error.
+type adminRejectAction int
+
+const (
+	//adminRejectLogout sends a Logout and disconnects.
+	adminRejectLogout adminRejectAction = iota
+
+	//adminRejectDisconnect disconnects without a Logout.
+	adminRejectDisconnect
+
+	//adminRejectIgnore discards the message without a Reject.
+	adminRejectIgnore
+)
+
+//adminRejectError refuses a message with a protocol response other than a Reject.
+type adminRejectError struct {
+	messageRejectError
+	action adminRejectAction
+}
+
+//NewRejectLogonError returns a MessageRejectError for FromAdmin, FromApp, or an Interceptor to refuse a message, for example a Logon with invalid credentials.
+//The session sends a Logout with text and disconnects.
+func NewRejectLogonError(text string) MessageRejectError {
+	return adminRejectError{messageRejectError{text: text, rejectReason: rejectReasonOther}, adminRejectLogout}
+}
+
+//NewDisconnectError returns a MessageRejectError for FromAdmin, FromApp, or an Interceptor to refuse a message by disconnecting without a Logout.
+func NewDisconnectError(text string) MessageRejectError {
+	return adminRejectError{messageRejectError{text: text, rejectReason: rejectReasonOther}, adminRejectDisconnect}
+}
+
+//NewIgnoreMessageError returns a MessageRejectError for FromAdmin, FromApp, or an Interceptor to discard a message without sending a Reject.
+//The sequence number of the message is consumed. A Logon cannot be ignored, the session disconnects instead.
+func NewIgnoreMessageError(text string) MessageRejectError {
+	return adminRejectError{messageRejectError{text: text, rejectReason: rejectReasonOther}, adminRejectIgnore}
+}
+
+//ErrSendQueueFull is returned when sending a message while not logged on with MaxSendQueueDepth messages already queued and SendQueueOverflow set to Error.
+var ErrSendQueueFull = errors.New("send queue full")
+
+//StoreError is returned when sending a message that cannot be saved to the MessageStore.
+//The message is not sent and does not consume a sequence number.
+type StoreError struct {
+	Err error
+}
+
+func (e StoreError) Error() string {
+	return "store failure: " + e.Err.Error()
+}
+
+//ErrThrottled is returned when sending a message over the ThrottleRate with ThrottlePolicy set to Reject.
+var ErrThrottled = errors.New("send throttled")
+
+//ErrOutboundQueueFull is returned when sending a message with OutboundQueueDepth messages already queued and OutboundQueueOverflow set to Error.
+var ErrOutboundQueueFull = errors.New("outbound queue full")
+
 //incorrectDataFormatForValue returns an error indicating a field that cannot be parsed as the type required.
 func incorrectDataFormatForValue(tag fix.Tag) MessageRejectError {
 	return NewMessageRejectError("Incorrect data format for value", rejectReasonIncorrectDataFormatForValue, &tag)
@@ -132,6 +202,18 @@ func sendingTimeAccuracyProblem() MessageRejectError {
 	return NewMessageRejectError("SendingTime accuracy problem", rejectReasonSendingTimeAccuracyProblem, nil)
 }
 
+//origSendingTimeProblem creates a reject for a possible duplicate with OrigSendingTime after SendingTime.
+func origSendingTimeProblem() MessageRejectError {
+	refTag := tag.OrigSendingTime
+	return NewMessageRejectError("SendingTime accuracy problem", rejectReasonSendingTimeAccuracyProblem, &refTag)
+}
+
+//unsupportedApplicationVersion creates a reject for a msg with an ApplVerID that is not configured for the session.
+func unsupportedApplicationVersion() MessageRejectError {
+	refTagID := tag.ApplVerID
+	return NewMessageRejectError("Invalid/Unsupported Application Version", rejectReasonInvalidUnsupportedApplicationVersion, &refTagID)
+}
+
 //requiredConfigurationMissing indicates a missing required conditional configuration option.
 func requiredConfigurationMissing(setting string) error {
 	return fmt.Errorf("missing configuration: %v", setting)
@@ -1,5 +1,7 @@
 package quickfix
 
+import "errors"
+
 type event int
 
 const (
@@ -8,3 +10,14 @@ const (
 	logonTimeout
 	logoutTimeout
 )
+
+var eventReasons = map[event]error{
+	peerTimeout:   errors.New("heartbeat timeout"),
+	logonTimeout:  errors.New("logon timeout"),
+	logoutTimeout: errors.New("logout timeout"),
+}
+
+//reason returns the cause of a state transition on the event, nil if the event is routine.
+func (e event) reason() error {
+	return eventReasons[e]
+}
@@ -1,21 +1,50 @@
 package quickfix
 
 import (
+	"sync/atomic"
 	"time"
 )
 
 type eventTimer struct {
 	Task  func()
-	timer *time.Timer
+	timer Timer
+
+	//clock is SystemClock if nil
+	clock Clock
+
+	//pooled timers are fired by the ticker of the worker pool running the session, rather than a timer of their own
+	pooled bool
+	//due is when a pooled timer fires, in UnixNano, 0 if not set
+	due int64
 }
 
 func (t *eventTimer) Reset(timeout time.Duration) (ok bool) {
+	clock := t.clock
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	if t.pooled {
+		return atomic.SwapInt64(&t.due, clock.Now().Add(timeout).UnixNano()) != 0
+	}
+
 	if t.timer != nil {
 		ok = t.timer.Stop()
 	} else {
 		ok = true
 	}
 
-	t.timer = time.AfterFunc(timeout, t.Task)
+	t.timer = clock.AfterFunc(timeout, t.Task)
 	return
 }
+
+//expire returns true, once, if a pooled timer is due by now.
+func (t *eventTimer) expire(now time.Time) bool {
+	due := atomic.LoadInt64(&t.due)
+	return due != 0 && now.UnixNano() >= due && atomic.CompareAndSwapInt64(&t.due, due, 0)
+}
+
+//clear unsets a pooled timer.
+func (t *eventTimer) clear() {
+	atomic.StoreInt64(&t.due, 0)
+}
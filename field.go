@@ -10,6 +10,12 @@ type FieldValue interface {
 	Read([]byte) error
 }
 
+//FieldValueAppender may be implemented by a FieldValue to append its value to a buffer, so that a MessageBuilder reused with Reset
+//sets the field without allocating. The field values of package fix implement it.
+type FieldValueAppender interface {
+	AppendValue(dst []byte) []byte
+}
+
 //Field is the interface implemented by all typed Fields in a Message
 type Field interface {
 	Tag() fix.Tag
@@ -14,13 +14,23 @@ type fieldBytes struct {
 }
 
 func newFieldBytes(tag fix.Tag, value []byte) *fieldBytes {
-	var buf bytes.Buffer
-	buf.WriteString(strconv.Itoa(int(tag)))
-	buf.WriteString("=")
-	buf.Write(value)
-	buf.WriteString("")
+	f := &fieldBytes{Tag: tag, Data: make([]byte, 0, len(value)+8)}
+	f.Data = append(appendTag(f.Data, tag), value...)
+	f.terminate(len(f.Data) - len(value))
+	f.Value = value
 
-	return &fieldBytes{Tag: tag, Data: buf.Bytes(), Value: value}
+	return f
+}
+
+//appendTag appends tag and the = that follows it to dst.
+func appendTag(dst []byte, tag fix.Tag) []byte {
+	return append(strconv.AppendInt(dst, int64(tag), 10), '=')
+}
+
+//terminate appends the delimiter to Data, holding the tag and the value starting at start, and sets Value to the value.
+func (f *fieldBytes) terminate(start int) {
+	f.Data = append(f.Data, '\001')
+	f.Value = f.Data[start : len(f.Data)-1]
 }
 
 func (f *fieldBytes) parseField(rawFieldBytes []byte) (err error) {
@@ -45,6 +55,11 @@ func (f *fieldBytes) parseField(rawFieldBytes []byte) (err error) {
 	return
 }
 
+//clone returns a copy of the field, not sharing its storage.
+func (f *fieldBytes) clone() *fieldBytes {
+	return &fieldBytes{Tag: f.Tag, Data: append([]byte(nil), f.Data...), Value: append([]byte(nil), f.Value...)}
+}
+
 func (f *fieldBytes) String() string {
 	return string(f.Data)
 }
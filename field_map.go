@@ -6,6 +6,7 @@ import (
 	"github.com/quickfixgo/quickfix/fix/tag"
 	"math"
 	"sort"
+	"strconv"
 )
 
 //FieldMap is a collection of fix fields that make up a fix message.
@@ -24,6 +25,9 @@ type MutableFieldMap interface {
 
 	SetField(tag fix.Tag, field FieldValue)
 	Set(field Field)
+
+	//SetGroup sets the NumInGroup field and entries of group, returns an error if an entry is malformed.
+	SetGroup(group *RepeatingGroup) error
 }
 
 // fieldOrder true if tag i should occur before tag j
@@ -84,6 +88,18 @@ func trailerFieldOrder(i, j fix.Tag) bool {
 type fieldMap struct {
 	fieldLookup map[fix.Tag]*fieldBytes
 	fieldOrder
+
+	//buffers hold the storage of the fields of a MessageBuilder, reused once reset, nil for the fields of messages parsed
+	buffers *fieldBuffers
+}
+
+//fieldBuffers are the storage of the fields of a fieldMap, reused so that a MessageBuilder reset and built again does not allocate.
+type fieldBuffers struct {
+	//spare are the fields removed by reset, reused by the fields set next
+	spare []*fieldBytes
+
+	//tags are the tags of the fields, sorted by AppendBuild
+	tags []fix.Tag
 }
 
 func (m *fieldMap) init(ordering fieldOrder) {
@@ -91,6 +107,64 @@ func (m *fieldMap) init(ordering fieldOrder) {
 	m.fieldOrder = ordering
 }
 
+//initReusable initializes a fieldMap reusing the storage of its fields once reset.
+func (m *fieldMap) initReusable(ordering fieldOrder) {
+	m.init(ordering)
+	m.buffers = new(fieldBuffers)
+}
+
+//clear removes every field, keeping their storage for the fields set next if reusable.
+func (m fieldMap) clear() {
+	for t, field := range m.fieldLookup {
+		if m.buffers != nil {
+			m.buffers.spare = append(m.buffers.spare, field)
+		}
+		delete(m.fieldLookup, t)
+	}
+}
+
+//newField returns a field for tag t, a spare field if any, with Data holding the tag and =.
+//The field replaces any field with tag t, which is not reused.
+func (m fieldMap) newField(t fix.Tag) *fieldBytes {
+	var field *fieldBytes
+	if spare := m.buffers; spare != nil && len(spare.spare) > 0 {
+		field = spare.spare[len(spare.spare)-1]
+		spare.spare = spare.spare[:len(spare.spare)-1]
+	} else {
+		field = new(fieldBytes)
+	}
+
+	field.Tag = t
+	field.Data = appendTag(field.Data[:0], t)
+	m.fieldLookup[t] = field
+	return field
+}
+
+//setField sets the field with tag t to value, appended to the storage of the field if reusable.
+func (m fieldMap) setField(t fix.Tag, value FieldValue) {
+	if m.buffers == nil {
+		m.fieldLookup[t] = newFieldBytes(t, value.Write())
+		return
+	}
+
+	field := m.newField(t)
+	start := len(field.Data)
+	if appender, ok := value.(FieldValueAppender); ok {
+		field.Data = appender.AppendValue(field.Data)
+	} else {
+		field.Data = append(field.Data, value.Write()...)
+	}
+	field.terminate(start)
+}
+
+//setInt sets the field with tag t to value.
+func (m fieldMap) setInt(t fix.Tag, value int) {
+	field := m.newField(t)
+	start := len(field.Data)
+	field.Data = strconv.AppendInt(field.Data, int64(value), 10)
+	field.terminate(start)
+}
+
 func (m fieldMap) Tags() []fix.Tag {
 	tags := make([]fix.Tag, 0, len(m.fieldLookup))
 	for t := range m.fieldLookup {
@@ -124,31 +198,84 @@ func (m fieldMap) GetField(tag fix.Tag, parser FieldValue) MessageRejectError {
 }
 
 func (m fieldMap) SetField(tag fix.Tag, field FieldValue) {
-	m.fieldLookup[tag] = newFieldBytes(tag, field.Write())
+	m.setField(tag, field)
 }
 
 func (m fieldMap) Set(field Field) {
-	m.fieldLookup[field.Tag()] = newFieldBytes(field.Tag(), field.Write())
+	m.setField(field.Tag(), field)
 }
 
+func (m fieldMap) SetGroup(group *RepeatingGroup) error {
+	f, err := group.build()
+	if err != nil {
+		return err
+	}
+
+	m.fieldLookup[group.Tag()] = f
+	return nil
+}
+
+//maxInsertionSort is the number of tags sorted by insertion, which unlike sort.Sort does not allocate.
+const maxInsertionSort = 64
+
+//sortedTags returns the tags of the fields in order, in a slice of their own so that the fields may be read concurrently.
 func (m fieldMap) sortedTags() []fix.Tag {
-	sortedTags := make([]fix.Tag, len(m.fieldLookup))
+	return m.sortTags(make([]fix.Tag, 0, len(m.fieldLookup)))
+}
+
+//reusedSortedTags returns the tags of the fields in order, sorted in the storage of the map if reusable, which the map must not be read
+//concurrently with.
+func (m fieldMap) reusedSortedTags() []fix.Tag {
+	if m.buffers == nil {
+		return m.sortedTags()
+	}
+
+	m.buffers.tags = m.sortTags(m.buffers.tags[:0])
+	return m.buffers.tags
+}
+
+//sortTags appends the tags of the fields to sortedTags, returning them in order.
+func (m fieldMap) sortTags(sortedTags []fix.Tag) []fix.Tag {
 	for tag := range m.fieldLookup {
 		sortedTags = append(sortedTags, tag)
 	}
 
-	sort.Sort(fieldSort{sortedTags, m.fieldOrder})
+	if len(sortedTags) > maxInsertionSort {
+		sort.Sort(fieldSort{sortedTags, m.fieldOrder})
+	} else {
+		for i := 1; i < len(sortedTags); i++ {
+			for j := i; j > 0 && m.fieldOrder(sortedTags[j], sortedTags[j-1]); j-- {
+				sortedTags[j], sortedTags[j-1] = sortedTags[j-1], sortedTags[j]
+			}
+		}
+	}
+
 	return sortedTags
 }
 
 func (m fieldMap) write(buffer *bytes.Buffer) {
-	tags := m.sortedTags()
+	for _, tag := range m.sortedTags() {
+		buffer.Write(m.fieldLookup[tag].Data)
+	}
+}
 
+//appendTo appends the fields with tags, in order, to dst.
+func (m fieldMap) appendTo(dst []byte, tags []fix.Tag) []byte {
 	for _, tag := range tags {
-		if field, ok := m.fieldLookup[tag]; ok {
-			buffer.Write(field.Data)
-		}
+		dst = append(dst, m.fieldLookup[tag].Data...)
 	}
+
+	return dst
+}
+
+//size returns the number of bytes of the fields.
+func (m fieldMap) size() int {
+	size := 0
+	for _, field := range m.fieldLookup {
+		size += len(field.Data)
+	}
+
+	return size
 }
 
 func (m fieldMap) total() int {
@@ -84,3 +84,36 @@ func TestFieldMap_Total(t *testing.T) {
 		t.Error("Total should includes all fields but checkSum- got ", fMap.total())
 	}
 }
+
+func TestFieldMap_SortedTagsReadConcurrently(t *testing.T) {
+	fMap := fieldMap{}
+	fMap.initReusable(normalFieldOrder)
+
+	for _, tag := range []fix.Tag{44, 11, 38, 1} {
+		fMap.Set(fix.NewStringField(tag, "x"))
+	}
+
+	//each read sorts the tags in a slice of its own, not in the storage reused by AppendBuild
+	first := fMap.sortedTags()
+	reused := fMap.reusedSortedTags()
+	second := fMap.sortedTags()
+	if &first[0] == &second[0] || &first[0] == &reused[0] || &second[0] == &reused[0] {
+		t.Error("Expected the tags sorted in slices of their own")
+	}
+
+	done := make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func() {
+			for j := 0; j < 100; j++ {
+				if tags := fMap.sortedTags(); len(tags) != 4 || tags[0] != 1 || tags[3] != 44 {
+					t.Errorf("Expected tags in order, got %v", tags)
+				}
+			}
+			done <- true
+		}()
+	}
+
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"github.com/quickfixgo/quickfix/config"
 	"log"
 	"os"
-	"path"
-	"strings"
 )
 
 type fileLog struct {
@@ -32,11 +30,15 @@ func (l fileLog) OnEventf(format string, v ...interface{}) {
 
 type fileLogFactory struct {
 	globalLogPath   string
+	globalRotation  fileLogRotation
 	sessionLogPaths map[SessionID]string
+	sessionRotation map[SessionID]fileLogRotation
 }
 
 //NewFileLogFactory creates an instance of LogFactory that writes messages and events to file.
 //The location of global and session log files is configured via FileLogPath.
+//Log files are rotated at the end of each UTC day with FileLogRotateDaily=Y, and before exceeding FileLogMaxSize bytes.
+//Rotated files are gzipped with FileLogCompress=Y and removed FileLogMaxAge days after their last write.
 func NewFileLogFactory(settings *Settings) (LogFactory, error) {
 	logFactory := fileLogFactory{}
 
@@ -45,7 +47,12 @@ func NewFileLogFactory(settings *Settings) (LogFactory, error) {
 		return logFactory, requiredConfigurationMissing(config.FileLogPath)
 	}
 
+	if logFactory.globalRotation, err = fileLogRotationFor(settings.GlobalSettings()); err != nil {
+		return logFactory, err
+	}
+
 	logFactory.sessionLogPaths = make(map[SessionID]string)
+	logFactory.sessionRotation = make(map[SessionID]fileLogRotation)
 
 	for sid, sessionSettings := range settings.SessionSettings() {
 		logPath, err := sessionSettings.Setting(config.FileLogPath)
@@ -53,28 +60,28 @@ func NewFileLogFactory(settings *Settings) (LogFactory, error) {
 			return logFactory, requiredConfigurationMissing(config.FileLogPath)
 		}
 		logFactory.sessionLogPaths[sid] = logPath
+
+		if logFactory.sessionRotation[sid], err = fileLogRotationFor(sessionSettings); err != nil {
+			return logFactory, err
+		}
 	}
 
 	return logFactory, nil
 }
 
-func (f fileLogFactory) buildFileLog(prefix string, logPath string) (fileLog, error) {
+func (f fileLogFactory) buildFileLog(prefix string, logPath string, rotation fileLogRotation) (fileLog, error) {
 	l := fileLog{}
 
-	eventLogName := path.Join(logPath, prefix+".event.current.log")
-	messageLogName := path.Join(logPath, prefix+".messages.current.log")
-
 	if err := os.MkdirAll(logPath, os.ModePerm); err != nil {
 		return l, err
 	}
 
-	fileFlags := os.O_RDWR | os.O_CREATE
-	eventFile, err := os.OpenFile(eventLogName, fileFlags, os.ModePerm)
+	eventFile, err := newRotatingFile(logPath, prefix+".event", rotation)
 	if err != nil {
 		return l, err
 	}
 
-	messageFile, err := os.OpenFile(messageLogName, fileFlags, os.ModePerm)
+	messageFile, err := newRotatingFile(logPath, prefix+".messages", rotation)
 	if err != nil {
 		return l, err
 	}
@@ -86,7 +93,7 @@ func (f fileLogFactory) buildFileLog(prefix string, logPath string) (fileLog, er
 }
 
 func (f fileLogFactory) Create() (Log, error) {
-	return f.buildFileLog("GLOBAL", f.globalLogPath)
+	return f.buildFileLog("GLOBAL", f.globalLogPath, f.globalRotation)
 }
 
 func (f fileLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
@@ -96,10 +103,5 @@ func (f fileLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
 		return nil, fmt.Errorf("logger not defined for %v", sessionID)
 	}
 
-	prefixParts := []string{sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID}
-	if len(sessionID.Qualifier) > 0 {
-		prefixParts = append(prefixParts, sessionID.Qualifier)
-	}
-
-	return f.buildFileLog(strings.Join(prefixParts, "-"), logPath)
+	return f.buildFileLog(sessionFilePrefix(sessionID), logPath, f.sessionRotation[sessionID])
 }
@@ -0,0 +1,235 @@
+package quickfix
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fileLogRotatedLayout = "20060102-150405.000000000"
+
+//fileLogRotation configures the rotation of the files of a file log, the zero value never rotates.
+type fileLogRotation struct {
+	//daily rotates the file at the end of each UTC day
+	daily bool
+
+	//maxSize rotates the file before it exceeds maxSize bytes, if not zero
+	maxSize int64
+
+	//maxAge removes rotated files older than maxAge, if not zero
+	maxAge time.Duration
+
+	//compress gzips rotated files
+	compress bool
+}
+
+//fileLogRotationFor parses FileLogRotateDaily, FileLogMaxSize, FileLogMaxAge and FileLogCompress.
+func fileLogRotationFor(settings *SessionSettings) (rotation fileLogRotation, err error) {
+	if settings.HasSetting(config.FileLogRotateDaily) {
+		if rotation.daily, err = settings.BoolSetting(config.FileLogRotateDaily); err != nil {
+			return
+		}
+	}
+
+	if settings.HasSetting(config.FileLogMaxSize) {
+		var maxSize int
+		if maxSize, err = settings.IntSetting(config.FileLogMaxSize); err != nil {
+			return
+		}
+
+		if maxSize < 1 {
+			return rotation, fmt.Errorf("invalid %v %v, expected at least 1", config.FileLogMaxSize, maxSize)
+		}
+		rotation.maxSize = int64(maxSize)
+	}
+
+	if settings.HasSetting(config.FileLogMaxAge) {
+		var days int
+		if days, err = settings.IntSetting(config.FileLogMaxAge); err != nil {
+			return
+		}
+
+		if days < 1 {
+			return rotation, fmt.Errorf("invalid %v %v, expected at least 1", config.FileLogMaxAge, days)
+		}
+		rotation.maxAge = time.Duration(days) * 24 * time.Hour
+	}
+
+	if settings.HasSetting(config.FileLogCompress) {
+		if rotation.compress, err = settings.BoolSetting(config.FileLogCompress); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+//rotatingFile is the file of a file log, appended to at <dir>/<name>.current.log.
+//It is renamed <dir>/<name>.<time>.log when rotated, then compressed and removed in the background according to its fileLogRotation.
+type rotatingFile struct {
+	dir, name string
+	rotation  fileLogRotation
+	now       func() time.Time
+
+	lock   sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	//pending tracks the rotated files being compressed and pruned
+	pending sync.WaitGroup
+}
+
+func newRotatingFile(dir, name string, rotation fileLogRotation) (*rotatingFile, error) {
+	f := &rotatingFile{dir: dir, name: name, rotation: rotation, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *rotatingFile) currentPath() string {
+	return path.Join(f.dir, f.name+".current.log")
+}
+
+//open opens the current file for appending, a file left by a previous process is rotated once its day or size is exceeded.
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.currentPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file, f.size, f.opened = file, info.Size(), f.now()
+	if f.size > 0 {
+		f.opened = info.ModTime()
+	}
+
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.expired(len(p)) {
+		//the current file is kept if it cannot be rotated
+		f.rotate()
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+//expired returns true if the current file is to be rotated before writing n bytes.
+func (f *rotatingFile) expired(n int) bool {
+	if f.size == 0 {
+		return false
+	}
+
+	if f.rotation.maxSize > 0 && f.size+int64(n) > f.rotation.maxSize {
+		return true
+	}
+
+	if f.rotation.daily {
+		year, month, day := f.now().UTC().Date()
+		openedYear, openedMonth, openedDay := f.opened.UTC().Date()
+		return year != openedYear || month != openedMonth || day != openedDay
+	}
+
+	return false
+}
+
+//rotate renames the current file and opens a new one, with lock held.
+func (f *rotatingFile) rotate() error {
+	rotated := path.Join(f.dir, f.name+"."+f.now().UTC().Format(fileLogRotatedLayout)+".log")
+	if err := os.Rename(f.currentPath(), rotated); err != nil {
+		return err
+	}
+
+	f.file.Close()
+	if err := f.open(); err != nil {
+		//writes fail until the next rotation succeeds
+		return err
+	}
+
+	f.pending.Add(1)
+	go func() {
+		defer f.pending.Done()
+
+		if f.rotation.compress {
+			compressFileLog(rotated)
+		}
+
+		if f.rotation.maxAge > 0 {
+			f.prune()
+		}
+	}()
+
+	return nil
+}
+
+//compressFileLog replaces the file at name with name.gz, the file is kept if it cannot be compressed.
+func compressFileLog(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(name + ".gz")
+	if err != nil {
+		return err
+	}
+
+	w := gzip.NewWriter(out)
+	if _, err = io.Copy(w, in); err == nil {
+		err = w.Close()
+	}
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+//prune removes the rotated files of the log last modified more than maxAge ago.
+func (f *rotatingFile) prune() {
+	files, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := f.now().Add(-f.rotation.maxAge)
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasPrefix(name, f.name+".") || file.ModTime().After(cutoff) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, f.name+"."), ".gz"), ".log")
+		if _, err := time.Parse(fileLogRotatedLayout, stamp); err == nil {
+			os.Remove(path.Join(f.dir, name))
+		}
+	}
+}
@@ -1,8 +1,14 @@
 package quickfix
 
 import (
+	"compress/gzip"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"os"
+	"path"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestFileLog_NewFileLogFactory(t *testing.T) {
@@ -45,3 +51,81 @@ SessionQualifier=BS
 		t.Error("Should have returned factory")
 	}
 }
+
+func TestFileLog_RotationSettings(t *testing.T) {
+	settings := NewSessionSettings()
+	if rotation, err := fileLogRotationFor(settings); err != nil || rotation != (fileLogRotation{}) {
+		t.Errorf("Expected no rotation by default, got %+v %v", rotation, err)
+	}
+
+	settings.Set(config.FileLogRotateDaily, "Y")
+	settings.Set(config.FileLogMaxSize, "1048576")
+	settings.Set(config.FileLogMaxAge, "7")
+	settings.Set(config.FileLogCompress, "Y")
+	rotation, err := fileLogRotationFor(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fileLogRotation{daily: true, maxSize: 1 << 20, maxAge: 7 * 24 * time.Hour, compress: true}
+	if rotation != expected {
+		t.Errorf("Expected %+v got %+v", expected, rotation)
+	}
+
+	settings.Set(config.FileLogMaxAge, "0")
+	if _, err := fileLogRotationFor(settings); err == nil {
+		t.Error("Expected error for FileLogMaxAge 0")
+	}
+}
+
+func TestFileLog_Rotation(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "filelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	now := time.Date(2016, 1, 1, 23, 0, 0, 0, time.UTC)
+	stale := path.Join(dirname, "GLOBAL.messages.20151201-000000.000000000.log.gz")
+	ioutil.WriteFile(stale, []byte("stale"), 0644)
+	os.Chtimes(stale, now.AddDate(0, -1, 0), now.AddDate(0, -1, 0))
+
+	f, err := newRotatingFile(dirname, "GLOBAL.messages", fileLogRotation{daily: true, maxSize: 10, maxAge: 24 * time.Hour, compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.now = func() time.Time { return now }
+
+	f.Write([]byte("hello\n"))
+	f.Write([]byte("cruel\n"))
+	now = now.Add(2 * time.Hour)
+	f.Write([]byte("world\n"))
+	f.pending.Wait()
+
+	if current, _ := ioutil.ReadFile(f.currentPath()); string(current) != "world\n" {
+		t.Errorf("Expected current file with message after day rotation, got %q", current)
+	}
+
+	files, _ := ioutil.ReadDir(dirname)
+	var rotated []string
+	for _, file := range files {
+		if file.Name() != "GLOBAL.messages.current.log" {
+			rotated = append(rotated, file.Name())
+		}
+	}
+
+	if len(rotated) != 2 || !strings.HasSuffix(rotated[0], ".log.gz") || rotated[1] != "GLOBAL.messages.20160102-010000.000000000.log.gz" {
+		t.Fatalf("Expected 2 compressed rotated files and the stale file removed, got %v", rotated)
+	}
+
+	compressed, _ := os.Open(path.Join(dirname, rotated[0]))
+	defer compressed.Close()
+	reader, err := gzip.NewReader(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, _ := ioutil.ReadAll(reader); string(content) != "hello\n" {
+		t.Errorf("Expected first message rotated by size, got %q", content)
+	}
+}
@@ -0,0 +1,687 @@
+package quickfix
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//fileStoreSeqNumsFormat is the fixed width record of the seqnums file, rewritten in place.
+	//Sender and target sequence numbers are followed by the RecoveryState so both are written together.
+	fileStoreSeqNumsFormat = "%010d : %010d : %d : %010d : %010d\n"
+
+	fileStoreCreationTimeFormat = "20060102-15:04:05.000000000"
+)
+
+//msgDef locates a message in the body file.
+type msgDef struct {
+	offset int64
+	size   int
+}
+
+//fileStore is a MessageStore writing messages to an append-only body file, indexed by a header file.
+//The header file records the seqnum, offset, and size of each message, and is loaded on startup for constant time retrieval of messages to resend.
+type fileStore struct {
+	//seqNumLock guards the sequence numbers read outside the session goroutine
+	seqNumLock                       sync.RWMutex
+	senderMsgSeqNum, targetMsgSeqNum int
+	recoveryState                    RecoveryState
+
+	//fileLock guards the files and index, read by GetMessages outside the session goroutine
+	fileLock     sync.Mutex
+	offsets      map[int]msgDef
+	bodySize     int64
+	creationTime time.Time
+
+	bodyFname, headerFname, seqNumsFname, sessionFname string
+	bodyFile, headerFile, seqNumsFile                  *os.File
+
+	//received are the messages received, in body and header files of their own created by the first SaveReceivedMessage
+	receivedOffsets                        map[int]msgDef
+	receivedSize                           int64
+	receivedBodyFname, receivedHeaderFname string
+	receivedBodyFile, receivedHeaderFile   *os.File
+
+	//sync fsyncs every write before returning
+	sync bool
+
+	//writeErr fails the writes that follow the first failed write or sync of the files, until Refresh reopens them
+	writeErr ErrorLatch
+}
+
+type fileStoreFactory struct {
+	settings *Settings
+}
+
+//NewFileStoreFactory returns a MessageStoreFactory that creates MessageStores persisting messages and sequence numbers to file.
+//The directory of each session's files is configured via FileStorePath, FileStoreSync=Y syncs every write to disk.
+func NewFileStoreFactory(settings *Settings) (MessageStoreFactory, error) {
+	for _, sessionSettings := range settings.SessionSettings() {
+		if !sessionSettings.HasSetting(config.FileStorePath) {
+			return nil, requiredConfigurationMissing(config.FileStorePath)
+		}
+	}
+
+	return fileStoreFactory{settings: settings}, nil
+}
+
+func (f fileStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
+	if _, ok := f.settings.sessionSettings[sessionID]; !ok {
+		return nil, fmt.Errorf("file store not defined for %v", sessionID)
+	}
+
+	settings := f.settings.sessionSettingsFor(sessionID)
+	dirname, err := settings.Setting(config.FileStorePath)
+	if err != nil {
+		return nil, requiredConfigurationMissing(config.FileStorePath)
+	}
+
+	syncWrites := false
+	if settings.HasSetting(config.FileStoreSync) {
+		if syncWrites, err = settings.BoolSetting(config.FileStoreSync); err != nil {
+			return nil, err
+		}
+	}
+
+	return newFileStore(sessionID, dirname, syncWrites)
+}
+
+func newFileStore(sessionID SessionID, dirname string, syncWrites bool) (*fileStore, error) {
+	if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	prefix := path.Join(dirname, sessionFilePrefix(sessionID))
+	store := &fileStore{
+		bodyFname:    prefix + ".body",
+		headerFname:  prefix + ".header",
+		seqNumsFname: prefix + ".seqnums",
+		sessionFname: prefix + ".session",
+		sync:         syncWrites,
+
+		receivedBodyFname:   prefix + ".received.body",
+		receivedHeaderFname: prefix + ".received.header",
+	}
+
+	if err := store.open(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+//sessionFilePrefix is the prefix of the files of sessionID, BeginString-SenderCompID-TargetCompID[-Qualifier].
+func sessionFilePrefix(sessionID SessionID) string {
+	prefixParts := []string{sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID}
+	if len(sessionID.Qualifier) > 0 {
+		prefixParts = append(prefixParts, sessionID.Qualifier)
+	}
+
+	return strings.Join(prefixParts, "-")
+}
+
+//open opens the files of the store, creating them if they do not exist, and loads the store from them.
+func (store *fileStore) open() (err error) {
+	if err := store.recoverCompaction(); err != nil {
+		return err
+	}
+
+	fileFlags := os.O_RDWR | os.O_CREATE
+	if store.bodyFile, err = os.OpenFile(store.bodyFname, fileFlags, os.ModePerm); err != nil {
+		return err
+	}
+
+	if store.headerFile, err = os.OpenFile(store.headerFname, fileFlags, os.ModePerm); err != nil {
+		return err
+	}
+
+	if store.seqNumsFile, err = os.OpenFile(store.seqNumsFname, fileFlags, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := store.loadCreationTime(); err != nil {
+		return err
+	}
+
+	if err := store.loadSeqNums(); err != nil {
+		return err
+	}
+
+	if err := store.loadIndex(); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(store.receivedBodyFname); os.IsNotExist(err) {
+		store.receivedOffsets, store.receivedSize = make(map[int]msgDef), 0
+		return nil
+	}
+
+	return store.openReceived()
+}
+
+//openReceived opens the files of the messages received, creating them if they do not exist, and loads their index.
+func (store *fileStore) openReceived() (err error) {
+	fileFlags := os.O_RDWR | os.O_CREATE
+	if store.receivedBodyFile, err = os.OpenFile(store.receivedBodyFname, fileFlags, os.ModePerm); err != nil {
+		return err
+	}
+
+	if store.receivedHeaderFile, err = os.OpenFile(store.receivedHeaderFname, fileFlags, os.ModePerm); err != nil {
+		return err
+	}
+
+	store.receivedOffsets, store.receivedSize, err = loadMessageIndex(store.receivedBodyFile, store.receivedHeaderFile)
+	return err
+}
+
+//close closes the files of the store.
+func (store *fileStore) close() error {
+	var firstErr error
+	for _, file := range []*os.File{store.bodyFile, store.headerFile, store.seqNumsFile, store.receivedBodyFile, store.receivedHeaderFile} {
+		if file == nil {
+			continue
+		}
+
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	store.bodyFile, store.headerFile, store.seqNumsFile = nil, nil, nil
+	store.receivedBodyFile, store.receivedHeaderFile = nil, nil
+	return firstErr
+}
+
+//loadCreationTime reads the session file, recording a new creation time if the store is new.
+func (store *fileStore) loadCreationTime() error {
+	data, err := ioutil.ReadFile(store.sessionFname)
+	if os.IsNotExist(err) {
+		return store.saveCreationTime(time.Now())
+	}
+	if err != nil {
+		return err
+	}
+
+	if store.creationTime, err = time.Parse(fileStoreCreationTimeFormat, strings.TrimSpace(string(data))); err != nil {
+		return fmt.Errorf("invalid session file %v: %v", store.sessionFname, err)
+	}
+
+	return nil
+}
+
+//saveCreationTime replaces the session file, by rename, with creationTime.
+func (store *fileStore) saveCreationTime(creationTime time.Time) error {
+	tmpFname := store.sessionFname + ".tmp"
+	if err := ioutil.WriteFile(tmpFname, []byte(creationTime.UTC().Format(fileStoreCreationTimeFormat)), os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpFname, store.sessionFname); err != nil {
+		return err
+	}
+
+	store.creationTime = creationTime.UTC()
+	return nil
+}
+
+//loadSeqNums reads the sequence numbers and RecoveryState from the seqnums file, empty for a new store.
+func (store *fileStore) loadSeqNums() error {
+	data, err := ioutil.ReadAll(io.NewSectionReader(store.seqNumsFile, 0, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	var loggedOn int
+	state := &store.recoveryState
+	if _, err := fmt.Sscanf(string(data), fileStoreSeqNumsFormat, &store.senderMsgSeqNum, &store.targetMsgSeqNum, &loggedOn, &state.ResendBeginSeqNo, &state.ResendEndSeqNo); err != nil {
+		return fmt.Errorf("invalid seqnums file %v: %v", store.seqNumsFname, err)
+	}
+	state.LoggedOn = loggedOn == 1
+
+	return nil
+}
+
+//loadIndex reads the header file into the index of the body file.
+func (store *fileStore) loadIndex() (err error) {
+	store.offsets, store.bodySize, err = loadMessageIndex(store.bodyFile, store.headerFile)
+	return err
+}
+
+//loadMessageIndex reads the index of the messages of body from header.
+//A record partially written by a crash, or referring past the end of the body file, ends the index and both files are truncated to the last complete message.
+func loadMessageIndex(body, header *os.File) (offsets map[int]msgDef, bodySize int64, err error) {
+	bodyInfo, err := body.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	offsets = make(map[int]msgDef)
+
+	var headerSize int64
+	reader := bufio.NewReader(io.NewSectionReader(header, 0, 1<<62))
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var seqNum int
+		var def msgDef
+		if _, err := fmt.Sscanf(line, "%d,%d,%d\n", &seqNum, &def.offset, &def.size); err != nil {
+			break
+		}
+
+		end := def.offset + int64(def.size)
+		if end > bodyInfo.Size() {
+			break
+		}
+
+		offsets[seqNum] = def
+		bodySize = end
+		headerSize += int64(len(line))
+	}
+
+	if err := header.Truncate(headerSize); err != nil {
+		return nil, 0, err
+	}
+	if _, err := header.Seek(headerSize, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+
+	if err := body.Truncate(bodySize); err != nil {
+		return nil, 0, err
+	}
+	_, err = body.Seek(bodySize, io.SeekStart)
+	return offsets, bodySize, err
+}
+
+//saveSeqNums rewrites the seqnums file with the sequence numbers and RecoveryState, with seqNumLock held.
+func (store *fileStore) saveSeqNums() {
+	loggedOn := 0
+	if store.recoveryState.LoggedOn {
+		loggedOn = 1
+	}
+
+	record := fmt.Sprintf(fileStoreSeqNumsFormat, store.senderMsgSeqNum, store.targetMsgSeqNum, loggedOn, store.recoveryState.ResendBeginSeqNo, store.recoveryState.ResendEndSeqNo)
+	if _, err := store.seqNumsFile.WriteAt([]byte(record), 0); err != nil {
+		store.writeErr.Set(err)
+		return
+	}
+
+	if store.sync {
+		store.writeErr.Set(store.seqNumsFile.Sync())
+	}
+}
+
+func (store *fileStore) NextSenderMsgSeqNum() int {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
+	return store.senderMsgSeqNum + 1
+}
+
+func (store *fileStore) NextTargetMsgSeqNum() int {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
+	return store.targetMsgSeqNum + 1
+}
+
+func (store *fileStore) IncrNextSenderMsgSeqNum() {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.senderMsgSeqNum++
+	store.saveSeqNums()
+}
+
+func (store *fileStore) IncrNextTargetMsgSeqNum() {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.targetMsgSeqNum++
+	store.saveSeqNums()
+}
+
+func (store *fileStore) SetNextSenderMsgSeqNum(nextSeqNum int) {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.senderMsgSeqNum = nextSeqNum - 1
+	store.saveSeqNums()
+}
+
+func (store *fileStore) SetNextTargetMsgSeqNum(nextSeqNum int) {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.targetMsgSeqNum = nextSeqNum - 1
+	store.saveSeqNums()
+}
+
+func (store *fileStore) CreationTime() time.Time {
+	store.fileLock.Lock()
+	defer store.fileLock.Unlock()
+	return store.creationTime
+}
+
+func (store *fileStore) SaveRecoveryState(state RecoveryState) error {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.recoveryState = state
+	store.saveSeqNums()
+	return store.writeErr.Err()
+}
+
+func (store *fileStore) RecoveryState() (RecoveryState, error) {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
+	return store.recoveryState, nil
+}
+
+//Reset discards the messages of the store and resets the sequence numbers, starting a new session.
+func (store *fileStore) Reset() {
+	store.fileLock.Lock()
+	store.writeErr.Set(store.saveCreationTime(time.Now()))
+	for _, file := range []*os.File{store.bodyFile, store.headerFile} {
+		store.writeErr.Set(file.Truncate(0))
+		_, err := file.Seek(0, io.SeekStart)
+		store.writeErr.Set(err)
+	}
+	store.offsets = make(map[int]msgDef)
+	store.bodySize = 0
+	if store.receivedBodyFile != nil {
+		for _, file := range []*os.File{store.receivedBodyFile, store.receivedHeaderFile} {
+			store.writeErr.Set(file.Truncate(0))
+			_, err := file.Seek(0, io.SeekStart)
+			store.writeErr.Set(err)
+		}
+	}
+	store.receivedOffsets = make(map[int]msgDef)
+	store.receivedSize = 0
+	store.fileLock.Unlock()
+
+	store.seqNumLock.Lock()
+	store.senderMsgSeqNum = 0
+	store.targetMsgSeqNum = 0
+	store.recoveryState.ResendBeginSeqNo, store.recoveryState.ResendEndSeqNo = 0, 0
+	store.saveSeqNums()
+	store.seqNumLock.Unlock()
+}
+
+//Refresh reloads the store from its files, clearing any write error.
+func (store *fileStore) Refresh() {
+	store.fileLock.Lock()
+	defer store.fileLock.Unlock()
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+
+	store.close()
+
+	store.writeErr.Clear()
+
+	store.writeErr.Set(store.open())
+}
+
+//TrySaveMessage appends msg to the body file and its location to the header file.
+func (store *fileStore) TrySaveMessage(seqNum int, msg []byte) error {
+	store.fileLock.Lock()
+	defer store.fileLock.Unlock()
+
+	if err := store.writeErr.Err(); err != nil {
+		return err
+	}
+
+	def, err := store.appendMessage(store.bodyFile, store.headerFile, store.bodySize, seqNum, msg)
+	if err != nil {
+		return err
+	}
+
+	store.offsets[seqNum] = def
+	store.bodySize += int64(def.size)
+	return nil
+}
+
+//SaveMessage appends msg as TrySaveMessage, a failed append failing the writes that follow until Refresh reopens the files.
+func (store *fileStore) SaveMessage(seqNum int, msg []byte) {
+	store.writeErr.Set(store.TrySaveMessage(seqNum, msg))
+}
+
+//SaveReceivedMessage appends msg to the body file of the messages received and its location to their header file.
+func (store *fileStore) SaveReceivedMessage(seqNum int, msg []byte) error {
+	store.fileLock.Lock()
+	defer store.fileLock.Unlock()
+
+	if err := store.writeErr.Err(); err != nil {
+		return err
+	}
+
+	if store.receivedBodyFile == nil {
+		if err := store.openReceived(); err != nil {
+			return err
+		}
+	}
+
+	def, err := store.appendMessage(store.receivedBodyFile, store.receivedHeaderFile, store.receivedSize, seqNum, msg)
+	if err != nil {
+		return err
+	}
+
+	store.receivedOffsets[seqNum] = def
+	store.receivedSize += int64(def.size)
+	return nil
+}
+
+//appendMessage appends msg to body at offset, and its location to header.
+func (store *fileStore) appendMessage(body, header *os.File, offset int64, seqNum int, msg []byte) (msgDef, error) {
+	if _, err := body.Write(msg); err != nil {
+		return msgDef{}, err
+	}
+
+	def := msgDef{offset: offset, size: len(msg)}
+	if err := writeMsgDef(header, seqNum, def); err != nil {
+		return msgDef{}, err
+	}
+
+	if store.sync {
+		if err := body.Sync(); err != nil {
+			return msgDef{}, err
+		}
+		if err := header.Sync(); err != nil {
+			return msgDef{}, err
+		}
+	}
+
+	return def, nil
+}
+
+//GetMessages returns the messages saved with seqnums from beginSeqNum to endSeqNum, in order.
+func (store *fileStore) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
+	return store.readMessages(beginSeqNum, endSeqNum, false)
+}
+
+//GetReceivedMessages returns the messages received saved with seqnums from beginSeqNum to endSeqNum, in order.
+func (store *fileStore) GetReceivedMessages(beginSeqNum, endSeqNum int) chan []byte {
+	return store.readMessages(beginSeqNum, endSeqNum, true)
+}
+
+func (store *fileStore) readMessages(beginSeqNum, endSeqNum int, received bool) chan []byte {
+	msgs := make(chan []byte)
+
+	go func() {
+		defer close(msgs)
+
+		for seqNum := beginSeqNum; seqNum <= endSeqNum; seqNum++ {
+			store.fileLock.Lock()
+			offsets, body := store.offsets, store.bodyFile
+			if received {
+				offsets, body = store.receivedOffsets, store.receivedBodyFile
+			}
+
+			def, ok := offsets[seqNum]
+			var msg []byte
+			if ok {
+				msg = make([]byte, def.size)
+				if _, err := body.ReadAt(msg, def.offset); err != nil {
+					ok = false
+				}
+			}
+			store.fileLock.Unlock()
+
+			if ok {
+				msgs <- msg
+			}
+		}
+	}()
+
+	return msgs
+}
+
+//Compact rewrites the body and header files without the messages with seqnums below seqNum.
+//The new files are written alongside, the rename of the new header file to the compact file commits the compaction, completed by recoverCompaction if interrupted.
+func (store *fileStore) Compact(seqNum int) error {
+	store.fileLock.Lock()
+	defer store.fileLock.Unlock()
+
+	var retained []int
+	for saved := range store.offsets {
+		if saved >= seqNum {
+			retained = append(retained, saved)
+		}
+	}
+
+	if len(retained) == len(store.offsets) {
+		return nil
+	}
+
+	sort.Slice(retained, func(i, j int) bool { return store.offsets[retained[i]].offset < store.offsets[retained[j]].offset })
+
+	if err := store.writeCompacted(retained); err != nil {
+		os.Remove(store.bodyFname + ".tmp")
+		os.Remove(store.headerFname + ".tmp")
+		return err
+	}
+
+	if err := os.Rename(store.headerFname+".tmp", store.headerFname+".compact"); err != nil {
+		return err
+	}
+
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+
+	store.close()
+	if err := store.open(); err != nil {
+		store.writeErr.Set(err)
+		return err
+	}
+
+	return nil
+}
+
+//writeCompacted writes the messages of seqNums to new body and header files, synced to disk.
+func (store *fileStore) writeCompacted(seqNums []int) error {
+	body, err := os.Create(store.bodyFname + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	header, err := os.Create(store.headerFname + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer header.Close()
+
+	writer := bufio.NewWriter(header)
+	var offset int64
+	for _, seqNum := range seqNums {
+		def := store.offsets[seqNum]
+		msg := getBuffer(def.size)[:def.size]
+		_, err := store.bodyFile.ReadAt(msg, def.offset)
+		if err == nil {
+			_, err = body.Write(msg)
+		}
+		putBuffer(msg)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(writer, "%d,%d,%d\n", seqNum, offset, def.size)
+		offset += int64(def.size)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	if err := body.Sync(); err != nil {
+		return err
+	}
+
+	return header.Sync()
+}
+
+//recoverCompaction completes a compaction interrupted after it was committed, or discards the files of one interrupted before.
+func (store *fileStore) recoverCompaction() error {
+	compactFname, bodyTmpFname := store.headerFname+".compact", store.bodyFname+".tmp"
+
+	if _, err := os.Stat(compactFname); os.IsNotExist(err) {
+		os.Remove(bodyTmpFname)
+		os.Remove(store.headerFname + ".tmp")
+		return nil
+	}
+
+	if _, err := os.Stat(bodyTmpFname); err == nil {
+		if err := os.Rename(bodyTmpFname, store.bodyFname); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(compactFname, store.headerFname)
+}
+
+//Health returns the first write error since the store was refreshed.
+func (store *fileStore) Health() error {
+	return store.writeErr.Err()
+}
+
+//Flush syncs the files of the store to disk.
+func (store *fileStore) Flush() error {
+	store.fileLock.Lock()
+	defer store.fileLock.Unlock()
+
+	for _, file := range []*os.File{store.bodyFile, store.headerFile, store.seqNumsFile} {
+		if err := file.Sync(); err != nil {
+			return err
+		}
+	}
+
+	return store.writeErr.Err()
+}
+
+//writeMsgDef writes the header line of def, the location of the message with seqNum, formatted in a buffer of the pool.
+func writeMsgDef(header io.Writer, seqNum int, def msgDef) error {
+	line := getBuffer(64)
+	line = strconv.AppendInt(line, int64(seqNum), 10)
+	line = append(line, ',')
+	line = strconv.AppendInt(line, def.offset, 10)
+	line = append(line, ',')
+	line = strconv.AppendInt(line, int64(def.size), 10)
+	line = append(line, '\n')
+
+	_, err := header.Write(line)
+	putBuffer(line)
+	return err
+}
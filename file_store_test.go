@@ -0,0 +1,165 @@
+package quickfix
+
+import (
+	"bytes"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newTestFileStore(t *testing.T, dirname string) *fileStore {
+	store, err := newFileStore(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}, dirname, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func collectMessages(store MessageStore, beginSeqNum, endSeqNum int) (msgs []string) {
+	for msg := range store.GetMessages(beginSeqNum, endSeqNum) {
+		msgs = append(msgs, string(msg))
+	}
+
+	return
+}
+
+func TestFileStore_NewFileStoreFactory(t *testing.T) {
+	cfg := `
+[DEFAULT]
+SenderCompID=TW
+FileStorePath=store
+
+[SESSION]
+BeginString=FIX.4.1
+TargetCompID=ARCA
+`
+	settings, _ := ParseSettings(strings.NewReader(cfg))
+	if _, err := NewFileStoreFactory(settings); err != nil {
+		t.Error("Did not expect error", err)
+	}
+
+	delete(settings.GlobalSettings().settings, config.FileStorePath)
+	if _, err := NewFileStoreFactory(settings); err == nil {
+		t.Error("Should expect error when settings have no file store path")
+	}
+}
+
+func TestFileStore_Persistence(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "filestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	store := newTestFileStore(t, dirname)
+	store.SetNextSenderMsgSeqNum(3)
+	store.IncrNextTargetMsgSeqNum()
+	store.SaveRecoveryState(RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2, ResendEndSeqNo: 5})
+	for seqNum, msg := range []string{"hello", "cruel", "world"} {
+		if err := saveMessage(store, seqNum+1, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	creationTime := store.CreationTime()
+	store.close()
+
+	store = newTestFileStore(t, dirname)
+	defer store.close()
+
+	if store.NextSenderMsgSeqNum() != 3 || store.NextTargetMsgSeqNum() != 2 {
+		t.Errorf("Expected seqnums 3 and 2 got %v and %v", store.NextSenderMsgSeqNum(), store.NextTargetMsgSeqNum())
+	}
+
+	if state, _ := store.RecoveryState(); state != (RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2, ResendEndSeqNo: 5}) {
+		t.Errorf("Unexpected recovery state %+v", state)
+	}
+
+	if !store.CreationTime().Equal(creationTime) {
+		t.Errorf("Expected creation time %v got %v", creationTime, store.CreationTime())
+	}
+
+	if msgs := collectMessages(store, 2, 3); len(msgs) != 2 || msgs[0] != "cruel" || msgs[1] != "world" {
+		t.Errorf("Unexpected messages %v", msgs)
+	}
+
+	store.Reset()
+	if store.NextSenderMsgSeqNum() != 1 || store.NextTargetMsgSeqNum() != 1 || len(collectMessages(store, 1, 3)) != 0 {
+		t.Error("Expected empty store after reset")
+	}
+}
+
+func TestFileStore_PartialWrite(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "filestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	store := newTestFileStore(t, dirname)
+	store.SaveMessage(1, []byte("hello"))
+	store.SaveMessage(2, []byte("world"))
+
+	//crash after writing the body, before the header of message 3 was complete
+	store.bodyFile.Write([]byte("lost"))
+	store.headerFile.Write([]byte("3,10,"))
+	store.close()
+
+	store = newTestFileStore(t, dirname)
+	defer store.close()
+
+	if msgs := collectMessages(store, 1, 3); len(msgs) != 2 {
+		t.Errorf("Expected 2 complete messages got %v", msgs)
+	}
+
+	if err := saveMessage(store, 3, []byte("again")); err != nil {
+		t.Fatal(err)
+	}
+
+	if msgs := collectMessages(store, 3, 3); len(msgs) != 1 || msgs[0] != "again" {
+		t.Errorf("Unexpected messages %v", msgs)
+	}
+
+	body, _ := ioutil.ReadFile(store.bodyFname)
+	if !bytes.Equal(body, []byte("helloworldagain")) {
+		t.Errorf("Expected truncated body to be appended to, got %q", body)
+	}
+}
+
+func TestFileStore_ReceivedMessages(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "filestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	store := newTestFileStore(t, dirname)
+	for seqNum, msg := range []string{"hello", "cruel", "world"} {
+		if err := store.SaveReceivedMessage(seqNum+1, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.close()
+
+	store = newTestFileStore(t, dirname)
+	defer store.close()
+
+	var msgs []string
+	for msg := range store.GetReceivedMessages(2, 5) {
+		msgs = append(msgs, string(msg))
+	}
+	if len(msgs) != 2 || msgs[0] != "cruel" || msgs[1] != "world" {
+		t.Errorf("Unexpected messages received %v", msgs)
+	}
+
+	if len(collectMessages(store, 1, 3)) != 0 {
+		t.Error("Expected messages received kept apart from messages sent")
+	}
+
+	store.Reset()
+	for range store.GetReceivedMessages(1, 3) {
+		t.Error("Expected no messages received after reset")
+	}
+}
@@ -30,6 +30,15 @@ func (f BooleanValue) Write() []byte {
 	return []byte("N")
 }
 
+//AppendValue appends the value to dst, without allocating if dst has room.
+func (f BooleanValue) AppendValue(dst []byte) []byte {
+	if f.Value {
+		return append(dst, 'Y')
+	}
+
+	return append(dst, 'N')
+}
+
 //BooleanField is a generic boolean Field Type, Implements Field.
 type BooleanField struct {
 	tagContainer
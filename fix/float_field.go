@@ -2,7 +2,6 @@ package fix
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
 )
 
@@ -17,15 +16,22 @@ func (f *FloatValue) Read(bytes []byte) (err error) {
 	}
 
 	//strconv allows values like "+100.00", which is not allowed for FIX float types
-	if valid, _ := regexp.MatchString("^[0-9.-]+$", string(bytes)); !valid {
-		return fmt.Errorf("invalid value %v", string(bytes))
+	for _, b := range bytes {
+		if (b < '0' || b > '9') && b != '.' && b != '-' {
+			return fmt.Errorf("invalid value %v", string(bytes))
+		}
 	}
 
 	return
 }
 
 func (f FloatValue) Write() []byte {
-	return []byte(strconv.FormatFloat(f.Value, 'f', -1, 64))
+	return f.AppendValue(nil)
+}
+
+//AppendValue appends the value to dst, without allocating if dst has room.
+func (f FloatValue) AppendValue(dst []byte) []byte {
+	return strconv.AppendFloat(dst, f.Value, 'f', -1, 64)
 }
 
 type FloatField struct {
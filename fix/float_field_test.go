@@ -31,4 +31,13 @@ func (s *FloatFieldTests) TestRead(c *C) {
 
 	err = field.Read([]byte("+200.00"))
 	c.Check(err, NotNil)
+
+	err = field.Read([]byte("-200.25"))
+	c.Check(err, IsNil)
+	c.Check(field.Value, Equals, -200.25)
+}
+
+func (s *FloatFieldTests) TestAppendValue(c *C) {
+	bytes := FloatValue{Value: 12.25}.AppendValue([]byte("44="))
+	c.Check(string(bytes), Equals, "44=12.25")
 }
@@ -55,7 +55,12 @@ func (f *IntValue) Read(bytes []byte) (err error) {
 }
 
 func (f IntValue) Write() []byte {
-	return []byte(strconv.Itoa(f.Value))
+	return f.AppendValue(nil)
+}
+
+//AppendValue appends the value to dst, without allocating if dst has room.
+func (f IntValue) AppendValue(dst []byte) []byte {
+	return strconv.AppendInt(dst, int64(f.Value), 10)
 }
 
 //IntField is a generic int Field Type, implements Field
@@ -51,3 +51,16 @@ func BenchmarkIntField_Read(b *testing.B) {
 		field.Read(intBytes)
 	}
 }
+
+func TestIntValue_AppendValue(t *testing.T) {
+	buf := make([]byte, 0, 16)
+	buf = append(buf, "34="...)
+
+	if buf = (IntValue{Value: -1500}).AppendValue(buf); string(buf) != "34=-1500" {
+		t.Error("Unexpected bytes ", string(buf))
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { IntValue{Value: 1500}.AppendValue(buf[:0]) }); allocs != 0 {
+		t.Error("Unexpected allocations ", allocs)
+	}
+}
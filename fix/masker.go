@@ -0,0 +1,176 @@
+package fix
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	tagBeginString Tag = 8
+	tagBodyLength  Tag = 9
+	tagCheckSum    Tag = 10
+)
+
+//RedactedValue replaces the value of fields redacted by a Masker.
+const RedactedValue = "***"
+
+//CredentialTags are the tags carrying passwords on Logon and UserRequest messages: Password, NewPassword, EncryptedPassword, and EncryptedNewPassword.
+var CredentialTags = []Tag{554, 925, 1402, 1404}
+
+//AccountTags are the tags carrying account identifiers: Account, AllocAccount, ClearingAccount, and LegAllocAccount.
+var AccountTags = []Tag{1, 79, 440, 671}
+
+//dataLengthTags maps the data fields that may contain the SOH delimiter to the field holding their length.
+var dataLengthTags = map[Tag]Tag{
+	89:   93,   //Signature, SignatureLength
+	91:   90,   //SecureData, SecureDataLen
+	96:   95,   //RawData, RawDataLength
+	213:  212,  //XmlData, XmlDataLen
+	1402: 1401, //EncryptedPassword, EncryptedPasswordLen
+	1404: 1403, //EncryptedNewPassword, EncryptedNewPasswordLen
+}
+
+type maskAction int
+
+const (
+	maskRedact maskAction = iota + 1
+	maskTokenize
+)
+
+//Masker produces copies of raw FIX messages with the values of selected tags redacted or tokenized.
+//The BodyLength, CheckSum, and length fields of masked data fields are recomputed so the result remains a valid message.
+type Masker struct {
+	actions map[Tag]maskAction
+	key     []byte
+}
+
+//NewMasker returns a Masker with no tags configured. key is used to derive tokens for tokenized tags,
+//the same value is always replaced with the same token for a given key.
+func NewMasker(key []byte) *Masker {
+	return &Masker{actions: make(map[Tag]maskAction), key: key}
+}
+
+//Redact configures the Masker to replace the values of tags with RedactedValue.
+func (m *Masker) Redact(tags ...Tag) *Masker {
+	for _, t := range tags {
+		m.actions[t] = maskRedact
+	}
+
+	return m
+}
+
+//Tokenize configures the Masker to replace the values of tags with a token derived from the value.
+//Tokens allow masked messages to be correlated, e.g. orders for the same account, without revealing the value.
+func (m *Masker) Tokenize(tags ...Tag) *Masker {
+	for _, t := range tags {
+		m.actions[t] = maskTokenize
+	}
+
+	return m
+}
+
+type rawField struct {
+	tag   Tag
+	value []byte
+}
+
+//Mask returns a copy of the raw message msg with the configured tags masked. msg is not modified.
+func (m *Masker) Mask(msg []byte) ([]byte, error) {
+	fields, err := splitRawFields(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) < 3 || fields[0].tag != tagBeginString || fields[1].tag != tagBodyLength || fields[len(fields)-1].tag != tagCheckSum {
+		return nil, errors.New("Masker: message must begin with BeginString and BodyLength and end with CheckSum")
+	}
+
+	body := fields[2 : len(fields)-1]
+	for i, f := range body {
+		switch m.actions[f.tag] {
+		case maskRedact:
+			body[i].value = []byte(RedactedValue)
+		case maskTokenize:
+			body[i].value = m.token(f.value)
+		default:
+			continue
+		}
+
+		if lengthTag, ok := dataLengthTags[f.tag]; ok && i > 0 && body[i-1].tag == lengthTag {
+			body[i-1].value = []byte(strconv.Itoa(len(body[i].value)))
+		}
+	}
+
+	var bodyBytes bytes.Buffer
+	for _, f := range body {
+		writeRawField(&bodyBytes, f.tag, f.value)
+	}
+
+	var out bytes.Buffer
+	writeRawField(&out, tagBeginString, fields[0].value)
+	writeRawField(&out, tagBodyLength, []byte(strconv.Itoa(bodyBytes.Len())))
+	out.Write(bodyBytes.Bytes())
+
+	checkSum := 0
+	for _, b := range out.Bytes() {
+		checkSum += int(b)
+	}
+	writeRawField(&out, tagCheckSum, []byte(fmt.Sprintf("%03d", checkSum%256)))
+
+	return out.Bytes(), nil
+}
+
+//token derives the replacement for a tokenized value.
+func (m *Masker) token(value []byte) []byte {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write(value)
+	return []byte("TOK" + hex.EncodeToString(mac.Sum(nil)[:8]))
+}
+
+//splitRawFields splits a raw message into its fields, honoring the length of data fields that may contain the SOH delimiter.
+func splitRawFields(msg []byte) ([]rawField, error) {
+	var fields []rawField
+
+	for len(msg) > 0 {
+		eq := bytes.IndexByte(msg, '=')
+		if eq == -1 {
+			return nil, errors.New("Masker: field missing '='")
+		}
+
+		t, err := ParseUInt(msg[:eq])
+		if err != nil {
+			return nil, fmt.Errorf("Masker: invalid tag %q", msg[:eq])
+		}
+		msg = msg[eq+1:]
+
+		end := -1
+		if lengthTag, ok := dataLengthTags[Tag(t)]; ok && len(fields) > 0 && fields[len(fields)-1].tag == lengthTag {
+			if length, err := ParseUInt(fields[len(fields)-1].value); err == nil && length < len(msg) && msg[length] == '\001' {
+				end = length
+			}
+		}
+
+		if end == -1 {
+			if end = bytes.IndexByte(msg, '\001'); end == -1 {
+				return nil, fmt.Errorf("Masker: field %d not terminated", t)
+			}
+		}
+
+		fields = append(fields, rawField{tag: Tag(t), value: msg[:end]})
+		msg = msg[end+1:]
+	}
+
+	return fields, nil
+}
+
+func writeRawField(b *bytes.Buffer, t Tag, value []byte) {
+	b.WriteString(strconv.Itoa(int(t)))
+	b.WriteByte('=')
+	b.Write(value)
+	b.WriteByte('\001')
+}
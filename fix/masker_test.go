@@ -0,0 +1,73 @@
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func frame(body string) []byte {
+	msg := fmt.Sprintf("8=FIX.4.4\0019=%d\001%v", len(body), body)
+	checkSum := 0
+	for _, b := range []byte(msg) {
+		checkSum += int(b)
+	}
+
+	return []byte(fmt.Sprintf("%v10=%03d\001", msg, checkSum%256))
+}
+
+func TestMasker_Mask(t *testing.T) {
+	masker := NewMasker([]byte("key")).Redact(CredentialTags...).Tokenize(AccountTags...)
+
+	logon := frame("35=A\00134=1\00149=TW\00156=ISLD\001553=user\001554=secret\001925=newsecret\001")
+	masked, err := masker.Mask(logon)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := frame("35=A\00134=1\00149=TW\00156=ISLD\001553=user\001554=***\001925=***\001")
+	if !bytes.Equal(masked, expected) {
+		t.Errorf("expected %q got %q", expected, masked)
+	}
+
+	if !bytes.Contains(logon, []byte("554=secret")) {
+		t.Error("original message should not be modified")
+	}
+
+	order1, _ := masker.Mask(frame("35=D\00134=2\00149=TW\00156=ISLD\0011=ACCT1\00111=ID1\001"))
+	order2, _ := masker.Mask(frame("35=D\00134=3\00149=TW\00156=ISLD\0011=ACCT1\00111=ID2\001"))
+	token1 := bytes.SplitN(bytes.SplitN(order1, []byte("\0011="), 2)[1], []byte("\001"), 2)[0]
+	token2 := bytes.SplitN(bytes.SplitN(order2, []byte("\0011="), 2)[1], []byte("\001"), 2)[0]
+
+	if bytes.Contains(order1, []byte("ACCT1")) {
+		t.Error("expected account to be tokenized", string(order1))
+	}
+
+	if !bytes.Equal(token1, token2) {
+		t.Errorf("expected the same token for the same account, got %s and %s", token1, token2)
+	}
+}
+
+func TestMasker_MaskDataField(t *testing.T) {
+	masker := NewMasker(nil).Redact(1402)
+
+	masked, err := masker.Mask(frame("35=A\00134=1\00149=TW\00156=ISLD\0011401=7\0011402=ab\001cdef\001"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := frame("35=A\00134=1\00149=TW\00156=ISLD\0011401=3\0011402=***\001")
+	if !bytes.Equal(masked, expected) {
+		t.Errorf("expected %q got %q", expected, masked)
+	}
+}
+
+func TestMasker_MaskInvalidMessage(t *testing.T) {
+	masker := NewMasker(nil).Redact(CredentialTags...)
+
+	for _, msg := range []string{"35=A\001554=secret\001", "8=FIX.4.4\0019=5\00135=A\001", "8=FIX.4.4\0019=5\00135=A"} {
+		if _, err := masker.Mask([]byte(msg)); err == nil {
+			t.Errorf("expected error masking %q", msg)
+		}
+	}
+}
@@ -14,6 +14,11 @@ func (f StringValue) Write() []byte {
 	return []byte(f.Value)
 }
 
+//AppendValue appends the value to dst, without allocating if dst has room.
+func (f StringValue) AppendValue(dst []byte) []byte {
+	return append(dst, f.Value...)
+}
+
 //StringField is a generic string Field Type. Implements Field.
 type StringField struct {
 	tagContainer
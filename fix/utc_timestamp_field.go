@@ -28,11 +28,55 @@ func (f *UTCTimestampValue) Read(bytes []byte) (err error) {
 }
 
 func (f UTCTimestampValue) Write() []byte {
+	return f.AppendValue(make([]byte, 0, len(utcTimestampFormat)))
+}
+
+//AppendValue appends the value to dst, without allocating if dst has room.
+func (f UTCTimestampValue) AppendValue(dst []byte) []byte {
+	t := f.Value.UTC()
+
+	//the digits are appended directly, rather than by interpreting the layout, as SendingTime is formatted for every message sent
+	year, month, day := t.Date()
+	if year < 0 || year > 9999 {
+		if f.NoMillis {
+			return t.AppendFormat(dst, utcTimestampNoMillisFormat)
+		}
+
+		return t.AppendFormat(dst, utcTimestampFormat)
+	}
+
+	hour, min, sec := t.Clock()
+	dst = appendDigits(dst, year, 4)
+	dst = appendDigits(dst, int(month), 2)
+	dst = appendDigits(dst, day, 2)
+	dst = append(dst, '-')
+	dst = appendDigits(dst, hour, 2)
+	dst = append(dst, ':')
+	dst = appendDigits(dst, min, 2)
+	dst = append(dst, ':')
+	dst = appendDigits(dst, sec, 2)
+
 	if f.NoMillis {
-		return []byte(f.Value.UTC().Format(utcTimestampNoMillisFormat))
+		return dst
+	}
+
+	dst = append(dst, '.')
+	return appendDigits(dst, t.Nanosecond()/int(time.Millisecond), 3)
+}
+
+//appendDigits appends the width least significant decimal digits of v, not negative, to dst.
+func appendDigits(dst []byte, v, width int) []byte {
+	start := len(dst)
+	for i := 0; i < width; i++ {
+		dst = append(dst, '0')
+	}
+
+	for i := len(dst) - 1; i >= start; i-- {
+		dst[i] = byte('0' + v%10)
+		v /= 10
 	}
 
-	return []byte(f.Value.UTC().Format(utcTimestampFormat))
+	return dst
 }
 
 //UTCTimestampField is a generic utctimestamp Field Type. Implements Field
@@ -0,0 +1,52 @@
+package fix
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUTCTimestampValue_AppendValue(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		newYork = time.FixedZone("EST", -5*60*60)
+	}
+
+	var testCases = []time.Time{
+		time.Date(2016, time.August, 3, 12, 30, 15, 123456789, time.UTC),
+		time.Date(2016, time.December, 31, 23, 59, 59, 999999999, time.UTC),
+		time.Date(1999, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(16, time.February, 9, 4, 5, 6, 7000000, time.UTC),
+		time.Date(2016, time.August, 3, 21, 30, 15, 1000000, newYork),
+		time.Date(12016, time.August, 3, 12, 30, 15, 0, time.UTC),
+	}
+
+	for _, value := range testCases {
+		for _, noMillis := range []bool{false, true} {
+			layout := "20060102-15:04:05.000"
+			if noMillis {
+				layout = "20060102-15:04:05"
+			}
+
+			expected := value.UTC().Format(layout)
+			if actual := string(UTCTimestampValue{Value: value, NoMillis: noMillis}.AppendValue([]byte("52="))); actual != "52="+expected {
+				t.Errorf("Expected 52=%v, got %v", expected, actual)
+			}
+		}
+	}
+
+	value := UTCTimestampValue{Value: time.Now()}
+	buf := make([]byte, 0, 32)
+	if allocs := testing.AllocsPerRun(100, func() { buf = value.AppendValue(buf[:0]) }); allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+func BenchmarkUTCTimestampValue_AppendValue(b *testing.B) {
+	value := UTCTimestampValue{Value: time.Date(2016, time.August, 3, 12, 30, 15, 123456789, time.UTC)}
+	buf := make([]byte, 0, 32)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = value.AppendValue(buf[:0])
+	}
+}
@@ -1808,7 +1808,7 @@ func Builder(
 	var builder MessageBuilder
 	builder.MessageBuilder = quickfix.NewMessageBuilder()
 	builder.Header().Set(field.NewBeginString(fix.BeginString_FIXT11))
-	builder.Header().Set(field.NewDefaultApplVerID(enum.ApplVerID_FIX50))
+	builder.Header().Set(field.NewApplVerID(enum.ApplVerID_FIX50))
 	builder.Header().Set(field.NewMsgType("J"))
 	builder.Body().Set(allocid)
 	builder.Body().Set(alloctranstype)
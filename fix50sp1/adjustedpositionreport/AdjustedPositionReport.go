@@ -148,7 +148,7 @@ func Builder(
 	var builder MessageBuilder
 	builder.MessageBuilder = quickfix.NewMessageBuilder()
 	builder.Header().Set(field.NewBeginString(fix.BeginString_FIXT11))
-	builder.Header().Set(field.NewDefaultApplVerID(enum.ApplVerID_FIX50SP1))
+	builder.Header().Set(field.NewApplVerID(enum.ApplVerID_FIX50SP1))
 	builder.Header().Set(field.NewMsgType("BL"))
 	builder.Body().Set(posmaintrptid)
 	builder.Body().Set(clearingbusinessdate)
@@ -0,0 +1,138 @@
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+//defaultConnectAttemptDelay is the time a connection attempt is given before the next address is tried in parallel, the Connection Attempt Delay of RFC 8305.
+const defaultConnectAttemptDelay = 250 * time.Millisecond
+
+//addressPreference determines the address family tried first when a host resolves to both IPv6 and IPv4 addresses.
+type addressPreference int
+
+const (
+	//preferIPv6 tries an IPv6 address first, as recommended by RFC 8305.
+	preferIPv6 addressPreference = iota
+
+	//preferIPv4 tries an IPv4 address first.
+	preferIPv4
+)
+
+//parseAddressPreference maps the SocketConnectAddressPreference setting to an addressPreference.
+func parseAddressPreference(setting string) (addressPreference, error) {
+	switch setting {
+	case "IPv6":
+		return preferIPv6, nil
+	case "IPv4":
+		return preferIPv4, nil
+	}
+
+	return preferIPv6, fmt.Errorf("invalid SocketConnectAddressPreference %v, expected IPv6 or IPv4", setting)
+}
+
+func isIPv6(address string) bool {
+	return strings.Contains(address, ":")
+}
+
+//sortAddresses orders the resolved addresses of a host as in RFC 8305 section 4, alternating between the address families starting with the preferred.
+//Addresses of the same family keep the order of the resolver.
+func sortAddresses(addresses []string, preference addressPreference) []string {
+	var preferred, other []string
+	for _, address := range addresses {
+		if isIPv6(address) == (preference == preferIPv6) {
+			preferred = append(preferred, address)
+		} else {
+			other = append(other, address)
+		}
+	}
+
+	sorted := make([]string, 0, len(addresses))
+	for i := 0; i < len(preferred) || i < len(other); i++ {
+		if i < len(preferred) {
+			sorted = append(sorted, preferred[i])
+		}
+		if i < len(other) {
+			sorted = append(sorted, other[i])
+		}
+	}
+
+	return sorted
+}
+
+//dialResult is the outcome of a connection attempt.
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+//dialParallel connects to the first of addresses to accept, as in RFC 8305 section 5. Each address is tried attemptDelay after the previous,
+//or as soon as the previous attempt fails, while earlier attempts continue. Each attempt is limited to attemptTimeout if positive.
+//Returns the error of the first attempt if none succeed.
+func dialParallel(ctx context.Context, dialer Dialer, network string, addresses []string, attemptDelay, attemptTimeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult, len(addresses))
+	attempt := func(address string) {
+		attemptCtx := ctx
+		if attemptTimeout > 0 {
+			var cancelAttempt context.CancelFunc
+			attemptCtx, cancelAttempt = context.WithTimeout(ctx, attemptTimeout)
+			defer cancelAttempt()
+		}
+
+		conn, err := dialer(attemptCtx, network, address)
+		results <- dialResult{conn, err}
+	}
+
+	var next, pending int
+	var delay <-chan time.Time
+	start := func() {
+		go attempt(addresses[next])
+		next++
+		pending++
+
+		delay = nil
+		if next < len(addresses) {
+			delay = time.After(attemptDelay)
+		}
+	}
+
+	var err error
+	for start(); pending > 0; {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				go closeConnections(results, pending)
+				return r.conn, nil
+			}
+
+			if err == nil {
+				err = r.err
+			}
+
+			if next < len(addresses) {
+				start()
+			}
+
+		case <-delay:
+			start()
+		}
+	}
+
+	return nil, err
+}
+
+//closeConnections closes the connections of the pending attempts that succeed after another attempt won.
+func closeConnections(results <-chan dialResult, pending int) {
+	for ; pending > 0; pending-- {
+		if r := <-results; r.err == nil {
+			r.conn.Close()
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package quickfix
+
+import (
+	"context"
+	"errors"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAddressPreference(t *testing.T) {
+	for setting, expected := range map[string]addressPreference{"IPv6": preferIPv6, "IPv4": preferIPv4} {
+		if preference, err := parseAddressPreference(setting); err != nil || preference != expected {
+			t.Errorf("Expected %v for %v, got %v %v", expected, setting, preference, err)
+		}
+	}
+
+	if _, err := parseAddressPreference("Any"); err == nil {
+		t.Error("Expected error for Any")
+	}
+}
+
+func TestSortAddresses(t *testing.T) {
+	addresses := []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "2001:db8::1", "2001:db8::2"}
+
+	var tests = []struct {
+		preference addressPreference
+		expected   []string
+	}{
+		{preferIPv6, []string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "192.0.2.2", "192.0.2.3"}},
+		{preferIPv4, []string{"192.0.2.1", "2001:db8::1", "192.0.2.2", "2001:db8::2", "192.0.2.3"}},
+	}
+
+	for _, test := range tests {
+		if sorted := sortAddresses(addresses, test.preference); !reflect.DeepEqual(sorted, test.expected) {
+			t.Errorf("Expected %v, got %v", test.expected, sorted)
+		}
+	}
+}
+
+//testDialer connects to "accepted", refuses "refused" and never completes connecting to any other address.
+func testDialer(ctx context.Context, network, address string) (net.Conn, error) {
+	switch address {
+	case "accepted":
+		conn, _ := net.Pipe()
+		return conn, nil
+	case "refused":
+		return nil, errors.New("connection refused")
+	}
+
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDialParallel(t *testing.T) {
+	var tests = []struct {
+		addresses      []string
+		attemptDelay   time.Duration
+		attemptTimeout time.Duration
+		connected      bool
+	}{
+		//an unreachable address does not hold up the next
+		{[]string{"unreachable", "accepted"}, 10 * time.Millisecond, 0, true},
+		//a refused address is followed immediately
+		{[]string{"refused", "accepted"}, time.Hour, 0, true},
+		{[]string{"unreachable", "refused"}, time.Millisecond, 20 * time.Millisecond, false},
+	}
+
+	for _, test := range tests {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+
+			conn, err := dialParallel(context.Background(), testDialer, "tcp", test.addresses, test.attemptDelay, test.attemptTimeout)
+			if connected := err == nil; connected != test.connected {
+				t.Errorf("%v: expected connected %v, got %v", test.addresses, test.connected, err)
+			}
+
+			if conn != nil {
+				conn.Close()
+			}
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%v: timed out connecting", test.addresses)
+		}
+	}
+}
+
+func TestEndpoints_AttemptSettings(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set(config.SocketConnectHost, "fix.example.com")
+	settings.Set(config.SocketConnectPort, "5001")
+
+	e, err := newEndpoints(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.preference != preferIPv6 || e.attemptDelay != defaultConnectAttemptDelay || e.attemptTimeout != 0 {
+		t.Errorf("Unexpected defaults %v %v %v", e.preference, e.attemptDelay, e.attemptTimeout)
+	}
+
+	settings.Set(config.SocketConnectAddressPreference, "IPv4")
+	settings.Set(config.SocketConnectAttemptDelay, "100")
+	settings.Set(config.SocketConnectAttemptTimeout, "2000")
+
+	if e, err = newEndpoints(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.preference != preferIPv4 || e.attemptDelay != 100*time.Millisecond || e.attemptTimeout != 2*time.Second {
+		t.Errorf("Unexpected settings %v %v %v", e.preference, e.attemptDelay, e.attemptTimeout)
+	}
+
+	settings.Set(config.SocketConnectAttemptTimeout, "-1")
+	if _, err := newEndpoints(settings); err == nil {
+		t.Error("Expected error for negative timeout")
+	}
+}
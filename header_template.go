@@ -0,0 +1,199 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"time"
+)
+
+//headerTemplate is the header of the messages sent by a session. The fields constant for the session are formatted once, SenderCompID
+//and TargetCompID as one block spliced into each message built following MsgType, so that only MsgSeqNum, SendingTime and BodyLength
+//are formatted per message, the block neither copied into the header nor summed for BodyLength and CheckSum.
+type headerTemplate struct {
+	//beginString is the BeginString field, written first as any field
+	beginString *fieldBytes
+
+	//fields are the fields of block, shared by the headers of the messages built so that they are read as any field
+	fields []*fieldBytes
+
+	//block is fields formatted as one slice
+	block []byte
+
+	//length and total are the contributions of block to BodyLength and CheckSum
+	length, total int
+
+	//noMillis formats SendingTime without milliseconds, before FIX.4.2
+	noMillis bool
+}
+
+//newHeaderTemplate returns the header of the messages sent by the session with sessionID.
+func newHeaderTemplate(sessionID SessionID) *headerTemplate {
+	h := &headerTemplate{
+		beginString: newFieldBytes(tag.BeginString, []byte(sessionID.BeginString)),
+		fields: []*fieldBytes{
+			newFieldBytes(tag.SenderCompID, []byte(sessionID.SenderCompID)),
+			newFieldBytes(tag.TargetCompID, []byte(sessionID.TargetCompID)),
+		},
+		noMillis: sessionID.BeginString < fix.BeginString_FIX42,
+	}
+
+	for _, f := range h.fields {
+		h.block = append(h.block, f.Data...)
+		h.length += f.Length()
+		h.total += f.Total()
+	}
+
+	return h
+}
+
+//owns returns true if field is a field of the block.
+func (h *headerTemplate) owns(field *fieldBytes) bool {
+	for _, f := range h.fields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+//fill sets the constant fields of the header, and SendingTime to now, on the header of builder. The header of a builder of
+//NewMessageBuilder shares the fields of the template, other builders are set with each field.
+func (h *headerTemplate) fill(builder MessageBuilder, now time.Time) {
+	if b, ok := builder.(*messageBuilder); ok {
+		b.setTemplate(h)
+	} else {
+		builder.Header().SetField(tag.BeginString, &fix.StringValue{Value: string(h.beginString.Value)})
+		for _, f := range h.fields {
+			builder.Header().SetField(f.Tag, &fix.StringValue{Value: string(f.Value)})
+		}
+	}
+
+	h.setSendingTime(builder.Header(), now)
+}
+
+//setSendingTime sets SendingTime to now on header.
+func (h *headerTemplate) setSendingTime(header MutableFieldMap, now time.Time) {
+	fields, ok := header.(fieldMap)
+	if !ok {
+		header.SetField(tag.SendingTime, &fix.UTCTimestampValue{Value: now, NoMillis: h.noMillis})
+		return
+	}
+
+	field := fields.newField(tag.SendingTime)
+	start := len(field.Data)
+	field.Data = fix.UTCTimestampValue{Value: now, NoMillis: h.noMillis}.AppendValue(field.Data)
+	field.terminate(start)
+}
+
+//setMsgSeqNum sets MsgSeqNum to seqNum on header.
+func (h *headerTemplate) setMsgSeqNum(header MutableFieldMap, seqNum int) {
+	if fields, ok := header.(fieldMap); ok {
+		fields.setInt(tag.MsgSeqNum, seqNum)
+		return
+	}
+
+	header.SetField(tag.MsgSeqNum, &fix.IntValue{Value: seqNum})
+}
+
+//setTemplate sets the constant header fields of t, replacing those of any template set before.
+func (m *messageBuilder) setTemplate(t *headerTemplate) {
+	m.releaseTemplate()
+
+	m.template = t
+	m.header.fieldLookup[tag.BeginString] = t.beginString
+	for _, f := range t.fields {
+		m.header.fieldLookup[f.Tag] = f
+	}
+}
+
+//releaseTemplate removes the fields of the template still set, so that they are not reused as the storage of the fields set next.
+func (m *messageBuilder) releaseTemplate() {
+	t := m.template
+	if t == nil {
+		return
+	}
+
+	if m.header.fieldLookup[tag.BeginString] == t.beginString {
+		delete(m.header.fieldLookup, tag.BeginString)
+	}
+	for _, f := range t.fields {
+		if m.header.fieldLookup[f.Tag] == f {
+			delete(m.header.fieldLookup, f.Tag)
+		}
+	}
+
+	m.template = nil
+}
+
+//splicedTemplate returns the template whose block is spliced into the message built, nil if the builder has none, or a field of
+//the block was replaced since it was set.
+func (m *messageBuilder) splicedTemplate() *headerTemplate {
+	t := m.template
+	if t == nil || !m.header.Has(tag.MsgType) {
+		return nil
+	}
+
+	for _, f := range t.fields {
+		if m.header.fieldLookup[f.Tag] != f {
+			return nil
+		}
+	}
+
+	return t
+}
+
+//headerLength returns the contribution of the header to BodyLength, that of the block of t, if not nil, precomputed.
+func (m *messageBuilder) headerLength(t *headerTemplate) int {
+	if t == nil {
+		return m.header.length()
+	}
+
+	length := t.length
+	for tg, field := range m.header.fieldLookup {
+		switch {
+		case tg == tag.BeginString, tg == tag.BodyLength, t.owns(field):
+		default:
+			length += field.Length()
+		}
+	}
+
+	return length
+}
+
+//headerTotal returns the contribution of the header to CheckSum, that of the block of t, if not nil, precomputed.
+func (m *messageBuilder) headerTotal(t *headerTemplate) int {
+	if t == nil {
+		return m.header.total()
+	}
+
+	total := t.total
+	for _, field := range m.header.fieldLookup {
+		if !t.owns(field) {
+			total += field.Total()
+		}
+	}
+
+	return total
+}
+
+//appendHeader appends the header fields with tags, in order, to dst, the block of t, if not nil, following MsgType.
+func (m *messageBuilder) appendHeader(dst []byte, t *headerTemplate, tags []fix.Tag) []byte {
+	if t == nil {
+		return m.header.appendTo(dst, tags)
+	}
+
+	for _, tg := range tags {
+		field := m.header.fieldLookup[tg]
+		if t.owns(field) {
+			continue
+		}
+
+		dst = append(dst, field.Data...)
+		if tg == tag.MsgType {
+			dst = append(dst, t.block...)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,161 @@
+package quickfix
+
+import (
+	"bytes"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+	"time"
+)
+
+var headerTemplateTime = time.Date(2016, time.August, 3, 12, 30, 15, 123456789, time.UTC)
+
+//formattedHeader sets the header of a message sent by the session with sessionID, formatting each field.
+func formattedHeader(builder MessageBuilder, sessionID SessionID, seqNum int) {
+	builder.Header().Set(field.NewBeginString(sessionID.BeginString))
+	builder.Header().Set(field.NewSenderCompID(sessionID.SenderCompID))
+	builder.Header().Set(field.NewTargetCompID(sessionID.TargetCompID))
+	builder.Header().Set(fix.NewUTCTimestampField(tag.SendingTime, headerTemplateTime))
+	builder.Header().Set(fix.NewIntField(tag.MsgSeqNum, seqNum))
+}
+
+//newHeartbeatBuilder returns a builder with the MsgType of a Heartbeat.
+func newHeartbeatBuilder() ReusableMessageBuilder {
+	builder := NewReusableMessageBuilder()
+	builder.Header().Set(field.NewMsgType("0"))
+	return builder
+}
+
+//headerFields returns the values of the header fields of msgBytes, by tag.
+func headerFields(t *testing.T, msgBytes []byte) map[fix.Tag]string {
+	msg, err := parseMessage(msgBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(map[fix.Tag]string)
+	for _, f := range msg.Header.Tags() {
+		value := new(fix.StringValue)
+		msg.Header.GetField(f, value)
+		fields[f] = value.Value
+	}
+
+	return fields
+}
+
+func TestHeaderTemplate_Fill(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "TW", TargetCompID: "ISLD"}
+	header := newHeaderTemplate(sessionID)
+
+	expected := newHeartbeatBuilder()
+	formattedHeader(expected, sessionID, 12)
+	expectedBytes, _ := expected.Build()
+
+	builder := newHeartbeatBuilder()
+	for i := 0; i < 2; i++ {
+		builder.Reset()
+		builder.Header().Set(field.NewMsgType("0"))
+		header.fill(builder, headerTemplateTime)
+		header.setMsgSeqNum(builder.Header(), 12)
+
+		//the fields of the template are read from the header as any field
+		targetCompID := new(fix.StringValue)
+		if builder.Header().GetField(tag.TargetCompID, targetCompID); targetCompID.Value != "ISLD" {
+			t.Errorf("Expected TargetCompID ISLD, got %v", targetCompID.Value)
+		}
+
+		msgBytes, _ := builder.Build()
+		if !bytes.HasPrefix(msgBytes, []byte("8=FIX.4.4\0019=50\00135=0\00149=TW\00156=ISLD\00134=12\00152=")) {
+			t.Errorf("Expected the CompIDs spliced following MsgType, got %q", msgBytes)
+		}
+
+		//BodyLength and CheckSum do not depend on the order of the fields
+		if len(msgBytes) != len(expectedBytes) || !bytes.HasSuffix(msgBytes, expectedBytes[len(expectedBytes)-7:]) {
+			t.Errorf("Expected the BodyLength and CheckSum of %q, got %q", expectedBytes, msgBytes)
+		}
+
+		fields, expectedFields := headerFields(t, msgBytes), headerFields(t, expectedBytes)
+		if len(fields) != len(expectedFields) {
+			t.Errorf("Expected header %v, got %v", expectedFields, fields)
+		}
+		for f, value := range expectedFields {
+			if fields[f] != value {
+				t.Errorf("Expected %v=%v, got %v", f, value, fields[f])
+			}
+		}
+	}
+
+	buf := make([]byte, 0, 256)
+	allocs := testing.AllocsPerRun(100, func() {
+		builder.Reset()
+		header.fill(builder, headerTemplateTime)
+		header.setMsgSeqNum(builder.Header(), 12)
+		buf, _ = builder.AppendBuild(buf[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("Expected the header filled without allocating, got %v allocations", allocs)
+	}
+}
+
+func TestHeaderTemplate_FieldReplaced(t *testing.T) {
+	header := newHeaderTemplate(SessionID{BeginString: "FIX.4.4", SenderCompID: "TW", TargetCompID: "ISLD"})
+
+	builder := newHeartbeatBuilder()
+	header.fill(builder, headerTemplateTime)
+	builder.Header().Set(field.NewTargetCompID("CHANGED"))
+
+	msgBytes, _ := builder.Build()
+	if fields := headerFields(t, msgBytes); fields[tag.TargetCompID] != "CHANGED" || fields[tag.SenderCompID] != "TW" {
+		t.Errorf("Expected TargetCompID replaced, got %q", msgBytes)
+	}
+	if bytes.Count(msgBytes, []byte("\00156=")) != 1 {
+		t.Errorf("Expected TargetCompID once, got %q", msgBytes)
+	}
+
+	//the fields of the template are not reused as the storage of the fields set once reset
+	builder.(ReusableMessageBuilder).Reset()
+	builder.Header().Set(field.NewBeginString("FIXT.1.1"))
+	builder.Header().Set(field.NewSenderCompID("OTHER"))
+	if string(header.beginString.Data) != "8=FIX.4.4\001" || string(header.fields[0].Data) != "49=TW\001" || string(header.block) != "49=TW\00156=ISLD\001" {
+		t.Errorf("Expected the template unchanged, got %q %q", header.beginString.Data, header.block)
+	}
+}
+
+func TestHeaderTemplate_NoMillis(t *testing.T) {
+	builder := NewMessageBuilder()
+	newHeaderTemplate(SessionID{BeginString: "FIX.4.1", SenderCompID: "TW", TargetCompID: "ISLD"}).fill(builder, headerTemplateTime)
+
+	sendingTime := new(fix.StringValue)
+	if builder.Header().GetField(tag.SendingTime, sendingTime); sendingTime.Value != "20160803-12:30:15" {
+		t.Errorf("Expected SendingTime without milliseconds before FIX.4.2, got %v", sendingTime.Value)
+	}
+}
+
+func BenchmarkHeaderTemplate(b *testing.B) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "TW", TargetCompID: "ISLD"}
+	header := newHeaderTemplate(sessionID)
+	builder := newHeartbeatBuilder()
+	buf := make([]byte, 0, 256)
+
+	b.Run("Formatted", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder.Reset()
+			builder.Header().Set(field.NewMsgType("0"))
+			formattedHeader(builder, sessionID, i)
+			buf, _ = builder.AppendBuild(buf[:0])
+		}
+	})
+
+	b.Run("Template", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder.Reset()
+			builder.Header().Set(field.NewMsgType("0"))
+			header.fill(builder, headerTemplateTime)
+			header.setMsgSeqNum(builder.Header(), i)
+			buf, _ = builder.AppendBuild(buf[:0])
+		}
+	})
+}
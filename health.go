@@ -0,0 +1,239 @@
+package quickfix
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+//SessionHealth is the health of a session, whether it is usable to send and receive messages.
+type SessionHealth struct {
+	SessionID SessionID
+	State     SessionState
+
+	//LoggedOn is true if the session is logged on, including while awaiting a resend or the response to a TestRequest.
+	LoggedOn bool
+
+	//SessionTime is true if the session schedule, if any, is active. Out of its schedule a session is not expected to be logged on.
+	SessionTime bool
+
+	//SeqNumGap is true while messages requested from the counterparty with a ResendRequest are outstanding.
+	SeqNumGap bool
+
+	//StoreHealth is the error returned by the store if a HealthStore, nil if healthy.
+	StoreHealth error
+
+	//LastReceivedAge is the time since the last message, Heartbeat or other, was received from the counterparty, zero if none has been received.
+	LastReceivedAge time.Duration
+
+	//HeartbeatOverdue is true if logged on and no message has been received within the TestRequest delay of HeartBtInt.
+	HeartbeatOverdue bool
+
+	//Usable is true if the session is logged on, without a sequence number gap or overdue heartbeat, and its store is healthy.
+	Usable bool
+
+	//Problems describe why the session is not usable, empty if usable.
+	Problems []string
+}
+
+//Health is the health of the sessions of an Initiator or Acceptor, as returned by their Health method.
+type Health struct {
+	//Live is true if the Initiator or Acceptor is started and not shut down.
+	Live bool
+
+	//Ready is true if live and the sessions are usable, see Initiator.Health and Acceptor.Health.
+	Ready bool
+
+	//Sessions are the health of each session, ordered by SessionID.
+	Sessions []SessionHealth
+}
+
+//Health returns the health of the session at now.
+//Safe to call outside the session goroutine.
+func (s *Session) Health(now time.Time) SessionHealth {
+	health := SessionHealth{SessionID: s.sessionID, SessionTime: s.isSessionTime(now)}
+
+	s.trafficLock.RLock()
+	health.State = s.traffic.state
+	lastReceived, heartBtInt := s.traffic.lastReceived, s.traffic.heartBtInt
+	s.trafficLock.RUnlock()
+
+	switch health.State {
+	case StateLoggedOn, StateTestRequestSent:
+		health.LoggedOn = true
+	case StateAwaitingResend:
+		health.LoggedOn, health.SeqNumGap = true, true
+	}
+
+	if !lastReceived.IsZero() {
+		health.LastReceivedAge = now.Sub(lastReceived)
+	}
+
+	multiplier := s.testRequestDelayMultiplier
+	if multiplier == 0 {
+		multiplier = defaultTestRequestDelayMultiplier
+	}
+	health.HeartbeatOverdue = health.LoggedOn && heartBtInt > 0 && health.LastReceivedAge > time.Duration(multiplier*float64(heartBtInt))
+
+	if store, ok := s.store.(HealthStore); ok {
+		health.StoreHealth = store.Health()
+	}
+
+	if !health.LoggedOn {
+		health.Problems = append(health.Problems, fmt.Sprintf("not logged on, %v", health.State))
+	}
+	if health.SeqNumGap {
+		health.Problems = append(health.Problems, "awaiting resend of a sequence number gap")
+	}
+	if health.HeartbeatOverdue {
+		health.Problems = append(health.Problems, fmt.Sprintf("no message received for %v, HeartBtInt %v", health.LastReceivedAge, heartBtInt))
+	}
+	if health.StoreHealth != nil {
+		health.Problems = append(health.Problems, fmt.Sprintf("store unhealthy: %v", health.StoreHealth))
+	}
+	health.Usable = len(health.Problems) == 0
+
+	return health
+}
+
+//LookupSessionHealth returns the health of the session with sessionID.
+func LookupSessionHealth(sessionID SessionID) (SessionHealth, error) {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return SessionHealth{}, err
+	}
+
+	return session.Health(session.now()), nil
+}
+
+//sessionsHealth returns the health of the registered sessions of sessionIDs, ordered by SessionID.
+func sessionsHealth(sessionIDs []SessionID) []SessionHealth {
+	var sessions []SessionHealth
+	for _, sessionID := range sessionIDs {
+		if health, err := LookupSessionHealth(sessionID); err == nil {
+			sessions = append(sessions, health)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].SessionID.String() < sessions[j].SessionID.String()
+	})
+
+	return sessions
+}
+
+//Health returns the health of the Initiator and its sessions.
+//The Initiator is ready if live and each session in its session time is usable, so that it is not ready until its sessions are logged on.
+func (i *Initiator) Health() Health {
+	i.sessionLock.Lock()
+	health := Health{Live: i.stopChan != nil}
+	sessionIDs := make([]SessionID, 0, len(i.sessionSettings))
+	for sessionID := range i.sessionSettings {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	i.sessionLock.Unlock()
+
+	health.Sessions = sessionsHealth(sessionIDs)
+	health.Ready = health.Live
+	for _, session := range health.Sessions {
+		if session.SessionTime && !session.Usable {
+			health.Ready = false
+		}
+	}
+
+	return health
+}
+
+//Health returns the health of the Acceptor and its sessions.
+//The Acceptor is ready if live and the store of each session is healthy. Sessions not logged on do not make the Acceptor unready,
+//the counterparty connecting to it once ready, but a logged on session with a sequence number gap or overdue heartbeat does.
+func (a *Acceptor) Health() Health {
+	a.sessionLock.RLock()
+	health := Health{Live: a.listeners != nil}
+	sessionIDs := make([]SessionID, 0, len(a.qualifiedSessionIDs))
+	for _, sessionID := range a.qualifiedSessionIDs {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	a.sessionLock.RUnlock()
+
+	health.Sessions = sessionsHealth(sessionIDs)
+	health.Ready = health.Live
+	for _, session := range health.Sessions {
+		if session.StoreHealth != nil || (session.LoggedOn && !session.Usable) {
+			health.Ready = false
+		}
+	}
+
+	return health
+}
+
+//HealthChecker is implemented by Initiator and Acceptor.
+type HealthChecker interface {
+	Health() Health
+}
+
+//healthResponse is the JSON body written by the handlers of NewLivenessHandler and NewReadinessHandler.
+type healthResponse struct {
+	Live     bool                    `json:"live"`
+	Ready    bool                    `json:"ready"`
+	Sessions []sessionHealthResponse `json:"sessions"`
+}
+
+type sessionHealthResponse struct {
+	SessionID       string   `json:"sessionID"`
+	State           string   `json:"state"`
+	LoggedOn        bool     `json:"loggedOn"`
+	SessionTime     bool     `json:"sessionTime"`
+	SeqNumGap       bool     `json:"seqNumGap"`
+	StoreHealthy    bool     `json:"storeHealthy"`
+	LastReceivedAge float64  `json:"lastReceivedAgeSeconds"`
+	Usable          bool     `json:"usable"`
+	Problems        []string `json:"problems,omitempty"`
+}
+
+//NewLivenessHandler returns an http.Handler responding 200 OK if the Health of checker is live, otherwise 503 Service Unavailable,
+//with the Health as a JSON body.
+func NewLivenessHandler(checker HealthChecker) http.Handler {
+	return healthHandler{checker, func(health Health) bool { return health.Live }}
+}
+
+//NewReadinessHandler returns an http.Handler responding 200 OK if the Health of checker is ready, otherwise 503 Service Unavailable,
+//with the Health as a JSON body.
+func NewReadinessHandler(checker HealthChecker) http.Handler {
+	return healthHandler{checker, func(health Health) bool { return health.Ready }}
+}
+
+type healthHandler struct {
+	checker HealthChecker
+	healthy func(Health) bool
+}
+
+func (h healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	health := h.checker.Health()
+
+	response := healthResponse{Live: health.Live, Ready: health.Ready, Sessions: []sessionHealthResponse{}}
+	for _, session := range health.Sessions {
+		response.Sessions = append(response.Sessions, sessionHealthResponse{
+			SessionID:       session.SessionID.String(),
+			State:           session.State.String(),
+			LoggedOn:        session.LoggedOn,
+			SessionTime:     session.SessionTime,
+			SeqNumGap:       session.SeqNumGap,
+			StoreHealthy:    session.StoreHealth == nil,
+			LastReceivedAge: session.LastReceivedAge.Seconds(),
+			Usable:          session.Usable,
+			Problems:        session.Problems,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if h.healthy(health) {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
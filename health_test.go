@@ -0,0 +1,136 @@
+package quickfix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/quickfixgo/quickfix/config"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSession_Health(t *testing.T) {
+	memory, _ := NewMemoryStoreFactory().Create(SessionID{})
+	store := &unhealthyStore{memoryStore: memory.(*memoryStore)}
+	session := &Session{store: store}
+	now := time.Now()
+
+	if health := session.Health(now); health.LoggedOn || health.Usable || !health.SessionTime || len(health.Problems) != 1 {
+		t.Errorf("Expected session not logged on, got %+v", health)
+	}
+
+	session.traffic.state = StateLoggedOn
+	session.traffic.heartBtInt = 30 * time.Second
+	session.traffic.lastReceived = now.Add(-10 * time.Second)
+	if health := session.Health(now); !health.Usable || health.LastReceivedAge != 10*time.Second || len(health.Problems) != 0 {
+		t.Errorf("Expected usable session, got %+v", health)
+	}
+
+	session.traffic.lastReceived = now.Add(-40 * time.Second)
+	if health := session.Health(now); !health.HeartbeatOverdue || health.Usable {
+		t.Errorf("Expected overdue heartbeat, got %+v", health)
+	}
+
+	session.traffic.state = StateAwaitingResend
+	session.traffic.lastReceived = now
+	if health := session.Health(now); !health.LoggedOn || !health.SeqNumGap || health.Usable {
+		t.Errorf("Expected sequence number gap, got %+v", health)
+	}
+
+	session.traffic.state = StateLoggedOn
+	store.err = errors.New("disk full")
+	if health := session.Health(now); health.StoreHealth != store.err || health.Usable {
+		t.Errorf("Expected unhealthy store, got %+v", health)
+	}
+}
+
+func TestInitiatorAcceptor_Health(t *testing.T) {
+	port := strconv.Itoa(freePort(t))
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, port)
+	acceptorSessionID, err := acceptorSettings.AddSession(newTestAcceptorSessionSettings("HEALTHY"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acceptorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorSettings := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "HEALTHY")
+	sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+	sessionSettings.Set(config.HeartBtInt, "30")
+	sessionSettings.Set(config.SocketConnectHost, "127.0.0.1")
+	sessionSettings.Set(config.SocketConnectPort, port)
+	initiatorSessionID, err := initiatorSettings.AddSession(sessionSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if health := acceptor.Health(); health.Live || health.Ready || len(health.Sessions) != 1 || health.Sessions[0].SessionID != acceptorSessionID {
+		t.Errorf("Expected acceptor not live before start, got %+v", health)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	if health := acceptor.Health(); !health.Live || !health.Ready || health.Sessions[0].LoggedOn {
+		t.Errorf("Expected acceptor ready awaiting logon, got %+v", health)
+	}
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer initiator.Shutdown(context.Background())
+
+	awaitState(t, initiatorApp.states, StateLoggedOn)
+	awaitState(t, acceptorApp.states, StateLoggedOn)
+
+	health := initiator.Health()
+	if !health.Live || !health.Ready || len(health.Sessions) != 1 {
+		t.Fatalf("Expected initiator ready, got %+v", health)
+	}
+
+	if session := health.Sessions[0]; session.SessionID != initiatorSessionID || !session.LoggedOn || !session.Usable {
+		t.Errorf("Expected usable session, got %+v", session)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewReadinessHandler(initiator).ServeHTTP(recorder, httptest.NewRequest("GET", "/ready", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected 200 OK, got %v", recorder.Code)
+	}
+
+	var response healthResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if !response.Ready || len(response.Sessions) != 1 || response.Sessions[0].SessionID != initiatorSessionID.String() || response.Sessions[0].State != "LoggedOn" {
+		t.Errorf("Expected ready response, got %+v", response)
+	}
+
+	initiator.Shutdown(context.Background())
+
+	recorder = httptest.NewRecorder()
+	NewLivenessHandler(initiator).ServeHTTP(recorder, httptest.NewRequest("GET", "/live", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 Service Unavailable once shut down, got %v", recorder.Code)
+	}
+}
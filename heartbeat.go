@@ -0,0 +1,139 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix/field"
+	"time"
+)
+
+const (
+	//defaultTestRequestDelayMultiplier of HeartBtInt without receiving a message before sending a TestRequest.
+	defaultTestRequestDelayMultiplier = 1.2
+
+	//defaultHeartBeatTimeoutMultiplier of HeartBtInt without a response to a TestRequest before disconnecting.
+	defaultHeartBeatTimeoutMultiplier = 1.2
+
+	//defaultMaxLatency is the largest deviation of SendingTime from local time accepted.
+	defaultMaxLatency = 120 * time.Second
+)
+
+//trafficState records message traffic for monitoring, read by monitoring from other goroutines.
+type trafficState struct {
+	state                  SessionState
+	lastReceived, lastSent time.Time
+	heartBtInt             time.Duration
+	clockSkew, maxSkew     time.Duration
+	throttled              int
+	counterpartyLogon      LogonAttributes
+}
+
+//LastReceivedTime returns the time the last message was received from the counterparty, zero if none has been received.
+func (s *Session) LastReceivedTime() time.Time {
+	s.trafficLock.RLock()
+	defer s.trafficLock.RUnlock()
+
+	return s.traffic.lastReceived
+}
+
+//LastSentTime returns the time the last message was sent to the counterparty, zero if none has been sent.
+func (s *Session) LastSentTime() time.Time {
+	s.trafficLock.RLock()
+	defer s.trafficLock.RUnlock()
+
+	return s.traffic.lastSent
+}
+
+//HeartBtInt returns the heartbeat interval negotiated on logon, zero if not logged on.
+func (s *Session) HeartBtInt() time.Duration {
+	s.trafficLock.RLock()
+	defer s.trafficLock.RUnlock()
+
+	return s.traffic.heartBtInt
+}
+
+//ClockSkew returns the difference between the receive time and SendingTime of the last message received, positive if SendingTime is in the past.
+//The skew includes network latency.
+func (s *Session) ClockSkew() time.Duration {
+	s.trafficLock.RLock()
+	defer s.trafficLock.RUnlock()
+
+	return s.traffic.clockSkew
+}
+
+//MaxClockSkew returns the largest absolute ClockSkew observed since the session was created.
+func (s *Session) MaxClockSkew() time.Duration {
+	s.trafficLock.RLock()
+	defer s.trafficLock.RUnlock()
+
+	return s.traffic.maxSkew
+}
+
+func (s *Session) onClockSkew(skew time.Duration) {
+	s.trafficLock.Lock()
+	defer s.trafficLock.Unlock()
+
+	s.traffic.clockSkew = skew
+
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if skew > s.traffic.maxSkew {
+		s.traffic.maxSkew = skew
+	}
+}
+
+func (s *Session) onMessageReceived(receiveTime time.Time) {
+	s.trafficLock.Lock()
+	s.traffic.lastReceived = receiveTime
+	s.trafficLock.Unlock()
+
+	s.peerTimer.Reset(s.testRequestDelay())
+}
+
+func (s *Session) onMessageSent(sendTime time.Time) {
+	s.trafficLock.Lock()
+	s.traffic.lastSent = sendTime
+	s.trafficLock.Unlock()
+
+	s.stateTimer.Reset(s.heartBeatTimeout)
+}
+
+//setHeartBtInt sets the heartbeat interval used for the session.
+func (s *Session) setHeartBtInt(heartBtInt time.Duration) {
+	s.heartBeatTimeout = heartBtInt
+
+	s.trafficLock.Lock()
+	s.traffic.heartBtInt = heartBtInt
+	s.trafficLock.Unlock()
+}
+
+//testRequestDelay is the time without receiving a message before a TestRequest is sent.
+func (s *Session) testRequestDelay() time.Duration {
+	multiplier := s.testRequestDelayMultiplier
+	if multiplier == 0 {
+		multiplier = defaultTestRequestDelayMultiplier
+	}
+
+	return time.Duration(multiplier * float64(s.heartBeatTimeout))
+}
+
+//testRequestTimeout is the time without a response to a TestRequest before the connection is considered dead.
+func (s *Session) testRequestTimeout() time.Duration {
+	multiplier := s.heartBeatTimeoutMultiplier
+	if multiplier == 0 {
+		multiplier = defaultHeartBeatTimeoutMultiplier
+	}
+
+	return time.Duration(multiplier * float64(s.heartBeatTimeout))
+}
+
+//negotiateHeartBtInt returns the HeartBtInt to use for the HeartBtInt of a Logon received from the counterparty.
+//With EnforceHeartBtInt the configured HeartBtInt is used regardless.
+func (s *Session) negotiateHeartBtInt(received *field.HeartBtIntField) int {
+	if s.enforceHeartBtInt && received.Value != s.heartBtInt {
+		logEventf(s.log, LogLevelWarn, LogCategorySession, "Enforcing HeartBtInt %d, counterparty requested %d", s.heartBtInt, received.Value)
+		return s.heartBtInt
+	}
+
+	return received.Value
+}
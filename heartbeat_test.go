@@ -0,0 +1,73 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix/field"
+	"testing"
+	"time"
+)
+
+func TestSession_HeartbeatTolerances(t *testing.T) {
+	session := &Session{}
+	session.setHeartBtInt(10 * time.Second)
+
+	if session.testRequestDelay() != 12*time.Second {
+		t.Errorf("Expected default TestRequest delay of 12s, got %v", session.testRequestDelay())
+	}
+
+	if session.testRequestTimeout() != 12*time.Second {
+		t.Errorf("Expected default TestRequest timeout of 12s, got %v", session.testRequestTimeout())
+	}
+
+	session.testRequestDelayMultiplier = 2
+	session.heartBeatTimeoutMultiplier = 0.5
+
+	if session.testRequestDelay() != 20*time.Second {
+		t.Errorf("Expected TestRequest delay of 20s, got %v", session.testRequestDelay())
+	}
+
+	if session.testRequestTimeout() != 5*time.Second {
+		t.Errorf("Expected TestRequest timeout of 5s, got %v", session.testRequestTimeout())
+	}
+
+	if session.HeartBtInt() != 10*time.Second {
+		t.Errorf("Expected HeartBtInt of 10s, got %v", session.HeartBtInt())
+	}
+}
+
+func TestSession_NegotiateHeartBtInt(t *testing.T) {
+	session := &Session{heartBtInt: 30, log: nullLog{}}
+
+	if heartBtInt := session.negotiateHeartBtInt(field.NewHeartBtInt(60)); heartBtInt != 60 {
+		t.Errorf("Expected counterparty HeartBtInt 60, got %v", heartBtInt)
+	}
+
+	session.enforceHeartBtInt = true
+	if heartBtInt := session.negotiateHeartBtInt(field.NewHeartBtInt(60)); heartBtInt != 30 {
+		t.Errorf("Expected enforced HeartBtInt 30, got %v", heartBtInt)
+	}
+}
+
+func TestSession_LastSentReceivedTime(t *testing.T) {
+	session := &Session{
+		messageOut: make(chan []byte, 1),
+		log:        nullLog{},
+		stateTimer: eventTimer{Task: func() {}},
+		peerTimer:  eventTimer{Task: func() {}},
+	}
+
+	if !session.LastSentTime().IsZero() || !session.LastReceivedTime().IsZero() {
+		t.Error("Expected zero times before any traffic")
+	}
+
+	before := time.Now()
+	session.sendBytes([]byte("msg"))
+	if session.LastSentTime().Before(before) {
+		t.Errorf("Expected last sent time after %v, got %v", before, session.LastSentTime())
+	}
+
+	received := time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC)
+	session.onMessageReceived(received)
+	if !session.LastReceivedTime().Equal(received) {
+		t.Errorf("Expected last received time %v, got %v", received, session.LastReceivedTime())
+	}
+}
@@ -52,7 +52,7 @@ func (state inSession) Timeout(session *Session, event event) (nextState session
 		testReq.Header().Set(field.NewMsgType("1"))
 		testReq.Body().Set(field.NewTestReqID("TEST"))
 		session.send(testReq)
-		session.peerTimer.Reset(time.Duration(int64(1.2 * float64(session.heartBeatTimeout))))
+		session.peerTimer.Reset(session.testRequestTimeout())
 		return pendingTimeout{}
 	}
 	return state
@@ -67,9 +67,11 @@ func (state inSession) handleLogon(session *Session, msg Message) (nextState ses
 }
 
 func (state inSession) handleLogout(session *Session, msg Message) (nextState sessionState) {
-	session.log.OnEvent("Received logout request")
+	logEventf(session.log, LogLevelInfo, LogCategorySession, "Received logout request")
+	session.checkSessionStatus(msg, false)
 	state.generateLogout(session)
 	session.application.OnLogout(session.sessionID)
+	session.onLogout()
 
 	return latentState{}
 }
@@ -85,7 +87,7 @@ func (state inSession) handleSequenceReset(session *Session, msg Message) (nextS
 	newSeqNo := new(fix.IntField)
 	if err := msg.Body.GetField(tag.NewSeqNo, newSeqNo); err == nil {
 		expectedSeqNum := session.store.NextTargetMsgSeqNum()
-		session.log.OnEventf("Received SequenceReset FROM: %v TO: %v", expectedSeqNum, newSeqNo.Value)
+		logEventf(session.log, LogLevelInfo, LogCategorySession, "Received SequenceReset FROM: %v TO: %v", expectedSeqNum, newSeqNo.Value)
 
 		switch {
 		case newSeqNo.Value > expectedSeqNum:
@@ -100,6 +102,8 @@ func (state inSession) handleSequenceReset(session *Session, msg Message) (nextS
 }
 
 func (state inSession) handleResendRequest(session *Session, msg Message) (nextState sessionState) {
+	session.onResendRequestReceived()
+
 	if err := session.verifyIgnoreSeqNumTooHighOrLow(msg); err != nil {
 		return state.processReject(session, msg, err)
 	}
@@ -119,13 +123,13 @@ func (state inSession) handleResendRequest(session *Session, msg Message) (nextS
 
 	endSeqNo := endSeqNoField.Value
 
-	session.log.OnEventf("Received ResendRequest FROM: %d TO: %d", beginSeqNo, endSeqNo)
-	expectedSeqNum := session.store.NextTargetMsgSeqNum()
+	logEventf(session.log, LogLevelInfo, LogCategorySession, "Received ResendRequest FROM: %d TO: %d", beginSeqNo, endSeqNo)
+	lastSentSeqNum := session.store.NextSenderMsgSeqNum() - 1
 
 	if (session.sessionID.BeginString >= fix.BeginString_FIX42 && endSeqNo == 0) ||
 		(session.sessionID.BeginString <= fix.BeginString_FIX42 && endSeqNo == 999999) ||
-		(endSeqNo >= expectedSeqNum) {
-		endSeqNo = expectedSeqNum - 1
+		(endSeqNo > lastSentSeqNum) {
+		endSeqNo = lastSentSeqNum
 	}
 
 	state.resendMessages(session, beginSeqNo, endSeqNo)
@@ -133,44 +137,40 @@ func (state inSession) handleResendRequest(session *Session, msg Message) (nextS
 	return state
 }
 
+//resendMessages resends the stored application messages from beginSeqNo to endSeqNo.
+//Admin messages, messages vetoed by the application, and messages missing from the store are replaced by SequenceReset-GapFill messages.
 func (state inSession) resendMessages(session *Session, beginSeqNo, endSeqNo int) {
-	msgs := session.store.GetMessages(beginSeqNo, endSeqNo)
-
+	//seqNum is the first sequence number not yet resent or gap filled
 	seqNum := beginSeqNo
-	nextSeqNum := seqNum
-
-	var msgBytes []byte
-	var ok bool
-	for {
-		if msgBytes, ok = <-msgs; !ok {
-			//gapfill for catch-up
-			if seqNum != nextSeqNum {
-				state.generateSequenceReset(session, seqNum, nextSeqNum)
-			}
 
-			return
+	for msgBytes := range session.store.GetMessages(beginSeqNo, endSeqNo) {
+		msg, err := parseMessage(msgBytes)
+		if err != nil {
+			logEventf(session.log, LogLevelError, LogCategoryStore, "Unable to parse stored message for resend: %v", err)
+			continue
 		}
 
-		msg, _ := parseMessage(msgBytes)
-
 		msgType := new(fix.StringValue)
 		msg.Header.GetField(tag.MsgType, msgType)
 
 		sentMessageSeqNum := new(fix.IntValue)
 		msg.Header.GetField(tag.MsgSeqNum, sentMessageSeqNum)
 
-		if fix.IsAdminMessageType(msgType.Value) {
-			nextSeqNum = sentMessageSeqNum.Value + 1
-		} else {
-
-			if seqNum != sentMessageSeqNum.Value {
-				state.generateSequenceReset(session, seqNum, sentMessageSeqNum.Value)
-			}
+		if fix.IsAdminMessageType(msgType.Value) || !session.allowResend(*msg) {
+			continue
+		}
 
-			session.resend(msg)
-			seqNum = sentMessageSeqNum.Value + 1
-			nextSeqNum = seqNum
+		if seqNum != sentMessageSeqNum.Value {
+			state.generateSequenceReset(session, seqNum, sentMessageSeqNum.Value)
 		}
+
+		session.resend(msg)
+		seqNum = sentMessageSeqNum.Value + 1
+	}
+
+	//gapfill for catch-up
+	if seqNum <= endSeqNo {
+		state.generateSequenceReset(session, seqNum, endSeqNo+1)
 	}
 }
 
@@ -181,7 +181,7 @@ func (state inSession) handleTestRequest(session *Session, msg Message) (nextSta
 
 	var testReq field.TestReqIDField
 	if err := msg.Body.Get(&testReq); err != nil {
-		session.log.OnEvent("Test Request with no testRequestID")
+		logEventf(session.log, LogLevelWarn, LogCategoryValidation, "Test Request with no testRequestID")
 	} else {
 		heartBt := NewMessageBuilder()
 		heartBt.Header().Set(field.NewMsgType("0"))
@@ -211,10 +211,21 @@ func (state inSession) processReject(session *Session, msg Message, rej MessageR
 		return state.doTargetTooLow(session, msg, TypedError)
 	case incorrectBeginString:
 		return state.initiateLogout(session, rej.Error())
+	case adminRejectError:
+		return state.doAdminReject(session, TypedError)
 	}
 
 	switch rej.RejectReason() {
-	case rejectReasonCompIDProblem, rejectReasonSendingTimeAccuracyProblem:
+	case rejectReasonSendingTimeAccuracyProblem:
+		session.doReject(msg, rej)
+
+		//an OrigSendingTime after SendingTime always logs out
+		if session.continueOnMaxLatency && rej.RefTagID() == nil {
+			session.store.IncrNextTargetMsgSeqNum()
+			return state
+		}
+		return state.initiateLogout(session, "")
+	case rejectReasonCompIDProblem:
 		session.doReject(msg, rej)
 		return state.initiateLogout(session, "")
 	default:
@@ -224,30 +235,45 @@ func (state inSession) processReject(session *Session, msg Message, rej MessageR
 	}
 }
 
+//doAdminReject responds to a message refused with NewRejectLogonError, NewDisconnectError, or NewIgnoreMessageError.
+func (state inSession) doAdminReject(session *Session, rej adminRejectError) (nextState sessionState) {
+	switch rej.action {
+	case adminRejectDisconnect:
+		logEventf(session.log, LogLevelError, LogCategorySession, "Disconnecting: %v", rej.Error())
+		return latentState{}
+	case adminRejectIgnore:
+		logEventf(session.log, LogLevelWarn, LogCategoryValidation, "Ignoring message: %v", rej.Error())
+		session.store.IncrNextTargetMsgSeqNum()
+		return state
+	}
+
+	return state.initiateLogout(session, rej.Error())
+}
+
 func (state inSession) doTargetTooLow(session *Session, msg Message, rej targetTooLow) (nextState sessionState) {
 	posDupFlag := new(fix.BooleanValue)
-	if err := msg.Header.GetField(tag.PossDupFlag, posDupFlag); err == nil && posDupFlag.Value {
-
-		origSendingTime := new(fix.UTCTimestampValue)
-		if err = msg.Header.GetField(tag.OrigSendingTime, origSendingTime); err != nil {
-			session.doReject(msg, requiredTagMissing(tag.OrigSendingTime))
-			return state
-		}
+	if err := msg.Header.GetField(tag.PossDupFlag, posDupFlag); err != nil || !posDupFlag.Value {
+		return state.initiateLogout(session, rej.Error())
+	}
 
-		sendingTime := new(fix.UTCTimestampValue)
-		msg.Header.GetField(tag.SendingTime, sendingTime)
+	if reject := session.checkOrigSendingTime(msg); reject != nil {
+		session.doReject(msg, reject)
 
-		if sendingTime.Value.Before(origSendingTime.Value) {
-			session.doReject(msg, sendingTimeAccuracyProblem())
+		if reject.RejectReason() == rejectReasonSendingTimeAccuracyProblem {
 			return state.initiateLogout(session, "")
 		}
+		return state
+	}
 
-		if appReject := session.fromCallback(msg); appReject != nil {
-			session.doReject(msg, appReject)
-			return state.initiateLogout(session, "")
-		}
-	} else {
-		return state.initiateLogout(session, rej.Error())
+	//the message was processed when first received
+	if !session.deliverPossDup {
+		logEventf(session.log, LogLevelInfo, LogCategorySession, "Ignoring duplicate message %v", rej.ReceivedTarget)
+		return state
+	}
+
+	if appReject := session.fromCallback(msg); appReject != nil {
+		session.doReject(msg, appReject)
+		return state.initiateLogout(session, "")
 	}
 
 	return state
@@ -255,7 +281,11 @@ func (state inSession) doTargetTooLow(session *Session, msg Message, rej targetT
 
 func (state *inSession) initiateLogout(session *Session, reason string) (nextState logoutState) {
 	state.generateLogoutWithReason(session, reason)
-	time.AfterFunc(time.Duration(2)*time.Second, func() { session.sessionEvent <- logoutTimeout })
+	if session.logoutTimer.pooled {
+		session.logoutTimer.Reset(time.Duration(2) * time.Second)
+	} else {
+		session.sessionClock().AfterFunc(time.Duration(2)*time.Second, func() { session.postEvent(logoutTimeout) })
+	}
 
 	return
 }
@@ -296,5 +326,5 @@ func (state *inSession) generateLogoutWithReason(session *Session, reason string
 	}
 
 	session.send(reply)
-	session.log.OnEvent("Sending logout response")
+	logEventf(session.log, LogLevelInfo, LogCategorySession, "Sending logout response")
 }
@@ -0,0 +1,191 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+	"time"
+)
+
+//vetoClient vetoes the resend of orders with the ClOrdID STALE.
+type vetoClient struct {
+	TestClient
+}
+
+func (e *vetoClient) ToResend(msg Message, sessionID SessionID) bool {
+	clOrdID := new(fix.StringValue)
+	msg.Body.GetField(tag.ClOrdID, clOrdID)
+	return clOrdID.Value != "STALE"
+}
+
+func TestInSession_HandleResendRequest(t *testing.T) {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	sent := make(chan []byte, 10)
+
+	session := &Session{
+		sessionID:   SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"},
+		header:      newHeaderTemplate(SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"}),
+		store:       store,
+		application: &vetoClient{},
+		messageOut:  sent,
+		log:         nullLog{},
+		stateTimer:  eventTimer{Task: func() {}},
+	}
+
+	stored := []string{
+		"35=A\00134=1\00149=TW\00152=20140511-23:10:34.000\00156=ISLD\00198=0\001108=30\001",
+		"35=D\00134=2\00149=TW\00152=20140511-23:10:35.000\00156=ISLD\00111=ID2\001",
+		"35=0\00134=3\00149=TW\00152=20140511-23:10:36.000\00156=ISLD\001",
+		"35=D\00134=4\00149=TW\00152=20140511-23:10:37.000\00156=ISLD\00111=STALE\001",
+		"35=D\00134=5\00149=TW\00152=20140511-23:10:38.000\00156=ISLD\00111=ID5\001",
+		"35=0\00134=6\00149=TW\00152=20140511-23:10:39.000\00156=ISLD\001",
+	}
+	for i, body := range stored {
+		store.SaveMessage(i+1, rawMessage(fix.BeginString_FIX42, body))
+		store.IncrNextSenderMsgSeqNum()
+	}
+
+	resendRequest := NewMessageBuilder()
+	resendRequest.Header().Set(field.NewBeginString(fix.BeginString_FIX42))
+	resendRequest.Header().Set(field.NewMsgType("2"))
+	resendRequest.Header().Set(field.NewMsgSeqNum(1))
+	resendRequest.Header().Set(field.NewSenderCompID("ISLD"))
+	resendRequest.Header().Set(field.NewTargetCompID("TW"))
+	resendRequest.Header().Set(fix.NewUTCTimestampField(tag.SendingTime, time.Now()))
+	resendRequest.Body().Set(field.NewBeginSeqNo(1))
+	resendRequest.Body().Set(field.NewEndSeqNo(0))
+	msgBytes, _ := resendRequest.Build()
+	msg, _ := parseMessage(msgBytes)
+
+	inSession{}.handleResendRequest(session, *msg)
+	close(sent)
+
+	var resent []string
+	for msgBytes := range sent {
+		msg, err := parseMessage(msgBytes)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		msgType, seqNum := new(fix.StringValue), new(fix.IntValue)
+		msg.Header.GetField(tag.MsgType, msgType)
+		msg.Header.GetField(tag.MsgSeqNum, seqNum)
+
+		possDup := new(fix.BooleanValue)
+		if err := msg.Header.GetField(tag.PossDupFlag, possDup); err != nil || !possDup.Value {
+			t.Errorf("expected PossDupFlag on resent message %d", seqNum.Value)
+		}
+
+		if !msg.Header.Has(tag.OrigSendingTime) {
+			t.Errorf("expected OrigSendingTime on resent message %d", seqNum.Value)
+		}
+
+		if msgType.Value == "4" {
+			newSeqNo := new(fix.IntValue)
+			msg.Body.GetField(tag.NewSeqNo, newSeqNo)
+			resent = append(resent, fmt.Sprintf("GapFill %d-%d", seqNum.Value, newSeqNo.Value))
+		} else {
+			resent = append(resent, fmt.Sprintf("%v %d", msgType.Value, seqNum.Value))
+		}
+	}
+
+	expected := []string{"GapFill 1-2", "D 2", "GapFill 3-5", "D 5", "GapFill 6-7"}
+	if fmt.Sprint(resent) != fmt.Sprint(expected) {
+		t.Errorf("expected %v got %v", expected, resent)
+	}
+}
+
+//possDupClient counts the app messages received.
+type possDupClient struct {
+	TestClient
+	fromAppCalled int
+}
+
+func (e *possDupClient) FromApp(msg Message, sessionID SessionID) MessageRejectError {
+	e.fromAppCalled++
+	return nil
+}
+
+func TestInSession_PossDup(t *testing.T) {
+	sendingTime := time.Now().UTC()
+
+	var tests = []struct {
+		seqNum          int
+		origSendingTime time.Duration
+		hasOrigTime     bool
+		deliverPossDup  bool
+		expectedState   sessionState
+		expectedFromApp int
+		expectReject    bool
+	}{
+		//duplicates of processed messages are ignored unless DeliverPossDup
+		{2, -time.Minute, true, false, inSession{}, 0, false},
+		{2, -time.Minute, true, true, inSession{}, 1, false},
+
+		//OrigSendingTime is required and must not be after SendingTime
+		{2, 0, false, true, inSession{}, 0, true},
+		{2, time.Minute, true, true, logoutState{}, 0, true},
+		{3, time.Minute, true, true, logoutState{}, 0, true},
+
+		//resent messages not yet processed are delivered
+		{3, -time.Minute, true, false, inSession{}, 1, false},
+	}
+
+	for i, test := range tests {
+		store, _ := NewMemoryStoreFactory().Create(SessionID{})
+		store.IncrNextTargetMsgSeqNum()
+		store.IncrNextTargetMsgSeqNum()
+
+		app := &possDupClient{}
+		session := &Session{
+			sessionID:      SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"},
+			header:         newHeaderTemplate(SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"}),
+			store:          store,
+			application:    app,
+			messageOut:     make(chan []byte, 10),
+			sessionEvent:   make(chan event, 10),
+			log:            nullLog{},
+			stateTimer:     eventTimer{Task: func() {}},
+			deliverPossDup: test.deliverPossDup,
+		}
+
+		builder := NewMessageBuilder()
+		builder.Header().Set(field.NewBeginString(fix.BeginString_FIX42))
+		builder.Header().Set(field.NewMsgType("D"))
+		builder.Header().Set(field.NewMsgSeqNum(test.seqNum))
+		builder.Header().Set(field.NewSenderCompID("ISLD"))
+		builder.Header().Set(field.NewTargetCompID("TW"))
+		builder.Header().Set(field.NewPossDupFlag(true))
+		builder.Header().Set(fix.NewUTCTimestampField(tag.SendingTime, sendingTime))
+		if test.hasOrigTime {
+			builder.Header().Set(fix.NewUTCTimestampField(tag.OrigSendingTime, sendingTime.Add(test.origSendingTime)))
+		}
+		msgBytes, _ := builder.Build()
+		msg, _ := parseMessage(msgBytes)
+
+		nextState := inSession{}.FixMsgIn(session, *msg)
+
+		if fmt.Sprintf("%T", nextState) != fmt.Sprintf("%T", test.expectedState) {
+			t.Errorf("%v: Expected state %T got %T", i, test.expectedState, nextState)
+		}
+
+		if app.fromAppCalled != test.expectedFromApp {
+			t.Errorf("%v: Expected FromApp called %v times got %v", i, test.expectedFromApp, app.fromAppCalled)
+		}
+
+		rejected := false
+		for len(session.messageOut) > 0 {
+			sent, _ := parseMessage(<-session.messageOut)
+			var msgType field.MsgTypeField
+			if sent.Header.Get(&msgType); msgType.Value == "3" {
+				rejected = true
+			}
+		}
+
+		if rejected != test.expectReject {
+			t.Errorf("%v: Expected reject %v got %v", i, test.expectReject, rejected)
+		}
+	}
+}
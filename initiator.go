@@ -1,9 +1,12 @@
 package quickfix
 
 import (
-	"fmt"
+	"context"
+	"crypto/tls"
 	"github.com/quickfixgo/quickfix/config"
 	"net"
+	"sync"
+	"time"
 )
 
 //Initiator initiates connections and processes messages for all sessions.
@@ -14,36 +17,222 @@ type Initiator struct {
 	storeFactory    MessageStoreFactory
 	logFactory      LogFactory
 	globalLog       Log
+	stopChan        chan interface{}
+	sessionLock     sync.Mutex
+	connections     connectionSet
+
+	//dialer connects the sessions, nil to connect over the network of the host
+	dialer Dialer
+
+	//endpoints are those of the sessions started, their TLS configuration replaced by ReloadSettings
+	endpoints map[SessionID]*endpoints
 }
 
 //Start Initiator.
 func (i *Initiator) Start() error {
+	i.sessionLock.Lock()
+	defer i.sessionLock.Unlock()
+
+	i.stopChan = make(chan interface{})
+	i.connections.open()
 
 	for sessionID, s := range i.sessionSettings {
-		socketConnectHost, err := s.Setting(config.SocketConnectHost)
-		if err != nil {
-			return fmt.Errorf("error on SocketConnectHost: %v", err)
+		if err := i.startSession(sessionID, s); err != nil {
+			return err
 		}
+	}
 
-		socketConnectPort, err := s.IntSetting(config.SocketConnectPort)
-		if err != nil {
-			return fmt.Errorf("error on SocketConnectPort: %v", err)
-		}
+	return nil
+}
 
-		conn, err := net.Dial("tcp", fmt.Sprintf("%v:%v", socketConnectHost, socketConnectPort))
+//startSession connects the session, or for a scheduled session starts connecting whenever the schedule is active.
+func (i *Initiator) startSession(sessionID SessionID, s *SessionSettings) error {
+	endpoints, err := newEndpoints(s)
+	if err != nil {
+		return err
+	}
+
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return err
+	}
+	endpoints.dialer = i.dialer
+	endpoints.tlsConfig = clientTLSConfig(session, endpoints.tlsConfig)
+	i.endpoints[sessionID] = endpoints
+
+	if session.sessionSchedule() != nil {
+		policy, err := newReconnectPolicy(s)
 		if err != nil {
 			return err
 		}
 
-		go handleInitiatorConnection(conn, i.globalLog, sessionID)
+		go i.runScheduled(session, endpoints, policy, i.stopChan)
+		return nil
 	}
 
+	conn, ep, err := endpoints.dial(i.globalLog, sessionID)
+	if err != nil {
+		return err
+	}
+
+	session.onEndpointConnect(ep)
+	go i.handleConnection(conn, session)
 	return nil
 }
 
-//Stop Initiator.
+//clientTLSConfig returns tlsConfig presenting the certificates of the ClientCertificateProvider of the Application of session, if implemented.
+func clientTLSConfig(session *Session, tlsConfig *tls.Config) *tls.Config {
+	if provider, ok := session.application.(ClientCertificateProvider); ok && tlsConfig != nil {
+		sessionID := session.sessionID
+		tlsConfig.GetClientCertificate = func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return provider.ClientCertificate(sessionID, info)
+		}
+	}
+
+	return tlsConfig
+}
+
+//handleConnection runs the session on conn, tracking conn until disconnected.
+func (i *Initiator) handleConnection(conn net.Conn, session *Session) {
+	if !i.connections.add(conn) {
+		conn.Close()
+		return
+	}
+	defer i.connections.remove(conn)
+
+	handleInitiatorConnection(conn, i.globalLog, session.sessionID)
+}
+
+//runScheduled connects the session whenever its schedule is active, reconnecting after a disconnect according to policy, until the Initiator is stopped, the session removed, or the policy exhausted.
+func (i *Initiator) runScheduled(session *Session, endpoints *endpoints, policy reconnectPolicy, stopChan chan interface{}) {
+	failures := 0
+	for {
+		wait := time.Second
+
+		if session.sessionSchedule().IsInRange(session.now()) {
+			if conn, ep, err := endpoints.dial(i.globalLog, session.sessionID); err != nil {
+				i.globalLog.OnEventf("Failed to connect %v: %v", session.sessionID, err)
+
+				failures++
+				if policy.exhausted(failures) {
+					session.onReconnectAbandoned(failures, err)
+					return
+				}
+			} else {
+				failures = 0
+				session.onEndpointConnect(ep)
+				i.handleConnection(conn, session)
+			}
+
+			wait = policy.delay(failures)
+		}
+
+		select {
+		case <-stopChan:
+			return
+		case <-session.stop:
+			return
+		case <-session.sessionClock().After(wait):
+		}
+	}
+}
+
+//Stop Initiator, logging out connected sessions. Sessions are no longer connected.
 func (i *Initiator) Stop() {
+	i.Shutdown(context.Background())
+}
+
+//Shutdown stops the Initiator, sessions are no longer connected.
+//Connected sessions send messages delayed by the throttle, then Logout, and are disconnected once the counterparty replies or the logout times out.
+//If ctx is done first, the remaining connections are closed and the error of ctx returned.
+func (i *Initiator) Shutdown(ctx context.Context) error {
+	i.sessionLock.Lock()
+	if i.stopChan != nil {
+		close(i.stopChan)
+		i.stopChan = nil
+	}
+	i.connections.close()
 
+	for sessionID := range i.sessionSettings {
+		if session, err := LookupSession(sessionID); err == nil {
+			session.requestShutdown()
+		}
+	}
+	i.sessionLock.Unlock()
+
+	err := i.connections.wait(ctx)
+	if flushable, ok := i.logFactory.(FlushableLogFactory); ok {
+		flushable.Flush()
+	}
+
+	return err
+}
+
+//AddSession creates a session from sessionSettings, overlaying the global settings of the Initiator.
+//If the Initiator is running, the session is connected.
+func (i *Initiator) AddSession(sessionSettings *SessionSettings) (SessionID, error) {
+	i.sessionLock.Lock()
+	defer i.sessionLock.Unlock()
+
+	sessionID, err := i.settings.AddSession(sessionSettings)
+	if err != nil {
+		return sessionID, err
+	}
+
+	s := i.settings.sessionSettingsFor(sessionID)
+	if err := i.createSession(sessionID, s); err != nil {
+		i.settings.RemoveSession(sessionID)
+		return sessionID, err
+	}
+	i.sessionSettings[sessionID] = s
+
+	if i.stopChan != nil {
+		return sessionID, i.startSession(sessionID, s)
+	}
+
+	return sessionID, nil
+}
+
+//RemoveSession logs out and disconnects the session, it is no longer connected by the Initiator.
+func (i *Initiator) RemoveSession(sessionID SessionID) error {
+	i.sessionLock.Lock()
+	defer i.sessionLock.Unlock()
+
+	if err := i.settings.RemoveSession(sessionID); err != nil {
+		return err
+	}
+
+	return i.removeSession(sessionID)
+}
+
+//removeSession logs out and disconnects the session removed from the settings.
+func (i *Initiator) removeSession(sessionID SessionID) error {
+	delete(i.sessionSettings, sessionID)
+	delete(i.endpoints, sessionID)
+
+	return unregisterSession(sessionID)
+}
+
+//createSession validates the settings of the session and creates it.
+func (i *Initiator) createSession(sessionID SessionID, s *SessionSettings) error {
+	//fail fast
+	if ok := s.HasSetting(config.SocketConnectHost); !ok {
+		return requiredConfigurationMissing(config.SocketConnectHost)
+	}
+
+	if host, _ := s.Setting(config.SocketConnectHost); !isUnixSocket(host) && !s.HasSetting(config.SocketConnectPort) {
+		return requiredConfigurationMissing(config.SocketConnectPort)
+	}
+
+	if _, err := newEndpoints(s); err != nil {
+		return err
+	}
+
+	if _, err := newReconnectPolicy(s); err != nil {
+		return err
+	}
+
+	return createSession(sessionID, i.storeFactory, s, i.logFactory, i.app)
 }
 
 //NewInitiator creates and initializes a new Initiator.
@@ -54,6 +243,7 @@ func NewInitiator(app Application, storeFactory MessageStoreFactory, appSettings
 	i.settings = appSettings
 	i.sessionSettings = appSettings.SessionSettings()
 	i.logFactory = logFactory
+	i.endpoints = make(map[SessionID]*endpoints)
 
 	var err error
 	i.globalLog, err = logFactory.Create()
@@ -62,18 +252,7 @@ func NewInitiator(app Application, storeFactory MessageStoreFactory, appSettings
 	}
 
 	for sessionID, s := range i.sessionSettings {
-
-		//fail fast
-		if ok := s.HasSetting(config.SocketConnectHost); !ok {
-			return nil, requiredConfigurationMissing(config.SocketConnectHost)
-		}
-
-		if ok := s.HasSetting(config.SocketConnectPort); !ok {
-			return nil, requiredConfigurationMissing(config.SocketConnectPort)
-		}
-
-		err = createSession(sessionID, storeFactory, s, logFactory, app)
-		if err != nil {
+		if err = i.createSession(sessionID, s); err != nil {
 			return nil, err
 		}
 	}
@@ -0,0 +1,268 @@
+package quickfix
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//failoverPolicy determines the endpoint an initiated session tries first on each connection attempt.
+type failoverPolicy int
+
+const (
+	//failoverPriority tries the primary endpoint first, then each backup in order.
+	failoverPriority failoverPolicy = iota
+
+	//failoverRoundRobin tries the endpoint after the one last connected first.
+	failoverRoundRobin
+)
+
+//parseFailoverPolicy maps the SocketConnectFailover setting to a failoverPolicy.
+func parseFailoverPolicy(setting string) (failoverPolicy, error) {
+	switch setting {
+	case "Priority":
+		return failoverPriority, nil
+	case "RoundRobin":
+		return failoverRoundRobin, nil
+	}
+
+	return failoverPriority, fmt.Errorf("invalid SocketConnectFailover %v, expected Priority or RoundRobin", setting)
+}
+
+//endpoint is a host and port an initiated session connects to.
+type endpoint struct {
+	host string
+	port int
+
+	//resolved are the cached addresses of host in the order tried, empty until resolved.
+	resolved []string
+}
+
+func (e endpoint) String() string {
+	if isUnixSocket(e.host) {
+		return e.host
+	}
+
+	return net.JoinHostPort(e.host, strconv.Itoa(e.port))
+}
+
+//endpoints are the primary and backup endpoints of an initiated session, configured by SocketConnectHost and SocketConnectPort, then SocketConnectHost1 and SocketConnectPort1, SocketConnectHost2 and SocketConnectPort2, and so on.
+//A host of unix:<path> connects to the unix domain socket at path, without a port, verifying the SocketServerName of the acceptor if secured by TLS.
+type endpoints struct {
+	endpoints []endpoint
+	policy    failoverPolicy
+
+	//resolveEachAttempt looks up the host on each connection attempt, otherwise the addresses found are reused.
+	resolveEachAttempt bool
+
+	//preference is the address family tried first when a host resolves to both IPv6 and IPv4 addresses.
+	preference addressPreference
+
+	//attemptDelay is the time an address is tried before the next address of the host is tried in parallel.
+	attemptDelay time.Duration
+
+	//attemptTimeout limits each connection attempt if positive.
+	attemptTimeout time.Duration
+
+	//next is the index of the endpoint tried first by round robin failover.
+	next int
+
+	//tlsConfig secures the connections, nil to connect without TLS. tlsLock guards replacing it while connecting.
+	tlsConfig *tls.Config
+	tlsLock   sync.Mutex
+
+	//dialer opens the connections, nil to connect over the network of the host.
+	dialer Dialer
+}
+
+//newEndpoints returns the endpoints configured in settings.
+func newEndpoints(settings *SessionSettings) (*endpoints, error) {
+	e := &endpoints{attemptDelay: defaultConnectAttemptDelay}
+
+	for n := 0; ; n++ {
+		hostSetting, portSetting := config.SocketConnectHost, config.SocketConnectPort
+		if n > 0 {
+			hostSetting = fmt.Sprintf("%v%v", config.SocketConnectHost, n)
+			portSetting = fmt.Sprintf("%v%v", config.SocketConnectPort, n)
+
+			if !settings.HasSetting(hostSetting) {
+				break
+			}
+		}
+
+		host, err := settings.Setting(hostSetting)
+		if err != nil {
+			return nil, fmt.Errorf("error on %v: %v", hostSetting, err)
+		}
+
+		if isUnixSocket(host) {
+			e.endpoints = append(e.endpoints, endpoint{host: host})
+			continue
+		}
+
+		port, err := settings.IntSetting(portSetting)
+		if err != nil {
+			return nil, fmt.Errorf("error on %v: %v", portSetting, err)
+		}
+
+		e.endpoints = append(e.endpoints, endpoint{host: host, port: port})
+	}
+
+	if settings.HasSetting(config.SocketConnectFailover) {
+		policy, err := settings.Setting(config.SocketConnectFailover)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.policy, err = parseFailoverPolicy(policy); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.HasSetting(config.SocketConnectResolveEachAttempt) {
+		var err error
+		if e.resolveEachAttempt, err = settings.BoolSetting(config.SocketConnectResolveEachAttempt); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.HasSetting(config.SocketConnectAddressPreference) {
+		preference, err := settings.Setting(config.SocketConnectAddressPreference)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.preference, err = parseAddressPreference(preference); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value *time.Duration
+	}{
+		{config.SocketConnectAttemptDelay, &e.attemptDelay},
+		{config.SocketConnectAttemptTimeout, &e.attemptTimeout},
+	} {
+		if !settings.HasSetting(setting.name) {
+			continue
+		}
+
+		millis, err := settings.IntSetting(setting.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if millis < 0 {
+			return nil, fmt.Errorf("%v must not be negative", setting.name)
+		}
+		*setting.value = time.Duration(millis) * time.Millisecond
+	}
+
+	var err error
+	if e.tlsConfig, err = loadTLSConfig(settings); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+//dial tries each endpoint once, in the order of the failover policy, and returns the connection to the first that accepts.
+//Each failed attempt is logged to log.
+func (e *endpoints) dial(log Log, sessionID SessionID) (net.Conn, endpoint, error) {
+	first := 0
+	if e.policy == failoverRoundRobin {
+		first = e.next
+	}
+
+	var err error
+	for n := range e.endpoints {
+		index := (first + n) % len(e.endpoints)
+
+		var conn net.Conn
+		if conn, err = e.dialEndpoint(index); err != nil {
+			logEventf(log, LogLevelWarn, LogCategoryTransport, "Failed to connect %v to %v: %v", sessionID, e.endpoints[index], err)
+			continue
+		}
+
+		e.next = (index + 1) % len(e.endpoints)
+		return conn, e.endpoints[index], nil
+	}
+
+	return nil, endpoint{}, err
+}
+
+//dialEndpoint connects to the endpoint at index and completes the TLS handshake if configured. Unless already resolved, the host is resolved
+//and its addresses are tried as in RFC 8305, alternating between IPv6 and IPv4 addresses in the order of the address preference.
+//A custom dialer is passed the host unresolved.
+func (e *endpoints) dialEndpoint(index int) (net.Conn, error) {
+	ep := &e.endpoints[index]
+
+	network, address := transportAddress(ep.String())
+	addresses, dialer := []string{address}, e.dialer
+	if dialer == nil {
+		dialer = defaultDialer
+	}
+
+	if e.dialer == nil && network == "tcp" {
+		resolved := ep.resolved
+		if len(resolved) == 0 || e.resolveEachAttempt {
+			addrs, err := net.LookupHost(ep.host)
+			if err != nil {
+				return nil, err
+			}
+
+			resolved = sortAddresses(addrs, e.preference)
+			if !e.resolveEachAttempt {
+				ep.resolved = resolved
+			}
+		}
+
+		addresses = make([]string, len(resolved))
+		for i, addr := range resolved {
+			addresses[i] = net.JoinHostPort(addr, strconv.Itoa(ep.port))
+		}
+	}
+
+	conn, err := dialParallel(context.Background(), dialer, network, addresses, e.attemptDelay, e.attemptTimeout)
+	if err != nil {
+		return conn, err
+	}
+
+	tlsConfig := e.currentTLSConfig()
+	if tlsConfig == nil {
+		return conn, nil
+	}
+
+	return tlsClient(conn, tlsConfig, ep.host)
+}
+
+//currentTLSConfig returns the TLS configuration of the connections opened from now on.
+func (e *endpoints) currentTLSConfig() *tls.Config {
+	e.tlsLock.Lock()
+	defer e.tlsLock.Unlock()
+
+	return e.tlsConfig
+}
+
+//setTLSConfig replaces the TLS configuration of the connections opened from now on.
+func (e *endpoints) setTLSConfig(tlsConfig *tls.Config) {
+	e.tlsLock.Lock()
+	defer e.tlsLock.Unlock()
+
+	e.tlsConfig = tlsConfig
+}
+
+//onEndpointConnect reports the endpoint an initiated session connected to.
+func (s *Session) onEndpointConnect(ep endpoint) {
+	logEventf(s.log, LogLevelInfo, LogCategoryTransport, "Connected to %v", ep)
+
+	if listener, ok := s.application.(EndpointListener); ok {
+		listener.OnEndpointConnect(s.sessionID, ep.String())
+	}
+}
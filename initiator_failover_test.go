@@ -0,0 +1,109 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"testing"
+)
+
+//newTestEndpoint returns the port of a listener accepting connections, or of a port refusing connections if closed.
+func newTestEndpoint(t *testing.T, closed bool) (net.Listener, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	if closed {
+		listener.Close()
+	}
+
+	return listener, port
+}
+
+func TestEndpoints_Settings(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set(config.SocketConnectHost, "primary")
+	settings.Set(config.SocketConnectPort, "5001")
+	settings.Set("SocketConnectHost1", "backup")
+	settings.Set("SocketConnectPort1", "5002")
+	settings.Set("SocketConnectHost3", "ignored")
+	settings.Set("SocketConnectPort3", "5003")
+	settings.Set(config.SocketConnectFailover, "RoundRobin")
+
+	e, err := newEndpoints(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints got %v", len(e.endpoints))
+	}
+
+	if e.endpoints[0].String() != "primary:5001" || e.endpoints[1].String() != "backup:5002" {
+		t.Errorf("Unexpected endpoints %v", e.endpoints)
+	}
+
+	if e.policy != failoverRoundRobin {
+		t.Error("Expected round robin failover")
+	}
+
+	settings.Set("SocketConnectHost2", "missing port")
+	if _, err := newEndpoints(settings); err == nil {
+		t.Error("Expected error for backup host without port")
+	}
+
+	settings.Set(config.SocketConnectFailover, "Random")
+	if _, err := newEndpoints(settings); err == nil {
+		t.Error("Expected error for invalid failover policy")
+	}
+}
+
+func TestEndpoints_Dial(t *testing.T) {
+	_, refused := newTestEndpoint(t, true)
+	listener, accepted := newTestEndpoint(t, false)
+	defer listener.Close()
+
+	var tests = []struct {
+		policy        failoverPolicy
+		expectedFirst int
+	}{
+		{failoverPriority, 0},
+		{failoverRoundRobin, 2},
+	}
+
+	for _, test := range tests {
+		e := &endpoints{policy: test.policy, endpoints: []endpoint{
+			{host: "127.0.0.1", port: refused},
+			{host: "127.0.0.1", port: accepted},
+			{host: "127.0.0.1", port: refused},
+		}}
+
+		conn, ep, err := e.dial(nullLog{}, SessionID{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		conn.Close()
+
+		if ep.port != accepted {
+			t.Errorf("Expected failover to port %v got %v", accepted, ep.port)
+		}
+
+		if len(e.endpoints[1].resolved) == 0 {
+			t.Error("Expected resolved address to be cached")
+		}
+
+		first := 0
+		if test.policy == failoverRoundRobin {
+			first = e.next
+		}
+		if first != test.expectedFirst {
+			t.Errorf("Expected next attempt to start with endpoint %v got %v", test.expectedFirst, first)
+		}
+	}
+
+	e := &endpoints{endpoints: []endpoint{{host: "127.0.0.1", port: refused}}}
+	if _, _, err := e.dial(nullLog{}, SessionID{}); err == nil {
+		t.Error("Expected error when no endpoint accepts")
+	}
+}
@@ -0,0 +1,126 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"sync"
+)
+
+//An Interceptor inspects, mutates, or vetoes messages of a session before the corresponding Application callback.
+//Interceptors added with AddGlobalInterceptor are called for every session, before those added with AddInterceptor, each in the order added.
+type Interceptor interface {
+	//ToAdmin is called before an admin message is sent. Returning an error vetoes the send.
+	ToAdmin(msgBuilder MessageBuilder, sessionID SessionID) error
+
+	//ToApp is called before an app message is sent. Returning an error vetoes the send, the error is returned to the sender.
+	ToApp(msgBuilder MessageBuilder, sessionID SessionID) error
+
+	//FromAdmin is called when an admin message is received. Returning a MessageRejectError rejects the message.
+	FromAdmin(msg Message, sessionID SessionID) MessageRejectError
+
+	//FromApp is called when an app message is received. Returning a MessageRejectError rejects the message.
+	FromApp(msg Message, sessionID SessionID) MessageRejectError
+}
+
+//NullInterceptor passes every message, it may be embedded by an Interceptor concerned with only some messages.
+type NullInterceptor struct{}
+
+//ToAdmin implements Interceptor.
+func (NullInterceptor) ToAdmin(msgBuilder MessageBuilder, sessionID SessionID) error { return nil }
+
+//ToApp implements Interceptor.
+func (NullInterceptor) ToApp(msgBuilder MessageBuilder, sessionID SessionID) error { return nil }
+
+//FromAdmin implements Interceptor.
+func (NullInterceptor) FromAdmin(msg Message, sessionID SessionID) MessageRejectError { return nil }
+
+//FromApp implements Interceptor.
+func (NullInterceptor) FromApp(msg Message, sessionID SessionID) MessageRejectError { return nil }
+
+//interceptorChain is an ordered list of interceptors, safe for concurrent use.
+type interceptorChain struct {
+	lock         sync.RWMutex
+	interceptors []Interceptor
+}
+
+func (c *interceptorChain) add(interceptor Interceptor) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.interceptors = append(c.interceptors, interceptor)
+}
+
+func (c *interceptorChain) list() []Interceptor {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.interceptors
+}
+
+var globalInterceptors interceptorChain
+
+//AddGlobalInterceptor adds interceptor to every session.
+func AddGlobalInterceptor(interceptor Interceptor) {
+	globalInterceptors.add(interceptor)
+}
+
+//AddInterceptor adds interceptor to the session with sessionID.
+func AddInterceptor(sessionID SessionID, interceptor Interceptor) error {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	session.interceptors.add(interceptor)
+	return nil
+}
+
+//interceptorsFor returns the interceptors of the session, global interceptors first.
+func (s *Session) interceptorsFor() []Interceptor {
+	global, own := globalInterceptors.list(), s.interceptors.list()
+	if len(own) == 0 {
+		return global
+	}
+
+	return append(append([]Interceptor{}, global...), own...)
+}
+
+//interceptOutbound calls the interceptors for builder before it is sent, returning the error of the first to veto.
+func (s *Session) interceptOutbound(builder MessageBuilder, isAdmin bool) error {
+	for _, interceptor := range s.interceptorsFor() {
+		var err error
+		if isAdmin {
+			err = interceptor.ToAdmin(builder, s.sessionID)
+		} else {
+			err = interceptor.ToApp(builder, s.sessionID)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//interceptInbound calls the interceptors for msg once received, returning the reject of the first to veto.
+func (s *Session) interceptInbound(msg Message) MessageRejectError {
+	msgType := new(fix.StringValue)
+	msg.Header.GetField(tag.MsgType, msgType)
+	isAdmin := fix.IsAdminMessageType(msgType.Value)
+
+	for _, interceptor := range s.interceptorsFor() {
+		var reject MessageRejectError
+		if isAdmin {
+			reject = interceptor.FromAdmin(msg, s.sessionID)
+		} else {
+			reject = interceptor.FromApp(msg, s.sessionID)
+		}
+
+		if reject != nil {
+			return reject
+		}
+	}
+
+	return nil
+}
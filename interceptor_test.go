@@ -0,0 +1,76 @@
+package quickfix
+
+import (
+	"errors"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+)
+
+//testInterceptor records the order it is called in, stamping Account on outbound app messages and vetoing those with a ClOrdID of "veto".
+type testInterceptor struct {
+	NullInterceptor
+	name  string
+	calls *[]string
+}
+
+func (i testInterceptor) ToApp(msgBuilder MessageBuilder, sessionID SessionID) error {
+	*i.calls = append(*i.calls, i.name)
+
+	clOrdID := new(fix.StringValue)
+	if msgBuilder.Body().GetField(tag.ClOrdID, clOrdID); clOrdID.Value == "veto" {
+		return errors.New("vetoed by " + i.name)
+	}
+
+	msgBuilder.Body().Set(fix.NewStringField(tag.Account, i.name))
+	return nil
+}
+
+func (i testInterceptor) FromApp(msg Message, sessionID SessionID) MessageRejectError {
+	*i.calls = append(*i.calls, i.name)
+	return NewBusinessMessageRejectError("rejected by "+i.name, 0, nil)
+}
+
+func TestSession_Interceptors(t *testing.T) {
+	defer func() {
+		globalInterceptors.lock.Lock()
+		globalInterceptors.interceptors = nil
+		globalInterceptors.lock.Unlock()
+	}()
+
+	var calls []string
+	s := newTestSendQueueSession(0, sendQueueOverflowError)
+	s.interceptors.add(testInterceptor{name: "session", calls: &calls})
+	AddGlobalInterceptor(testInterceptor{name: "global", calls: &calls})
+
+	if err := s.send(newSendQueueTestMessage("1")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(calls) != 2 || calls[0] != "global" || calls[1] != "session" {
+		t.Errorf("Expected global then session interceptor got %v", calls)
+	}
+
+	msg, _ := parseMessage(<-s.messageOut)
+	account := new(fix.StringValue)
+	if msg.Body.GetField(tag.Account, account); account.Value != "session" {
+		t.Errorf("Expected Account stamped by interceptors got %v", account.Value)
+	}
+
+	if err := s.send(newSendQueueTestMessage("veto")); err == nil || err.Error() != "vetoed by global" {
+		t.Errorf("Expected veto by first interceptor got %v", err)
+	}
+
+	if len(s.messageOut) != 0 || s.store.NextSenderMsgSeqNum() != 2 {
+		t.Error("Expected vetoed message not to be sent")
+	}
+
+	calls = nil
+	if reject := s.fromCallback(*msg); reject == nil || reject.Error() != "rejected by global" {
+		t.Errorf("Expected reject by first interceptor got %v", reject)
+	}
+
+	if len(calls) != 1 {
+		t.Errorf("Expected interceptors after a reject not to be called got %v", calls)
+	}
+}
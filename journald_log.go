@@ -0,0 +1,172 @@
+package quickfix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+//Fields of the journal entries written by the journald LogFactory, in addition to MESSAGE, PRIORITY and SYSLOG_IDENTIFIER.
+const (
+	JournaldFieldSession   = "FIX_SESSION"
+	JournaldFieldDirection = "FIX_DIRECTION"
+	JournaldFieldMsgType   = "FIX_MSG_TYPE"
+	JournaldFieldSeqNum    = "FIX_SEQ_NUM"
+	JournaldFieldCategory  = "FIX_CATEGORY"
+)
+
+//JournaldLogFactory is a LogFactory writing the messages and events of sessions to systemd-journald over its native protocol.
+//Entries carry the session, and the direction, MsgType and MsgSeqNum of messages, as fields.
+type JournaldLogFactory struct {
+	socket     string
+	identifier string
+
+	lock sync.Mutex
+	conn net.Conn
+
+	//OnError, if set, is called each time an entry cannot be written. The entry is discarded.
+	OnError func(err error)
+}
+
+//NewJournaldLogFactory returns a JournaldLogFactory writing to the journal socket JournaldSocket, /run/systemd/journal/socket by default.
+//Entries are identified by JournaldIdentifier, quickfix by default. An entry must fit a datagram of the socket.
+func NewJournaldLogFactory(settings *Settings) (*JournaldLogFactory, error) {
+	globalSettings := settings.GlobalSettings()
+	f := &JournaldLogFactory{socket: defaultJournaldSocket, identifier: defaultSyslogAppName}
+
+	if globalSettings.HasSetting(config.JournaldSocket) {
+		f.socket, _ = globalSettings.Setting(config.JournaldSocket)
+	}
+
+	if globalSettings.HasSetting(config.JournaldIdentifier) {
+		f.identifier, _ = globalSettings.Setting(config.JournaldIdentifier)
+	}
+
+	return f, nil
+}
+
+//Create returns the global log, its entries without a session.
+func (f *JournaldLogFactory) Create() (Log, error) {
+	return journaldLog{factory: f}, nil
+}
+
+//CreateSessionLog returns the log of sessionID.
+func (f *JournaldLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	return journaldLog{factory: f, session: sessionID.String()}, nil
+}
+
+//Close closes the connection to the journal.
+func (f *JournaldLogFactory) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.conn == nil {
+		return nil
+	}
+
+	err := f.conn.Close()
+	f.conn = nil
+	return err
+}
+
+//write writes entry, redialing once if the connection fails.
+func (f *JournaldLogFactory) write(entry []byte) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if f.conn == nil {
+			if f.conn, err = net.Dial("unixgram", f.socket); err != nil {
+				continue
+			}
+		}
+
+		if _, err = f.conn.Write(entry); err == nil {
+			return
+		}
+
+		f.conn.Close()
+		f.conn = nil
+	}
+
+	if f.OnError != nil {
+		f.OnError(fmt.Errorf("cannot write to journal %v: %v", f.socket, err))
+	}
+}
+
+type journaldLog struct {
+	factory *JournaldLogFactory
+	session string
+}
+
+func (l journaldLog) OnIncoming(msg string) {
+	l.onMessage("incoming", msg)
+}
+
+func (l journaldLog) OnOutgoing(msg string) {
+	l.onMessage("outgoing", msg)
+}
+
+func (l journaldLog) onMessage(direction, msg string) {
+	fields := []string{JournaldFieldDirection, direction}
+
+	msgType, seqNum := logHeader(msg)
+	if msgType != nil {
+		fields = append(fields, JournaldFieldMsgType, msgType.Value)
+	}
+
+	if seqNum != nil {
+		fields = append(fields, JournaldFieldSeqNum, strconv.Itoa(seqNum.Value))
+	}
+
+	l.write(syslogInformational, fields, msg)
+}
+
+func (l journaldLog) OnEvent(msg string) {
+	l.write(syslogInformational, nil, msg)
+}
+
+func (l journaldLog) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+//OnLeveledEvent writes msg at the priority of level, with its category as a field.
+func (l journaldLog) OnLeveledEvent(level LogLevel, category LogCategory, msg string) {
+	l.write(syslogSeverity(level), []string{JournaldFieldCategory, string(category)}, msg)
+}
+
+//write serializes an entry of msg with the session and fields, name value pairs.
+func (l journaldLog) write(priority int, fields []string, msg string) {
+	fields = append([]string{"MESSAGE", msg, "PRIORITY", strconv.Itoa(priority), "SYSLOG_IDENTIFIER", l.factory.identifier}, fields...)
+	if l.session != "" {
+		fields = append(fields, JournaldFieldSession, l.session)
+	}
+
+	entry := bytes.NewBuffer(getBuffer(len(msg) + 256))
+	for i := 0; i+1 < len(fields); i += 2 {
+		writeJournaldField(entry, fields[i], fields[i+1])
+	}
+
+	l.factory.write(entry.Bytes())
+	putBuffer(entry.Bytes())
+}
+
+//writeJournaldField writes name=value, or name, the length of value and value for values spanning lines.
+func writeJournaldField(entry *bytes.Buffer, name, value string) {
+	if !strings.Contains(value, "\n") {
+		entry.WriteString(name + "=" + value + "\n")
+		return
+	}
+
+	entry.WriteString(name + "\n")
+	binary.Write(entry, binary.LittleEndian, uint64(len(value)))
+	entry.WriteString(value + "\n")
+}
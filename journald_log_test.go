@@ -0,0 +1,65 @@
+package quickfix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestJournaldLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journald")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := path.Join(dir, "socket")
+	conn, err := net.ListenPacket("unixgram", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.JournaldSocket, socket)
+	factory, err := NewJournaldLogFactory(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer factory.Close()
+
+	log, _ := factory.CreateSessionLog(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"})
+	msg := "8=FIX.4.2\x019=20\x0135=D\x0134=7\x0158=two\nlines\x01"
+	log.OnOutgoing(msg)
+	logEventf(log, LogLevelError, LogCategoryStore, "Cannot flush store")
+
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var message bytes.Buffer
+	message.WriteString("MESSAGE\n")
+	binary.Write(&message, binary.LittleEndian, uint64(len(msg)))
+	message.WriteString(msg + "\n")
+	message.WriteString("PRIORITY=6\nSYSLOG_IDENTIFIER=quickfix\nFIX_DIRECTION=outgoing\nFIX_MSG_TYPE=D\nFIX_SEQ_NUM=7\nFIX_SESSION=FIX.4.2:TW->ISLD\n")
+	if !bytes.Equal(buf[:n], message.Bytes()) {
+		t.Errorf("Expected %q got %q", message.Bytes(), buf[:n])
+	}
+
+	n, _, err = conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "MESSAGE=Cannot flush store\nPRIORITY=3\nSYSLOG_IDENTIFIER=quickfix\nFIX_CATEGORY=Store\nFIX_SESSION=FIX.4.2:TW->ISLD\n"
+	if string(buf[:n]) != expected {
+		t.Errorf("Expected %q got %q", expected, buf[:n])
+	}
+}
@@ -0,0 +1,292 @@
+//Package kafkalog provides a QuickFIX/Go LogFactory publishing the messages and events of sessions to Kafka.
+package kafkalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/segmentio/kafka-go"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+
+	//globalKey is the key of the records of the global log
+	globalKey = "GLOBAL"
+
+	//HeaderDirection is the header of message records, incoming or outgoing.
+	HeaderDirection = "direction"
+)
+
+//LogType is the LogType of the log, registered with quickfix.RegisterLogFactory when the package is imported.
+const LogType = "kafka"
+
+func init() {
+	quickfix.RegisterLogFactory(LogType, func(settings *quickfix.Settings) (quickfix.LogFactory, error) {
+		factory, err := NewLogFactory(settings)
+		if err != nil {
+			return nil, err
+		}
+		return factory, nil
+	})
+}
+
+//Writer is the part of *kafka.Writer used by LogFactory.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+//format is the serialization of the records published, set by KafkaLogFormat.
+type format int
+
+const (
+	//formatRaw publishes messages as sent and received, events as text.
+	formatRaw format = iota
+
+	//formatJSON publishes JSON objects, message fields are named by the data dictionaries of the session.
+	formatJSON
+)
+
+//parseFormat maps the KafkaLogFormat setting to a format.
+func parseFormat(setting string) (format, error) {
+	switch setting {
+	case "Raw":
+		return formatRaw, nil
+	case "JSON":
+		return formatJSON, nil
+	}
+
+	return formatRaw, fmt.Errorf("invalid KafkaLogFormat %v, expected Raw or JSON", setting)
+}
+
+//LogFactory is a quickfix.LogFactory publishing the messages of sessions to KafkaLogMessageTopic and their events to KafkaLogEventTopic.
+//Records are keyed by SessionID, GLOBAL for the global log, so the records of a session are published to one partition in order.
+type LogFactory struct {
+	writer   Writer
+	settings *quickfix.Settings
+
+	messageTopic string
+	eventTopic   string
+	format       format
+
+	//Timeout limits each publish, 10 seconds if zero.
+	Timeout time.Duration
+
+	//OnError, if set, is called each time a record cannot be published. The record is discarded.
+	OnError func(err error)
+}
+
+//NewLogFactory returns a LogFactory publishing to the comma separated KafkaLogBrokers, with a writer waiting for all in-sync replicas.
+//See NewLogFactoryWithWriter.
+func NewLogFactory(settings *quickfix.Settings) (*LogFactory, error) {
+	brokers, err := settings.GlobalSettings().Setting(config.KafkaLogBrokers)
+	if err != nil {
+		return nil, fmt.Errorf("missing configuration: %v", config.KafkaLogBrokers)
+	}
+
+	var addrs []string
+	for _, broker := range strings.Split(brokers, ",") {
+		addrs = append(addrs, strings.TrimSpace(broker))
+	}
+
+	writer := &kafka.Writer{Addr: kafka.TCP(addrs...), Balancer: &kafka.Hash{}, RequiredAcks: kafka.RequireAll}
+	return NewLogFactoryWithWriter(writer, settings)
+}
+
+//NewLogFactoryWithWriter returns a LogFactory publishing with writer, which must not set a Topic.
+//Messages are published to KafkaLogMessageTopic, events to KafkaLogEventTopic if set.
+//KafkaLogFormat=Raw, the default, publishes messages as sent and received and events as text, with a direction header on messages.
+//KafkaLogFormat=JSON publishes objects with the session, direction, and time, and the fields of the message named by the
+//DataDictionary, or TransportDataDictionary and AppDataDictionary, of the session.
+//Records are published as logged by the session goroutine, unless writer is asynchronous, see quickfix.NewAsyncLogFactory.
+func NewLogFactoryWithWriter(writer Writer, settings *quickfix.Settings) (*LogFactory, error) {
+	f := &LogFactory{writer: writer, settings: settings}
+
+	globalSettings := settings.GlobalSettings()
+	var err error
+	if f.messageTopic, err = globalSettings.Setting(config.KafkaLogMessageTopic); err != nil {
+		return nil, fmt.Errorf("missing configuration: %v", config.KafkaLogMessageTopic)
+	}
+
+	if globalSettings.HasSetting(config.KafkaLogEventTopic) {
+		f.eventTopic, _ = globalSettings.Setting(config.KafkaLogEventTopic)
+	}
+
+	if globalSettings.HasSetting(config.KafkaLogFormat) {
+		setting, _ := globalSettings.Setting(config.KafkaLogFormat)
+		if f.format, err = parseFormat(setting); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+//Create returns the global log, its records keyed GLOBAL.
+func (f *LogFactory) Create() (quickfix.Log, error) {
+	return &log{factory: f, key: globalKey}, nil
+}
+
+//CreateSessionLog returns the log of sessionID.
+func (f *LogFactory) CreateSessionLog(sessionID quickfix.SessionID) (quickfix.Log, error) {
+	settings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("kafka log not defined for %v", sessionID)
+	}
+
+	l := &log{factory: f, key: sessionID.String()}
+	if f.format == formatJSON {
+		for _, setting := range []string{config.DataDictionary, config.TransportDataDictionary, config.AppDataDictionary} {
+			dict, err := settings.DataDictionary(setting)
+			if err != nil {
+				return nil, err
+			}
+
+			if dict != nil {
+				l.dicts = append(l.dicts, dict)
+			}
+		}
+	}
+
+	return l, nil
+}
+
+//Close closes the writer, publishing the records it buffers.
+func (f *LogFactory) Close() error {
+	if closer, ok := f.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+func (f *LogFactory) publish(msg kafka.Message) {
+	timeout := f.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := f.writer.WriteMessages(ctx, msg); err != nil && f.OnError != nil {
+		f.OnError(fmt.Errorf("cannot publish to %v: %v", msg.Topic, err))
+	}
+}
+
+type log struct {
+	factory *LogFactory
+	key     string
+
+	//dicts name the fields of messages published as JSON, searched in order
+	dicts []*datadictionary.DataDictionary
+}
+
+//messageRecord is the JSON object of a message.
+type messageRecord struct {
+	Session   string        `json:"session"`
+	Direction string        `json:"direction"`
+	Time      time.Time     `json:"time"`
+	Fields    []fieldRecord `json:"fields"`
+}
+
+type fieldRecord struct {
+	Tag   int    `json:"tag"`
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value"`
+}
+
+//eventRecord is the JSON object of an event.
+type eventRecord struct {
+	Session string    `json:"session"`
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+}
+
+func (l *log) OnIncoming(msg string) {
+	l.onMessage("incoming", msg)
+}
+
+func (l *log) OnOutgoing(msg string) {
+	l.onMessage("outgoing", msg)
+}
+
+func (l *log) onMessage(direction, msg string) {
+	now := time.Now().UTC()
+	value := []byte(msg)
+	if l.factory.format == formatJSON {
+		value = l.encode(messageRecord{Session: l.key, Direction: direction, Time: now, Fields: l.fields(msg)})
+	}
+
+	l.factory.publish(kafka.Message{
+		Topic:   l.factory.messageTopic,
+		Key:     []byte(l.key),
+		Value:   value,
+		Headers: []kafka.Header{{Key: HeaderDirection, Value: []byte(direction)}},
+		Time:    now,
+	})
+}
+
+func (l *log) OnEvent(msg string) {
+	if l.factory.eventTopic == "" {
+		return
+	}
+
+	now := time.Now().UTC()
+	value := []byte(msg)
+	if l.factory.format == formatJSON {
+		value = l.encode(eventRecord{Session: l.key, Time: now, Event: msg})
+	}
+
+	l.factory.publish(kafka.Message{Topic: l.factory.eventTopic, Key: []byte(l.key), Value: value, Time: now})
+}
+
+func (l *log) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+func (l *log) encode(record interface{}) []byte {
+	value, err := json.Marshal(record)
+	if err != nil && l.factory.OnError != nil {
+		l.factory.OnError(err)
+	}
+
+	return value
+}
+
+//fields splits msg into its fields, named by the first dictionary defining the tag. A field without '=' is published with tag 0.
+func (l *log) fields(msg string) []fieldRecord {
+	var fields []fieldRecord
+	for _, field := range bytes.Split(bytes.TrimSuffix([]byte(msg), []byte("\001")), []byte("\001")) {
+		record := fieldRecord{Value: string(field)}
+		if eq := bytes.IndexByte(field, '='); eq != -1 {
+			if t, err := strconv.Atoi(string(field[:eq])); err == nil {
+				record.Tag, record.Value = t, string(field[eq+1:])
+				record.Name = l.fieldName(fix.Tag(t))
+			}
+		}
+
+		fields = append(fields, record)
+	}
+
+	return fields
+}
+
+func (l *log) fieldName(t fix.Tag) string {
+	for _, dict := range l.dicts {
+		if fieldType, ok := dict.FieldTypeByTag[t]; ok {
+			return fieldType.Name
+		}
+	}
+
+	return ""
+}
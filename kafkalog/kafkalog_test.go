@@ -0,0 +1,157 @@
+package kafkalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/segmentio/kafka-go"
+	"strings"
+	"testing"
+)
+
+var testSessionID = quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+
+type fakeWriter struct {
+	msgs []kafka.Message
+	err  error
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func newTestSettings(t *testing.T, format string) *quickfix.Settings {
+	cfg := `
+[DEFAULT]
+KafkaLogMessageTopic=fix.messages
+KafkaLogEventTopic=fix.events
+KafkaLogFormat=` + format + `
+DataDictionary=../spec/FIX42.xml
+
+[SESSION]
+BeginString=FIX.4.2
+SenderCompID=TW
+TargetCompID=ISLD
+`
+	settings, err := quickfix.ParseSettings(strings.NewReader(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return settings
+}
+
+func TestNewLogFactory(t *testing.T) {
+	settings := quickfix.NewSettings()
+	if _, err := NewLogFactory(settings); err == nil {
+		t.Error("Expected error without KafkaLogBrokers")
+	}
+
+	settings.GlobalSettings().Set(config.KafkaLogBrokers, "kafka1:9092, kafka2:9092")
+	if _, err := NewLogFactory(settings); err == nil {
+		t.Error("Expected error without KafkaLogMessageTopic")
+	}
+
+	settings.GlobalSettings().Set(config.KafkaLogMessageTopic, "fix.messages")
+	settings.GlobalSettings().Set(config.KafkaLogFormat, "XML")
+	if _, err := NewLogFactory(settings); err == nil {
+		t.Error("Expected error for KafkaLogFormat XML")
+	}
+
+	settings.GlobalSettings().Set(config.KafkaLogFormat, "Raw")
+	factory, err := NewLogFactory(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addr := factory.writer.(*kafka.Writer).Addr.String(); addr != "kafka1:9092,kafka2:9092" {
+		t.Errorf("Unexpected brokers %v", addr)
+	}
+}
+
+func TestLog_Raw(t *testing.T) {
+	writer := new(fakeWriter)
+	factory, err := NewLogFactoryWithWriter(writer, newTestSettings(t, "Raw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := factory.CreateSessionLog(testSessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.OnIncoming("8=FIX.4.2\x0135=0\x01")
+	log.OnEventf("Connected to %v", "localhost:5001")
+
+	global, _ := factory.Create()
+	global.OnEvent("Listening")
+
+	if len(writer.msgs) != 3 {
+		t.Fatalf("Expected 3 records, got %v", writer.msgs)
+	}
+
+	msg := writer.msgs[0]
+	if msg.Topic != "fix.messages" || string(msg.Key) != testSessionID.String() || string(msg.Value) != "8=FIX.4.2\x0135=0\x01" {
+		t.Errorf("Unexpected message record %+v", msg)
+	}
+
+	if len(msg.Headers) != 1 || string(msg.Headers[0].Value) != "incoming" {
+		t.Errorf("Expected direction header, got %v", msg.Headers)
+	}
+
+	if event := writer.msgs[1]; event.Topic != "fix.events" || string(event.Value) != "Connected to localhost:5001" {
+		t.Errorf("Unexpected event record %+v", event)
+	}
+
+	if string(writer.msgs[2].Key) != "GLOBAL" {
+		t.Errorf("Expected global record keyed GLOBAL, got %q", writer.msgs[2].Key)
+	}
+
+	writer.err = errors.New("leader not available")
+	var published error
+	factory.OnError = func(err error) { published = err }
+	log.OnOutgoing("8=FIX.4.2\x0135=0\x01")
+	if published == nil {
+		t.Error("Expected publish error reported")
+	}
+}
+
+func TestLog_JSON(t *testing.T) {
+	writer := new(fakeWriter)
+	factory, err := NewLogFactoryWithWriter(writer, newTestSettings(t, "JSON"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := factory.CreateSessionLog(testSessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.OnOutgoing("8=FIX.4.2\x0135=D\x015001=custom\x01")
+
+	var record messageRecord
+	if err := json.Unmarshal(writer.msgs[0].Value, &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Session != testSessionID.String() || record.Direction != "outgoing" || len(record.Fields) != 3 {
+		t.Fatalf("Unexpected record %+v", record)
+	}
+
+	if f := record.Fields[1]; f.Tag != 35 || f.Name != "MsgType" || f.Value != "D" {
+		t.Errorf("Expected field named by the dictionary, got %+v", f)
+	}
+
+	if f := record.Fields[2]; f.Tag != 5001 || f.Name != "" {
+		t.Errorf("Expected field unknown to the dictionary unnamed, got %+v", f)
+	}
+}
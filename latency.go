@@ -0,0 +1,293 @@
+package quickfix
+
+import (
+	"math/bits"
+	"sync"
+	"time"
+)
+
+//latencySubBucketBits sets the precision of a LatencyHistogram, each power of two range of latencies is divided into 2^latencySubBucketBits buckets.
+const latencySubBucketBits = 5
+
+const latencySubBuckets = 1 << latencySubBucketBits
+
+//LatencyHistogram is a log-linear histogram of latencies, in the manner of an HDR histogram, recording latencies from
+//nanoseconds to hours with a relative error under 1/32 while growing only with the range of latencies recorded.
+//A LatencyHistogram is not safe for concurrent use, those returned by Session.Latency are copies.
+type LatencyHistogram struct {
+	counts   []int64
+	count    int64
+	sum      time.Duration
+	min, max time.Duration
+}
+
+//latencyBucket returns the bucket of the histogram recording the latency of nanos.
+func latencyBucket(nanos uint64) int {
+	shift := bits.Len64(nanos) - latencySubBucketBits - 1
+	if shift < 0 {
+		shift = 0
+	}
+
+	return shift*latencySubBuckets + int(nanos>>uint(shift))
+}
+
+//latencyBucketRange returns the lowest latency of bucket, and the width of its range of latencies.
+func latencyBucketRange(bucket int) (lowest, width time.Duration) {
+	if bucket < 2*latencySubBuckets {
+		return time.Duration(bucket), 1
+	}
+
+	shift := uint(bucket/latencySubBuckets - 1)
+	return time.Duration(bucket-int(shift)*latencySubBuckets) << shift, 1 << shift
+}
+
+//Record records a latency, negative latencies as 0.
+func (h *LatencyHistogram) Record(latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+
+	bucket := latencyBucket(uint64(latency))
+	if bucket >= len(h.counts) {
+		counts := make([]int64, bucket+1)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	h.counts[bucket]++
+
+	if h.count == 0 || latency < h.min {
+		h.min = latency
+	}
+	if latency > h.max {
+		h.max = latency
+	}
+	h.count++
+	h.sum += latency
+}
+
+//Merge records the latencies recorded by other.
+func (h *LatencyHistogram) Merge(other *LatencyHistogram) {
+	if other.count == 0 {
+		return
+	}
+
+	if len(other.counts) > len(h.counts) {
+		counts := make([]int64, len(other.counts))
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	for bucket, count := range other.counts {
+		h.counts[bucket] += count
+	}
+
+	if h.count == 0 || other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+	h.count += other.count
+	h.sum += other.sum
+}
+
+//Count returns the number of latencies recorded.
+func (h *LatencyHistogram) Count() int64 {
+	return h.count
+}
+
+//Min returns the lowest latency recorded, 0 if none.
+func (h *LatencyHistogram) Min() time.Duration {
+	return h.min
+}
+
+//Max returns the highest latency recorded, 0 if none.
+func (h *LatencyHistogram) Max() time.Duration {
+	return h.max
+}
+
+//Mean returns the mean of the latencies recorded, 0 if none.
+func (h *LatencyHistogram) Mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	return h.sum / time.Duration(h.count)
+}
+
+//Percentile returns the latency at or below which percentile percent of the latencies recorded fall, 0 if none.
+//The latency is the middle of the bucket of the percentile, within the relative error of the histogram, bounded by Min and Max.
+func (h *LatencyHistogram) Percentile(percentile float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := int64(percentile/100*float64(h.count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	for bucket, count := range h.counts {
+		if seen += count; seen < rank {
+			continue
+		}
+
+		lowest, width := latencyBucketRange(bucket)
+		latency := lowest + width/2
+		if latency < h.min {
+			return h.min
+		}
+		if latency > h.max {
+			return h.max
+		}
+		return latency
+	}
+
+	return h.max
+}
+
+//clone returns a copy of the histogram.
+func (h *LatencyHistogram) clone() *LatencyHistogram {
+	clone := *h
+	clone.counts = append([]int64(nil), h.counts...)
+	return &clone
+}
+
+//LatencyStats are the latencies of the messages of a session over a window, by MsgType, with RecordLatency=Y.
+type LatencyStats struct {
+	//WindowStart is when the window started, on creation of the session or the last ResetLatency.
+	WindowStart time.Time
+
+	//WireToCallback are the latencies from a message being read from the connection to the Interceptors, then FromAdmin or FromApp, being called with it.
+	WireToCallback map[string]*LatencyHistogram
+
+	//CallbackToWire are the latencies from a message being sent, through ToAdmin or ToApp and the store, to it being handed to the connection.
+	CallbackToWire map[string]*LatencyHistogram
+}
+
+//MergeLatency returns a histogram of the latencies of each of histograms, such as those of each MsgType of LatencyStats.WireToCallback.
+func MergeLatency(histograms map[string]*LatencyHistogram) *LatencyHistogram {
+	merged := new(LatencyHistogram)
+	for _, histogram := range histograms {
+		merged.Merge(histogram)
+	}
+
+	return merged
+}
+
+//latencyRecorder records the latencies of the messages of a session, from the session goroutine and the goroutines sending messages.
+type latencyRecorder struct {
+	lock           sync.Mutex
+	windowStart    time.Time
+	wireToCallback map[string]*LatencyHistogram
+	callbackToWire map[string]*LatencyHistogram
+}
+
+func newLatencyRecorder(now time.Time) *latencyRecorder {
+	return &latencyRecorder{
+		windowStart:    now,
+		wireToCallback: make(map[string]*LatencyHistogram),
+		callbackToWire: make(map[string]*LatencyHistogram),
+	}
+}
+
+func recordLatency(histograms map[string]*LatencyHistogram, msgType string, latency time.Duration) {
+	histogram, ok := histograms[msgType]
+	if !ok {
+		histogram = new(LatencyHistogram)
+		histograms[msgType] = histogram
+	}
+	histogram.Record(latency)
+}
+
+func (r *latencyRecorder) recordWireToCallback(msgType string, latency time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	recordLatency(r.wireToCallback, msgType, latency)
+}
+
+func (r *latencyRecorder) recordCallbackToWire(msgType string, latency time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	recordLatency(r.callbackToWire, msgType, latency)
+}
+
+//stats returns a copy of the latencies of the window, starting a new window at now if reset.
+func (r *latencyRecorder) stats(reset bool, now time.Time) LatencyStats {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	stats := LatencyStats{
+		WindowStart:    r.windowStart,
+		WireToCallback: make(map[string]*LatencyHistogram, len(r.wireToCallback)),
+		CallbackToWire: make(map[string]*LatencyHistogram, len(r.callbackToWire)),
+	}
+
+	if reset {
+		stats.WireToCallback, stats.CallbackToWire = r.wireToCallback, r.callbackToWire
+		r.windowStart = now
+		r.wireToCallback = make(map[string]*LatencyHistogram)
+		r.callbackToWire = make(map[string]*LatencyHistogram)
+		return stats
+	}
+
+	for msgType, histogram := range r.wireToCallback {
+		stats.WireToCallback[msgType] = histogram.clone()
+	}
+	for msgType, histogram := range r.callbackToWire {
+		stats.CallbackToWire[msgType] = histogram.clone()
+	}
+
+	return stats
+}
+
+//Latency returns the latencies of the messages of the session since the window started, empty without RecordLatency=Y.
+//Safe to call outside the session goroutine.
+func (s *Session) Latency() LatencyStats {
+	if s.latency == nil {
+		return LatencyStats{}
+	}
+
+	return s.latency.stats(false, s.now())
+}
+
+//ResetLatency returns the latencies of the messages of the session since the window started, and starts a new window.
+//Safe to call outside the session goroutine.
+func (s *Session) ResetLatency() LatencyStats {
+	if s.latency == nil {
+		return LatencyStats{}
+	}
+
+	return s.latency.stats(true, s.now())
+}
+
+//LookupLatency returns the latencies of the messages of the session with sessionID, starting a new window if reset.
+func LookupLatency(sessionID SessionID, reset bool) (LatencyStats, error) {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return LatencyStats{}, err
+	}
+
+	if reset {
+		return session.ResetLatency(), nil
+	}
+	return session.Latency(), nil
+}
+
+//MessageTimestamps are the times of the stages of a message received, recorded with MessageTimestamps=Y for the Application
+//to measure the latency of its own processing. Times are zero if not recorded.
+type MessageTimestamps struct {
+	//Received is when the message was read from the connection, as Message.ReceiveTime.
+	Received time.Time
+
+	//Parsed is when the message was parsed.
+	Parsed time.Time
+
+	//Validated is when the header and sequence number of the message were checked, and the message validated against the data dictionary.
+	Validated time.Time
+
+	//Callback is when the Interceptors, then FromAdmin or FromApp, were called with the message.
+	Callback time.Time
+}
@@ -0,0 +1,155 @@
+package quickfix
+
+import (
+	"context"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	var histogram LatencyHistogram
+	if histogram.Percentile(50) != 0 || histogram.Mean() != 0 {
+		t.Error("Expected no latencies")
+	}
+
+	for i := 1; i <= 1000; i++ {
+		histogram.Record(time.Duration(i) * time.Microsecond)
+	}
+
+	if histogram.Count() != 1000 || histogram.Min() != time.Microsecond || histogram.Max() != time.Millisecond {
+		t.Errorf("Expected 1000 latencies from 1us to 1ms, got %v from %v to %v", histogram.Count(), histogram.Min(), histogram.Max())
+	}
+
+	for percentile, expected := range map[float64]time.Duration{50: 500 * time.Microsecond, 99: 990 * time.Microsecond, 99.9: 999 * time.Microsecond} {
+		actual := histogram.Percentile(percentile)
+		if diff := actual - expected; diff > expected/32 || diff < -expected/32 {
+			t.Errorf("Expected p%v of %v, got %v", percentile, expected, actual)
+		}
+	}
+
+	if histogram.Percentile(100) != time.Millisecond || histogram.Percentile(0) != time.Microsecond {
+		t.Errorf("Expected p0 and p100 to be the min and max, got %v and %v", histogram.Percentile(0), histogram.Percentile(100))
+	}
+
+	var other LatencyHistogram
+	other.Record(time.Second)
+	merged := MergeLatency(map[string]*LatencyHistogram{"D": &histogram, "F": &other})
+	if merged.Count() != 1001 || merged.Max() != time.Second || merged.Min() != time.Microsecond {
+		t.Errorf("Expected merged histogram, got %v from %v to %v", merged.Count(), merged.Min(), merged.Max())
+	}
+}
+
+func TestLatencyBucket(t *testing.T) {
+	for _, nanos := range []uint64{0, 1, 63, 64, 65, 1000, 123456789, 1 << 40} {
+		lowest, width := latencyBucketRange(latencyBucket(nanos))
+		if time.Duration(nanos) < lowest || time.Duration(nanos) >= lowest+width {
+			t.Errorf("Expected %v in bucket from %v width %v", nanos, lowest, width)
+		}
+	}
+}
+
+//timestampedClient reports each app message received.
+type timestampedClient struct {
+	shutdownClient
+	messages chan Message
+}
+
+func (c *timestampedClient) FromApp(msg Message, sessionID SessionID) MessageRejectError {
+	c.messages <- msg
+	return nil
+}
+
+func TestSession_Latency(t *testing.T) {
+	port := strconv.Itoa(freePort(t))
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, port)
+	acceptorSessionSettings := newTestAcceptorSessionSettings("LATENCY")
+	acceptorSessionSettings.Set(config.RecordLatency, "Y")
+	acceptorSessionSettings.Set(config.MessageTimestamps, "Y")
+	acceptorSessionID, err := acceptorSettings.AddSession(acceptorSessionSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acceptorApp := &timestampedClient{shutdownClient{states: make(chan SessionState, 20)}, make(chan Message, 1)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorSettings := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "LATENCY")
+	sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+	sessionSettings.Set(config.HeartBtInt, "30")
+	sessionSettings.Set(config.SocketConnectHost, "127.0.0.1")
+	sessionSettings.Set(config.SocketConnectPort, port)
+	sessionSettings.Set(config.RecordLatency, "Y")
+	initiatorSessionID, err := initiatorSettings.AddSession(sessionSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer initiator.Shutdown(context.Background())
+
+	awaitState(t, initiatorApp.states, StateLoggedOn)
+	awaitState(t, acceptorApp.states, StateLoggedOn)
+
+	order := NewMessageBuilder()
+	order.Header().Set(fix.NewStringField(tag.MsgType, "D"))
+	if err := SendToTarget(order, initiatorSessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-acceptorApp.messages:
+		timestamps := msg.Timestamps
+		if !timestamps.Received.Equal(msg.ReceiveTime) || timestamps.Parsed.Before(timestamps.Received) ||
+			timestamps.Validated.Before(timestamps.Parsed) || timestamps.Callback.Before(timestamps.Validated) {
+			t.Errorf("Expected ordered timestamps, got %+v", timestamps)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for order")
+	}
+
+	sent, err := LookupLatency(initiatorSessionID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.CallbackToWire["A"].Count() != 1 || sent.CallbackToWire["D"].Count() != 1 || sent.WireToCallback["A"].Count() != 1 {
+		t.Errorf("Expected latencies of Logon sent and received and order sent, got %+v", sent)
+	}
+
+	received, err := LookupLatency(acceptorSessionID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.WireToCallback["D"].Count() != 1 || received.WindowStart.IsZero() {
+		t.Errorf("Expected latency of order received, got %+v", received)
+	}
+
+	reset, _ := LookupLatency(acceptorSessionID, false)
+	if len(reset.WireToCallback) != 0 || !reset.WindowStart.After(received.WindowStart) {
+		t.Errorf("Expected new window, got %+v", reset)
+	}
+}
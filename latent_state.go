@@ -3,7 +3,7 @@ package quickfix
 type latentState struct{}
 
 func (state latentState) FixMsgIn(session *Session, msg Message) (nextState sessionState) {
-	session.log.OnEventf("Invalid Session State: Unexpected Msg %v while in Latent state", msg)
+	logEventf(session.log, LogLevelWarn, LogCategorySession, "Invalid Session State: Unexpected Msg %v while in Latent state", msg)
 	return state
 }
 
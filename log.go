@@ -1,6 +1,12 @@
 package quickfix
 
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+)
+
 //Log is a generic interface for logging FIX messages and events.
+//NewSlogLogFactory adapts Log to log/slog for structured records.
 type Log interface {
 	//log incoming fix message
 	OnIncoming(string)
@@ -23,3 +29,26 @@ type LogFactory interface {
 	//session specific log
 	CreateSessionLog(sessionID SessionID) (Log, error)
 }
+
+//FlushableLogFactory may be implemented by a LogFactory that buffers records, it is flushed once an Initiator or Acceptor is shut down.
+type FlushableLogFactory interface {
+	//Flush returns once the records logged before the call are written.
+	Flush()
+}
+
+//logHeader returns the MsgType and MsgSeqNum of a message logged, nil if missing from msg.
+func logHeader(msg string) (msgType *fix.StringValue, seqNum *fix.IntValue) {
+	header := parseHeader([]byte(msg))
+
+	msgType = new(fix.StringValue)
+	if header.GetField(tag.MsgType, msgType) != nil {
+		msgType = nil
+	}
+
+	seqNum = new(fix.IntValue)
+	if header.GetField(tag.MsgSeqNum, seqNum) != nil {
+		seqNum = nil
+	}
+
+	return
+}
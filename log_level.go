@@ -0,0 +1,159 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"strings"
+	"sync"
+)
+
+//LogLevel is the severity of an event.
+type LogLevel int
+
+const (
+	//LogLevelDebug events trace the normal operation of a session.
+	LogLevelDebug LogLevel = iota
+
+	//LogLevelInfo events record changes of session state, such as logon and resets.
+	LogLevelInfo
+
+	//LogLevelWarn events record messages rejected or ignored and conditions the session recovers from.
+	LogLevelWarn
+
+	//LogLevelError events record failures disconnecting the session or losing messages.
+	LogLevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "Debug"
+	case LogLevelInfo:
+		return "Info"
+	case LogLevelWarn:
+		return "Warn"
+	case LogLevelError:
+		return "Error"
+	}
+
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+//parseLogLevel maps the LogEventLevel setting to a LogLevel.
+func parseLogLevel(setting string) (LogLevel, error) {
+	for level := LogLevelDebug; level <= LogLevelError; level++ {
+		if setting == level.String() {
+			return level, nil
+		}
+	}
+
+	return LogLevelDebug, fmt.Errorf("invalid LogEventLevel %v, expected Debug, Info, Warn, or Error", setting)
+}
+
+//LogCategory is the area of the engine an event relates to.
+type LogCategory string
+
+//The categories of events.
+const (
+	LogCategorySession    LogCategory = "Session"
+	LogCategoryValidation LogCategory = "Validation"
+	LogCategoryStore      LogCategory = "Store"
+	LogCategoryTransport  LogCategory = "Transport"
+)
+
+//LeveledLog may be implemented by a Log to receive the level and category of the events of a session.
+//Logs not implementing LeveledLog receive events through OnEvent.
+type LeveledLog interface {
+	OnLeveledEvent(level LogLevel, category LogCategory, msg string)
+}
+
+//logEventf writes an event of level and category to log.
+func logEventf(log Log, level LogLevel, category LogCategory, format string, a ...interface{}) {
+	if leveled, ok := log.(LeveledLog); ok {
+		leveled.OnLeveledEvent(level, category, fmt.Sprintf(format, a...))
+		return
+	}
+
+	log.OnEventf(format, a...)
+}
+
+//logFilter is the LogEventLevel and LogEventCategories the events of a session are filtered by, updated by ReloadSettings.
+type logFilter struct {
+	lock       sync.RWMutex
+	level      LogLevel
+	categories map[LogCategory]bool
+}
+
+//newLogFilter returns the filter configured by the LogEventLevel and LogEventCategories of settings, permitting all events if neither is set.
+func newLogFilter(settings *SessionSettings) (*logFilter, error) {
+	filter := new(logFilter)
+	if setting, err := settings.Setting(config.LogEventLevel); err == nil {
+		if filter.level, err = parseLogLevel(setting); err != nil {
+			return nil, err
+		}
+	}
+
+	if setting, err := settings.Setting(config.LogEventCategories); err == nil {
+		filter.categories = make(map[LogCategory]bool)
+		for _, value := range strings.Split(setting, ",") {
+			switch category := LogCategory(strings.TrimSpace(value)); category {
+			case LogCategorySession, LogCategoryValidation, LogCategoryStore, LogCategoryTransport:
+				filter.categories[category] = true
+			default:
+				return nil, fmt.Errorf("invalid %v %v, expected Session, Validation, Store, or Transport", config.LogEventCategories, setting)
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+//update replaces the level and categories of the filter with those of filter.
+func (f *logFilter) update(filter *logFilter) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.level, f.categories = filter.level, filter.categories
+}
+
+//permits returns true unless events of level and category are discarded.
+func (f *logFilter) permits(level LogLevel, category LogCategory) bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return level >= f.level && (f.categories == nil || f.categories[category])
+}
+
+//filteredLog discards the events of a session below LogEventLevel or outside LogEventCategories.
+//Events written without a level through OnEvent are Info events of the Session category.
+type filteredLog struct {
+	Log
+	filter *logFilter
+}
+
+//newFilteredLog returns log filtered by the LogEventLevel and LogEventCategories of settings, permitting all events if neither is set
+//until the filter is updated.
+func newFilteredLog(log Log, settings *SessionSettings) (filteredLog, error) {
+	filter, err := newLogFilter(settings)
+	if err != nil {
+		return filteredLog{}, err
+	}
+
+	return filteredLog{Log: log, filter: filter}, nil
+}
+
+func (l filteredLog) OnEvent(msg string) {
+	l.OnLeveledEvent(LogLevelInfo, LogCategorySession, msg)
+}
+
+func (l filteredLog) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+func (l filteredLog) OnLeveledEvent(level LogLevel, category LogCategory, msg string) {
+	if !l.filter.permits(level, category) {
+		return
+	}
+
+	logEventf(l.Log, level, category, "%s", msg)
+}
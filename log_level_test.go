@@ -0,0 +1,130 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"log/slog"
+	"testing"
+)
+
+type leveledEvent struct {
+	level    LogLevel
+	category LogCategory
+	msg      string
+}
+
+type leveledRecordingLog struct {
+	nullLog
+	events []leveledEvent
+}
+
+func (l *leveledRecordingLog) OnLeveledEvent(level LogLevel, category LogCategory, msg string) {
+	l.events = append(l.events, leveledEvent{level, category, msg})
+}
+
+type eventRecordingLog struct {
+	nullLog
+	events []string
+}
+
+func (l *eventRecordingLog) OnEvent(msg string) { l.events = append(l.events, msg) }
+func (l *eventRecordingLog) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for _, level := range []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError} {
+		parsed, err := parseLogLevel(level.String())
+		if err != nil || parsed != level {
+			t.Errorf("Expected %v, got %v %v", level, parsed, err)
+		}
+	}
+
+	if _, err := parseLogLevel("Trace"); err == nil {
+		t.Error("Expected error for Trace")
+	}
+}
+
+func TestLogEventf(t *testing.T) {
+	leveled := new(leveledRecordingLog)
+	logEventf(leveled, LogLevelWarn, LogCategoryStore, "Cannot store message %v", 3)
+
+	if len(leveled.events) != 1 || leveled.events[0] != (leveledEvent{LogLevelWarn, LogCategoryStore, "Cannot store message 3"}) {
+		t.Errorf("Unexpected events %v", leveled.events)
+	}
+
+	plain := new(eventRecordingLog)
+	logEventf(plain, LogLevelWarn, LogCategoryStore, "Cannot store message %v", 3)
+
+	if len(plain.events) != 1 || plain.events[0] != "Cannot store message 3" {
+		t.Errorf("Expected event written with OnEventf, got %v", plain.events)
+	}
+}
+
+func TestNewFilteredLog(t *testing.T) {
+	recorded := new(leveledRecordingLog)
+
+	settings := NewSessionSettings()
+	log, err := newFilteredLog(recorded, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logEventf(log, LogLevelDebug, LogCategoryTransport, "Connected")
+	if len(recorded.events) != 1 {
+		t.Errorf("Expected log unfiltered without settings, got %v", recorded.events)
+	}
+	recorded.events = nil
+
+	settings.Set(config.LogEventLevel, "Warn")
+	settings.Set(config.LogEventCategories, "Session, Store")
+	if log, err = newFilteredLog(recorded, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	logEventf(log, LogLevelInfo, LogCategorySession, "Sending heartbeat")
+	logEventf(log, LogLevelWarn, LogCategoryValidation, "Message Rejected")
+	logEventf(log, LogLevelError, LogCategorySession, "MsgSeqNum too low")
+	logEventf(log, LogLevelWarn, LogCategoryStore, "Cannot flush store")
+	log.OnEvent("Unleveled")
+
+	expected := []leveledEvent{
+		{LogLevelError, LogCategorySession, "MsgSeqNum too low"},
+		{LogLevelWarn, LogCategoryStore, "Cannot flush store"},
+	}
+	if len(recorded.events) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, recorded.events)
+	}
+	for i, event := range expected {
+		if recorded.events[i] != event {
+			t.Errorf("Expected %v, got %v", event, recorded.events[i])
+		}
+	}
+
+	for key, value := range map[string]string{config.LogEventLevel: "Verbose", config.LogEventCategories: "Session,Heartbeat"} {
+		invalid := NewSessionSettings()
+		invalid.Set(key, value)
+		if _, err := newFilteredLog(recorded, invalid); err == nil {
+			t.Errorf("Expected error for %v=%v", key, value)
+		}
+	}
+}
+
+func TestLeveledEvent_Forwarded(t *testing.T) {
+	recorded := new(leveledRecordingLog)
+	log := teeLog{maskingLog{Log: recorded}, recorded}
+	logEventf(log, LogLevelDebug, LogCategoryTransport, "Connected")
+
+	if len(recorded.events) != 2 || recorded.events[0] != recorded.events[1] || recorded.events[0].category != LogCategoryTransport {
+		t.Errorf("Expected event forwarded with level and category, got %v", recorded.events)
+	}
+
+	buffer := new(bytes.Buffer)
+	logEventf(slogLog{slog.New(slog.NewJSONHandler(buffer, nil))}, LogLevelError, LogCategoryStore, "Cannot flush store")
+
+	records := decodeRecords(t, buffer)
+	if len(records) != 1 || records[0]["level"] != "ERROR" || records[0][SlogKeyCategory] != string(LogCategoryStore) {
+		t.Errorf("Unexpected slog records %v", records)
+	}
+}
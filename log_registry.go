@@ -0,0 +1,220 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//defaultLogType is the LogType of sessions without the setting.
+const defaultLogType = "null"
+
+//LogFactoryConstructor creates the LogFactory of a log sink for settings.
+type LogFactoryConstructor func(settings *Settings) (LogFactory, error)
+
+var logFactories = struct {
+	sync.RWMutex
+	constructors map[string]LogFactoryConstructor
+}{
+	constructors: map[string]LogFactoryConstructor{
+		"null":   func(*Settings) (LogFactory, error) { return NewNullLogFactory(), nil },
+		"screen": func(*Settings) (LogFactory, error) { return NewScreenLogFactory(), nil },
+		"slog":   func(*Settings) (LogFactory, error) { return NewSlogLogFactory(slog.Default()), nil },
+		"file":   NewFileLogFactory,
+		"syslog": func(settings *Settings) (LogFactory, error) {
+			factory, err := NewSyslogLogFactory(settings)
+			if err != nil {
+				return nil, err
+			}
+			return factory, nil
+		},
+		"journald": func(settings *Settings) (LogFactory, error) {
+			factory, err := NewJournaldLogFactory(settings)
+			if err != nil {
+				return nil, err
+			}
+			return factory, nil
+		},
+	},
+}
+
+//RegisterLogFactory makes a log sink available as LogType name to NewLogFactoryFromSettings.
+//Packages providing a log typically register it in an init function. Panics if name is already registered.
+func RegisterLogFactory(name string, constructor LogFactoryConstructor) {
+	logFactories.Lock()
+	defer logFactories.Unlock()
+
+	if _, dup := logFactories.constructors[name]; dup {
+		panic(fmt.Sprintf("quickfix: log factory %v registered twice", name))
+	}
+
+	logFactories.constructors[name] = constructor
+}
+
+//LogFactories returns the names of the registered log sinks, sorted.
+func LogFactories() []string {
+	logFactories.RLock()
+	defer logFactories.RUnlock()
+
+	var names []string
+	for name := range logFactories.constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+//settingsLogFactory creates the log of each session writing to the sinks named by its LogType.
+type settingsLogFactory struct {
+	settings *Settings
+
+	lock      sync.Mutex
+	factories map[string]LogFactory
+
+	//views are the settings passed to the sink of each LogType, holding only the sessions logging to the sink
+	views map[string]*Settings
+}
+
+//NewLogFactoryFromSettings returns a LogFactory creating the log of each session with the sinks registered as its LogType,
+//a comma separated list of sinks each record is written to, e.g. LogType=file,kafka. The global log writes to the sinks of the
+//LogType of the default settings. Without LogType records are discarded.
+//The sinks null, screen, slog, writing to slog.Default, file, syslog and journald are registered by this package.
+func NewLogFactoryFromSettings(settings *Settings) (LogFactory, error) {
+	f := &settingsLogFactory{settings: settings, factories: make(map[string]LogFactory), views: make(map[string]*Settings)}
+
+	for _, logType := range logTypesOf(settings.GlobalSettings()) {
+		f.addToView(logType, nil)
+	}
+
+	for sessionID, sessionSettings := range settings.SessionSettings() {
+		sessionID := sessionID
+		for _, logType := range logTypesOf(sessionSettings) {
+			f.addToView(logType, &sessionID)
+		}
+	}
+
+	for logType := range f.views {
+		if _, err := f.factoryFor(logType); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func logTypesOf(settings *SessionSettings) []string {
+	setting, err := settings.Setting(config.LogType)
+	if err != nil {
+		return []string{defaultLogType}
+	}
+
+	var logTypes []string
+	for _, logType := range strings.Split(setting, ",") {
+		logTypes = append(logTypes, strings.TrimSpace(logType))
+	}
+
+	return logTypes
+}
+
+//addToView adds the settings of sessionID, if not nil, to the view of logType, with lock held.
+func (f *settingsLogFactory) addToView(logType string, sessionID *SessionID) {
+	view, ok := f.views[logType]
+	if !ok {
+		view = &Settings{globalSettings: f.settings.GlobalSettings(), sessionSettings: make(map[SessionID]*SessionSettings)}
+		f.views[logType] = view
+	}
+
+	if sessionID != nil {
+		view.sessionSettings[*sessionID] = f.settings.sessionSettings[*sessionID]
+	}
+}
+
+//factoryFor returns the LogFactory of logType, constructing it on first use.
+func (f *settingsLogFactory) factoryFor(logType string) (LogFactory, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if factory, ok := f.factories[logType]; ok {
+		return factory, nil
+	}
+
+	logFactories.RLock()
+	constructor, ok := logFactories.constructors[logType]
+	logFactories.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown LogType %v, expected one of %v", logType, LogFactories())
+	}
+
+	factory, err := constructor(f.views[logType])
+	if err != nil {
+		return nil, err
+	}
+
+	f.factories[logType] = factory
+	return factory, nil
+}
+
+//factoriesFor returns the LogFactory writing to the sinks of logTypes.
+func (f *settingsLogFactory) factoriesFor(logTypes []string) (LogFactory, error) {
+	var factories teeLogFactory
+	for _, logType := range logTypes {
+		factory, err := f.factoryFor(logType)
+		if err != nil {
+			return nil, err
+		}
+		factories = append(factories, factory)
+	}
+
+	if len(factories) == 1 {
+		return factories[0], nil
+	}
+
+	return factories, nil
+}
+
+func (f *settingsLogFactory) Create() (Log, error) {
+	factory, err := f.factoriesFor(logTypesOf(f.settings.GlobalSettings()))
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.Create()
+}
+
+//CreateSessionLog creates the log of sessionID, adding sessions created after the factory, for example from an acceptor template, to the views of their sinks.
+func (f *settingsLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	if _, ok := f.settings.sessionSettings[sessionID]; !ok {
+		return nil, fmt.Errorf("logger not defined for %v", sessionID)
+	}
+
+	logTypes := logTypesOf(f.settings.sessionSettingsFor(sessionID))
+	f.lock.Lock()
+	for _, logType := range logTypes {
+		f.addToView(logType, &sessionID)
+	}
+	f.lock.Unlock()
+
+	factory, err := f.factoriesFor(logTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.CreateSessionLog(sessionID)
+}
+
+//Flush flushes the sinks implementing FlushableLogFactory.
+func (f *settingsLogFactory) Flush() {
+	f.lock.Lock()
+	var factories teeLogFactory
+	for _, factory := range f.factories {
+		factories = append(factories, factory)
+	}
+	f.lock.Unlock()
+
+	factories.Flush()
+}
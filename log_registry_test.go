@@ -0,0 +1,121 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+//recordingLogFactory creates logs recording messages, shared by all sessions.
+type recordingLogFactory struct {
+	log     *recordingLog
+	flushed int
+}
+
+func (f *recordingLogFactory) Create() (Log, error)                    { return f.log, nil }
+func (f *recordingLogFactory) CreateSessionLog(SessionID) (Log, error) { return f.log, nil }
+func (f *recordingLogFactory) Flush()                                  { f.flushed++ }
+
+func TestRegisterLogFactory(t *testing.T) {
+	RegisterLogFactory("registered", func(*Settings) (LogFactory, error) { return NewNullLogFactory(), nil })
+
+	names := LogFactories()
+	if len(names) < 5 || names[0] != "file" {
+		t.Errorf("Unexpected log factories %v", names)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic registering a name twice")
+		}
+	}()
+	RegisterLogFactory("registered", func(*Settings) (LogFactory, error) { return NewNullLogFactory(), nil })
+}
+
+func TestTeeLogFactory(t *testing.T) {
+	first, second := &recordingLogFactory{log: new(recordingLog)}, &recordingLogFactory{log: new(recordingLog)}
+	factory := NewTeeLogFactory(first, NewNullLogFactory(), second)
+
+	log, err := factory.CreateSessionLog(SessionID{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.OnIncoming("hello")
+	log.OnOutgoing("world")
+	if len(first.log.messages) != 2 || len(second.log.messages) != 2 {
+		t.Errorf("Expected messages written to both logs, got %v and %v", first.log.messages, second.log.messages)
+	}
+
+	factory.(FlushableLogFactory).Flush()
+	if first.flushed != 1 || second.flushed != 1 {
+		t.Error("Expected flushable factories flushed")
+	}
+}
+
+func TestNewLogFactoryFromSettings(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "filelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	recording := &recordingLogFactory{log: new(recordingLog)}
+	RegisterLogFactory("recording", func(settings *Settings) (LogFactory, error) {
+		if len(settings.SessionSettings()) != 1 {
+			t.Errorf("Expected settings of the sessions logging to the sink only, got %v", settings.SessionSettings())
+		}
+		return recording, nil
+	})
+
+	cfg := `
+[DEFAULT]
+SenderCompID=TW
+LogType=file
+FileLogPath=` + dirname + `
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=TEE
+LogType=file, recording
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=FILE
+`
+	settings, _ := ParseSettings(strings.NewReader(cfg))
+	factory, err := NewLogFactoryFromSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := factory.CreateSessionLog(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "TEE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.OnIncoming("8=FIX.4.2\x0135=0\x01")
+	if len(recording.log.messages) != 1 {
+		t.Error("Expected message written to the recording sink")
+	}
+
+	if logged, _ := ioutil.ReadFile(dirname + "/FIX.4.2-TW-TEE.messages.current.log"); len(logged) == 0 {
+		t.Error("Expected message written to the file sink")
+	}
+
+	if _, err := factory.Create(); err != nil {
+		t.Error("Did not expect error", err)
+	}
+
+	factory.(FlushableLogFactory).Flush()
+	if recording.flushed != 1 {
+		t.Error("Expected sinks flushed")
+	}
+
+	settings.GlobalSettings().Set(config.LogType, "nonesuch")
+	if _, err := NewLogFactoryFromSettings(settings); err == nil {
+		t.Error("Expected error for unknown LogType")
+	}
+}
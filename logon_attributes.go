@@ -0,0 +1,64 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"time"
+)
+
+//LogonAttributes are the values the counterparty declared on its last Logon, zero values for fields not sent.
+type LogonAttributes struct {
+	HeartBtInt       time.Duration
+	ResetSeqNumFlag  bool
+	DefaultApplVerID string
+
+	//MaxMessageSize is the largest message the counterparty accepts, 0 if not limited.
+	MaxMessageSize int
+	Username       string
+}
+
+//newLogonAttributes returns the attributes declared by logon.
+func newLogonAttributes(logon Message) LogonAttributes {
+	var attributes LogonAttributes
+
+	heartBtInt, resetSeqNumFlag, maxMessageSize := new(fix.IntValue), new(fix.BooleanValue), new(fix.IntValue)
+	if logon.Body.GetField(tag.HeartBtInt, heartBtInt) == nil {
+		attributes.HeartBtInt = time.Duration(heartBtInt.Value) * time.Second
+	}
+
+	if logon.Body.GetField(tag.ResetSeqNumFlag, resetSeqNumFlag) == nil {
+		attributes.ResetSeqNumFlag = resetSeqNumFlag.Value
+	}
+
+	if logon.Body.GetField(tag.MaxMessageSize, maxMessageSize) == nil {
+		attributes.MaxMessageSize = maxMessageSize.Value
+	}
+
+	defaultApplVerID, username := new(fix.StringValue), new(fix.StringValue)
+	if logon.Body.GetField(tag.DefaultApplVerID, defaultApplVerID) == nil {
+		attributes.DefaultApplVerID = defaultApplVerID.Value
+	}
+
+	if logon.Body.GetField(tag.Username, username) == nil {
+		attributes.Username = username.Value
+	}
+
+	return attributes
+}
+
+//CounterpartyLogon returns the attributes the counterparty declared on its last Logon, available to the Application from OnLogon.
+//Safe to call outside the session goroutine and from Application callbacks.
+func (s *Session) CounterpartyLogon() LogonAttributes {
+	s.trafficLock.RLock()
+	defer s.trafficLock.RUnlock()
+
+	return s.traffic.counterpartyLogon
+}
+
+//onCounterpartyLogon records the attributes of the Logon of the counterparty.
+func (s *Session) onCounterpartyLogon(logon Message) {
+	s.trafficLock.Lock()
+	defer s.trafficLock.Unlock()
+
+	s.traffic.counterpartyLogon = newLogonAttributes(logon)
+}
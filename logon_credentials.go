@@ -0,0 +1,148 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/enum"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+)
+
+//Credentials are sent on the Logon of an initiated session.
+type Credentials struct {
+	//Username and Password are sent as tags 553 and 554 if not empty.
+	Username, Password string
+
+	//RawData is sent as tag 96, with RawDataLength, if not empty.
+	RawData string
+}
+
+//CredentialsProvider may be implemented by an Application to supply the credentials of each Logon, for example to fetch rotated passwords from a secret store.
+//If not implemented, the Username, Password, and RawData session settings are sent, resolving the secrets they reference with the SecretsProvider of the session.
+type CredentialsProvider interface {
+	//LogonCredentials is called before each Logon is sent. Returning an error disconnects without logging on.
+	LogonCredentials(sessionID SessionID) (Credentials, error)
+
+	//NewPassword is called when the counterparty reports the password has expired or is due to expire.
+	//The returned password is sent as NewPassword (925) on the next Logon, an empty password leaves the password unchanged.
+	//Once that Logon is accepted LogonCredentials must return the new password.
+	NewPassword(sessionID SessionID) (string, error)
+}
+
+//newCredentials returns the credentials configured in settings.
+func newCredentials(settings *SessionSettings) Credentials {
+	var credentials Credentials
+	credentials.Username, _ = settings.Setting(config.Username)
+	credentials.Password, _ = settings.Setting(config.Password)
+	credentials.RawData, _ = settings.Setting(config.RawData)
+
+	return credentials
+}
+
+//logonCredentials returns the credentials for the next Logon.
+func (s *Session) logonCredentials() (Credentials, error) {
+	if provider, ok := s.application.(CredentialsProvider); ok {
+		return provider.LogonCredentials(s.sessionID)
+	}
+
+	s.reloadLock.RLock()
+	credentials, secrets := s.credentials, s.secrets
+	s.reloadLock.RUnlock()
+
+	return secrets.resolveCredentials(credentials)
+}
+
+//sessionSecrets returns the resolver of the secrets of the session, nil if the settings reference no secrets.
+func (s *Session) sessionSecrets() *secretResolver {
+	s.reloadLock.RLock()
+	defer s.reloadLock.RUnlock()
+
+	return s.secrets
+}
+
+//setLogonCredentials sets the credentials, and any pending password change, on logon.
+func (s *Session) setLogonCredentials(logon MessageBuilder) error {
+	credentials, err := s.logonCredentials()
+	if err != nil {
+		return err
+	}
+
+	if len(credentials.Username) > 0 {
+		logon.Body().Set(field.NewUsername(credentials.Username))
+	}
+
+	if len(credentials.Password) > 0 {
+		logon.Body().Set(field.NewPassword(credentials.Password))
+	}
+
+	if len(credentials.RawData) > 0 {
+		logon.Body().Set(field.NewRawDataLength(len(credentials.RawData)))
+		logon.Body().Set(field.NewRawData(credentials.RawData))
+	}
+
+	if len(s.newPassword) > 0 {
+		logon.Body().Set(field.NewNewPassword(s.newPassword))
+	}
+
+	return nil
+}
+
+//checkSessionStatus handles the SessionStatus of a Logon or Logout received from the counterparty, loggedOn if a Logon.
+func (s *Session) checkSessionStatus(msg Message, loggedOn bool) {
+	status := new(fix.StringValue)
+	msg.Body.GetField(tag.SessionStatus, status)
+
+	switch status.Value {
+	case enum.SessionStatus_NEW_SESSION_PASSWORD_DOES_NOT_COMPLY_WITH_POLICY:
+		logEventf(s.log, LogLevelWarn, LogCategorySession, "New password does not comply with policy")
+		s.newPassword = ""
+
+	case enum.SessionStatus_INVALID_USERNAME_OR_PASSWORD:
+		logEventf(s.log, LogLevelWarn, LogCategorySession, "Invalid username or password")
+		//the secrets may have been rotated since read
+		s.sessionSecrets().invalidate()
+
+	case enum.SessionStatus_ACCOUNT_LOCKED:
+		logEventf(s.log, LogLevelWarn, LogCategorySession, "Account locked")
+	}
+
+	//a password change sent on logon is accepted with the logon, not all counterparties confirm it with SessionStatus
+	if loggedOn {
+		s.passwordChanged()
+	}
+
+	switch status.Value {
+	case enum.SessionStatus_SESSION_PASSWORD_DUE_TO_EXPIRE, enum.SessionStatus_PASSWORD_EXPIRED:
+		logEventf(s.log, LogLevelWarn, LogCategorySession, "Password expired or due to expire")
+		s.sessionSecrets().invalidate()
+		s.requestNewPassword()
+	}
+}
+
+//requestNewPassword asks the CredentialsProvider of the Application for the password to send on the next Logon.
+func (s *Session) requestNewPassword() {
+	provider, ok := s.application.(CredentialsProvider)
+	if !ok {
+		return
+	}
+
+	newPassword, err := provider.NewPassword(s.sessionID)
+	if err != nil {
+		logEventf(s.log, LogLevelError, LogCategorySession, "Cannot get new password: %v", err)
+		return
+	}
+
+	s.newPassword = newPassword
+}
+
+//passwordChanged replaces the configured password with the new password accepted by the counterparty.
+func (s *Session) passwordChanged() {
+	if len(s.newPassword) > 0 {
+		logEventf(s.log, LogLevelInfo, LogCategorySession, "Password changed")
+		s.reloadLock.Lock()
+		s.credentials.Password = s.newPassword
+		s.reloadLock.Unlock()
+
+		s.newPassword = ""
+	}
+}
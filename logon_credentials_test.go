@@ -0,0 +1,108 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+)
+
+type credentialsClient struct {
+	TestClient
+	password    string
+	newPassword string
+	err         error
+}
+
+func (c *credentialsClient) LogonCredentials(sessionID SessionID) (Credentials, error) {
+	return Credentials{Username: "USER", Password: c.password}, c.err
+}
+
+func (c *credentialsClient) NewPassword(sessionID SessionID) (string, error) {
+	return c.newPassword, nil
+}
+
+func newTestLogon() MessageBuilder {
+	logon := NewMessageBuilder()
+	logon.Header().Set(field.NewBeginString("FIX.4.4"))
+	logon.Header().Set(field.NewMsgType("A"))
+	return logon
+}
+
+func TestSession_LogonCredentialsFromSettings(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set(config.Username, "USER")
+	settings.Set(config.Password, "SECRET")
+	settings.Set(config.RawData, "RAW\001DATA")
+
+	session := Session{application: &TestClient{}, credentials: newCredentials(settings)}
+
+	logon := newTestLogon()
+	if err := session.setLogonCredentials(logon); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := buildAndParse(t, logon)
+	checkStringField(t, msg.Body, tag.Username, "USER")
+	checkStringField(t, msg.Body, tag.Password, "SECRET")
+	checkStringField(t, msg.Body, tag.RawDataLength, "8")
+	checkStringField(t, msg.Body, tag.RawData, "RAW\001DATA")
+
+	if msg.Body.Has(tag.NewPassword) {
+		t.Error("Unexpected NewPassword")
+	}
+}
+
+func TestSession_LogonCredentialsProvider(t *testing.T) {
+	app := &credentialsClient{password: "ROTATED"}
+	session := Session{application: app, credentials: Credentials{Password: "SETTING"}}
+
+	logon := newTestLogon()
+	if err := session.setLogonCredentials(logon); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := buildAndParse(t, logon)
+	checkStringField(t, msg.Body, tag.Username, "USER")
+	checkStringField(t, msg.Body, tag.Password, "ROTATED")
+
+	app.err = fmt.Errorf("secret store unavailable")
+	if err := session.setLogonCredentials(newTestLogon()); err == nil {
+		t.Error("Expected provider error")
+	}
+}
+
+func TestSession_PasswordChange(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set(config.Password, "OLD")
+
+	app := &credentialsClient{password: "OLD", newPassword: "NEW"}
+	session := Session{application: app, log: nullLog{}, credentials: newCredentials(settings)}
+
+	logout, _ := parseMessage(rawMessage("FIX.4.4", "35=5\00134=2\00149=TW\00156=ISLD\0011409=8\001"))
+	session.checkSessionStatus(*logout, false)
+
+	if session.newPassword != "NEW" {
+		t.Fatalf("Expected pending new password, got %q", session.newPassword)
+	}
+
+	logon := newTestLogon()
+	session.setLogonCredentials(logon)
+	msg := buildAndParse(t, logon)
+	checkStringField(t, msg.Body, tag.Password, "OLD")
+	checkStringField(t, msg.Body, tag.NewPassword, "NEW")
+
+	rejected, _ := parseMessage(rawMessage("FIX.4.4", "35=A\00134=1\00149=TW\00156=ISLD\0011409=3\001"))
+	session.checkSessionStatus(*rejected, true)
+	if session.newPassword != "" || session.credentials.Password != "OLD" {
+		t.Errorf("Expected password change to be dropped, got new %q password %q", session.newPassword, session.credentials.Password)
+	}
+
+	session.newPassword = "NEW"
+	accepted, _ := parseMessage(rawMessage("FIX.4.4", "35=A\00134=1\00149=TW\00156=ISLD\001"))
+	session.checkSessionStatus(*accepted, true)
+	if session.newPassword != "" || session.credentials.Password != "NEW" {
+		t.Errorf("Expected password to be changed, got new %q password %q", session.newPassword, session.credentials.Password)
+	}
+}
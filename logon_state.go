@@ -1,6 +1,7 @@
 package quickfix
 
 import (
+	"fmt"
 	"github.com/quickfixgo/quickfix/fix"
 	"github.com/quickfixgo/quickfix/fix/tag"
 )
@@ -11,20 +12,38 @@ func (s logonState) FixMsgIn(session *Session, msg Message) (nextState sessionSt
 	msgType := new(fix.StringValue)
 	if err := msg.Header.GetField(tag.MsgType, msgType); err == nil && msgType.Value == "A" {
 		if err := session.handleLogon(msg); err != nil {
-			session.log.OnEvent(err.Error())
+			logEventf(session.log, LogLevelError, LogCategorySession, "%v", err)
+			session.onLogonRejected(err)
 			return latentState{}
 		}
+		session.onLogonAccepted()
+
+		if session.resendPending() {
+			return resendState{}
+		}
 
 		return inSession{}
 	}
 
-	session.log.OnEventf("Invalid Session State: Received Msg %v while waiting for Logon", msg)
+	if msgType.Value == "5" {
+		logEventf(session.log, LogLevelWarn, LogCategorySession, "Received logout while waiting for logon")
+		session.checkSessionStatus(msg, false)
+
+		reason := fmt.Errorf("Received logout while waiting for logon")
+		if text := new(fix.StringValue); msg.Body.GetField(tag.Text, text) == nil {
+			reason = fmt.Errorf("Received logout while waiting for logon: %v", text.Value)
+		}
+		session.onLogonRejected(reason)
+		return latentState{}
+	}
+
+	logEventf(session.log, LogLevelWarn, LogCategorySession, "Invalid Session State: Received Msg %v while waiting for Logon", msg)
 	return latentState{}
 }
 
 func (s logonState) Timeout(session *Session, e event) (nextState sessionState) {
 	if e == logonTimeout {
-		session.log.OnEvent("Timed out waiting for logon response")
+		logEventf(session.log, LogLevelWarn, LogCategorySession, "Timed out waiting for logon response")
 		return latentState{}
 	}
 
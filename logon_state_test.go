@@ -0,0 +1,211 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+	"time"
+)
+
+//adminClient refuses admin messages with reject and adds Username to each Logon sent.
+type adminClient struct {
+	TestClient
+	reject MessageRejectError
+}
+
+func (c *adminClient) ToAdmin(msgBuilder MessageBuilder, sessionID SessionID) {
+	msgType := new(fix.StringValue)
+	if msgBuilder.Header().GetField(tag.MsgType, msgType); msgType.Value == "A" {
+		msgBuilder.Body().Set(field.NewUsername("gateway"))
+	}
+}
+
+func (c *adminClient) FromAdmin(msg Message, sessionID SessionID) MessageRejectError {
+	return c.reject
+}
+
+func newTestAdminMessage(msgType string, seqNum int) Message {
+	builder := NewMessageBuilder()
+	builder.Header().Set(field.NewBeginString(fix.BeginString_FIX42))
+	builder.Header().Set(field.NewMsgType(msgType))
+	builder.Header().Set(field.NewMsgSeqNum(seqNum))
+	builder.Header().Set(field.NewSenderCompID("ISLD"))
+	builder.Header().Set(field.NewTargetCompID("TW"))
+	builder.Header().Set(fix.NewUTCTimestampField(tag.SendingTime, time.Now()))
+	if msgType == "A" {
+		builder.Body().Set(field.NewEncryptMethod(0))
+		builder.Body().Set(field.NewHeartBtInt(30))
+	}
+
+	msgBytes, _ := builder.Build()
+	msg, _ := parseMessage(msgBytes)
+	return *msg
+}
+
+func newTestAdminSession(app Application) *Session {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	return &Session{
+		sessionID:    SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"},
+		header:       newHeaderTemplate(SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"}),
+		store:        store,
+		application:  app,
+		messageOut:   make(chan []byte, 10),
+		sessionEvent: make(chan event, 10),
+		log:          nullLog{},
+		stateTimer:   eventTimer{Task: func() {}},
+	}
+}
+
+//sentMsgTypes returns the MsgTypes of the messages sent by session, and the Username of the last.
+func sentMsgTypes(t *testing.T, session *Session) (msgTypes []string, username string) {
+	for len(session.messageOut) > 0 {
+		msg, err := parseMessage(<-session.messageOut)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msgType, sentUsername := new(fix.StringValue), new(fix.StringValue)
+		msg.Header.GetField(tag.MsgType, msgType)
+		msg.Body.GetField(tag.Username, sentUsername)
+		msgTypes, username = append(msgTypes, msgType.Value), sentUsername.Value
+	}
+
+	return
+}
+
+func TestLogonState_FromAdminLogon(t *testing.T) {
+	var tests = []struct {
+		reject           MessageRejectError
+		expectedLoggedOn bool
+		expectedSent     []string
+	}{
+		{nil, true, []string{"A"}},
+		{NewRejectLogonError("unknown user"), false, []string{"5"}},
+		{NewDisconnectError("blocked"), false, nil},
+		{NewIgnoreMessageError("cannot ignore logon"), false, nil},
+	}
+
+	for _, test := range tests {
+		session := newTestAdminSession(&adminClient{reject: test.reject})
+
+		nextState := logonState{}.FixMsgIn(session, newTestAdminMessage("A", 1))
+		if _, loggedOn := nextState.(inSession); loggedOn != test.expectedLoggedOn {
+			t.Errorf("%v: expected logged on %v got state %T", test.reject, test.expectedLoggedOn, nextState)
+		}
+
+		sent, username := sentMsgTypes(t, session)
+		if len(sent) != len(test.expectedSent) || (len(sent) > 0 && sent[0] != test.expectedSent[0]) {
+			t.Errorf("%v: expected %v sent got %v", test.reject, test.expectedSent, sent)
+		}
+
+		if test.expectedLoggedOn && username != "gateway" {
+			t.Errorf("Expected Logon response modified by ToAdmin, got Username %v", username)
+		}
+	}
+}
+
+func TestLogonState_FromAdminLogonResponse(t *testing.T) {
+	session := newTestAdminSession(&adminClient{reject: NewRejectLogonError("unexpected counterparty")})
+	session.initiateLogon = true
+
+	if nextState := (logonState{}).FixMsgIn(session, newTestAdminMessage("A", 1)); nextState != (latentState{}) {
+		t.Errorf("Expected refused logon response to disconnect got state %T", nextState)
+	}
+
+	if sent, _ := sentMsgTypes(t, session); len(sent) != 1 || sent[0] != "5" {
+		t.Errorf("Expected Logout sent got %v", sent)
+	}
+}
+
+func TestInSession_FromAdminReject(t *testing.T) {
+	var tests = []struct {
+		reject          MessageRejectError
+		expectedState   sessionState
+		expectedSent    []string
+		expectedNextSeq int
+	}{
+		{NewIgnoreMessageError("unwanted heartbeat"), inSession{}, nil, 2},
+		{NewDisconnectError("blocked"), latentState{}, nil, 1},
+		{NewRejectLogonError("goodbye"), logoutState{}, []string{"5"}, 1},
+		{NewMessageRejectError("bad heartbeat", rejectReasonOther, nil), inSession{}, []string{"3"}, 2},
+	}
+
+	for _, test := range tests {
+		session := newTestAdminSession(&adminClient{reject: test.reject})
+
+		nextState := inSession{}.FixMsgIn(session, newTestAdminMessage("0", 1))
+		if nextState != test.expectedState {
+			t.Errorf("%v: expected state %T got %T", test.reject, test.expectedState, nextState)
+		}
+
+		if sent, _ := sentMsgTypes(t, session); len(sent) != len(test.expectedSent) || (len(sent) > 0 && sent[0] != test.expectedSent[0]) {
+			t.Errorf("%v: expected %v sent got %v", test.reject, test.expectedSent, sent)
+		}
+
+		if next := session.store.NextTargetMsgSeqNum(); next != test.expectedNextSeq {
+			t.Errorf("%v: expected next target %v got %v", test.reject, test.expectedNextSeq, next)
+		}
+	}
+}
+
+func TestLogonState_CounterpartyLogon(t *testing.T) {
+	session := newTestAdminSession(&TestClient{})
+
+	builder := NewMessageBuilder()
+	builder.Header().Set(field.NewBeginString(fix.BeginString_FIX42))
+	builder.Header().Set(field.NewMsgType("A"))
+	builder.Header().Set(field.NewMsgSeqNum(1))
+	builder.Header().Set(field.NewSenderCompID("ISLD"))
+	builder.Header().Set(field.NewTargetCompID("TW"))
+	builder.Header().Set(fix.NewUTCTimestampField(tag.SendingTime, time.Now()))
+	builder.Body().Set(field.NewEncryptMethod(0))
+	builder.Body().Set(field.NewHeartBtInt(45))
+	builder.Body().Set(field.NewResetSeqNumFlag(true))
+	builder.Body().Set(field.NewMaxMessageSize(4096))
+	builder.Body().Set(field.NewUsername("isld-user"))
+	msgBytes, _ := builder.Build()
+	logon, _ := parseMessage(msgBytes)
+
+	if nextState := (logonState{}).FixMsgIn(session, *logon); nextState != (inSession{}) {
+		t.Fatalf("Expected logon accepted got state %T", nextState)
+	}
+
+	expected := LogonAttributes{HeartBtInt: 45 * time.Second, ResetSeqNumFlag: true, MaxMessageSize: 4096, Username: "isld-user"}
+	if attributes := session.CounterpartyLogon(); attributes != expected {
+		t.Errorf("Expected %+v got %+v", expected, attributes)
+	}
+
+	if info := session.Info(); info.CounterpartyLogon != expected {
+		t.Errorf("Expected %+v in session info got %+v", expected, info.CounterpartyLogon)
+	}
+}
+
+func TestLogonState_ResetOnLogonReply(t *testing.T) {
+	session := newTestAdminSession(&TestClient{})
+	session.resetOnLogon = true
+	session.store.SetNextSenderMsgSeqNum(5)
+
+	//the logon does not request the reset, ResetSeqNumFlag is not set
+	if nextState := (logonState{}).FixMsgIn(session, newTestAdminMessage("A", 1)); nextState != (inSession{}) {
+		t.Fatalf("Expected logon accepted got state %T", nextState)
+	}
+
+	if len(session.messageOut) != 1 {
+		t.Fatalf("Expected Logon response sent, got %v messages", len(session.messageOut))
+	}
+	reply, err := parseMessage(<-session.messageOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resetSeqNumFlag := new(fix.BooleanValue)
+	if err := reply.Body.GetField(tag.ResetSeqNumFlag, resetSeqNumFlag); err != nil || !resetSeqNumFlag.Value {
+		t.Errorf("Expected ResetSeqNumFlag=Y on the Logon response of a session reset on logon, got %v %v", resetSeqNumFlag.Value, err)
+	}
+
+	msgSeqNum := new(fix.IntValue)
+	if reply.Header.GetField(tag.MsgSeqNum, msgSeqNum); msgSeqNum.Value != 1 {
+		t.Errorf("Expected Logon response MsgSeqNum 1, got %v", msgSeqNum.Value)
+	}
+}
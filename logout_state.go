@@ -1,16 +1,26 @@
 package quickfix
 
+import "github.com/quickfixgo/quickfix/fix/field"
+
 type logoutState struct {
 }
 
 func (state logoutState) FixMsgIn(session *Session, msg Message) (nextState sessionState) {
+	var msgType field.MsgTypeField
+	if err := msg.Header.Get(&msgType); err == nil && msgType.Value == "5" {
+		logEventf(session.log, LogLevelInfo, LogCategorySession, "Received logout response")
+		session.application.OnLogout(session.sessionID)
+		session.onLogout()
+		return latentState{}
+	}
+
 	return state
 }
 
 func (state logoutState) Timeout(session *Session, event event) (nextState sessionState) {
 	switch event {
 	case logoutTimeout:
-		session.log.OnEvent("Timed out waiting for Logout response")
+		logEventf(session.log, LogLevelWarn, LogCategorySession, "Timed out waiting for Logout response")
 		return latentState{}
 	}
 
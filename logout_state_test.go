@@ -0,0 +1,62 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"testing"
+)
+
+func newLogoutTestSession(resetOnLogout bool) *Session {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	store.SetNextSenderMsgSeqNum(10)
+	store.SetNextTargetMsgSeqNum(20)
+
+	return &Session{
+		sessionID:     SessionID{BeginString: fix.BeginString_FIX44, SenderCompID: "TW", TargetCompID: "ISLD"},
+		header:        newHeaderTemplate(SessionID{BeginString: fix.BeginString_FIX44, SenderCompID: "TW", TargetCompID: "ISLD"}),
+		store:         store,
+		application:   &TestClient{},
+		messageOut:    make(chan []byte, 10),
+		log:           nullLog{},
+		stateTimer:    eventTimer{Task: func() {}},
+		resetOnLogout: resetOnLogout,
+	}
+}
+
+func TestLogoutState_FixMsgInLogout(t *testing.T) {
+	logout, _ := parseMessage(rawMessage(fix.BeginString_FIX44, "35=5\00134=20\00149=ISLD\00156=TW\001"))
+
+	var testCases = []struct {
+		resetOnLogout      bool
+		expectedNextSender int
+		expectedNextTarget int
+	}{
+		{false, 10, 20},
+		{true, 1, 1},
+	}
+
+	for _, tc := range testCases {
+		session := newLogoutTestSession(tc.resetOnLogout)
+
+		if _, ok := (logoutState{}).FixMsgIn(session, *logout).(latentState); !ok {
+			t.Error("Expected latentState after logout response")
+		}
+
+		if session.store.NextSenderMsgSeqNum() != tc.expectedNextSender || session.store.NextTargetMsgSeqNum() != tc.expectedNextTarget {
+			t.Errorf("ResetOnLogout=%v expected next sender %v target %v, got %v %v", tc.resetOnLogout,
+				tc.expectedNextSender, tc.expectedNextTarget, session.store.NextSenderMsgSeqNum(), session.store.NextTargetMsgSeqNum())
+		}
+	}
+}
+
+func TestInSession_HandleLogoutResetOnLogout(t *testing.T) {
+	logout, _ := parseMessage(rawMessage(fix.BeginString_FIX44, "35=5\00134=20\00149=ISLD\00156=TW\001"))
+	session := newLogoutTestSession(true)
+
+	if _, ok := (inSession{}).handleLogout(session, *logout).(latentState); !ok {
+		t.Error("Expected latentState after logout")
+	}
+
+	if session.store.NextSenderMsgSeqNum() != 1 || session.store.NextTargetMsgSeqNum() != 1 {
+		t.Errorf("Expected sequence numbers reset, got %v %v", session.store.NextSenderMsgSeqNum(), session.store.NextTargetMsgSeqNum())
+	}
+}
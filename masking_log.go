@@ -0,0 +1,61 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"strconv"
+	"strings"
+)
+
+//maskingLog writes the messages of a session to its Log with the tags configured by LogMaskTags redacted.
+//The messages sent and received are not modified.
+type maskingLog struct {
+	Log
+	masker *fix.Masker
+}
+
+func (l maskingLog) OnIncoming(msg string) {
+	l.Log.OnIncoming(l.mask(msg))
+}
+
+func (l maskingLog) OnOutgoing(msg string) {
+	l.Log.OnOutgoing(l.mask(msg))
+}
+
+func (l maskingLog) OnLeveledEvent(level LogLevel, category LogCategory, msg string) {
+	logEventf(l.Log, level, category, "%s", msg)
+}
+
+//mask returns msg with its BodyLength and CheckSum recomputed for the redacted values.
+//A message that cannot be parsed is not logged, as it may contain the values redacted.
+func (l maskingLog) mask(msg string) string {
+	masked, err := l.masker.Mask([]byte(msg))
+	if err != nil {
+		return fmt.Sprintf("%v (message of %d bytes redacted: %v)", fix.RedactedValue, len(msg), err)
+	}
+
+	return string(masked)
+}
+
+//parseLogMaskTags maps the LogMaskTags setting, a comma separated list of tags, to the tags to redact.
+//Credentials and Accounts stand for fix.CredentialTags and fix.AccountTags.
+func parseLogMaskTags(setting string) ([]fix.Tag, error) {
+	var tags []fix.Tag
+	for _, value := range strings.Split(setting, ",") {
+		switch value = strings.TrimSpace(value); value {
+		case "Credentials":
+			tags = append(tags, fix.CredentialTags...)
+		case "Accounts":
+			tags = append(tags, fix.AccountTags...)
+		default:
+			t, err := strconv.Atoi(value)
+			if err != nil || t <= 0 {
+				return nil, fmt.Errorf("invalid %v %v, expected tags, Credentials, or Accounts", config.LogMaskTags, setting)
+			}
+			tags = append(tags, fix.Tag(t))
+		}
+	}
+
+	return tags, nil
+}
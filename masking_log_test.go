@@ -0,0 +1,54 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"strings"
+	"testing"
+)
+
+type recordingLog struct {
+	nullLog
+	messages []string
+}
+
+func (l *recordingLog) OnIncoming(s string) { l.messages = append(l.messages, s) }
+func (l *recordingLog) OnOutgoing(s string) { l.messages = append(l.messages, s) }
+
+func TestParseLogMaskTags(t *testing.T) {
+	tags, err := parseLogMaskTags("Credentials, 1,9999")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tags) != len(fix.CredentialTags)+2 || tags[0] != 554 || tags[len(tags)-1] != 9999 {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+
+	for _, setting := range []string{"", "Password", "554,-1"} {
+		if _, err := parseLogMaskTags(setting); err == nil {
+			t.Errorf("Expected error for %q", setting)
+		}
+	}
+}
+
+func TestMaskingLog(t *testing.T) {
+	recorded := new(recordingLog)
+	log := maskingLog{Log: recorded, masker: fix.NewMasker(nil).Redact(554)}
+
+	logon := "8=FIX.4.2\x019=35\x0135=A\x0134=1\x0149=TW\x0156=ISLD\x01554=secret\x0110=201\x01"
+	log.OnIncoming(logon)
+	log.OnOutgoing("8=FIX.4.2\x01554=secret")
+
+	if len(recorded.messages) != 2 {
+		t.Fatalf("Expected 2 messages logged, got %v", recorded.messages)
+	}
+
+	expected := "8=FIX.4.2\x019=32\x0135=A\x0134=1\x0149=TW\x0156=ISLD\x01554=***\x0110=061\x01"
+	if recorded.messages[0] != expected {
+		t.Errorf("Expected %q got %q", expected, recorded.messages[0])
+	}
+
+	if strings.Contains(recorded.messages[1], "secret") {
+		t.Errorf("Expected message that cannot be masked redacted, got %q", recorded.messages[1])
+	}
+}
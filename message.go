@@ -2,6 +2,7 @@ package quickfix
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/quickfixgo/quickfix/fix"
 	"github.com/quickfixgo/quickfix/fix/tag"
@@ -17,6 +18,9 @@ type Message struct {
 	//ReceiveTime is the time that this message was read from the socket connection
 	ReceiveTime time.Time
 
+	//Timestamps are the times of the stages of a message received, with MessageTimestamps=Y
+	Timestamps MessageTimestamps
+
 	rawMessage []byte
 
 	//slice of Bytes corresponding to the message body
@@ -24,6 +28,9 @@ type Message struct {
 
 	//field bytes as they appear in the raw message
 	fields []fieldBytes
+
+	//ctx is the context of a message received, carrying its trace
+	ctx context.Context
 }
 
 //parseError is returned when bytes cannot be parsed as a FIX message.
@@ -33,14 +40,80 @@ type parseError struct {
 
 func (e parseError) Error() string { return fmt.Sprintf("error parsing message: %s", e.OrigError) }
 
+//MessageTooLargeError is returned when reading a message with a BodyLength exceeding the configured maximum message size.
+//The message body is discarded, Header holds the standard header fields that preceded it.
+type MessageTooLargeError struct {
+	Header         FieldMap
+	BodyLength     int
+	MaxMessageSize int
+}
+
+func (e MessageTooLargeError) Error() string {
+	return fmt.Sprintf("message BodyLength %d exceeds maximum message size %d", e.BodyLength, e.MaxMessageSize)
+}
+
+//parseHeader reads the standard header fields at the start of a possibly incomplete raw message.
+func parseHeader(rawMessage []byte) FieldMap {
+	var header fieldMap
+	header.init(headerFieldOrder)
+
+	for len(rawMessage) > 0 {
+		parsedFieldBytes := new(fieldBytes)
+
+		var err error
+		if rawMessage, err = extractField(parsedFieldBytes, rawMessage); err != nil || !tag.IsHeader(parsedFieldBytes.Tag) {
+			break
+		}
+
+		header.fieldLookup[parsedFieldBytes.Tag] = parsedFieldBytes
+	}
+
+	return header
+}
+
 //parseMessage constructs a Message from a byte slice wrapping a FIX message.
+//The Message is returned with an error if its fields were parsed but its BodyLength is incorrect.
 func parseMessage(rawMessage []byte) (*Message, error) {
-	var header, body, trailer fieldMap
-	header.init(headerFieldOrder)
-	body.init(normalFieldOrder)
-	trailer.init(trailerFieldOrder)
+	msg := new(Message)
+	parsed, err := msg.parse(rawMessage)
+	if err != nil && !parsed {
+		return nil, err
+	}
 
-	msg := &Message{Header: header, Body: body, Trailer: trailer, rawMessage: rawMessage}
+	return msg, err
+}
+
+//reuseFieldMap clears and returns *m if the fieldMap of a message parsed, otherwise sets *m to a new fieldMap.
+//A fieldMap is reused as it is, storing a fieldMap as a FieldMap allocates.
+func reuseFieldMap(m *FieldMap, ordering fieldOrder) fieldMap {
+	if parsed, ok := (*m).(fieldMap); ok && parsed.fieldLookup != nil && parsed.buffers == nil {
+		parsed.clear()
+		return parsed
+	}
+
+	var fields fieldMap
+	fields.init(ordering)
+	*m = fields
+	return fields
+}
+
+//Parse parses rawMessage into m, replacing the fields of m. The fields of m reference rawMessage, which must not be modified while m is used.
+//A Message parsed into again reuses the storage of its fields, so that once it has held a message with as many fields, parsing performs
+//no heap allocations. Its previous fields must no longer be used, including by copies of the Message. The Messages passed to the
+//Application by sessions are not parsed into again, and may be kept.
+func (m *Message) Parse(rawMessage []byte) error {
+	_, err := m.parse(rawMessage)
+	return err
+}
+
+//parse parses rawMessage into m, returning true once its fields are parsed, even if its BodyLength is incorrect.
+func (m *Message) parse(rawMessage []byte) (parsed bool, err error) {
+	header := reuseFieldMap(&m.Header, headerFieldOrder)
+	body := reuseFieldMap(&m.Body, normalFieldOrder)
+	trailer := reuseFieldMap(&m.Trailer, trailerFieldOrder)
+
+	fields := m.fields
+	*m = Message{Header: m.Header, Body: m.Body, Trailer: m.Trailer, rawMessage: rawMessage}
 
 	//allocate fields in one chunk
 	fieldCount := 0
@@ -49,42 +122,52 @@ func parseMessage(rawMessage []byte) (*Message, error) {
 			fieldCount++
 		}
 	}
-	msg.fields = make([]fieldBytes, fieldCount)
+
+	if cap(fields) >= fieldCount {
+		m.fields = fields[:fieldCount]
+		recordParsed(0)
+	} else {
+		m.fields = make([]fieldBytes, fieldCount)
+		recordParsed(fieldCount)
+	}
 
 	fieldIndex := 0
-	var err error
 
 	//message must start with begin string, body length, msg type
-	if rawMessage, err = extractSpecificField(&msg.fields[fieldIndex], tag.BeginString, rawMessage); err != nil {
-		return nil, err
+	if rawMessage, err = extractSpecificField(&m.fields[fieldIndex], tag.BeginString, rawMessage); err != nil {
+		return false, err
 	}
 
-	header.fieldLookup[msg.fields[fieldIndex].Tag] = &msg.fields[fieldIndex]
+	header.fieldLookup[m.fields[fieldIndex].Tag] = &m.fields[fieldIndex]
 	fieldIndex++
 
-	parsedFieldBytes := &msg.fields[fieldIndex]
+	parsedFieldBytes := &m.fields[fieldIndex]
 	if rawMessage, err = extractSpecificField(parsedFieldBytes, tag.BodyLength, rawMessage); err != nil {
-		return nil, err
+		return false, err
 	}
 
 	header.fieldLookup[parsedFieldBytes.Tag] = parsedFieldBytes
 	fieldIndex++
 
-	parsedFieldBytes = &msg.fields[fieldIndex]
+	parsedFieldBytes = &m.fields[fieldIndex]
 	if rawMessage, err = extractSpecificField(parsedFieldBytes, tag.MsgType, rawMessage); err != nil {
-		return nil, err
+		return false, err
 	}
 
 	header.fieldLookup[parsedFieldBytes.Tag] = parsedFieldBytes
 	fieldIndex++
 
-	trailerBytes := []byte{}
+	var trailerBytes []byte
 	foundBody := false
 	for {
-		parsedFieldBytes = &msg.fields[fieldIndex]
-		rawMessage, err = extractField(parsedFieldBytes, rawMessage)
+		parsedFieldBytes = &m.fields[fieldIndex]
+		if dataTag, length, ok := dataFieldLength(m.fields[fieldIndex-1]); ok {
+			rawMessage, err = extractDataField(parsedFieldBytes, dataTag, length, rawMessage)
+		} else {
+			rawMessage, err = extractField(parsedFieldBytes, rawMessage)
+		}
 		if err != nil {
-			return nil, err
+			return false, err
 		}
 
 		switch {
@@ -102,19 +185,22 @@ func parseMessage(rawMessage []byte) (*Message, error) {
 		}
 
 		if !foundBody {
-			msg.bodyBytes = rawMessage
+			m.bodyBytes = rawMessage
 		}
 
 		fieldIndex++
 	}
 
+	//data fields containing the delimiter leave unused fields
+	m.fields = m.fields[:fieldIndex+1]
+
 	//body length would only be larger than trailer if fields out of order
-	if len(msg.bodyBytes) > len(trailerBytes) {
-		msg.bodyBytes = msg.bodyBytes[:len(msg.bodyBytes)-len(trailerBytes)]
+	if len(m.bodyBytes) > len(trailerBytes) {
+		m.bodyBytes = m.bodyBytes[:len(m.bodyBytes)-len(trailerBytes)]
 	}
 
 	length := 0
-	for _, field := range msg.fields {
+	for _, field := range m.fields {
 		switch field.Tag {
 		case tag.BeginString, tag.BodyLength, tag.CheckSum: //tags do not contribute to length
 		default:
@@ -122,16 +208,24 @@ func parseMessage(rawMessage []byte) (*Message, error) {
 		}
 	}
 
-	bodyLength := new(fix.IntValue)
-	msg.Header.GetField(tag.BodyLength, bodyLength)
-	if bodyLength.Value != length {
-		return msg, parseError{OrigError: fmt.Sprintf("Incorrect Message Length, expected %d, got %d", bodyLength.Value, length)}
+	if bodyLength, _ := fix.ParseUInt(m.fields[1].Value); bodyLength != length {
+		return true, parseError{OrigError: fmt.Sprintf("Incorrect Message Length, expected %d, got %d", bodyLength, length)}
 	}
 
-	return msg, nil
+	return true, nil
 }
 
 //reverseRoute returns a message builder with routing header fields initialized as the reverse of this message.
+//Context returns the context of a message received, carrying its trace if a MessageTracer is set, so that the Application may
+//continue the trace of the message in FromAdmin and FromApp. Returns context.Background() if the message is not traced.
+func (m *Message) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+
+	return m.ctx
+}
+
 func (m *Message) reverseRoute() MessageBuilder {
 	reverseBuilder := NewMessageBuilder()
 
@@ -214,10 +308,13 @@ func (m *Message) rebuild() {
 
 	trailer.Set(newCheckSum(checkSum))
 
-	var b bytes.Buffer
-	header.write(&b)
+	//the message is written to a scratch buffer from the pool, and copied out at its exact size to be kept by the caller
+	b := bytes.NewBuffer(getBuffer(len(m.rawMessage) + 64))
+	header.write(b)
 	b.Write(m.bodyBytes)
-	trailer.write(&b)
+	trailer.write(b)
 
-	m.rawMessage = b.Bytes()
+	m.rawMessage = append(make([]byte, 0, b.Len()), b.Bytes()...)
+	putBuffer(b.Bytes())
+	recordSerialized(len(m.rawMessage))
 }
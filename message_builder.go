@@ -1,8 +1,6 @@
 package quickfix
 
 import (
-	"bytes"
-	"github.com/quickfixgo/quickfix/fix"
 	"github.com/quickfixgo/quickfix/fix/tag"
 )
 
@@ -13,38 +11,100 @@ type MessageBuilder interface {
 	Build() ([]byte, error)
 }
 
+//ReusableMessageBuilder is a MessageBuilder that may be reset and built into a buffer of the caller, implemented by the MessageBuilders
+//of NewMessageBuilder. Once warmed up by a message like the next, a builder Reset, set with fields whose values implement
+//FieldValueAppender, such as those of package fix reused from message to message, and built with AppendBuild into a reused buffer
+//performs no heap allocations.
+type ReusableMessageBuilder interface {
+	MessageBuilder
+
+	//Reset removes every field, keeping their storage for the fields set next. The fields, and the values read from them
+	//without copying, must not be used once Reset, nor the builder Reset while a message sent with it may still be queued.
+	Reset()
+
+	//AppendBuild appends the message to dst, returning the extended buffer, as Build.
+	AppendBuild(dst []byte) ([]byte, error)
+}
+
 type messageBuilder struct {
 	header  fieldMap
 	trailer fieldMap
 	body    fieldMap
+
+	//headerMap, trailerMap and bodyMap are the field maps as MutableFieldMaps, so that getting them does not allocate
+	headerMap, trailerMap, bodyMap MutableFieldMap
+
+	//template is the header of the session sending the message, if set by it
+	template *headerTemplate
 }
 
+//NewMessageBuilder returns an empty MessageBuilder, implementing ReusableMessageBuilder.
 func NewMessageBuilder() MessageBuilder {
+	return NewReusableMessageBuilder()
+}
+
+//NewReusableMessageBuilder returns an empty ReusableMessageBuilder.
+func NewReusableMessageBuilder() ReusableMessageBuilder {
 	m := &messageBuilder{}
-	m.header.init(headerFieldOrder)
-	m.trailer.init(trailerFieldOrder)
-	m.body.init(normalFieldOrder)
+	m.header.initReusable(headerFieldOrder)
+	m.trailer.initReusable(trailerFieldOrder)
+	m.body.initReusable(normalFieldOrder)
+	m.headerMap, m.trailerMap, m.bodyMap = m.header, m.trailer, m.body
 	return m
 }
 
-func (m messageBuilder) Header() MutableFieldMap  { return m.header }
-func (m messageBuilder) Trailer() MutableFieldMap { return m.trailer }
-func (m messageBuilder) Body() MutableFieldMap    { return m.body }
+func (m *messageBuilder) Header() MutableFieldMap  { return m.headerMap }
+func (m *messageBuilder) Trailer() MutableFieldMap { return m.trailerMap }
+func (m *messageBuilder) Body() MutableFieldMap    { return m.bodyMap }
 
-func (m messageBuilder) Build() ([]byte, error) {
-	m.cook()
+func (m *messageBuilder) Build() ([]byte, error) {
+	t := m.cook()
+
+	return m.appendFields(make([]byte, 0, m.header.size()+m.body.size()+m.trailer.size()), t, false), nil
+}
 
-	var b bytes.Buffer
-	m.header.write(&b)
-	m.body.write(&b)
-	m.trailer.write(&b)
+func (m *messageBuilder) AppendBuild(dst []byte) ([]byte, error) {
+	t := m.cook()
 
-	return b.Bytes(), nil
+	return m.appendFields(dst, t, true), nil
 }
 
-func (m messageBuilder) cook() {
-	bodyLength := m.header.length() + m.body.length() + m.trailer.length()
-	m.header.Set(fix.NewIntField(tag.BodyLength, bodyLength))
-	checkSum := (m.header.total() + m.body.total() + m.trailer.total()) % 256
-	m.trailer.Set(newCheckSum(checkSum))
+func (m *messageBuilder) Reset() {
+	m.releaseTemplate()
+	m.header.clear()
+	m.body.clear()
+	m.trailer.clear()
+}
+
+//appendFields appends the fields of the cooked message to dst, splicing in the header template t if not nil. The tags are sorted in the
+//storage of the builder if reuse, as AppendBuild, the builder not being read concurrently with, otherwise in slices of their own.
+func (m *messageBuilder) appendFields(dst []byte, t *headerTemplate, reuse bool) []byte {
+	sortedTags := fieldMap.sortedTags
+	if reuse {
+		sortedTags = fieldMap.reusedSortedTags
+	}
+
+	start := len(dst)
+	dst = m.appendHeader(dst, t, sortedTags(m.header))
+	dst = m.body.appendTo(dst, sortedTags(m.body))
+	dst = m.trailer.appendTo(dst, sortedTags(m.trailer))
+
+	recordSerialized(len(dst) - start)
+	return dst
+}
+
+//cook sets the BodyLength and CheckSum of the message, returning the header template spliced into the message built, if any.
+func (m *messageBuilder) cook() *headerTemplate {
+	t := m.splicedTemplate()
+
+	bodyLength := m.headerLength(t) + m.body.length() + m.trailer.length()
+	m.header.setInt(tag.BodyLength, bodyLength)
+
+	checkSum := (m.headerTotal(t) + m.body.total() + m.trailer.total()) % 256
+	field := m.trailer.newField(tag.CheckSum)
+	start := len(field.Data)
+	field.Data = append(field.Data, byte('0'+checkSum/100), byte('0'+checkSum/10%10), byte('0'+checkSum%10))
+	field.terminate(start)
+
+	return t
 }
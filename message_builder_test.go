@@ -3,9 +3,10 @@ package quickfix
 import (
 	"bytes"
 	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
 	"github.com/quickfixgo/quickfix/fix/tag"
 	"testing"
-	"github.com/quickfixgo/quickfix/fix/field"
+	"time"
 )
 
 var builder MessageBuilder
@@ -32,3 +33,127 @@ func TestMessageBuilder_checkBuild(t *testing.T) {
 
 }
 
+//newOrderFields are the fields of a NewOrderSingle, set on builders by the benchmarks.
+type newOrderFields struct {
+	sendingTime  *field.SendingTimeField
+	msgSeqNum    *field.MsgSeqNumField
+	clOrdID      *field.ClOrdIDField
+	side         *field.SideField
+	orderQty     *field.OrderQtyField
+	price        *field.PriceField
+	transactTime *field.TransactTimeField
+}
+
+func newNewOrderFields() newOrderFields {
+	now := time.Date(2014, time.May, 15, 19, 49, 56, 659000000, time.UTC)
+	return newOrderFields{
+		sendingTime:  &field.SendingTimeField{UTCTimestampValue: fix.UTCTimestampValue{Value: now}},
+		msgSeqNum:    &field.MsgSeqNumField{SeqNumValue: fix.SeqNumValue{IntValue: fix.IntValue{Value: 2}}},
+		clOrdID:      &field.ClOrdIDField{StringValue: fix.StringValue{Value: "ORDER-100"}},
+		side:         &field.SideField{CharValue: fix.CharValue{StringValue: fix.StringValue{Value: "1"}}},
+		orderQty:     &field.OrderQtyField{QtyValue: fix.QtyValue{FloatValue: fix.FloatValue{Value: 100}}},
+		price:        &field.PriceField{PriceValue: fix.PriceValue{FloatValue: fix.FloatValue{Value: 12.25}}},
+		transactTime: &field.TransactTimeField{UTCTimestampValue: fix.UTCTimestampValue{Value: now}},
+	}
+}
+
+//set sets the fields of the order on builder.
+func (f newOrderFields) set(builder MessageBuilder) {
+	builder.Header().Set(field.NewBeginString(fix.BeginString_FIX42))
+	builder.Header().Set(field.NewMsgType("D"))
+	builder.Header().Set(f.msgSeqNum)
+	builder.Header().Set(field.NewSenderCompID("TW"))
+	builder.Header().Set(field.NewTargetCompID("ISLD"))
+	builder.Header().Set(f.sendingTime)
+	builder.Body().Set(f.clOrdID)
+	builder.Body().Set(f.side)
+	builder.Body().Set(f.orderQty)
+	builder.Body().Set(f.price)
+	builder.Body().Set(f.transactTime)
+}
+
+//setReused is set without allocating fields, the fields constant for the session reused.
+func (f newOrderFields) setReused(builder MessageBuilder, session []Field) {
+	for _, constant := range session {
+		builder.Header().Set(constant)
+	}
+	builder.Header().Set(f.msgSeqNum)
+	builder.Header().Set(f.sendingTime)
+	builder.Body().Set(f.clOrdID)
+	builder.Body().Set(f.side)
+	builder.Body().Set(f.orderQty)
+	builder.Body().Set(f.price)
+	builder.Body().Set(f.transactTime)
+}
+
+var sessionHeader = []Field{field.NewBeginString(fix.BeginString_FIX42), field.NewMsgType("D"), field.NewSenderCompID("TW"), field.NewTargetCompID("ISLD")}
+
+var builtResult []byte
+
+//BenchmarkMessageBuilder_Build builds each message with a new builder.
+func BenchmarkMessageBuilder_Build(b *testing.B) {
+	fields := newNewOrderFields()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		builder := NewMessageBuilder()
+		fields.set(builder)
+		builtResult, _ = builder.Build()
+	}
+}
+
+//BenchmarkMessageBuilder_AppendBuild builds each message with a builder Reset, into a reused buffer, without allocating.
+func BenchmarkMessageBuilder_AppendBuild(b *testing.B) {
+	fields := newNewOrderFields()
+	builder := NewReusableMessageBuilder()
+	buf := make([]byte, 0, 512)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		builder.Reset()
+		fields.setReused(builder, sessionHeader)
+		buf, _ = builder.AppendBuild(buf[:0])
+	}
+	builtResult = buf
+}
+
+func TestMessageBuilder_Reset(t *testing.T) {
+	fields := newNewOrderFields()
+
+	fresh := NewMessageBuilder()
+	fields.set(fresh)
+	expected, err := fresh.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parseMessage(expected); err != nil {
+		t.Errorf("Expected a valid message, got %v: %q", err, expected)
+	}
+
+	builder := NewReusableMessageBuilder()
+	builder.Body().Set(field.NewText("not part of the order"))
+	builder.Build()
+
+	buf := make([]byte, 0, 512)
+	for i := 0; i < 3; i++ {
+		builder.Reset()
+		fields.setReused(builder, sessionHeader)
+		if buf, err = builder.AppendBuild(buf[:0]); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buf, expected) {
+			t.Errorf("Expected %q once reset, got %q", expected, buf)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		builder.Reset()
+		fields.setReused(builder, sessionHeader)
+		buf, _ = builder.AppendBuild(buf[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("Expected building with a reset builder not to allocate, got %v allocations", allocs)
+	}
+}
@@ -0,0 +1,219 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//canonicalField is a field of a message being canonicalized, with the entries of the group it starts if it is a NumInGroup field.
+type canonicalField struct {
+	fieldBytes
+	entries [][]canonicalField
+}
+
+//Canonicalize returns a canonical byte form of the message, suitable for hashing and deduplication.
+//Two messages carrying the same content canonicalize to the same bytes regardless of field order, timestamp precision, or empty optional fields:
+//
+//Fields of each section and of each repeating group entry are ordered as declared in dict, fields unknown to dict follow in ascending tag order.
+//UTCTimestamp values are truncated to precision, one of time.Second, time.Millisecond, time.Microsecond, or time.Nanosecond.
+//Fields with empty values are removed unless required by dict.
+//BodyLength and CheckSum are recomputed.
+func (m *Message) Canonicalize(dict *datadictionary.DataDictionary, precision time.Duration) ([]byte, error) {
+	var timestampFormat string
+	switch precision {
+	case time.Second:
+		timestampFormat = "20060102-15:04:05"
+	case time.Millisecond:
+		timestampFormat = "20060102-15:04:05.000"
+	case time.Microsecond:
+		timestampFormat = "20060102-15:04:05.000000"
+	case time.Nanosecond:
+		timestampFormat = "20060102-15:04:05.000000000"
+	default:
+		return nil, fmt.Errorf("Canonicalize: unsupported timestamp precision %v", precision)
+	}
+
+	msgType := new(fix.StringValue)
+	if err := m.Header.GetField(tag.MsgType, msgType); err != nil {
+		return nil, err
+	}
+
+	msgDef, ok := dict.Messages[msgType.Value]
+	if !ok {
+		return nil, fmt.Errorf("Canonicalize: message type %v not in data dictionary", msgType.Value)
+	}
+
+	var headerFields, bodyFields, trailerFields []fieldBytes
+	for _, f := range m.fields {
+		switch {
+		case f.Tag == tag.BodyLength || f.Tag == tag.CheckSum:
+		case tag.IsHeader(f.Tag):
+			headerFields = append(headerFields, f)
+		case tag.IsTrailer(f.Tag):
+			trailerFields = append(trailerFields, f)
+		default:
+			bodyFields = append(bodyFields, f)
+		}
+	}
+
+	c := canonicalizer{dict: dict, timestampFormat: timestampFormat, precision: precision}
+
+	body := bytes.NewBuffer(getBuffer(len(m.rawMessage)))
+	for _, section := range []struct {
+		fields []fieldBytes
+		def    *datadictionary.MessageDef
+	}{
+		{headerFields, dict.Header},
+		{bodyFields, msgDef},
+		{trailerFields, dict.Trailer},
+	} {
+		var decls []*datadictionary.FieldDef
+		if section.def != nil {
+			decls = section.def.FieldsInDeclarationOrder
+		}
+
+		c.write(body, c.collect(section.fields, decls), decls)
+	}
+	defer putBuffer(body.Bytes())
+
+	//BeginString is written first, ahead of the BodyLength it does not contribute to
+	beginString := body.Next(bytes.IndexByte(body.Bytes(), '\001') + 1)
+
+	var b bytes.Buffer
+	b.Write(beginString)
+	b.Write(newFieldBytes(tag.BodyLength, []byte(strconv.Itoa(body.Len()))).Data)
+	b.Write(body.Bytes())
+
+	checkSum := 0
+	for _, c := range b.Bytes() {
+		checkSum += int(c)
+	}
+	b.Write(newFieldBytes(tag.CheckSum, newCheckSum(checkSum%256).Write()).Data)
+
+	return b.Bytes(), nil
+}
+
+type canonicalizer struct {
+	dict            *datadictionary.DataDictionary
+	timestampFormat string
+	precision       time.Duration
+}
+
+//collect arranges fields into canonicalFields, gathering the entries of repeating groups declared in decls.
+func (c canonicalizer) collect(fields []fieldBytes, decls []*datadictionary.FieldDef) []canonicalField {
+	var collected []canonicalField
+	for len(fields) > 0 {
+		var entries [][]canonicalField
+		f := fields[0]
+		fields = fields[1:]
+
+		if groupDef := findFieldDef(decls, f.Tag); groupDef != nil && groupDef.IsGroup() {
+			entries, fields = c.collectGroup(fields, groupDef)
+		}
+
+		collected = append(collected, canonicalField{fieldBytes: f, entries: entries})
+	}
+
+	return collected
+}
+
+//collectGroup gathers the entries of the group defined by groupDef from the start of fields. Returns the entries and the fields following the group.
+func (c canonicalizer) collectGroup(fields []fieldBytes, groupDef *datadictionary.FieldDef) ([][]canonicalField, []fieldBytes) {
+	delim := groupDef.ChildFields[0].Tag
+
+	var entries [][]canonicalField
+	for len(fields) > 0 {
+		child := findFieldDef(groupDef.ChildFields, fields[0].Tag)
+		if child == nil || (len(entries) == 0 && fields[0].Tag != delim) {
+			break
+		}
+
+		if fields[0].Tag == delim {
+			entries = append(entries, nil)
+		}
+
+		var nested [][]canonicalField
+		f := fields[0]
+		fields = fields[1:]
+		if child.IsGroup() {
+			nested, fields = c.collectGroup(fields, child)
+		}
+
+		entries[len(entries)-1] = append(entries[len(entries)-1], canonicalField{fieldBytes: f, entries: nested})
+	}
+
+	return entries, fields
+}
+
+//write orders fields by decls and writes them to b, followed by the entries of each group in turn.
+func (c canonicalizer) write(b *bytes.Buffer, fields []canonicalField, decls []*datadictionary.FieldDef) {
+	position := make(map[fix.Tag]int, len(decls))
+	for i, def := range decls {
+		position[def.Tag] = i
+	}
+
+	rank := func(t fix.Tag) int {
+		//BeginString and MsgType lead the header even when the dictionary does not declare it
+		switch t {
+		case tag.BeginString:
+			return -2
+		case tag.MsgType:
+			return -1
+		}
+
+		if p, ok := position[t]; ok {
+			return p
+		}
+		return len(decls) + int(t)
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool { return rank(fields[i].Tag) < rank(fields[j].Tag) })
+
+	for _, f := range fields {
+		def := findFieldDef(decls, f.Tag)
+		if len(f.Value) == 0 && (def == nil || !def.Required) {
+			continue
+		}
+
+		b.Write(newFieldBytes(f.Tag, c.normalize(f.Tag, f.Value)).Data)
+
+		if def != nil {
+			for _, entry := range f.entries {
+				c.write(b, entry, def.ChildFields)
+			}
+		}
+	}
+}
+
+//normalize truncates UTCTimestamp values to the canonical precision, other values are returned unchanged.
+func (c canonicalizer) normalize(t fix.Tag, value []byte) []byte {
+	fieldType, ok := c.dict.FieldTypeByTag[t]
+	if !ok || fieldType.Type != "UTCTIMESTAMP" {
+		return value
+	}
+
+	//fractional seconds of any precision are accepted when parsing
+	timestamp, err := time.Parse("20060102-15:04:05", string(value))
+	if err != nil {
+		return value
+	}
+
+	return []byte(timestamp.Truncate(c.precision).Format(c.timestampFormat))
+}
+
+//findFieldDef returns the definition of t in decls, nil if not declared.
+func findFieldDef(decls []*datadictionary.FieldDef, t fix.Tag) *datadictionary.FieldDef {
+	for _, def := range decls {
+		if def.Tag == t {
+			return def
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package quickfix
+
+import (
+	"bytes"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+	"time"
+)
+
+func TestMessage_Canonicalize(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX42.xml")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	msg1, err := parseMessage(rawMessage("FIX.4.2", "35=D\00134=2\00149=TW\00152=20140511-23:10:34.123456\00156=ISLD\00111=ID\00121=1\00155=TSLA\00154=1\00160=20140511-23:10:34.123\00140=1\00178=2\00179=A\00180=10\00179=B\00180=20\00158=\001"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	msg2, err := parseMessage(rawMessage("FIX.4.2", "35=D\00149=TW\00156=ISLD\00134=2\00152=20140511-23:10:34.123\00178=2\00179=A\00180=10\00179=B\00180=20\00154=1\00155=TSLA\00111=ID\00121=1\00140=1\00160=20140511-23:10:34.123999\001"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	canonical1, err := msg1.Canonicalize(dict, time.Millisecond)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	canonical2, err := msg2.Canonicalize(dict, time.Millisecond)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !bytes.Equal(canonical1, canonical2) {
+		t.Errorf("expected canonical forms to match\n%q\n%q", canonical1, canonical2)
+	}
+
+	if !bytes.HasPrefix(canonical1, []byte("8=FIX.4.2\0019=")) || !bytes.Contains(canonical1, []byte("\00135=D\00149=TW\00156=ISLD\00134=2\001")) {
+		t.Errorf("expected standard header order, got %q", canonical1)
+	}
+
+	if !bytes.Contains(canonical1, []byte("\00111=ID\00178=2\00179=A\00180=10\00179=B\00180=20\00121=1\001")) {
+		t.Errorf("expected body in declaration order, got %q", canonical1)
+	}
+
+	if bytes.Contains(canonical1, []byte("\00158=")) {
+		t.Errorf("expected empty Text to be removed, got %q", canonical1)
+	}
+
+	canonical, err := parseMessage(canonical1)
+	if err != nil {
+		t.Fatal("canonical form should parse", err)
+	}
+
+	sendingTime := new(fix.StringValue)
+	canonical.Header.GetField(tag.SendingTime, sendingTime)
+	if sendingTime.Value != "20140511-23:10:34.123" {
+		t.Errorf("expected SendingTime truncated to milliseconds, got %v", sendingTime.Value)
+	}
+
+	secondsOnly, _ := msg1.Canonicalize(dict, time.Second)
+	if !bytes.Contains(secondsOnly, []byte("\00152=20140511-23:10:34\001")) {
+		t.Errorf("expected SendingTime truncated to seconds, got %q", secondsOnly)
+	}
+
+	if _, err := msg1.Canonicalize(dict, time.Minute); err == nil {
+		t.Error("expected error for unsupported precision")
+	}
+}
@@ -0,0 +1,131 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"strings"
+)
+
+const dumpIndent = "  "
+
+//Dump returns a multi-line rendering of the message for humans, one field per line in message order.
+//Fields are labeled with their names and enum values with their descriptions from dict, repeating group entries are nested under their NumInGroup field.
+//dict may be nil, in which case fields are labeled by tag only.
+func (m *Message) Dump(dict *datadictionary.DataDictionary) string {
+	var b bytes.Buffer
+	d := messageDumper{dict: dict, buffer: &b}
+
+	var msgDef *datadictionary.MessageDef
+	if dict != nil {
+		msgType := new(fix.StringValue)
+		if m.Header.GetField(tag.MsgType, msgType) == nil {
+			msgDef = dict.Messages[msgType.Value]
+		}
+	}
+
+	section := ""
+	fields := m.fields
+	for len(fields) > 0 {
+		var def *datadictionary.MessageDef
+		var nextSection string
+		switch t := fields[0].Tag; {
+		case tag.IsHeader(t):
+			nextSection = "Header"
+			if dict != nil {
+				def = dict.Header
+			}
+		case tag.IsTrailer(t):
+			nextSection = "Trailer"
+			if dict != nil {
+				def = dict.Trailer
+			}
+		default:
+			nextSection = "Body"
+			def = msgDef
+		}
+
+		if nextSection != section {
+			section = nextSection
+			fmt.Fprintln(&b, section)
+		}
+
+		fields = d.dumpField(fields, def, 1)
+	}
+
+	return b.String()
+}
+
+type messageDumper struct {
+	dict   *datadictionary.DataDictionary
+	buffer *bytes.Buffer
+}
+
+//dumpField writes the first field and, if it starts a repeating group of def, the group entries. Returns the remaining fields.
+func (d messageDumper) dumpField(fields []fieldBytes, def *datadictionary.MessageDef, depth int) []fieldBytes {
+	d.writeField(fields[0], depth)
+
+	if def == nil {
+		return fields[1:]
+	}
+
+	if fieldDef, ok := def.Fields[fields[0].Tag]; ok && fieldDef.IsGroup() {
+		return d.dumpGroup(fields[1:], fieldDef, depth+1)
+	}
+
+	return fields[1:]
+}
+
+//dumpGroup writes the entries of the group defined by groupDef, each entry indented under its position. Returns the fields following the group.
+func (d messageDumper) dumpGroup(fields []fieldBytes, groupDef *datadictionary.FieldDef, depth int) []fieldBytes {
+	children := make(map[fix.Tag]*datadictionary.FieldDef)
+	for _, child := range groupDef.ChildFields {
+		children[child.Tag] = child
+	}
+	delim := groupDef.ChildFields[0].Tag
+
+	entry := 0
+	for len(fields) > 0 {
+		child, ok := children[fields[0].Tag]
+		if !ok {
+			break
+		}
+
+		if fields[0].Tag == delim {
+			entry++
+			fmt.Fprintf(d.buffer, "%v[%d]\n", strings.Repeat(dumpIndent, depth), entry)
+		}
+
+		d.writeField(fields[0], depth+1)
+		if child.IsGroup() {
+			fields = d.dumpGroup(fields[1:], child, depth+2)
+		} else {
+			fields = fields[1:]
+		}
+	}
+
+	return fields
+}
+
+func (d messageDumper) writeField(f fieldBytes, depth int) {
+	indent := strings.Repeat(dumpIndent, depth)
+
+	var fieldType *datadictionary.FieldType
+	if d.dict != nil {
+		fieldType = d.dict.FieldTypeByTag[f.Tag]
+	}
+
+	if fieldType == nil {
+		fmt.Fprintf(d.buffer, "%v%d = %s\n", indent, f.Tag, f.Value)
+		return
+	}
+
+	if enum, ok := fieldType.Enums[string(f.Value)]; ok && enum.Description != "" {
+		fmt.Fprintf(d.buffer, "%v%v (%d) = %s (%v)\n", indent, fieldType.Name, f.Tag, f.Value, enum.Description)
+		return
+	}
+
+	fmt.Fprintf(d.buffer, "%v%v (%d) = %s\n", indent, fieldType.Name, f.Tag, f.Value)
+}
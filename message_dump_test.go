@@ -0,0 +1,66 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"testing"
+)
+
+func TestMessage_Dump(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX42.xml")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	msg, err := parseMessage(rawMessage("FIX.4.2", "35=D\00134=2\00149=TW\00156=ISLD\00111=ID\00154=1\00178=2\00179=A\00180=10\00179=B\00180=20\00155=TSLA\001"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expected := `Header
+  BeginString (8) = FIX.4.2
+  BodyLength (9) = 70
+  MsgType (35) = D (ORDER_SINGLE)
+  MsgSeqNum (34) = 2
+  SenderCompID (49) = TW
+  TargetCompID (56) = ISLD
+Body
+  ClOrdID (11) = ID
+  Side (54) = 1 (BUY)
+  NoAllocs (78) = 2
+    [1]
+      AllocAccount (79) = A
+      AllocShares (80) = 10
+    [2]
+      AllocAccount (79) = B
+      AllocShares (80) = 20
+  Symbol (55) = TSLA
+Trailer
+  CheckSum (10) = 151
+`
+	if dump := msg.Dump(dict); dump != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, dump)
+	}
+
+	expected = `Header
+  8 = FIX.4.2
+  9 = 70
+  35 = D
+  34 = 2
+  49 = TW
+  56 = ISLD
+Body
+  11 = ID
+  54 = 1
+  78 = 2
+  79 = A
+  80 = 10
+  79 = B
+  80 = 20
+  55 = TSLA
+Trailer
+  10 = 151
+`
+	if dump := msg.Dump(nil); dump != expected {
+		t.Errorf("expected\n%v\ngot\n%v", expected, dump)
+	}
+}
@@ -0,0 +1,158 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/enum"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+	"strconv"
+	"strings"
+)
+
+//dataLengthTags maps data fields, which may contain the SOH delimiter, to the field preceding them with their length in bytes.
+var dataLengthTags = map[fix.Tag]fix.Tag{
+	tag.RawData:                             tag.RawDataLength,
+	tag.SecureData:                          tag.SecureDataLen,
+	tag.Signature:                           tag.SignatureLength,
+	tag.XmlData:                             tag.XmlDataLen,
+	tag.EncodedIssuer:                       tag.EncodedIssuerLen,
+	tag.EncodedSecurityDesc:                 tag.EncodedSecurityDescLen,
+	tag.EncodedListExecInst:                 tag.EncodedListExecInstLen,
+	tag.EncodedText:                         tag.EncodedTextLen,
+	tag.EncodedSubject:                      tag.EncodedSubjectLen,
+	tag.EncodedHeadline:                     tag.EncodedHeadlineLen,
+	tag.EncodedAllocText:                    tag.EncodedAllocTextLen,
+	tag.EncodedUnderlyingIssuer:             tag.EncodedUnderlyingIssuerLen,
+	tag.EncodedUnderlyingSecurityDesc:       tag.EncodedUnderlyingSecurityDescLen,
+	tag.EncodedListStatusText:               tag.EncodedListStatusTextLen,
+	tag.EncodedLegIssuer:                    tag.EncodedLegIssuerLen,
+	tag.EncodedLegSecurityDesc:              tag.EncodedLegSecurityDescLen,
+	tag.DerivativeEncodedIssuer:             tag.DerivativeEncodedIssuerLen,
+	tag.DerivativeEncodedSecurityDesc:       tag.DerivativeEncodedSecurityDescLen,
+	tag.EncodedSymbol:                       tag.EncodedSymbolLen,
+	tag.EncodedMktSegmDesc:                  tag.EncodedMktSegmDescLen,
+	tag.EncodedSecurityListDesc:             tag.EncodedSecurityListDescLen,
+	tag.RelationshipRiskEncodedSecurityDesc: tag.RelationshipRiskEncodedSecurityDescLen,
+	tag.RiskEncodedSecurityDesc:             tag.RiskEncodedSecurityDescLen,
+}
+
+//dataTags maps the length fields of dataLengthTags to their data fields, looked up for each field parsed.
+var dataTags = make(map[fix.Tag]fix.Tag, len(dataLengthTags))
+
+func init() {
+	for dataTag, lengthTag := range dataLengthTags {
+		dataTags[lengthTag] = dataTag
+	}
+}
+
+//dataFieldLength returns the length declared by f if it is the length field of a data field.
+func dataFieldLength(f fieldBytes) (dataTag fix.Tag, length int, ok bool) {
+	if dataTag, ok = dataTags[f.Tag]; !ok {
+		return
+	}
+
+	if length, err := fix.ParseUInt(f.Value); err == nil {
+		return dataTag, length, true
+	}
+
+	return 0, 0, false
+}
+
+//extractDataField extracts a data field of the given length, which may contain the SOH delimiter.
+//Falls back to extractField if the field at the start of buffer is not dataTag with a value of the given length.
+func extractDataField(parsedFieldBytes *fieldBytes, dataTag fix.Tag, length int, buffer []byte) (remBytes []byte, err error) {
+	prefix := strconv.Itoa(int(dataTag)) + "="
+	end := len(prefix) + length
+	if !bytes.HasPrefix(buffer, []byte(prefix)) || end >= len(buffer) || buffer[end] != '\001' {
+		return extractField(parsedFieldBytes, buffer)
+	}
+
+	err = parsedFieldBytes.parseField(buffer[:end+1])
+	return buffer[(end + 1):], err
+}
+
+//messageEncoding returns the character set for a MessageEncoding value, nil for UTF-8.
+//In addition to the values defined for MessageEncoding, UTF-16 (big endian) is supported.
+func messageEncoding(name string) (encoding.Encoding, error) {
+	switch strings.ToUpper(name) {
+	case "", enum.MessageEncoding_UTF_8:
+		return nil, nil
+	case enum.MessageEncoding_SHIFT_JIS:
+		return japanese.ShiftJIS, nil
+	case enum.MessageEncoding_EUC_JP:
+		return japanese.EUCJP, nil
+	case enum.MessageEncoding_ISO_2022_JP:
+		return japanese.ISO2022JP, nil
+	case "UTF-16":
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), nil
+	}
+
+	return nil, fmt.Errorf("unsupported MessageEncoding %v", name)
+}
+
+//headerMessageEncoding returns the character set declared by the MessageEncoding field of header.
+func headerMessageEncoding(header FieldMap) (encoding.Encoding, error) {
+	name := new(fix.StringValue)
+	if header.Has(tag.MessageEncoding) {
+		if err := header.GetField(tag.MessageEncoding, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return messageEncoding(name.Value)
+}
+
+//SetEncodedString sets the Encoded field dataTag, e.g. EncodedText, in the body of builder to value converted to the character set
+//declared by the MessageEncoding header field of builder. The paired length field is set to the length of the converted value in bytes.
+//MessageEncoding must be set on builder before calling SetEncodedString.
+func SetEncodedString(builder MessageBuilder, dataTag fix.Tag, value string) error {
+	lengthTag, ok := dataLengthTags[dataTag]
+	if !ok {
+		return fmt.Errorf("tag %d is not a data field with a length field", dataTag)
+	}
+
+	enc, err := headerMessageEncoding(builder.Header())
+	if err != nil {
+		return err
+	}
+
+	encoded := []byte(value)
+	if enc != nil {
+		if encoded, err = enc.NewEncoder().Bytes(encoded); err != nil {
+			return fmt.Errorf("encoding tag %d: %v", dataTag, err)
+		}
+	}
+
+	builder.Body().SetField(lengthTag, &fix.IntValue{Value: len(encoded)})
+	builder.Body().SetField(dataTag, &fix.StringValue{Value: string(encoded)})
+	return nil
+}
+
+//GetEncodedString reads the Encoded field dataTag, e.g. EncodedText, from the message converted to UTF-8 from the character set
+//declared by the MessageEncoding header field.
+func (m *Message) GetEncodedString(dataTag fix.Tag) (string, error) {
+	if _, ok := dataLengthTags[dataTag]; !ok {
+		return "", fmt.Errorf("tag %d is not a data field with a length field", dataTag)
+	}
+
+	value := new(fix.StringValue)
+	if err := m.Body.GetField(dataTag, value); err != nil {
+		return "", err
+	}
+
+	enc, err := headerMessageEncoding(m.Header)
+	if err != nil || enc == nil {
+		return value.Value, err
+	}
+
+	decoded, err := enc.NewDecoder().String(value.Value)
+	if err != nil {
+		return "", fmt.Errorf("decoding tag %d: %v", dataTag, err)
+	}
+
+	return decoded, nil
+}
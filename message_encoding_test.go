@@ -0,0 +1,88 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+)
+
+func TestMessage_EncodedString(t *testing.T) {
+	var testCases = []struct {
+		encoding       string
+		value          string
+		expectedLength int
+	}{
+		{"SHIFT_JIS", "東京証券取引所", 14},
+		{"EUC-JP", "東京証券取引所", 14},
+		{"ISO-2022-JP", "東京", 10},
+		{"UTF-8", "東京", 6},
+		//U+0101 encodes to the delimiter in UTF-16
+		{"UTF-16", "āb", 4},
+	}
+
+	for _, tc := range testCases {
+		builder := NewMessageBuilder()
+		builder.Header().Set(field.NewBeginString(fix.BeginString_FIX44))
+		builder.Header().Set(field.NewMsgType("B"))
+		builder.Header().Set(field.NewMessageEncoding(tc.encoding))
+		builder.Body().Set(field.NewMarketSegmentID("SEG"))
+
+		if err := SetEncodedString(builder, tag.EncodedText, tc.value); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		msgBytes, _ := builder.Build()
+		msg, err := parseMessage(msgBytes)
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", tc.encoding, err)
+		}
+
+		length := new(fix.IntValue)
+		if err := msg.Body.GetField(tag.EncodedTextLen, length); err != nil || length.Value != tc.expectedLength {
+			t.Errorf("%v: expected EncodedTextLen %v got %v %v", tc.encoding, tc.expectedLength, length.Value, err)
+		}
+
+		decoded, err := msg.GetEncodedString(tag.EncodedText)
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", tc.encoding, err)
+		}
+
+		if decoded != tc.value {
+			t.Errorf("%v: expected %v got %v", tc.encoding, tc.value, decoded)
+		}
+
+		if !msg.Body.Has(tag.MarketSegmentID) {
+			t.Errorf("%v: expected fields following the encoded field to be parsed", tc.encoding)
+		}
+	}
+}
+
+func TestMessage_EncodedStringErrors(t *testing.T) {
+	builder := NewMessageBuilder()
+	builder.Header().Set(field.NewMessageEncoding("KOI8-R"))
+
+	if err := SetEncodedString(builder, tag.EncodedText, "text"); err == nil {
+		t.Error("expected error for unsupported MessageEncoding")
+	}
+
+	if err := SetEncodedString(NewMessageBuilder(), tag.Text, "text"); err == nil {
+		t.Error("expected error for a field that is not a data field")
+	}
+}
+
+func TestDataFieldLength(t *testing.T) {
+	for dataTag, lengthTag := range dataLengthTags {
+		if got, length, ok := dataFieldLength(*newFieldBytes(lengthTag, []byte("12"))); !ok || got != dataTag || length != 12 {
+			t.Errorf("expected length 12 of %v from %v, got %v %v %v", dataTag, lengthTag, got, length, ok)
+		}
+	}
+
+	if _, _, ok := dataFieldLength(*newFieldBytes(tag.Text, []byte("12"))); ok {
+		t.Error("expected Text not to be the length of a data field")
+	}
+
+	if _, _, ok := dataFieldLength(*newFieldBytes(tag.EncodedTextLen, []byte("twelve"))); ok {
+		t.Error("expected invalid length not to be a data field length")
+	}
+}
@@ -53,7 +53,10 @@ func (c MessageRouter) tryRoute(beginString *field.BeginStringField, msgType *fi
 	if beginString.Value == fix.BeginString_FIXT11 && !fix.IsAdminMessageType(msgType.Value) {
 		applVerID := &field.ApplVerIDField{}
 		if err := msg.Header.Get(applVerID); err != nil {
-			session, _ := LookupSession(sessionID)
+			session, err := LookupSession(sessionID)
+			if err != nil {
+				return unsupportedMessageType()
+			}
 			applVerID.Value = session.TargetDefaultApplicationVersionID()
 		}
 
@@ -68,8 +71,13 @@ func (c MessageRouter) tryRoute(beginString *field.BeginStringField, msgType *fi
 			beginString.Value = fix.BeginString_FIX43
 		case enum.ApplVerID_FIX44:
 			beginString.Value = fix.BeginString_FIX44
-		case enum.ApplVerID_FIX50, enum.ApplVerID_FIX50SP1, enum.ApplVerID_FIX50SP2:
+		case enum.ApplVerID_FIX50:
 			beginString.Value = fix.BeginString_FIX50
+		//FIX.5.0 service packs are routed by ApplVerID
+		case enum.ApplVerID_FIX50SP1, enum.ApplVerID_FIX50SP2:
+			beginString.Value = applVerID.Value
+		default:
+			return unsupportedMessageType()
 		}
 
 		return c.tryRoute(beginString, msgType, msg, sessionID)
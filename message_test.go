@@ -12,6 +12,7 @@ var msgResult *Message
 func BenchmarkParseMessage(b *testing.B) {
 	rawMsg := []byte("8=FIX.4.29=10435=D34=249=TW52=20140515-19:49:56.65956=ISLD11=10021=140=154=155=TSLA60=00010101-00:00:00.00010=039")
 
+	b.ReportAllocs()
 	var msg *Message
 	for i := 0; i < b.N; i++ {
 		msg, _ = parseMessage(rawMsg)
@@ -20,6 +21,50 @@ func BenchmarkParseMessage(b *testing.B) {
 	msgResult = msg
 }
 
+//BenchmarkMessage_Parse parses into a reused Message, without allocating, unlike BenchmarkParseMessage.
+func BenchmarkMessage_Parse(b *testing.B) {
+	rawMsg := []byte("8=FIX.4.2\x019=104\x0135=D\x0134=2\x0149=TW\x0152=20140515-19:49:56.659\x0156=ISLD\x0111=100\x0121=1\x0140=1\x0154=1\x0155=TSLA\x0160=00010101-00:00:00.000\x0110=039\x01")
+
+	b.ReportAllocs()
+	msg := new(Message)
+	for i := 0; i < b.N; i++ {
+		msg.Parse(rawMsg)
+	}
+
+	msgResult = msg
+}
+
+func TestMessage_ParseReused(t *testing.T) {
+	order := []byte("8=FIX.4.2\x019=104\x0135=D\x0134=2\x0149=TW\x0152=20140515-19:49:56.659\x0156=ISLD\x0111=100\x0121=1\x0140=1\x0154=1\x0155=TSLA\x0160=00010101-00:00:00.000\x0110=039\x01")
+	heartbeat := rawMessage("FIX.4.2", "35=0\x0134=3\x0149=TW\x0152=20140515-19:49:56.659\x0156=ISLD\x01")
+
+	var msg Message
+	if err := msg.Parse(order); err != nil {
+		t.Fatal(err)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { msg.Parse(order) }); allocs != 0 {
+		t.Errorf("Expected parsing into a reused Message not to allocate, got %v allocations", allocs)
+	}
+
+	if err := msg.Parse(heartbeat); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Body.Has(tag.ClOrdID) || len(msg.fields) != 8 || !bytes.Equal(msg.rawMessage, heartbeat) {
+		t.Errorf("Expected only the fields of the heartbeat, got %v", msg.String())
+	}
+
+	msgSeqNum := new(fix.IntValue)
+	if err := msg.Header.GetField(tag.MsgSeqNum, msgSeqNum); err != nil || msgSeqNum.Value != 3 {
+		t.Errorf("Expected MsgSeqNum 3, got %v", msgSeqNum.Value)
+	}
+
+	if err := msg.Parse(bytes.Replace(order, []byte("9=104"), []byte("9=105"), 1)); err == nil {
+		t.Error("Expected error parsing a message with an incorrect BodyLength")
+	}
+}
+
 func TestMessage_parseMessage(t *testing.T) {
 	rawMsg := []byte("8=FIX.4.29=10435=D34=249=TW52=20140515-19:49:56.65956=ISLD11=10021=140=154=155=TSLA60=00010101-00:00:00.00010=039")
 
@@ -0,0 +1,114 @@
+package quickfix
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//MessageStage is a stage of the lifecycle of a message traced by a MessageTracer.
+type MessageStage string
+
+const (
+	//StageParse parses a message received.
+	StageParse MessageStage = "parse"
+
+	//StageValidate checks the header and sequence number of a message received, and validates it against the data dictionary of the session.
+	StageValidate MessageStage = "validate"
+
+	//StageFromAdmin and StageFromApp call the Interceptors and the Application with a message received.
+	StageFromAdmin MessageStage = "FromAdmin"
+	StageFromApp   MessageStage = "FromApp"
+
+	//StageToAdmin and StageToApp call the Interceptors and the Application with a message sent.
+	StageToAdmin MessageStage = "ToAdmin"
+	StageToApp   MessageStage = "ToApp"
+
+	//StageSerialize builds a message sent, validating it against the data dictionary of the counterparty if configured.
+	StageSerialize MessageStage = "serialize"
+
+	//StageStore saves a message sent to the MessageStore.
+	StageStore MessageStage = "store"
+
+	//StageWrite hands a message sent to the connection, waiting while the connection is not ready for it.
+	StageWrite MessageStage = "write"
+)
+
+//MessageTracer traces the lifecycle of the messages received and sent by sessions, such as with the OpenTelemetry spans of package oteltrace.
+//A message received is traced from when it is read from the connection through StageParse, StageValidate, and StageFromAdmin or StageFromApp,
+//a message sent through StageToAdmin or StageToApp, StageSerialize, StageStore, and StageWrite. Set with SetMessageTracer.
+//Messages of several sessions may be traced concurrently, methods must be safe for concurrent use and must not block.
+type MessageTracer interface {
+	//StartReceive starts the trace of a message read from the connection of the session at receiveTime.
+	//The context returned is the Context of the message in FromAdmin and FromApp.
+	StartReceive(ctx context.Context, sessionID SessionID, receiveTime time.Time) (context.Context, MessageTrace)
+
+	//StartSend starts the trace of a message sent by the session, ctx being the context passed to SendToTargetCtx.
+	StartSend(ctx context.Context, sessionID SessionID) (context.Context, MessageTrace)
+}
+
+//MessageTrace is the trace of a message started by a MessageTracer.
+type MessageTrace interface {
+	//StartStage starts a stage of the lifecycle of the message, returning the func ending it with the error of the stage, nil if it succeeded.
+	StartStage(stage MessageStage) (end func(err error))
+
+	//End ends the trace. header is the header of the message, nil if a message received cannot be parsed, and err the error
+	//that stopped it being sent, nil if sent or received.
+	End(header FieldMap, err error)
+}
+
+var globalMessageTracer struct {
+	lock   sync.RWMutex
+	tracer MessageTracer
+}
+
+//SetMessageTracer sets the tracer of the messages of every session, nil to stop tracing.
+func SetMessageTracer(tracer MessageTracer) {
+	globalMessageTracer.lock.Lock()
+	defer globalMessageTracer.lock.Unlock()
+
+	globalMessageTracer.tracer = tracer
+}
+
+func messageTracer() MessageTracer {
+	globalMessageTracer.lock.RLock()
+	defer globalMessageTracer.lock.RUnlock()
+
+	return globalMessageTracer.tracer
+}
+
+//messageTraceKey is the key of the MessageTrace of a message in its context.
+type messageTraceKey struct{}
+
+func endUntracedStage(error) {}
+
+//startMessageStage starts stage of the trace of the message with context ctx, if traced.
+func startMessageStage(ctx context.Context, stage MessageStage) func(err error) {
+	if trace, ok := ctx.Value(messageTraceKey{}).(MessageTrace); ok {
+		return trace.StartStage(stage)
+	}
+
+	return endUntracedStage
+}
+
+//startReceiveTrace starts the trace of a message received at receiveTime, nil if messages are not traced.
+func (s *Session) startReceiveTrace(receiveTime time.Time) (context.Context, MessageTrace) {
+	tracer := messageTracer()
+	if tracer == nil {
+		return context.Background(), nil
+	}
+
+	ctx, trace := tracer.StartReceive(context.Background(), s.sessionID, receiveTime)
+	return context.WithValue(ctx, messageTraceKey{}, trace), trace
+}
+
+//startSendTrace starts the trace of a message sent with ctx, nil if messages are not traced.
+func (s *Session) startSendTrace(ctx context.Context) (context.Context, MessageTrace) {
+	tracer := messageTracer()
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	ctx, trace := tracer.StartSend(ctx, s.sessionID)
+	return context.WithValue(ctx, messageTraceKey{}, trace), trace
+}
@@ -0,0 +1,175 @@
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//recordingTracer records the stages of each message traced as "<direction> <MsgType>: <stage> <stage>...".
+type recordingTracer struct {
+	lock   sync.Mutex
+	traces map[SessionID][]string
+}
+
+type recordedTrace struct {
+	tracer    *recordingTracer
+	sessionID SessionID
+	direction string
+	stages    []string
+}
+
+type tracerKey struct{}
+
+func (r *recordingTracer) StartReceive(ctx context.Context, sessionID SessionID, receiveTime time.Time) (context.Context, MessageTrace) {
+	trace := &recordedTrace{tracer: r, sessionID: sessionID, direction: "receive"}
+	return context.WithValue(ctx, tracerKey{}, trace), trace
+}
+
+func (r *recordingTracer) StartSend(ctx context.Context, sessionID SessionID) (context.Context, MessageTrace) {
+	trace := &recordedTrace{tracer: r, sessionID: sessionID, direction: "send"}
+	if parent, ok := ctx.Value(tracerKey{}).(string); ok {
+		trace.direction = "send from " + parent
+	}
+	return ctx, trace
+}
+
+func (r *recordedTrace) StartStage(stage MessageStage) func(err error) {
+	return func(err error) {
+		if err != nil {
+			r.stages = append(r.stages, fmt.Sprintf("%v(%v)", stage, err))
+		} else {
+			r.stages = append(r.stages, string(stage))
+		}
+	}
+}
+
+func (r *recordedTrace) End(header FieldMap, err error) {
+	msgType := new(fix.StringValue)
+	if header != nil {
+		header.GetField(tag.MsgType, msgType)
+	}
+
+	r.tracer.lock.Lock()
+	defer r.tracer.lock.Unlock()
+
+	r.tracer.traces[r.sessionID] = append(r.tracer.traces[r.sessionID], fmt.Sprintf("%v %v: %v", r.direction, msgType.Value, strings.Join(r.stages, " ")))
+}
+
+func (r *recordingTracer) tracesOf(sessionID SessionID) []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string(nil), r.traces[sessionID]...)
+}
+
+//tracedClient reports the context of each app message received.
+type tracedClient struct {
+	shutdownClient
+	contexts chan context.Context
+}
+
+func (c *tracedClient) FromApp(msg Message, sessionID SessionID) MessageRejectError {
+	c.contexts <- msg.Context()
+	return nil
+}
+
+func TestSession_MessageTracer(t *testing.T) {
+	tracer := &recordingTracer{traces: make(map[SessionID][]string)}
+	SetMessageTracer(tracer)
+	defer SetMessageTracer(nil)
+
+	port := strconv.Itoa(freePort(t))
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, port)
+	acceptorSessionID, err := acceptorSettings.AddSession(newTestAcceptorSessionSettings("TRACED"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acceptorApp := &tracedClient{shutdownClient{states: make(chan SessionState, 20)}, make(chan context.Context, 1)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorSettings := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "TRACED")
+	sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+	sessionSettings.Set(config.HeartBtInt, "30")
+	sessionSettings.Set(config.SocketConnectHost, "127.0.0.1")
+	sessionSettings.Set(config.SocketConnectPort, port)
+	initiatorSessionID, err := initiatorSettings.AddSession(sessionSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer initiator.Shutdown(context.Background())
+
+	awaitState(t, initiatorApp.states, StateLoggedOn)
+	awaitState(t, acceptorApp.states, StateLoggedOn)
+
+	order := NewMessageBuilder()
+	order.Header().Set(fix.NewStringField(tag.MsgType, "D"))
+	if err := SendToTargetCtx(context.WithValue(context.Background(), tracerKey{}, "order"), order, initiatorSessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ctx := <-acceptorApp.contexts:
+		if trace, ok := ctx.Value(tracerKey{}).(*recordedTrace); !ok || trace.direction != "receive" {
+			t.Errorf("Expected the context of the message received to carry its trace, got %v", ctx)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for order")
+	}
+
+	expected := map[SessionID][]string{
+		//the logon response is not verified, the logon reply is sent before the logon received is handled
+		initiatorSessionID: {"send A: ToAdmin serialize store write", "receive A: parse FromAdmin", "send from order D: ToApp serialize store write"},
+		acceptorSessionID:  {"send A: ToAdmin serialize store write", "receive A: parse validate FromAdmin", "receive D: parse validate FromApp"},
+	}
+
+	for sessionID, traces := range expected {
+		//the trace of a message received ends once FromApp returns
+		actual := tracer.tracesOf(sessionID)
+		for deadline := time.Now().Add(5 * time.Second); len(actual) < len(traces) && time.Now().Before(deadline); actual = tracer.tracesOf(sessionID) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if len(actual) < len(traces) {
+			t.Errorf("%v: expected traces %q, got %q", sessionID, traces, actual)
+			continue
+		}
+
+		for i, trace := range traces {
+			if actual[i] != trace {
+				t.Errorf("%v: expected trace %q, got %q", sessionID, trace, actual[i])
+			}
+		}
+	}
+}
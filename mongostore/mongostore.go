@@ -0,0 +1,381 @@
+//Package mongostore provides a QuickFIX/Go MessageStore persisting sessions to MongoDB.
+package mongostore
+
+import (
+	"context"
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//StoreType is the MessageStoreType of the store, registered with quickfix.RegisterStoreFactory when the package is imported.
+const StoreType = "mongo"
+
+func init() {
+	quickfix.RegisterStoreFactory(StoreType, NewStoreFactory)
+}
+
+const (
+	defaultDatabase = "quickfix"
+	defaultEngine   = "quickfix"
+
+	//healthTimeout limits the ping of the server by Health
+	healthTimeout = 5 * time.Second
+)
+
+//sessionDoc is the document of a session in the sessions collection, keyed by SessionID.
+type sessionDoc struct {
+	ID                string    `bson:"_id"`
+	CreationTime      time.Time `bson:"creation_time"`
+	IncomingSeqNum    int       `bson:"incoming_seqnum"`
+	OutgoingSeqNum    int       `bson:"outgoing_seqnum"`
+	LoggedOn          bool      `bson:"logged_on"`
+	ResendBeginSeqNum int       `bson:"resend_begin_seqnum"`
+	ResendEndSeqNum   int       `bson:"resend_end_seqnum"`
+}
+
+//messageDoc is the document of a message in the messages collection, keyed by SessionID and MsgSeqNum.
+type messageDoc struct {
+	Session   string    `bson:"session"`
+	MsgSeqNum int       `bson:"msgseqnum"`
+	Message   []byte    `bson:"message"`
+	Saved     time.Time `bson:"saved"`
+}
+
+//collections are the collections of an engine, shared by the sessions of the engine.
+type collections struct {
+	sessions, messages *mongo.Collection
+}
+
+type storeFactory struct {
+	settings *quickfix.Settings
+
+	//clients are shared by the sessions using the same connection and collections
+	clientLock  sync.Mutex
+	clients     map[string]*mongo.Client
+	collections map[string]collections
+}
+
+//NewStoreFactory returns a MessageStoreFactory that creates MessageStores persisting sessions to MongoDB.
+//The connection string is configured via MongoStoreConnection.
+//Sessions are stored in the collections MongoStoreEngine_sessions and MongoStoreEngine_messages of MongoStoreDatabase.
+//MongoStoreRetention, in seconds, expires messages saved longer ago, MongoStoreWriteConcern and MongoStoreJournal configure durability.
+func NewStoreFactory(settings *quickfix.Settings) (quickfix.MessageStoreFactory, error) {
+	for _, sessionSettings := range settings.SessionSettings() {
+		if !sessionSettings.HasSetting(config.MongoStoreConnection) {
+			return nil, fmt.Errorf("missing configuration: %v", config.MongoStoreConnection)
+		}
+
+		if _, err := writeConcernFor(sessionSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	return &storeFactory{settings: settings, clients: make(map[string]*mongo.Client), collections: make(map[string]collections)}, nil
+}
+
+func (f *storeFactory) Create(sessionID quickfix.SessionID) (quickfix.MessageStore, error) {
+	settings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("mongo store not defined for %v", sessionID)
+	}
+
+	connection, err := settings.Setting(config.MongoStoreConnection)
+	if err != nil {
+		return nil, fmt.Errorf("missing configuration: %v", config.MongoStoreConnection)
+	}
+
+	database, engine := defaultDatabase, defaultEngine
+	if settings.HasSetting(config.MongoStoreDatabase) {
+		database, _ = settings.Setting(config.MongoStoreDatabase)
+	}
+	if settings.HasSetting(config.MongoStoreEngine) {
+		engine, _ = settings.Setting(config.MongoStoreEngine)
+	}
+
+	var retention time.Duration
+	if settings.HasSetting(config.MongoStoreRetention) {
+		seconds, err := settings.IntSetting(config.MongoStoreRetention)
+		if err != nil {
+			return nil, err
+		}
+		retention = time.Duration(seconds) * time.Second
+	}
+
+	writeConcern, err := writeConcernFor(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := f.open(connection, database, engine, retention, writeConcern)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStore(sessionID, c)
+}
+
+//open returns the collections of engine, connecting and creating the indexes of the collections if not already open.
+func (f *storeFactory) open(connection, database, engine string, retention time.Duration, writeConcern *writeconcern.WriteConcern) (collections, error) {
+	f.clientLock.Lock()
+	defer f.clientLock.Unlock()
+
+	key := connection + " " + database + " " + engine
+	if c, ok := f.collections[key]; ok {
+		return c, nil
+	}
+
+	client, ok := f.clients[connection]
+	if !ok {
+		var err error
+		if client, err = mongo.Connect(context.Background(), options.Client().ApplyURI(connection)); err != nil {
+			return collections{}, err
+		}
+		f.clients[connection] = client
+	}
+
+	collectionOptions := options.Collection().SetWriteConcern(writeConcern)
+	db := client.Database(database)
+	c := collections{
+		sessions: db.Collection(engine+"_sessions", collectionOptions),
+		messages: db.Collection(engine+"_messages", collectionOptions),
+	}
+
+	indexes := []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "session", Value: 1}, {Key: "msgseqnum", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}}
+	if retention > 0 {
+		indexes = append(indexes, mongo.IndexModel{
+			Keys:    bson.D{{Key: "saved", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(retention / time.Second)),
+		})
+	}
+
+	if _, err := c.messages.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		return collections{}, err
+	}
+
+	f.collections[key] = c
+	return c, nil
+}
+
+//writeConcernFor parses MongoStoreWriteConcern, majority or a number of nodes, and MongoStoreJournal.
+//Without either setting the write concern of the connection string is used.
+func writeConcernFor(settings *quickfix.SessionSettings) (*writeconcern.WriteConcern, error) {
+	if !settings.HasSetting(config.MongoStoreWriteConcern) && !settings.HasSetting(config.MongoStoreJournal) {
+		return nil, nil
+	}
+
+	writeConcern := new(writeconcern.WriteConcern)
+	if settings.HasSetting(config.MongoStoreWriteConcern) {
+		w, _ := settings.Setting(config.MongoStoreWriteConcern)
+		if w == "majority" {
+			writeConcern.W = "majority"
+		} else if nodes, err := strconv.Atoi(w); err == nil && nodes >= 0 {
+			writeConcern.W = nodes
+		} else {
+			return nil, fmt.Errorf("invalid MongoStoreWriteConcern %v, expected majority or a number of nodes", w)
+		}
+	}
+
+	if settings.HasSetting(config.MongoStoreJournal) {
+		journal, err := settings.BoolSetting(config.MongoStoreJournal)
+		if err != nil {
+			return nil, err
+		}
+		writeConcern.Journal = &journal
+	}
+
+	return writeConcern, nil
+}
+
+//store is a MessageStore persisting a session to MongoDB.
+type store struct {
+	sessionID string
+	c         collections
+
+	//lock guards the session document, read outside the session goroutine
+	lock sync.RWMutex
+	doc  sessionDoc
+
+	//writeErr keeps the error of the first failed replace of the session document or write of a message, until Refresh reloads the document
+	writeErr quickfix.ErrorLatch
+}
+
+func newStore(sessionID quickfix.SessionID, c collections) (*store, error) {
+	s := &store{sessionID: sessionID.String(), c: c}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+//load reads the session document, inserting it if the session is new.
+func (s *store) load() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.c.sessions.FindOne(context.Background(), bson.M{"_id": s.sessionID}).Decode(&s.doc)
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	s.doc = sessionDoc{ID: s.sessionID, CreationTime: time.Now().UTC()}
+	_, err = s.c.sessions.InsertOne(context.Background(), s.doc)
+	return err
+}
+
+//save replaces the session document, with lock held.
+func (s *store) save() {
+	_, err := s.c.sessions.ReplaceOne(context.Background(), bson.M{"_id": s.sessionID}, s.doc, options.Replace().SetUpsert(true))
+	s.writeErr.Set(err)
+}
+
+func (s *store) NextSenderMsgSeqNum() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.doc.OutgoingSeqNum + 1
+}
+
+func (s *store) NextTargetMsgSeqNum() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.doc.IncomingSeqNum + 1
+}
+
+func (s *store) IncrNextSenderMsgSeqNum() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.doc.OutgoingSeqNum++
+	s.save()
+}
+
+func (s *store) IncrNextTargetMsgSeqNum() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.doc.IncomingSeqNum++
+	s.save()
+}
+
+func (s *store) SetNextSenderMsgSeqNum(next int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.doc.OutgoingSeqNum = next - 1
+	s.save()
+}
+
+func (s *store) SetNextTargetMsgSeqNum(next int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.doc.IncomingSeqNum = next - 1
+	s.save()
+}
+
+func (s *store) CreationTime() time.Time {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.doc.CreationTime
+}
+
+func (s *store) SaveRecoveryState(state quickfix.RecoveryState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.doc.LoggedOn, s.doc.ResendBeginSeqNum, s.doc.ResendEndSeqNum = state.LoggedOn, state.ResendBeginSeqNo, state.ResendEndSeqNo
+	s.save()
+	return s.writeErr.Err()
+}
+
+func (s *store) RecoveryState() (quickfix.RecoveryState, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return quickfix.RecoveryState{LoggedOn: s.doc.LoggedOn, ResendBeginSeqNo: s.doc.ResendBeginSeqNum, ResendEndSeqNo: s.doc.ResendEndSeqNum}, nil
+}
+
+//Reset deletes the messages of the session and resets the sequence numbers, starting a new session.
+func (s *store) Reset() {
+	_, err := s.c.messages.DeleteMany(context.Background(), bson.M{"session": s.sessionID})
+	s.writeErr.Set(err)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.doc.CreationTime = time.Now().UTC()
+	s.doc.OutgoingSeqNum, s.doc.IncomingSeqNum = 0, 0
+	s.doc.ResendBeginSeqNum, s.doc.ResendEndSeqNum = 0, 0
+	s.save()
+}
+
+//Refresh reloads the session document, clearing any write error.
+func (s *store) Refresh() {
+	s.writeErr.Clear()
+
+	s.writeErr.Set(s.load())
+}
+
+//Health returns the first write error since the store was refreshed, or the failure to reach the server.
+func (s *store) Health() error {
+	if err := s.writeErr.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+	return s.c.sessions.Database().Client().Ping(ctx, nil)
+}
+
+//TrySaveMessage upserts msg, replacing any message saved with seqNum.
+func (s *store) TrySaveMessage(seqNum int, msg []byte) error {
+	if err := s.writeErr.Err(); err != nil {
+		return err
+	}
+
+	doc := messageDoc{Session: s.sessionID, MsgSeqNum: seqNum, Message: msg, Saved: time.Now().UTC()}
+	_, err := s.c.messages.ReplaceOne(context.Background(), bson.M{"session": s.sessionID, "msgseqnum": seqNum}, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+//SaveMessage upserts msg as TrySaveMessage, a failed upsert kept as the error reported by Health until Refresh reloads the session document.
+func (s *store) SaveMessage(seqNum int, msg []byte) {
+	s.writeErr.Set(s.TrySaveMessage(seqNum, msg))
+}
+
+//Compact deletes the messages with seqnums below seqNum.
+func (s *store) Compact(seqNum int) error {
+	_, err := s.c.messages.DeleteMany(context.Background(), bson.M{"session": s.sessionID, "msgseqnum": bson.M{"$lt": seqNum}})
+	return err
+}
+
+//GetMessages returns the messages saved with seqnums from beginSeqNum to endSeqNum, in order.
+func (s *store) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
+	msgs := make(chan []byte)
+
+	go func() {
+		defer close(msgs)
+
+		filter := bson.M{"session": s.sessionID, "msgseqnum": bson.M{"$gte": beginSeqNum, "$lte": endSeqNum}}
+		cursor, err := s.c.messages.Find(context.Background(), filter, options.Find().SetSort(bson.D{{Key: "msgseqnum", Value: 1}}))
+		if err != nil {
+			return
+		}
+		defer cursor.Close(context.Background())
+
+		for cursor.Next(context.Background()) {
+			var doc messageDoc
+			if err := cursor.Decode(&doc); err != nil {
+				return
+			}
+
+			msgs <- doc.Message
+		}
+	}()
+
+	return msgs
+}
@@ -0,0 +1,120 @@
+package mongostore
+
+import (
+	"context"
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/storetest"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewStoreFactory(t *testing.T) {
+	cfg := `
+[DEFAULT]
+SenderCompID=TW
+
+[SESSION]
+BeginString=FIX.4.1
+TargetCompID=ARCA
+`
+	settings, _ := quickfix.ParseSettings(strings.NewReader(cfg))
+	if _, err := NewStoreFactory(settings); err == nil {
+		t.Error("Should expect error when settings have no connection")
+	}
+
+	settings.GlobalSettings().Set(config.MongoStoreConnection, "mongodb://localhost:27017")
+	if _, err := NewStoreFactory(settings); err != nil {
+		t.Error("Did not expect error", err)
+	}
+
+	settings.GlobalSettings().Set(config.MongoStoreWriteConcern, "most")
+	if _, err := NewStoreFactory(settings); err == nil {
+		t.Error("Should expect error for invalid write concern")
+	}
+}
+
+func TestWriteConcernFor(t *testing.T) {
+	var testCases = []struct {
+		w, journal string
+		expectedW  interface{}
+		expectErr  bool
+	}{
+		{"majority", "Y", "majority", false},
+		{"2", "", 2, false},
+		{"-1", "", nil, true},
+		{"", "maybe", nil, true},
+	}
+
+	for _, tc := range testCases {
+		settings := quickfix.NewSessionSettings()
+		if tc.w != "" {
+			settings.Set(config.MongoStoreWriteConcern, tc.w)
+		}
+		if tc.journal != "" {
+			settings.Set(config.MongoStoreJournal, tc.journal)
+		}
+
+		writeConcern, err := writeConcernFor(settings)
+		if (err != nil) != tc.expectErr {
+			t.Errorf("%v %v: unexpected error %v", tc.w, tc.journal, err)
+			continue
+		}
+
+		if err == nil && writeConcern.W != tc.expectedW {
+			t.Errorf("%v: expected W %v got %v", tc.w, tc.expectedW, writeConcern.W)
+		}
+
+		if tc.journal == "Y" && (writeConcern.Journal == nil || !*writeConcern.Journal) {
+			t.Errorf("%v: expected journal", tc.w)
+		}
+	}
+
+	if writeConcern, err := writeConcernFor(quickfix.NewSessionSettings()); writeConcern != nil || err != nil {
+		t.Errorf("Expected the write concern of the connection string, got %v %v", writeConcern, err)
+	}
+}
+
+//TestStore_Conformance runs the store conformance tests against the MongoDB server of the connection string MONGODB_URI, skipped if unset.
+//Each test uses collections of its own in a database dropped once the tests are run.
+func TestStore_Conformance(t *testing.T) {
+	uri := os.Getenv("MONGODB_URI")
+	if uri == "" {
+		t.Skip("MONGODB_URI not set")
+	}
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+
+	database := fmt.Sprintf("quickfix_storetest_%d", time.Now().UnixNano())
+	defer client.Database(database).Drop(context.Background())
+
+	backends := 0
+	storetest.Run(t, storetest.Harness{
+		NewBackend: func(t *testing.T) func([]quickfix.SessionID) quickfix.MessageStoreFactory {
+			backends++
+			engine := fmt.Sprintf("backend%d", backends)
+
+			return func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory {
+				factory, err := NewStoreFactory(storetest.Settings(sessionIDs, map[string]string{
+					config.MongoStoreConnection: uri,
+					config.MongoStoreDatabase:   database,
+					config.MongoStoreEngine:     engine,
+				}))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return factory
+			}
+		},
+		Persistent: true,
+	})
+}
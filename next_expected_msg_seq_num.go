@@ -0,0 +1,58 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+)
+
+//setNextExpectedMsgSeqNum sets NextExpectedMsgSeqNum (789) on logon if enabled by the SendNextExpectedMsgSeqNum setting.
+//counterpartyLogon is the Logon being responded to, nil when initiating.
+func (s *Session) setNextExpectedMsgSeqNum(logon MessageBuilder, counterpartyLogon *Message) {
+	if !s.sendNextExpectedMsgSeqNum {
+		return
+	}
+
+	nextExpected := s.store.NextTargetMsgSeqNum()
+	if counterpartyLogon != nil {
+		//the logon being responded to is not yet counted
+		seqNum := new(fix.IntValue)
+		if err := counterpartyLogon.Header.GetField(tag.MsgSeqNum, seqNum); err == nil && seqNum.Value == nextExpected {
+			nextExpected++
+		}
+	}
+
+	logon.Body().Set(field.NewNextExpectedMsgSeqNum(nextExpected))
+}
+
+//hasNextExpectedMsgSeqNum returns true if NextExpectedMsgSeqNum is negotiated, enabled by the SendNextExpectedMsgSeqNum setting and sent by the counterparty on logon.
+//The counterparty then resends any messages missed before the logon without a ResendRequest.
+func (s *Session) hasNextExpectedMsgSeqNum(logon Message) bool {
+	return s.sendNextExpectedMsgSeqNum && logon.Body.Has(tag.NextExpectedMsgSeqNum)
+}
+
+//handleNextExpectedMsgSeqNum resends messages the counterparty has not received, as reported by the NextExpectedMsgSeqNum of their logon.
+//Our logon must already have been sent. Returns an error if the counterparty expects messages that have not been sent.
+func (s *Session) handleNextExpectedMsgSeqNum(logon Message) error {
+	if !s.hasNextExpectedMsgSeqNum(logon) {
+		return nil
+	}
+
+	nextExpected := new(fix.IntValue)
+	if err := logon.Body.GetField(tag.NextExpectedMsgSeqNum, nextExpected); err != nil {
+		return err
+	}
+
+	nextSeqNum := s.store.NextSenderMsgSeqNum()
+	switch {
+	case nextExpected.Value > nextSeqNum:
+		return fmt.Errorf("NextExpectedMsgSeqNum %d is higher than next sequence number %d", nextExpected.Value, nextSeqNum)
+
+	case nextExpected.Value < nextSeqNum:
+		logEventf(s.log, LogLevelInfo, LogCategorySession, "Counterparty expects %d, resending FROM: %d TO: %d", nextExpected.Value, nextExpected.Value, nextSeqNum-1)
+		inSession{}.resendMessages(s, nextExpected.Value, nextSeqNum-1)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+)
+
+func newNextExpectedTestSession(sent chan []byte) *Session {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+
+	return &Session{
+		sessionID:                 SessionID{BeginString: fix.BeginString_FIX44, SenderCompID: "TW", TargetCompID: "ISLD"},
+		header:                    newHeaderTemplate(SessionID{BeginString: fix.BeginString_FIX44, SenderCompID: "TW", TargetCompID: "ISLD"}),
+		store:                     store,
+		application:               &TestClient{},
+		messageOut:                sent,
+		log:                       nullLog{},
+		stateTimer:                eventTimer{Task: func() {}},
+		sendNextExpectedMsgSeqNum: true,
+	}
+}
+
+func TestSession_SetNextExpectedMsgSeqNum(t *testing.T) {
+	session := newNextExpectedTestSession(nil)
+	session.store.SetNextTargetMsgSeqNum(5)
+
+	logon := newTestLogon()
+	session.setNextExpectedMsgSeqNum(logon, nil)
+	checkStringField(t, buildAndParse(t, logon).Body, tag.NextExpectedMsgSeqNum, "5")
+
+	counterpartyLogon, _ := parseMessage(rawMessage("FIX.4.4", "35=A\00134=5\00149=ISLD\00156=TW\001"))
+	reply := newTestLogon()
+	session.setNextExpectedMsgSeqNum(reply, counterpartyLogon)
+	checkStringField(t, buildAndParse(t, reply).Body, tag.NextExpectedMsgSeqNum, "6")
+
+	gapLogon, _ := parseMessage(rawMessage("FIX.4.4", "35=A\00134=9\00149=ISLD\00156=TW\001"))
+	reply = newTestLogon()
+	session.setNextExpectedMsgSeqNum(reply, gapLogon)
+	checkStringField(t, buildAndParse(t, reply).Body, tag.NextExpectedMsgSeqNum, "5")
+
+	session.sendNextExpectedMsgSeqNum = false
+	logon = newTestLogon()
+	session.setNextExpectedMsgSeqNum(logon, nil)
+	if buildAndParse(t, logon).Body.Has(tag.NextExpectedMsgSeqNum) {
+		t.Error("Unexpected NextExpectedMsgSeqNum when disabled")
+	}
+}
+
+func TestSession_HandleNextExpectedMsgSeqNum(t *testing.T) {
+	sent := make(chan []byte, 10)
+	session := newNextExpectedTestSession(sent)
+
+	stored := []string{
+		"35=D\00134=1\00149=TW\00152=20140511-23:10:35.000\00156=ISLD\00111=ID1\001",
+		"35=D\00134=2\00149=TW\00152=20140511-23:10:36.000\00156=ISLD\00111=ID2\001",
+		"35=A\00134=3\00149=TW\00152=20140511-23:10:37.000\00156=ISLD\00198=0\001108=30\001",
+	}
+	for i, body := range stored {
+		session.store.SaveMessage(i+1, rawMessage(fix.BeginString_FIX44, body))
+		session.store.IncrNextSenderMsgSeqNum()
+	}
+
+	tooHigh, _ := parseMessage(rawMessage("FIX.4.4", "35=A\00134=1\00149=ISLD\00156=TW\001789=5\001"))
+	if err := session.handleNextExpectedMsgSeqNum(*tooHigh); err == nil {
+		t.Error("Expected error for NextExpectedMsgSeqNum higher than next sequence number")
+	}
+
+	upToDate, _ := parseMessage(rawMessage("FIX.4.4", "35=A\00134=1\00149=ISLD\00156=TW\001789=4\001"))
+	if err := session.handleNextExpectedMsgSeqNum(*upToDate); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("Expected no resend, got %v messages", len(sent))
+	}
+
+	behind, _ := parseMessage(rawMessage("FIX.4.4", "35=A\00134=1\00149=ISLD\00156=TW\001789=2\001"))
+	if err := session.handleNextExpectedMsgSeqNum(*behind); err != nil {
+		t.Fatal(err)
+	}
+	close(sent)
+
+	var resent []string
+	for msgBytes := range sent {
+		msg, err := parseMessage(msgBytes)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		var msgType field.MsgTypeField
+		msg.Header.Get(&msgType)
+		resent = append(resent, msgType.Value)
+
+		if msgType.Value == "4" {
+			checkStringField(t, msg.Body, tag.NewSeqNo, "4")
+		}
+	}
+
+	if len(resent) != 2 || resent[0] != "D" || resent[1] != "4" {
+		t.Errorf("Expected order 2 resent then gap fill of logon, got %v", resent)
+	}
+
+	disabled := newNextExpectedTestSession(nil)
+	disabled.sendNextExpectedMsgSeqNum = false
+	if err := disabled.handleNextExpectedMsgSeqNum(*tooHigh); err != nil {
+		t.Errorf("Expected NextExpectedMsgSeqNum to be ignored when disabled, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+//Package oteltrace provides a QuickFIX/Go MessageTracer recording the lifecycle of messages as OpenTelemetry spans.
+//
+//Each message received is a fix.receive span, from when it is read from the connection, and each message sent a fix.send span,
+//with a child span for each stage of its lifecycle, such as fix.parse and fix.FromApp. The context of a message received,
+//quickfix.Message.Context, carries its span, so that spans started by the Application in FromApp continue the trace,
+//and messages sent with quickfix.SendToTargetCtx are traced as children of the span of the context.
+//
+//	quickfix.SetMessageTracer(oteltrace.NewTracer(nil))
+package oteltrace
+
+import (
+	"context"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+//InstrumentationName is the name of the tracer of the spans.
+const InstrumentationName = "github.com/quickfixgo/quickfix/oteltrace"
+
+//Attributes of the spans.
+const (
+	AttributeSessionID    = attribute.Key("fix.session_id")
+	AttributeBeginString  = attribute.Key("fix.begin_string")
+	AttributeSenderCompID = attribute.Key("fix.sender_comp_id")
+	AttributeTargetCompID = attribute.Key("fix.target_comp_id")
+	AttributeMsgType      = attribute.Key("fix.msg_type")
+	AttributeMsgSeqNum    = attribute.Key("fix.msg_seq_num")
+)
+
+//Tracer is a quickfix.MessageTracer starting OpenTelemetry spans.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+//NewTracer returns a Tracer starting spans with the tracers of provider, the global TracerProvider if nil.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &Tracer{tracer: provider.Tracer(InstrumentationName)}
+}
+
+//StartReceive implements quickfix.MessageTracer, starting a consumer span at receiveTime.
+func (t *Tracer) StartReceive(ctx context.Context, sessionID quickfix.SessionID, receiveTime time.Time) (context.Context, quickfix.MessageTrace) {
+	return t.start(ctx, "fix.receive", sessionID, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithTimestamp(receiveTime))
+}
+
+//StartSend implements quickfix.MessageTracer, starting a producer span, a child of the span of ctx if any.
+func (t *Tracer) StartSend(ctx context.Context, sessionID quickfix.SessionID) (context.Context, quickfix.MessageTrace) {
+	return t.start(ctx, "fix.send", sessionID, trace.WithSpanKind(trace.SpanKindProducer))
+}
+
+func (t *Tracer) start(ctx context.Context, name string, sessionID quickfix.SessionID, options ...trace.SpanStartOption) (context.Context, quickfix.MessageTrace) {
+	options = append(options, trace.WithAttributes(
+		AttributeSessionID.String(sessionID.String()),
+		AttributeBeginString.String(sessionID.BeginString),
+		AttributeSenderCompID.String(sessionID.SenderCompID),
+		AttributeTargetCompID.String(sessionID.TargetCompID),
+	))
+
+	ctx, span := t.tracer.Start(ctx, name, options...)
+	return ctx, &messageTrace{ctx: ctx, span: span, tracer: t.tracer}
+}
+
+//messageTrace is the span of a message, parent of the spans of its stages.
+type messageTrace struct {
+	ctx    context.Context
+	span   trace.Span
+	tracer trace.Tracer
+}
+
+//StartStage implements quickfix.MessageTrace, starting a child span of the message span.
+func (m *messageTrace) StartStage(stage quickfix.MessageStage) func(err error) {
+	_, span := m.tracer.Start(m.ctx, "fix."+string(stage))
+
+	return func(err error) {
+		recordError(span, err)
+		span.End()
+	}
+}
+
+//End implements quickfix.MessageTrace, recording the MsgType and MsgSeqNum of header.
+func (m *messageTrace) End(header quickfix.FieldMap, err error) {
+	if header != nil {
+		var msgType fix.StringValue
+		if header.GetField(tag.MsgType, &msgType) == nil {
+			m.span.SetAttributes(AttributeMsgType.String(msgType.Value))
+		}
+
+		var seqNum fix.IntValue
+		if header.GetField(tag.MsgSeqNum, &seqNum) == nil {
+			m.span.SetAttributes(AttributeMsgSeqNum.Int(seqNum.Value))
+		}
+	}
+
+	recordError(m.span, err)
+	m.span.End()
+}
+
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,112 @@
+package oteltrace
+
+import (
+	"context"
+	"errors"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+var testSessionID = quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}
+
+func attributes(span sdktrace.ReadOnlySpan) map[string]string {
+	attributes := make(map[string]string)
+	for _, attribute := range span.Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+	return attributes
+}
+
+func TestTracer_Send(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewTracer(provider)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "order")
+	_, messageTrace := tracer.StartSend(ctx, testSessionID)
+
+	for _, stage := range []quickfix.MessageStage{quickfix.StageToApp, quickfix.StageSerialize, quickfix.StageStore} {
+		messageTrace.StartStage(stage)(nil)
+	}
+	messageTrace.StartStage(quickfix.StageWrite)(errors.New("connection closed"))
+
+	builder := quickfix.NewMessageBuilder()
+	builder.Header().Set(fix.NewStringField(tag.MsgType, "D"))
+	builder.Header().Set(fix.NewIntField(tag.MsgSeqNum, 42))
+	messageTrace.End(builder.Header(), errors.New("connection closed"))
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 6 {
+		t.Fatalf("Expected 6 spans, got %v", len(spans))
+	}
+
+	send := spans[4]
+	if send.Name() != "fix.send" || send.SpanKind() != trace.SpanKindProducer || send.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Expected fix.send producer span child of order, got %v %v", send.Name(), send.SpanKind())
+	}
+
+	expected := map[string]string{
+		"fix.session_id":     "FIX.4.4:CLIENT->VENUE",
+		"fix.begin_string":   "FIX.4.4",
+		"fix.sender_comp_id": "CLIENT",
+		"fix.target_comp_id": "VENUE",
+		"fix.msg_type":       "D",
+		"fix.msg_seq_num":    "42",
+	}
+	actual := attributes(send)
+	for key, value := range expected {
+		if actual[key] != value {
+			t.Errorf("Expected %v %v, got %v", key, value, actual[key])
+		}
+	}
+
+	if send.Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", send.Status())
+	}
+
+	for i, name := range []string{"fix.ToApp", "fix.serialize", "fix.store", "fix.write"} {
+		if spans[i].Name() != name || spans[i].Parent().SpanID() != send.SpanContext().SpanID() {
+			t.Errorf("Expected %v child of fix.send, got %v", name, spans[i].Name())
+		}
+	}
+
+	if spans[2].Status().Code == codes.Error || spans[3].Status().Code != codes.Error {
+		t.Error("Expected only fix.write to fail")
+	}
+}
+
+func TestTracer_Receive(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	receiveTime := time.Now().Add(-time.Millisecond)
+	ctx, messageTrace := tracer.StartReceive(context.Background(), testSessionID, receiveTime)
+	messageTrace.StartStage(quickfix.StageParse)(errors.New("garbled"))
+	messageTrace.End(nil, errors.New("garbled"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %v", len(spans))
+	}
+
+	receive := spans[1]
+	if receive.Name() != "fix.receive" || receive.SpanKind() != trace.SpanKindConsumer || !receive.StartTime().Equal(receiveTime) {
+		t.Errorf("Expected fix.receive consumer span started at %v, got %v %v %v", receiveTime, receive.Name(), receive.SpanKind(), receive.StartTime())
+	}
+
+	if trace.SpanContextFromContext(ctx).SpanID() != receive.SpanContext().SpanID() {
+		t.Error("Expected the context of the message to carry the fix.receive span")
+	}
+
+	if _, ok := attributes(receive)["fix.msg_type"]; ok {
+		t.Error("Expected no MsgType recorded for a message not parsed")
+	}
+}
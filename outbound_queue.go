@@ -0,0 +1,199 @@
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+//outboundQueueOverflow determines how a send is handled with the outbound queue of its session full.
+type outboundQueueOverflow int
+
+const (
+	//outboundQueueOverflowError fails the send with ErrOutboundQueueFull.
+	outboundQueueOverflowError outboundQueueOverflow = iota
+
+	//outboundQueueOverflowBlock waits until a message queued is sent.
+	outboundQueueOverflowBlock
+)
+
+//parseOutboundQueueOverflow maps the OutboundQueueOverflow setting to an outboundQueueOverflow.
+func parseOutboundQueueOverflow(setting string) (outboundQueueOverflow, error) {
+	switch setting {
+	case "Error":
+		return outboundQueueOverflowError, nil
+	case "Block":
+		return outboundQueueOverflowBlock, nil
+	}
+
+	return outboundQueueOverflowError, fmt.Errorf("invalid OutboundQueueOverflow %v, expected Error or Block", setting)
+}
+
+//outboundQueue is a bounded multi-producer single-consumer ring of the application messages sent on a session with OutboundQueueDepth.
+//Goroutines sending concurrently contend only on claiming a slot, with a compare and swap, rather than on the lock held while each message
+//is serialized, stored and written. Messages are sent in the order their slots are claimed, by a goroutine running while any are queued.
+type outboundQueue struct {
+	//head is the position of the next slot claimed, tail of the next slot sent, accessed atomically and first for alignment
+	head uint64
+	tail uint64
+
+	//draining is 1 while a goroutine sends the messages queued, accessed atomically
+	draining uint32
+
+	slots    []outboundSlot
+	mask     uint64
+	overflow outboundQueueOverflow
+
+	//space is signaled as messages queued are sent, waking a send blocked with the queue full
+	space chan struct{}
+
+	//send sends a message dequeued
+	send func(msg MessageBuilder)
+}
+
+//outboundSlot is a slot of the ring. seq is the position the slot is next claimed at while free, that position + 1 once its message is queued.
+type outboundSlot struct {
+	seq uint64
+	msg MessageBuilder
+}
+
+//newOutboundQueue returns a queue of depth messages, rounded up to a power of two, sending each dequeued with send.
+func newOutboundQueue(depth int, overflow outboundQueueOverflow, send func(msg MessageBuilder)) *outboundQueue {
+	size := 1
+	for size < depth {
+		size <<= 1
+	}
+
+	q := &outboundQueue{slots: make([]outboundSlot, size), mask: uint64(size - 1), overflow: overflow, space: make(chan struct{}, 1), send: send}
+	for i := range q.slots {
+		q.slots[i].seq = uint64(i)
+	}
+
+	return q
+}
+
+//push queues msg to be sent, returning ErrOutboundQueueFull with the queue full, or waiting for space until ctx is done with
+//OutboundQueueOverflow=Block.
+func (q *outboundQueue) push(ctx context.Context, msg MessageBuilder) error {
+	for !q.tryPush(msg) {
+		if q.overflow != outboundQueueOverflowBlock {
+			return ErrOutboundQueueFull
+		}
+
+		select {
+		case <-q.space:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	q.drain()
+	return nil
+}
+
+//tryPush claims the slot at head for msg, returning false if the queue is full.
+func (q *outboundQueue) tryPush(msg MessageBuilder) bool {
+	for {
+		pos := atomic.LoadUint64(&q.head)
+		slot := &q.slots[pos&q.mask]
+
+		switch seq := atomic.LoadUint64(&slot.seq); {
+		case seq == pos:
+			if atomic.CompareAndSwapUint64(&q.head, pos, pos+1) {
+				slot.msg = msg
+				atomic.StoreUint64(&slot.seq, pos+1)
+				return true
+			}
+
+		case seq < pos:
+			//the slot still holds the message queued a lap earlier
+			return false
+		}
+
+		//another send claimed the slot first
+	}
+}
+
+//pop dequeues the message at tail, returning false if none is queued. Called by the draining goroutine only.
+func (q *outboundQueue) pop() (MessageBuilder, bool) {
+	pos := atomic.LoadUint64(&q.tail)
+	slot := &q.slots[pos&q.mask]
+	if atomic.LoadUint64(&slot.seq) != pos+1 {
+		return nil, false
+	}
+
+	msg := slot.msg
+	slot.msg = nil
+	atomic.StoreUint64(&slot.seq, pos+q.mask+1)
+	atomic.StoreUint64(&q.tail, pos+1)
+
+	select {
+	case q.space <- struct{}{}:
+	default:
+	}
+
+	return msg, true
+}
+
+//queued returns true if the message at tail is queued.
+func (q *outboundQueue) queued() bool {
+	pos := atomic.LoadUint64(&q.tail)
+	return atomic.LoadUint64(&q.slots[pos&q.mask].seq) == pos+1
+}
+
+//drain starts a goroutine sending the messages queued, unless one is running. The goroutine returns once the queue is empty.
+func (q *outboundQueue) drain() {
+	if !atomic.CompareAndSwapUint32(&q.draining, 0, 1) {
+		return
+	}
+
+	go func() {
+		for {
+			for msg, ok := q.pop(); ok; msg, ok = q.pop() {
+				q.send(msg)
+			}
+
+			//a message queued while draining is sent by this goroutine, unless another is started once done
+			atomic.StoreUint32(&q.draining, 0)
+			if !q.queued() || !atomic.CompareAndSwapUint32(&q.draining, 0, 1) {
+				return
+			}
+		}
+	}()
+}
+
+//depth returns the number of messages queued.
+func (q *outboundQueue) depth() int {
+	return int(atomic.LoadUint64(&q.head) - atomic.LoadUint64(&q.tail))
+}
+
+//capacity returns the number of messages the queue holds.
+func (q *outboundQueue) capacity() int {
+	return len(q.slots)
+}
+
+//submit sends msg on behalf of the Application, queueing it on the outbound queue with OutboundQueueDepth, otherwise as sendOrQueueCtx.
+func (s *Session) submit(ctx context.Context, msg MessageBuilder) error {
+	if s.outbound == nil {
+		return s.sendOrQueueCtx(ctx, msg)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.outbound.push(ctx, msg)
+}
+
+//sendQueued sends msg dequeued from the outbound queue, reporting the error if not sent to the SendErrorListener.
+func (s *Session) sendQueued(msg MessageBuilder) {
+	err := s.sendOrQueue(msg)
+	if err == nil {
+		return
+	}
+
+	logEventf(s.log, LogLevelWarn, LogCategoryTransport, "Queued message not sent: %v", err)
+	if listener, ok := s.application.(SendErrorListener); ok {
+		listener.OnSendError(msg, s.sessionID, err)
+	}
+}
@@ -0,0 +1,202 @@
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseOutboundQueueOverflow(t *testing.T) {
+	if overflow, err := parseOutboundQueueOverflow("Block"); err != nil || overflow != outboundQueueOverflowBlock {
+		t.Errorf("Expected Block, got %v %v", overflow, err)
+	}
+
+	if _, err := parseOutboundQueueOverflow("DropOldest"); err == nil {
+		t.Error("Expected error for DropOldest")
+	}
+}
+
+func TestSession_OutboundQueueConcurrentSends(t *testing.T) {
+	const senders, sends = 8, 50
+
+	s := newTestSendQueueSession(0, sendQueueOverflowError)
+	s.messageOut = make(chan []byte, senders*sends)
+	s.outbound = newOutboundQueue(16, outboundQueueOverflowBlock, s.sendQueued)
+	s.updateSendQueue(StateLoggedOn)
+
+	if s.outbound.capacity() != 16 {
+		t.Errorf("Expected capacity 16, got %v", s.outbound.capacity())
+	}
+
+	var wg sync.WaitGroup
+	for sender := 0; sender < senders; sender++ {
+		wg.Add(1)
+		go func(sender int) {
+			defer wg.Done()
+			for i := 0; i < sends; i++ {
+				if err := s.submit(context.Background(), newSendQueueTestMessage(fmt.Sprintf("%d-%d", sender, i))); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}
+		}(sender)
+	}
+	wg.Wait()
+
+	next := make(map[string]int)
+	for n := 1; n <= senders*sends; n++ {
+		var msgBytes []byte
+		select {
+		case msgBytes = <-s.messageOut:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %v messages sent, got %v", senders*sends, n-1)
+		}
+
+		msg, err := parseMessage(msgBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		seqNum, clOrdID := new(fix.IntValue), new(fix.StringValue)
+		msg.Header.GetField(tag.MsgSeqNum, seqNum)
+		msg.Body.GetField(tag.ClOrdID, clOrdID)
+
+		//the messages of each sender are sent in order
+		parts := strings.Split(clOrdID.Value, "-")
+		if expected := fmt.Sprintf("%v-%d", parts[0], next[parts[0]]); clOrdID.Value != expected || seqNum.Value != n {
+			t.Errorf("Expected %v with MsgSeqNum %v, got %v with MsgSeqNum %v", expected, n, clOrdID.Value, seqNum.Value)
+		}
+		next[parts[0]]++
+	}
+
+	if depth := s.outbound.depth(); depth != 0 {
+		t.Errorf("Expected the queue drained, got %v", depth)
+	}
+}
+
+func TestOutboundQueue_Overflow(t *testing.T) {
+	full := newOutboundQueue(3, outboundQueueOverflowError, func(MessageBuilder) {})
+	for i := 0; i < 4; i++ {
+		if !full.tryPush(newSendQueueTestMessage("1")) {
+			t.Fatalf("Expected message %v queued", i+1)
+		}
+	}
+
+	if err := full.push(context.Background(), newSendQueueTestMessage("5")); err != ErrOutboundQueueFull {
+		t.Errorf("Expected ErrOutboundQueueFull, got %v", err)
+	}
+
+	sent := make(chan string, 5)
+	blocking := newOutboundQueue(4, outboundQueueOverflowBlock, func(msg MessageBuilder) {
+		clOrdID := new(fix.StringValue)
+		msg.Body().GetField(tag.ClOrdID, clOrdID)
+		sent <- clOrdID.Value
+	})
+	for _, clOrdID := range []string{"1", "2", "3", "4"} {
+		blocking.tryPush(newSendQueueTestMessage(clOrdID))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := blocking.push(ctx, newSendQueueTestMessage("5")); err != context.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	pushed := make(chan error)
+	go func() {
+		pushed <- blocking.push(context.Background(), newSendQueueTestMessage("5"))
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("Expected push to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if msg, ok := blocking.pop(); !ok || msg == nil {
+		t.Fatal("Expected a message dequeued")
+	}
+
+	if err := <-pushed; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{"2", "3", "4", "5"} {
+		select {
+		case clOrdID := <-sent:
+			if clOrdID != expected {
+				t.Errorf("Expected %v sent, got %v", expected, clOrdID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %v sent", expected)
+		}
+	}
+}
+
+//sendErrorClient records the errors of queued messages not sent.
+type sendErrorClient struct {
+	TestClient
+	errs chan error
+}
+
+func (c *sendErrorClient) OnSendError(msg MessageBuilder, sessionID SessionID, err error) {
+	c.errs <- err
+}
+
+func TestSession_OutboundQueueSendError(t *testing.T) {
+	s := newTestSendQueueSession(0, sendQueueOverflowError)
+	app := &sendErrorClient{errs: make(chan error, 1)}
+	s.application = app
+	s.store = failingStore{s.store}
+	s.outbound = newOutboundQueue(4, outboundQueueOverflowError, s.sendQueued)
+	s.updateSendQueue(StateLoggedOn)
+
+	if err := s.submit(context.Background(), newSendQueueTestMessage("1")); err != nil {
+		t.Fatalf("Expected message queued, got %v", err)
+	}
+
+	select {
+	case err := <-app.errs:
+		if _, ok := err.(StoreError); !ok {
+			t.Errorf("Expected StoreError, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the send error reported")
+	}
+}
+
+func BenchmarkSession_SendToTargetConcurrent(b *testing.B) {
+	for _, queued := range []bool{false, true} {
+		b.Run(fmt.Sprintf("OutboundQueue=%v", queued), func(b *testing.B) {
+			s := newTestSendQueueSession(0, sendQueueOverflowError)
+			s.persistMessages = persistNone
+			s.messageOut = make(chan []byte, 1024)
+			if queued {
+				s.outbound = newOutboundQueue(1024, outboundQueueOverflowBlock, s.sendQueued)
+			}
+			s.updateSendQueue(StateLoggedOn)
+
+			done := make(chan struct{})
+			go func() {
+				for {
+					select {
+					case <-s.messageOut:
+					case <-done:
+						return
+					}
+				}
+			}()
+			defer close(done)
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					s.submit(context.Background(), newSendQueueTestMessage("1"))
+				}
+			})
+		})
+	}
+}
@@ -4,17 +4,37 @@ import (
 	"bytes"
 	"github.com/quickfixgo/quickfix/fix"
 	"io"
+	"io/ioutil"
 	"time"
 )
 
 const (
 	defaultBufSize = 4096
+
+	//maxHeaderPeek bounds the bytes read to recover the header of a discarded message
+	maxHeaderPeek = 1024
 )
 
 type parser struct {
-	buffer   []byte
+	//buffer holds the bytes read and not yet parsed, in readBuffer, taken from the buffer pool
+	buffer     []byte
+	readBuffer []byte
+
 	reader   io.Reader
 	lastRead time.Time
+
+	//maxMessageSize is the largest BodyLength accepted, 0 for no limit
+	maxMessageSize int
+
+	//clock stamps lastRead, SystemClock if nil
+	clock Clock
+
+	//debug records the read buffer of the session, nil until the session is known
+	debug *sessionDebug
+
+	//tooLarge is the message being discarded once a read would block, with discarding bytes of its body still to be read
+	tooLarge   *MessageTooLargeError
+	discarding int64
 }
 
 func newParser(reader io.Reader) *parser {
@@ -23,17 +43,43 @@ func newParser(reader io.Reader) *parser {
 
 func (p *parser) readMore() (int, error) {
 	if len(p.buffer) == cap(p.buffer) {
-		newBuffer := make([]byte, len(p.buffer), len(p.buffer)+defaultBufSize)
-		copy(newBuffer, p.buffer)
-		p.buffer = newBuffer
+		if len(p.buffer) < cap(p.readBuffer)/2 {
+			//the bytes not yet parsed are moved to the front of the read buffer, at least half of it free to read into
+			p.buffer = p.readBuffer[:copy(p.readBuffer, p.buffer)]
+		} else {
+			//reads into a new buffer are bounded to defaultBufSize bytes past those buffered, whatever its size class
+			size := len(p.buffer) + defaultBufSize
+			readBuffer := getBuffer(size)
+			readBuffer = readBuffer[:cap(readBuffer)]
+			unparsed := copy(readBuffer, p.buffer)
+			p.release()
+			p.buffer, p.readBuffer = readBuffer[:unparsed:size], readBuffer
+
+			recordParserBuffer(cap(readBuffer))
+			if p.debug != nil {
+				p.debug.setParserBuffer(cap(readBuffer))
+			}
+		}
 	}
 
 	n, e := p.reader.Read(p.buffer[len(p.buffer):cap(p.buffer)])
-	p.lastRead = time.Now()
+	if p.clock != nil {
+		p.lastRead = p.clock.Now()
+	} else {
+		p.lastRead = time.Now()
+	}
 	p.buffer = p.buffer[:len(p.buffer)+n]
 	return n, e
 }
 
+//release returns the read buffer to the pool, dropping the bytes not yet parsed, once the connection is read to the end.
+func (p *parser) release() {
+	if p.readBuffer != nil {
+		putBuffer(p.readBuffer)
+	}
+	p.buffer, p.readBuffer = nil, nil
+}
+
 func (p *parser) findIndex(delim []byte) (int, error) {
 	return p.findIndexAfterOffset(0, delim)
 }
@@ -79,43 +125,111 @@ func (p *parser) findEndAfterOffset(offset int) (int, error) {
 }
 
 func (p *parser) jumpLength() (int, error) {
+	offset, length, err := p.readLength()
+	if err != nil {
+		return length, err
+	}
+	return offset + length, nil
+}
+
+//readLength returns the index of the delimiter ending the BodyLength field and the BodyLength value.
+func (p *parser) readLength() (offset int, length int, err error) {
 	lengthIndex, err := p.findIndex([]byte("9="))
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	lengthIndex += 3
 
-	offset, err := p.findIndexAfterOffset(lengthIndex, []byte("\001"))
-	if err != nil {
-		return 0, err
+	if offset, err = p.findIndexAfterOffset(lengthIndex, []byte("\001")); err != nil {
+		return 0, 0, err
+	}
+
+	length, err = fix.Atoi(p.buffer[lengthIndex:offset])
+	return offset, length, err
+}
+
+//discardMessage drops the message at the start of the buffer, ending at the trailer following index.
+//The standard header fields read before the body are returned in a MessageTooLargeError.
+func (p *parser) discardMessage(index, length int) error {
+	for len(p.buffer) < index+1 && len(p.buffer) < maxHeaderPeek {
+		if n, err := p.readMore(); n == 0 && err != nil {
+			return err
+		}
 	}
 
-	length, err := fix.Atoi(p.buffer[lengthIndex:offset])
+	headerBytes := p.buffer
+	if len(headerBytes) > index+1 {
+		headerBytes = headerBytes[:index+1]
+	}
+
+	//buffer is reused while discarding, header fields must not reference it
+	headerBytes = append([]byte(nil), headerBytes...)
+
+	tooLarge := MessageTooLargeError{BodyLength: length, MaxMessageSize: p.maxMessageSize}
+	tooLarge.Header = parseHeader(headerBytes)
+
+	if len(p.buffer) >= index {
+		p.buffer = p.buffer[index:]
+	} else {
+		p.discarding = int64(index - len(p.buffer))
+		p.buffer = p.buffer[:0]
+	}
+
+	p.tooLarge = &tooLarge
+	return p.finishDiscard()
+}
+
+//finishDiscard reads and drops the rest of the message being discarded, returning its MessageTooLargeError once dropped.
+//The connection of a worker pool is read without blocking, the discard resumed by the next ReadMessage if a read would block.
+func (p *parser) finishDiscard() error {
+	if p.discarding > 0 {
+		n, err := io.CopyN(ioutil.Discard, p.reader, p.discarding)
+		p.discarding -= n
+		if err != nil {
+			return err
+		}
+	}
+
+	end, err := p.findEndAfterOffset(0)
 	if err != nil {
-		return length, err
+		return err
 	}
-	return offset + length, nil
+	p.buffer = p.buffer[end:]
+
+	tooLarge := *p.tooLarge
+	p.tooLarge = nil
+	return tooLarge
 }
 
 func (p *parser) ReadMessage() ([]byte, error) {
+	if p.tooLarge != nil {
+		return []byte{}, p.finishDiscard()
+	}
+
 	start, err := p.findStart()
 	if err != nil {
 		return []byte{}, err
 	}
 	p.buffer = p.buffer[start:]
 
-	index, err := p.jumpLength()
+	offset, length, err := p.readLength()
 	if err != nil {
 		return []byte{}, err
 	}
+	index := offset + length
+
+	if p.maxMessageSize > 0 && length > p.maxMessageSize {
+		return []byte{}, p.discardMessage(index, length)
+	}
 
 	index, err = p.findEndAfterOffset(index)
 	if err != nil {
 		return []byte{}, err
 	}
 
-	msgBytes := p.buffer[:index]
+	//the message is kept by the session and the application, the read buffer being reused
+	msgBytes := append(make([]byte, 0, index), p.buffer[:index]...)
 	p.buffer = p.buffer[index:]
 
 	return msgBytes, nil
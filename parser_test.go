@@ -1,6 +1,9 @@
 package quickfix
 
 import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"io"
 	"strings"
 	"testing"
 )
@@ -183,3 +186,93 @@ func TestParser_ReadMessageGrowBuffer(t *testing.T) {
 		}
 	}
 }
+
+func TestParser_ReadMessageTooLarge(t *testing.T) {
+	large := string(rawMessage("FIX.4.2", "35=D\00134=3\00149=TW\00156=ISLD\00158="+strings.Repeat("x", 2*defaultBufSize)+"\001"))
+	small := string(rawMessage("FIX.4.2", "35=0\00134=4\00149=TW\00156=ISLD\001"))
+
+	parser := newParser(strings.NewReader(large + small))
+	parser.maxMessageSize = 100
+
+	_, err := parser.ReadMessage()
+	tooLarge, ok := err.(MessageTooLargeError)
+	if !ok {
+		t.Fatal("expected MessageTooLargeError, got ", err)
+	}
+
+	msgType := new(fix.StringValue)
+	if err := tooLarge.Header.GetField(tag.MsgType, msgType); err != nil || msgType.Value != "D" {
+		t.Errorf("expected MsgType D in discarded header, got %v %v", msgType.Value, err)
+	}
+
+	msg, err := parser.ReadMessage()
+	if err != nil {
+		t.Fatal("unexpected error ", err)
+	}
+
+	if string(msg) != small {
+		t.Errorf("expected %q got %q", small, msg)
+	}
+}
+
+//wouldBlockReader reads a chunk of its bytes each time it is readable, as a polled connection does.
+type wouldBlockReader struct {
+	data     string
+	chunk    int
+	readable bool
+}
+
+func (r *wouldBlockReader) Read(p []byte) (int, error) {
+	if !r.readable {
+		return 0, errWouldBlock
+	}
+	r.readable = false
+
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+
+	if len(p) > r.chunk {
+		p = p[:r.chunk]
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestParser_ReadMessageTooLargeWouldBlock(t *testing.T) {
+	large := string(rawMessage("FIX.4.2", "35=D\00134=3\00149=TW\00156=ISLD\00158="+strings.Repeat("x", 2*defaultBufSize)+"\001"))
+	small := string(rawMessage("FIX.4.2", "35=0\00134=4\00149=TW\00156=ISLD\001"))
+
+	reader := &wouldBlockReader{data: large + small, chunk: 1000}
+	parser := newParser(reader)
+	parser.maxMessageSize = 100
+
+	var tooLarge, read []string
+	for i := 0; i < 100 && len(read) == 0; i++ {
+		reader.readable = true
+
+		msg, err := parser.ReadMessage()
+		switch err := err.(type) {
+		case nil:
+			read = append(read, string(msg))
+		case MessageTooLargeError:
+			msgType := new(fix.StringValue)
+			err.Header.GetField(tag.MsgType, msgType)
+			tooLarge = append(tooLarge, msgType.Value)
+		default:
+			if err != errWouldBlock {
+				t.Fatal("unexpected error ", err)
+			}
+		}
+	}
+
+	if len(tooLarge) != 1 || tooLarge[0] != "D" {
+		t.Errorf("expected one MessageTooLargeError for D, got %v", tooLarge)
+	}
+
+	if len(read) != 1 || read[0] != small {
+		t.Errorf("expected %q got %q", small, read)
+	}
+}
@@ -7,7 +7,8 @@ type pendingTimeout struct {
 func (currentState pendingTimeout) Timeout(session *Session, event event) (nextState sessionState) {
 	switch event {
 	case peerTimeout:
-		session.log.OnEvent("Session Timeout")
+		logEventf(session.log, LogLevelWarn, LogCategoryTransport, "Session Timeout")
+		session.onAnomaly(AnomalyHeartbeatTimeout, 1, nil)
 		return latentState{}
 	}
 
@@ -0,0 +1,44 @@
+package quickfix
+
+import "fmt"
+
+//persistMessages determines which messages sent by a session are saved to the MessageStore.
+//Sequence numbers and recovery state are saved regardless, messages not saved are replaced by SequenceReset-GapFill when resent.
+type persistMessages int
+
+const (
+	//persistAll saves every message sent.
+	persistAll persistMessages = iota
+
+	//persistNone saves no messages, for sessions such as market data where replay is meaningless.
+	persistNone
+
+	//persistAdmin saves only admin messages, keeping a record of session level traffic without the cost of saving application messages.
+	persistAdmin
+)
+
+//parsePersistMessages maps the PersistMessages setting to a persistMessages.
+func parsePersistMessages(setting string) (persistMessages, error) {
+	switch setting {
+	case "Y", "y":
+		return persistAll, nil
+	case "N", "n":
+		return persistNone, nil
+	case "Admin":
+		return persistAdmin, nil
+	}
+
+	return persistAll, fmt.Errorf("invalid PersistMessages %v, expected Y, N, or Admin", setting)
+}
+
+//persists returns true if a message sent is saved to the MessageStore, isAdmin if it is an admin message.
+func (p persistMessages) persists(isAdmin bool) bool {
+	switch p {
+	case persistNone:
+		return false
+	case persistAdmin:
+		return isAdmin
+	}
+
+	return true
+}
@@ -0,0 +1,94 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+)
+
+func TestSession_PersistMessages(t *testing.T) {
+	var tests = []struct {
+		setting       string
+		expectedTypes []string
+	}{
+		{"Y", []string{"0", "D"}},
+		{"N", nil},
+		{"Admin", []string{"0"}},
+	}
+
+	for _, test := range tests {
+		s := newTestSendQueueSession(0, sendQueueOverflowError)
+		var err error
+		if s.persistMessages, err = parsePersistMessages(test.setting); err != nil {
+			t.Fatal(err)
+		}
+
+		heartbeat := NewMessageBuilder()
+		heartbeat.Header().Set(fix.NewStringField(tag.MsgType, "0"))
+		for _, msg := range []MessageBuilder{heartbeat, newSendQueueTestMessage("1")} {
+			if err := s.send(msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var stored []string
+		for msgBytes := range s.store.GetMessages(1, 2) {
+			msg, _ := parseMessage(msgBytes)
+			msgType := new(fix.StringValue)
+			msg.Header.GetField(tag.MsgType, msgType)
+			stored = append(stored, msgType.Value)
+		}
+
+		if len(stored) != len(test.expectedTypes) {
+			t.Errorf("PersistMessages=%v: expected %v stored got %v", test.setting, test.expectedTypes, stored)
+			continue
+		}
+
+		for n, msgType := range test.expectedTypes {
+			if stored[n] != msgType {
+				t.Errorf("PersistMessages=%v: expected %v stored got %v", test.setting, test.expectedTypes, stored)
+			}
+		}
+
+		if s.store.NextSenderMsgSeqNum() != 3 {
+			t.Errorf("PersistMessages=%v: expected sequence numbers saved regardless", test.setting)
+		}
+	}
+
+	if _, err := parsePersistMessages("Sometimes"); err == nil {
+		t.Error("Expected error for invalid PersistMessages")
+	}
+}
+
+func TestSession_PersistMessagesResendGapFill(t *testing.T) {
+	s := newTestSendQueueSession(0, sendQueueOverflowError)
+	s.persistMessages = persistNone
+	s.sessionID = SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+	s.header = newHeaderTemplate(s.sessionID)
+
+	for _, clOrdID := range []string{"1", "2"} {
+		if err := s.send(newSendQueueTestMessage(clOrdID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-s.messageOut
+	<-s.messageOut
+
+	inSession{}.resendMessages(s, 1, 2)
+
+	if len(s.messageOut) != 1 {
+		t.Fatalf("Expected a single gap fill got %v messages", len(s.messageOut))
+	}
+
+	msg, err := parseMessage(<-s.messageOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgType, newSeqNo := new(fix.StringValue), new(fix.IntValue)
+	msg.Header.GetField(tag.MsgType, msgType)
+	msg.Body.GetField(tag.NewSeqNo, newSeqNo)
+	if msgType.Value != "4" || newSeqNo.Value != 3 {
+		t.Errorf("Expected SequenceReset-GapFill to 3 got MsgType %v NewSeqNo %v", msgType.Value, newSeqNo.Value)
+	}
+}
@@ -0,0 +1,224 @@
+package quickfix
+
+import (
+	"io"
+	"net"
+	"sync"
+	"syscall"
+)
+
+//pollReadable and pollWritable are the readiness a polledConn is armed for.
+const (
+	pollReadable = syscall.EPOLLIN | syscall.EPOLLRDHUP
+	pollWritable = syscall.EPOLLOUT
+)
+
+//poller waits on epoll for the connections of a worker pool to be readable or writable, on a single goroutine for the pool.
+//Connections are armed one shot, each readiness reported once until armed again.
+type poller struct {
+	fd int
+
+	lock  sync.Mutex
+	conns map[int]*polledConn
+}
+
+//polledConn is a connection registered with a poller.
+type polledConn struct {
+	poller *poller
+	fd     int
+
+	//readable and writable are called on the goroutine of the poller once the connection is ready
+	readable, writable func()
+
+	//armed are the events the connection is armed for, guarded by the lock of the poller
+	armed uint32
+}
+
+//newPoller returns a poller waiting on its own goroutine.
+func newPoller() (*poller, error) {
+	fd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &poller{fd: fd, conns: make(map[int]*polledConn)}
+	go p.wait()
+
+	return p, nil
+}
+
+//pollConn reads and writes a polled connection without blocking, returning errWouldBlock rather than waiting for it
+//to be ready. The bytes are recorded to the wire capture of the connection, if any.
+type pollConn struct {
+	raw     syscall.RawConn
+	capture *captureConn
+	fd      int
+}
+
+//newPollConn returns conn read and written without blocking, unwrapping the connection of a wire capture. Returns false
+//for connections without a file descriptor, such as pipes, and for TLS, whose records cannot be resumed once a read or
+//write would block.
+func newPollConn(conn net.Conn) (*pollConn, bool) {
+	c := &pollConn{fd: -1}
+	if capture, ok := conn.(*captureConn); ok {
+		c.capture, conn = capture, capture.Conn
+	}
+
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return nil, false
+	}
+
+	var err error
+	if c.raw, err = sc.SyscallConn(); err != nil {
+		return nil, false
+	}
+
+	if err := c.raw.Control(func(fd uintptr) { c.fd = int(fd) }); err != nil || c.fd < 0 {
+		return nil, false
+	}
+
+	return c, true
+}
+
+func (c *pollConn) Read(p []byte) (n int, err error) {
+	if rawErr := c.raw.Read(func(fd uintptr) bool {
+		for n, err = syscall.Read(int(fd), p); err == syscall.EINTR; {
+			n, err = syscall.Read(int(fd), p)
+		}
+		return true
+	}); rawErr != nil {
+		return 0, rawErr
+	}
+
+	switch {
+	case err == syscall.EAGAIN:
+		return 0, errWouldBlock
+	case err != nil:
+		return 0, err
+	case n == 0 && len(p) > 0:
+		return 0, io.EOF
+	}
+
+	if c.capture != nil {
+		c.capture.record(WireRead, p[:n])
+	}
+	return n, nil
+}
+
+//Write writes as much of p as the connection takes, returning errWouldBlock with the bytes written if not all of p.
+func (c *pollConn) Write(p []byte) (n int, err error) {
+	for n < len(p) && err == nil {
+		var written int
+		if rawErr := c.raw.Write(func(fd uintptr) bool {
+			for written, err = syscall.Write(int(fd), p[n:]); err == syscall.EINTR; {
+				written, err = syscall.Write(int(fd), p[n:])
+			}
+			return true
+		}); rawErr != nil {
+			err = rawErr
+		}
+
+		if written > 0 {
+			if c.capture != nil {
+				c.capture.record(WireWritten, p[n:n+written])
+			}
+			n += written
+		}
+	}
+
+	if err == syscall.EAGAIN {
+		err = errWouldBlock
+	}
+	return n, err
+}
+
+//add registers the connection of fd, disarmed, calling readable and writable once armed for and ready.
+func (p *poller) add(fd int, readable, writable func()) (*polledConn, error) {
+	c := &polledConn{poller: p, fd: fd, readable: readable, writable: writable}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	event := syscall.EpollEvent{Events: syscall.EPOLLONESHOT, Fd: int32(fd)}
+	if err := syscall.EpollCtl(p.fd, syscall.EPOLL_CTL_ADD, fd, &event); err != nil {
+		return nil, err
+	}
+
+	p.conns[fd] = c
+	return c, nil
+}
+
+//arm arms the connection for events, in addition to those it is armed for.
+func (c *polledConn) arm(events uint32) {
+	p := c.poller
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.conns[c.fd] != c || c.armed&events == events {
+		return
+	}
+
+	c.armed |= events
+	event := syscall.EpollEvent{Events: c.armed | syscall.EPOLLONESHOT, Fd: int32(c.fd)}
+	syscall.EpollCtl(p.fd, syscall.EPOLL_CTL_MOD, c.fd, &event)
+}
+
+//remove unregisters the connection, before it is closed.
+func (c *polledConn) remove() {
+	p := c.poller
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.conns[c.fd] != c {
+		return
+	}
+
+	delete(p.conns, c.fd)
+	syscall.EpollCtl(p.fd, syscall.EPOLL_CTL_DEL, c.fd, nil)
+}
+
+//wait reports the connections ready, rearming each for the events armed but not reported.
+func (p *poller) wait() {
+	events := make([]syscall.EpollEvent, 128)
+
+	for {
+		n, err := syscall.EpollWait(p.fd, events, -1)
+		if err != nil {
+			//interrupted
+			continue
+		}
+
+		for _, event := range events[:n] {
+			p.lock.Lock()
+			c, ok := p.conns[int(event.Fd)]
+			if !ok {
+				p.lock.Unlock()
+				continue
+			}
+
+			//errors and hang ups are reported to both, which find them reading or writing
+			ready := event.Events
+			if ready&(syscall.EPOLLERR|syscall.EPOLLHUP) != 0 {
+				ready |= pollReadable | pollWritable
+			}
+			ready &= c.armed
+
+			c.armed &^= ready
+			if c.armed != 0 {
+				rearm := syscall.EpollEvent{Events: c.armed | syscall.EPOLLONESHOT, Fd: int32(c.fd)}
+				syscall.EpollCtl(p.fd, syscall.EPOLL_CTL_MOD, c.fd, &rearm)
+			}
+			p.lock.Unlock()
+
+			if ready&pollReadable != 0 {
+				c.readable()
+			}
+			if ready&pollWritable != 0 {
+				c.writable()
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !linux
+
+package quickfix
+
+import (
+	"errors"
+	"net"
+)
+
+//pollReadable and pollWritable are the readiness a polledConn is armed for.
+const (
+	pollReadable = 1 << iota
+	pollWritable
+)
+
+//poller is only implemented on Linux, the connections of worker pools elsewhere are read and written on goroutines of their own.
+type poller struct{}
+
+//polledConn is a connection registered with a poller.
+type polledConn struct{}
+
+func newPoller() (*poller, error) {
+	return nil, errors.New("connections are not polled on this platform")
+}
+
+//pollConn reads and writes a polled connection without blocking.
+type pollConn struct {
+	fd int
+}
+
+func newPollConn(conn net.Conn) (*pollConn, bool) {
+	return nil, false
+}
+
+func (c *pollConn) Read(p []byte) (int, error) {
+	return 0, errWouldBlock
+}
+
+func (c *pollConn) Write(p []byte) (int, error) {
+	return 0, errWouldBlock
+}
+
+func (p *poller) add(fd int, readable, writable func()) (*polledConn, error) {
+	return nil, errors.New("connections are not polled on this platform")
+}
+
+func (c *polledConn) arm(events uint32) {}
+
+func (c *polledConn) remove() {}
@@ -0,0 +1,213 @@
+package quickfix
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//proxyHeaderTimeout bounds the wait for the PROXY protocol header of an accepted connection.
+const proxyHeaderTimeout = 10 * time.Second
+
+//proxyV2Signature starts a PROXY protocol version 2 header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+//proxyProtocolPolicy determines whether connections accepted behind a load balancer start with a PROXY protocol header.
+type proxyProtocolPolicy int
+
+const (
+	//proxyProtocolOff reads connections without a header.
+	proxyProtocolOff proxyProtocolPolicy = iota
+
+	//proxyProtocolOptional reads the header of connections starting with one.
+	proxyProtocolOptional
+
+	//proxyProtocolRequired refuses connections without a header.
+	proxyProtocolRequired
+)
+
+//parseProxyProtocolPolicy maps the SocketProxyProtocol setting to a proxyProtocolPolicy.
+func parseProxyProtocolPolicy(setting string) (proxyProtocolPolicy, error) {
+	switch setting {
+	case "Off":
+		return proxyProtocolOff, nil
+	case "Optional":
+		return proxyProtocolOptional, nil
+	case "Required":
+		return proxyProtocolRequired, nil
+	}
+
+	return proxyProtocolOff, fmt.Errorf("invalid SocketProxyProtocol %v, expected Off, Optional, or Required", setting)
+}
+
+//proxyListener accepts connections whose remote address is the client address of their PROXY protocol header.
+type proxyListener struct {
+	net.Listener
+	policy proxyProtocolPolicy
+	log    Log
+}
+
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyConn{Conn: conn, policy: l.policy, log: l.log, reader: bufio.NewReader(conn)}, nil
+}
+
+//proxyConn is a connection accepted behind a load balancer. Its header is read on the first Read or RemoteAddr,
+//so a client slow to send it does not delay accepting other connections.
+type proxyConn struct {
+	net.Conn
+	policy proxyProtocolPolicy
+	log    Log
+	reader *bufio.Reader
+
+	once       sync.Once
+	remoteAddr net.Addr
+	err        error
+}
+
+func (c *proxyConn) Read(p []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(p)
+}
+
+//RemoteAddr returns the client address of the header, the address of the load balancer if the header has none.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+//readHeader reads the PROXY protocol header, the connection fails if the header is invalid or required and missing.
+func (c *proxyConn) readHeader() {
+	c.Conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+	defer c.Conn.SetReadDeadline(time.Time{})
+
+	if c.remoteAddr, c.err = readProxyHeader(c.reader, c.policy == proxyProtocolRequired); c.err != nil {
+		logEventf(c.log, LogLevelWarn, LogCategoryTransport, "Invalid PROXY protocol header from %v: %v", c.Conn.RemoteAddr(), c.err)
+		c.Conn.Close()
+	}
+}
+
+//readProxyHeader reads a version 1 or 2 header from reader, returning the client address it declares, nil for a header without
+//an address or, unless required, a connection without a header.
+func readProxyHeader(reader *bufio.Reader, required bool) (net.Addr, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch first[0] {
+	case 'P':
+		if start, err := reader.Peek(6); err == nil && string(start) == "PROXY " {
+			return readProxyV1Header(reader)
+		}
+	case '\r':
+		if start, err := reader.Peek(len(proxyV2Signature)); err == nil && bytes.Equal(start, proxyV2Signature) {
+			return readProxyV2Header(reader)
+		}
+	}
+
+	if required {
+		return nil, fmt.Errorf("header missing")
+	}
+
+	return nil, nil
+}
+
+//readProxyV1Header reads a header such as PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n.
+func readProxyV1Header(reader *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < 107 {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		line = append(line, b)
+		if bytes.HasSuffix(line, []byte("\r\n")) {
+			break
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, fmt.Errorf("version 1 header too long")
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(line), "\r\n"), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("invalid version 1 header %q", line)
+	}
+
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.Atoi(fields[4])
+	if ip == nil || err != nil || port < 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid version 1 header %q", line)
+	}
+
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+//readProxyV2Header reads a binary header, following its signature.
+func readProxyV2Header(reader *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, len(proxyV2Signature)+4)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+
+	versionCommand, family := header[12], header[13]
+	addresses := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(reader, addresses); err != nil {
+		return nil, err
+	}
+
+	if versionCommand>>4 != 2 {
+		return nil, fmt.Errorf("unsupported version %v", versionCommand>>4)
+	}
+
+	switch versionCommand & 0x0F {
+	case 0:
+		//LOCAL, a health check of the load balancer
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("unsupported command %v", versionCommand&0x0F)
+	}
+
+	switch family >> 4 {
+	case 1:
+		if len(addresses) < 12 {
+			return nil, fmt.Errorf("IPv4 addresses truncated")
+		}
+		return &net.TCPAddr{IP: net.IP(addresses[0:4]), Port: int(binary.BigEndian.Uint16(addresses[8:]))}, nil
+	case 2:
+		if len(addresses) < 36 {
+			return nil, fmt.Errorf("IPv6 addresses truncated")
+		}
+		return &net.TCPAddr{IP: net.IP(addresses[0:16]), Port: int(binary.BigEndian.Uint16(addresses[32:]))}, nil
+	}
+
+	//unspecified and unix addresses
+	return nil, nil
+}
@@ -0,0 +1,159 @@
+package quickfix
+
+import (
+	"bufio"
+	"context"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseProxyProtocolPolicy(t *testing.T) {
+	for setting, expected := range map[string]proxyProtocolPolicy{"Off": proxyProtocolOff, "Optional": proxyProtocolOptional, "Required": proxyProtocolRequired} {
+		if policy, err := parseProxyProtocolPolicy(setting); err != nil || policy != expected {
+			t.Errorf("Expected %v for %v, got %v %v", expected, setting, policy, err)
+		}
+	}
+
+	if _, err := parseProxyProtocolPolicy("Y"); err == nil {
+		t.Error("Expected error for Y")
+	}
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	v2 := func(command, family byte, addresses ...byte) string {
+		return string(proxyV2Signature) + string([]byte{0x20 | command, family, 0, byte(len(addresses))}) + string(addresses)
+	}
+
+	ipv6 := make([]byte, 36)
+	copy(ipv6, net.ParseIP("2001:db8::7"))
+	ipv6[32], ipv6[33] = 0x1F, 0x90
+
+	var tests = []struct {
+		input      string
+		required   bool
+		remoteAddr string
+		valid      bool
+	}{
+		{"PROXY TCP4 203.0.113.7 198.51.100.1 56324 5001\r\n8=FIX", true, "203.0.113.7:56324", true},
+		{"PROXY TCP6 2001:db8::7 2001:db8::1 8080 5001\r\n8=FIX", true, "[2001:db8::7]:8080", true},
+		{"PROXY UNKNOWN\r\n8=FIX", true, "", true},
+		{"PROXY TCP4 203.0.113.7 198.51.100.1 port 5001\r\n8=FIX", false, "", false},
+		{"PROXY TCP4 " + strings.Repeat("1", 100) + "\r\n8=FIX", false, "", false},
+		{v2(1, 0x11, 203, 0, 113, 7, 198, 51, 100, 1, 0xDC, 0x04, 0x13, 0x89) + "8=FIX", true, "203.0.113.7:56324", true},
+		{v2(1, 0x21, ipv6...) + "8=FIX", true, "[2001:db8::7]:8080", true},
+		{v2(0, 0x00) + "8=FIX", true, "", true},
+		{v2(1, 0x11, 203, 0, 113) + "8=FIX", true, "", false},
+		{v2(2, 0x11) + "8=FIX", true, "", false},
+		{"8=FIX", false, "", true},
+		{"8=FIX", true, "", false},
+	}
+
+	for _, test := range tests {
+		reader := bufio.NewReader(strings.NewReader(test.input))
+		addr, err := readProxyHeader(reader, test.required)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("%q: expected valid %v, got %v", test.input, test.valid, err)
+			continue
+		}
+
+		if !test.valid {
+			continue
+		}
+
+		remoteAddr := ""
+		if addr != nil {
+			remoteAddr = addr.String()
+		}
+
+		if remoteAddr != test.remoteAddr {
+			t.Errorf("%q: expected remote address %q, got %q", test.input, test.remoteAddr, remoteAddr)
+		}
+
+		if rest, _ := ioutil.ReadAll(reader); string(rest) != "8=FIX" {
+			t.Errorf("%q: expected message following the header, got %q", test.input, rest)
+		}
+	}
+}
+
+//proxyAuthenticator reports the remote address of each logon authenticated.
+type proxyAuthenticator struct {
+	shutdownClient
+	remoteAddrs chan string
+}
+
+func (a *proxyAuthenticator) AuthenticateLogon(sessionID SessionID, logon Message, remoteAddr net.Addr) error {
+	a.remoteAddrs <- remoteAddr.String()
+	return nil
+}
+
+func TestProxyProtocol_Logon(t *testing.T) {
+	port := strconv.Itoa(freePort(t))
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, port)
+	acceptorSettings.GlobalSettings().Set(config.SocketProxyProtocol, "Required")
+	templateSettings := newTestAcceptorSessionSettings("PROXIED_*")
+	templateSettings.Set(config.AcceptorTemplate, "Y")
+	if _, err := acceptorSettings.AddSession(templateSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	acceptorApp := &proxyAuthenticator{shutdownClient: shutdownClient{states: make(chan SessionState, 20)}, remoteAddrs: make(chan string, 1)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	initiatorSettings := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "PROXIED_CLIENT")
+	sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+	sessionSettings.Set(config.HeartBtInt, "30")
+	sessionSettings.Set(config.SocketConnectHost, "127.0.0.1")
+	sessionSettings.Set(config.SocketConnectPort, port)
+	if _, err := initiatorSettings.AddSession(sessionSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//a load balancer in front of the acceptor
+	initiator.SetDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := conn.Write([]byte("PROXY TCP4 203.0.113.7 198.51.100.1 56324 " + port + "\r\n")); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	})
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer initiator.Stop()
+
+	awaitState(t, initiatorApp.states, StateLoggedOn)
+
+	if remoteAddr := <-acceptorApp.remoteAddrs; remoteAddr != "203.0.113.7:56324" {
+		t.Errorf("Expected logon authenticated from the client address, got %v", remoteAddr)
+	}
+}
@@ -0,0 +1,104 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"time"
+)
+
+//poisonMessagePolicy determines how a session responds to quarantining a message.
+type poisonMessagePolicy int
+
+const (
+	//poisonMessageReject sends a Reject for the quarantined message.
+	poisonMessageReject poisonMessagePolicy = iota
+
+	//poisonMessageSkip skips the quarantined message without a Reject.
+	poisonMessageSkip
+)
+
+//parsePoisonMessagePolicy maps the PoisonMessagePolicy setting to a poisonMessagePolicy.
+func parsePoisonMessagePolicy(setting string) (poisonMessagePolicy, error) {
+	switch setting {
+	case "Reject":
+		return poisonMessageReject, nil
+	case "Skip":
+		return poisonMessageSkip, nil
+	}
+
+	return poisonMessageReject, fmt.Errorf("invalid PoisonMessagePolicy %v, expected Reject or Skip", setting)
+}
+
+//DeadLetter is an inbound message quarantined because it could not be processed.
+type DeadLetter struct {
+	SeqNum  int
+	Message []byte
+	Reason  string
+	Time    time.Time
+}
+
+//DeadLetterStore may be implemented by a MessageStore to keep messages quarantined by the session.
+type DeadLetterStore interface {
+	SaveDeadLetter(deadLetter DeadLetter) error
+	DeadLetters() ([]DeadLetter, error)
+}
+
+//checkPoisonMessage counts a failure to parse msgBytes, quarantining the message once it has failed PoisonMessageThreshold times.
+//Only the next message expected is counted, a message that cannot be parsed otherwise stalls the session as each resend of it fails again.
+func (s *Session) checkPoisonMessage(msgBytes []byte, reason error) {
+	if s.poisonMessageThreshold == 0 {
+		return
+	}
+
+	header := parseHeader(msgBytes)
+	seqNum := new(fix.IntValue)
+	if header.GetField(tag.MsgSeqNum, seqNum) != nil || seqNum.Value != s.store.NextTargetMsgSeqNum() {
+		return
+	}
+
+	if seqNum.Value != s.poisonSeqNum {
+		s.poisonSeqNum, s.poisonFailures = seqNum.Value, 0
+	}
+
+	s.poisonFailures++
+	if s.poisonFailures < s.poisonMessageThreshold {
+		return
+	}
+
+	s.quarantine(header, msgBytes, reason)
+
+	//messages received during a resend resume once the gap is passed
+	if stashed, ok := s.messageStash[s.store.NextTargetMsgSeqNum()]; ok {
+		s.transition(s.currentState.FixMsgIn(s, stashed), nil)
+	}
+}
+
+//quarantine saves msgBytes as a DeadLetter, then skips it according to the PoisonMessagePolicy.
+func (s *Session) quarantine(header FieldMap, msgBytes []byte, reason error) {
+	deadLetter := DeadLetter{SeqNum: s.poisonSeqNum, Message: msgBytes, Reason: reason.Error(), Time: s.now()}
+	logEventf(s.log, LogLevelError, LogCategoryValidation, "Quarantining message %d after %d failures: %v", deadLetter.SeqNum, s.poisonFailures, reason)
+	s.poisonSeqNum, s.poisonFailures = 0, 0
+
+	if store, ok := s.store.(DeadLetterStore); ok {
+		if err := store.SaveDeadLetter(deadLetter); err != nil {
+			s.storeMetrics.recordError(err, s.now())
+			logEventf(s.log, LogLevelError, LogCategoryStore, "Unable to save quarantined message %d: %v", deadLetter.SeqNum, err)
+		}
+	}
+
+	if s.poisonMessagePolicy == poisonMessageReject {
+		var body, trailer fieldMap
+		body.init(normalFieldOrder)
+		trailer.init(trailerFieldOrder)
+		msg := Message{Header: header, Body: body, Trailer: trailer}
+
+		s.doReject(msg, NewMessageRejectError(reason.Error(), rejectReasonOther, nil))
+	}
+
+	s.store.IncrNextTargetMsgSeqNum()
+
+	if listener, ok := s.application.(QuarantineListener); ok {
+		listener.OnQuarantine(s.sessionID, deadLetter)
+	}
+}
@@ -0,0 +1,89 @@
+package quickfix
+
+import (
+	"errors"
+	"testing"
+)
+
+//quarantineApp records the messages quarantined.
+type quarantineApp struct {
+	TestClient
+	deadLetters []DeadLetter
+}
+
+func (a *quarantineApp) OnQuarantine(sessionID SessionID, deadLetter DeadLetter) {
+	a.deadLetters = append(a.deadLetters, deadLetter)
+}
+
+func TestParsePoisonMessagePolicy(t *testing.T) {
+	var testCases = []struct {
+		setting   string
+		expected  poisonMessagePolicy
+		expectErr bool
+	}{
+		{"Reject", poisonMessageReject, false},
+		{"Skip", poisonMessageSkip, false},
+		{"Drop", poisonMessageReject, true},
+	}
+
+	for _, tc := range testCases {
+		policy, err := parsePoisonMessagePolicy(tc.setting)
+		if (err != nil) != tc.expectErr {
+			t.Errorf("%v: unexpected error %v", tc.setting, err)
+		}
+
+		if policy != tc.expected {
+			t.Errorf("%v: expected %v got %v", tc.setting, tc.expected, policy)
+		}
+	}
+}
+
+func TestSession_QuarantinePoisonMessage(t *testing.T) {
+	var testCases = []struct {
+		policy           poisonMessagePolicy
+		expectedMsgTypes []string
+	}{
+		{poisonMessageReject, []string{"3"}},
+		{poisonMessageSkip, nil},
+	}
+
+	poison := rawMessage("FIX.4.2", "35=D\00134=1\00149=ISLD\00156=TW\00155\001")
+	if _, err := parseMessage(poison); err == nil {
+		t.Fatal("Expected poison message to fail parsing")
+	}
+
+	parseErr := errors.New("invalid field")
+	for _, tc := range testCases {
+		app := new(quarantineApp)
+		session := newTestAdminSession(app)
+		session.currentState = inSession{}
+		session.poisonMessageThreshold = 2
+		session.poisonMessagePolicy = tc.policy
+
+		//a message other than the next expected is not counted
+		session.checkPoisonMessage(rawMessage("FIX.4.2", "35=D\00134=5\00149=ISLD\00156=TW\00155\001"), parseErr)
+		session.checkPoisonMessage(poison, parseErr)
+		if session.store.NextTargetMsgSeqNum() != 1 || len(app.deadLetters) != 0 {
+			t.Fatalf("Expected message not quarantined before threshold")
+		}
+
+		session.checkPoisonMessage(poison, parseErr)
+		if session.store.NextTargetMsgSeqNum() != 2 {
+			t.Errorf("Expected NextTargetMsgSeqNum 2 got %v", session.store.NextTargetMsgSeqNum())
+		}
+
+		if len(app.deadLetters) != 1 || app.deadLetters[0].SeqNum != 1 || string(app.deadLetters[0].Message) != string(poison) {
+			t.Fatalf("Unexpected dead letters %v", app.deadLetters)
+		}
+
+		deadLetters, _ := session.store.(DeadLetterStore).DeadLetters()
+		if len(deadLetters) != 1 || deadLetters[0].Reason != parseErr.Error() {
+			t.Errorf("Expected dead letter saved to store, got %v", deadLetters)
+		}
+
+		msgTypes, _ := sentMsgTypes(t, session)
+		if len(msgTypes) != len(tc.expectedMsgTypes) || (len(msgTypes) > 0 && msgTypes[0] != tc.expectedMsgTypes[0]) {
+			t.Errorf("Expected sent %v got %v", tc.expectedMsgTypes, msgTypes)
+		}
+	}
+}
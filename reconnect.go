@@ -0,0 +1,127 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"math"
+	"math/rand"
+	"time"
+)
+
+//defaultReconnectInterval is the delay before a scheduled session reconnects after a disconnect.
+const defaultReconnectInterval = 30 * time.Second
+
+//reconnectPolicy determines the delay before each attempt to reconnect an initiated session.
+type reconnectPolicy struct {
+	//interval is the delay after a disconnect, and after the first failed attempt.
+	interval time.Duration
+
+	//multiplier grows the delay after each further consecutive failed attempt, up to maxInterval if not zero.
+	multiplier  float64
+	maxInterval time.Duration
+
+	//jitter randomizes each delay by up to the fraction jitter either way.
+	jitter float64
+
+	//maxAttempts is the number of consecutive failed attempts after which the session is no longer reconnected, zero to reconnect indefinitely.
+	maxAttempts int
+}
+
+//newReconnectPolicy returns the reconnect policy configured in settings.
+func newReconnectPolicy(settings *SessionSettings) (reconnectPolicy, error) {
+	p := reconnectPolicy{interval: defaultReconnectInterval, multiplier: 1}
+
+	if settings.HasSetting(config.ReconnectInterval) {
+		interval, err := settings.IntSetting(config.ReconnectInterval)
+		if err != nil {
+			return p, err
+		}
+
+		if interval <= 0 {
+			return p, fmt.Errorf("ReconnectInterval must be a positive number of seconds")
+		}
+		p.interval = time.Duration(interval) * time.Second
+	}
+
+	if settings.HasSetting(config.ReconnectBackoffMultiplier) {
+		multiplier, err := settings.FloatSetting(config.ReconnectBackoffMultiplier)
+		if err != nil {
+			return p, err
+		}
+
+		if multiplier < 1 {
+			return p, fmt.Errorf("ReconnectBackoffMultiplier must be at least 1")
+		}
+		p.multiplier = multiplier
+	}
+
+	if settings.HasSetting(config.MaxReconnectInterval) {
+		maxInterval, err := settings.IntSetting(config.MaxReconnectInterval)
+		if err != nil {
+			return p, err
+		}
+
+		if maxInterval <= 0 {
+			return p, fmt.Errorf("MaxReconnectInterval must be a positive number of seconds")
+		}
+		p.maxInterval = time.Duration(maxInterval) * time.Second
+	}
+
+	if settings.HasSetting(config.ReconnectJitter) {
+		jitter, err := settings.FloatSetting(config.ReconnectJitter)
+		if err != nil {
+			return p, err
+		}
+
+		if jitter < 0 || jitter > 1 {
+			return p, fmt.Errorf("ReconnectJitter must be between 0 and 1")
+		}
+		p.jitter = jitter
+	}
+
+	if settings.HasSetting(config.MaxReconnectAttempts) {
+		maxAttempts, err := settings.IntSetting(config.MaxReconnectAttempts)
+		if err != nil {
+			return p, err
+		}
+
+		if maxAttempts <= 0 {
+			return p, fmt.Errorf("MaxReconnectAttempts must be a positive number")
+		}
+		p.maxAttempts = maxAttempts
+	}
+
+	return p, nil
+}
+
+//delay returns the delay before the next attempt after the given number of consecutive failed attempts.
+func (p reconnectPolicy) delay(failures int) time.Duration {
+	d := float64(p.interval)
+	if failures > 1 {
+		d *= math.Pow(p.multiplier, float64(failures-1))
+	}
+
+	if p.maxInterval > 0 && d > float64(p.maxInterval) {
+		d = float64(p.maxInterval)
+	}
+
+	if p.jitter > 0 {
+		d += d * p.jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(d)
+}
+
+//exhausted returns true if no attempt follows the given number of consecutive failed attempts.
+func (p reconnectPolicy) exhausted(failures int) bool {
+	return p.maxAttempts > 0 && failures >= p.maxAttempts
+}
+
+//onReconnectAbandoned reports that the session is no longer reconnected after attempts consecutive failures, the last with err.
+func (s *Session) onReconnectAbandoned(attempts int, err error) {
+	logEventf(s.log, LogLevelError, LogCategoryTransport, "Abandoned reconnecting after %v attempts: %v", attempts, err)
+
+	if listener, ok := s.application.(ReconnectListener); ok {
+		listener.OnReconnectAbandoned(s.sessionID, attempts, err)
+	}
+}
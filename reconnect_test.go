@@ -0,0 +1,88 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"testing"
+	"time"
+)
+
+func TestReconnectPolicy_Delay(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set(config.ReconnectInterval, "1")
+	settings.Set(config.ReconnectBackoffMultiplier, "2")
+	settings.Set(config.MaxReconnectInterval, "5")
+	settings.Set(config.MaxReconnectAttempts, "4")
+
+	policy, err := newReconnectPolicy(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		failures  int
+		expected  time.Duration
+		exhausted bool
+	}{
+		{0, time.Second, false},
+		{1, time.Second, false},
+		{2, 2 * time.Second, false},
+		{3, 4 * time.Second, false},
+		{4, 5 * time.Second, true},
+	}
+
+	for _, test := range tests {
+		if delay := policy.delay(test.failures); delay != test.expected {
+			t.Errorf("Expected delay %v after %v failures got %v", test.expected, test.failures, delay)
+		}
+
+		if exhausted := policy.exhausted(test.failures); exhausted != test.exhausted {
+			t.Errorf("Expected exhausted %v after %v failures got %v", test.exhausted, test.failures, exhausted)
+		}
+	}
+}
+
+func TestReconnectPolicy_Jitter(t *testing.T) {
+	policy := reconnectPolicy{interval: 10 * time.Second, multiplier: 1, jitter: 0.2}
+
+	for n := 0; n < 100; n++ {
+		if delay := policy.delay(1); delay < 8*time.Second || delay > 12*time.Second {
+			t.Fatalf("Expected delay within 20%% of 10s got %v", delay)
+		}
+	}
+}
+
+func TestReconnectPolicy_Defaults(t *testing.T) {
+	policy, err := newReconnectPolicy(NewSessionSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if delay := policy.delay(10); delay != defaultReconnectInterval {
+		t.Errorf("Expected fixed default interval got %v", delay)
+	}
+
+	if policy.exhausted(1000) {
+		t.Error("Expected reconnecting indefinitely by default")
+	}
+}
+
+func TestReconnectPolicy_InvalidSettings(t *testing.T) {
+	var tests = []struct {
+		setting, value string
+	}{
+		{config.ReconnectInterval, "0"},
+		{config.ReconnectBackoffMultiplier, "0.5"},
+		{config.MaxReconnectInterval, "-1"},
+		{config.ReconnectJitter, "1.5"},
+		{config.MaxReconnectAttempts, "0"},
+	}
+
+	for _, test := range tests {
+		settings := NewSessionSettings()
+		settings.Set(test.setting, test.value)
+
+		if _, err := newReconnectPolicy(settings); err == nil {
+			t.Errorf("Expected error for %v=%v", test.setting, test.value)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package quickfix
+
+//RecoveryState is the runtime state of a session persisted with its sequence numbers, to resume the session after the process restarts.
+type RecoveryState struct {
+	//LoggedOn is true while the session is logged on. Found set on startup, the process stopped without logging out.
+	LoggedOn bool
+
+	//ResendBeginSeqNo and ResendEndSeqNo are the range of a ResendRequest not yet satisfied, zero if none is in progress.
+	ResendBeginSeqNo, ResendEndSeqNo int
+}
+
+//RecoveryStore may be implemented by a MessageStore to persist the RecoveryState of the session.
+//SaveRecoveryState must be durable together with the sequence numbers of the store.
+type RecoveryStore interface {
+	SaveRecoveryState(state RecoveryState) error
+	RecoveryState() (RecoveryState, error)
+}
+
+//recover loads the RecoveryState of the session, if persisted by the store.
+func (s *Session) recover() error {
+	store, ok := s.store.(RecoveryStore)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	if s.recovery, err = store.RecoveryState(); err != nil {
+		return err
+	}
+
+	if s.recovery.LoggedOn {
+		logEventf(s.log, LogLevelWarn, LogCategorySession, "Recovering session after unclean shutdown, next sender %d target %d", s.store.NextSenderMsgSeqNum(), s.store.NextTargetMsgSeqNum())
+	}
+
+	if s.resendPending() {
+		logEventf(s.log, LogLevelWarn, LogCategorySession, "Recovering incomplete resend FROM: %d TO: %d", s.recovery.ResendBeginSeqNo, s.recovery.ResendEndSeqNo)
+	}
+
+	return nil
+}
+
+//saveRecoveryState persists state if it differs from the persisted state.
+func (s *Session) saveRecoveryState(state RecoveryState) {
+	if state == s.recovery {
+		return
+	}
+	s.recovery = state
+
+	if store, ok := s.store.(RecoveryStore); ok {
+		if err := store.SaveRecoveryState(state); err != nil {
+			s.storeMetrics.recordError(err, s.now())
+			logEventf(s.log, LogLevelError, LogCategoryStore, "Unable to save recovery state: %v", err)
+		}
+	}
+}
+
+//resendRequested records the range of a ResendRequest sent to the counterparty.
+func (s *Session) resendRequested(beginSeqNo, endSeqNo int) {
+	state := s.recovery
+	state.ResendBeginSeqNo, state.ResendEndSeqNo = beginSeqNo, endSeqNo
+	s.saveRecoveryState(state)
+}
+
+//resendPending returns true if a ResendRequest has not been satisfied.
+func (s *Session) resendPending() bool {
+	return s.recovery.ResendBeginSeqNo != 0
+}
+
+//updateRecoveryState persists the state of the session after a transition to to.
+func (s *Session) updateRecoveryState(to SessionState) {
+	state := s.recovery
+
+	switch to {
+	case StateLoggedOn, StateAwaitingResend, StateTestRequestSent:
+		state.LoggedOn = true
+	case StateDisconnected:
+		state.LoggedOn = false
+	}
+
+	if s.resendPending() {
+		//complete, or abandoned by a sequence reset
+		if nextTarget := s.store.NextTargetMsgSeqNum(); nextTarget > s.recovery.ResendEndSeqNo || nextTarget < s.recovery.ResendBeginSeqNo {
+			state.ResendBeginSeqNo, state.ResendEndSeqNo = 0, 0
+		}
+	}
+
+	s.saveRecoveryState(state)
+}
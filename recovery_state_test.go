@@ -0,0 +1,64 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"testing"
+	"time"
+)
+
+func TestSession_RecoverIncompleteResend(t *testing.T) {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	store.SetNextSenderMsgSeqNum(3)
+	store.SetNextTargetMsgSeqNum(5)
+
+	session := &Session{
+		sessionID:     SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"},
+		header:        newHeaderTemplate(SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"}),
+		store:         store,
+		application:   &TestClient{},
+		messageOut:    make(chan []byte, 10),
+		log:           nullLog{},
+		stateTimer:    eventTimer{Task: func() {}},
+		initiateLogon: true,
+	}
+
+	sendingTime := time.Now().UTC().Format("20060102-15:04:05")
+	logon, _ := parseMessage(rawMessage(fix.BeginString_FIX42, "35=A\00134=12\00149=ISLD\00152="+sendingTime+"\00156=TW\00198=0\001108=30\001"))
+	session.transition(logonState{}.FixMsgIn(session, *logon), nil)
+
+	if _, ok := session.currentState.(resendState); !ok {
+		t.Fatalf("Expected resendState after logon with gap, got %T", session.currentState)
+	}
+
+	if store.NextTargetMsgSeqNum() != 5 {
+		t.Errorf("Expected logon not to be counted before the gap is filled, next target %v", store.NextTargetMsgSeqNum())
+	}
+
+	expected := RecoveryState{LoggedOn: true, ResendBeginSeqNo: 5, ResendEndSeqNo: 12}
+	if persisted, _ := store.(RecoveryStore).RecoveryState(); persisted != expected {
+		t.Errorf("Expected persisted %+v, got %+v", expected, persisted)
+	}
+
+	//process restarts
+	restarted := &Session{store: store, header: newHeaderTemplate(session.sessionID), application: &TestClient{}, log: nullLog{}, messageOut: make(chan []byte, 10), stateTimer: eventTimer{Task: func() {}}}
+	if err := restarted.recover(); err != nil {
+		t.Fatal(err)
+	}
+
+	if restarted.recovery != expected || !restarted.resendPending() {
+		t.Errorf("Expected recovered %+v, got %+v", expected, restarted.recovery)
+	}
+
+	store.SetNextTargetMsgSeqNum(13)
+	restarted.transition(inSession{}, nil)
+
+	expected = RecoveryState{LoggedOn: true}
+	if persisted, _ := store.(RecoveryStore).RecoveryState(); persisted != expected {
+		t.Errorf("Expected resend complete %+v, got %+v", expected, persisted)
+	}
+
+	restarted.transition(latentState{}, nil)
+	if persisted, _ := store.(RecoveryStore).RecoveryState(); persisted.LoggedOn {
+		t.Error("Expected LoggedOn cleared on disconnect")
+	}
+}
@@ -0,0 +1,392 @@
+//Package redisstore provides a QuickFIX/Go MessageStore persisting sessions to Redis or Valkey.
+//Durability is delegated to the persistence and replication of the server.
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//StoreType is the MessageStoreType of the store, registered with quickfix.RegisterStoreFactory when the package is imported.
+const StoreType = "redis"
+
+func init() {
+	quickfix.RegisterStoreFactory(StoreType, NewStoreFactory)
+}
+
+const (
+	defaultKeyPrefix = "quickfix"
+
+	//getMessagesBatch is the number of messages read per round trip by GetMessages
+	getMessagesBatch = 100
+
+	//healthTimeout limits the ping of the server by Health
+	healthTimeout = 5 * time.Second
+)
+
+//Fields of the session hash.
+const (
+	fieldSender       = "sender"
+	fieldTarget       = "target"
+	fieldCreationTime = "creation_time"
+	fieldLoggedOn     = "logged_on"
+	fieldResendBegin  = "resend_begin"
+	fieldResendEnd    = "resend_end"
+)
+
+//setSeqNumScript sets a sequence number of the session hash to ARGV[3] only if it is ARGV[2], the value last read by this store.
+//A different value means another engine is writing the session, the conflict fails the write rather than corrupting the sequence.
+var setSeqNumScript = redis.NewScript(`
+local current = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+if current ~= tonumber(ARGV[2]) then
+	return redis.error_reply('sequence number ' .. ARGV[1] .. ' is ' .. current .. ', expected ' .. ARGV[2])
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+return ARGV[3]
+`)
+
+type storeFactory struct {
+	settings *quickfix.Settings
+
+	//clients are shared by the sessions using the same addresses
+	clientLock sync.Mutex
+	clients    map[string]redis.UniversalClient
+}
+
+//NewStoreFactory returns a MessageStoreFactory that creates MessageStores persisting sessions to Redis.
+//The comma separated addresses of the server, or cluster with RedisStoreCluster=Y, are configured via RedisStoreAddrs.
+//Keys of a session are prefixed with RedisStoreKeyPrefix, to share a server between engine instances.
+func NewStoreFactory(settings *quickfix.Settings) (quickfix.MessageStoreFactory, error) {
+	for _, sessionSettings := range settings.SessionSettings() {
+		if _, err := clientOptionsFor(sessionSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	return &storeFactory{settings: settings, clients: make(map[string]redis.UniversalClient)}, nil
+}
+
+func (f *storeFactory) Create(sessionID quickfix.SessionID) (quickfix.MessageStore, error) {
+	settings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("redis store not defined for %v", sessionID)
+	}
+
+	options, err := clientOptionsFor(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := defaultKeyPrefix
+	if settings.HasSetting(config.RedisStoreKeyPrefix) {
+		prefix, _ = settings.Setting(config.RedisStoreKeyPrefix)
+	}
+
+	return newStore(sessionID, f.client(options), prefix)
+}
+
+//client returns the client for options, creating it if not already created.
+func (f *storeFactory) client(options *redis.UniversalOptions) redis.UniversalClient {
+	f.clientLock.Lock()
+	defer f.clientLock.Unlock()
+
+	key := fmt.Sprintf("%v %v %v", options.Addrs, options.DB, options.IsClusterMode)
+	if client, ok := f.clients[key]; ok {
+		return client
+	}
+
+	client := redis.NewUniversalClient(options)
+	f.clients[key] = client
+	return client
+}
+
+//clientOptionsFor parses RedisStoreAddrs, RedisStoreCluster, RedisStorePassword, and RedisStoreDB.
+func clientOptionsFor(settings *quickfix.SessionSettings) (*redis.UniversalOptions, error) {
+	addrs, err := settings.Setting(config.RedisStoreAddrs)
+	if err != nil {
+		return nil, fmt.Errorf("missing configuration: %v", config.RedisStoreAddrs)
+	}
+
+	options := new(redis.UniversalOptions)
+	for _, addr := range strings.Split(addrs, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			options.Addrs = append(options.Addrs, addr)
+		}
+	}
+
+	if len(options.Addrs) == 0 {
+		return nil, fmt.Errorf("invalid RedisStoreAddrs %q, expected host:port", addrs)
+	}
+
+	if settings.HasSetting(config.RedisStoreCluster) {
+		if options.IsClusterMode, err = settings.BoolSetting(config.RedisStoreCluster); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.HasSetting(config.RedisStorePassword) {
+		options.Password, _ = settings.Setting(config.RedisStorePassword)
+	}
+
+	if settings.HasSetting(config.RedisStoreDB) {
+		if options.DB, err = settings.IntSetting(config.RedisStoreDB); err != nil {
+			return nil, err
+		}
+
+		if options.IsClusterMode && options.DB != 0 {
+			return nil, fmt.Errorf("RedisStoreDB is not supported with RedisStoreCluster")
+		}
+	}
+
+	return options, nil
+}
+
+//sessionKeys are the keys of a session, hash tagged by session so all are assigned the same slot in a cluster.
+type sessionKeys struct {
+	session, messages string
+}
+
+func keysFor(prefix string, sessionID quickfix.SessionID) sessionKeys {
+	tag := fmt.Sprintf("%v:{%v}", prefix, sessionID)
+	return sessionKeys{session: tag + ":session", messages: tag + ":messages"}
+}
+
+//store is a MessageStore persisting a session as a hash of its sequence numbers and RecoveryState, and a hash of its messages by seqnum.
+type store struct {
+	client redis.UniversalClient
+	keys   sessionKeys
+
+	//lock guards the fields cached from the session hash, held across each checked update so that the value expected is the value last set
+	lock                             sync.RWMutex
+	senderMsgSeqNum, targetMsgSeqNum int
+	creationTime                     time.Time
+	recoveryState                    quickfix.RecoveryState
+
+	//writeErr keeps the error of the first failed command, such as a seqnum update refused because the hash no longer holds the value cached
+	writeErr quickfix.ErrorLatch
+}
+
+func newStore(sessionID quickfix.SessionID, client redis.UniversalClient, prefix string) (*store, error) {
+	s := &store{client: client, keys: keysFor(prefix, sessionID)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+//load reads the session hash, creating it if the session is new.
+func (s *store) load() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ctx := context.Background()
+	values, err := s.client.HGetAll(ctx, s.keys.session).Result()
+	if err != nil {
+		return err
+	}
+
+	if len(values) == 0 {
+		s.creationTime = time.Now().UTC()
+		s.senderMsgSeqNum, s.targetMsgSeqNum, s.recoveryState = 0, 0, quickfix.RecoveryState{}
+		return s.client.HSetNX(ctx, s.keys.session, fieldCreationTime, s.creationTime.UnixNano()).Err()
+	}
+
+	ints := make(map[string]int64)
+	for _, field := range []string{fieldSender, fieldTarget, fieldCreationTime, fieldLoggedOn, fieldResendBegin, fieldResendEnd} {
+		if value, ok := values[field]; ok {
+			if ints[field], err = strconv.ParseInt(value, 10, 64); err != nil {
+				return fmt.Errorf("invalid %v of %v: %v", field, s.keys.session, err)
+			}
+		}
+	}
+
+	s.senderMsgSeqNum, s.targetMsgSeqNum = int(ints[fieldSender]), int(ints[fieldTarget])
+	s.creationTime = time.Unix(0, ints[fieldCreationTime]).UTC()
+	s.recoveryState = quickfix.RecoveryState{LoggedOn: ints[fieldLoggedOn] == 1, ResendBeginSeqNo: int(ints[fieldResendBegin]), ResendEndSeqNo: int(ints[fieldResendEnd])}
+	return nil
+}
+
+//setSeqNum sets the seqnum field from current to next, with lock held.
+func (s *store) setSeqNum(field string, current, next int) {
+	s.writeErr.Set(setSeqNumScript.Run(context.Background(), s.client, []string{s.keys.session}, field, current, next).Err())
+}
+
+func (s *store) NextSenderMsgSeqNum() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.senderMsgSeqNum + 1
+}
+
+func (s *store) NextTargetMsgSeqNum() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.targetMsgSeqNum + 1
+}
+
+func (s *store) IncrNextSenderMsgSeqNum() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setSeqNum(fieldSender, s.senderMsgSeqNum, s.senderMsgSeqNum+1)
+	s.senderMsgSeqNum++
+}
+
+func (s *store) IncrNextTargetMsgSeqNum() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setSeqNum(fieldTarget, s.targetMsgSeqNum, s.targetMsgSeqNum+1)
+	s.targetMsgSeqNum++
+}
+
+func (s *store) SetNextSenderMsgSeqNum(next int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setSeqNum(fieldSender, s.senderMsgSeqNum, next-1)
+	s.senderMsgSeqNum = next - 1
+}
+
+func (s *store) SetNextTargetMsgSeqNum(next int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.setSeqNum(fieldTarget, s.targetMsgSeqNum, next-1)
+	s.targetMsgSeqNum = next - 1
+}
+
+func (s *store) CreationTime() time.Time {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.creationTime
+}
+
+func (s *store) SaveRecoveryState(state quickfix.RecoveryState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	loggedOn := 0
+	if state.LoggedOn {
+		loggedOn = 1
+	}
+
+	err := s.client.HSet(context.Background(), s.keys.session, fieldLoggedOn, loggedOn, fieldResendBegin, state.ResendBeginSeqNo, fieldResendEnd, state.ResendEndSeqNo).Err()
+	if err != nil {
+		return err
+	}
+
+	s.recoveryState = state
+	return nil
+}
+
+func (s *store) RecoveryState() (quickfix.RecoveryState, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.recoveryState, nil
+}
+
+//Reset deletes the messages of the session and resets the sequence numbers, in a single transaction, starting a new session.
+func (s *store) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.creationTime = time.Now().UTC()
+	s.senderMsgSeqNum, s.targetMsgSeqNum = 0, 0
+	s.recoveryState.ResendBeginSeqNo, s.recoveryState.ResendEndSeqNo = 0, 0
+
+	_, err := s.client.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		pipe.Del(context.Background(), s.keys.messages)
+		pipe.HSet(context.Background(), s.keys.session, fieldSender, 0, fieldTarget, 0, fieldCreationTime, s.creationTime.UnixNano(), fieldResendBegin, 0, fieldResendEnd, 0)
+		return nil
+	})
+	s.writeErr.Set(err)
+}
+
+//Refresh reloads the session hash, clearing any write error.
+func (s *store) Refresh() {
+	s.writeErr.Clear()
+
+	s.writeErr.Set(s.load())
+}
+
+//Health returns the first write error since the store was refreshed, or the failure to reach the server.
+func (s *store) Health() error {
+	if err := s.writeErr.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+	return s.client.Ping(ctx).Err()
+}
+
+//TrySaveMessage sets msg as the message of seqNum.
+func (s *store) TrySaveMessage(seqNum int, msg []byte) error {
+	if err := s.writeErr.Err(); err != nil {
+		return err
+	}
+
+	return s.client.HSet(context.Background(), s.keys.messages, strconv.Itoa(seqNum), msg).Err()
+}
+
+//SaveMessage sets msg as TrySaveMessage, a failed command kept as the error reported by Health until Refresh.
+func (s *store) SaveMessage(seqNum int, msg []byte) {
+	s.writeErr.Set(s.TrySaveMessage(seqNum, msg))
+}
+
+//Compact deletes the messages with seqnums below seqNum.
+func (s *store) Compact(seqNum int) error {
+	ctx := context.Background()
+	fields, err := s.client.HKeys(ctx, s.keys.messages).Result()
+	if err != nil {
+		return err
+	}
+
+	var compacted []string
+	for _, field := range fields {
+		if saved, err := strconv.Atoi(field); err == nil && saved < seqNum {
+			compacted = append(compacted, field)
+		}
+	}
+
+	if len(compacted) == 0 {
+		return nil
+	}
+
+	return s.client.HDel(ctx, s.keys.messages, compacted...).Err()
+}
+
+//GetMessages returns the messages saved with seqnums from beginSeqNum to endSeqNum, in order.
+//Messages are read getMessagesBatch at a time.
+func (s *store) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
+	msgs := make(chan []byte)
+
+	go func() {
+		defer close(msgs)
+
+		ctx := context.Background()
+		for begin := beginSeqNum; begin <= endSeqNum; begin += getMessagesBatch {
+			fields := make([]string, 0, getMessagesBatch)
+			for seqNum := begin; seqNum <= endSeqNum && seqNum < begin+getMessagesBatch; seqNum++ {
+				fields = append(fields, strconv.Itoa(seqNum))
+			}
+
+			values, err := s.client.HMGet(ctx, s.keys.messages, fields...).Result()
+			if err != nil {
+				return
+			}
+
+			for _, value := range values {
+				if msg, ok := value.(string); ok {
+					msgs <- []byte(msg)
+				}
+			}
+		}
+	}()
+
+	return msgs
+}
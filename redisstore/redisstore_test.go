@@ -0,0 +1,180 @@
+package redisstore
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/storetest"
+	"github.com/redis/go-redis/v9"
+	"testing"
+)
+
+var testSessionID = quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+
+func newTestStore(t *testing.T) (*store, *miniredis.Miniredis) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := newStore(testSessionID, redis.NewClient(&redis.Options{Addr: server.Addr()}), "engine1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s, server
+}
+
+func TestClientOptionsFor(t *testing.T) {
+	settings := quickfix.NewSessionSettings()
+	if _, err := clientOptionsFor(settings); err == nil {
+		t.Error("Expected error without RedisStoreAddrs")
+	}
+
+	settings.Set(config.RedisStoreAddrs, "node1:6379, node2:6379")
+	settings.Set(config.RedisStoreCluster, "Y")
+	options, err := clientOptionsFor(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(options.Addrs) != 2 || options.Addrs[1] != "node2:6379" || !options.IsClusterMode {
+		t.Errorf("Unexpected options %+v", options)
+	}
+
+	settings.Set(config.RedisStoreDB, "1")
+	if _, err := clientOptionsFor(settings); err == nil {
+		t.Error("Expected error for RedisStoreDB in cluster mode")
+	}
+}
+
+func TestKeysFor(t *testing.T) {
+	keys := keysFor("engine1", testSessionID)
+	if keys.session != "engine1:{FIX.4.2:TW->ISLD}:session" || keys.messages != "engine1:{FIX.4.2:TW->ISLD}:messages" {
+		t.Errorf("Unexpected keys %+v", keys)
+	}
+}
+
+func TestStore_Persistence(t *testing.T) {
+	s, server := newTestStore(t)
+	defer server.Close()
+
+	s.SetNextSenderMsgSeqNum(3)
+	s.IncrNextTargetMsgSeqNum()
+	s.SaveRecoveryState(quickfix.RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2})
+	for seqNum, msg := range []string{"hello", "cruel", "world"} {
+		if err := s.TrySaveMessage(seqNum+1, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	restarted, err := newStore(testSessionID, s.client, "engine1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restarted.NextSenderMsgSeqNum() != 3 || restarted.NextTargetMsgSeqNum() != 2 {
+		t.Errorf("Expected seqnums 3 and 2 got %v and %v", restarted.NextSenderMsgSeqNum(), restarted.NextTargetMsgSeqNum())
+	}
+
+	if state, _ := restarted.RecoveryState(); state != (quickfix.RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2}) {
+		t.Errorf("Unexpected recovery state %+v", state)
+	}
+
+	if !restarted.CreationTime().Equal(s.CreationTime()) {
+		t.Errorf("Expected creation time %v got %v", s.CreationTime(), restarted.CreationTime())
+	}
+
+	var msgs []string
+	for msg := range restarted.GetMessages(2, 5) {
+		msgs = append(msgs, string(msg))
+	}
+	if len(msgs) != 2 || msgs[0] != "cruel" || msgs[1] != "world" {
+		t.Errorf("Unexpected messages %v", msgs)
+	}
+
+	restarted.Reset()
+	if restarted.NextSenderMsgSeqNum() != 1 || server.Exists(restarted.keys.messages) {
+		t.Error("Expected empty store after reset")
+	}
+}
+
+func TestStore_SeqNumConflict(t *testing.T) {
+	s, server := newTestStore(t)
+	defer server.Close()
+
+	other, err := newStore(testSessionID, s.client, "engine1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.IncrNextSenderMsgSeqNum()
+	other.IncrNextSenderMsgSeqNum()
+
+	if err := other.TrySaveMessage(1, []byte("hello")); err == nil {
+		t.Error("Expected conflicting write of sender seqnum to fail the next save")
+	}
+
+	if err := s.TrySaveMessage(1, []byte("hello")); err != nil {
+		t.Error("Did not expect error", err)
+	}
+}
+
+func TestStore_Compact(t *testing.T) {
+	s, server := newTestStore(t)
+	defer server.Close()
+
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		s.SaveMessage(seqNum, []byte("msg"))
+	}
+
+	if err := s.Compact(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if fields, _ := s.client.HKeys(context.Background(), s.keys.messages).Result(); len(fields) != 1 || fields[0] != "3" {
+		t.Errorf("Expected message 3 retained got %v", fields)
+	}
+}
+
+func TestStore_Health(t *testing.T) {
+	s, server := newTestStore(t)
+
+	if err := s.Health(); err != nil {
+		t.Fatal(err)
+	}
+
+	server.Close()
+	if err := s.Health(); err == nil {
+		t.Error("Expected unhealthy store once the server is down")
+	}
+}
+
+func TestStore_Conformance(t *testing.T) {
+	var servers []*miniredis.Miniredis
+	defer func() {
+		for _, server := range servers {
+			server.Close()
+		}
+	}()
+
+	storetest.Run(t, storetest.Harness{
+		NewBackend: func(t *testing.T) func([]quickfix.SessionID) quickfix.MessageStoreFactory {
+			server, err := miniredis.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			servers = append(servers, server)
+
+			return func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory {
+				factory, err := NewStoreFactory(storetest.Settings(sessionIDs, map[string]string{config.RedisStoreAddrs: server.Addr()}))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return factory
+			}
+		},
+		Persistent: true,
+	})
+}
@@ -1,11 +1,13 @@
 package quickfix
 
 import (
+	"context"
 	"fmt"
 	"github.com/quickfixgo/quickfix/fix/field"
 )
 
-//Send determines the session to send msgBuilder using header fields BeginString, TargetCompID, SenderCompID
+//Send determines the session to send msgBuilder using header fields BeginString, TargetCompID, SenderCompID.
+//If several sessions share these fields, distinguished by SessionQualifier, use SendToTarget with the qualified SessionID instead.
 func Send(msg MessageBuilder) (err error) {
 	var beginString field.BeginStringField
 	if err := msg.Header().Get(&beginString); err != nil {
@@ -25,18 +27,37 @@ func Send(msg MessageBuilder) (err error) {
 
 	sessionID := SessionID{BeginString: beginString.Value, TargetCompID: targetCompID.Value, SenderCompID: senderCompID.Value}
 
-	return SendToTarget(msg, sessionID)
+	session, err := lookupSession(sessionID, true)
+	if err != nil {
+		return err
+	}
+
+	return session.submit(context.Background(), msg)
 }
 
+//SendToTarget sends msgBuilder on the session with sessionID.
+//Messages sent while the session is not logged on are queued, and sent in order once logged on, subject to MaxSendQueueDepth and SendQueueOverflow.
+//With OutboundQueueDepth, returns once the message is queued to be sent by the session, subject to OutboundQueueOverflow, errors sending it
+//are reported to the SendErrorListener.
 func SendToTarget(msgBuilder MessageBuilder, sessionID SessionID) error {
 	session, err := LookupSession(sessionID)
 	if err != nil {
 		return err
 	}
 
-	session.send(msgBuilder)
+	return session.submit(context.Background(), msgBuilder)
+}
 
-	return nil
+//SendToTargetCtx is SendToTarget, abandoning the send if ctx is done while waiting for the session to log on, for the throttle, or for the connection to accept the message.
+//Returns ErrSendQueueFull, ErrThrottled, a StoreError, or the error of ctx if the message is not sent or queued.
+//With OutboundQueueDepth, returns ErrOutboundQueueFull, or the error of ctx if done while waiting for space in the outbound queue.
+func SendToTargetCtx(ctx context.Context, msgBuilder MessageBuilder, sessionID SessionID) error {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return session.submit(ctx, msgBuilder)
 }
 
 type sessionActivate struct {
@@ -57,6 +78,14 @@ type sessionLookupResponse struct {
 type sessionLookup struct {
 	SessionID
 	reply chan sessionLookupResponse
+
+	//anyQualifier matches a single session with any SessionQualifier if sessionID does not match exactly
+	anyQualifier bool
+}
+
+type sessionRemove struct {
+	SessionID
+	reply chan *Session
 }
 
 type registry struct {
@@ -64,6 +93,8 @@ type registry struct {
 	activate   chan sessionActivate
 	deactivate chan SessionID
 	lookup     chan sessionLookup
+	remove     chan sessionRemove
+	list       chan chan []*Session
 }
 
 var sessions *registry
@@ -74,6 +105,8 @@ func init() {
 	sessions.activate = make(chan sessionActivate)
 	sessions.deactivate = make(chan SessionID)
 	sessions.lookup = make(chan sessionLookup)
+	sessions.remove = make(chan sessionRemove)
+	sessions.list = make(chan chan []*Session)
 
 	go sessions.sessionResourceServerLoop()
 }
@@ -88,10 +121,36 @@ func deactivate(sessionID SessionID) {
 	sessions.deactivate <- sessionID
 }
 
+//unregisterSession removes the session from the registry and stops it, logging out if connected.
+func unregisterSession(sessionID SessionID) error {
+	response := make(chan *Session)
+	sessions.remove <- sessionRemove{sessionID, response}
+
+	session := <-response
+	if session == nil {
+		return fmt.Errorf("session not found")
+	}
+
+	close(session.stop)
+	session.wake()
+	return nil
+}
+
 //LookupSession returns the Session associated with the sessionID.
 func LookupSession(sessionID SessionID) (*Session, error) {
+	return lookupSession(sessionID, false)
+}
+
+//allSessions returns each registered session.
+func allSessions() []*Session {
+	response := make(chan []*Session)
+	sessions.list <- response
+	return <-response
+}
+
+func lookupSession(sessionID SessionID, anyQualifier bool) (*Session, error) {
 	responseChannel := make(chan sessionLookupResponse)
-	sessions.lookup <- sessionLookup{sessionID, responseChannel}
+	sessions.lookup <- sessionLookup{sessionID, responseChannel, anyQualifier}
 
 	response := <-responseChannel
 	return response.session, response.err
@@ -110,13 +169,30 @@ func (r *registry) sessionResourceServerLoop() {
 				resource.active = false
 			}
 
+		case removal := <-r.remove:
+			if resource, ok := sessions[removal.SessionID]; ok {
+				delete(sessions, removal.SessionID)
+				removal.reply <- resource.session
+			} else {
+				removal.reply <- nil
+			}
+
 		case lookup := <-r.lookup:
 			if resource, ok := sessions[lookup.SessionID]; ok {
 				lookup.reply <- sessionLookupResponse{resource.session, nil}
+			} else if lookup.anyQualifier {
+				lookup.reply <- lookupQualified(sessions, lookup.SessionID)
 			} else {
 				lookup.reply <- sessionLookupResponse{nil, fmt.Errorf("session not found")}
 			}
 
+		case reply := <-r.list:
+			list := make([]*Session, 0, len(sessions))
+			for _, resource := range sessions {
+				list = append(list, resource.session)
+			}
+			reply <- list
+
 		case request := <-r.activate:
 			resource, ok := sessions[request.SessionID]
 
@@ -134,3 +210,24 @@ func (r *registry) sessionResourceServerLoop() {
 		}
 	}
 }
+
+//lookupQualified finds the only session matching sessionID ignoring SessionQualifier.
+func lookupQualified(sessions map[SessionID]*sessionResource, sessionID SessionID) sessionLookupResponse {
+	var match *Session
+	for id, resource := range sessions {
+		if unqualified(id) != sessionID {
+			continue
+		}
+
+		if match != nil {
+			return sessionLookupResponse{nil, fmt.Errorf("ambiguous session %v, sessions are distinguished by SessionQualifier", sessionID)}
+		}
+		match = resource.session
+	}
+
+	if match == nil {
+		return sessionLookupResponse{nil, fmt.Errorf("session not found")}
+	}
+
+	return sessionLookupResponse{match, nil}
+}
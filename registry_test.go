@@ -0,0 +1,45 @@
+package quickfix
+
+import (
+	"testing"
+)
+
+func TestRegistry_LookupSessionQualifier(t *testing.T) {
+	orders := SessionID{BeginString: "FIX.4.2", SenderCompID: "QUAL", TargetCompID: "CPTY", Qualifier: "ORDERS"}
+	dropCopy := SessionID{BeginString: "FIX.4.2", SenderCompID: "QUAL", TargetCompID: "CPTY", Qualifier: "DROPCOPY"}
+	single := SessionID{BeginString: "FIX.4.2", SenderCompID: "QUAL", TargetCompID: "OTHER", Qualifier: "ORDERS"}
+
+	for _, sessionID := range []SessionID{orders, dropCopy, single} {
+		if err := createSession(sessionID, NewMemoryStoreFactory(), NewSessionSettings(), NewNullLogFactory(), &TestClient{}); err != nil {
+			t.Fatal(err)
+		}
+		defer unregisterSession(sessionID)
+	}
+
+	for _, sessionID := range []SessionID{orders, dropCopy} {
+		session, err := LookupSession(sessionID)
+		if err != nil {
+			t.Fatalf("Unexpected error looking up %v: %v", sessionID, err)
+		}
+
+		if session.sessionID != sessionID {
+			t.Errorf("Expected session %v got %v", sessionID, session.sessionID)
+		}
+	}
+
+	if _, err := lookupSession(unqualified(orders), true); err == nil {
+		t.Error("Expected error for sessions distinguished by qualifier")
+	}
+
+	session, err := lookupSession(unqualified(single), true)
+	switch {
+	case err != nil:
+		t.Errorf("Unexpected error looking up %v: %v", unqualified(single), err)
+	case session.sessionID != single:
+		t.Errorf("Expected session %v got %v", single, session.sessionID)
+	}
+
+	if _, err := LookupSession(unqualified(single)); err == nil {
+		t.Error("Expected LookupSession to match the qualifier exactly")
+	}
+}
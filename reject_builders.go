@@ -0,0 +1,157 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/enum"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+)
+
+//OrderCancelRejectError is an application error refusing an OrderCancelRequest or OrderCancelReplaceRequest.
+type OrderCancelRejectError struct {
+	//Reason is the CxlRejReason, tag 102.
+	Reason int
+
+	//OrdStatus is the current status of the order, tag 39. Defaults to Rejected.
+	OrdStatus string
+
+	//OrderID identifies the order, tag 37. Defaults to NONE for unknown orders.
+	OrderID string
+
+	Text string
+}
+
+func (e OrderCancelRejectError) Error() string {
+	if e.Text != "" {
+		return e.Text
+	}
+
+	return fmt.Sprintf("Order cancel rejected, reason %d", e.Reason)
+}
+
+//NewReject returns a session level Reject (35=3) of msg for rej.
+//RefSeqNum is populated from msg, and for FIX.4.2 and later RefMsgType, RefTagID, and SessionRejectReason.
+//Prior to FIX.4.2 the RefTagID is included in Text.
+func NewReject(msg Message, rej MessageRejectError) MessageBuilder {
+	return buildReject(messageBeginString(msg), msg, rej, false)
+}
+
+//NewBusinessMessageReject returns a BusinessMessageReject (35=j) of msg for rej.
+//RefSeqNum, RefMsgType, and BusinessRejectReason are populated from msg and rej, businessRejectRefID is set as BusinessRejectRefID if not empty.
+//BusinessMessageReject was introduced in FIX.4.2, for earlier versions a session level Reject is returned.
+func NewBusinessMessageReject(msg Message, rej MessageRejectError, businessRejectRefID string) MessageBuilder {
+	reply := buildReject(messageBeginString(msg), msg, rej, true)
+
+	if businessRejectRefID != "" {
+		reply.Body().Set(field.NewBusinessRejectRefID(businessRejectRefID))
+	}
+
+	return reply
+}
+
+//NewOrderCancelReject returns an OrderCancelReject (35=9) of msg, an OrderCancelRequest or OrderCancelReplaceRequest, for rej.
+//ClOrdID and OrigClOrdID are copied from msg, and for FIX.4.2 and later CxlRejResponseTo is derived from the MsgType of msg.
+func NewOrderCancelReject(msg Message, rej OrderCancelRejectError) (MessageBuilder, error) {
+	var msgType field.MsgTypeField
+	if err := msg.Header.Get(&msgType); err != nil {
+		return nil, err
+	}
+
+	var responseTo string
+	switch msgType.Value {
+	case "F":
+		responseTo = enum.CxlRejResponseTo_ORDER_CANCEL_REQUEST
+	case "G":
+		responseTo = enum.CxlRejResponseTo_ORDER_CANCEL_REPLACE_REQUEST
+	default:
+		return nil, fmt.Errorf("OrderCancelReject of unexpected MsgType %v", msgType.Value)
+	}
+
+	reply := msg.reverseRoute()
+	reply.Header().Set(field.NewBeginString(messageBeginString(msg)))
+	reply.Header().Set(field.NewMsgType("9"))
+
+	orderID := rej.OrderID
+	if orderID == "" {
+		orderID = "NONE"
+	}
+	reply.Body().Set(field.NewOrderID(orderID))
+
+	ordStatus := rej.OrdStatus
+	if ordStatus == "" {
+		ordStatus = enum.OrdStatus_REJECTED
+	}
+	reply.Body().Set(field.NewOrdStatus(ordStatus))
+
+	for _, t := range []fix.Tag{tag.ClOrdID, tag.OrigClOrdID} {
+		if value := new(fix.StringValue); msg.Body.GetField(t, value) == nil {
+			reply.Body().SetField(t, value)
+		}
+	}
+
+	if messageBeginString(msg) >= fix.BeginString_FIX42 {
+		reply.Body().Set(field.NewCxlRejResponseTo(responseTo))
+	}
+
+	reply.Body().Set(field.NewCxlRejReason(rej.Reason))
+	if rej.Text != "" {
+		reply.Body().Set(field.NewText(rej.Text))
+	}
+
+	return reply, nil
+}
+
+//messageBeginString returns the BeginString of msg, empty if not set.
+func messageBeginString(msg Message) string {
+	beginString := new(fix.StringValue)
+	msg.Header.GetField(tag.BeginString, beginString)
+	return beginString.Value
+}
+
+//buildReject builds a Reject or, if business and supported by beginString, a BusinessMessageReject of msg.
+func buildReject(beginString string, msg Message, rej MessageRejectError, business bool) MessageBuilder {
+	reply := msg.reverseRoute()
+	reply.Header().Set(field.NewBeginString(beginString))
+
+	if beginString >= fix.BeginString_FIX42 {
+		if business {
+			reply.Header().Set(field.NewMsgType("j"))
+			reply.Body().Set(field.NewBusinessRejectReason(int(rej.RejectReason())))
+		} else {
+			reply.Header().Set(field.NewMsgType("3"))
+			switch {
+			default:
+				reply.Body().Set(field.NewSessionRejectReason(int(rej.RejectReason())))
+			case rej.RejectReason() > rejectReasonInvalidMsgType && beginString == fix.BeginString_FIX42:
+				//fix42 knows up to invalid msg type
+			}
+
+			//RefTagID is not a field of BusinessMessageReject
+			if refTagID := rej.RefTagID(); refTagID != nil {
+				reply.Body().Set(field.NewRefTagID(int(*refTagID)))
+			}
+		}
+		reply.Body().Set(field.NewText(rej.Error()))
+
+		var msgType field.MsgTypeField
+		if err := msg.Header.Get(&msgType); err == nil {
+			reply.Body().Set(field.NewRefMsgType(msgType.Value))
+		}
+	} else {
+		reply.Header().Set(field.NewMsgType("3"))
+
+		if refTagID := rej.RefTagID(); refTagID != nil {
+			reply.Body().Set(field.NewText(fmt.Sprintf("%s (%d)", rej.Error(), *refTagID)))
+		} else {
+			reply.Body().Set(field.NewText(rej.Error()))
+		}
+	}
+
+	var seqNum field.MsgSeqNumField
+	if err := msg.Header.Get(&seqNum); err == nil {
+		reply.Body().Set(field.NewRefSeqNum(seqNum.Value))
+	}
+
+	return reply
+}
@@ -0,0 +1,103 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+)
+
+func buildAndParse(t *testing.T, builder MessageBuilder) *Message {
+	msgBytes, err := builder.Build()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	msg, err := parseMessage(msgBytes)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	return msg
+}
+
+func checkStringField(t *testing.T, fieldMap FieldMap, fieldTag fix.Tag, expected string) {
+	value := new(fix.StringValue)
+	if err := fieldMap.GetField(fieldTag, value); err != nil {
+		t.Errorf("expected %v = %v, got %v", fieldTag, expected, err)
+		return
+	}
+
+	if value.Value != expected {
+		t.Errorf("expected %v = %v, got %v", fieldTag, expected, value.Value)
+	}
+}
+
+func TestNewReject(t *testing.T) {
+	msg, _ := parseMessage(rawMessage("FIX.4.4", "35=D\00134=7\00149=TW\00156=ISLD\00111=ID\001"))
+
+	reject := buildAndParse(t, NewReject(*msg, requiredTagMissing(tag.Side)))
+
+	checkStringField(t, reject.Header, tag.MsgType, "3")
+	checkStringField(t, reject.Header, tag.SenderCompID, "ISLD")
+	checkStringField(t, reject.Header, tag.TargetCompID, "TW")
+	checkStringField(t, reject.Body, tag.RefSeqNum, "7")
+	checkStringField(t, reject.Body, tag.RefMsgType, "D")
+	checkStringField(t, reject.Body, tag.RefTagID, "54")
+	checkStringField(t, reject.Body, tag.SessionRejectReason, "1")
+}
+
+func TestNewReject_FIX40(t *testing.T) {
+	msg, _ := parseMessage(rawMessage("FIX.4.0", "35=D\00134=7\00149=TW\00156=ISLD\00111=ID\001"))
+
+	reject := buildAndParse(t, NewReject(*msg, requiredTagMissing(tag.Side)))
+
+	checkStringField(t, reject.Header, tag.MsgType, "3")
+	checkStringField(t, reject.Body, tag.RefSeqNum, "7")
+	checkStringField(t, reject.Body, tag.Text, "Required tag missing (54)")
+
+	for _, unsupported := range []fix.Tag{tag.RefMsgType, tag.RefTagID, tag.SessionRejectReason} {
+		if reject.Body.Has(unsupported) {
+			t.Errorf("tag %v not supported by FIX.4.0", unsupported)
+		}
+	}
+}
+
+func TestNewBusinessMessageReject(t *testing.T) {
+	msg, _ := parseMessage(rawMessage("FIX.4.4", "35=D\00134=7\00149=TW\00156=ISLD\00111=ID\001"))
+
+	reject := buildAndParse(t, NewBusinessMessageReject(*msg, conditionallyRequiredFieldMissing(tag.Price), "ID"))
+
+	checkStringField(t, reject.Header, tag.MsgType, "j")
+	checkStringField(t, reject.Body, tag.RefSeqNum, "7")
+	checkStringField(t, reject.Body, tag.RefMsgType, "D")
+	checkStringField(t, reject.Body, tag.BusinessRejectRefID, "ID")
+	checkStringField(t, reject.Body, tag.BusinessRejectReason, "5")
+
+	if reject.Body.Has(tag.RefTagID) {
+		t.Error("RefTagID is not a field of BusinessMessageReject")
+	}
+}
+
+func TestNewOrderCancelReject(t *testing.T) {
+	msg, _ := parseMessage(rawMessage("FIX.4.4", "35=G\00134=7\00149=TW\00156=ISLD\00111=ID2\00141=ID1\001"))
+
+	builder, err := NewOrderCancelReject(*msg, OrderCancelRejectError{Reason: 1, Text: "Unknown order"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	reject := buildAndParse(t, builder)
+	checkStringField(t, reject.Header, tag.MsgType, "9")
+	checkStringField(t, reject.Body, tag.OrderID, "NONE")
+	checkStringField(t, reject.Body, tag.OrdStatus, "8")
+	checkStringField(t, reject.Body, tag.ClOrdID, "ID2")
+	checkStringField(t, reject.Body, tag.OrigClOrdID, "ID1")
+	checkStringField(t, reject.Body, tag.CxlRejResponseTo, "2")
+	checkStringField(t, reject.Body, tag.CxlRejReason, "1")
+	checkStringField(t, reject.Body, tag.Text, "Unknown order")
+
+	order, _ := parseMessage(rawMessage("FIX.4.4", "35=D\00134=7\00149=TW\00156=ISLD\00111=ID\001"))
+	if _, err := NewOrderCancelReject(*order, OrderCancelRejectError{}); err == nil {
+		t.Error("expected error for NewOrderSingle")
+	}
+}
@@ -0,0 +1,141 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"sort"
+	"strconv"
+)
+
+//RepeatingGroup is a repeating group being built for a MessageBuilder, added to a section with SetGroup.
+//Every entry must start with the group delimiter field, SetGroup returns an error describing the first entry that does not.
+type RepeatingGroup struct {
+	numInGroupTag fix.Tag
+	delimiter     fix.Tag
+	def           *datadictionary.FieldDef
+	entries       []*GroupEntry
+
+	//SortFields orders the fields of each entry as declared in the group definition instead of the order they were set.
+	//Only applies to groups created with NewRepeatingGroupForDef.
+	SortFields bool
+}
+
+//GroupEntry is a single entry of a RepeatingGroup. Fields are written in the order they are set.
+type GroupEntry struct {
+	fields []*fieldBytes
+}
+
+//NewRepeatingGroup returns an empty group counted by numInGroupTag, with entries starting with delimiter.
+func NewRepeatingGroup(numInGroupTag, delimiter fix.Tag) *RepeatingGroup {
+	return &RepeatingGroup{numInGroupTag: numInGroupTag, delimiter: delimiter}
+}
+
+//NewRepeatingGroupForDef returns an empty group defined by def, a repeating group field from a data dictionary.
+//The delimiter is the first field declared for the group, and entries may only hold fields declared for the group.
+func NewRepeatingGroupForDef(def *datadictionary.FieldDef) *RepeatingGroup {
+	return &RepeatingGroup{numInGroupTag: def.Tag, delimiter: def.ChildFields[0].Tag, def: def}
+}
+
+//Tag returns the NumInGroup tag of the group.
+func (g *RepeatingGroup) Tag() fix.Tag { return g.numInGroupTag }
+
+//Add appends a new empty entry to the group.
+func (g *RepeatingGroup) Add() *GroupEntry {
+	entry := new(GroupEntry)
+	g.entries = append(g.entries, entry)
+	return entry
+}
+
+//Len returns the number of entries in the group.
+func (g *RepeatingGroup) Len() int { return len(g.entries) }
+
+//SetField sets the value of tag in the entry, replacing any previous value in place.
+func (e *GroupEntry) SetField(tag fix.Tag, field FieldValue) *GroupEntry {
+	e.set(newFieldBytes(tag, field.Write()))
+	return e
+}
+
+//Set sets field in the entry, replacing any previous value in place.
+func (e *GroupEntry) Set(field Field) *GroupEntry {
+	return e.SetField(field.Tag(), field)
+}
+
+//SetGroup sets a nested repeating group in the entry.
+func (e *GroupEntry) SetGroup(group *RepeatingGroup) error {
+	f, err := group.build()
+	if err != nil {
+		return err
+	}
+
+	e.set(f)
+	return nil
+}
+
+func (e *GroupEntry) set(f *fieldBytes) {
+	for i, existing := range e.fields {
+		if existing.Tag == f.Tag {
+			e.fields[i] = f
+			return
+		}
+	}
+
+	e.fields = append(e.fields, f)
+}
+
+//build serializes the group as a single field keyed by the NumInGroup tag, holding the NumInGroup field followed by every entry.
+func (g *RepeatingGroup) build() (*fieldBytes, error) {
+	var position map[fix.Tag]int
+	if g.def != nil {
+		position = make(map[fix.Tag]int, len(g.def.ChildFields))
+		for i, child := range g.def.ChildFields {
+			position[child.Tag] = i
+		}
+	}
+
+	count := []byte(strconv.Itoa(len(g.entries)))
+
+	var b bytes.Buffer
+	b.Write(newFieldBytes(g.numInGroupTag, count).Data)
+
+	for i, entry := range g.entries {
+		fields := entry.fields
+
+		if position != nil {
+			for _, f := range fields {
+				if _, ok := position[f.Tag]; !ok {
+					return nil, fmt.Errorf("group %d entry %d: tag %d is not defined for the group", g.numInGroupTag, i+1, f.Tag)
+				}
+			}
+
+			if g.SortFields {
+				fields = append([]*fieldBytes(nil), fields...)
+				sort.SliceStable(fields, func(i, j int) bool { return position[fields[i].Tag] < position[fields[j].Tag] })
+			}
+		}
+
+		switch {
+		case len(fields) == 0 || !entry.has(g.delimiter):
+			return nil, fmt.Errorf("group %d entry %d: missing delimiter tag %d", g.numInGroupTag, i+1, g.delimiter)
+		case fields[0].Tag != g.delimiter:
+			return nil, fmt.Errorf("group %d entry %d: delimiter tag %d must be the first field of the entry, found tag %d", g.numInGroupTag, i+1, g.delimiter, fields[0].Tag)
+		}
+
+		for _, f := range fields {
+			b.Write(f.Data)
+		}
+	}
+
+	return &fieldBytes{Tag: g.numInGroupTag, Data: b.Bytes(), Value: count}, nil
+}
+
+func (e *GroupEntry) has(tag fix.Tag) bool {
+	for _, f := range e.fields {
+		if f.Tag == tag {
+			return true
+		}
+	}
+
+	return false
+}
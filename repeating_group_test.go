@@ -0,0 +1,97 @@
+package quickfix
+
+import (
+	"bytes"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"strings"
+	"testing"
+)
+
+func TestFieldMap_SetGroup(t *testing.T) {
+	builder := NewMessageBuilder()
+	builder.Header().Set(field.NewBeginString(fix.BeginString_FIX42))
+	builder.Header().Set(field.NewMsgType("D"))
+	builder.Body().Set(field.NewClOrdID("ID"))
+
+	group := NewRepeatingGroup(tag.NoAllocs, tag.AllocAccount)
+	group.Add().Set(field.NewAllocAccount("A")).Set(field.NewAllocShares(10))
+	group.Add().Set(field.NewAllocAccount("B")).Set(field.NewAllocShares(20))
+	if err := builder.Body().SetGroup(group); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	msgBytes, _ := builder.Build()
+	if !bytes.Contains(msgBytes, []byte("11=ID\00178=2\00179=A\00180=10\00179=B\00180=20\001")) {
+		t.Errorf("unexpected group serialization %q", msgBytes)
+	}
+
+	if _, err := parseMessage(msgBytes); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestFieldMap_SetGroupDelimiter(t *testing.T) {
+	missing := NewRepeatingGroup(tag.NoAllocs, tag.AllocAccount)
+	missing.Add().Set(field.NewAllocAccount("A"))
+	missing.Add().Set(field.NewAllocShares(20))
+
+	outOfOrder := NewRepeatingGroup(tag.NoAllocs, tag.AllocAccount)
+	outOfOrder.Add().Set(field.NewAllocShares(10)).Set(field.NewAllocAccount("A"))
+
+	var testCases = []struct {
+		group    *RepeatingGroup
+		expected string
+	}{
+		{missing, "group 78 entry 2: missing delimiter tag 79"},
+		{outOfOrder, "group 78 entry 1: delimiter tag 79 must be the first field of the entry, found tag 80"},
+	}
+
+	for _, tc := range testCases {
+		err := NewMessageBuilder().Body().SetGroup(tc.group)
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("expected error %q got %v", tc.expected, err)
+		}
+	}
+}
+
+func TestFieldMap_SetGroupForDef(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX44.xml")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	def := dict.Messages["D"].Fields[tag.NoPartyIDs]
+
+	group := NewRepeatingGroupForDef(def)
+	entry := group.Add().Set(field.NewPartyRole(1)).Set(field.NewPartyID("P1"))
+
+	subIDs := NewRepeatingGroupForDef(def.ChildFields[3])
+	subIDs.Add().Set(field.NewPartySubID("S1"))
+	if err := entry.SetGroup(subIDs); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if err := NewMessageBuilder().Body().SetGroup(group); err == nil {
+		t.Error("expected error for delimiter out of order")
+	}
+
+	group.SortFields = true
+	body := NewMessageBuilder().Body()
+	if err := body.SetGroup(group); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var b bytes.Buffer
+	body.(fieldMap).write(&b)
+	if b.String() != "453=1\001448=P1\001452=1\001802=1\001523=S1\001" {
+		t.Errorf("unexpected group serialization %q", b.String())
+	}
+
+	group.Add().Set(field.NewPartyID("P2")).Set(field.NewSide("1"))
+	if err := body.SetGroup(group); err == nil || !strings.Contains(err.Error(), "tag 54 is not defined for the group") {
+		t.Error("expected error for undefined tag, got", err)
+	}
+}
@@ -16,6 +16,15 @@ func (state resendState) FixMsgIn(session *Session, msg Message) (nextState sess
 		msg, ok = session.messageStash[session.store.NextTargetMsgSeqNum()]
 	}
 
+	session.continueResend()
+
+	//stashed messages already processed, from the resend, are duplicates
+	for seqNum := range session.messageStash {
+		if seqNum < session.store.NextTargetMsgSeqNum() {
+			delete(session.messageStash, seqNum)
+		}
+	}
+
 	if len(session.messageStash) != 0 {
 		nextState = resendState{}
 	}
@@ -0,0 +1,67 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"testing"
+)
+
+//checkResendRequest checks the only message sent by session is a ResendRequest from beginSeqNo to endSeqNo.
+func checkResendRequest(t *testing.T, session *Session, beginSeqNo, endSeqNo int) {
+	if len(session.messageOut) != 1 {
+		t.Fatalf("Expected ResendRequest FROM: %d TO: %d, %d messages sent", beginSeqNo, endSeqNo, len(session.messageOut))
+	}
+
+	msg, _ := parseMessage(<-session.messageOut)
+	msgType, begin, end := new(fix.StringValue), new(fix.IntValue), new(fix.IntValue)
+	msg.Header.GetField(tag.MsgType, msgType)
+	msg.Body.GetField(tag.BeginSeqNo, begin)
+	msg.Body.GetField(tag.EndSeqNo, end)
+
+	if msgType.Value != "2" || begin.Value != beginSeqNo || end.Value != endSeqNo {
+		t.Errorf("Expected ResendRequest FROM: %d TO: %d got MsgType %v FROM: %d TO: %d", beginSeqNo, endSeqNo, msgType.Value, begin.Value, end.Value)
+	}
+}
+
+func TestResendState_ResendRequestChunkSize(t *testing.T) {
+	session := newTestAdminSession(&TestClient{})
+	session.messageStash = make(map[int]Message)
+	session.resendRequestChunkSize = 2
+
+	var state sessionState = inSession{}
+	state = state.FixMsgIn(session, newTestAdminMessage("0", 6))
+	if state != (resendState{}) {
+		t.Fatalf("Expected resend state got %T", state)
+	}
+	checkResendRequest(t, session, 1, 2)
+
+	var chunks = []struct {
+		seqNums              []int
+		expectedBeginSeqNo   int
+		expectedEndSeqNo     int
+		expectedResendFinish bool
+	}{
+		{[]int{1, 2}, 3, 4, false},
+		{[]int{3, 4}, 5, 0, false},
+		{[]int{5}, 0, 0, true},
+	}
+
+	for _, chunk := range chunks {
+		for _, seqNum := range chunk.seqNums {
+			state = state.FixMsgIn(session, newTestAdminMessage("0", seqNum))
+		}
+
+		if chunk.expectedResendFinish {
+			if state != (inSession{}) || len(session.messageOut) != 0 {
+				t.Errorf("Expected resend complete, state %T with %d messages sent", state, len(session.messageOut))
+			}
+			continue
+		}
+
+		checkResendRequest(t, session, chunk.expectedBeginSeqNo, chunk.expectedEndSeqNo)
+	}
+
+	if next := session.store.NextTargetMsgSeqNum(); next != 7 {
+		t.Errorf("Expected stashed message processed, next target %d", next)
+	}
+}
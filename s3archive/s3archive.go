@@ -0,0 +1,81 @@
+//Package s3archive provides a QuickFIX/Go MessageArchiver storing archived message logs in Amazon S3.
+//Google Cloud Storage may be used through its S3 compatible XML API, with an s3.Client whose BaseEndpoint is https://storage.googleapis.com.
+package s3archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"time"
+)
+
+const defaultTimeout = time.Minute
+
+//PutObjectAPI is the part of *s3.Client used by Archiver.
+type PutObjectAPI interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+//Archiver is a quickfix.MessageArchiver putting each message log as an object of Bucket.
+type Archiver struct {
+	client PutObjectAPI
+	bucket string
+
+	//Timeout limits each PutObject, one minute if zero.
+	Timeout time.Duration
+
+	//StorageClass of the objects put, the bucket default if empty.
+	StorageClass types.StorageClass
+
+	//OnError, if set, is called each time putting key fails, before it is retried.
+	OnError func(key string, err error)
+
+	//OnDropped, if set, is called when the message log key is discarded without being put.
+	OnDropped func(key string, err error)
+}
+
+//NewArchiver returns an Archiver putting message logs in bucket with client.
+func NewArchiver(client PutObjectAPI, bucket string) *Archiver {
+	return &Archiver{client: client, bucket: bucket}
+}
+
+//Archive puts log as the object key.
+func (a *Archiver) Archive(key string, log []byte) error {
+	timeout := a.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(a.bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(log),
+		ContentLength: aws.Int64(int64(len(log))),
+		ContentType:   aws.String("application/gzip"),
+		StorageClass:  a.StorageClass,
+	}
+
+	if _, err := a.client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("cannot put s3://%v/%v: %v", a.bucket, key, err)
+	}
+
+	return nil
+}
+
+func (a *Archiver) OnArchiveError(key string, err error) {
+	if a.OnError != nil {
+		a.OnError(key, err)
+	}
+}
+
+func (a *Archiver) OnArchiveDropped(key string, err error) {
+	if a.OnDropped != nil {
+		a.OnDropped(key, err)
+	}
+}
@@ -0,0 +1,82 @@
+package s3archive
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type fakeClient struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (c *fakeClient) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	body, err := ioutil.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.ToInt64(params.ContentLength) != int64(len(body)) {
+		return nil, errors.New("content length mismatch")
+	}
+
+	c.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestArchiver_Archive(t *testing.T) {
+	client := &fakeClient{objects: make(map[string][]byte)}
+	archiver := NewArchiver(client, "bucket")
+
+	if err := archiver.Archive("2016/01/02/FIX.4.2-TW-ISLD/000000.log.gz", []byte("log")); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(client.objects["bucket/2016/01/02/FIX.4.2-TW-ISLD/000000.log.gz"]) != "log" {
+		t.Errorf("Expected object put, got %v", client.objects)
+	}
+
+	client.err = errors.New("access denied")
+	if err := archiver.Archive("key", []byte("log")); err == nil || !strings.Contains(err.Error(), "s3://bucket/key") {
+		t.Errorf("Expected error naming object, got %v", err)
+	}
+}
+
+func TestArchiver_ArchiveLogFactory(t *testing.T) {
+	client := &fakeClient{objects: make(map[string][]byte)}
+	archiver := NewArchiver(client, "bucket")
+
+	var dropped []string
+	archiver.OnDropped = func(key string, err error) { dropped = append(dropped, key) }
+
+	settings := quickfix.NewSettings()
+	settings.GlobalSettings().Set(config.ArchivePrefix, "quickfix")
+
+	factory, err := quickfix.NewArchiveLogFactory(quickfix.NewNullLogFactory(), archiver, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log, _ := factory.CreateSessionLog(quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"})
+	log.OnOutgoing("8=FIX.4.2\x01")
+
+	client.err = errors.New("unavailable")
+	if err := factory.Close(); err == nil {
+		t.Error("Expected error closing while unavailable")
+	}
+
+	if len(dropped) != 1 || !strings.HasPrefix(dropped[0], "quickfix/") {
+		t.Errorf("Expected log dropped, got %v", dropped)
+	}
+}
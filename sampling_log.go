@@ -0,0 +1,163 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultLogSummaryInterval = time.Minute
+
+//samplingLog writes 1 in LogSampleRate of the application messages of a session to its Log, per direction and MsgType,
+//and an event summarizing the messages logged by MsgType each LogSummaryInterval.
+//Admin messages, rejects and messages whose MsgType cannot be read are always written.
+type samplingLog struct {
+	Log
+	rate     int
+	interval time.Duration
+	now      func() time.Time
+
+	//msgTypes are the MsgTypes sampled, all application messages if nil
+	msgTypes map[string]bool
+
+	lock    sync.Mutex
+	since   time.Time
+	counts  map[string]int
+	omitted int
+}
+
+//newSamplingLog returns log sampled by the LogSampleRate, LogSampleMsgTypes and LogSummaryInterval of settings,
+//log itself if LogSampleRate is not set.
+func newSamplingLog(log Log, settings *SessionSettings, now func() time.Time) (Log, error) {
+	if !settings.HasSetting(config.LogSampleRate) {
+		return log, nil
+	}
+
+	rate, err := settings.IntSetting(config.LogSampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	if rate < 1 {
+		return nil, fmt.Errorf("invalid %v %v, expected at least 1", config.LogSampleRate, rate)
+	}
+
+	l := &samplingLog{Log: log, rate: rate, interval: defaultLogSummaryInterval, now: now, counts: make(map[string]int)}
+	if settings.HasSetting(config.LogSampleMsgTypes) {
+		setting, _ := settings.Setting(config.LogSampleMsgTypes)
+
+		l.msgTypes = make(map[string]bool)
+		for _, msgType := range strings.Split(setting, ",") {
+			if msgType = strings.TrimSpace(msgType); msgType == "" {
+				return nil, fmt.Errorf("invalid %v %v, expected MsgTypes", config.LogSampleMsgTypes, setting)
+			}
+			l.msgTypes[msgType] = true
+		}
+	}
+
+	if settings.HasSetting(config.LogSummaryInterval) {
+		seconds, err := settings.IntSetting(config.LogSummaryInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		if seconds < 1 {
+			return nil, fmt.Errorf("invalid %v %v, expected at least 1", config.LogSummaryInterval, seconds)
+		}
+		l.interval = time.Duration(seconds) * time.Second
+	}
+
+	l.since = now()
+	return l, nil
+}
+
+func (l *samplingLog) OnIncoming(msg string) {
+	if l.sample("incoming", msg) {
+		l.Log.OnIncoming(msg)
+	}
+}
+
+func (l *samplingLog) OnOutgoing(msg string) {
+	if l.sample("outgoing", msg) {
+		l.Log.OnOutgoing(msg)
+	}
+}
+
+//sample counts msg, returning true if it is to be written. The summary is written first once LogSummaryInterval has elapsed.
+func (l *samplingLog) sample(direction, msg string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.summarizeElapsed()
+
+	msgType, ok := logMsgType(msg)
+	if !ok {
+		return true
+	}
+
+	key := direction + "/" + msgType
+	l.counts[key]++
+	if !l.sampled(msgType) || (l.counts[key]-1)%l.rate == 0 {
+		return true
+	}
+
+	l.omitted++
+	return false
+}
+
+//sampled returns true if messages of msgType may be left out of the log.
+func (l *samplingLog) sampled(msgType string) bool {
+	if fix.IsAdminMessageType(msgType) || msgType == "j" {
+		return false
+	}
+
+	return l.msgTypes == nil || l.msgTypes[msgType]
+}
+
+//summarizeElapsed writes the summary if LogSummaryInterval has elapsed, with lock held.
+//Heartbeats keep the summaries of idle sessions current.
+func (l *samplingLog) summarizeElapsed() {
+	now := l.now()
+	if now.Sub(l.since) < l.interval {
+		return
+	}
+
+	if len(l.counts) > 0 {
+		keys := make([]string, 0, len(l.counts))
+		for key := range l.counts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var summary bytes.Buffer
+		fmt.Fprintf(&summary, "Messages since %v, %d not logged:", l.since.UTC().Format(time.RFC3339), l.omitted)
+		for _, key := range keys {
+			fmt.Fprintf(&summary, " %v=%d", key, l.counts[key])
+		}
+
+		logEventf(l.Log, LogLevelInfo, LogCategorySession, "%s", summary.String())
+	}
+
+	l.since, l.counts, l.omitted = now, make(map[string]int), 0
+}
+
+//logMsgType returns the MsgType of msg without parsing the message.
+func logMsgType(msg string) (string, bool) {
+	i := strings.Index(msg, "\x0135=")
+	if i == -1 {
+		return "", false
+	}
+
+	msgType := msg[i+4:]
+	end := strings.IndexByte(msgType, '\x01')
+	if end <= 0 {
+		return "", false
+	}
+
+	return msgType[:end], true
+}
@@ -0,0 +1,94 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sampledRecordingLog struct {
+	recordingLog
+	events []string
+}
+
+func (l *sampledRecordingLog) OnEvent(msg string) { l.events = append(l.events, msg) }
+func (l *sampledRecordingLog) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+func TestNewSamplingLog_Settings(t *testing.T) {
+	recorded := new(sampledRecordingLog)
+	if log, err := newSamplingLog(recorded, NewSessionSettings(), time.Now); err != nil || log != Log(recorded) {
+		t.Errorf("Expected log unsampled without LogSampleRate, got %v %v", log, err)
+	}
+
+	for _, invalid := range []map[string]string{
+		{config.LogSampleRate: "0"},
+		{config.LogSampleRate: "ten"},
+		{config.LogSampleRate: "10", config.LogSampleMsgTypes: "W,,X"},
+		{config.LogSampleRate: "10", config.LogSummaryInterval: "0"},
+	} {
+		settings := NewSessionSettings()
+		for key, value := range invalid {
+			settings.Set(key, value)
+		}
+
+		if _, err := newSamplingLog(recorded, settings, time.Now); err == nil {
+			t.Errorf("Expected error for %v", invalid)
+		}
+	}
+}
+
+func TestSamplingLog(t *testing.T) {
+	now := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	settings := NewSessionSettings()
+	settings.Set(config.LogSampleRate, "3")
+	settings.Set(config.LogSampleMsgTypes, "W, X")
+	settings.Set(config.LogSummaryInterval, "60")
+
+	recorded := new(sampledRecordingLog)
+	log, err := newSamplingLog(recorded, settings, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 7; i++ {
+		log.OnIncoming(fmt.Sprintf("8=FIX.4.4\x019=5\x0135=W\x0134=%d\x01", i))
+	}
+	log.OnIncoming("8=FIX.4.4\x019=5\x0135=0\x01")
+	log.OnIncoming("8=FIX.4.4\x019=5\x0135=j\x01")
+	log.OnOutgoing("8=FIX.4.4\x019=5\x0135=D\x01")
+	log.OnOutgoing("8=FIX.4.4\x019=5\x0135=D\x01")
+	log.OnOutgoing("garbled")
+
+	if len(recorded.messages) != 8 {
+		t.Fatalf("Expected 3 of 7 W and all other messages logged, got %q", recorded.messages)
+	}
+	for i, seqNum := range []int{0, 3, 6} {
+		if !strings.Contains(recorded.messages[i], fmt.Sprintf("34=%d\x01", seqNum)) {
+			t.Errorf("Expected W %d logged, got %q", seqNum, recorded.messages[i])
+		}
+	}
+
+	if len(recorded.events) != 0 {
+		t.Errorf("Expected no summary before LogSummaryInterval, got %v", recorded.events)
+	}
+
+	now = now.Add(time.Minute)
+	log.OnIncoming("8=FIX.4.4\x019=5\x0135=0\x01")
+
+	expected := "Messages since 2016-01-01T00:00:00Z, 4 not logged: incoming/0=1 incoming/W=7 incoming/j=1 outgoing/D=2"
+	if len(recorded.events) != 1 || recorded.events[0] != expected {
+		t.Errorf("Expected summary %q, got %v", expected, recorded.events)
+	}
+
+	now = now.Add(time.Minute)
+	log.OnIncoming("8=FIX.4.4\x019=5\x0135=W\x01")
+	if len(recorded.events) != 2 || !strings.Contains(recorded.events[1], "0 not logged: incoming/0=1") {
+		t.Errorf("Expected counts reset after summary, got %v", recorded.events)
+	}
+}
@@ -0,0 +1,268 @@
+package quickfix
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//secretScheme prefixes the settings referencing a secret, such as Password=secret://fix/venueA/password.
+const secretScheme = "secret://"
+
+const (
+	//defaultSecretsCacheTTL is the time a secret is cached for without SecretsCacheTTL.
+	defaultSecretsCacheTTL = 5 * time.Minute
+
+	//secretTimeout limits each request of a secret from the SecretsProvider.
+	secretTimeout = 10 * time.Second
+)
+
+//secretSettings are the settings that may reference a secret.
+var secretSettings = []string{config.Username, config.Password, config.RawData, config.SocketCertificateFile, config.SocketPrivateKeyFile}
+
+//SecretsProvider resolves the secrets referenced by settings, so that credentials and keys are not kept in config files.
+//The Username, Password, and RawData of a session, and the PEM content of its SocketCertificateFile and SocketPrivateKeyFile,
+//may reference a secret of the SecretsProviderType of the session as secret://<path>, resolved when each Logon is sent and
+//each TLS handshake made. Secrets are cached for SecretsCacheTTL seconds, five minutes by default, so rotated secrets are
+//read within SecretsCacheTTL, and at once after the counterparty rejects the username or password on logon.
+type SecretsProvider interface {
+	//Secret returns the current value of the secret at path, the reference without secret://.
+	Secret(ctx context.Context, path string) (string, error)
+}
+
+//SecretsProviderConstructor creates the SecretsProvider of a secrets backend for the settings of a session.
+type SecretsProviderConstructor func(settings *SessionSettings) (SecretsProvider, error)
+
+var secretsProviders = struct {
+	sync.RWMutex
+	constructors map[string]SecretsProviderConstructor
+}{
+	constructors: make(map[string]SecretsProviderConstructor),
+}
+
+//RegisterSecretsProvider makes a secrets backend available as SecretsProviderType name.
+//Packages providing a backend typically register it in an init function. Panics if name is already registered.
+func RegisterSecretsProvider(name string, constructor SecretsProviderConstructor) {
+	secretsProviders.Lock()
+	defer secretsProviders.Unlock()
+
+	if _, dup := secretsProviders.constructors[name]; dup {
+		panic(fmt.Sprintf("quickfix: secrets provider %v registered twice", name))
+	}
+
+	secretsProviders.constructors[name] = constructor
+}
+
+//SecretsProviders returns the names of the registered secrets backends, sorted.
+func SecretsProviders() []string {
+	secretsProviders.RLock()
+	defer secretsProviders.RUnlock()
+
+	var names []string
+	for name := range secretsProviders.constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func isSecretReference(value string) bool {
+	return strings.HasPrefix(value, secretScheme)
+}
+
+//referencesSecrets returns the first setting of settings referencing a secret, empty if none.
+func referencesSecrets(settings *SessionSettings) string {
+	for _, setting := range secretSettings {
+		if value, err := settings.Setting(setting); err == nil && isSecretReference(value) {
+			return setting
+		}
+	}
+
+	return ""
+}
+
+//cachedSecret is a secret read from the SecretsProvider, read again once expired.
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+//secretResolver resolves the secret references of a session with its SecretsProvider, caching the secrets read.
+type secretResolver struct {
+	provider SecretsProvider
+	ttl      time.Duration
+
+	lock  sync.Mutex
+	cache map[string]cachedSecret
+}
+
+//newSecretResolver returns the resolver of the SecretsProviderType of settings, nil if settings reference no secrets.
+func newSecretResolver(settings *SessionSettings) (*secretResolver, error) {
+	setting := referencesSecrets(settings)
+	if setting == "" {
+		return nil, nil
+	}
+
+	name, err := settings.Setting(config.SecretsProviderType)
+	if err != nil {
+		return nil, fmt.Errorf("%v references a secret, %v required", setting, config.SecretsProviderType)
+	}
+
+	secretsProviders.RLock()
+	constructor, ok := secretsProviders.constructors[name]
+	secretsProviders.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown %v %v, registered are %v", config.SecretsProviderType, name, strings.Join(SecretsProviders(), ", "))
+	}
+
+	r := &secretResolver{ttl: defaultSecretsCacheTTL, cache: make(map[string]cachedSecret)}
+	if settings.HasSetting(config.SecretsCacheTTL) {
+		ttl, err := settings.IntSetting(config.SecretsCacheTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		if ttl < 0 {
+			return nil, fmt.Errorf("%v must not be negative", config.SecretsCacheTTL)
+		}
+		r.ttl = time.Duration(ttl) * time.Second
+	}
+
+	if r.provider, err = constructor(settings); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+//resolve returns value, or the secret it references. A secret is read again from the SecretsProvider once cached for the TTL,
+//if it cannot be read the secret cached is returned until it can, so a SecretsProvider unavailable for a time does not prevent logon.
+func (r *secretResolver) resolve(value string) (string, error) {
+	if r == nil || !isSecretReference(value) {
+		return value, nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cached, ok := r.cache[value]
+	if ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+
+	secret, err := r.provider.Secret(ctx, strings.TrimPrefix(value, secretScheme))
+	if err != nil {
+		if ok {
+			return cached.value, nil
+		}
+		return "", fmt.Errorf("cannot read secret %v: %v", value, err)
+	}
+
+	r.cache[value] = cachedSecret{value: secret, expires: time.Now().Add(r.ttl)}
+	return secret, nil
+}
+
+//invalidate expires the cached secrets, read again when next resolved.
+func (r *secretResolver) invalidate() {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for reference, cached := range r.cache {
+		cached.expires = time.Time{}
+		r.cache[reference] = cached
+	}
+}
+
+//resolveCredentials returns credentials with the secrets referenced resolved.
+func (r *secretResolver) resolveCredentials(credentials Credentials) (Credentials, error) {
+	var err error
+	for _, value := range []*string{&credentials.Username, &credentials.Password, &credentials.RawData} {
+		if *value, err = r.resolve(*value); err != nil {
+			return credentials, err
+		}
+	}
+
+	return credentials, nil
+}
+
+//secretCertificate is the certificate of SocketCertificateFile and SocketPrivateKeyFile, either of which references a secret.
+type secretCertificate struct {
+	resolver                        *secretResolver
+	certificateFile, privateKeyFile string
+
+	lock                          sync.Mutex
+	certificatePEM, privateKeyPEM string
+	certificate                   *tls.Certificate
+}
+
+//loadSecretCertificate returns the certificate of settings, the certificate or key of which references a secret.
+func loadSecretCertificate(settings *SessionSettings, certificateFile, privateKeyFile string) (*secretCertificate, error) {
+	resolver, err := newSecretResolver(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &secretCertificate{resolver: resolver, certificateFile: certificateFile, privateKeyFile: privateKeyFile}
+	if _, err := c.load(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+//load returns the certificate, parsed again if the secrets have changed since last loaded.
+func (c *secretCertificate) load() (*tls.Certificate, error) {
+	certificatePEM, err := c.pem(c.certificateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKeyPEM, err := c.pem(c.privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.certificate != nil && certificatePEM == c.certificatePEM && privateKeyPEM == c.privateKeyPEM {
+		return c.certificate, nil
+	}
+
+	certificate, err := tls.X509KeyPair([]byte(certificatePEM), []byte(privateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load %v: %v", config.SocketCertificateFile, err)
+	}
+
+	c.certificate, c.certificatePEM, c.privateKeyPEM = &certificate, certificatePEM, privateKeyPEM
+	return c.certificate, nil
+}
+
+//pem returns the PEM content of the secret referenced by setting, or of the file it names.
+func (c *secretCertificate) pem(setting string) (string, error) {
+	if isSecretReference(setting) {
+		return c.resolver.resolve(setting)
+	}
+
+	pem, err := ioutil.ReadFile(setting)
+	if err != nil {
+		return "", fmt.Errorf("cannot load %v: %v", config.SocketCertificateFile, err)
+	}
+
+	return string(pem), nil
+}
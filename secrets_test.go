@@ -0,0 +1,217 @@
+package quickfix
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+)
+
+//fakeSecrets is a SecretsProvider of the secrets set, counting the secrets read.
+type fakeSecrets struct {
+	lock    sync.Mutex
+	secrets map[string]string
+	reads   int
+	err     error
+}
+
+func (f *fakeSecrets) Secret(ctx context.Context, path string) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.reads++
+	if f.err != nil {
+		return "", f.err
+	}
+
+	secret, ok := f.secrets[path]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+
+	return secret, nil
+}
+
+func (f *fakeSecrets) set(path, secret string, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.secrets[path] = secret
+	f.err = err
+}
+
+var testSecrets = &fakeSecrets{secrets: make(map[string]string)}
+
+func init() {
+	RegisterSecretsProvider("test", func(settings *SessionSettings) (SecretsProvider, error) { return testSecrets, nil })
+}
+
+func TestRegisterSecretsProvider_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic registering test twice")
+		}
+	}()
+
+	RegisterSecretsProvider("test", nil)
+}
+
+func TestNewSecretResolver(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set(config.Password, "hunter2")
+	if r, err := newSecretResolver(settings); r != nil || err != nil {
+		t.Errorf("Expected no resolver without secrets, got %v %v", r, err)
+	}
+
+	settings.Set(config.Password, "secret://fix/venueA/password")
+	if _, err := newSecretResolver(settings); err == nil {
+		t.Error("Expected error without SecretsProviderType")
+	}
+
+	settings.Set(config.SecretsProviderType, "unknown")
+	if _, err := newSecretResolver(settings); err == nil {
+		t.Error("Expected error with unknown SecretsProviderType")
+	}
+
+	settings.Set(config.SecretsProviderType, "test")
+	settings.Set(config.SecretsCacheTTL, "-1")
+	if _, err := newSecretResolver(settings); err == nil {
+		t.Error("Expected error with negative SecretsCacheTTL")
+	}
+
+	settings.Set(config.SecretsCacheTTL, "60")
+	r, err := newSecretResolver(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.ttl.Seconds() != 60 {
+		t.Errorf("Expected TTL of 60s, got %v", r.ttl)
+	}
+}
+
+func TestSecretResolver_Resolve(t *testing.T) {
+	provider := &fakeSecrets{secrets: map[string]string{"fix/venueA/password": "hunter2"}}
+	r := &secretResolver{provider: provider, ttl: defaultSecretsCacheTTL, cache: make(map[string]cachedSecret)}
+
+	credentials, err := r.resolveCredentials(Credentials{Username: "client", Password: "secret://fix/venueA/password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if credentials.Username != "client" || credentials.Password != "hunter2" {
+		t.Errorf("Expected client hunter2, got %v %v", credentials.Username, credentials.Password)
+	}
+
+	provider.set("fix/venueA/password", "swordfish", nil)
+	if secret, _ := r.resolve("secret://fix/venueA/password"); secret != "hunter2" || provider.reads != 1 {
+		t.Errorf("Expected cached hunter2 after 1 read, got %v after %v", secret, provider.reads)
+	}
+
+	r.invalidate()
+	if secret, _ := r.resolve("secret://fix/venueA/password"); secret != "swordfish" {
+		t.Errorf("Expected rotated swordfish after invalidate, got %v", secret)
+	}
+
+	provider.set("fix/venueA/password", "swordfish", errors.New("unavailable"))
+	r.invalidate()
+	if secret, err := r.resolve("secret://fix/venueA/password"); err != nil || secret != "swordfish" {
+		t.Errorf("Expected cached swordfish while unavailable, got %v %v", secret, err)
+	}
+
+	if _, err := r.resolve("secret://fix/venueB/password"); err == nil {
+		t.Error("Expected error reading uncached secret while unavailable")
+	}
+
+	var none *secretResolver
+	if value, err := none.resolve("hunter2"); err != nil || value != "hunter2" {
+		t.Errorf("Expected hunter2 without resolver, got %v %v", value, err)
+	}
+	none.invalidate()
+}
+
+func TestSession_LogonCredentialsSecrets(t *testing.T) {
+	testSecrets.set("fix/logon/password", "hunter2", nil)
+
+	settings := NewSessionSettings()
+	settings.Set(config.Username, "client")
+	settings.Set(config.Password, "secret://fix/logon/password")
+	settings.Set(config.SecretsProviderType, "test")
+
+	s := &Session{}
+	s.credentials = newCredentials(settings)
+	var err error
+	if s.secrets, err = newSecretResolver(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials, err := s.logonCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if credentials.Username != "client" || credentials.Password != "hunter2" {
+		t.Errorf("Expected client hunter2, got %v %v", credentials.Username, credentials.Password)
+	}
+}
+
+func TestLoadTLSConfig_SecretCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	readPEM := func(file string) string {
+		pem, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(pem)
+	}
+
+	certificateFile, keyFile := writeTestCertificate(t, dir, "original")
+	testSecrets.set("fix/tls/key", readPEM(keyFile), nil)
+
+	settings := NewSessionSettings()
+	settings.Set(config.SocketCertificateFile, certificateFile)
+	settings.Set(config.SocketPrivateKeyFile, "secret://fix/tls/key")
+	settings.Set(config.SecretsProviderType, "test")
+	settings.Set(config.SecretsCacheTTL, "0")
+
+	tlsConfig, err := loadTLSConfig(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject := func() string {
+		certificate, err := tlsConfig.GetCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed.Subject.CommonName
+	}
+
+	if name := subject(); name != "original" {
+		t.Errorf("Expected original certificate, got %v", name)
+	}
+
+	rotatedCertificateFile, rotatedKeyFile := writeTestCertificate(t, dir, "rotated")
+	testSecrets.set("fix/tls/key", readPEM(rotatedKeyFile), nil)
+	if err := ioutil.WriteFile(certificateFile, []byte(readPEM(rotatedCertificateFile)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if name := subject(); name != "rotated" {
+		t.Errorf("Expected rotated certificate, got %v", name)
+	}
+}
@@ -0,0 +1,218 @@
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+//sendQueueOverflow determines how a session handles a message sent while not logged on with its send queue full.
+type sendQueueOverflow int
+
+const (
+	//sendQueueOverflowError fails the send with ErrSendQueueFull.
+	sendQueueOverflowError sendQueueOverflow = iota
+
+	//sendQueueOverflowBlock waits until the session logs on and the queue is flushed.
+	sendQueueOverflowBlock
+
+	//sendQueueOverflowDropOldest discards the oldest queued message.
+	sendQueueOverflowDropOldest
+)
+
+//parseSendQueueOverflow maps the SendQueueOverflow setting to a sendQueueOverflow.
+func parseSendQueueOverflow(setting string) (sendQueueOverflow, error) {
+	switch setting {
+	case "Error":
+		return sendQueueOverflowError, nil
+	case "Block":
+		return sendQueueOverflowBlock, nil
+	case "DropOldest":
+		return sendQueueOverflowDropOldest, nil
+	}
+
+	return sendQueueOverflowError, fmt.Errorf("invalid SendQueueOverflow %v, expected Error, Block, or DropOldest", setting)
+}
+
+//canSendApp returns true if application messages may be sent in state.
+func canSendApp(state SessionState) bool {
+	switch state {
+	case StateLoggedOn, StateAwaitingResend, StateTestRequestSent:
+		return true
+	}
+
+	return false
+}
+
+//sendOrQueue sends msg if the session is logged on, subject to the throttle, otherwise queues msg to be sent once logged on.
+//Safe to call outside the session goroutine.
+func (s *Session) sendOrQueue(msg MessageBuilder) error {
+	return s.sendOrQueueCtx(context.Background(), msg)
+}
+
+//sendOrQueueCtx is sendOrQueue, abandoning the send if ctx is done while waiting for space in the send queue, the throttle, or the connection.
+func (s *Session) sendOrQueueCtx(ctx context.Context, msg MessageBuilder) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+
+	throttled := false
+	for {
+		if !s.loggedOn {
+			if s.maxSendQueueDepth == 0 || len(s.sendQueue) < s.maxSendQueueDepth {
+				s.sendQueue = append(s.sendQueue, msg)
+				return nil
+			}
+
+			switch s.sendQueueOverflow {
+			case sendQueueOverflowBlock:
+				if err := s.waitSendQueueFlushed(ctx); err != nil {
+					return err
+				}
+
+			case sendQueueOverflowDropOldest:
+				logEventf(s.log, LogLevelWarn, LogCategoryTransport, "Send queue full, dropping oldest message")
+				s.sendQueue = s.sendQueue[1:]
+
+			default:
+				return ErrSendQueueFull
+			}
+			continue
+		}
+
+		if s.throttle == nil {
+			return s.sendLockedCtx(ctx, msg)
+		}
+
+		//messages already delayed are sent first
+		if len(s.delayed) > 0 {
+			s.onThrottled()
+			s.delayed = append(s.delayed, msg)
+			return nil
+		}
+
+		wait := s.throttle.reserve(s.now())
+		if wait == 0 {
+			return s.sendLockedCtx(ctx, msg)
+		}
+
+		if !throttled {
+			throttled = true
+			s.onThrottled()
+		}
+
+		switch s.throttle.policy {
+		case throttleReject:
+			return ErrThrottled
+
+		case throttleBlock:
+			s.sendLock.Unlock()
+			select {
+			case <-s.sessionClock().After(wait):
+			case <-ctx.Done():
+			}
+			s.sendLock.Lock()
+
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+		default:
+			s.delayed = append(s.delayed, msg)
+			s.scheduleDelayed(wait)
+			return nil
+		}
+	}
+}
+
+//waitSendQueueFlushed waits, with sendLock released, until the send queue is flushed or ctx is done, returning the error of ctx.
+//Called with sendLock held.
+func (s *Session) waitSendQueueFlushed(ctx context.Context) error {
+	if s.sendQueueFlushed == nil {
+		s.sendQueueFlushed = make(chan interface{})
+	}
+	flushed := s.sendQueueFlushed
+
+	s.sendLock.Unlock()
+	defer s.sendLock.Lock()
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//updateSendQueue flushes the send queue, in order, when the session enters a state in which application messages may be sent.
+//Messages delayed by the throttle are queued again when the session leaves such a state.
+func (s *Session) updateSendQueue(to SessionState) {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+
+	loggedOn := canSendApp(to)
+	if loggedOn == s.loggedOn {
+		return
+	}
+	s.loggedOn = loggedOn
+
+	if !loggedOn {
+		//the send queue is empty while logged on
+		s.sendQueue, s.delayed = s.delayed, nil
+		return
+	}
+
+	if len(s.sendQueue) > 0 {
+		logEventf(s.log, LogLevelInfo, LogCategoryTransport, "Sending %v queued messages", len(s.sendQueue))
+	}
+
+	s.delayed = append(s.delayed, s.sendQueue...)
+	s.sendQueue = nil
+	s.sendDelayedLocked()
+
+	//wake the sends waiting for space in the send queue
+	if s.sendQueueFlushed != nil {
+		close(s.sendQueueFlushed)
+		s.sendQueueFlushed = nil
+	}
+}
+
+//sendDelayed sends the delayed messages the throttle allows, scheduling the rest.
+func (s *Session) sendDelayed() {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+
+	s.sendDelayedLocked()
+}
+
+//sendDelayedLocked is sendDelayed with sendLock held.
+func (s *Session) sendDelayedLocked() {
+	for s.loggedOn && len(s.delayed) > 0 {
+		if s.throttle != nil {
+			if wait := s.throttle.reserve(s.now()); wait > 0 {
+				s.scheduleDelayed(wait)
+				return
+			}
+		}
+
+		msg := s.delayed[0]
+		s.delayed = s.delayed[1:]
+
+		if err := s.sendLocked(msg); err != nil {
+			logEventf(s.log, LogLevelWarn, LogCategoryTransport, "Queued message not sent: %v", err)
+		}
+	}
+}
+
+//scheduleDelayed sends the delayed messages after wait.
+func (s *Session) scheduleDelayed(wait time.Duration) {
+	if s.delayTimer == nil {
+		s.delayTimer = s.sessionClock().AfterFunc(wait, s.sendDelayed)
+		return
+	}
+
+	s.delayTimer.Reset(wait)
+}
@@ -0,0 +1,207 @@
+package quickfix
+
+import (
+	"context"
+	"errors"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func newTestSendQueueSession(maxDepth int, overflow sendQueueOverflow) *Session {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	s := &Session{store: store, application: &TestClient{}, log: nullLog{}, messageOut: make(chan []byte, 10), header: newHeaderTemplate(SessionID{})}
+	s.stateTimer = eventTimer{Task: func() {}}
+	s.maxSendQueueDepth = maxDepth
+	s.sendQueueOverflow = overflow
+
+	return s
+}
+
+func newSendQueueTestMessage(clOrdID string) MessageBuilder {
+	builder := getBuilder()
+	builder.Body().Set(fix.NewStringField(tag.ClOrdID, clOrdID))
+	return builder
+}
+
+//checkSent checks the messages sent by s have the ClOrdIDs expected, in order with consecutive MsgSeqNums.
+func checkSent(t *testing.T, s *Session, expected ...string) {
+	for n, clOrdID := range expected {
+		select {
+		case msgBytes := <-s.messageOut:
+			msg, err := parseMessage(msgBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			seqNum, sent := new(fix.IntValue), new(fix.StringValue)
+			msg.Header.GetField(tag.MsgSeqNum, seqNum)
+			msg.Body.GetField(tag.ClOrdID, sent)
+
+			if sent.Value != clOrdID || seqNum.Value != n+1 {
+				t.Errorf("Expected %v with MsgSeqNum %v got %v with MsgSeqNum %v", clOrdID, n+1, sent.Value, seqNum.Value)
+			}
+		default:
+			t.Fatalf("Expected %v to be sent", clOrdID)
+		}
+	}
+
+	if len(s.messageOut) > 0 {
+		t.Errorf("Unexpected %v messages sent", len(s.messageOut))
+	}
+}
+
+func TestSession_SendQueueFlushedOnLogon(t *testing.T) {
+	s := newTestSendQueueSession(0, sendQueueOverflowError)
+
+	for _, clOrdID := range []string{"1", "2", "3"} {
+		if err := s.sendOrQueue(newSendQueueTestMessage(clOrdID)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	checkSent(t, s)
+
+	s.updateSendQueue(StateAwaitingLogon)
+	checkSent(t, s)
+
+	s.updateSendQueue(StateLoggedOn)
+	checkSent(t, s, "1", "2", "3")
+
+	s.sendOrQueue(newSendQueueTestMessage("4"))
+	if len(s.messageOut) != 1 || len(s.sendQueue) != 0 {
+		t.Error("Expected message sent while logged on to be sent immediately")
+	}
+	<-s.messageOut
+
+	s.updateSendQueue(StateDisconnected)
+	s.sendOrQueue(newSendQueueTestMessage("5"))
+	if len(s.messageOut) != 0 || len(s.sendQueue) != 1 {
+		t.Error("Expected message sent after disconnect to be queued")
+	}
+}
+
+func TestSession_SendQueueOverflow(t *testing.T) {
+	s := newTestSendQueueSession(2, sendQueueOverflowError)
+	s.sendOrQueue(newSendQueueTestMessage("1"))
+	s.sendOrQueue(newSendQueueTestMessage("2"))
+	if err := s.sendOrQueue(newSendQueueTestMessage("3")); err != ErrSendQueueFull {
+		t.Errorf("Expected ErrSendQueueFull got %v", err)
+	}
+	s.updateSendQueue(StateLoggedOn)
+	checkSent(t, s, "1", "2")
+
+	s = newTestSendQueueSession(2, sendQueueOverflowDropOldest)
+	for _, clOrdID := range []string{"1", "2", "3"} {
+		if err := s.sendOrQueue(newSendQueueTestMessage(clOrdID)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	s.updateSendQueue(StateLoggedOn)
+	checkSent(t, s, "2", "3")
+
+	blocking := newTestSendQueueSession(2, sendQueueOverflowBlock)
+	blocking.sendOrQueue(newSendQueueTestMessage("1"))
+	blocking.sendOrQueue(newSendQueueTestMessage("2"))
+
+	sent := make(chan error)
+	go func() {
+		sent <- blocking.sendOrQueue(newSendQueueTestMessage("3"))
+	}()
+
+	select {
+	case <-sent:
+		t.Fatal("Expected send to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	blocking.updateSendQueue(StateLoggedOn)
+	if err := <-sent; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkSent(t, blocking, "1", "2", "3")
+}
+
+//failingStore fails to save messages.
+type failingStore struct {
+	MessageStore
+}
+
+func (failingStore) TrySaveMessage(seqNum int, msg []byte) error {
+	return errors.New("disk full")
+}
+
+func TestSession_SendOrQueueCtx(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	s := newTestSendQueueSession(1, sendQueueOverflowBlock)
+	s.sendOrQueue(newSendQueueTestMessage("1"))
+	if err := s.sendOrQueueCtx(ctx, newSendQueueTestMessage("2")); err != context.DeadlineExceeded {
+		t.Errorf("Expected the send waiting for the session to log on to fail with DeadlineExceeded got %v", err)
+	}
+
+	if err := s.sendOrQueueCtx(ctx, newSendQueueTestMessage("3")); err != context.DeadlineExceeded {
+		t.Errorf("Expected send with expired context to fail got %v", err)
+	}
+
+	s.updateSendQueue(StateLoggedOn)
+	checkSent(t, s, "1")
+
+	//the connection does not accept the message
+	s.messageOut = make(chan []byte)
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.sendOrQueueCtx(ctx, newSendQueueTestMessage("4")); err != context.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded got %v", err)
+	}
+
+	if s.store.NextSenderMsgSeqNum() != 2 {
+		t.Errorf("Expected abandoned message not to consume a seqnum, next is %v", s.store.NextSenderMsgSeqNum())
+	}
+
+	s.store = failingStore{s.store}
+	err := s.sendOrQueueCtx(context.Background(), newSendQueueTestMessage("5"))
+	if _, ok := err.(StoreError); !ok {
+		t.Errorf("Expected StoreError got %v", err)
+	}
+}
+
+func TestSession_SendOrQueueCtxCancelled(t *testing.T) {
+	s := newTestSendQueueSession(1, sendQueueOverflowBlock)
+	s.sendOrQueue(newSendQueueTestMessage("1"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sent := make(chan error)
+	go func() { sent <- s.sendOrQueueCtx(ctx, newSendQueueTestMessage("2")) }()
+
+	select {
+	case err := <-sent:
+		t.Fatalf("Expected the send to wait for space in the send queue, got %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-sent; err != context.Canceled {
+		t.Errorf("Expected Canceled got %v", err)
+	}
+
+	s.updateSendQueue(StateLoggedOn)
+	checkSent(t, s, "1")
+
+	//sends with a cancellable context do not leave goroutines behind
+	goroutines := runtime.NumGoroutine()
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := s.sendOrQueueCtx(ctx, newSendQueueTestMessage("3")); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		<-s.messageOut
+	}
+
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Errorf("Expected at most %v goroutines, got %v", goroutines, n)
+	}
+}
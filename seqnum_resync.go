@@ -0,0 +1,69 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"time"
+)
+
+//defaultSeqNumResetGraceWindow is the time after logon a MsgSeqNum too low is adopted with SeqNumTooLowPolicy set to Reset.
+const defaultSeqNumResetGraceWindow = 60 * time.Second
+
+//seqNumTooLowPolicy determines how a session handles a message with a MsgSeqNum lower than expected and without PossDupFlag.
+type seqNumTooLowPolicy int
+
+const (
+	//seqNumTooLowStrict logs out, as required by the FIX session protocol.
+	seqNumTooLowStrict seqNumTooLowPolicy = iota
+
+	//seqNumTooLowResync adopts the MsgSeqNum of the counterparty if approved by the SeqNumResyncApprover of the Application, otherwise logs out.
+	seqNumTooLowResync
+
+	//seqNumTooLowReset adopts the MsgSeqNum of the counterparty within SeqNumResetGraceWindow of logon, otherwise logs out.
+	seqNumTooLowReset
+)
+
+//parseSeqNumTooLowPolicy maps the SeqNumTooLowPolicy setting to a seqNumTooLowPolicy.
+func parseSeqNumTooLowPolicy(setting string) (seqNumTooLowPolicy, error) {
+	switch setting {
+	case "Strict":
+		return seqNumTooLowStrict, nil
+	case "Resync":
+		return seqNumTooLowResync, nil
+	case "Reset":
+		return seqNumTooLowReset, nil
+	}
+
+	return seqNumTooLowStrict, fmt.Errorf("invalid SeqNumTooLowPolicy %v, expected Strict, Resync, or Reset", setting)
+}
+
+//resyncTargetTooLow applies the SeqNumTooLowPolicy to msg, received with a MsgSeqNum too low.
+//Returns true if the MsgSeqNum of msg is adopted as the next expected, msg is then processed as if in sequence.
+func (s *Session) resyncTargetTooLow(msg Message, tooLow targetTooLow) bool {
+	if s.seqNumTooLowPolicy == seqNumTooLowStrict {
+		return false
+	}
+
+	//a resent message is a duplicate, not a sequence mismatch
+	possDup := new(fix.BooleanValue)
+	if msg.Header.GetField(tag.PossDupFlag, possDup) == nil && possDup.Value {
+		return false
+	}
+
+	switch s.seqNumTooLowPolicy {
+	case seqNumTooLowResync:
+		if approver, ok := s.application.(SeqNumResyncApprover); ok && !approver.ApproveSeqNumResync(s.sessionID, tooLow.ExpectedTarget, tooLow.ReceivedTarget) {
+			return false
+		}
+
+	case seqNumTooLowReset:
+		if s.now().Sub(s.logonTime) > s.seqNumResetGraceWindow {
+			return false
+		}
+	}
+
+	logEventf(s.log, LogLevelWarn, LogCategorySession, "MsgSeqNum too low, expecting %d but received %d, resynchronizing to %d", tooLow.ExpectedTarget, tooLow.ReceivedTarget, tooLow.ReceivedTarget)
+	s.store.SetNextTargetMsgSeqNum(tooLow.ReceivedTarget)
+	return true
+}
@@ -0,0 +1,55 @@
+package quickfix
+
+import (
+	"testing"
+	"time"
+)
+
+//resyncApprover approves resynchronization if approve is set.
+type resyncApprover struct {
+	TestClient
+	approve bool
+}
+
+func (a *resyncApprover) ApproveSeqNumResync(sessionID SessionID, expected, received int) bool {
+	return a.approve
+}
+
+func TestInSession_SeqNumTooLowPolicy(t *testing.T) {
+	var tests = []struct {
+		name            string
+		policy          seqNumTooLowPolicy
+		app             Application
+		sinceLogon      time.Duration
+		expectedState   sessionState
+		expectedNextSeq int
+	}{
+		{"strict", seqNumTooLowStrict, &TestClient{}, 0, logoutState{}, 5},
+		{"resync", seqNumTooLowResync, &TestClient{}, time.Hour, inSession{}, 4},
+		{"resync approved", seqNumTooLowResync, &resyncApprover{approve: true}, 0, inSession{}, 4},
+		{"resync denied", seqNumTooLowResync, &resyncApprover{approve: false}, 0, logoutState{}, 5},
+		{"reset in grace window", seqNumTooLowReset, &TestClient{}, 10 * time.Second, inSession{}, 4},
+		{"reset after grace window", seqNumTooLowReset, &TestClient{}, 2 * time.Minute, logoutState{}, 5},
+	}
+
+	for _, test := range tests {
+		session := newTestAdminSession(test.app)
+		session.seqNumTooLowPolicy = test.policy
+		session.seqNumResetGraceWindow = defaultSeqNumResetGraceWindow
+		session.logonTime = time.Now().Add(-test.sinceLogon)
+		session.store.SetNextTargetMsgSeqNum(5)
+
+		nextState := inSession{}.FixMsgIn(session, newTestAdminMessage("0", 3))
+		if nextState != test.expectedState {
+			t.Errorf("%v: expected state %T got %T", test.name, test.expectedState, nextState)
+		}
+
+		if next := session.store.NextTargetMsgSeqNum(); next != test.expectedNextSeq {
+			t.Errorf("%v: expected next target %v got %v", test.name, test.expectedNextSeq, next)
+		}
+	}
+
+	if _, err := parseSeqNumTooLowPolicy("Lenient"); err == nil {
+		t.Error("Expected error for invalid SeqNumTooLowPolicy")
+	}
+}
@@ -1,12 +1,16 @@
 package quickfix
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/quickfixgo/quickfix/config"
 	"github.com/quickfixgo/quickfix/datadictionary"
 	"github.com/quickfixgo/quickfix/fix"
 	"github.com/quickfixgo/quickfix/fix/field"
 	"github.com/quickfixgo/quickfix/fix/tag"
+	"net"
+	"sync"
 	"time"
 )
 
@@ -14,8 +18,31 @@ import (
 type Session struct {
 	store MessageStore
 
+	//storeMetrics records the operations of store, read outside the session goroutine
+	storeMetrics storeMetrics
+
+	//trafficStats records the messages received and sent, read outside the session goroutine
+	trafficStats trafficStats
+
+	//anomalies counts the events reported to AnomalyListeners once repeated
+	anomalies anomalyDetector
+
+	//persistReceivedMessages saves the messages received to a ReceivedMessageStore
+	persistReceivedMessages bool
+	//auditIndex indexes the messages sent and received by the tags of AuditIndexTags, nil without
+	auditIndex *auditIndex
+
+	//latency records the latencies of the messages received and sent, nil without RecordLatency
+	latency *latencyRecorder
+	//messageTimestamps records the MessageTimestamps of the messages received
+	messageTimestamps bool
+	//debug tracks the goroutines and connection of the session for DebugState
+	debug sessionDebug
+
 	log       Log
 	sessionID SessionID
+	//header is the header of the messages sent, formatted once from sessionID
+	header *headerTemplate
 
 	messageOut chan []byte
 	toSend     chan MessageBuilder
@@ -23,16 +50,119 @@ type Session struct {
 	sessionEvent            chan event
 	application             Application
 	currentState            sessionState
+	state                   SessionState
+	recovery                RecoveryState
 	stateTimer              eventTimer
 	peerTimer               eventTimer
+	logoutTimer             eventTimer
 	messageStash            map[int]Message
 	dataDictionary          *datadictionary.DataDictionary
 	transportDataDictionary *datadictionary.DataDictionary
 	appDataDictionary       *datadictionary.DataDictionary
-	resetOnLogon            bool
-	initiateLogon           bool
-	heartBtInt              int
-	heartBeatTimeout        time.Duration
+	//appDataDictionaries are keyed by ApplVerID, for FIXT sessions with several application versions
+	appDataDictionaries map[string]*datadictionary.DataDictionary
+	//counterpartyDataDictionary validates outbound messages, for FIXT sessions it is the counterparty application dictionary
+	counterpartyDataDictionary *datadictionary.DataDictionary
+	resetOnLogon               bool
+	resetOnLogout              bool
+	resetOnDisconnect          bool
+	initiateLogon              bool
+	heartBtInt                 int
+	heartBeatTimeout           time.Duration
+	enforceHeartBtInt          bool
+	testRequestDelayMultiplier float64
+	heartBeatTimeoutMultiplier float64
+	skipLatencyCheck           bool
+	maxLatency                 time.Duration
+	continueOnMaxLatency       bool
+	duplicateTagPolicy         duplicateTagPolicy
+	maxMessageSize             int
+	clock                      Clock
+
+	//reloadLock guards the settings replaced by ReloadSettings while the session runs
+	reloadLock sync.RWMutex
+	schedule   *sessionSchedule
+	//logFilter filters the events of log by LogEventLevel and LogEventCategories
+	logFilter *logFilter
+
+	//traffic is read by monitoring outside the session goroutine
+	trafficLock sync.RWMutex
+	traffic     trafficState
+
+	//sendLock guards sending, and the queue of application messages sent while not logged on
+	sendLock          sync.Mutex
+	loggedOn          bool
+	sendQueue         []MessageBuilder
+	sendQueueFlushed  chan interface{}
+	maxSendQueueDepth int
+	sendQueueOverflow sendQueueOverflow
+	//throttle limits the rate application messages are sent, those over the limit may be delayed
+	throttle   *throttle
+	delayed    []MessageBuilder
+	delayTimer Timer
+	//outbound queues the messages sent by the Application to be sent by one goroutine, nil without OutboundQueueDepth
+	outbound *outboundQueue
+
+	//deliverPossDup delivers messages resent by the counterparty to the Application if already received
+	deliverPossDup bool
+
+	persistMessages persistMessages
+
+	//conn is the connection of an accepted session, closed to replace it with a duplicate logon
+	connLock             sync.Mutex
+	conn                 net.Conn
+	duplicateLogonPolicy duplicateLogonPolicy
+
+	//wireCapture records the bytes of the connections of the session, nil without WireCapturePath
+	wireCapture *wireCapture
+
+	//socketOptions tune the connections of the session, nil if not configured
+	socketOptions *socketOptions
+
+	//writeLimits protect the session from a counterparty not reading the messages sent
+	writeLimits writeLimits
+	//writeBatching coalesces the messages queued while the connection is written into single writes
+	writeBatching writeBatching
+
+	//workers run the session with ThreadingModel=Pool, nil to run it on a goroutine of its own
+	workers *workerPool
+	//pooled is the run of the connection of the session by workers, nil while not connected
+	pooledLock sync.Mutex
+	pooled     *pooledRun
+
+	//resendRequestChunkSize bounds the range of each ResendRequest sent, 0 for no limit
+	resendRequestChunkSize int
+	//resendChunkEnd is the end of the ResendRequest chunk in progress, 0 if the last chunk was requested
+	resendChunkEnd int
+
+	//poisonMessageThreshold is the number of failures to parse the next message expected before it is quarantined, 0 to never quarantine
+	poisonMessageThreshold int
+	poisonMessagePolicy    poisonMessagePolicy
+	poisonSeqNum           int
+	poisonFailures         int
+
+	seqNumTooLowPolicy     seqNumTooLowPolicy
+	seqNumResetGraceWindow time.Duration
+	//logonTime is when the last Logon was received
+	logonTime time.Time
+
+	//interceptors are called before the Application callbacks, after global interceptors
+	interceptors interceptorChain
+
+	//stop is closed when the session is removed
+	stop chan interface{}
+
+	//shutdown logs out the session, see requestShutdown
+	shutdown chan bool
+
+	//sendNextExpectedMsgSeqNum sends NextExpectedMsgSeqNum (789) on logon and resends according to the counterparty's
+	sendNextExpectedMsgSeqNum bool
+
+	credentials Credentials
+	//secrets resolves the credentials and keys referencing secrets, nil if none do
+	secrets *secretResolver
+	//newPassword is sent on the next logon to change the password
+	newPassword string
 
 	//required on logon for FIX.T.1 messages
 	defaultApplVerID       string
@@ -47,38 +177,66 @@ func (s *Session) TargetDefaultApplicationVersionID() string {
 
 //Creates Session, associates with internal session registry
 func createSession(sessionID SessionID, storeFactory MessageStoreFactory, settings *SessionSettings, logFactory LogFactory, application Application) error {
-	session := &Session{sessionID: sessionID}
+	session := &Session{sessionID: sessionID, header: newHeaderTemplate(sessionID)}
 
 	if sessionID.BeginString == fix.BeginString_FIXT11 {
 		defaultApplVerID, err := settings.Setting(config.DefaultApplVerID)
 		if err != nil {
 			return requiredConfigurationMissing(config.DefaultApplVerID)
 		}
-		session.defaultApplVerID = defaultApplVerID
-	}
 
-	if dataDictionaryPath, err := settings.Setting(config.DataDictionary); err == nil {
-		if session.dataDictionary, err = datadictionary.Parse(dataDictionaryPath); err != nil {
-			return err
+		if session.defaultApplVerID, err = parseApplVerID(defaultApplVerID); err != nil {
+			return fmt.Errorf("invalid DefaultApplVerID: %v", err)
 		}
 	}
 
-	if transportDataDictionaryPath, err := settings.Setting(config.TransportDataDictionary); err == nil {
-		if session.transportDataDictionary, err = datadictionary.Parse(transportDataDictionaryPath); err != nil {
+	var err error
+	if session.dataDictionary, err = settings.dataDictionary(config.DataDictionary); err != nil {
+		return err
+	}
+
+	if session.transportDataDictionary, err = settings.dataDictionary(config.TransportDataDictionary); err != nil {
+		return err
+	}
+
+	if session.appDataDictionary, err = settings.dataDictionary(config.AppDataDictionary); err != nil {
+		return err
+	}
+
+	if session.appDataDictionaries, err = parseAppDataDictionaries(settings); err != nil {
+		return err
+	}
+
+	hasAppDataDictionary := session.appDataDictionary != nil || len(session.appDataDictionaries) > 0
+	switch {
+	case session.transportDataDictionary != nil && !hasAppDataDictionary:
+		return requiredConfigurationMissing(config.AppDataDictionary)
+	case session.transportDataDictionary == nil && hasAppDataDictionary:
+		return requiredConfigurationMissing(config.TransportDataDictionary)
+	}
+
+	if session.counterpartyDataDictionary, err = settings.dataDictionary(config.CounterpartyDataDictionary); err != nil {
+		return err
+	}
+
+	if session.counterpartyDataDictionary != nil && sessionID.BeginString == fix.BeginString_FIXT11 && session.transportDataDictionary == nil {
+		return requiredConfigurationMissing(config.TransportDataDictionary)
+	}
+
+	if settings.HasSetting(config.ResetOnLogon) {
+		if session.resetOnLogon, err = settings.BoolSetting(config.ResetOnLogon); err != nil {
 			return err
 		}
 	}
 
-	//FIXME: tDictionary w/o appDictionary and vice versa should throw config error
-	if appDataDictionaryPath, err := settings.Setting(config.AppDataDictionary); err == nil {
-		if session.appDataDictionary, err = datadictionary.Parse(appDataDictionaryPath); err != nil {
+	if settings.HasSetting(config.ResetOnLogout) {
+		if session.resetOnLogout, err = settings.BoolSetting(config.ResetOnLogout); err != nil {
 			return err
 		}
 	}
 
-	var err error
-	if settings.HasSetting(config.ResetOnLogon) {
-		if session.resetOnLogon, err = settings.BoolSetting(config.ResetOnLogon); err != nil {
+	if settings.HasSetting(config.ResetOnDisconnect) {
+		if session.resetOnDisconnect, err = settings.BoolSetting(config.ResetOnDisconnect); err != nil {
 			return err
 		}
 	}
@@ -89,19 +247,308 @@ func createSession(sessionID SessionID, storeFactory MessageStoreFactory, settin
 		}
 	}
 
+	if settings.HasSetting(config.EnforceHeartBtInt) {
+		if session.enforceHeartBtInt, err = settings.BoolSetting(config.EnforceHeartBtInt); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.TestRequestDelayMultiplier) {
+		if session.testRequestDelayMultiplier, err = settings.FloatSetting(config.TestRequestDelayMultiplier); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.HeartBeatTimeoutMultiplier) {
+		if session.heartBeatTimeoutMultiplier, err = settings.FloatSetting(config.HeartBeatTimeoutMultiplier); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.CheckLatency) {
+		checkLatency, err := settings.BoolSetting(config.CheckLatency)
+		if err != nil {
+			return err
+		}
+		session.skipLatencyCheck = !checkLatency
+	}
+
+	if settings.HasSetting(config.MaxLatency) {
+		maxLatency, err := settings.IntSetting(config.MaxLatency)
+		if err != nil {
+			return err
+		}
+
+		if maxLatency <= 0 {
+			return fmt.Errorf("MaxLatency must be a positive number of seconds")
+		}
+		session.maxLatency = time.Duration(maxLatency) * time.Second
+	}
+
+	if settings.HasSetting(config.LogoutOnMaxLatency) {
+		logoutOnMaxLatency, err := settings.BoolSetting(config.LogoutOnMaxLatency)
+		if err != nil {
+			return err
+		}
+		session.continueOnMaxLatency = !logoutOnMaxLatency
+	}
+
+	if settings.HasSetting(config.DeliverPossDup) {
+		if session.deliverPossDup, err = settings.BoolSetting(config.DeliverPossDup); err != nil {
+			return err
+		}
+	}
+
+	if policy, err := settings.Setting(config.DuplicateLogonPolicy); err == nil {
+		if session.duplicateLogonPolicy, err = parseDuplicateLogonPolicy(policy); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.RecordLatency) {
+		recordLatency, err := settings.BoolSetting(config.RecordLatency)
+		if err != nil {
+			return err
+		}
+
+		if recordLatency {
+			session.latency = newLatencyRecorder(session.now())
+		}
+	}
+
+	if settings.HasSetting(config.PersistReceivedMessages) {
+		if session.persistReceivedMessages, err = settings.BoolSetting(config.PersistReceivedMessages); err != nil {
+			return err
+		}
+	}
+
+	if indexTags, err := settings.Setting(config.AuditIndexTags); err == nil {
+		tags, err := parseAuditIndexTags(indexTags)
+		if err != nil {
+			return err
+		}
+		session.auditIndex = newAuditIndex(tags)
+	}
+
+	if settings.HasSetting(config.MessageTimestamps) {
+		if session.messageTimestamps, err = settings.BoolSetting(config.MessageTimestamps); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.ResendRequestChunkSize) {
+		if session.resendRequestChunkSize, err = settings.IntSetting(config.ResendRequestChunkSize); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.ResendStormThreshold) {
+		if session.anomalies.resendStormThreshold, err = settings.IntSetting(config.ResendStormThreshold); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.ResendStormWindow) {
+		window, err := settings.IntSetting(config.ResendStormWindow)
+		if err != nil {
+			return err
+		}
+		session.anomalies.resendStormWindow = time.Duration(window) * time.Second
+	}
+
+	if settings.HasSetting(config.LogonRejectThreshold) {
+		if session.anomalies.logonRejectThreshold, err = settings.IntSetting(config.LogonRejectThreshold); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.PoisonMessageThreshold) {
+		if session.poisonMessageThreshold, err = settings.IntSetting(config.PoisonMessageThreshold); err != nil {
+			return err
+		}
+	}
+
+	if policy, err := settings.Setting(config.PoisonMessagePolicy); err == nil {
+		if session.poisonMessagePolicy, err = parsePoisonMessagePolicy(policy); err != nil {
+			return err
+		}
+	}
+
+	if policy, err := settings.Setting(config.SeqNumTooLowPolicy); err == nil {
+		if session.seqNumTooLowPolicy, err = parseSeqNumTooLowPolicy(policy); err != nil {
+			return err
+		}
+	}
+
+	session.seqNumResetGraceWindow = defaultSeqNumResetGraceWindow
+	if settings.HasSetting(config.SeqNumResetGraceWindow) {
+		graceWindow, err := settings.IntSetting(config.SeqNumResetGraceWindow)
+		if err != nil {
+			return err
+		}
+		session.seqNumResetGraceWindow = time.Duration(graceWindow) * time.Second
+	}
+
+	if policy, err := settings.Setting(config.PersistMessages); err == nil {
+		if session.persistMessages, err = parsePersistMessages(policy); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.MaxSendQueueDepth) {
+		if session.maxSendQueueDepth, err = settings.IntSetting(config.MaxSendQueueDepth); err != nil {
+			return err
+		}
+
+		if session.maxSendQueueDepth <= 0 {
+			return fmt.Errorf("MaxSendQueueDepth must be a positive number")
+		}
+	}
+
+	if settings.HasSetting(config.SendQueueOverflow) {
+		overflow, err := settings.Setting(config.SendQueueOverflow)
+		if err != nil {
+			return err
+		}
+
+		if session.sendQueueOverflow, err = parseSendQueueOverflow(overflow); err != nil {
+			return err
+		}
+	}
+
+	if settings.HasSetting(config.OutboundQueueDepth) {
+		depth, err := settings.IntSetting(config.OutboundQueueDepth)
+		if err != nil {
+			return err
+		}
+
+		if depth <= 0 {
+			return fmt.Errorf("%v must be a positive number", config.OutboundQueueDepth)
+		}
+
+		overflow := outboundQueueOverflowError
+		if settings.HasSetting(config.OutboundQueueOverflow) {
+			setting, err := settings.Setting(config.OutboundQueueOverflow)
+			if err != nil {
+				return err
+			}
+
+			if overflow, err = parseOutboundQueueOverflow(setting); err != nil {
+				return err
+			}
+		}
+
+		session.outbound = newOutboundQueue(depth, overflow, session.sendQueued)
+	}
+
+	if session.throttle, err = newThrottle(settings); err != nil {
+		return err
+	}
+
+	if settings.HasSetting(config.MaxMessageSize) {
+		if session.maxMessageSize, err = settings.IntSetting(config.MaxMessageSize); err != nil {
+			return err
+		}
+	}
+
+	if session.socketOptions, err = newSocketOptions(settings); err != nil {
+		return err
+	}
+
+	if session.writeLimits, err = newWriteLimits(settings); err != nil {
+		return err
+	}
+
+	if session.writeBatching, err = newWriteBatching(settings); err != nil {
+		return err
+	}
+
+	if session.workers, err = newSessionWorkers(settings); err != nil {
+		return err
+	}
+
+	if settings.HasSetting(config.SendNextExpectedMsgSeqNum) {
+		if session.sendNextExpectedMsgSeqNum, err = settings.BoolSetting(config.SendNextExpectedMsgSeqNum); err != nil {
+			return err
+		}
+	}
+
+	session.credentials = newCredentials(settings)
+	if session.secrets, err = newSecretResolver(settings); err != nil {
+		return err
+	}
+
+	if session.schedule, err = newSessionSchedule(settings); err != nil {
+		return err
+	}
+
+	if calendar, ok := application.(HolidayCalendar); ok && session.schedule != nil {
+		session.schedule.sessionID, session.schedule.holidays = sessionID, calendar
+	}
+
+	if policy, err := settings.Setting(config.DuplicateTagPolicy); err == nil {
+		if session.duplicateTagPolicy, err = parseDuplicateTagPolicy(policy); err != nil {
+			return err
+		}
+
+		//without a data dictionary the tags of repeating groups are unknown, each group of more than one entry would repeat its tags
+		if session.dataDictionary == nil && session.transportDataDictionary == nil {
+			return fmt.Errorf("DuplicateTagPolicy %v requires DataDictionary or TransportDataDictionary", policy)
+		}
+	}
+
+	if session.wireCapture, err = newWireCapture(sessionID, settings); err != nil {
+		return err
+	}
+
 	if session.log, err = logFactory.CreateSessionLog(session.sessionID); err != nil {
 		return err
 	}
 
+	if maskTags, err := settings.Setting(config.LogMaskTags); err == nil {
+		tags, err := parseLogMaskTags(maskTags)
+		if err != nil {
+			return err
+		}
+		session.log = maskingLog{Log: session.log, masker: fix.NewMasker(nil).Redact(tags...)}
+	}
+
+	filtered, err := newFilteredLog(session.log, settings)
+	if err != nil {
+		return err
+	}
+	session.log, session.logFilter = filtered, filtered.filter
+
+	if session.log, err = newSamplingLog(session.log, settings, session.now); err != nil {
+		return err
+	}
+
 	if session.store, err = storeFactory.Create(session.sessionID); err != nil {
 		return err
 	}
 
+	if _, ok := session.store.(ReceivedMessageStore); session.persistReceivedMessages && !ok {
+		return fmt.Errorf("%v requires a store saving messages received", config.PersistReceivedMessages)
+	}
+
 	session.toSend = make(chan MessageBuilder)
 	session.sessionEvent = make(chan event)
+	session.stop = make(chan interface{})
+	session.shutdown = make(chan bool, 1)
 	session.application = application
-	session.stateTimer = eventTimer{Task: func() { session.sessionEvent <- needHeartbeat }}
-	session.peerTimer = eventTimer{Task: func() { session.sessionEvent <- peerTimeout }}
+	if provider, ok := application.(ClockProvider); ok {
+		session.clock = provider.Clock(sessionID)
+	}
+
+	pooled := session.workers != nil
+	session.stateTimer = eventTimer{Task: func() { session.postEvent(needHeartbeat) }, clock: session.clock, pooled: pooled}
+	session.peerTimer = eventTimer{Task: func() { session.postEvent(peerTimeout) }, clock: session.clock, pooled: pooled}
+	//the logout timeout of sessions run on goroutines of their own is a timer for each logout
+	session.logoutTimer = eventTimer{Task: func() { session.postEvent(logoutTimeout) }, clock: session.clock, pooled: pooled}
+
+	if err = session.recover(); err != nil {
+		return err
+	}
 
 	application.OnCreate(session.sessionID)
 	sessions.newSession <- session
@@ -110,43 +557,44 @@ func createSession(sessionID SessionID, storeFactory MessageStoreFactory, settin
 }
 
 func (s *Session) initiate() (chan []byte, error) {
-	s.currentState = logonState{}
+	s.trafficStats.connect(s.now())
 	s.messageOut = make(chan []byte)
 	s.messageStash = make(map[int]Message)
 	s.initiateLogon = true
+	s.transition(logonState{}, nil)
 
 	return s.messageOut, nil
 }
 
 func (s *Session) accept() (chan []byte, error) {
-	s.currentState = logonState{}
+	s.trafficStats.connect(s.now())
 	s.messageOut = make(chan []byte)
 	s.messageStash = make(map[int]Message)
+	s.transition(logonState{}, nil)
 
 	return s.messageOut, nil
 }
 
+//onLogout is called once logout has been exchanged with the counterparty.
+func (s *Session) onLogout() {
+	if s.resetOnLogout {
+		logEventf(s.log, LogLevelInfo, LogCategorySession, "ResetOnLogout, resetting sequence numbers to 1")
+		s.store.Reset()
+	}
+}
+
 func (s *Session) onDisconnect() {
 	s.application.OnLogout(s.sessionID)
-	s.log.OnEvent("Disconnected")
+	logEventf(s.log, LogLevelInfo, LogCategoryTransport, "Disconnected")
 }
 
 func (s *Session) insertSendingTime(header MutableFieldMap) {
-	sendingTime := time.Now().UTC()
-
-	if s.sessionID.BeginString >= fix.BeginString_FIX42 {
-		header.Set(fix.NewUTCTimestampField(tag.SendingTime, sendingTime))
-	} else {
-		header.Set(fix.NewUTCTimestampFieldNoMillis(tag.SendingTime, sendingTime))
-	}
+	s.header.setSendingTime(header, s.now())
 }
 
+//fillDefaultHeader sets the BeginString, CompIDs and SendingTime of the session on builder.
 func (s *Session) fillDefaultHeader(builder MessageBuilder) {
-	builder.Header().Set(field.NewBeginString(s.sessionID.BeginString))
-	builder.Header().Set(field.NewSenderCompID(s.sessionID.SenderCompID))
-	builder.Header().Set(field.NewTargetCompID(s.sessionID.TargetCompID))
-
-	s.insertSendingTime(builder.Header())
+	s.header.fill(builder, s.now())
 }
 
 func (s *Session) resend(msg *Message) {
@@ -164,41 +612,190 @@ func (s *Session) resend(msg *Message) {
 	s.sendBytes(msg.rawMessage)
 }
 
-func (s *Session) send(builder MessageBuilder) {
-	s.fillDefaultHeader(builder)
+//allowResend returns false if the application vetoes the resend of msg.
+func (s *Session) allowResend(msg Message) bool {
+	filter, ok := s.application.(ResendFilter)
+	if !ok || filter.ToResend(msg, s.sessionID) {
+		return true
+	}
+
+	seqNum := new(fix.IntValue)
+	msg.Header.GetField(tag.MsgSeqNum, seqNum)
+	logEventf(s.log, LogLevelWarn, LogCategorySession, "Resend of message %d vetoed, sending GapFill", seqNum.Value)
+
+	return false
+}
+
+//send stamps the session header on builder and sends the message.
+//Returns an OutboundRejectError if an application message fails validation against the counterparty data dictionary.
+func (s *Session) send(builder MessageBuilder) error {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+
+	return s.sendLocked(builder)
+}
+
+//sendLocked is send with sendLock held.
+func (s *Session) sendLocked(builder MessageBuilder) error {
+	return s.sendLockedCtx(context.Background(), builder)
+}
+
+//sendLockedCtx is sendLocked, abandoning the send if ctx is done before the message is handed to the connection.
+//Returns a StoreError if the message cannot be stored.
+func (s *Session) sendLockedCtx(ctx context.Context, builder MessageBuilder) (err error) {
+	var sendTime time.Time
+	if s.latency != nil {
+		sendTime = s.now()
+	}
+
+	ctx, trace := s.startSendTrace(ctx)
+	if trace != nil {
+		defer func() { trace.End(builder.Header(), err) }()
+	}
+
+	s.header.fill(builder, s.now())
 
 	seqNum := s.store.NextSenderMsgSeqNum()
-	builder.Header().Set(fix.NewIntField(tag.MsgSeqNum, seqNum))
+	s.header.setMsgSeqNum(builder.Header(), seqNum)
 
 	msgType := new(fix.StringValue)
-	if builder.Header().GetField(tag.MsgType, msgType); fix.IsAdminMessageType(msgType.Value) {
+	builder.Header().GetField(tag.MsgType, msgType)
+	isAdmin := fix.IsAdminMessageType(msgType.Value)
+
+	stage := StageToApp
+	if isAdmin {
+		stage = StageToAdmin
+	}
+	endStage := startMessageStage(ctx, stage)
+
+	if err := s.interceptOutbound(builder, isAdmin); err != nil {
+		endStage(err)
+		logEventf(s.log, LogLevelWarn, LogCategorySession, "Outbound message vetoed: %v", err)
+		return err
+	}
+
+	if isAdmin {
 		s.application.ToAdmin(builder, s.sessionID)
 	} else {
 		s.application.ToApp(builder, s.sessionID)
 	}
-	if msgBytes, err := builder.Build(); err != nil {
+	endStage(nil)
+
+	endStage = startMessageStage(ctx, StageSerialize)
+	msgBytes, err := builder.Build()
+	if err != nil {
 		panic(err)
-	} else {
-		s.store.SaveMessage(seqNum, msgBytes)
-		s.sendBytes(msgBytes)
-		s.store.IncrNextSenderMsgSeqNum()
 	}
+
+	if reject := s.validateOutbound(msgBytes); reject != nil {
+		logEventf(s.log, LogLevelWarn, LogCategoryValidation, "Outbound message failed counterparty validation: %v", reject.Error())
+
+		//session level messages are required to maintain the session, send regardless
+		if !isAdmin {
+			endStage(reject)
+			return OutboundRejectError{reject}
+		}
+	}
+	endStage(nil)
+
+	if s.persistMessages.persists(isAdmin) {
+		endStage = startMessageStage(ctx, StageStore)
+		err := s.saveMessage(seqNum, msgBytes)
+		endStage(err)
+
+		if err != nil {
+			logEventf(s.log, LogLevelError, LogCategoryStore, "Cannot store message %v: %v", seqNum, err)
+			return StoreError{err}
+		}
+	}
+
+	//the stored message is replaced by the next message sent with the same seqnum
+	endStage = startMessageStage(ctx, StageWrite)
+	err = s.sendBytesCtx(ctx, msgBytes)
+	endStage(err)
+
+	if err != nil {
+		return err
+	}
+	s.store.IncrNextSenderMsgSeqNum()
+
+	if s.latency != nil {
+		s.latency.recordCallbackToWire(msgType.Value, s.now().Sub(sendTime))
+	}
+
+	return nil
+}
+
+//validateOutbound validates msgBytes against the counterparty data dictionary, if configured.
+func (s *Session) validateOutbound(msgBytes []byte) MessageRejectError {
+	if s.counterpartyDataDictionary == nil {
+		return nil
+	}
+
+	msg, err := parseMessage(msgBytes)
+	if err != nil {
+		return NewMessageRejectError(err.Error(), rejectReasonOther, nil)
+	}
+
+	if s.sessionID.BeginString != fix.BeginString_FIXT11 {
+		return validate(s.counterpartyDataDictionary, *msg)
+	}
+
+	var msgType field.MsgTypeField
+	msg.Header.Get(&msgType)
+	if fix.IsAdminMessageType(msgType.Value) {
+		return nil
+	}
+
+	return validateFIXTApp(s.transportDataDictionary, s.counterpartyDataDictionary, *msg)
 }
 
 func (s *Session) sendBytes(msg []byte) {
+	s.sendBytesCtx(context.Background(), msg)
+}
+
+//sendBytesCtx hands msg to the connection, returning the error of ctx if done first.
+//With ThreadingModel=Pool, msg is queued on the writer of the connection without waiting, dropped if not connected.
+func (s *Session) sendBytesCtx(ctx context.Context, msg []byte) error {
+	if s.workers != nil {
+		if r := s.pooledRun(); r != nil {
+			r.writer.post(msg)
+		}
+	} else {
+		select {
+		case s.messageOut <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 
+	now := s.now()
+	s.trafficStats.recordOut(len(msg), now)
 	s.log.OnOutgoing(string(msg))
-	s.messageOut <- msg
-	s.stateTimer.Reset(time.Duration(s.heartBeatTimeout))
+	s.onMessageSent(now)
+	return nil
 }
 
 func (s *Session) doTargetTooHigh(reject targetTooHigh) {
+	s.onAnomaly(AnomalySeqNumGap, reject.ReceivedTarget-reject.ExpectedTarget, nil)
+	s.sendResendRequest(reject.ExpectedTarget, reject.ReceivedTarget-1)
+	s.resendRequested(reject.ExpectedTarget, reject.ReceivedTarget-1)
+}
+
+//sendResendRequest requests the resend of messages from beginSeqNo to endSeqNo.
+//With ResendRequestChunkSize, no more than the chunk size is requested, the rest is requested by continueResend once received.
+func (s *Session) sendResendRequest(beginSeqNo, endSeqNo int) {
 	resend := NewMessageBuilder()
 	resend.Header().Set(field.NewMsgType("2"))
-	resend.Body().Set(field.NewBeginSeqNo(reject.ExpectedTarget))
+	resend.Body().Set(field.NewBeginSeqNo(beginSeqNo))
 
-	var endSeqNum = 0
-	if s.sessionID.BeginString < fix.BeginString_FIX42 {
+	s.resendChunkEnd = 0
+	if s.resendRequestChunkSize > 0 && endSeqNo-beginSeqNo+1 > s.resendRequestChunkSize {
+		s.resendChunkEnd = beginSeqNo + s.resendRequestChunkSize - 1
+	}
+
+	var endSeqNum = s.resendChunkEnd
+	if endSeqNum == 0 && s.sessionID.BeginString < fix.BeginString_FIX42 {
 		endSeqNum = 999999
 	}
 	resend.Body().Set(field.NewEndSeqNo(endSeqNum))
@@ -206,7 +803,30 @@ func (s *Session) doTargetTooHigh(reject targetTooHigh) {
 	s.send(resend)
 }
 
+//continueResend requests the next chunk of a resend once the messages of the last chunk requested are received.
+func (s *Session) continueResend() {
+	if s.resendChunkEnd == 0 || !s.resendPending() {
+		s.resendChunkEnd = 0
+		return
+	}
+
+	nextTarget := s.store.NextTargetMsgSeqNum()
+	if nextTarget <= s.resendChunkEnd {
+		return
+	}
+
+	if nextTarget > s.recovery.ResendEndSeqNo {
+		s.resendChunkEnd = 0
+		return
+	}
+
+	logEventf(s.log, LogLevelInfo, LogCategorySession, "Requesting next resend chunk FROM: %d TO: %d", nextTarget, s.recovery.ResendEndSeqNo)
+	s.sendResendRequest(nextTarget, s.recovery.ResendEndSeqNo)
+}
+
 func (s *Session) handleLogon(msg Message) error {
+	s.logonTime = s.now()
+
 	//Grab default app ver id from fixt.1.1 logon
 	if s.sessionID.BeginString == fix.BeginString_FIXT11 {
 		targetApplVerID := &field.DefaultApplVerIDField{}
@@ -219,22 +839,32 @@ func (s *Session) handleLogon(msg Message) error {
 	}
 
 	if !s.initiateLogon {
-		s.log.OnEvent("Received logon request")
-		if s.resetOnLogon {
+		logEventf(s.log, LogLevelInfo, LogCategorySession, "Received logon request")
+		if !s.isSessionTime(s.now()) {
+			return fmt.Errorf("Logon request received outside of session time")
+		}
+
+		s.checkSessionReset(s.now())
+
+		//reset is true if the sequence numbers are reset on this logon, by ResetOnLogon or as requested by the counterparty
+		reset := s.resetOnLogon
+		if reset {
 			s.store.Reset()
 		}
 
 		resetSeqNumFlag := new(fix.BooleanValue)
 		if err := msg.Body.GetField(tag.ResetSeqNumFlag, resetSeqNumFlag); err == nil {
 			if resetSeqNumFlag.Value {
-				s.log.OnEvent("Logon contains ResetSeqNumFlag=Y, resetting sequence numbers to 1")
+				logEventf(s.log, LogLevelInfo, LogCategorySession, "Logon contains ResetSeqNumFlag=Y, resetting sequence numbers to 1")
 				s.store.Reset()
+				reset = true
 			}
 		}
 
 		if err := s.verifyIgnoreSeqNumTooHigh(msg); err != nil {
-			return err
+			return s.refuseLogon(err)
 		}
+		s.onCounterpartyLogon(msg)
 
 		reply := NewMessageBuilder()
 		reply.Header().Set(field.NewMsgType("A"))
@@ -245,20 +875,48 @@ func (s *Session) handleLogon(msg Message) error {
 
 		heartBtInt := &field.HeartBtIntField{}
 		if err := msg.Body.Get(heartBtInt); err == nil {
-			s.heartBeatTimeout = time.Duration(heartBtInt.Value) * time.Second
+			heartBtInt.Value = s.negotiateHeartBtInt(heartBtInt)
+			s.setHeartBtInt(time.Duration(heartBtInt.Value) * time.Second)
 			reply.Body().Set(heartBtInt)
 		}
 
-		if resetSeqNumFlag.Value {
-			reply.Body().Set(field.NewResetSeqNumFlag(resetSeqNumFlag.Value))
+		if reset {
+			reply.Body().Set(field.NewResetSeqNumFlag(true))
 		}
 
 		if len(s.defaultApplVerID) > 0 {
 			reply.Body().Set(field.NewDefaultApplVerID(s.defaultApplVerID))
 		}
 
-		s.log.OnEvent("Responding to logon request")
-		s.send(reply)
+		s.setNextExpectedMsgSeqNum(reply, &msg)
+
+		logEventf(s.log, LogLevelInfo, LogCategorySession, "Responding to logon request")
+		if err := s.send(reply); err != nil {
+			return fmt.Errorf("Logon response not sent: %v", err)
+		}
+	} else {
+		s.checkSessionStatus(msg, true)
+
+		if reject := s.fromCallback(msg); reject != nil {
+			return s.refuseLogon(reject)
+		}
+		s.onCounterpartyLogon(msg)
+
+		heartBtInt := &field.HeartBtIntField{}
+		if err := msg.Body.Get(heartBtInt); err == nil && heartBtInt.Value != s.heartBtInt && !s.enforceHeartBtInt {
+			logEventf(s.log, LogLevelWarn, LogCategorySession, "Logon response HeartBtInt %d, using in place of %d", heartBtInt.Value, s.heartBtInt)
+			s.setHeartBtInt(time.Duration(heartBtInt.Value) * time.Second)
+		}
+
+		resetSeqNumFlag := new(fix.BooleanValue)
+		if err := msg.Body.GetField(tag.ResetSeqNumFlag, resetSeqNumFlag); err == nil && resetSeqNumFlag.Value && !s.resetOnLogon {
+			logEventf(s.log, LogLevelInfo, LogCategorySession, "Logon response contains ResetSeqNumFlag=Y, resetting target sequence number to 1")
+			s.store.SetNextTargetMsgSeqNum(1)
+		}
+	}
+
+	if err := s.handleNextExpectedMsgSeqNum(msg); err != nil {
+		return err
 	}
 
 	s.application.OnLogon(s.sessionID)
@@ -266,7 +924,16 @@ func (s *Session) handleLogon(msg Message) error {
 	if err := s.checkTargetTooHigh(msg); err != nil {
 		switch TypedError := err.(type) {
 		case targetTooHigh:
-			s.doTargetTooHigh(TypedError)
+			if s.hasNextExpectedMsgSeqNum(msg) {
+				s.onAnomaly(AnomalySeqNumGap, TypedError.ReceivedTarget-TypedError.ExpectedTarget, nil)
+				logEventf(s.log, LogLevelWarn, LogCategorySession, "Logon MsgSeqNum too high, expecting counterparty to resend from %d", TypedError.ExpectedTarget)
+			} else {
+				s.doTargetTooHigh(TypedError)
+			}
+
+			//the logon is not counted until the gap is filled, it is gap filled by the counterparty on resend
+			s.resendRequested(TypedError.ExpectedTarget, TypedError.ReceivedTarget)
+			return nil
 		}
 	}
 
@@ -274,6 +941,16 @@ func (s *Session) handleLogon(msg Message) error {
 	return nil
 }
 
+//refuseLogon returns the error disconnecting a Logon refused by reject, first sending a Logout if the Application returned NewRejectLogonError.
+func (s *Session) refuseLogon(reject MessageRejectError) error {
+	if rej, ok := reject.(adminRejectError); ok && rej.action == adminRejectLogout {
+		state := inSession{}
+		state.generateLogoutWithReason(s, rej.Error())
+	}
+
+	return fmt.Errorf("Logon refused: %v", reject.Error())
+}
+
 func (s *Session) verify(msg Message) MessageRejectError {
 	return s.verifySelect(msg, true, true)
 }
@@ -287,6 +964,23 @@ func (s *Session) verifyIgnoreSeqNumTooHighOrLow(msg Message) MessageRejectError
 }
 
 func (s *Session) verifySelect(msg Message, checkTooHigh bool, checkTooLow bool) MessageRejectError {
+	endStage := startMessageStage(msg.Context(), StageValidate)
+	reject := s.checkInbound(msg, checkTooHigh, checkTooLow)
+	if reject != nil {
+		endStage(reject)
+		return reject
+	}
+	endStage(nil)
+
+	if s.messageTimestamps {
+		msg.Timestamps.Validated = s.now()
+	}
+
+	return s.fromCallback(msg)
+}
+
+//checkInbound checks the header of msg, and validates it against the data dictionaries of the session.
+func (s *Session) checkInbound(msg Message, checkTooHigh bool, checkTooLow bool) MessageRejectError {
 	if reject := s.checkBeginString(msg); reject != nil {
 		return reject
 	}
@@ -301,7 +995,9 @@ func (s *Session) verifySelect(msg Message, checkTooHigh bool, checkTooLow bool)
 
 	if checkTooLow {
 		if reject := s.checkTargetTooLow(msg); reject != nil {
-			return reject
+			if tooLow, ok := reject.(targetTooLow); !ok || !s.resyncTargetTooLow(msg, tooLow) {
+				return reject
+			}
 		}
 	}
 
@@ -311,6 +1007,14 @@ func (s *Session) verifySelect(msg Message, checkTooHigh bool, checkTooLow bool)
 		}
 	}
 
+	if reject := s.checkOrigSendingTime(msg); reject != nil {
+		return reject
+	}
+
+	if reject := s.checkDuplicateTags(msg); reject != nil {
+		return reject
+	}
+
 	if s.dataDictionary != nil {
 		if reject := validate(s.dataDictionary, msg); reject != nil {
 			return reject
@@ -325,18 +1029,58 @@ func (s *Session) verifySelect(msg Message, checkTooHigh bool, checkTooLow bool)
 				return reject
 			}
 		} else {
-			if reject := validateFIXTApp(s.transportDataDictionary, s.appDataDictionary, msg); reject != nil {
+			appDataDictionary := s.appDataDictionaryFor(msg)
+			if appDataDictionary == nil {
+				return unsupportedApplicationVersion()
+			}
+
+			if reject := validateFIXTApp(s.transportDataDictionary, appDataDictionary, msg); reject != nil {
 				return reject
 			}
 		}
 	}
 
-	return s.fromCallback(msg)
+	return nil
 }
 
 func (s *Session) fromCallback(msg Message) MessageRejectError {
 	msgType := new(fix.StringValue)
-	if msg.Header.GetField(tag.MsgType, msgType); fix.IsAdminMessageType(msgType.Value) {
+	msg.Header.GetField(tag.MsgType, msgType)
+	isAdmin := fix.IsAdminMessageType(msgType.Value)
+
+	stage := StageFromApp
+	if isAdmin {
+		stage = StageFromAdmin
+	}
+	endStage := startMessageStage(msg.Context(), stage)
+
+	if s.latency != nil || s.messageTimestamps {
+		now := s.now()
+		if s.messageTimestamps {
+			msg.Timestamps.Callback = now
+		}
+		if s.latency != nil && !msg.ReceiveTime.IsZero() {
+			s.latency.recordWireToCallback(msgType.Value, now.Sub(msg.ReceiveTime))
+		}
+	}
+
+	reject := s.fromApplication(msg, isAdmin)
+	if reject != nil {
+		endStage(reject)
+		return reject
+	}
+	endStage(nil)
+
+	return nil
+}
+
+//fromApplication calls the interceptors and the Application with msg.
+func (s *Session) fromApplication(msg Message, isAdmin bool) MessageRejectError {
+	if reject := s.interceptInbound(msg); reject != nil {
+		return reject
+	}
+
+	if isAdmin {
 		return s.application.FromAdmin(msg, s.sessionID)
 	}
 
@@ -400,13 +1144,50 @@ func (s *Session) checkSendingTime(msg Message) MessageRejectError {
 		return err
 	}
 
-	if delta := time.Since(sendingTime.Value); delta <= -1*time.Duration(120)*time.Second || delta >= time.Duration(120)*time.Second {
+	receiveTime := msg.ReceiveTime
+	if receiveTime.IsZero() {
+		receiveTime = s.now()
+	}
+
+	skew := receiveTime.Sub(sendingTime.Value)
+	s.onClockSkew(skew)
+
+	if s.skipLatencyCheck {
+		return nil
+	}
+
+	maxLatency := s.maxLatency
+	if maxLatency == 0 {
+		maxLatency = defaultMaxLatency
+	}
+
+	if skew <= -maxLatency || skew >= maxLatency {
 		return sendingTimeAccuracyProblem()
 	}
 
 	return nil
 }
 
+//checkOrigSendingTime returns a reject for a message with PossDupFlag set if OrigSendingTime is missing or after SendingTime.
+func (s *Session) checkOrigSendingTime(msg Message) MessageRejectError {
+	possDupFlag := new(fix.BooleanValue)
+	if err := msg.Header.GetField(tag.PossDupFlag, possDupFlag); err != nil || !possDupFlag.Value {
+		return nil
+	}
+
+	origSendingTime := new(fix.UTCTimestampValue)
+	if err := msg.Header.GetField(tag.OrigSendingTime, origSendingTime); err != nil {
+		return requiredTagMissing(tag.OrigSendingTime)
+	}
+
+	sendingTime := new(fix.UTCTimestampValue)
+	if err := msg.Header.GetField(tag.SendingTime, sendingTime); err == nil && sendingTime.Value.Before(origSendingTime.Value) {
+		return origSendingTimeProblem()
+	}
+
+	return nil
+}
+
 func (s *Session) checkBeginString(msg Message) MessageRejectError {
 	beginString := new(fix.StringValue)
 	switch err := msg.Header.GetField(tag.BeginString, beginString); {
@@ -420,85 +1201,122 @@ func (s *Session) checkBeginString(msg Message) MessageRejectError {
 }
 
 func (s *Session) doReject(msg Message, rej MessageRejectError) {
-	reply := msg.reverseRoute()
+	reply := buildReject(s.sessionID.BeginString, msg, rej, rej.IsBusinessReject())
 
-	if s.sessionID.BeginString >= fix.BeginString_FIX42 {
+	s.send(reply)
+	logEventf(s.log, LogLevelWarn, LogCategoryValidation, "Message Rejected: %v", rej.Error())
+}
 
-		if rej.IsBusinessReject() {
-			reply.Header().Set(field.NewMsgType("j"))
-			reply.Body().Set(field.NewBusinessRejectReason(int(rej.RejectReason())))
-		} else {
-			reply.Header().Set(field.NewMsgType("3"))
-			switch {
-			default:
-				reply.Body().Set(field.NewSessionRejectReason(int(rej.RejectReason())))
-			case rej.RejectReason() > rejectReasonInvalidMsgType && s.sessionID.BeginString == fix.BeginString_FIX42:
-				//fix42 knows up to invalid msg type
-			}
-		}
-		reply.Body().Set(field.NewText(rej.Error()))
+//rejectMessageTooLarge rejects a message discarded for exceeding the max message size.
+//The discarded message consumes its sequence number so that the session is not stalled waiting on a resend.
+func (s *Session) rejectMessageTooLarge(tooLarge MessageTooLargeError) {
+	logEventf(s.log, LogLevelWarn, LogCategoryValidation, "Discarded message: %v", tooLarge.Error())
 
-		var msgType field.MsgTypeField
-		if err := msg.Header.Get(&msgType); err == nil {
-			reply.Body().Set(field.NewRefMsgType(msgType.Value))
-		}
+	switch s.currentState.(type) {
+	case inSession, pendingTimeout:
+	default:
+		return
+	}
 
-		if refTagID := rej.RefTagID(); refTagID != nil {
-			reply.Body().Set(field.NewRefTagID(int(*refTagID)))
-		}
-	} else {
-		reply.Header().Set(field.NewMsgType("3"))
+	var body, trailer fieldMap
+	body.init(normalFieldOrder)
+	trailer.init(trailerFieldOrder)
+	msg := Message{Header: tooLarge.Header, Body: body, Trailer: trailer}
 
-		if refTagID := rej.RefTagID(); refTagID != nil {
-			reply.Body().Set(field.NewText(fmt.Sprintf("%s (%d)", rej.Error(), *refTagID)))
-		} else {
-			reply.Body().Set(field.NewText(rej.Error()))
-		}
+	s.doReject(msg, NewMessageRejectError(tooLarge.Error(), rejectReasonOther, nil))
+
+	seqNum := new(fix.IntValue)
+	if err := msg.Header.GetField(tag.MsgSeqNum, seqNum); err == nil && seqNum.Value == s.store.NextTargetMsgSeqNum() {
+		s.store.IncrNextTargetMsgSeqNum()
 	}
+}
+
+//isSessionTime returns true if the session schedule, if any, is active at now.
+func (s *Session) isSessionTime(now time.Time) bool {
+	schedule := s.sessionSchedule()
+	return schedule == nil || schedule.IsInRange(now)
+}
 
-	var seqNum field.MsgSeqNumField
-	if err := msg.Header.Get(&seqNum); err == nil {
-		reply.Body().Set(field.NewRefSeqNum(seqNum.Value))
+//checkSessionReset resets the store if it was created in an earlier period of the session schedule.
+func (s *Session) checkSessionReset(now time.Time) {
+	if schedule := s.sessionSchedule(); schedule == nil || schedule.IsInSameRange(s.store.CreationTime(), now) {
+		return
 	}
 
-	s.send(reply)
-	s.log.OnEventf("Message Rejected: %v", rej.Error())
+	logEventf(s.log, LogLevelInfo, LogCategorySession, "New session period, resetting sequence numbers")
+	s.store.Reset()
+}
+
+//endSession logs out the session, disconnecting if not logged on.
+func (s *Session) endSession() (nextState sessionState) {
+	switch s.currentState.(type) {
+	case inSession, resendState, pendingTimeout:
+		state := inSession{}
+		return state.initiateLogout(s, "")
+	case logoutState, latentState:
+		return s.currentState
+	}
+
+	logEventf(s.log, LogLevelInfo, LogCategoryTransport, "Disconnecting")
+	return latentState{}
 }
 
 type fixIn struct {
 	bytes       []byte
 	receiveTime time.Time
+
+	//tooLarge is set in place of bytes for a message discarded for exceeding the max message size
+	tooLarge *MessageTooLargeError
 }
 
-func (s *Session) run(msgIn chan fixIn) {
-	defer func() {
-		s.messageOut <- nil
-	}()
+//receive parses and handles a message read from the connection.
+func (s *Session) receive(fixIn fixIn) {
+	ctx, trace := s.startReceiveTrace(fixIn.receiveTime)
 
-	if s.initiateLogon {
+	endStage := startMessageStage(ctx, StageParse)
+	msg, err := parseMessage(fixIn.bytes)
+	endStage(err)
 
-		if s.resetOnLogon {
-			s.store.Reset()
+	if err != nil {
+		logEventf(s.log, LogLevelWarn, LogCategoryValidation, "Msg Parse Error: %v, %q", err.Error(), fixIn.bytes)
+		s.checkPoisonMessage(fixIn.bytes, err)
+
+		if trace != nil {
+			trace.End(nil, err)
 		}
+		return
+	}
 
-		logon := NewMessageBuilder()
-		logon.Header().Set(field.NewMsgType("A"))
-		logon.Header().Set(field.NewBeginString(s.sessionID.BeginString))
-		logon.Header().Set(field.NewTargetCompID(s.sessionID.TargetCompID))
-		logon.Header().Set(field.NewSenderCompID(s.sessionID.SenderCompID))
-		logon.Body().Set(field.NewEncryptMethod(0))
-		logon.Body().Set(field.NewHeartBtInt(s.heartBtInt))
+	msg.ReceiveTime = fixIn.receiveTime
+	msg.ctx = ctx
+	s.checkCheckSum(msg)
+	s.saveReceivedMessage(msg, fixIn.bytes)
+	if s.messageTimestamps {
+		msg.Timestamps = MessageTimestamps{Received: fixIn.receiveTime, Parsed: s.now()}
+	}
+	s.resolveDuplicateTags(msg)
+	s.transition(s.currentState.FixMsgIn(s, *msg), nil)
 
-		s.heartBeatTimeout = time.Duration(s.heartBtInt) * time.Second
+	if trace != nil {
+		trace.End(msg.Header, nil)
+	}
+}
 
-		if len(s.defaultApplVerID) > 0 {
-			logon.Body().Set(field.NewDefaultApplVerID(s.defaultApplVerID))
-		}
+func (s *Session) run(msgIn chan fixIn) {
+	defer s.debug.goroutine()()
+	defer s.endRun()
+
+	s.startRun()
 
-		s.log.OnEvent("Sending logon request")
-		s.send(logon)
+	var sessionTimeCheck <-chan time.Time
+	if s.sessionSchedule() != nil {
+		ticker := s.sessionClock().NewTicker(time.Second)
+		defer ticker.Stop()
+		sessionTimeCheck = ticker.C()
 	}
 
+	stop := s.stop
+
 	for {
 
 		switch s.currentState.(type) {
@@ -508,25 +1326,141 @@ func (s *Session) run(msgIn chan fixIn) {
 
 		select {
 		case fixIn, ok := <-msgIn:
-			if ok {
-				s.log.OnIncoming(string(fixIn.bytes))
-				if msg, err := parseMessage(fixIn.bytes); err != nil {
-					s.log.OnEventf("Msg Parse Error: %v, %q", err.Error(), fixIn.bytes)
-				} else {
-					msg.ReceiveTime = fixIn.receiveTime
-					s.currentState = s.currentState.FixMsgIn(s, *msg)
-				}
-			} else {
-				s.onDisconnect()
-				return
-			}
-			s.peerTimer.Reset(time.Duration(int64(1.2 * float64(s.heartBeatTimeout))))
+			s.onReceived(fixIn, ok)
 
 		case msg := <-s.toSend:
 			s.send(msg)
 
 		case evt := <-s.sessionEvent:
-			s.currentState = s.currentState.Timeout(s, evt)
+			s.onSessionEvent(evt)
+
+		case now := <-sessionTimeCheck:
+			s.onSessionTimeCheck(now)
+
+		case <-s.shutdown:
+			s.onShutdown()
+
+		case <-stop:
+			//stop is closed, only handle once
+			stop = nil
+			s.onStop()
+		}
+	}
+}
+
+//startRun starts running the session on a new connection, sending the logon if initiating.
+func (s *Session) startRun() {
+	//a shutdown requested while not connected does not apply to this connection
+	select {
+	case <-s.shutdown:
+	default:
+	}
+
+	if !s.initiateLogon {
+		return
+	}
+
+	s.checkSessionReset(s.now())
+
+	if s.resetOnLogon {
+		s.store.Reset()
+	}
+
+	logon := NewMessageBuilder()
+	logon.Header().Set(field.NewMsgType("A"))
+	logon.Header().Set(field.NewBeginString(s.sessionID.BeginString))
+	logon.Header().Set(field.NewTargetCompID(s.sessionID.TargetCompID))
+	logon.Header().Set(field.NewSenderCompID(s.sessionID.SenderCompID))
+	logon.Body().Set(field.NewEncryptMethod(0))
+	logon.Body().Set(field.NewHeartBtInt(s.heartBtInt))
+
+	if s.resetOnLogon {
+		logon.Body().Set(field.NewResetSeqNumFlag(true))
+	}
+
+	s.setHeartBtInt(time.Duration(s.heartBtInt) * time.Second)
+
+	if len(s.defaultApplVerID) > 0 {
+		logon.Body().Set(field.NewDefaultApplVerID(s.defaultApplVerID))
+	}
+
+	s.setNextExpectedMsgSeqNum(logon, nil)
+
+	if err := s.setLogonCredentials(logon); err != nil {
+		logEventf(s.log, LogLevelError, LogCategorySession, "Cannot get logon credentials: %v", err)
+		s.transition(latentState{}, fmt.Errorf("cannot get logon credentials: %v", err))
+		return
+	}
+
+	logEventf(s.log, LogLevelInfo, LogCategorySession, "Sending logon request")
+	if err := s.send(logon); err != nil {
+		logEventf(s.log, LogLevelError, LogCategoryTransport, "Logon not sent: %v", err)
+		s.transition(latentState{}, fmt.Errorf("logon not sent: %v", err))
+	}
+}
+
+//endRun ends running the session on its connection, once latent.
+func (s *Session) endRun() {
+	if s.resetOnDisconnect {
+		logEventf(s.log, LogLevelInfo, LogCategorySession, "ResetOnDisconnect, resetting sequence numbers to 1")
+		s.store.Reset()
+	}
+
+	if flushable, ok := s.store.(FlushableStore); ok {
+		if err := flushable.Flush(); err != nil {
+			s.storeMetrics.recordError(err, s.now())
+			s.onAnomaly(AnomalyStoreWriteFailure, 1, err)
+			logEventf(s.log, LogLevelError, LogCategoryStore, "Cannot flush store: %v", err)
 		}
 	}
+
+	if r := s.pooledRun(); r != nil {
+		r.writer.end()
+		return
+	}
+	s.messageOut <- nil
+}
+
+//onReceived handles fixIn read from the connection, ok false once the connection is closed.
+func (s *Session) onReceived(fixIn fixIn, ok bool) {
+	if !ok {
+		s.onDisconnect()
+		s.transition(latentState{}, errors.New("connection closed"))
+		return
+	}
+
+	if fixIn.tooLarge != nil {
+		s.rejectMessageTooLarge(*fixIn.tooLarge)
+	} else {
+		s.trafficStats.recordIn(len(fixIn.bytes), s.now())
+		s.log.OnIncoming(string(fixIn.bytes))
+		s.receive(fixIn)
+	}
+	s.onMessageReceived(fixIn.receiveTime)
+}
+
+//onSessionEvent handles evt, fired by a timer of the session.
+func (s *Session) onSessionEvent(evt event) {
+	s.transition(s.currentState.Timeout(s, evt), evt.reason())
+}
+
+//onSessionTimeCheck ends the session once now is past its schedule.
+func (s *Session) onSessionTimeCheck(now time.Time) {
+	if !s.isSessionTime(now) {
+		logEventf(s.log, LogLevelInfo, LogCategorySession, "Session end time reached")
+		s.transition(s.endSession(), errors.New("session end time reached"))
+	}
+}
+
+//onShutdown logs out the session on shutdown, see requestShutdown.
+func (s *Session) onShutdown() {
+	logEventf(s.log, LogLevelInfo, LogCategorySession, "Shutting down")
+	s.flushDelayed()
+	s.transition(s.endSession(), errors.New("shutdown"))
+}
+
+//onStop logs out the session once removed.
+func (s *Session) onStop() {
+	logEventf(s.log, LogLevelInfo, LogCategorySession, "Session removed")
+	s.transition(s.endSession(), errors.New("session removed"))
 }
@@ -4,7 +4,8 @@ import (
 	"fmt"
 )
 
-// SessionID is a unique identifer of a Session
+// SessionID is a unique identifer of a Session.
+// Qualifier distinguishes initiated sessions with the same BeginString, TargetCompID, and SenderCompID, each having its own store, log, and sequence numbers.
 type SessionID struct {
 	BeginString, TargetCompID, SenderCompID, Qualifier string
 }
@@ -16,3 +17,11 @@ func (s SessionID) String() string {
 
 	return fmt.Sprintf("%s:%s->%s", s.BeginString, s.SenderCompID, s.TargetCompID)
 }
+
+//unqualified returns sessionID without the Qualifier.
+func unqualified(sessionID SessionID) SessionID {
+	return SessionID{
+		BeginString:  sessionID.BeginString,
+		TargetCompID: sessionID.TargetCompID,
+		SenderCompID: sessionID.SenderCompID}
+}
@@ -0,0 +1,83 @@
+package quickfix
+
+import (
+	"sort"
+	"time"
+)
+
+//SessionInfo is a snapshot of the runtime state of a session, for monitoring and administration.
+type SessionInfo struct {
+	SessionID SessionID
+	State     SessionState
+
+	//NextSenderMsgSeqNum is the MsgSeqNum of the next message sent, NextTargetMsgSeqNum of the next message expected from the counterparty.
+	NextSenderMsgSeqNum, NextTargetMsgSeqNum int
+
+	//LastSentTime and LastReceivedTime are zero if no message has been sent or received.
+	LastSentTime, LastReceivedTime time.Time
+
+	//QueuedMessages is the number of application messages waiting to be sent, queued while not logged on or delayed by the throttle.
+	QueuedMessages int
+
+	//HeartBtInt is the heartbeat interval negotiated on logon, zero if not logged on.
+	HeartBtInt time.Duration
+
+	//CounterpartyLogon is declared by the counterparty on its last Logon.
+	CounterpartyLogon LogonAttributes
+
+	//StoreHealth is the error returned by the store if a HealthStore, nil if healthy. See Session.StoreMetrics for the store operation metrics.
+	StoreHealth error
+
+	//Traffic is the traffic of the session with its counterparty.
+	Traffic TrafficStats
+}
+
+//Info returns a snapshot of the runtime state of the session.
+//Safe to call outside the session goroutine.
+func (s *Session) Info() SessionInfo {
+	info := SessionInfo{SessionID: s.sessionID}
+
+	s.trafficLock.RLock()
+	info.State = s.traffic.state
+	info.LastSentTime, info.LastReceivedTime = s.traffic.lastSent, s.traffic.lastReceived
+	info.HeartBtInt = s.traffic.heartBtInt
+	info.CounterpartyLogon = s.traffic.counterpartyLogon
+	s.trafficLock.RUnlock()
+
+	s.sendLock.Lock()
+	info.NextSenderMsgSeqNum = s.store.NextSenderMsgSeqNum()
+	info.NextTargetMsgSeqNum = s.store.NextTargetMsgSeqNum()
+	info.QueuedMessages = len(s.sendQueue) + len(s.delayed)
+	s.sendLock.Unlock()
+
+	if store, ok := s.store.(HealthStore); ok {
+		info.StoreHealth = store.Health()
+	}
+	info.Traffic = s.TrafficStats()
+
+	return info
+}
+
+//LookupSessionInfo returns a snapshot of the runtime state of the session with sessionID.
+func LookupSessionInfo(sessionID SessionID) (SessionInfo, error) {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return SessionInfo{}, err
+	}
+
+	return session.Info(), nil
+}
+
+//AllSessionInfo returns a snapshot of the runtime state of each session, ordered by SessionID.
+func AllSessionInfo() []SessionInfo {
+	var infos []SessionInfo
+	for _, session := range allSessions() {
+		infos = append(infos, session.Info())
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].SessionID.String() < infos[j].SessionID.String()
+	})
+
+	return infos
+}
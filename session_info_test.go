@@ -0,0 +1,66 @@
+package quickfix
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSession_Info(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.2", SenderCompID: "INFO", TargetCompID: "DASHBOARD"}
+	if err := createSession(sessionID, NewMemoryStoreFactory(), NewSessionSettings(), NewNullLogFactory(), &TestClient{}); err != nil {
+		t.Fatal(err)
+	}
+	defer unregisterSession(sessionID)
+
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session.initiateLogon = true
+	session.transition(logonState{}, nil)
+	session.store.SetNextSenderMsgSeqNum(5)
+	session.store.SetNextTargetMsgSeqNum(7)
+	session.setHeartBtInt(30 * time.Second)
+
+	sent := time.Now()
+	session.onMessageSent(sent)
+
+	for _, clOrdID := range []string{"1", "2"} {
+		if err := session.sendOrQueue(newSendQueueTestMessage(clOrdID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info, err := LookupSessionInfo(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := SessionInfo{
+		SessionID:           sessionID,
+		State:               StateLogonSent,
+		NextSenderMsgSeqNum: 5,
+		NextTargetMsgSeqNum: 7,
+		LastSentTime:        sent,
+		QueuedMessages:      2,
+		HeartBtInt:          30 * time.Second,
+	}
+
+	if info != expected {
+		t.Errorf("Expected %+v got %+v", expected, info)
+	}
+
+	found := false
+	for _, info := range AllSessionInfo() {
+		found = found || info.SessionID == sessionID
+	}
+
+	if !found {
+		t.Errorf("Expected %v in info of all sessions", sessionID)
+	}
+
+	if _, err := LookupSessionInfo(SessionID{BeginString: "FIX.4.2", SenderCompID: "INFO", TargetCompID: "UNKNOWN"}); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+}
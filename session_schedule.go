@@ -0,0 +1,252 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"strings"
+	"time"
+)
+
+const (
+	timeOfDayFormat = "15:04:05"
+	secondsPerDay   = 24 * 60 * 60
+)
+
+//schedulePeriod is a daily period a session is active, startTime and endTime are seconds into the day.
+type schedulePeriod struct {
+	startTime, endTime int
+}
+
+//contains returns the number of days before the day of offset the period containing offset started, false if offset is outside the period.
+func (p schedulePeriod) contains(offset int) (daysBack int, ok bool) {
+	switch {
+	case p.startTime <= p.endTime:
+		return 0, p.startTime <= offset && offset <= p.endTime
+	case offset >= p.startTime:
+		return 0, true
+	case offset <= p.endTime:
+		//period wraps around midnight
+		return 1, true
+	}
+
+	return 0, false
+}
+
+//sessionSchedule is the period a session is active, configured with StartTime and EndTime, optionally StartDay and EndDay for a weekly session, and TimeZone.
+//A daily session may be active for several periods a day, configured with StartTime1 and EndTime1, StartTime2 and EndTime2, and so on, and only on the Weekdays listed.
+//The session is not active on dates the HolidayCalendar of the Application, if implemented, reports as holidays.
+type sessionSchedule struct {
+	//periods of a weekly session hold only the times of StartTime and EndTime
+	periods          []schedulePeriod
+	startDay, endDay time.Weekday
+	weekly           bool
+	//weekdays a daily period may start on, nil for every day
+	weekdays map[time.Weekday]bool
+	location *time.Location
+
+	sessionID SessionID
+	holidays  HolidayCalendar
+}
+
+//newSessionSchedule returns the schedule configured in settings, nil if the session is not scheduled.
+func newSessionSchedule(settings *SessionSettings) (*sessionSchedule, error) {
+	if !settings.HasSetting(config.StartTime) && !settings.HasSetting(config.EndTime) {
+		return nil, nil
+	}
+
+	schedule := &sessionSchedule{location: time.UTC}
+
+	for n := 0; ; n++ {
+		startSetting, endSetting := config.StartTime, config.EndTime
+		if n > 0 {
+			startSetting = fmt.Sprintf("%v%v", config.StartTime, n)
+			endSetting = fmt.Sprintf("%v%v", config.EndTime, n)
+
+			if !settings.HasSetting(startSetting) && !settings.HasSetting(endSetting) {
+				break
+			}
+		}
+
+		var period schedulePeriod
+		var err error
+		if period.startTime, err = timeOfDaySetting(settings, startSetting); err != nil {
+			return nil, err
+		}
+
+		if period.endTime, err = timeOfDaySetting(settings, endSetting); err != nil {
+			return nil, err
+		}
+
+		schedule.periods = append(schedule.periods, period)
+	}
+
+	var err error
+	switch hasStart, hasEnd := settings.HasSetting(config.StartDay), settings.HasSetting(config.EndDay); {
+	case hasStart && hasEnd:
+		schedule.weekly = true
+		if schedule.startDay, err = weekdaySetting(settings, config.StartDay); err != nil {
+			return nil, err
+		}
+
+		if schedule.endDay, err = weekdaySetting(settings, config.EndDay); err != nil {
+			return nil, err
+		}
+	case hasStart:
+		return nil, requiredConfigurationMissing(config.EndDay)
+	case hasEnd:
+		return nil, requiredConfigurationMissing(config.StartDay)
+	}
+
+	if schedule.weekly && len(schedule.periods) > 1 {
+		return nil, fmt.Errorf("weekly session with StartDay and EndDay cannot have several periods a day")
+	}
+
+	if settings.HasSetting(config.Weekdays) {
+		if schedule.weekly {
+			return nil, fmt.Errorf("weekly session with StartDay and EndDay cannot have Weekdays")
+		}
+
+		if schedule.weekdays, err = weekdaysSetting(settings, config.Weekdays); err != nil {
+			return nil, err
+		}
+	}
+
+	if timeZone, err := settings.Setting(config.TimeZone); err == nil {
+		if schedule.location, err = time.LoadLocation(timeZone); err != nil {
+			return nil, fmt.Errorf("invalid TimeZone %v: %v", timeZone, err)
+		}
+	}
+
+	return schedule, nil
+}
+
+func timeOfDaySetting(settings *SessionSettings, setting string) (int, error) {
+	value, err := settings.Setting(setting)
+	if err != nil {
+		return 0, requiredConfigurationMissing(setting)
+	}
+
+	t, err := time.Parse(timeOfDayFormat, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v %v, expected HH:MM:SS", setting, value)
+	}
+
+	return t.Hour()*60*60 + t.Minute()*60 + t.Second(), nil
+}
+
+func weekdaySetting(settings *SessionSettings, setting string) (time.Weekday, error) {
+	value, err := settings.Setting(setting)
+	if err != nil {
+		return 0, requiredConfigurationMissing(setting)
+	}
+
+	return parseWeekday(setting, value)
+}
+
+//weekdaysSetting parses a comma separated list of days of the week, for example Mon,Tue,Wed,Thu,Fri.
+func weekdaysSetting(settings *SessionSettings, setting string) (map[time.Weekday]bool, error) {
+	value, err := settings.Setting(setting)
+	if err != nil {
+		return nil, requiredConfigurationMissing(setting)
+	}
+
+	weekdays := make(map[time.Weekday]bool)
+	for _, day := range strings.Split(value, ",") {
+		weekday, err := parseWeekday(setting, strings.TrimSpace(day))
+		if err != nil {
+			return nil, err
+		}
+		weekdays[weekday] = true
+	}
+
+	return weekdays, nil
+}
+
+func parseWeekday(setting, value string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(value, day.String()) || strings.EqualFold(value, day.String()[:3]) {
+			return day, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid %v %v, expected a day of the week", setting, value)
+}
+
+//timeOfDay returns the offset of t into its day, in seconds.
+func timeOfDay(t time.Time) int {
+	return t.Hour()*60*60 + t.Minute()*60 + t.Second()
+}
+
+//periodStart returns the start of the period containing t, ignoring holidays, false if t is outside the schedule.
+func (s *sessionSchedule) periodStart(t time.Time) (time.Time, bool) {
+	t = t.In(s.location)
+
+	if s.weekly {
+		return s.weeklyPeriodStart(t)
+	}
+
+	for _, period := range s.periods {
+		daysBack, ok := period.contains(timeOfDay(t))
+		if !ok {
+			continue
+		}
+
+		//computed on the wall clock so that daylight saving transitions do not shift the start
+		start := time.Date(t.Year(), t.Month(), t.Day()-daysBack, period.startTime/3600, period.startTime/60%60, period.startTime%60, 0, s.location)
+		if s.weekdays != nil && !s.weekdays[start.Weekday()] {
+			continue
+		}
+
+		return start, true
+	}
+
+	return time.Time{}, false
+}
+
+//weeklyPeriodStart is periodStart for a weekly session, t is in the location of the schedule.
+func (s *sessionSchedule) weeklyPeriodStart(t time.Time) (time.Time, bool) {
+	startTime, endTime := s.periods[0].startTime, s.periods[0].endTime
+	offset := int(t.Weekday())*secondsPerDay + timeOfDay(t)
+	start, end := int(s.startDay)*secondsPerDay+startTime, int(s.endDay)*secondsPerDay+endTime
+
+	if start <= end && (offset < start || offset > end) {
+		return time.Time{}, false
+	}
+
+	//period wraps around the end of the week
+	if start > end && offset < start && offset > end {
+		return time.Time{}, false
+	}
+
+	daysBack := (int(t.Weekday()) - int(s.startDay) + 7) % 7
+	if daysBack == 0 && timeOfDay(t) < startTime {
+		daysBack = 7
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day()-daysBack, startTime/3600, startTime/60%60, startTime%60, 0, s.location), true
+}
+
+//isHoliday returns true if the date of t, in the location of the schedule, is a holiday of the HolidayCalendar.
+func (s *sessionSchedule) isHoliday(t time.Time) bool {
+	if s.holidays == nil {
+		return false
+	}
+
+	t = t.In(s.location)
+	return s.holidays.IsHoliday(s.sessionID, time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.location))
+}
+
+//IsInRange returns true if the session is active at t.
+func (s *sessionSchedule) IsInRange(t time.Time) bool {
+	_, ok := s.periodStart(t)
+	return ok && !s.isHoliday(t)
+}
+
+//IsInSameRange returns true if t1 and t2 both fall within the same period of the session.
+//Holidays do not end the period, a weekly session is not reset by a holiday during the week.
+func (s *sessionSchedule) IsInSameRange(t1, t2 time.Time) bool {
+	start1, ok1 := s.periodStart(t1)
+	start2, ok2 := s.periodStart(t2)
+
+	return ok1 && ok2 && start1.Equal(start2)
+}
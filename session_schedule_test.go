@@ -0,0 +1,268 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"testing"
+	"time"
+)
+
+func newTestSchedule(t *testing.T, settings map[string]string) *sessionSchedule {
+	sessionSettings := NewSessionSettings()
+	for setting, value := range settings {
+		sessionSettings.Set(setting, value)
+	}
+
+	schedule, err := newSessionSchedule(sessionSettings)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	return schedule
+}
+
+func TestNewSessionSchedule(t *testing.T) {
+	if schedule, err := newSessionSchedule(NewSessionSettings()); schedule != nil || err != nil {
+		t.Errorf("expected no schedule, got %v %v", schedule, err)
+	}
+
+	var invalid = []map[string]string{
+		{config.StartTime: "08:00:00"},
+		{config.StartTime: "8am", config.EndTime: "17:00:00"},
+		{config.StartTime: "08:00:00", config.EndTime: "17:00:00", config.StartDay: "Mon"},
+		{config.StartTime: "08:00:00", config.EndTime: "17:00:00", config.StartDay: "Mon", config.EndDay: "Someday"},
+		{config.StartTime: "08:00:00", config.EndTime: "17:00:00", config.TimeZone: "Nowhere/Special"},
+		{config.StartTime: "08:00:00", config.EndTime: "17:00:00", config.Weekdays: "Mon,Someday"},
+		{config.StartTime: "08:00:00", config.EndTime: "17:00:00", config.StartDay: "Sun", config.EndDay: "Fri", config.Weekdays: "Mon"},
+		{config.StartTime: "08:00:00", config.EndTime: "12:00:00", "StartTime1": "13:00:00"},
+		{config.StartTime: "08:00:00", config.EndTime: "12:00:00", "StartTime1": "13:00:00", "EndTime1": "17:00:00", config.StartDay: "Sun", config.EndDay: "Fri"},
+	}
+
+	for _, settings := range invalid {
+		sessionSettings := NewSessionSettings()
+		for setting, value := range settings {
+			sessionSettings.Set(setting, value)
+		}
+
+		if _, err := newSessionSchedule(sessionSettings); err == nil {
+			t.Errorf("expected error for %v", settings)
+		}
+	}
+}
+
+func TestSessionSchedule_DailyIsInRange(t *testing.T) {
+	schedule := newTestSchedule(t, map[string]string{config.StartTime: "08:00:00", config.EndTime: "17:00:00", config.TimeZone: "America/New_York"})
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	var testCases = []struct {
+		time     time.Time
+		expected bool
+	}{
+		{time.Date(2016, time.March, 1, 7, 59, 59, 0, newYork), false},
+		{time.Date(2016, time.March, 1, 8, 0, 0, 0, newYork), true},
+		{time.Date(2016, time.March, 1, 17, 0, 0, 0, newYork), true},
+		{time.Date(2016, time.March, 1, 17, 0, 1, 0, newYork), false},
+		{time.Date(2016, time.March, 1, 14, 0, 0, 0, time.UTC), true},
+		{time.Date(2016, time.March, 1, 12, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tc := range testCases {
+		if actual := schedule.IsInRange(tc.time); actual != tc.expected {
+			t.Errorf("%v: expected %v got %v", tc.time, tc.expected, actual)
+		}
+	}
+}
+
+func TestSessionSchedule_OvernightIsInRange(t *testing.T) {
+	schedule := newTestSchedule(t, map[string]string{config.StartTime: "22:00:00", config.EndTime: "06:00:00"})
+
+	if !schedule.IsInRange(time.Date(2016, time.March, 1, 23, 0, 0, 0, time.UTC)) {
+		t.Error("expected 23:00 in range")
+	}
+
+	if !schedule.IsInRange(time.Date(2016, time.March, 2, 5, 0, 0, 0, time.UTC)) {
+		t.Error("expected 05:00 in range")
+	}
+
+	if schedule.IsInRange(time.Date(2016, time.March, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Error("expected 12:00 out of range")
+	}
+
+	if !schedule.IsInSameRange(time.Date(2016, time.March, 1, 23, 0, 0, 0, time.UTC), time.Date(2016, time.March, 2, 5, 0, 0, 0, time.UTC)) {
+		t.Error("expected overnight times in the same range")
+	}
+
+	if schedule.IsInSameRange(time.Date(2016, time.March, 1, 5, 0, 0, 0, time.UTC), time.Date(2016, time.March, 1, 23, 0, 0, 0, time.UTC)) {
+		t.Error("expected times on consecutive nights in different ranges")
+	}
+}
+
+func TestSessionSchedule_Weekly(t *testing.T) {
+	schedule := newTestSchedule(t, map[string]string{config.StartTime: "18:00:00", config.EndTime: "17:00:00", config.StartDay: "Sunday", config.EndDay: "Fri"})
+
+	//2016-03-06 is a Sunday
+	sundayOpen := time.Date(2016, time.March, 6, 18, 0, 0, 0, time.UTC)
+	wednesday := time.Date(2016, time.March, 9, 3, 0, 0, 0, time.UTC)
+	fridayClose := time.Date(2016, time.March, 11, 17, 0, 1, 0, time.UTC)
+	saturday := time.Date(2016, time.March, 12, 12, 0, 0, 0, time.UTC)
+	nextMonday := time.Date(2016, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+	if !schedule.IsInRange(sundayOpen) || !schedule.IsInRange(wednesday) {
+		t.Error("expected weekdays in range")
+	}
+
+	if schedule.IsInRange(fridayClose) || schedule.IsInRange(saturday) {
+		t.Error("expected weekend out of range")
+	}
+
+	if !schedule.IsInSameRange(sundayOpen, wednesday) {
+		t.Error("expected the same week in the same range")
+	}
+
+	if schedule.IsInSameRange(wednesday, nextMonday) {
+		t.Error("expected consecutive weeks in different ranges")
+	}
+}
+
+func TestSession_CheckSessionReset(t *testing.T) {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	store.Reset()
+	store.IncrNextSenderMsgSeqNum()
+
+	session := Session{store: store, log: nullLog{}}
+	session.checkSessionReset(time.Now())
+	if store.NextSenderMsgSeqNum() != 2 {
+		t.Error("unscheduled session should not be reset")
+	}
+
+	now := time.Now().UTC()
+	session.schedule = newTestSchedule(t, map[string]string{config.StartTime: now.Add(-time.Hour).Format(timeOfDayFormat), config.EndTime: now.Add(time.Hour).Format(timeOfDayFormat)})
+	session.checkSessionReset(now)
+	if store.NextSenderMsgSeqNum() != 2 {
+		t.Error("store created in the current session period should not be reset")
+	}
+
+	session.checkSessionReset(now.Add(24 * time.Hour))
+	if store.NextSenderMsgSeqNum() != 1 {
+		t.Error("store created in an earlier session period should be reset")
+	}
+}
+
+func TestSessionSchedule_Weekdays(t *testing.T) {
+	schedule := newTestSchedule(t, map[string]string{config.StartTime: "22:00:00", config.EndTime: "06:00:00", config.Weekdays: "Mon, Tue,Wed,Thu,Fri"})
+
+	//2016-03-04 is a Friday
+	var testCases = []struct {
+		time     time.Time
+		expected bool
+	}{
+		{time.Date(2016, time.March, 4, 23, 0, 0, 0, time.UTC), true},
+		{time.Date(2016, time.March, 5, 3, 0, 0, 0, time.UTC), true},
+		{time.Date(2016, time.March, 5, 23, 0, 0, 0, time.UTC), false},
+		{time.Date(2016, time.March, 6, 3, 0, 0, 0, time.UTC), false},
+		{time.Date(2016, time.March, 6, 23, 0, 0, 0, time.UTC), false},
+		{time.Date(2016, time.March, 7, 23, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, tc := range testCases {
+		if actual := schedule.IsInRange(tc.time); actual != tc.expected {
+			t.Errorf("%v: expected %v got %v", tc.time, tc.expected, actual)
+		}
+	}
+}
+
+func TestSessionSchedule_MultiplePeriods(t *testing.T) {
+	schedule := newTestSchedule(t, map[string]string{config.StartTime: "08:00:00", config.EndTime: "12:00:00", "StartTime1": "13:00:00", "EndTime1": "17:00:00"})
+
+	morning := time.Date(2016, time.March, 1, 9, 0, 0, 0, time.UTC)
+	lunch := time.Date(2016, time.March, 1, 12, 30, 0, 0, time.UTC)
+	afternoon := time.Date(2016, time.March, 1, 14, 0, 0, 0, time.UTC)
+
+	if !schedule.IsInRange(morning) || !schedule.IsInRange(afternoon) {
+		t.Error("expected both periods in range")
+	}
+
+	if schedule.IsInRange(lunch) {
+		t.Error("expected time between periods out of range")
+	}
+
+	if schedule.IsInSameRange(morning, afternoon) {
+		t.Error("expected periods of the same day in different ranges")
+	}
+
+	if !schedule.IsInSameRange(afternoon, afternoon.Add(time.Hour)) {
+		t.Error("expected times in the same period in the same range")
+	}
+}
+
+type testHolidayCalendar map[string]bool
+
+func (c testHolidayCalendar) IsHoliday(sessionID SessionID, date time.Time) bool {
+	return c[date.Format("20060102")]
+}
+
+func TestSessionSchedule_Holidays(t *testing.T) {
+	schedule := newTestSchedule(t, map[string]string{config.StartTime: "17:00:00", config.EndTime: "17:00:00", config.StartDay: "Sun", config.EndDay: "Fri", config.TimeZone: "America/New_York"})
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	//2016-03-09 is a Wednesday
+	schedule.holidays = testHolidayCalendar{"20160309": true}
+
+	monday := time.Date(2016, time.March, 7, 12, 0, 0, 0, newYork)
+	holiday := time.Date(2016, time.March, 9, 12, 0, 0, 0, newYork)
+	holidayUTC := time.Date(2016, time.March, 10, 3, 0, 0, 0, time.UTC)
+	thursday := time.Date(2016, time.March, 10, 12, 0, 0, 0, newYork)
+
+	if schedule.IsInRange(holiday) || schedule.IsInRange(holidayUTC) {
+		t.Error("expected holiday out of range")
+	}
+
+	if !schedule.IsInRange(monday) || !schedule.IsInRange(thursday) {
+		t.Error("expected days around the holiday in range")
+	}
+
+	if !schedule.IsInSameRange(monday, thursday) {
+		t.Error("expected holiday not to end the weekly range")
+	}
+}
+
+func TestSessionSchedule_DaylightSaving(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	//daylight saving time starts 2016-03-13 02:00 in New York
+	daily := newTestSchedule(t, map[string]string{config.StartTime: "08:00:00", config.EndTime: "17:00:00", config.TimeZone: "America/New_York"})
+	overnight := newTestSchedule(t, map[string]string{config.StartTime: "22:00:00", config.EndTime: "06:00:00", config.TimeZone: "America/New_York"})
+	weekly := newTestSchedule(t, map[string]string{config.StartTime: "17:00:00", config.EndTime: "17:00:00", config.StartDay: "Sun", config.EndDay: "Fri", config.TimeZone: "America/New_York"})
+
+	var testCases = []struct {
+		schedule *sessionSchedule
+		time     time.Time
+		expected bool
+	}{
+		{daily, time.Date(2016, time.March, 11, 13, 0, 0, 0, time.UTC), true},
+		{daily, time.Date(2016, time.March, 11, 12, 59, 59, 0, time.UTC), false},
+		{daily, time.Date(2016, time.March, 14, 12, 0, 0, 0, time.UTC), true},
+		{daily, time.Date(2016, time.March, 14, 11, 59, 59, 0, time.UTC), false},
+		{daily, time.Date(2016, time.March, 14, 21, 0, 0, 0, time.UTC), true},
+		{daily, time.Date(2016, time.March, 14, 21, 0, 1, 0, time.UTC), false},
+		{weekly, time.Date(2016, time.March, 13, 21, 0, 0, 0, time.UTC), true},
+		{weekly, time.Date(2016, time.March, 13, 20, 59, 59, 0, time.UTC), false},
+		{weekly, time.Date(2016, time.March, 11, 22, 0, 0, 0, time.UTC), true},
+		{weekly, time.Date(2016, time.March, 11, 22, 0, 1, 0, time.UTC), false},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.schedule.IsInRange(tc.time); actual != tc.expected {
+			t.Errorf("%v: expected %v got %v", tc.time, tc.expected, actual)
+		}
+	}
+
+	beforeChange := time.Date(2016, time.March, 12, 23, 0, 0, 0, newYork)
+	afterChange := time.Date(2016, time.March, 13, 5, 0, 0, 0, newYork)
+	if !overnight.IsInSameRange(beforeChange, afterChange) {
+		t.Error("expected overnight period across the change in the same range")
+	}
+
+	if weekly.IsInSameRange(time.Date(2016, time.March, 11, 12, 0, 0, 0, newYork), time.Date(2016, time.March, 14, 12, 0, 0, 0, newYork)) {
+		t.Error("expected weeks either side of the change in different ranges")
+	}
+}
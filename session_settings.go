@@ -3,12 +3,17 @@ package quickfix
 import (
 	"errors"
 	"fmt"
+	"github.com/quickfixgo/quickfix/datadictionary"
 	"strconv"
+	"strings"
 )
 
 //SessionSettings maps session settings to values with typed accessors.
 type SessionSettings struct {
 	settings map[string]string
+
+	//dataDictionaries are parsed data dictionaries set in place of a path
+	dataDictionaries map[string]*datadictionary.DataDictionary
 }
 
 //Init initializes or resets SessionSettings
@@ -32,6 +37,59 @@ func (s *SessionSettings) Set(setting string, val string) {
 	}
 
 	s.settings[setting] = val
+	delete(s.dataDictionaries, setting)
+}
+
+//SetDataDictionary assigns a parsed data dictionary to a data dictionary setting, such as DataDictionary or AppDataDictionary.FIX.5.0SP2, in place of a path.
+//A parsed data dictionary may be shared by several sessions.
+func (s *SessionSettings) SetDataDictionary(setting string, dataDictionary *datadictionary.DataDictionary) {
+	if s.dataDictionaries == nil {
+		s.dataDictionaries = make(map[string]*datadictionary.DataDictionary)
+	}
+
+	s.dataDictionaries[setting] = dataDictionary
+	delete(s.settings, setting)
+}
+
+//DataDictionary returns the data dictionary of setting, either set parsed or parsed from the path set, nil if not set.
+func (s *SessionSettings) DataDictionary(setting string) (*datadictionary.DataDictionary, error) {
+	return s.dataDictionary(setting)
+}
+
+//dataDictionary returns the data dictionary of setting, either set parsed or parsed from the path set, nil if not set.
+func (s *SessionSettings) dataDictionary(setting string) (*datadictionary.DataDictionary, error) {
+	if dataDictionary, ok := s.dataDictionaries[setting]; ok {
+		return dataDictionary, nil
+	}
+
+	path, err := s.Setting(setting)
+	if err != nil {
+		return nil, nil
+	}
+
+	dataDictionary, err := datadictionary.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("error on %v: %v", setting, err)
+	}
+
+	return dataDictionary, nil
+}
+
+//dataDictionarySettings returns the data dictionary settings with the prefix, set as a path or parsed.
+func (s *SessionSettings) dataDictionarySettings(prefix string) (settings []string) {
+	for setting := range s.settings {
+		if strings.HasPrefix(setting, prefix) {
+			settings = append(settings, setting)
+		}
+	}
+
+	for setting := range s.dataDictionaries {
+		if strings.HasPrefix(setting, prefix) {
+			settings = append(settings, setting)
+		}
+	}
+
+	return
 }
 
 //HasSetting returns true if a setting is set, false if not
@@ -61,6 +119,17 @@ func (s *SessionSettings) IntSetting(setting string) (int, error) {
 	return strconv.Atoi(stringVal)
 }
 
+//FloatSetting returns the requested setting parsed as a float64.  Returns an errror if the setting is not set or cannot be parsed as a float64.
+func (s *SessionSettings) FloatSetting(setting string) (float64, error) {
+	stringVal, err := s.Setting(setting)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(stringVal, 64)
+}
+
 //BoolSetting returns the requested setting parsed as a boolean.  Returns an errror if the setting is not set or cannot be parsed as a bool.
 func (s SessionSettings) BoolSetting(setting string) (bool, error) {
 	stringVal, err := s.Setting(setting)
@@ -82,7 +151,11 @@ func (s SessionSettings) BoolSetting(setting string) (bool, error) {
 
 func (s *SessionSettings) overlay(overlay *SessionSettings) {
 	for key, val := range overlay.settings {
-		s.settings[key] = val
+		s.Set(key, val)
+	}
+
+	for key, dataDictionary := range overlay.dataDictionaries {
+		s.SetDataDictionary(key, dataDictionary)
 	}
 }
 
@@ -93,5 +166,9 @@ func (s *SessionSettings) clone() *SessionSettings {
 		sClone.settings[k] = v
 	}
 
+	for k, v := range s.dataDictionaries {
+		sClone.SetDataDictionary(k, v)
+	}
+
 	return sClone
 }
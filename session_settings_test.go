@@ -2,6 +2,7 @@ package quickfix
 
 import (
 	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/datadictionary"
 	"testing"
 )
 
@@ -54,6 +55,28 @@ func TestSessionSettings_IntSettings(t *testing.T) {
 	}
 }
 
+func TestSessionSettings_FloatSettings(t *testing.T) {
+	s := NewSessionSettings()
+	if _, err := s.FloatSetting(config.TestRequestDelayMultiplier); err == nil {
+		t.Error("Expected error for unknown setting")
+	}
+
+	s.Set(config.TestRequestDelayMultiplier, "notafloat")
+	if _, err := s.FloatSetting(config.TestRequestDelayMultiplier); err == nil {
+		t.Error("Expected error for unparsable value")
+	}
+
+	s.Set(config.TestRequestDelayMultiplier, "1.5")
+	val, err := s.FloatSetting(config.TestRequestDelayMultiplier)
+	if err != nil {
+		t.Error("Unexpected err", err)
+	}
+
+	if val != 1.5 {
+		t.Errorf("Expected %v, got %v", 1.5, val)
+	}
+}
+
 func TestSessionSettings_BoolSettings(t *testing.T) {
 	s := NewSessionSettings()
 	if _, err := s.BoolSetting(config.ResetOnLogon); err == nil {
@@ -155,3 +178,41 @@ func TestSessionSettings_Overlay(t *testing.T) {
 		}
 	}
 }
+
+func TestSessionSettings_DataDictionary(t *testing.T) {
+	fix42, err := datadictionary.Parse("spec/FIX42.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	global := NewSessionSettings()
+	global.SetDataDictionary(config.DataDictionary, fix42)
+
+	if dataDictionary, err := global.dataDictionary(config.DataDictionary); err != nil || dataDictionary != fix42 {
+		t.Errorf("Expected parsed data dictionary set got %v, %v", dataDictionary, err)
+	}
+
+	overlay := NewSessionSettings()
+	overlay.Set(config.DataDictionary, "spec/FIX44.xml")
+
+	s := global.clone()
+	s.overlay(overlay)
+
+	dataDictionary, err := s.dataDictionary(config.DataDictionary)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dataDictionary == fix42 || dataDictionary.FIXType != "FIX" || dataDictionary.Major != 4 || dataDictionary.Minor != 4 {
+		t.Error("Expected session path to override global parsed data dictionary")
+	}
+
+	if dataDictionary, err := NewSessionSettings().dataDictionary(config.DataDictionary); err != nil || dataDictionary != nil {
+		t.Errorf("Expected no data dictionary got %v, %v", dataDictionary, err)
+	}
+
+	s.Set(config.DataDictionary, "spec/missing.xml")
+	if _, err := s.dataDictionary(config.DataDictionary); err == nil {
+		t.Error("Expected error for missing data dictionary")
+	}
+}
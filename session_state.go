@@ -1,6 +1,116 @@
 package quickfix
 
+import "sync"
+
 type sessionState interface {
 	FixMsgIn(*Session, Message) (nextState sessionState)
 	Timeout(*Session, event) (nextState sessionState)
 }
+
+//SessionState is the state of a Session, reported to Applications implementing SessionStateListener.
+type SessionState int
+
+const (
+	//StateDisconnected is the state of a session without a connection.
+	StateDisconnected SessionState = iota
+
+	//StateAwaitingLogon is the state of an accepted connection until the Logon of the counterparty is received.
+	StateAwaitingLogon
+
+	//StateLogonSent is the state of an initiated connection until the Logon response of the counterparty is received.
+	StateLogonSent
+
+	//StateLoggedOn is the state of a logged on session.
+	StateLoggedOn
+
+	//StateAwaitingResend is the state of a logged on session waiting for messages requested with a ResendRequest.
+	StateAwaitingResend
+
+	//StateTestRequestSent is the state of a logged on session waiting for a response to a TestRequest.
+	StateTestRequestSent
+
+	//StateLogoutSent is the state of a session waiting for the Logout response of the counterparty.
+	StateLogoutSent
+)
+
+var sessionStateNames = map[SessionState]string{
+	StateDisconnected:    "Disconnected",
+	StateAwaitingLogon:   "AwaitingLogon",
+	StateLogonSent:       "LogonSent",
+	StateLoggedOn:        "LoggedOn",
+	StateAwaitingResend:  "AwaitingResend",
+	StateTestRequestSent: "TestRequestSent",
+	StateLogoutSent:      "LogoutSent",
+}
+
+func (s SessionState) String() string {
+	return sessionStateNames[s]
+}
+
+//stateOf returns the SessionState of state.
+func (s *Session) stateOf(state sessionState) SessionState {
+	switch state.(type) {
+	case logonState:
+		if s.initiateLogon {
+			return StateLogonSent
+		}
+		return StateAwaitingLogon
+	case inSession:
+		return StateLoggedOn
+	case resendState:
+		return StateAwaitingResend
+	case pendingTimeout:
+		return StateTestRequestSent
+	case logoutState:
+		return StateLogoutSent
+	}
+
+	return StateDisconnected
+}
+
+//transition sets the current state of the session, notifying the Application if it implements SessionStateListener and the SessionState changes.
+func (s *Session) transition(nextState sessionState, reason error) {
+	s.currentState = nextState
+
+	from, to := s.state, s.stateOf(nextState)
+	s.updateRecoveryState(to)
+	s.updateSendQueue(to)
+
+	if from == to {
+		return
+	}
+	s.state = to
+
+	s.trafficLock.Lock()
+	s.traffic.state = to
+	s.trafficLock.Unlock()
+
+	if listener, ok := s.application.(SessionStateListener); ok {
+		listener.OnSessionStateChange(s.sessionID, from, to, reason)
+	}
+
+	for _, listener := range sessionStateListeners() {
+		listener.OnSessionStateChange(s.sessionID, from, to, reason)
+	}
+}
+
+var globalSessionStateListeners struct {
+	lock      sync.RWMutex
+	listeners []SessionStateListener
+}
+
+//AddSessionStateListener adds listener, notified of each change of SessionState of every session, after the Application.
+//Listeners are called from the session goroutine and must not block.
+func AddSessionStateListener(listener SessionStateListener) {
+	globalSessionStateListeners.lock.Lock()
+	defer globalSessionStateListeners.lock.Unlock()
+
+	globalSessionStateListeners.listeners = append(globalSessionStateListeners.listeners, listener)
+}
+
+func sessionStateListeners() []SessionStateListener {
+	globalSessionStateListeners.lock.RLock()
+	defer globalSessionStateListeners.lock.RUnlock()
+
+	return globalSessionStateListeners.listeners
+}
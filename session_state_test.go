@@ -0,0 +1,92 @@
+package quickfix
+
+import (
+	"testing"
+)
+
+type stateChange struct {
+	from, to SessionState
+	reason   error
+}
+
+type stateListenerClient struct {
+	TestClient
+	changes []stateChange
+}
+
+func (c *stateListenerClient) OnSessionStateChange(sessionID SessionID, from, to SessionState, reason error) {
+	c.changes = append(c.changes, stateChange{from, to, reason})
+}
+
+func TestSession_StateTransitions(t *testing.T) {
+	app := &stateListenerClient{}
+	session := &Session{application: app, log: nullLog{}}
+
+	if _, err := session.initiate(); err != nil {
+		t.Fatal(err)
+	}
+	session.transition(inSession{}, nil)
+	session.transition(inSession{}, nil)
+	session.transition(resendState{}, nil)
+	session.transition(inSession{}, nil)
+	session.transition(pendingTimeout{}, nil)
+	session.transition(session.currentState.Timeout(session, peerTimeout), peerTimeout.reason())
+
+	expected := []stateChange{
+		{StateDisconnected, StateLogonSent, nil},
+		{StateLogonSent, StateLoggedOn, nil},
+		{StateLoggedOn, StateAwaitingResend, nil},
+		{StateAwaitingResend, StateLoggedOn, nil},
+		{StateLoggedOn, StateTestRequestSent, nil},
+		{StateTestRequestSent, StateDisconnected, peerTimeout.reason()},
+	}
+
+	if len(app.changes) != len(expected) {
+		t.Fatalf("Expected %v changes, got %v", expected, app.changes)
+	}
+
+	for i, change := range app.changes {
+		if change != expected[i] {
+			t.Errorf("Expected change %v to be %v got %v", i, expected[i], change)
+		}
+	}
+}
+
+func TestSession_StateAccept(t *testing.T) {
+	app := &stateListenerClient{}
+	session := &Session{application: app}
+
+	if _, err := session.accept(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(app.changes) != 1 || app.changes[0].to != StateAwaitingLogon {
+		t.Errorf("Expected change to %v, got %v", StateAwaitingLogon, app.changes)
+	}
+
+	if StateAwaitingLogon.String() != "AwaitingLogon" {
+		t.Errorf("Unexpected name %v", StateAwaitingLogon.String())
+	}
+}
+
+func TestAddSessionStateListener(t *testing.T) {
+	listener := &stateListenerClient{}
+	AddSessionStateListener(listener)
+	defer func() {
+		globalSessionStateListeners.lock.Lock()
+		defer globalSessionStateListeners.lock.Unlock()
+
+		globalSessionStateListeners.listeners = nil
+	}()
+
+	app := &stateListenerClient{}
+	session := &Session{application: app, log: nullLog{}}
+	if _, err := session.initiate(); err != nil {
+		t.Fatal(err)
+	}
+	session.transition(inSession{}, nil)
+
+	if len(listener.changes) != 2 || listener.changes[1] != (stateChange{StateLogonSent, StateLoggedOn, nil}) || len(app.changes) != 2 {
+		t.Errorf("Expected the listener notified with the Application, got %v", listener.changes)
+	}
+}
@@ -1,6 +1,7 @@
 package quickfix
 
 import (
+	"github.com/quickfixgo/quickfix/datadictionary"
 	"github.com/quickfixgo/quickfix/fix"
 	"github.com/quickfixgo/quickfix/fix/field"
 	"github.com/quickfixgo/quickfix/fix/tag"
@@ -80,6 +81,7 @@ func TestSession_CheckCorrectCompID(t *testing.T) {
 func TestSession_CheckBeginString(t *testing.T) {
 	session := Session{
 		sessionID: SessionID{BeginString: "FIX.4.2"},
+		header:    newHeaderTemplate(SessionID{BeginString: "FIX.4.2"}),
 	}
 
 	builder := getBuilder()
@@ -295,7 +297,7 @@ func TestSession_CheckToAdminCalled(t *testing.T) {
 		<- otherEnd
 	} ()
 
-	session := Session{store: store, application: app, messageOut: otherEnd}
+	session := Session{store: store, application: app, messageOut: otherEnd, header: newHeaderTemplate(SessionID{})}
 	session.toSend = make(chan MessageBuilder)
 	session.sessionEvent = make(chan event)
 	session.stateTimer = eventTimer{Task: func() { session.sessionEvent <- needHeartbeat }}
@@ -341,7 +343,7 @@ func TestSession_CheckToAppCalled(t *testing.T) {
 		<- otherEnd
 	} ()
 
-	session := Session{store: store, application: app, messageOut: otherEnd}
+	session := Session{store: store, application: app, messageOut: otherEnd, header: newHeaderTemplate(SessionID{})}
 	session.toSend = make(chan MessageBuilder)
 	session.sessionEvent = make(chan event)
 	session.stateTimer = eventTimer{Task: func() { session.sessionEvent <- needHeartbeat }}
@@ -367,3 +369,98 @@ func TestSession_CheckToAppCalled(t *testing.T) {
 		t.Error("Toadmin should not have been called, instead was called", app.adminCalled, "times")
 	}
 }
+
+func TestSession_SendValidatesAgainstCounterpartyDictionary(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX42.xml")
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	otherEnd := make(chan []byte)
+	go func() {
+		for range otherEnd {
+		}
+	}()
+
+	session := Session{
+		sessionID:                  SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"},
+		header:                     newHeaderTemplate(SessionID{BeginString: fix.BeginString_FIX42, SenderCompID: "TW", TargetCompID: "ISLD"}),
+		store:                      store,
+		application:                &TestClient{},
+		messageOut:                 otherEnd,
+		log:                        nullLog{},
+		counterpartyDataDictionary: dict,
+		stateTimer:                 eventTimer{Task: func() {}},
+	}
+
+	order := NewMessageBuilder()
+	order.Header().Set(field.NewMsgType("D"))
+	order.Body().Set(field.NewClOrdID("ID"))
+
+	err = session.send(order)
+	if _, ok := err.(OutboundRejectError); !ok {
+		t.Fatal("Expected OutboundRejectError, got ", err)
+	}
+
+	if store.NextSenderMsgSeqNum() != 1 {
+		t.Error("Rejected message should not consume a sequence number, next is ", store.NextSenderMsgSeqNum())
+	}
+
+	order.Body().Set(field.NewHandlInst("1"))
+	order.Body().Set(field.NewSymbol("TSLA"))
+	order.Body().Set(field.NewSide("1"))
+	order.Body().Set(fix.NewUTCTimestampField(tag.TransactTime, time.Now()))
+	order.Body().Set(field.NewOrdType("1"))
+
+	if err = session.send(order); err != nil {
+		t.Error("Unexpected error ", err)
+	}
+
+	if store.NextSenderMsgSeqNum() != 2 {
+		t.Error("Expected next sender seq num 2, got ", store.NextSenderMsgSeqNum())
+	}
+}
+
+func TestSession_CheckSendingTimeMaxLatency(t *testing.T) {
+	session := Session{maxLatency: 10 * time.Second}
+	builder := getBuilder()
+
+	receiveTime := time.Now()
+	builder.Header().Set(fix.NewUTCTimestampField(tag.SendingTime, receiveTime.Add(-30*time.Second)))
+	msgBytes, _ := builder.Build()
+	msg, _ := parseMessage(msgBytes)
+	msg.ReceiveTime = receiveTime
+
+	err := session.checkSendingTime(*msg)
+	if err == nil || err.RejectReason() != rejectReasonSendingTimeAccuracyProblem {
+		t.Error("Expected SendingTime accuracy problem beyond MaxLatency, got ", err)
+	}
+
+	if skew := session.ClockSkew(); skew < 29*time.Second || skew > 31*time.Second {
+		t.Error("Expected observed skew of 30s, got ", skew)
+	}
+
+	session.skipLatencyCheck = true
+	if err := session.checkSendingTime(*msg); err != nil {
+		t.Error("Unexpected error with latency check disabled ", err)
+	}
+
+	builder.Header().Set(fix.NewUTCTimestampField(tag.SendingTime, receiveTime.Add(5*time.Second)))
+	msgBytes, _ = builder.Build()
+	msg, _ = parseMessage(msgBytes)
+	msg.ReceiveTime = receiveTime
+
+	session.skipLatencyCheck = false
+	if err := session.checkSendingTime(*msg); err != nil {
+		t.Error("Unexpected error within MaxLatency ", err)
+	}
+
+	if skew := session.ClockSkew(); skew > -4*time.Second || skew < -6*time.Second {
+		t.Error("Expected observed skew of -5s, got ", skew)
+	}
+
+	if maxSkew := session.MaxClockSkew(); maxSkew < 29*time.Second {
+		t.Error("Expected max skew of 30s, got ", maxSkew)
+	}
+}
@@ -5,19 +5,26 @@ import (
 	"fmt"
 	"github.com/quickfixgo/quickfix/config"
 	"io"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 )
 
 //The Settings type represents a collection of global and session settings.
 type Settings struct {
 	globalSettings  *SessionSettings
 	sessionSettings map[SessionID]*SessionSettings
+
+	//profiles are the named settings sessions inherit by their Profile setting, overlaying the global settings
+	profiles map[string]*SessionSettings
 }
 
 //Init initializes or resets a Settings instance
 func (s *Settings) Init() {
 	s.globalSettings = NewSessionSettings()
 	s.sessionSettings = make(map[SessionID]*SessionSettings)
+	s.profiles = make(map[string]*SessionSettings)
 }
 
 func (s *Settings) lazyInit() {
@@ -59,17 +66,114 @@ func sessionIDFromSessionSettings(globalSettings *SessionSettings, sessionSettin
 
 //ParseSettings creates and initializes a Settings instance with config parsed from a Reader.
 //Returns error if the config is has parse errors
+//
+//Setting values may reference environment variables as ${NAME}, or ${NAME:-default} to use default if NAME is not set. $${NAME} is the literal ${NAME}.
+//A line of include <path> parses the config file at path in its place, such as a fragment of defaults shared by several config files.
+//Included paths are relative to the working directory, see ParseSettingsFile.
+//
+//A [PROFILE <name>] section declares settings inherited by the sessions setting Profile=<name>, overriding the [DEFAULT] settings,
+//such as a lowLatency profile shared by several sessions. A profile must be declared before the sessions inheriting it.
 func ParseSettings(reader io.Reader) (*Settings, error) {
-	s := NewSettings()
+	p := &settingsParser{s: NewSettings(), including: make(map[string]bool)}
+	return p.parseSettings(func() error { return p.parse(reader, "") })
+}
 
-	scanner := bufio.NewScanner(reader)
-	blankRegEx := regexp.MustCompile(`^\s*$`)
-	commentRegEx := regexp.MustCompile(`^#.*`)
-	defaultRegEx := regexp.MustCompile(`^\[DEFAULT\]\s*$`)
-	sessionRegEx := regexp.MustCompile(`^\[SESSION\]\s*$`)
-	settingRegEx := regexp.MustCompile(`^(.*)=(.*)$`)
+//ParseSettingsFile creates and initializes a Settings instance with config parsed from the file at path, as ParseSettings.
+//Paths included are relative to the directory of the file including them.
+//Files named .yaml or .yml are parsed as ParseYAMLSettings, and .json as ParseJSONSettings.
+func ParseSettingsFile(path string) (*Settings, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseSettingsDocument(path, ParseYAMLSettings)
+	case ".json":
+		return parseSettingsDocument(path, ParseJSONSettings)
+	}
+
+	p := &settingsParser{s: NewSettings(), including: make(map[string]bool)}
+	return p.parseSettings(func() error { return p.parseFile(path) })
+}
+
+//settingsParser parses a config file, and the files it includes, into Settings.
+type settingsParser struct {
+	s *Settings
+
+	//settings are the settings of the section being parsed, nil before the first section
+	settings *SessionSettings
+
+	//session is true if the section being parsed is a [SESSION]
+	session bool
+
+	//including are the files being parsed, to detect circular includes
+	including map[string]bool
+}
+
+var (
+	blankRegEx   = regexp.MustCompile(`^\s*$`)
+	commentRegEx = regexp.MustCompile(`^#.*`)
+	defaultRegEx = regexp.MustCompile(`^\[DEFAULT\]\s*$`)
+	sessionRegEx = regexp.MustCompile(`^\[SESSION\]\s*$`)
+	profileRegEx = regexp.MustCompile(`^\[PROFILE\s+(\S+)\]\s*$`)
+	includeRegEx = regexp.MustCompile(`^include\s+(.+?)\s*$`)
+	settingRegEx = regexp.MustCompile(`^(.*)=(.*)$`)
 
-	var settings *SessionSettings
+	//variableRegEx matches the references to environment variables in setting values
+	variableRegEx = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+)
+
+//parseSettings runs parse and adds the last session declared.
+func (p *settingsParser) parseSettings(parse func() error) (*Settings, error) {
+	if err := parse(); err != nil {
+		return p.s, err
+	}
+
+	if err := p.endSection(); err != nil {
+		return p.s, err
+	}
+
+	if len(p.s.sessionSettings) == 0 {
+		return p.s, fmt.Errorf("no sessions declared")
+	}
+
+	return p.s, nil
+}
+
+//endSection adds the session of the section parsed, if a [SESSION].
+func (p *settingsParser) endSection() error {
+	if !p.session {
+		return nil
+	}
+	p.session = false
+
+	_, err := p.s.AddSession(p.settings)
+	return err
+}
+
+//parseFile parses the config file at path.
+func (p *settingsParser) parseFile(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if p.including[absPath] {
+		return fmt.Errorf("circular include of %v", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	p.including[absPath] = true
+	defer delete(p.including, absPath)
+
+	return p.parse(file, filepath.Dir(path))
+}
+
+//parse parses the config read from reader, including paths relative to dir.
+func (p *settingsParser) parse(reader io.Reader, dir string) error {
+	scanner := bufio.NewScanner(reader)
 
 	lineNumber := 0
 	for scanner.Scan() {
@@ -81,35 +185,85 @@ func ParseSettings(reader io.Reader) (*Settings, error) {
 			continue
 
 		case defaultRegEx.MatchString(line):
-			settings = s.GlobalSettings()
+			if err := p.endSection(); err != nil {
+				return err
+			}
+			p.settings = p.s.GlobalSettings()
 
 		case sessionRegEx.MatchString(line):
-			if settings != nil && settings != s.GlobalSettings() {
-				if _, err := s.AddSession(settings); err != nil {
-					return nil, err
-				}
+			if err := p.endSection(); err != nil {
+				return err
+			}
+			p.settings, p.session = NewSessionSettings(), true
+
+		case profileRegEx.MatchString(line):
+			if err := p.endSection(); err != nil {
+				return err
+			}
+
+			p.settings = NewSessionSettings()
+			if err := p.s.AddProfile(profileRegEx.FindStringSubmatch(line)[1], p.settings); err != nil {
+				return fmt.Errorf("error parsing line %v: %v", lineNumber, err)
+			}
+
+		case includeRegEx.MatchString(line):
+			path, err := expandEnv(includeRegEx.FindStringSubmatch(line)[1])
+			if err != nil {
+				return fmt.Errorf("error parsing line %v: %v", lineNumber, err)
+			}
+
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+			if err := p.parseFile(path); err != nil {
+				return fmt.Errorf("error including %v on line %v: %v", path, lineNumber, err)
 			}
-			settings = NewSessionSettings()
 
 		case settingRegEx.MatchString(line):
+			if p.settings == nil {
+				return fmt.Errorf("error parsing line %v: setting outside of a [DEFAULT] or [SESSION] section", lineNumber)
+			}
+
 			parts := settingRegEx.FindStringSubmatch(line)
-			settings.Set(parts[1], parts[2])
+			value, err := expandEnv(parts[2])
+			if err != nil {
+				return fmt.Errorf("error parsing line %v: %v", lineNumber, err)
+			}
+			p.settings.Set(parts[1], value)
 
 		default:
-			return s, fmt.Errorf("error parsing line %v", lineNumber)
+			return fmt.Errorf("error parsing line %v", lineNumber)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return s, err
-	}
+	return scanner.Err()
+}
 
-	if settings == nil || settings == s.GlobalSettings() {
-		return s, fmt.Errorf("no sessions declared")
-	}
-	_, err := s.AddSession(settings)
+//expandEnv replaces the references to environment variables in value. Returns an error if a variable without a default is not set.
+func expandEnv(value string) (string, error) {
+	var err error
+	expanded := variableRegEx.ReplaceAllStringFunc(value, func(reference string) string {
+		if strings.HasPrefix(reference, "$$") {
+			return reference[1:]
+		}
+
+		parts := variableRegEx.FindStringSubmatch(reference)
+		if val, ok := os.LookupEnv(parts[1]); ok {
+			return val
+		}
 
-	return s, err
+		if len(parts[2]) > 0 {
+			return parts[3]
+		}
+
+		if err == nil {
+			err = fmt.Errorf("environment variable %v not set", parts[1])
+		}
+		return reference
+	})
+
+	return expanded, err
 }
 
 //GlobalSettings are default setting inherited by all session settings.
@@ -122,20 +276,25 @@ func (s *Settings) GlobalSettings() *SessionSettings {
 func (s *Settings) SessionSettings() map[SessionID]*SessionSettings {
 	allSessionSettings := make(map[SessionID]*SessionSettings)
 
-	for sessionID, settings := range s.sessionSettings {
-		cloneSettings := s.globalSettings.clone()
-		cloneSettings.overlay(settings)
-		allSessionSettings[sessionID] = cloneSettings
+	for sessionID := range s.sessionSettings {
+		allSessionSettings[sessionID] = s.sessionSettingsFor(sessionID)
 	}
 
 	return allSessionSettings
 }
 
-//AddSession adds Session Settings to Settings instance. Returns an error if session settings with duplicate sessionID has already been added
+//AddSession adds Session Settings to Settings instance. Returns an error if session settings with duplicate sessionID has already been added,
+//or if the session inherits a profile that has not been added.
 func (s *Settings) AddSession(sessionSettings *SessionSettings) (SessionID, error) {
 	s.lazyInit()
 
-	sessionID := sessionIDFromSessionSettings(s.GlobalSettings(), sessionSettings)
+	if profile := s.profileOf(sessionSettings); profile != "" {
+		if _, ok := s.profiles[profile]; !ok {
+			return SessionID{}, fmt.Errorf("unknown profile %v", profile)
+		}
+	}
+
+	sessionID := sessionIDFromSessionSettings(s.GlobalSettings(), s.overlaySettings(sessionSettings))
 	if _, dup := s.sessionSettings[sessionID]; dup {
 		return sessionID, fmt.Errorf("duplicate session configured for %v", sessionID)
 	}
@@ -144,3 +303,68 @@ func (s *Settings) AddSession(sessionSettings *SessionSettings) (SessionID, erro
 
 	return sessionID, nil
 }
+
+//RemoveSession removes the Session Settings for sessionID. Returns an error if no session settings have been added for sessionID
+func (s *Settings) RemoveSession(sessionID SessionID) error {
+	s.lazyInit()
+
+	if _, ok := s.sessionSettings[sessionID]; !ok {
+		return fmt.Errorf("no session configured for %v", sessionID)
+	}
+
+	delete(s.sessionSettings, sessionID)
+
+	return nil
+}
+
+//AddProfile adds the settings of a profile, inherited by the sessions setting Profile to name.
+//Returns an error if a profile of the same name has already been added.
+func (s *Settings) AddProfile(name string, profileSettings *SessionSettings) error {
+	s.lazyInit()
+
+	if name == "" {
+		return fmt.Errorf("profile name required")
+	}
+
+	if _, dup := s.profiles[name]; dup {
+		return fmt.Errorf("duplicate profile %v", name)
+	}
+
+	s.profiles[name] = profileSettings
+
+	return nil
+}
+
+//Profile returns the settings of the profile name, false if no such profile has been added.
+func (s *Settings) Profile(name string) (*SessionSettings, bool) {
+	s.lazyInit()
+
+	profileSettings, ok := s.profiles[name]
+	return profileSettings, ok
+}
+
+//profileOf returns the profile sessionSettings inherit, set by the session or the global settings, empty if none.
+func (s *Settings) profileOf(sessionSettings *SessionSettings) string {
+	for _, settings := range []*SessionSettings{sessionSettings, s.globalSettings} {
+		if profile, err := settings.Setting(config.Profile); err == nil {
+			return profile
+		}
+	}
+
+	return ""
+}
+
+//overlaySettings returns sessionSettings overlaying the settings of its profile, overlaying global settings.
+func (s *Settings) overlaySettings(sessionSettings *SessionSettings) *SessionSettings {
+	settings := s.globalSettings.clone()
+	if profileSettings, ok := s.profiles[s.profileOf(sessionSettings)]; ok {
+		settings.overlay(profileSettings)
+	}
+	settings.overlay(sessionSettings)
+	return settings
+}
+
+//sessionSettingsFor returns the settings of sessionID overlaying its profile and global settings.
+func (s *Settings) sessionSettingsFor(sessionID SessionID) *SessionSettings {
+	return s.overlaySettings(s.sessionSettings[sessionID])
+}
@@ -0,0 +1,259 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"strconv"
+)
+
+//SettingsBuilder builds Settings in code, such as from sessions defined in a database, rather than by parsing a configuration file.
+//Settings are validated by Build.
+//
+//	settings, err := quickfix.NewSettingsBuilder().
+//		GlobalString(config.SocketConnectHost, "fix.example.com").
+//		GlobalInt(config.SocketConnectPort, 5001).
+//		Session(func(s *quickfix.SessionSettingsBuilder) {
+//			s.BeginString("FIX.4.4").SenderCompID("CLIENT").TargetCompID("VENUE").HeartBtInt(30)
+//		}).
+//		Build()
+type SettingsBuilder struct {
+	global   *SessionSettingsBuilder
+	profiles map[string]*SessionSettingsBuilder
+	sessions []*SessionSettingsBuilder
+}
+
+//NewSettingsBuilder returns a builder of Settings without global settings or sessions.
+func NewSettingsBuilder() *SettingsBuilder {
+	return &SettingsBuilder{global: newSessionSettingsBuilder(), profiles: make(map[string]*SessionSettingsBuilder)}
+}
+
+//Global configures the global settings, inherited by all sessions.
+func (b *SettingsBuilder) Global(configure func(*SessionSettingsBuilder)) *SettingsBuilder {
+	configure(b.global)
+	return b
+}
+
+//GlobalString sets the global setting to value.
+func (b *SettingsBuilder) GlobalString(setting, value string) *SettingsBuilder {
+	b.global.String(setting, value)
+	return b
+}
+
+//GlobalInt sets the global setting to value.
+func (b *SettingsBuilder) GlobalInt(setting string, value int) *SettingsBuilder {
+	b.global.Int(setting, value)
+	return b
+}
+
+//GlobalBool sets the global setting to value.
+func (b *SettingsBuilder) GlobalBool(setting string, value bool) *SettingsBuilder {
+	b.global.Bool(setting, value)
+	return b
+}
+
+//Profile configures the settings of the profile name, inherited by the sessions setting Profile to name.
+func (b *SettingsBuilder) Profile(name string, configure func(*SessionSettingsBuilder)) *SettingsBuilder {
+	profile, ok := b.profiles[name]
+	if !ok {
+		profile = newSessionSettingsBuilder()
+		b.profiles[name] = profile
+	}
+
+	configure(profile)
+	return b
+}
+
+//Session adds a session, configured by configure.
+func (b *SettingsBuilder) Session(configure func(*SessionSettingsBuilder)) *SettingsBuilder {
+	session := newSessionSettingsBuilder()
+	configure(session)
+	b.sessions = append(b.sessions, session)
+	return b
+}
+
+//Build returns the Settings built. Returns an error if a setting is invalid, no sessions are added, a session is not identified by
+//BeginString, SenderCompID and TargetCompID, a FIXT.1.1 session is missing its DefaultApplVerID,
+//the schedule of a session is invalid, a session inherits a profile not configured, or sessions are duplicated.
+func (b *SettingsBuilder) Build() (*Settings, error) {
+	if b.global.err != nil {
+		return nil, fmt.Errorf("global settings: %v", b.global.err)
+	}
+
+	if len(b.sessions) == 0 {
+		return nil, fmt.Errorf("no sessions declared")
+	}
+
+	settings := NewSettings()
+	settings.GlobalSettings().overlay(b.global.settings)
+
+	for name, profile := range b.profiles {
+		if profile.err != nil {
+			return nil, fmt.Errorf("profile %v: %v", name, profile.err)
+		}
+
+		if err := settings.AddProfile(name, profile.settings.clone()); err != nil {
+			return nil, err
+		}
+	}
+
+	for n, session := range b.sessions {
+		if session.err != nil {
+			return nil, fmt.Errorf("session %v: %v", n+1, session.err)
+		}
+
+		if profile := settings.profileOf(session.settings); profile != "" {
+			if _, ok := settings.Profile(profile); !ok {
+				return nil, fmt.Errorf("session %v: unknown profile %v", n+1, profile)
+			}
+		}
+
+		if err := validateSessionSettings(settings.overlaySettings(session.settings)); err != nil {
+			return nil, fmt.Errorf("session %v: %v", n+1, err)
+		}
+
+		if _, err := settings.AddSession(session.settings.clone()); err != nil {
+			return nil, err
+		}
+	}
+
+	return settings, nil
+}
+
+//validateSessionSettings returns an error unless the settings of a session, overlaying its profile and the global settings, identify a session
+//of a known BeginString with a valid schedule.
+func validateSessionSettings(settings *SessionSettings) error {
+	for _, setting := range []string{config.BeginString, config.SenderCompID, config.TargetCompID} {
+		if !settings.HasSetting(setting) {
+			return requiredConfigurationMissing(setting)
+		}
+	}
+
+	beginString, _ := settings.Setting(config.BeginString)
+	switch beginString {
+	case fix.BeginString_FIX40, fix.BeginString_FIX41, fix.BeginString_FIX42, fix.BeginString_FIX43, fix.BeginString_FIX44, fix.BeginString_FIX50:
+	case fix.BeginString_FIXT11:
+		if !settings.HasSetting(config.DefaultApplVerID) {
+			return requiredConfigurationMissing(config.DefaultApplVerID)
+		}
+	default:
+		return fmt.Errorf("invalid BeginString %v", beginString)
+	}
+
+	_, err := newSessionSchedule(settings)
+	return err
+}
+
+//SessionSettingsBuilder sets the settings of a session, or the global settings, of a SettingsBuilder.
+//The first invalid setting is reported by SettingsBuilder.Build.
+type SessionSettingsBuilder struct {
+	settings *SessionSettings
+	err      error
+}
+
+func newSessionSettingsBuilder() *SessionSettingsBuilder {
+	return &SessionSettingsBuilder{settings: NewSessionSettings()}
+}
+
+//fail records err unless an earlier setting is invalid.
+func (b *SessionSettingsBuilder) fail(err error) *SessionSettingsBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+//String sets setting to value.
+func (b *SessionSettingsBuilder) String(setting, value string) *SessionSettingsBuilder {
+	b.settings.Set(setting, value)
+	return b
+}
+
+//Int sets setting to value.
+func (b *SessionSettingsBuilder) Int(setting string, value int) *SessionSettingsBuilder {
+	return b.String(setting, strconv.Itoa(value))
+}
+
+//Bool sets setting to value, as Y or N.
+func (b *SessionSettingsBuilder) Bool(setting string, value bool) *SessionSettingsBuilder {
+	if value {
+		return b.String(setting, "Y")
+	}
+	return b.String(setting, "N")
+}
+
+//port sets setting to port, invalid unless a TCP port number.
+func (b *SessionSettingsBuilder) port(setting string, port int) *SessionSettingsBuilder {
+	if port <= 0 || port > 65535 {
+		return b.fail(fmt.Errorf("invalid %v %v, expected a port number", setting, port))
+	}
+	return b.Int(setting, port)
+}
+
+//BeginString sets the BeginString of the session, such as FIX.4.4 or FIXT.1.1.
+func (b *SessionSettingsBuilder) BeginString(beginString string) *SessionSettingsBuilder {
+	return b.String(config.BeginString, beginString)
+}
+
+//SenderCompID sets the SenderCompID of the session.
+func (b *SessionSettingsBuilder) SenderCompID(senderCompID string) *SessionSettingsBuilder {
+	return b.String(config.SenderCompID, senderCompID)
+}
+
+//TargetCompID sets the TargetCompID of the session.
+func (b *SessionSettingsBuilder) TargetCompID(targetCompID string) *SessionSettingsBuilder {
+	return b.String(config.TargetCompID, targetCompID)
+}
+
+//SessionQualifier sets the SessionQualifier distinguishing sessions of the same BeginString, SenderCompID and TargetCompID.
+func (b *SessionSettingsBuilder) SessionQualifier(qualifier string) *SessionSettingsBuilder {
+	return b.String(config.SessionQualifier, qualifier)
+}
+
+//DefaultApplVerID sets the DefaultApplVerID of a FIXT.1.1 session.
+func (b *SessionSettingsBuilder) DefaultApplVerID(applVerID string) *SessionSettingsBuilder {
+	return b.String(config.DefaultApplVerID, applVerID)
+}
+
+//HeartBtInt sets the heartbeat interval, in seconds, of an initiated session.
+func (b *SessionSettingsBuilder) HeartBtInt(seconds int) *SessionSettingsBuilder {
+	if seconds <= 0 {
+		return b.fail(fmt.Errorf("invalid %v %v, expected a positive number", config.HeartBtInt, seconds))
+	}
+	return b.Int(config.HeartBtInt, seconds)
+}
+
+//SocketConnectHost sets the host an initiated session connects to.
+func (b *SessionSettingsBuilder) SocketConnectHost(host string) *SessionSettingsBuilder {
+	return b.String(config.SocketConnectHost, host)
+}
+
+//SocketConnectPort sets the port an initiated session connects to.
+func (b *SessionSettingsBuilder) SocketConnectPort(port int) *SessionSettingsBuilder {
+	return b.port(config.SocketConnectPort, port)
+}
+
+//SocketAcceptPort sets the port the Acceptor listens on for the session.
+func (b *SessionSettingsBuilder) SocketAcceptPort(port int) *SessionSettingsBuilder {
+	return b.port(config.SocketAcceptPort, port)
+}
+
+//StartTime sets the time of day, as hh:mm:ss, the session starts.
+func (b *SessionSettingsBuilder) StartTime(startTime string) *SessionSettingsBuilder {
+	return b.String(config.StartTime, startTime)
+}
+
+//EndTime sets the time of day, as hh:mm:ss, the session ends.
+func (b *SessionSettingsBuilder) EndTime(endTime string) *SessionSettingsBuilder {
+	return b.String(config.EndTime, endTime)
+}
+
+//DataDictionary sets the path of the data dictionary of a session before FIXT.1.1.
+func (b *SessionSettingsBuilder) DataDictionary(path string) *SessionSettingsBuilder {
+	return b.String(config.DataDictionary, path)
+}
+
+//ResetOnLogon sets whether the sequence numbers are reset on logon.
+func (b *SessionSettingsBuilder) ResetOnLogon(reset bool) *SessionSettingsBuilder {
+	return b.Bool(config.ResetOnLogon, reset)
+}
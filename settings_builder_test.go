@@ -0,0 +1,106 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"testing"
+)
+
+func TestSettingsBuilder_Build(t *testing.T) {
+	settings, err := NewSettingsBuilder().
+		GlobalString(config.SocketConnectHost, "fix.example.com").
+		GlobalInt(config.SocketConnectPort, 5001).
+		GlobalBool(config.ResetOnLogon, true).
+		Global(func(s *SessionSettingsBuilder) {
+			s.BeginString("FIX.4.4").SenderCompID("CLIENT")
+		}).
+		Session(func(s *SessionSettingsBuilder) {
+			s.TargetCompID("VENUE").HeartBtInt(30)
+		}).
+		Session(func(s *SessionSettingsBuilder) {
+			s.BeginString("FIXT.1.1").DefaultApplVerID("9").TargetCompID("VENUE").SocketConnectPort(5002).ResetOnLogon(false)
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionSettings := settings.SessionSettings()
+	if len(sessionSettings) != 2 {
+		t.Fatalf("Expected 2 sessions, got %v", len(sessionSettings))
+	}
+
+	var tests = []struct {
+		sessionID SessionID
+		setting   string
+		expected  string
+	}{
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.SocketConnectPort, "5001"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.HeartBtInt, "30"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.ResetOnLogon, "Y"},
+		{SessionID{BeginString: "FIXT.1.1", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.SocketConnectHost, "fix.example.com"},
+		{SessionID{BeginString: "FIXT.1.1", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.SocketConnectPort, "5002"},
+		{SessionID{BeginString: "FIXT.1.1", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.ResetOnLogon, "N"},
+	}
+
+	for _, test := range tests {
+		s, ok := sessionSettings[test.sessionID]
+		if !ok {
+			t.Errorf("Expected session %v", test.sessionID)
+			continue
+		}
+
+		if value, err := s.Setting(test.setting); err != nil || value != test.expected {
+			t.Errorf("%v: expected %v %v, got %v %v", test.sessionID, test.setting, test.expected, value, err)
+		}
+	}
+}
+
+func TestSettingsBuilder_Invalid(t *testing.T) {
+	session := func(s *SessionSettingsBuilder) {
+		s.BeginString("FIX.4.2").SenderCompID("CLIENT").TargetCompID("VENUE")
+	}
+
+	var tests = map[string]*SettingsBuilder{
+		"no sessions":           NewSettingsBuilder(),
+		"missing TargetCompID":  NewSettingsBuilder().Session(func(s *SessionSettingsBuilder) { s.BeginString("FIX.4.2").SenderCompID("CLIENT") }),
+		"invalid BeginString":   NewSettingsBuilder().Session(func(s *SessionSettingsBuilder) { session(s); s.BeginString("FIX.4.9") }),
+		"missing ApplVerID":     NewSettingsBuilder().Session(func(s *SessionSettingsBuilder) { session(s); s.BeginString("FIXT.1.1") }),
+		"invalid HeartBtInt":    NewSettingsBuilder().Session(func(s *SessionSettingsBuilder) { session(s); s.HeartBtInt(0) }),
+		"invalid port":          NewSettingsBuilder().Global(func(s *SessionSettingsBuilder) { s.SocketAcceptPort(70000) }).Session(session),
+		"invalid schedule":      NewSettingsBuilder().Session(func(s *SessionSettingsBuilder) { session(s); s.StartTime("8am").EndTime("17:00:00") }),
+		"duplicate sessions":    NewSettingsBuilder().Session(session).Session(session),
+		"invalid and then more": NewSettingsBuilder().Session(func(s *SessionSettingsBuilder) { session(s); s.SocketConnectPort(-1).SocketConnectPort(5001) }),
+		"unknown profile":       NewSettingsBuilder().Session(func(s *SessionSettingsBuilder) { session(s); s.String(config.Profile, "missing") }),
+		"invalid profile":       NewSettingsBuilder().Profile("p", func(s *SessionSettingsBuilder) { s.HeartBtInt(0) }).Session(session),
+	}
+
+	for name, builder := range tests {
+		if _, err := builder.Build(); err == nil {
+			t.Errorf("%v: expected error", name)
+		}
+	}
+}
+
+func TestSettingsBuilder_Profile(t *testing.T) {
+	settings, err := NewSettingsBuilder().
+		GlobalInt(config.HeartBtInt, 60).
+		Profile("lowLatency", func(s *SessionSettingsBuilder) {
+			s.HeartBtInt(10).BeginString("FIX.4.4")
+		}).
+		Session(func(s *SessionSettingsBuilder) {
+			s.SenderCompID("CLIENT").TargetCompID("VENUE").String(config.Profile, "lowLatency")
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, ok := settings.SessionSettings()[SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}]
+	if !ok {
+		t.Fatalf("Expected session identified by the BeginString of its profile, got %v", settings.SessionSettings())
+	}
+
+	if value, _ := s.Setting(config.HeartBtInt); value != "10" {
+		t.Errorf("Expected HeartBtInt 10 from profile, got %v", value)
+	}
+}
@@ -0,0 +1,104 @@
+package quickfix
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"io"
+	"sort"
+)
+
+//redactedSettings are the settings that may hold credentials, redacted unless they reference a secret.
+var redactedSettings = map[string]bool{
+	config.Password:               true,
+	config.RawData:                true,
+	config.RedisStorePassword:     true,
+	config.SQLStoreDataSourceName: true,
+	config.MongoStoreConnection:   true,
+}
+
+//Dump writes the settings as a config file, the [DEFAULT] section and a [PROFILE] section for each profile, followed by a [SESSION] section
+//for each session, sorted by session ID, holding every setting of the session overlaying its profile and the defaults. Settings are written as resolved, after environment variables are expanded
+//and files included, so the dumps of environments may be compared to detect drift, and parsed back with ParseSettings.
+//If redactSecrets is true, the values of the settings holding credentials, such as Password, are replaced, unless they reference a secret.
+//Data dictionaries set parsed with SetDataDictionary are written as comments.
+func (s *Settings) Dump(w io.Writer, redactSecrets bool) error {
+	s.lazyInit()
+
+	var sessionIDs []SessionID
+	for sessionID := range s.sessionSettings {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sort.Slice(sessionIDs, func(i, j int) bool { return sessionIDs[i].String() < sessionIDs[j].String() })
+
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintln(b, "[DEFAULT]")
+	dumpSessionSettings(b, s.globalSettings, redactSecrets)
+
+	var profiles []string
+	for profile := range s.profiles {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+
+	for _, profile := range profiles {
+		fmt.Fprintln(b)
+		fmt.Fprintf(b, "[PROFILE %v]\n", profile)
+		dumpSessionSettings(b, s.profiles[profile], redactSecrets)
+	}
+
+	for _, sessionID := range sessionIDs {
+		fmt.Fprintln(b)
+		fmt.Fprintln(b, "[SESSION]")
+		fmt.Fprintf(b, "#%v\n", sessionID)
+		dumpSessionSettings(b, s.sessionSettingsFor(sessionID), redactSecrets)
+	}
+
+	return b.Flush()
+}
+
+//dumpSessionSettings writes the settings sorted by name, escaping references to environment variables so they are not expanded when parsed.
+func dumpSessionSettings(w io.Writer, settings *SessionSettings, redactSecrets bool) {
+	var names []string
+	for name := range settings.settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := settings.settings[name]
+		if redactSecrets && redactedSettings[name] && !isSecretReference(value) {
+			value = fix.RedactedValue
+		}
+
+		fmt.Fprintf(w, "%v=%v\n", name, variableRegEx.ReplaceAllStringFunc(value, func(reference string) string { return "$" + reference }))
+	}
+
+	names = names[:0]
+	for name := range settings.dataDictionaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "#%v set parsed\n", name)
+	}
+}
+
+//DumpSettings writes the settings of the Initiator, as reloaded and with the sessions added, see Settings.Dump.
+func (i *Initiator) DumpSettings(w io.Writer, redactSecrets bool) error {
+	i.sessionLock.Lock()
+	defer i.sessionLock.Unlock()
+
+	return i.settings.Dump(w, redactSecrets)
+}
+
+//DumpSettings writes the settings of the Acceptor, as reloaded and with the sessions added and created from templates, see Settings.Dump.
+func (a *Acceptor) DumpSettings(w io.Writer, redactSecrets bool) error {
+	a.sessionLock.RLock()
+	defer a.sessionLock.RUnlock()
+
+	return a.settings.Dump(w, redactSecrets)
+}
@@ -0,0 +1,133 @@
+package quickfix
+
+import (
+	"bytes"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSettings_Dump(t *testing.T) {
+	os.Setenv("QUICKFIX_DUMP_HOST", "fix.example.com")
+	defer os.Unsetenv("QUICKFIX_DUMP_HOST")
+
+	cfg := `
+[DEFAULT]
+ConnectionType=initiator
+SenderCompID=TW
+Password=hunter2
+HeartBtInt=30
+
+[SESSION]
+BeginString=FIX.4.4
+TargetCompID=ISLD
+SocketConnectHost=${QUICKFIX_DUMP_HOST}
+SocketConnectPort=5001
+Username=$${USER}
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=ARCA
+HeartBtInt=20
+Password=secret://fix/arca/password
+`
+	settings, err := ParseSettings(strings.NewReader(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.sessionSettings[SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ARCA"}].SetDataDictionary(config.DataDictionary, &datadictionary.DataDictionary{})
+
+	var b bytes.Buffer
+	if err := settings.Dump(&b, true); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[DEFAULT]
+ConnectionType=initiator
+HeartBtInt=30
+Password=***
+SenderCompID=TW
+
+[SESSION]
+#FIX.4.2:TW->ARCA
+BeginString=FIX.4.2
+ConnectionType=initiator
+HeartBtInt=20
+Password=secret://fix/arca/password
+SenderCompID=TW
+TargetCompID=ARCA
+#DataDictionary set parsed
+
+[SESSION]
+#FIX.4.4:TW->ISLD
+BeginString=FIX.4.4
+ConnectionType=initiator
+HeartBtInt=30
+Password=***
+SenderCompID=TW
+SocketConnectHost=fix.example.com
+SocketConnectPort=5001
+TargetCompID=ISLD
+Username=$${USER}
+`
+	if b.String() != expected {
+		t.Errorf("Expected dump\n%v\ngot\n%v", expected, b.String())
+	}
+
+	b.Reset()
+	if err := settings.Dump(&b, false); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseSettings(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for sessionID, expected := range settings.SessionSettings() {
+		actual, ok := parsed.SessionSettings()[sessionID]
+		if !ok {
+			t.Errorf("Expected %v parsed from dump", sessionID)
+			continue
+		}
+
+		for name, value := range expected.settings {
+			if actual.settings[name] != value {
+				t.Errorf("%v: expected %v=%v parsed from dump, got %v", sessionID, name, value, actual.settings[name])
+			}
+		}
+	}
+}
+
+func TestInitiator_DumpSettings(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketConnectHost, "127.0.0.1")
+	settings.GlobalSettings().Set(config.SocketConnectPort, "5001")
+	settings.GlobalSettings().Set(config.HeartBtInt, "30")
+
+	initiator, err := NewInitiator(&TestClient{}, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.4")
+	sessionSettings.Set(config.SenderCompID, "DUMP")
+	sessionSettings.Set(config.TargetCompID, "ADDED")
+	sessionID, err := initiator.AddSession(sessionSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unregisterSession(sessionID)
+
+	var b bytes.Buffer
+	if err := initiator.DumpSettings(&b, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(b.String(), "#FIX.4.4:DUMP->ADDED\n") {
+		t.Errorf("Expected added session dumped, got\n%v", b.String())
+	}
+}
@@ -0,0 +1,225 @@
+package quickfix
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//ParseYAMLSettings creates and initializes a Settings instance with config parsed from a YAML document of the schema:
+//
+//	default:               # optional, the settings inherited by all sessions, as [DEFAULT]
+//	  <setting>: <value>
+//	profiles:              # optional, the settings of each profile, as [PROFILE <name>]
+//	  <name>:
+//	    <setting>: <value>
+//	sessions:              # the settings of each session, as [SESSION]
+//	  - <setting>: <value>
+//
+//Values are strings, numbers or booleans, true and false are Y and N. A list of values is a comma separated list, such as
+//AllowedRemoteAddresses. A nested mapping prefixes its settings with its key, and a list of mappings numbers the settings of
+//each mapping after the first, so that failover hosts and TLS options are configured as
+//
+//	sessions:
+//	  - BeginString: FIX.4.4
+//	    SenderCompID: CLIENT
+//	    TargetCompID: VENUE
+//	    SocketConnect:       # SocketConnectHost, SocketConnectPort, SocketConnectHost1, SocketConnectPort1
+//	      - {Host: primary.example.com, Port: 5001}
+//	      - {Host: backup.example.com, Port: 5001}
+//	    Socket:              # SocketUseSSL, SocketCAFile
+//	      UseSSL: true
+//	      CAFile: ca.pem
+//
+//String values may reference environment variables as in ParseSettings.
+func ParseYAMLSettings(reader io.Reader) (*Settings, error) {
+	var document map[string]interface{}
+	if err := yaml.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, fmt.Errorf("error parsing YAML settings: %v", err)
+	}
+
+	return settingsFromDocument(document)
+}
+
+//ParseJSONSettings creates and initializes a Settings instance with config parsed from a JSON document of the schema of ParseYAMLSettings.
+//
+//	{"default": {"SenderCompID": "CLIENT"}, "sessions": [{"BeginString": "FIX.4.4", "TargetCompID": "VENUE"}]}
+func ParseJSONSettings(reader io.Reader) (*Settings, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("error parsing JSON settings: %v", err)
+	}
+
+	return settingsFromDocument(document)
+}
+
+//parseSettingsDocument parses the file at path with parse.
+func parseSettingsDocument(path string, parse func(io.Reader) (*Settings, error)) (*Settings, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return parse(file)
+}
+
+//settingsFromDocument returns the settings of a YAML or JSON document.
+func settingsFromDocument(document map[string]interface{}) (*Settings, error) {
+	s := NewSettings()
+
+	for key := range document {
+		if key != "default" && key != "profiles" && key != "sessions" {
+			return nil, fmt.Errorf("unexpected %v, expected default, profiles or sessions", key)
+		}
+	}
+
+	if defaults, ok := document["default"]; ok {
+		if err := flattenSettings(s.GlobalSettings(), "", defaults); err != nil {
+			return nil, fmt.Errorf("error in default: %v", err)
+		}
+	}
+
+	if profiles, ok := document["profiles"]; ok {
+		mapping, ok := profiles.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("error in profiles: expected a mapping of profiles")
+		}
+
+		for name, profile := range mapping {
+			settings := NewSessionSettings()
+			if err := flattenSettings(settings, "", profile); err != nil {
+				return nil, fmt.Errorf("error in profile %v: %v", name, err)
+			}
+
+			if err := s.AddProfile(name, settings); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sessions, ok := document["sessions"].([]interface{})
+	if !ok || len(sessions) == 0 {
+		return nil, fmt.Errorf("no sessions declared")
+	}
+
+	for n, session := range sessions {
+		settings := NewSessionSettings()
+		if err := flattenSettings(settings, "", session); err != nil {
+			return nil, fmt.Errorf("error in session %v: %v", n+1, err)
+		}
+
+		if _, err := s.AddSession(settings); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+//flattenSettings sets the settings of the mapping value, prefixing their names with prefix.
+func flattenSettings(settings *SessionSettings, prefix string, value interface{}) error {
+	mapping, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected a mapping of settings")
+	}
+
+	//sorted so the error reported is the same on each parse
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		setting := prefix + key
+
+		switch value := mapping[key].(type) {
+		case map[string]interface{}:
+			if err := flattenSettings(settings, setting, value); err != nil {
+				return err
+			}
+
+		case []interface{}:
+			if err := flattenList(settings, setting, value); err != nil {
+				return err
+			}
+
+		default:
+			s, err := settingValue(setting, value)
+			if err != nil {
+				return err
+			}
+			settings.Set(setting, s)
+		}
+	}
+
+	return nil
+}
+
+//flattenList sets setting to the comma separated list of values, or the settings of each mapping of a list of mappings,
+//numbering those after the first.
+func flattenList(settings *SessionSettings, setting string, values []interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	if _, ok := values[0].(map[string]interface{}); ok {
+		for n, value := range values {
+			numbered := NewSessionSettings()
+			if err := flattenSettings(numbered, setting, value); err != nil {
+				return err
+			}
+
+			for key, val := range numbered.settings {
+				if n > 0 {
+					key = fmt.Sprintf("%v%v", key, n)
+				}
+				settings.Set(key, val)
+			}
+		}
+		return nil
+	}
+
+	list := make([]string, len(values))
+	for i, value := range values {
+		var err error
+		if list[i], err = settingValue(setting, value); err != nil {
+			return err
+		}
+	}
+	settings.Set(setting, strings.Join(list, ","))
+
+	return nil
+}
+
+//settingValue returns the scalar value of setting as in a config file.
+func settingValue(setting string, value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return expandEnv(value)
+	case bool:
+		if value {
+			return "Y", nil
+		}
+		return "N", nil
+	case int:
+		return strconv.Itoa(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case json.Number:
+		return value.String(), nil
+	}
+
+	return "", fmt.Errorf("invalid value of %v", setting)
+}
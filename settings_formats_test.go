@@ -0,0 +1,151 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testYAMLSettings = `
+default:
+  SenderCompID: CLIENT
+  HeartBtInt: 30
+  ResetOnLogon: true
+profiles:
+  backoff:
+    ReconnectBackoffMultiplier: 1.5
+sessions:
+  - BeginString: FIX.4.4
+    TargetCompID: VENUE
+    SocketConnect:
+      - {Host: primary.example.com, Port: 5001}
+      - {Host: backup.example.com, Port: 5002}
+    Socket:
+      UseSSL: false
+      CAFile: ${QUICKFIX_TEST_CA_FILE}
+    AllowedRemoteAddresses: [10.0.0.0/8, 192.0.2.7]
+  - BeginString: FIX.4.2
+    TargetCompID: VENUE
+    HeartBtInt: 20
+    Profile: backoff
+`
+
+const testJSONSettings = `{
+  "default": {"SenderCompID": "CLIENT", "HeartBtInt": 30, "ResetOnLogon": true},
+  "profiles": {"backoff": {"ReconnectBackoffMultiplier": 1.5}},
+  "sessions": [
+    {
+      "BeginString": "FIX.4.4",
+      "TargetCompID": "VENUE",
+      "SocketConnect": [{"Host": "primary.example.com", "Port": 5001}, {"Host": "backup.example.com", "Port": 5002}],
+      "Socket": {"UseSSL": false, "CAFile": "${QUICKFIX_TEST_CA_FILE}"},
+      "AllowedRemoteAddresses": ["10.0.0.0/8", "192.0.2.7"]
+    },
+    {"BeginString": "FIX.4.2", "TargetCompID": "VENUE", "HeartBtInt": 20, "Profile": "backoff"}
+  ]
+}`
+
+func checkDocumentSettings(t *testing.T, format string, s *Settings) {
+	var tests = []struct {
+		sessionID SessionID
+		setting   string
+		expected  string
+	}{
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.HeartBtInt, "30"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.ResetOnLogon, "Y"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.SocketConnectHost, "primary.example.com"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.SocketConnectPort, "5001"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, "SocketConnectHost1", "backup.example.com"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, "SocketConnectPort1", "5002"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.SocketUseSSL, "N"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.SocketCAFile, "ca.pem"},
+		{SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.AllowedRemoteAddresses, "10.0.0.0/8,192.0.2.7"},
+		{SessionID{BeginString: "FIX.4.2", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.HeartBtInt, "20"},
+		{SessionID{BeginString: "FIX.4.2", SenderCompID: "CLIENT", TargetCompID: "VENUE"}, config.ReconnectBackoffMultiplier, "1.5"},
+	}
+
+	sessionSettings := s.SessionSettings()
+	if len(sessionSettings) != 2 {
+		t.Fatalf("%v: expected 2 sessions, got %v", format, len(sessionSettings))
+	}
+
+	for _, test := range tests {
+		settings, ok := sessionSettings[test.sessionID]
+		if !ok {
+			t.Errorf("%v: expected session %v", format, test.sessionID)
+			continue
+		}
+
+		if value, err := settings.Setting(test.setting); err != nil || value != test.expected {
+			t.Errorf("%v: expected %v %v, got %v %v", format, test.setting, test.expected, value, err)
+		}
+	}
+}
+
+func TestParseDocumentSettings(t *testing.T) {
+	os.Setenv("QUICKFIX_TEST_CA_FILE", "ca.pem")
+	defer os.Unsetenv("QUICKFIX_TEST_CA_FILE")
+
+	s, err := ParseYAMLSettings(strings.NewReader(testYAMLSettings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDocumentSettings(t, "YAML", s)
+
+	if s, err = ParseJSONSettings(strings.NewReader(testJSONSettings)); err != nil {
+		t.Fatal(err)
+	}
+	checkDocumentSettings(t, "JSON", s)
+}
+
+func TestParseDocumentSettings_Invalid(t *testing.T) {
+	var tests = []string{
+		``,
+		`default: {SenderCompID: CLIENT}`,
+		`sessions: []`,
+		`sessions: [BeginString]`,
+		`{sessions: [{BeginString: FIX.4.2}], session: []}`,
+		`sessions: [{BeginString: FIX.4.2, TargetCompID: [[nested]]}]`,
+		`sessions: [{BeginString: FIX.4.2}, {BeginString: FIX.4.2}]`,
+		`sessions: [{BeginString: FIX.4.2`,
+		`{profiles: [HeartBtInt], sessions: [{BeginString: FIX.4.2}]}`,
+		`sessions: [{BeginString: FIX.4.2, Profile: missing}]`,
+	}
+
+	for _, test := range tests {
+		if _, err := ParseYAMLSettings(strings.NewReader(test)); err == nil {
+			t.Errorf("%q: expected error", test)
+		}
+	}
+
+	if _, err := ParseJSONSettings(strings.NewReader(`{"sessions": [{"BeginString": "FIX.4.2"}]`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestParseSettingsFile_Formats(t *testing.T) {
+	os.Setenv("QUICKFIX_TEST_CA_FILE", "ca.pem")
+	defer os.Unsetenv("QUICKFIX_TEST_CA_FILE")
+
+	dir, err := ioutil.TempDir("", "settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{"sessions.yml": testYAMLSettings, "sessions.JSON": testJSONSettings} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := ParseSettingsFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkDocumentSettings(t, name, s)
+	}
+}
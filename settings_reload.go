@@ -0,0 +1,513 @@
+package quickfix
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"sort"
+	"strings"
+)
+
+//SettingsReload reports the changes ReloadSettings made to the sessions of an Initiator or Acceptor.
+type SettingsReload struct {
+	//Added and Removed are the sessions added and removed, as by AddSession and RemoveSession.
+	Added, Removed []SessionID
+
+	//Updated are the sessions with changed settings applied without interrupting them.
+	Updated []SessionID
+
+	//RestartRequired are the changed settings of each session not applied until the session is removed and added again,
+	//or the Initiator or Acceptor is created again.
+	RestartRequired map[SessionID][]string
+}
+
+func newSettingsReload() *SettingsReload {
+	return &SettingsReload{RestartRequired: make(map[SessionID][]string)}
+}
+
+//record records the changed settings of sessionID, of which restart are not applied.
+func (r *SettingsReload) record(sessionID SessionID, changed, restart []string) {
+	if len(restart) > 0 {
+		r.RestartRequired[sessionID] = restart
+	}
+
+	if len(restart) < len(changed) {
+		r.Updated = append(r.Updated, sessionID)
+	}
+}
+
+func (r *SettingsReload) sort() {
+	for _, sessionIDs := range [][]SessionID{r.Added, r.Removed, r.Updated} {
+		sort.Slice(sessionIDs, func(i, j int) bool { return sessionIDs[i].String() < sessionIDs[j].String() })
+	}
+}
+
+//changedSettings returns the names of the settings and data dictionaries that differ between from and to, sorted.
+func changedSettings(from, to *SessionSettings) []string {
+	changed := make(map[string]bool)
+	for setting, value := range from.settings {
+		if other, ok := to.settings[setting]; !ok || other != value {
+			changed[setting] = true
+		}
+	}
+
+	for setting := range to.settings {
+		if _, ok := from.settings[setting]; !ok {
+			changed[setting] = true
+		}
+	}
+
+	for setting, dataDictionary := range from.dataDictionaries {
+		if other, ok := to.dataDictionaries[setting]; !ok || other != dataDictionary {
+			changed[setting] = true
+		}
+	}
+
+	for setting := range to.dataDictionaries {
+		if _, ok := from.dataDictionaries[setting]; !ok {
+			changed[setting] = true
+		}
+	}
+
+	names := make([]string, 0, len(changed))
+	for setting := range changed {
+		names = append(names, setting)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func isScheduleSetting(setting string) bool {
+	switch setting {
+	case config.StartDay, config.EndDay, config.Weekdays, config.TimeZone:
+		return true
+	}
+
+	return strings.HasPrefix(setting, config.StartTime) || strings.HasPrefix(setting, config.EndTime)
+}
+
+func isTLSSetting(setting string) bool {
+	for _, name := range tlsSettings {
+		if setting == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+//sessionUpdate replaces the settings of a running session changed by ReloadSettings.
+type sessionUpdate struct {
+	session *Session
+
+	//schedule, credentials and logFilter replace those of the session, if not nil, secrets replacing those of the session with credentials
+	schedule    *sessionSchedule
+	credentials *Credentials
+	secrets     *secretResolver
+	logFilter   *logFilter
+}
+
+//newSessionUpdate returns the update of session to settings, of which the changed settings differ, and the changed settings that are not
+//applied until the session is restarted. The schedule, credentials, and LogEventLevel and LogEventCategories are updated, the settings
+//reloaded by the Initiator or Acceptor itself are reported by reloaded.
+func newSessionUpdate(session *Session, settings *SessionSettings, changed []string, reloaded func(setting string) bool) (*sessionUpdate, []string, error) {
+	update := &sessionUpdate{session: session}
+
+	current := session.sessionSchedule()
+	schedule, err := newSessionSchedule(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	//a scheduled session is connected and disconnected by its schedule, which must not be added or removed while running
+	reschedulable := (schedule == nil) == (current == nil)
+	if schedule != nil && current != nil {
+		schedule.sessionID, schedule.holidays = current.sessionID, current.holidays
+	}
+
+	var restart []string
+	for _, setting := range changed {
+		switch {
+		case reloaded(setting):
+
+		case isScheduleSetting(setting) && reschedulable:
+			update.schedule = schedule
+
+		case setting == config.Username || setting == config.Password || setting == config.RawData:
+			credentials := newCredentials(settings)
+			update.credentials = &credentials
+			if update.secrets, err = newSecretResolver(settings); err != nil {
+				return nil, nil, err
+			}
+
+		case setting == config.LogEventLevel || setting == config.LogEventCategories:
+			if update.logFilter, err = newLogFilter(settings); err != nil {
+				return nil, nil, err
+			}
+
+		default:
+			restart = append(restart, setting)
+		}
+	}
+
+	return update, restart, nil
+}
+
+//apply replaces the settings of the session.
+func (u *sessionUpdate) apply() {
+	s := u.session
+
+	var applied []string
+	s.reloadLock.Lock()
+	if u.schedule != nil {
+		s.schedule = u.schedule
+		applied = append(applied, "schedule")
+	}
+
+	if u.credentials != nil {
+		s.credentials, s.secrets = *u.credentials, u.secrets
+		applied = append(applied, "credentials")
+	}
+	s.reloadLock.Unlock()
+
+	if u.logFilter != nil && s.logFilter != nil {
+		s.logFilter.update(u.logFilter)
+		applied = append(applied, "log filter")
+	}
+
+	if len(applied) > 0 {
+		logEventf(s.log, LogLevelInfo, LogCategorySession, "Reloaded %v", strings.Join(applied, ", "))
+	}
+}
+
+//sessionSchedule returns the schedule of the session, nil if not scheduled.
+func (s *Session) sessionSchedule() *sessionSchedule {
+	s.reloadLock.RLock()
+	defer s.reloadLock.RUnlock()
+
+	return s.schedule
+}
+
+//ReloadSettings replaces the settings of the Initiator with settings, applying the changes without restarting sessions where safe.
+//Sessions no longer in settings are removed, and sessions new to settings added, and connected if the Initiator is running.
+//The schedule, Username, Password and RawData, LogEventLevel and LogEventCategories, and TLS settings of the other sessions are replaced.
+//The certificate files of sessions secured by TLS are read again, so certificates replaced at the same path are rotated.
+//Other changed settings are reported as RestartRequired, applying once the session is removed and added again.
+//The settings are validated before changes are made, if a session cannot be added the changes already made are returned with the error.
+func (i *Initiator) ReloadSettings(settings *Settings) (*SettingsReload, error) {
+	i.sessionLock.Lock()
+	defer i.sessionLock.Unlock()
+
+	reload := newSettingsReload()
+	sessionSettings := settings.SessionSettings()
+
+	var updates []*sessionUpdate
+	tlsConfigs := make(map[SessionID]*tls.Config)
+	for sessionID, current := range i.sessionSettings {
+		s, ok := sessionSettings[sessionID]
+		if !ok {
+			reload.Removed = append(reload.Removed, sessionID)
+			continue
+		}
+
+		session, err := LookupSession(sessionID)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, started := i.endpoints[sessionID]; started {
+			tlsConfig, err := loadTLSConfig(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid settings of %v: %v", sessionID, err)
+			}
+			tlsConfigs[sessionID] = clientTLSConfig(session, tlsConfig)
+		}
+
+		changed := changedSettings(current, s)
+		if len(changed) == 0 {
+			continue
+		}
+
+		update, restart, err := newSessionUpdate(session, s, changed, isTLSSetting)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings of %v: %v", sessionID, err)
+		}
+
+		updates = append(updates, update)
+		reload.record(sessionID, changed, restart)
+	}
+
+	for _, sessionID := range reload.Removed {
+		i.removeSession(sessionID)
+	}
+
+	for sessionID, tlsConfig := range tlsConfigs {
+		i.endpoints[sessionID].setTLSConfig(tlsConfig)
+	}
+
+	for _, update := range updates {
+		update.apply()
+	}
+
+	i.settings = settings
+	current := i.sessionSettings
+	i.sessionSettings = make(map[SessionID]*SessionSettings)
+	for sessionID, s := range sessionSettings {
+		if _, ok := current[sessionID]; ok {
+			i.sessionSettings[sessionID] = s
+		}
+	}
+
+	for sessionID, s := range sessionSettings {
+		if _, ok := current[sessionID]; ok {
+			continue
+		}
+
+		if err := i.createSession(sessionID, s); err != nil {
+			settings.RemoveSession(sessionID)
+			reload.sort()
+			return reload, fmt.Errorf("cannot add %v: %v", sessionID, err)
+		}
+		i.sessionSettings[sessionID] = s
+		reload.Added = append(reload.Added, sessionID)
+
+		if i.stopChan != nil {
+			if err := i.startSession(sessionID, s); err != nil {
+				reload.sort()
+				return reload, err
+			}
+		}
+	}
+
+	reload.sort()
+	return reload, nil
+}
+
+func isAddressFilterSetting(setting string) bool {
+	return setting == config.AllowedRemoteAddresses || setting == config.DeniedRemoteAddresses
+}
+
+//ReloadSettings replaces the settings of the Acceptor with settings, applying the changes without restarting sessions where safe.
+//Sessions no longer in settings are removed, and sessions new to settings added, as by RemoveSession and AddSession.
+//The schedule, Username, Password and RawData, LogEventLevel and LogEventCategories, and AllowedRemoteAddresses and DeniedRemoteAddresses
+//of the other sessions are replaced, those of the global settings replacing the filter of SetAddressFilter. The TLS settings of an address
+//are replaced if the sessions on the address remain secured by TLS, and the certificate files read again, so certificates replaced at the
+//same path are rotated. Other changed settings, and changes to templates, are reported as RestartRequired, applying once the session is
+//removed and added again, or the Acceptor created again. Sessions created from templates are kept.
+//The settings are validated before changes are made, if a session cannot be added the changes already made are returned with the error.
+func (a *Acceptor) ReloadSettings(settings *Settings) (*SettingsReload, error) {
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
+
+	reload := newSettingsReload()
+	current := a.settings
+
+	isTemplate := func(s *SessionSettings) bool {
+		template, err := s.BoolSetting(config.AcceptorTemplate)
+		return err == nil && template
+	}
+
+	//templates are not reloaded, those of the current settings are kept
+	templates := make(map[SessionID]bool)
+	for _, template := range a.templates {
+		templates[template.sessionID] = true
+		if _, ok := settings.sessionSettings[template.sessionID]; !ok || !isTemplate(settings.sessionSettingsFor(template.sessionID)) {
+			reload.RestartRequired[template.sessionID] = []string{config.AcceptorTemplate}
+		} else if changed := changedSettings(current.sessionSettingsFor(template.sessionID), settings.sessionSettingsFor(template.sessionID)); len(changed) > 0 {
+			reload.RestartRequired[template.sessionID] = changed
+		}
+	}
+
+	var added []SessionID
+	for sessionID := range settings.sessionSettings {
+		if _, ok := current.sessionSettings[sessionID]; !ok && isTemplate(settings.sessionSettingsFor(sessionID)) {
+			reload.RestartRequired[sessionID] = []string{config.AcceptorTemplate}
+		} else if !ok {
+			added = append(added, sessionID)
+		}
+	}
+
+	//the sessions on each address, to reload the TLS settings of its listener
+	addresses := make(map[string][]*SessionSettings)
+	sessionSettings := make(map[SessionID]*SessionSettings)
+	for sessionID := range current.sessionSettings {
+		if templates[sessionID] || a.templateSessions[sessionID] {
+			continue
+		}
+
+		if _, ok := settings.sessionSettings[sessionID]; !ok || isTemplate(settings.sessionSettingsFor(sessionID)) {
+			reload.Removed = append(reload.Removed, sessionID)
+			continue
+		}
+
+		s := settings.sessionSettingsFor(sessionID)
+		if address, err := acceptAddress(s); err == nil && address == a.sessionAddresses[sessionID] {
+			addresses[address] = append(addresses[address], s)
+		}
+		sessionSettings[sessionID] = s
+	}
+
+	listenerSettings, listenerTLSConfigs, err := a.reloadListenerSettings(addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	var globalFilter *addressFilter
+	globalFilterChanged := false
+	for _, setting := range changedSettings(current.GlobalSettings(), settings.GlobalSettings()) {
+		globalFilterChanged = globalFilterChanged || isAddressFilterSetting(setting)
+	}
+	if globalFilterChanged {
+		if globalFilter, err = addressFilterFromSettings(settings.GlobalSettings()); err != nil {
+			return nil, err
+		}
+	}
+
+	var updates []*sessionUpdate
+	sessionAddressFilters := make(map[SessionID]*addressFilter)
+	for sessionID, s := range sessionSettings {
+		changed := changedSettings(current.sessionSettingsFor(sessionID), s)
+		if len(changed) == 0 {
+			continue
+		}
+
+		session, err := LookupSession(sessionID)
+		if err != nil {
+			return nil, err
+		}
+
+		address := a.sessionAddresses[sessionID]
+		reloaded := func(setting string) bool {
+			if isAddressFilterSetting(setting) {
+				return true
+			}
+
+			_, listenerReloaded := listenerSettings[address]
+			return listenerReloaded && isTLSSetting(setting)
+		}
+
+		update, restart, err := newSessionUpdate(session, s, changed, reloaded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings of %v: %v", sessionID, err)
+		}
+
+		if filter, err := addressFilterFromSettings(settings.sessionSettings[sessionID]); err != nil {
+			return nil, fmt.Errorf("invalid settings of %v: %v", sessionID, err)
+		} else if changedAddressFilter(changed) {
+			sessionAddressFilters[sessionID] = filter
+		}
+
+		updates = append(updates, update)
+		reload.record(sessionID, changed, restart)
+	}
+
+	for _, sessionID := range reload.Removed {
+		a.removeSession(sessionID)
+	}
+
+	for address, s := range listenerSettings {
+		a.listenerSettings[address] = s
+	}
+
+	for address, tlsConfig := range listenerTLSConfigs {
+		a.listenerTLSConfigs[address] = tlsConfig
+	}
+
+	if globalFilterChanged {
+		a.addressFilter = globalFilter
+	}
+
+	for sessionID, filter := range sessionAddressFilters {
+		if filter == nil {
+			delete(a.sessionAddressFilters, sessionID)
+		} else {
+			a.sessionAddressFilters[sessionID] = filter
+		}
+	}
+
+	for _, update := range updates {
+		update.apply()
+	}
+
+	//templates and the sessions created from them are kept
+	for sessionID := range current.sessionSettings {
+		if templates[sessionID] || a.templateSessions[sessionID] {
+			settings.sessionSettings[sessionID] = current.sessionSettings[sessionID]
+		}
+	}
+
+	for sessionID := range settings.sessionSettings {
+		if _, ok := current.sessionSettings[sessionID]; !ok && isTemplate(settings.sessionSettingsFor(sessionID)) {
+			delete(settings.sessionSettings, sessionID)
+		}
+	}
+	a.settings = settings
+
+	sort.Slice(added, func(i, j int) bool { return added[i].String() < added[j].String() })
+	for _, sessionID := range added {
+		if err := a.createSession(sessionID); err != nil {
+			settings.RemoveSession(sessionID)
+			reload.sort()
+			return reload, fmt.Errorf("cannot add %v: %v", sessionID, err)
+		}
+		reload.Added = append(reload.Added, sessionID)
+	}
+
+	reload.sort()
+	return reload, nil
+}
+
+func changedAddressFilter(changed []string) bool {
+	for _, setting := range changed {
+		if isAddressFilterSetting(setting) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//reloadListenerSettings returns the listener settings of each address with the settings of the sessions on the address, and the TLS
+//configuration of each address listened on with TLS, read again from the certificate files. Addresses whose listener must be restarted
+//to apply the settings, to secure the address with TLS or no longer, or to change the PROXY protocol, are not returned.
+func (a *Acceptor) reloadListenerSettings(addresses map[string][]*SessionSettings) (map[string]*SessionSettings, map[string]*tls.Config, error) {
+	listenerSettings := make(map[string]*SessionSettings)
+	tlsConfigs := make(map[string]*tls.Config)
+
+	for address, sessionSettings := range addresses {
+		s := sessionSettings[0]
+		for _, other := range sessionSettings[1:] {
+			if settingsKey(s, listenerSettingNames) != settingsKey(other, listenerSettingNames) {
+				return nil, nil, fmt.Errorf("sessions accepted on %v must share the same TLS and PROXY protocol settings", address)
+			}
+		}
+
+		tlsConfig, err := loadTLSConfig(s)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, listening := a.listeners[address]; a.listeners == nil || !listening {
+			listenerSettings[address] = s
+			continue
+		}
+
+		current := a.listenerSettings[address]
+		proxyProtocol, _ := s.Setting(config.SocketProxyProtocol)
+		currentProxyProtocol, _ := current.Setting(config.SocketProxyProtocol)
+		if _, secured := a.listenerTLSConfigs[address]; secured != (tlsConfig != nil) || proxyProtocol != currentProxyProtocol {
+			continue
+		}
+
+		if tlsConfig != nil {
+			if len(tlsConfig.Certificates) == 0 {
+				return nil, nil, fmt.Errorf("TLS on %v requires %v", address, config.SocketCertificateFile)
+			}
+			tlsConfigs[address] = tlsConfig
+		}
+		listenerSettings[address] = s
+	}
+
+	return listenerSettings, tlsConfigs, nil
+}
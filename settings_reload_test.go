@@ -0,0 +1,202 @@
+package quickfix
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestChangedSettings(t *testing.T) {
+	from := NewSessionSettings()
+	from.Set(config.HeartBtInt, "30")
+	from.Set(config.Username, "client")
+	from.Set(config.ResetOnLogon, "Y")
+
+	to := from.clone()
+	to.Set(config.HeartBtInt, "20")
+	to.Set(config.Password, "secret")
+	delete(to.settings, config.ResetOnLogon)
+
+	expected := []string{config.HeartBtInt, config.Password, config.ResetOnLogon}
+	if changed := changedSettings(from, to); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expected %v changed, got %v", expected, changed)
+	}
+
+	if changed := changedSettings(from, from.clone()); len(changed) != 0 {
+		t.Errorf("Expected no settings changed, got %v", changed)
+	}
+}
+
+func newTestReloadInitiatorSettings(targetCompIDs ...string) *Settings {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketConnectHost, "127.0.0.1")
+	settings.GlobalSettings().Set(config.SocketConnectPort, "5001")
+	settings.GlobalSettings().Set(config.HeartBtInt, "30")
+	settings.GlobalSettings().Set(config.Username, "client")
+
+	for _, targetCompID := range targetCompIDs {
+		settings.AddSession(newTestAcceptorSessionSettings(targetCompID))
+	}
+
+	return settings
+}
+
+func TestInitiator_ReloadSettings(t *testing.T) {
+	initiator, err := NewInitiator(&TestClient{}, NewMemoryStoreFactory(), newTestReloadInitiatorSettings("RELOAD_KEPT", "RELOAD_REMOVED"), NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept := SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "RELOAD_KEPT"}
+	removed := SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "RELOAD_REMOVED"}
+	added := SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "RELOAD_ADDED"}
+	defer unregisterSession(kept)
+	defer unregisterSession(added)
+
+	settings := newTestReloadInitiatorSettings("RELOAD_KEPT", "RELOAD_ADDED")
+	settings.GlobalSettings().Set(config.Username, "rotated")
+	settings.GlobalSettings().Set(config.LogEventLevel, "Warn")
+	settings.GlobalSettings().Set(config.HeartBtInt, "20")
+
+	reload, err := initiator.ReloadSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reload.Added, []SessionID{added}) || !reflect.DeepEqual(reload.Removed, []SessionID{removed}) ||
+		!reflect.DeepEqual(reload.Updated, []SessionID{kept}) {
+		t.Errorf("Unexpected reload %+v", reload)
+	}
+
+	if restart := reload.RestartRequired[kept]; !reflect.DeepEqual(restart, []string{config.HeartBtInt}) {
+		t.Errorf("Expected restart required to apply HeartBtInt, got %v", restart)
+	}
+
+	session, err := LookupSession(kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if credentials, _ := session.logonCredentials(); credentials.Username != "rotated" {
+		t.Errorf("Expected Username reloaded, got %v", credentials.Username)
+	}
+
+	if session.logFilter.permits(LogLevelInfo, LogCategorySession) {
+		t.Error("Expected LogEventLevel reloaded")
+	}
+
+	if _, err := LookupSession(removed); err == nil {
+		t.Error("Expected removed session to be unregistered")
+	}
+
+	if _, err := LookupSession(added); err != nil {
+		t.Errorf("Expected added session to be registered: %v", err)
+	}
+
+	invalid := newTestReloadInitiatorSettings("RELOAD_KEPT")
+	invalid.GlobalSettings().Set(config.LogEventLevel, "Verbose")
+	if _, err := initiator.ReloadSettings(invalid); err == nil {
+		t.Error("Expected error reloading invalid settings")
+	}
+
+	if _, err := LookupSession(added); err != nil {
+		t.Error("Expected invalid settings not to remove sessions")
+	}
+}
+
+func TestAcceptor_ReloadSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	port := strconv.Itoa(freePort(t))
+	newSettings := func(name string, targetCompIDs ...string) *Settings {
+		certificateFile, keyFile := writeTestCertificate(t, dir, name)
+
+		settings := NewSettings()
+		settings.GlobalSettings().Set(config.SocketAcceptHost, "127.0.0.1")
+		settings.GlobalSettings().Set(config.SocketAcceptPort, port)
+		settings.GlobalSettings().Set(config.SocketCertificateFile, certificateFile)
+		settings.GlobalSettings().Set(config.SocketPrivateKeyFile, keyFile)
+
+		for _, targetCompID := range targetCompIDs {
+			settings.AddSession(newTestAcceptorSessionSettings(targetCompID))
+		}
+
+		template := newTestAcceptorSessionSettings("*")
+		template.Set(config.AcceptorTemplate, "Y")
+		settings.AddSession(template)
+
+		return settings
+	}
+
+	acceptor, err := NewAcceptor(&TestClient{}, NewMemoryStoreFactory(), newSettings("first", "ACCEPTOR_RELOAD_KEPT"), NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	kept := SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "ACCEPTOR_RELOAD_KEPT"}
+	added := SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "ACCEPTOR_RELOAD_ADDED"}
+	template := SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "*"}
+	defer unregisterSession(kept)
+	defer unregisterSession(added)
+
+	settings := newSettings("second", "ACCEPTOR_RELOAD_KEPT", "ACCEPTOR_RELOAD_ADDED")
+	settings.GlobalSettings().Set(config.AllowedRemoteAddresses, "127.0.0.1")
+	settings.GlobalSettings().Set(config.ResetOnLogon, "Y")
+
+	reload, err := acceptor.ReloadSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reload.Added, []SessionID{added}) || len(reload.Removed) != 0 || !reflect.DeepEqual(reload.Updated, []SessionID{kept}) {
+		t.Errorf("Unexpected reload %+v", reload)
+	}
+
+	if restart := reload.RestartRequired[kept]; !reflect.DeepEqual(restart, []string{config.ResetOnLogon}) {
+		t.Errorf("Expected restart required to apply ResetOnLogon, got %v", restart)
+	}
+
+	if _, ok := reload.RestartRequired[template]; !ok {
+		t.Error("Expected restart required to apply the settings of the template")
+	}
+
+	if acceptor.addressFilter == nil {
+		t.Error("Expected AllowedRemoteAddresses reloaded")
+	}
+
+	conn, err := tls.Dial("tcp", net.JoinHostPort("127.0.0.1", port), &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if subject := conn.ConnectionState().PeerCertificates[0].Subject.CommonName; subject != "second" {
+		t.Errorf("Expected reloaded certificate presented, got %v", subject)
+	}
+
+	plain := newSettings("third", "ACCEPTOR_RELOAD_KEPT", "ACCEPTOR_RELOAD_ADDED")
+	delete(plain.GlobalSettings().settings, config.SocketCertificateFile)
+	delete(plain.GlobalSettings().settings, config.SocketPrivateKeyFile)
+	if reload, err = acceptor.ReloadSettings(plain); err != nil {
+		t.Fatal(err)
+	}
+
+	if restart := reload.RestartRequired[kept]; len(restart) == 0 {
+		t.Error("Expected restart required to disable TLS")
+	}
+}
@@ -2,6 +2,9 @@ package quickfix
 
 import (
 	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -266,6 +269,114 @@ DataDictionary=somewhere/FIX42.xml
 	}
 }
 
+func TestSettings_ParseSettingsEnv(t *testing.T) {
+	os.Setenv("QUICKFIX_TEST_HOST", "fix.example.com")
+	defer os.Unsetenv("QUICKFIX_TEST_HOST")
+	os.Unsetenv("QUICKFIX_TEST_UNSET")
+
+	cfg := `
+[DEFAULT]
+SocketConnectHost=${QUICKFIX_TEST_HOST}
+SocketConnectPort=${QUICKFIX_TEST_UNSET:-5001}
+Password=pa$${QUICKFIX_TEST_HOST}$$
+[SESSION]
+BeginString=FIX.4.2
+SenderCompID=CLIENT
+TargetCompID=${QUICKFIX_TEST_HOST:-VENUE}
+`
+
+	s, err := ParseSettings(strings.NewReader(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := s.SessionSettings()[SessionID{BeginString: "FIX.4.2", SenderCompID: "CLIENT", TargetCompID: "fix.example.com"}]
+	if settings == nil {
+		t.Fatalf("Expected session with expanded TargetCompID, got %v", s.SessionSettings())
+	}
+
+	var tests = []struct {
+		setting  string
+		expected string
+	}{
+		{config.SocketConnectHost, "fix.example.com"},
+		{config.SocketConnectPort, "5001"},
+		{"Password", "pa${QUICKFIX_TEST_HOST}$$"},
+	}
+
+	for _, test := range tests {
+		if value, _ := settings.Setting(test.setting); value != test.expected {
+			t.Errorf("Expected %v %v, got %v", test.setting, test.expected, value)
+		}
+	}
+
+	if _, err := ParseSettings(strings.NewReader("[SESSION]\nTargetCompID=${QUICKFIX_TEST_UNSET}\n")); err == nil {
+		t.Error("Expected error for unset environment variable")
+	}
+
+	if _, err := ParseSettings(strings.NewReader("BeginString=FIX.4.2\n[SESSION]\n")); err == nil {
+		t.Error("Expected error for setting outside of a section")
+	}
+}
+
+func TestSettings_ParseSettingsFileInclude(t *testing.T) {
+	dir, err := ioutil.TempDir("", "settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"shared/defaults.cfg": "[DEFAULT]\nBeginString=FIX.4.4\nSenderCompID=CLIENT\nHeartBtInt=30\n",
+		"shared/venue.cfg":    "SocketConnectHost=fix.example.com\nSocketConnectPort=5001\n",
+		"sessions.cfg":        "include shared/defaults.cfg\n[SESSION]\nTargetCompID=VENUE\ninclude shared/venue.cfg\nHeartBtInt=20\n",
+		"circular.cfg":        "include circular.cfg\n",
+		"missing.cfg":         "include shared/missing.cfg\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := ParseSettingsFile(filepath.Join(dir, "sessions.cfg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := s.SessionSettings()[SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "VENUE"}]
+	if settings == nil {
+		t.Fatalf("Expected session with included defaults, got %v", s.SessionSettings())
+	}
+
+	var tests = []struct {
+		setting  string
+		expected string
+	}{
+		{config.SocketConnectHost, "fix.example.com"},
+		{config.SocketConnectPort, "5001"},
+		{config.HeartBtInt, "20"},
+	}
+
+	for _, test := range tests {
+		if value, _ := settings.Setting(test.setting); value != test.expected {
+			t.Errorf("Expected %v %v, got %v", test.setting, test.expected, value)
+		}
+	}
+
+	for _, name := range []string{"circular.cfg", "missing.cfg"} {
+		if _, err := ParseSettingsFile(filepath.Join(dir, name)); err == nil {
+			t.Errorf("Expected error parsing %v", name)
+		}
+	}
+}
+
 func TestSettings_SessionIDFromSessionSettings(t *testing.T) {
 	var testCases = []struct {
 		globalBeginString   string
@@ -0,0 +1,146 @@
+package quickfix
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//SettingLayer is a layer of the settings of a session, each overriding the settings of the layers below it.
+type SettingLayer int
+
+const (
+	//DefaultLayer is the global settings, the [DEFAULT] section.
+	DefaultLayer SettingLayer = iota
+
+	//ProfileLayer is the profile the session inherits by its Profile setting.
+	ProfileLayer
+
+	//SessionLayer is the settings of the session, its [SESSION] section.
+	SessionLayer
+)
+
+func (l SettingLayer) String() string {
+	switch l {
+	case DefaultLayer:
+		return "default"
+	case ProfileLayer:
+		return "profile"
+	case SessionLayer:
+		return "session"
+	}
+
+	return fmt.Sprintf("SettingLayer(%d)", int(l))
+}
+
+//SettingValue is the value a layer sets a setting to.
+type SettingValue struct {
+	Layer SettingLayer
+
+	//Profile is the name of the profile of a ProfileLayer value.
+	Profile string
+
+	Value string
+}
+
+func (v SettingValue) String() string {
+	if v.Layer == ProfileLayer {
+		return fmt.Sprintf("%v from profile %v", v.Value, v.Profile)
+	}
+
+	return fmt.Sprintf("%v from %v", v.Value, v.Layer)
+}
+
+//SettingTrace is the value of a setting of a session, with the values of the layers it overrides.
+type SettingTrace struct {
+	Setting string
+
+	//Values are the values of the layers setting the setting, lowest first. The last is the value of the session.
+	Values []SettingValue
+}
+
+//Value returns the value of the setting for the session, from the highest layer setting it.
+func (t SettingTrace) Value() SettingValue {
+	return t.Values[len(t.Values)-1]
+}
+
+func (t SettingTrace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v=%v", t.Setting, t.Value())
+
+	for i := len(t.Values) - 2; i >= 0; i-- {
+		if i == len(t.Values)-2 {
+			b.WriteString(", overriding ")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(t.Values[i].String())
+	}
+
+	return b.String()
+}
+
+//TraceSettings returns the trace of each setting of the session, sorted by setting, to explain which layer of the settings,
+//the defaults, the profile of the session, or the session itself, each value of the session comes from.
+//Returns an error if no session is configured for sessionID.
+func (s *Settings) TraceSettings(sessionID SessionID) ([]SettingTrace, error) {
+	s.lazyInit()
+
+	sessionSettings, ok := s.sessionSettings[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("no session configured for %v", sessionID)
+	}
+
+	layers := []SettingValue{{Layer: DefaultLayer}}
+	settings := []*SessionSettings{s.globalSettings}
+
+	if profile := s.profileOf(sessionSettings); profile != "" {
+		if profileSettings, ok := s.profiles[profile]; ok {
+			layers = append(layers, SettingValue{Layer: ProfileLayer, Profile: profile})
+			settings = append(settings, profileSettings)
+		}
+	}
+
+	layers = append(layers, SettingValue{Layer: SessionLayer})
+	settings = append(settings, sessionSettings)
+
+	traces := make(map[string]*SettingTrace)
+	for i, layer := range settings {
+		for setting, value := range layer.settings {
+			trace, ok := traces[setting]
+			if !ok {
+				trace = &SettingTrace{Setting: setting}
+				traces[setting] = trace
+			}
+
+			v := layers[i]
+			v.Value = value
+			trace.Values = append(trace.Values, v)
+		}
+	}
+
+	sorted := make([]SettingTrace, 0, len(traces))
+	for _, trace := range traces {
+		sorted = append(sorted, *trace)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Setting < sorted[j].Setting })
+
+	return sorted, nil
+}
+
+//TraceSetting returns the trace of a setting of the session, see TraceSettings.
+//Returns an error if no session is configured for sessionID or the setting is not set for the session.
+func (s *Settings) TraceSetting(sessionID SessionID, setting string) (SettingTrace, error) {
+	traces, err := s.TraceSettings(sessionID)
+	if err != nil {
+		return SettingTrace{}, err
+	}
+
+	for _, trace := range traces {
+		if trace.Setting == setting {
+			return trace, nil
+		}
+	}
+
+	return SettingTrace{}, fmt.Errorf("%v not set for %v", setting, sessionID)
+}
@@ -0,0 +1,133 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"strings"
+	"testing"
+)
+
+const testProfileSettings = `
+[DEFAULT]
+ConnectionType=initiator
+SenderCompID=CLIENT
+HeartBtInt=60
+ReconnectInterval=30
+
+[PROFILE lowLatency]
+HeartBtInt=30
+ReconnectInterval=1
+
+[SESSION]
+BeginString=FIX.4.4
+TargetCompID=FAST
+Profile=lowLatency
+HeartBtInt=10
+
+[SESSION]
+BeginString=FIX.4.4
+TargetCompID=SLOW
+`
+
+func TestSettings_ParseSettingsProfile(t *testing.T) {
+	s, err := ParseSettings(strings.NewReader(testProfileSettings))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.Profile("lowLatency"); !ok {
+		t.Error("Expected profile lowLatency")
+	}
+
+	fast := s.SessionSettings()[SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "FAST"}]
+	slow := s.SessionSettings()[SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "SLOW"}]
+	if fast == nil || slow == nil {
+		t.Fatalf("Expected sessions FAST and SLOW, got %v", s.SessionSettings())
+	}
+
+	var tests = []struct {
+		settings *SessionSettings
+		setting  string
+		expected string
+	}{
+		{fast, config.HeartBtInt, "10"},
+		{fast, config.ReconnectInterval, "1"},
+		{slow, config.HeartBtInt, "60"},
+		{slow, config.ReconnectInterval, "30"},
+	}
+
+	for _, test := range tests {
+		if value, _ := test.settings.Setting(test.setting); value != test.expected {
+			t.Errorf("Expected %v %v, got %v", test.setting, test.expected, value)
+		}
+	}
+
+	for _, cfg := range []string{
+		"[SESSION]\nBeginString=FIX.4.4\nSenderCompID=A\nTargetCompID=B\nProfile=missing\n",
+		"[PROFILE p]\nHeartBtInt=1\n[PROFILE p]\nHeartBtInt=2\n[SESSION]\nBeginString=FIX.4.4\nSenderCompID=A\nTargetCompID=B\n",
+		"[PROFILE p]\nBeginString=FIX.4.4\nSenderCompID=A\nTargetCompID=B\n",
+	} {
+		if _, err := ParseSettings(strings.NewReader(cfg)); err == nil {
+			t.Errorf("Expected error parsing %q", cfg)
+		}
+	}
+}
+
+func TestSettings_TraceSettings(t *testing.T) {
+	s, err := ParseSettings(strings.NewReader(testProfileSettings))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fast := SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "FAST"}
+	trace, err := s.TraceSetting(fast, config.HeartBtInt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value := trace.Value(); value.Layer != SessionLayer || value.Value != "10" {
+		t.Errorf("Expected 10 from session, got %v", value)
+	}
+
+	if expected := "HeartBtInt=10 from session, overriding 30 from profile lowLatency, 60 from default"; trace.String() != expected {
+		t.Errorf("Expected %v, got %v", expected, trace)
+	}
+
+	trace, err = s.TraceSetting(fast, config.ReconnectInterval)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value := trace.Value(); value.Layer != ProfileLayer || value.Profile != "lowLatency" || value.Value != "1" {
+		t.Errorf("Expected 1 from profile lowLatency, got %v", value)
+	}
+
+	traces, err := s.TraceSettings(SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "SLOW"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var settings []string
+	for _, trace := range traces {
+		settings = append(settings, trace.String())
+	}
+
+	expected := []string{
+		"BeginString=FIX.4.4 from session",
+		"ConnectionType=initiator from default",
+		"HeartBtInt=60 from default",
+		"ReconnectInterval=30 from default",
+		"SenderCompID=CLIENT from default",
+		"TargetCompID=SLOW from session",
+	}
+	if strings.Join(settings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected traces\n%v\ngot\n%v", strings.Join(expected, "\n"), strings.Join(settings, "\n"))
+	}
+
+	if _, err := s.TraceSetting(fast, config.SocketConnectHost); err == nil {
+		t.Error("Expected error tracing setting not set")
+	}
+
+	if _, err := s.TraceSettings(SessionID{BeginString: "FIX.4.4", SenderCompID: "CLIENT", TargetCompID: "NONE"}); err == nil {
+		t.Error("Expected error tracing session not configured")
+	}
+}
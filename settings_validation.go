@@ -0,0 +1,537 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//SettingsError is returned by Settings.Validate, reporting every invalid setting found.
+type SettingsError struct {
+	Problems []SettingProblem
+}
+
+func (e *SettingsError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.String()
+	}
+
+	return fmt.Sprintf("%v invalid settings: %v", len(e.Problems), strings.Join(problems, "; "))
+}
+
+//SettingProblem is an invalid setting of the global settings or of a session.
+type SettingProblem struct {
+	//SessionID is the session of the setting, nil for the global settings and profiles.
+	SessionID *SessionID
+
+	//Profile is the profile of the setting, empty unless set by a profile.
+	Profile string
+
+	//Setting is the name of the setting.
+	Setting string
+
+	//Problem describes what is wrong with the setting.
+	Problem string
+}
+
+func (p SettingProblem) String() string {
+	section := "[DEFAULT]"
+	switch {
+	case p.SessionID != nil:
+		section = fmt.Sprintf("[SESSION %v]", p.SessionID)
+	case p.Profile != "":
+		section = fmt.Sprintf("[PROFILE %v]", p.Profile)
+	}
+
+	return fmt.Sprintf("%v %v: %v", section, p.Setting, p.Problem)
+}
+
+//settingValidator returns an error if value is not valid for a setting.
+type settingValidator func(value string) error
+
+func validString(string) error { return nil }
+
+func validBool(value string) error {
+	switch value {
+	case "Y", "y", "N", "n":
+		return nil
+	}
+
+	return fmt.Errorf("expected Y or N, got %v", value)
+}
+
+func validInt(value string) error {
+	if _, err := strconv.Atoi(value); err != nil {
+		return fmt.Errorf("expected an integer, got %v", value)
+	}
+
+	return nil
+}
+
+func validPositiveInt(value string) error {
+	if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+		return fmt.Errorf("expected a positive integer, got %v", value)
+	}
+
+	return nil
+}
+
+func validFloat(value string) error {
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return fmt.Errorf("expected a number, got %v", value)
+	}
+
+	return nil
+}
+
+func validPort(value string) error {
+	if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("expected a port from 1 to 65535, got %v", value)
+	}
+
+	return nil
+}
+
+func validConnectionType(value string) error {
+	if value != "initiator" && value != "acceptor" {
+		return fmt.Errorf("invalid ConnectionType %v, expected initiator or acceptor", value)
+	}
+
+	return nil
+}
+
+func validRemoteAddresses(value string) error {
+	var addresses []string
+	for _, address := range strings.Split(value, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+
+	_, err := parseNetworks(addresses)
+	return err
+}
+
+//knownSettings are the settings read by the package and its log and store implementations, with the validator of their values.
+var knownSettings = map[string]settingValidator{
+	config.ConnectionType:                  validConnectionType,
+	config.BeginString:                     validString,
+	config.SenderCompID:                    validString,
+	config.TargetCompID:                    validString,
+	config.SessionQualifier:                validString,
+	config.SocketAcceptHost:                validString,
+	config.SocketAcceptPort:                validPort,
+	config.AcceptorTemplate:                validBool,
+	config.SocketConnectHost:               validString,
+	config.SocketConnectPort:               validPort,
+	config.SocketConnectFailover:           func(value string) error { _, err := parseFailoverPolicy(value); return err },
+	config.SocketConnectResolveEachAttempt: validBool,
+	config.SocketConnectAddressPreference:  func(value string) error { _, err := parseAddressPreference(value); return err },
+	config.SocketConnectAttemptDelay:       validInt,
+	config.SocketConnectAttemptTimeout:     validInt,
+	config.SocketUseSSL:                    validBool,
+	config.SocketCertificateFile:           validString,
+	config.SocketPrivateKeyFile:            validString,
+	config.SocketCAFile:                    validString,
+	config.SocketMinimumTLSVersion:         validString,
+	config.SocketCipherSuites:              func(value string) error { _, err := parseCipherSuites(value); return err },
+	config.SocketServerName:                validString,
+	config.SocketInsecureSkipVerify:        validBool,
+	config.SocketClientAuth:                validString,
+	config.SocketPinnedCertificates:        validString,
+	config.SocketCRLFile:                   validString,
+	config.SocketOCSPPolicy:                func(value string) error { _, err := parseOCSPPolicy(value); return err },
+	config.SocketNodelay:                   validBool,
+	config.SocketKeepAliveInterval:         validInt,
+	config.SocketReceiveBufferSize:         validInt,
+	config.SocketSendBufferSize:            validInt,
+	config.SocketTrafficClass:              validInt,
+	config.SocketWriteTimeout:              validInt,
+	config.SocketProxyProtocol:             func(value string) error { _, err := parseProxyProtocolPolicy(value); return err },
+	config.AllowedRemoteAddresses:          validRemoteAddresses,
+	config.DeniedRemoteAddresses:           validRemoteAddresses,
+	config.ReconnectInterval:               validPositiveInt,
+	config.ReconnectBackoffMultiplier:      validFloat,
+	config.MaxReconnectInterval:            validPositiveInt,
+	config.ReconnectJitter:                 validFloat,
+	config.MaxReconnectAttempts:            validInt,
+	config.DefaultApplVerID:                func(value string) error { _, err := parseApplVerID(value); return err },
+	config.DataDictionary:                  validString,
+	config.TransportDataDictionary:         validString,
+	config.AppDataDictionary:               validString,
+	config.CounterpartyDataDictionary:      validString,
+	config.ResetOnLogon:                    validBool,
+	config.ResetOnLogout:                   validBool,
+	config.ResetOnDisconnect:               validBool,
+	config.HeartBtInt:                      validPositiveInt,
+	config.EnforceHeartBtInt:               validBool,
+	config.TestRequestDelayMultiplier:      validFloat,
+	config.HeartBeatTimeoutMultiplier:      validFloat,
+	config.LogType:                         validString,
+	config.FileLogPath:                     validString,
+	config.FileLogRotateDaily:              validBool,
+	config.FileLogMaxSize:                  validInt,
+	config.FileLogMaxAge:                   validInt,
+	config.FileLogCompress:                 validBool,
+	config.LogMaskTags:                     func(value string) error { _, err := parseLogMaskTags(value); return err },
+	config.LogEventLevel:                   func(value string) error { _, err := parseLogLevel(value); return err },
+	config.LogEventCategories:              validString,
+	config.LogSampleRate:                   validPositiveInt,
+	config.LogSampleMsgTypes:               validString,
+	config.LogSummaryInterval:              validInt,
+	config.AsyncLogQueueDepth:              validInt,
+	config.AsyncLogOverflow:                func(value string) error { _, err := parseAsyncLogOverflow(value); return err },
+	config.KafkaLogBrokers:                 validString,
+	config.KafkaLogMessageTopic:            validString,
+	config.KafkaLogEventTopic:              validString,
+	config.KafkaLogFormat:                  validString,
+	config.SyslogNetwork:                   validString,
+	config.SyslogAddress:                   validString,
+	config.SyslogFacility:                  validString,
+	config.SyslogAppName:                   validString,
+	config.JournaldSocket:                  validString,
+	config.JournaldIdentifier:              validString,
+	config.WireCapturePath:                 validString,
+	config.WireCapture:                     validBool,
+	config.ArchivePrefix:                   validString,
+	config.ArchiveMaxSize:                  validInt,
+	config.ArchiveInterval:                 validInt,
+	config.ArchiveQueueDepth:               validInt,
+	config.ArchiveOverflow:                 func(value string) error { _, err := parseArchiveOverflow(value); return err },
+	config.MessageStoreType:                validString,
+	config.FileStorePath:                   validString,
+	config.FileStoreSync:                   validBool,
+	config.AsyncStoreQueueDepth:            validInt,
+	config.AsyncStoreMaxBatch:              validInt,
+	config.AsyncStoreFlushInterval:         validInt,
+	config.SQLStoreDriver:                  validString,
+	config.SQLStoreDataSourceName:          validString,
+	config.SQLStoreMaxOpenConns:            validInt,
+	config.SQLStoreMaxIdleConns:            validInt,
+	config.SQLStoreConnMaxLifetime:         validInt,
+	config.SQLStoreBatchSize:               validInt,
+	config.MongoStoreConnection:            validString,
+	config.MongoStoreDatabase:              validString,
+	config.MongoStoreEngine:                validString,
+	config.MongoStoreRetention:             validInt,
+	config.MongoStoreWriteConcern:          validString,
+	config.MongoStoreJournal:               validBool,
+	config.RedisStoreAddrs:                 validString,
+	config.RedisStoreCluster:               validBool,
+	config.RedisStorePassword:              validString,
+	config.RedisStoreDB:                    validInt,
+	config.RedisStoreKeyPrefix:             validString,
+	config.BoltStorePath:                   validString,
+	config.BoltStoreNoSync:                 validBool,
+	config.SQLiteStorePath:                 validString,
+	config.SQLiteStoreSynchronous:          validString,
+	config.SQLiteStoreBusyTimeout:          validInt,
+	config.DynamoStoreTable:                validString,
+	config.DynamoStoreRegion:               validString,
+	config.DynamoStoreEndpoint:             validString,
+	config.DynamoStoreMaxBatch:             validInt,
+	config.DuplicateTagPolicy:              func(value string) error { _, err := parseDuplicateTagPolicy(value); return err },
+	config.DuplicateLogonPolicy:            func(value string) error { _, err := parseDuplicateLogonPolicy(value); return err },
+	config.DeliverPossDup:                  validBool,
+	config.PersistMessages:                 func(value string) error { _, err := parsePersistMessages(value); return err },
+	config.ResendRequestChunkSize:          validInt,
+	config.SeqNumTooLowPolicy:              func(value string) error { _, err := parseSeqNumTooLowPolicy(value); return err },
+	config.PoisonMessageThreshold:          validInt,
+	config.PoisonMessagePolicy:             func(value string) error { _, err := parsePoisonMessagePolicy(value); return err },
+	config.SeqNumResetGraceWindow:          validInt,
+	config.MaxSendQueueDepth:               validPositiveInt,
+	config.SendQueueOverflow:               func(value string) error { _, err := parseSendQueueOverflow(value); return err },
+	config.SlowConsumerMaxMessages:         validInt,
+	config.SlowConsumerMaxBytes:            validInt,
+	config.ThrottleRate:                    validFloat,
+	config.ThrottleBurst:                   validInt,
+	config.ThrottlePolicy:                  func(value string) error { _, err := parseThrottlePolicy(value); return err },
+	config.MaxMessageSize:                  validInt,
+	config.CheckLatency:                    validBool,
+	config.MaxLatency:                      validInt,
+	config.LogoutOnMaxLatency:              validBool,
+	config.StartTime:                       validString,
+	config.EndTime:                         validString,
+	config.StartDay:                        validString,
+	config.EndDay:                          validString,
+	config.Weekdays:                        validString,
+	config.TimeZone:                        validString,
+	config.Username:                        validString,
+	config.Password:                        validString,
+	config.RawData:                         validString,
+	config.SendNextExpectedMsgSeqNum:       validBool,
+	config.SecretsProviderType:             validString,
+	config.SecretsCacheTTL:                 validInt,
+	config.VaultAddress:                    validString,
+	config.VaultMount:                      validString,
+	config.VaultNamespace:                  validString,
+	config.VaultTokenFile:                  validString,
+	config.SecretsManagerRegion:            validString,
+	config.SecretsManagerEndpoint:          validString,
+	config.Profile:                         validString,
+	config.RecordLatency:                   validBool,
+	config.MessageTimestamps:               validBool,
+	config.ResendStormThreshold:            validPositiveInt,
+	config.ResendStormWindow:               validPositiveInt,
+	config.LogonRejectThreshold:            validPositiveInt,
+	config.PersistReceivedMessages:         validBool,
+	config.AuditIndexTags:                  func(value string) error { _, err := parseAuditIndexTags(value); return err },
+	config.WebhookURLs:                     validString,
+	config.WebhookSecret:                   validString,
+	config.WebhookMaxRetries:               validInt,
+	config.WebhookRetryInterval:            validPositiveInt,
+	config.WebhookQueueDepth:               validPositiveInt,
+	config.OutboundQueueDepth:              validPositiveInt,
+	config.OutboundQueueOverflow:           func(value string) error { _, err := parseOutboundQueueOverflow(value); return err },
+	config.WriteBatching:                   validBool,
+	config.WriteBatchMaxBytes:              validPositiveInt,
+	config.WriteBatchInterval:              validInt,
+	config.ThreadingModel:                  func(value string) error { _, err := parseThreadingModel(value); return err },
+	config.WorkerPoolSize:                  validPositiveInt,
+}
+
+//numberedSettings are the settings repeated with a number suffix, such as SocketConnectHost1 for the first failover host.
+var numberedSettings = []string{config.SocketConnectHost, config.SocketConnectPort, config.StartTime, config.EndTime}
+
+//Validate checks the global settings and the settings of each session, reporting every problem found as a *SettingsError.
+//Settings not known to the package are reported, with the known setting it is most likely a misspelling of, as are values
+//of the wrong type, such as HeartBtInt=30s, and combinations of settings that conflict, such as ResetOnLogon=Y with
+//SendNextExpectedMsgSeqNum=Y. With ConnectionType=initiator or ConnectionType=acceptor, the settings the Initiator or
+//Acceptor requires of each session are checked, such as SocketConnectHost and SocketConnectPort for an Initiator.
+//
+//Settings are not validated by NewInitiator and NewAcceptor, which accept settings the Application defines for itself.
+func (s *Settings) Validate() error {
+	s.lazyInit()
+
+	var problems []SettingProblem
+	problems = append(problems, validateSettings(nil, s.globalSettings)...)
+
+	profiles := make([]string, 0, len(s.profiles))
+	for profile := range s.profiles {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+
+	for _, profile := range profiles {
+		for _, problem := range validateSettings(nil, s.profiles[profile]) {
+			problem.Profile = profile
+			problems = append(problems, problem)
+		}
+
+		if s.profiles[profile].HasSetting(config.Profile) {
+			problems = append(problems, SettingProblem{Profile: profile, Setting: config.Profile, Problem: "a profile cannot inherit a profile"})
+		}
+	}
+
+	sessionIDs := make([]SessionID, 0, len(s.sessionSettings))
+	for sessionID := range s.sessionSettings {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sort.Slice(sessionIDs, func(i, j int) bool { return sessionIDs[i].String() < sessionIDs[j].String() })
+
+	for i := range sessionIDs {
+		sessionID := &sessionIDs[i]
+		problems = append(problems, validateSettings(sessionID, s.sessionSettings[*sessionID])...)
+
+		if profile := s.profileOf(s.sessionSettings[*sessionID]); profile != "" {
+			if _, ok := s.profiles[profile]; !ok {
+				problems = append(problems, SettingProblem{SessionID: sessionID, Setting: config.Profile, Problem: fmt.Sprintf("unknown profile %v", profile)})
+			}
+		}
+		problems = append(problems, validateSession(sessionID, s.sessionSettingsFor(*sessionID))...)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &SettingsError{Problems: problems}
+}
+
+//validateSettings reports the settings of a section that are not known or not valid, sessionID is nil for the global settings.
+func validateSettings(sessionID *SessionID, settings *SessionSettings) (problems []SettingProblem) {
+	names := make([]string, 0, len(settings.settings))
+	for setting := range settings.settings {
+		names = append(names, setting)
+	}
+	sort.Strings(names)
+
+	for _, setting := range names {
+		validate, err := settingValidatorFor(setting)
+		if err == nil {
+			err = validate(settings.settings[setting])
+		}
+
+		if err != nil {
+			problems = append(problems, SettingProblem{SessionID: sessionID, Setting: setting, Problem: err.Error()})
+		}
+	}
+
+	return
+}
+
+//settingValidatorFor returns the validator of setting, an error if the setting is not known.
+func settingValidatorFor(setting string) (settingValidator, error) {
+	if validate, ok := knownSettings[setting]; ok {
+		return validate, nil
+	}
+
+	for _, numbered := range numberedSettings {
+		if n := strings.TrimPrefix(setting, numbered); n != setting {
+			if _, err := strconv.Atoi(n); err == nil {
+				return knownSettings[numbered], nil
+			}
+		}
+	}
+
+	prefix := config.AppDataDictionary + "."
+	if strings.HasPrefix(setting, prefix) {
+		if _, err := parseApplVerID(strings.TrimPrefix(setting, prefix)); err != nil {
+			return nil, err
+		}
+		return validString, nil
+	}
+
+	if suggestion := closestSetting(setting); suggestion != "" {
+		return nil, fmt.Errorf("unknown setting, did you mean %v?", suggestion)
+	}
+
+	return nil, fmt.Errorf("unknown setting")
+}
+
+//closestSetting returns the known setting setting is most likely a misspelling of, empty if none is close.
+func closestSetting(setting string) string {
+	closest, closestDistance := "", 3
+	for known := range knownSettings {
+		distance := editDistance(strings.ToLower(setting), strings.ToLower(known))
+		if distance < closestDistance || (distance == closestDistance && known < closest) {
+			closest, closestDistance = known, distance
+		}
+	}
+
+	return closest
+}
+
+//editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+
+			current[j] = substitution
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+//validateSession reports the settings required by the session, and the settings of the session that conflict.
+func validateSession(sessionID *SessionID, settings *SessionSettings) (problems []SettingProblem) {
+	problem := func(setting, format string, args ...interface{}) {
+		problems = append(problems, SettingProblem{SessionID: sessionID, Setting: setting, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	isSet := func(setting string) bool {
+		value, err := settings.BoolSetting(setting)
+		return err == nil && value
+	}
+
+	switch sessionID.BeginString {
+	case fix.BeginString_FIX40, fix.BeginString_FIX41, fix.BeginString_FIX42, fix.BeginString_FIX43, fix.BeginString_FIX44, fix.BeginString_FIX50:
+	case fix.BeginString_FIXT11:
+		if !settings.HasSetting(config.DefaultApplVerID) {
+			problem(config.DefaultApplVerID, "required for %v", fix.BeginString_FIXT11)
+		}
+	default:
+		problem(config.BeginString, "unsupported version %v", sessionID.BeginString)
+	}
+
+	connectionType, _ := settings.Setting(config.ConnectionType)
+	switch connectionType {
+	case "initiator":
+		host, err := settings.Setting(config.SocketConnectHost)
+		if err != nil {
+			problem(config.SocketConnectHost, "required by an initiator")
+		}
+
+		if !isUnixSocket(host) && !settings.HasSetting(config.SocketConnectPort) {
+			problem(config.SocketConnectPort, "required by an initiator")
+		}
+
+		if !settings.HasSetting(config.HeartBtInt) {
+			problem(config.HeartBtInt, "required by an initiator")
+		}
+
+		if isSet(config.AcceptorTemplate) {
+			problem(config.AcceptorTemplate, "only valid for an acceptor")
+		}
+
+	case "acceptor":
+		if host, _ := settings.Setting(config.SocketAcceptHost); !isUnixSocket(host) && !settings.HasSetting(config.SocketAcceptPort) {
+			problem(config.SocketAcceptPort, "required by an acceptor")
+		}
+
+		if isSet(config.SocketUseSSL) && !settings.HasSetting(config.SocketCertificateFile) {
+			problem(config.SocketCertificateFile, "required by an acceptor with SocketUseSSL=Y")
+		}
+	}
+
+	if settings.HasSetting(config.SocketCertificateFile) != settings.HasSetting(config.SocketPrivateKeyFile) {
+		if settings.HasSetting(config.SocketCertificateFile) {
+			problem(config.SocketPrivateKeyFile, "required with SocketCertificateFile")
+		} else {
+			problem(config.SocketCertificateFile, "required with SocketPrivateKeyFile")
+		}
+	}
+
+	if setting := referencesSecrets(settings); setting != "" && !settings.HasSetting(config.SecretsProviderType) {
+		problem(config.SecretsProviderType, "required by %v referencing a secret", setting)
+	}
+
+	if isSet(config.ResetOnLogon) {
+		if isSet(config.SendNextExpectedMsgSeqNum) {
+			problem(config.SendNextExpectedMsgSeqNum, "conflicts with ResetOnLogon=Y, sequence numbers reset on each Logon are never recovered")
+		}
+
+		if policy, err := settings.Setting(config.PersistMessages); err == nil {
+			if persist, err := parsePersistMessages(policy); err == nil && persist != persistNone {
+				problem(config.PersistMessages, "conflicts with ResetOnLogon=Y, messages sent are never resent once sequence numbers are reset on each Logon")
+			}
+		}
+	}
+
+	interval, intervalErr := settings.IntSetting(config.ReconnectInterval)
+	maxInterval, maxIntervalErr := settings.IntSetting(config.MaxReconnectInterval)
+	if intervalErr == nil && maxIntervalErr == nil && maxInterval < interval {
+		problem(config.MaxReconnectInterval, "%v is less than ReconnectInterval %v", maxInterval, interval)
+	}
+
+	//the schedule settings are validated together, once each is of the right form
+	if _, err := newSessionSchedule(settings); err != nil {
+		problem(config.StartTime, "invalid schedule: %v", err)
+	}
+
+	return
+}
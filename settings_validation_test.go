@@ -0,0 +1,153 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"strings"
+	"testing"
+)
+
+func TestSettings_Validate(t *testing.T) {
+	s, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+ConnectionType=initiator
+SocketConnectHost=127.0.0.1
+SocketConnectPort=5001
+SocketConnectHost1=127.0.0.2
+SocketConnectPort1=5002
+HeartBtInt=30
+StartTime=08:00:00
+EndTime=17:00:00
+LogEventLevel=Warn
+
+[SESSION]
+BeginString=FIXT.1.1
+DefaultApplVerID=FIX.5.0SP2
+AppDataDictionary.FIX.5.0SP2=spec/FIX50SP2.xml
+SenderCompID=CLIENT
+TargetCompID=VENUE
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Validate(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestSettings_ValidateProblems(t *testing.T) {
+	s, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+ConnectionType=initiator
+SocketConnectHost=127.0.0.1
+HartBtInt=30
+ReconnectInterval=30s
+MyApplicationSetting=Y
+
+[SESSION]
+BeginString=FIXT.1.1
+SenderCompID=CLIENT
+TargetCompID=VENUE
+SocketConnectPort=70000
+ResetOnLogon=Y
+SendNextExpectedMsgSeqNum=Y
+AcceptorTemplate=Y
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Validate()
+	settingsErr, ok := err.(*SettingsError)
+	if !ok {
+		t.Fatalf("Expected *SettingsError, got %v", err)
+	}
+
+	expected := []string{
+		"[DEFAULT] HartBtInt: unknown setting, did you mean HeartBtInt?",
+		"[DEFAULT] MyApplicationSetting: unknown setting",
+		"[DEFAULT] ReconnectInterval: expected a positive integer, got 30s",
+		"[SESSION FIXT.1.1:CLIENT->VENUE] SocketConnectPort: expected a port from 1 to 65535, got 70000",
+		"[SESSION FIXT.1.1:CLIENT->VENUE] DefaultApplVerID: required for FIXT.1.1",
+		"[SESSION FIXT.1.1:CLIENT->VENUE] HeartBtInt: required by an initiator",
+		"[SESSION FIXT.1.1:CLIENT->VENUE] AcceptorTemplate: only valid for an acceptor",
+		"[SESSION FIXT.1.1:CLIENT->VENUE] SendNextExpectedMsgSeqNum: conflicts with ResetOnLogon=Y, sequence numbers reset on each Logon are never recovered",
+	}
+
+	if len(settingsErr.Problems) != len(expected) {
+		t.Fatalf("Expected %v problems, got %v", len(expected), settingsErr)
+	}
+
+	for i, problem := range settingsErr.Problems {
+		if problem.String() != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], problem)
+		}
+	}
+
+	if settingsErr.Problems[0].SessionID != nil || settingsErr.Problems[0].Setting != "HartBtInt" {
+		t.Errorf("Unexpected problem %+v", settingsErr.Problems[0])
+	}
+}
+
+func TestSettings_ValidateAcceptor(t *testing.T) {
+	s := NewSettings()
+	s.GlobalSettings().Set(config.ConnectionType, "acceptor")
+	s.GlobalSettings().Set(config.SocketUseSSL, "Y")
+	s.GlobalSettings().Set(config.SocketPrivateKeyFile, "acceptor.key")
+	if _, err := s.AddSession(newTestAcceptorSessionSettings("VALIDATE")); err != nil {
+		t.Fatal(err)
+	}
+
+	settingsErr, ok := s.Validate().(*SettingsError)
+	if !ok || len(settingsErr.Problems) != 3 {
+		t.Fatalf("Expected SocketAcceptPort and SocketCertificateFile reported, got %v", settingsErr)
+	}
+
+	for i, setting := range []string{config.SocketAcceptPort, config.SocketCertificateFile, config.SocketCertificateFile} {
+		if settingsErr.Problems[i].Setting != setting {
+			t.Errorf("Expected %v reported, got %v", setting, settingsErr.Problems[i])
+		}
+	}
+
+	s.GlobalSettings().Set(config.SocketAcceptPort, "5001")
+	s.GlobalSettings().Set(config.SocketCertificateFile, "acceptor.crt")
+	if err := s.Validate(); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestSettings_ValidateProfile(t *testing.T) {
+	s := NewSettings()
+	profile := NewSessionSettings()
+	profile.Set(config.HeartBtInt, "fast")
+	profile.Set(config.Profile, "other")
+	if err := s.AddProfile("lowLatency", profile); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.AddSession(newTestAcceptorSessionSettings("VALIDATE_PROFILE")); err != nil {
+		t.Fatal(err)
+	}
+	s.GlobalSettings().Set(config.Profile, "missing")
+
+	settingsErr, ok := s.Validate().(*SettingsError)
+	if !ok {
+		t.Fatalf("Expected *SettingsError, got %v", settingsErr)
+	}
+
+	expected := []string{
+		"[PROFILE lowLatency] HeartBtInt: expected a positive integer, got fast",
+		"[PROFILE lowLatency] Profile: a profile cannot inherit a profile",
+		"[SESSION FIX.4.2:ACCEPTOR->VALIDATE_PROFILE] Profile: unknown profile missing",
+	}
+
+	if len(settingsErr.Problems) != len(expected) {
+		t.Fatalf("Expected %v problems, got %v", len(expected), settingsErr)
+	}
+
+	for i, problem := range settingsErr.Problems {
+		if problem.String() != expected[i] {
+			t.Errorf("Expected %v, got %v", expected[i], problem)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package quickfix
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+//connectionSet tracks the open connections of an Initiator or Acceptor, to wait for or close them on shutdown.
+type connectionSet struct {
+	lock   sync.Mutex
+	conns  map[net.Conn]bool
+	closed bool
+	done   sync.WaitGroup
+}
+
+//open accepts connections to be added.
+func (c *connectionSet) open() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = false
+}
+
+//add tracks conn, returning false if the set is closed.
+func (c *connectionSet) add(conn net.Conn) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	if c.conns == nil {
+		c.conns = make(map[net.Conn]bool)
+	}
+	c.conns[conn] = true
+	c.done.Add(1)
+
+	return true
+}
+
+//remove stops tracking conn once its session has disconnected.
+func (c *connectionSet) remove(conn net.Conn) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.conns[conn] {
+		delete(c.conns, conn)
+		c.done.Done()
+	}
+}
+
+//close refuses further connections.
+func (c *connectionSet) close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.closed = true
+}
+
+//wait waits for the connections to be removed. If ctx is done first, the connections are closed and the error of ctx returned.
+func (c *connectionSet) wait(ctx context.Context) error {
+	done := make(chan interface{})
+	go func() {
+		c.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	c.lock.Lock()
+	for conn := range c.conns {
+		conn.Close()
+	}
+	c.lock.Unlock()
+
+	<-done
+	return ctx.Err()
+}
+
+//requestShutdown logs out the session if connected, after sending messages delayed by the throttle.
+func (s *Session) requestShutdown() {
+	select {
+	case s.shutdown <- true:
+	default:
+	}
+	s.wake()
+}
+
+//flushDelayed sends the messages delayed by the throttle without further delay.
+func (s *Session) flushDelayed() {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+
+	for _, msg := range s.delayed {
+		if err := s.sendLocked(msg); err != nil {
+			logEventf(s.log, LogLevelWarn, LogCategoryTransport, "Queued message not sent: %v", err)
+		}
+	}
+	s.delayed = nil
+}
@@ -0,0 +1,109 @@
+package quickfix
+
+import (
+	"context"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//shutdownClient reports each SessionState entered.
+type shutdownClient struct {
+	TestClient
+	states chan SessionState
+}
+
+func (c *shutdownClient) OnSessionStateChange(sessionID SessionID, from, to SessionState, reason error) {
+	c.states <- to
+}
+
+func awaitState(t *testing.T, states chan SessionState, expected SessionState) {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case state := <-states:
+			if state == expected {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %v", expected)
+		}
+	}
+}
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestShutdown_LogoutHandshake(t *testing.T) {
+	port := strconv.Itoa(freePort(t))
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, port)
+	if _, err := acceptorSettings.AddSession(newTestAcceptorSessionSettings("SHUTDOWN")); err != nil {
+		t.Fatal(err)
+	}
+
+	acceptorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorSettings := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "SHUTDOWN")
+	sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+	sessionSettings.Set(config.HeartBtInt, "30")
+	sessionSettings.Set(config.SocketConnectHost, "127.0.0.1")
+	sessionSettings.Set(config.SocketConnectPort, port)
+	if _, err := initiatorSettings.AddSession(sessionSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	awaitState(t, initiatorApp.states, StateLoggedOn)
+	awaitState(t, acceptorApp.states, StateLoggedOn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	//the logout times out after 2 seconds, a reply from the counterparty disconnects before ctx is done
+	if err := initiator.Shutdown(ctx); err != nil {
+		t.Errorf("Expected counterparty to reply to Logout: %v", err)
+	}
+
+	awaitState(t, initiatorApp.states, StateDisconnected)
+	awaitState(t, acceptorApp.states, StateDisconnected)
+
+	if err := acceptor.Shutdown(ctx); err != nil {
+		t.Errorf("Unexpected error shutting down Acceptor: %v", err)
+	}
+
+	if _, err := net.Dial("tcp", "127.0.0.1:"+port); err == nil {
+		t.Error("Expected Acceptor to stop accepting connections")
+	}
+}
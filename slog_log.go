@@ -0,0 +1,91 @@
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+//Attribute keys of the records written by the slog LogFactory.
+const (
+	SlogKeySession   = "session"
+	SlogKeyDirection = "direction"
+	SlogKeyMsgType   = "msg_type"
+	SlogKeySeqNum    = "seqnum"
+	SlogKeyMessage   = "message"
+	SlogKeyCategory  = "category"
+)
+
+type slogLog struct {
+	logger *slog.Logger
+}
+
+func (l slogLog) OnIncoming(msg string) {
+	l.logMessage("incoming", msg)
+}
+
+func (l slogLog) OnOutgoing(msg string) {
+	l.logMessage("outgoing", msg)
+}
+
+//logMessage writes msg with its direction, MsgType and MsgSeqNum as attributes, those missing from msg are omitted.
+func (l slogLog) logMessage(direction, msg string) {
+	attrs := []slog.Attr{slog.String(SlogKeyDirection, direction)}
+
+	msgType, seqNum := logHeader(msg)
+	if msgType != nil {
+		attrs = append(attrs, slog.String(SlogKeyMsgType, msgType.Value))
+	}
+
+	if seqNum != nil {
+		attrs = append(attrs, slog.Int(SlogKeySeqNum, seqNum.Value))
+	}
+
+	attrs = append(attrs, slog.String(SlogKeyMessage, msg))
+	l.logger.LogAttrs(context.Background(), slog.LevelInfo, direction+" message", attrs...)
+}
+
+func (l slogLog) OnEvent(msg string) {
+	l.logger.LogAttrs(context.Background(), slog.LevelInfo, msg)
+}
+
+//OnLeveledEvent writes msg at the slog level of level, with its category as an attribute.
+func (l slogLog) OnLeveledEvent(level LogLevel, category LogCategory, msg string) {
+	l.logger.LogAttrs(context.Background(), slogLevel(level), msg, slog.String(SlogKeyCategory, string(category)))
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	}
+
+	return slog.LevelInfo
+}
+
+func (l slogLog) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+type slogLogFactory struct {
+	logger *slog.Logger
+}
+
+func (f slogLogFactory) Create() (Log, error) {
+	return slogLog{f.logger}, nil
+}
+
+func (f slogLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	return slogLog{f.logger.With(slog.String(SlogKeySession, sessionID.String()))}, nil
+}
+
+//NewSlogLogFactory creates an instance of LogFactory that writes messages and events as structured records to logger.
+//Session logs add the session ID to each record, messages are recorded with their direction, MsgType and MsgSeqNum.
+//Messages are written at slog.LevelInfo, events at the slog level of their LogLevel with their LogCategory, filtered by the handler of logger.
+func NewSlogLogFactory(logger *slog.Logger) LogFactory {
+	return slogLogFactory{logger}
+}
@@ -0,0 +1,62 @@
+package quickfix
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func decodeRecords(t *testing.T, buffer *bytes.Buffer) (records []map[string]interface{}) {
+	decoder := json.NewDecoder(buffer)
+	for decoder.More() {
+		record := make(map[string]interface{})
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+
+	return
+}
+
+func TestSlogLog(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	factory := NewSlogLogFactory(slog.New(slog.NewJSONHandler(buffer, nil)))
+
+	sessionID := SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+	log, err := factory.CreateSessionLog(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.OnIncoming("8=FIX.4.2\x019=49\x0135=A\x0134=7\x0149=ISLD\x0152=20160101-00:00:00\x0156=TW\x0110=000\x01")
+	log.OnOutgoing("garbled")
+	log.OnEventf("Received ResendRequest FROM: %d TO: %d", 1, 2)
+
+	global, _ := factory.Create()
+	global.OnEvent("Listening")
+
+	records := decodeRecords(t, buffer)
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 records, got %v", records)
+	}
+
+	incoming := records[0]
+	if incoming[SlogKeySession] != sessionID.String() || incoming[SlogKeyDirection] != "incoming" || incoming[SlogKeyMsgType] != "A" || incoming[SlogKeySeqNum] != float64(7) {
+		t.Errorf("Unexpected incoming record %v", incoming)
+	}
+
+	outgoing := records[1]
+	if _, ok := outgoing[SlogKeyMsgType]; ok || outgoing[SlogKeyDirection] != "outgoing" || outgoing[SlogKeyMessage] != "garbled" {
+		t.Errorf("Expected unparsed attributes omitted, got %v", outgoing)
+	}
+
+	if event := records[2]; event["msg"] != "Received ResendRequest FROM: 1 TO: 2" || event[SlogKeySession] != sessionID.String() {
+		t.Errorf("Unexpected event record %v", event)
+	}
+
+	if _, ok := records[3][SlogKeySession]; ok {
+		t.Errorf("Expected global record without session, got %v", records[3])
+	}
+}
@@ -0,0 +1,161 @@
+package quickfix
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"net"
+	"time"
+)
+
+//socketOptions tune the TCP connections of a session, options not set keep the defaults of the operating system and Go.
+type socketOptions struct {
+	//noDelay disables Nagle's algorithm, set if hasNoDelay
+	noDelay, hasNoDelay bool
+
+	//keepAlive is the interval of TCP keepalive probes, negative to disable keepalive, 0 if not set
+	keepAlive time.Duration
+
+	//receiveBufferSize and sendBufferSize are SO_RCVBUF and SO_SNDBUF in bytes, 0 if not set
+	receiveBufferSize, sendBufferSize int
+
+	//trafficClass is the IP TOS byte, or IPv6 traffic class, marking the packets sent, -1 if not set
+	trafficClass int
+}
+
+//newSocketOptions returns the options configured by SocketNodelay, SocketKeepAliveInterval, SocketReceiveBufferSize, SocketSendBufferSize
+//and SocketTrafficClass, nil if none are set. SocketKeepAliveInterval is in seconds, 0 disables keepalive. SocketTrafficClass is the
+//TOS byte, the DSCP shifted left by 2 bits, 184 for Expedited Forwarding.
+func newSocketOptions(settings *SessionSettings) (*socketOptions, error) {
+	o := &socketOptions{trafficClass: -1}
+	set := false
+
+	if settings.HasSetting(config.SocketNodelay) {
+		var err error
+		if o.noDelay, err = settings.BoolSetting(config.SocketNodelay); err != nil {
+			return nil, err
+		}
+		o.hasNoDelay, set = true, true
+	}
+
+	if settings.HasSetting(config.SocketKeepAliveInterval) {
+		seconds, err := settings.IntSetting(config.SocketKeepAliveInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case seconds < 0:
+			return nil, fmt.Errorf("%v must not be negative", config.SocketKeepAliveInterval)
+		case seconds == 0:
+			o.keepAlive = -1
+		default:
+			o.keepAlive = time.Duration(seconds) * time.Second
+		}
+		set = true
+	}
+
+	for _, option := range []struct {
+		setting string
+		size    *int
+	}{
+		{config.SocketReceiveBufferSize, &o.receiveBufferSize},
+		{config.SocketSendBufferSize, &o.sendBufferSize},
+	} {
+		if !settings.HasSetting(option.setting) {
+			continue
+		}
+
+		size, err := settings.IntSetting(option.setting)
+		if err != nil {
+			return nil, err
+		}
+
+		if size <= 0 {
+			return nil, fmt.Errorf("%v must be a positive number", option.setting)
+		}
+		*option.size, set = size, true
+	}
+
+	if settings.HasSetting(config.SocketTrafficClass) {
+		trafficClass, err := settings.IntSetting(config.SocketTrafficClass)
+		if err != nil {
+			return nil, err
+		}
+
+		if trafficClass < 0 || trafficClass > 255 {
+			return nil, fmt.Errorf("%v must be between 0 and 255", config.SocketTrafficClass)
+		}
+		o.trafficClass, set = trafficClass, true
+	}
+
+	if !set {
+		return nil, nil
+	}
+
+	return o, nil
+}
+
+//apply sets the options on conn, or the TCP connection secured by conn or accepted from a load balancer. Connections other than TCP are left as they are.
+func (o *socketOptions) apply(conn net.Conn) error {
+	if o == nil {
+		return nil
+	}
+
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+
+	if proxied, ok := conn.(*proxyConn); ok {
+		conn = proxied.Conn
+	}
+
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if o.hasNoDelay {
+		if err := tcpConn.SetNoDelay(o.noDelay); err != nil {
+			return err
+		}
+	}
+
+	if o.keepAlive < 0 {
+		if err := tcpConn.SetKeepAlive(false); err != nil {
+			return err
+		}
+	} else if o.keepAlive > 0 {
+		if err := tcpConn.SetKeepAlive(true); err != nil {
+			return err
+		}
+
+		if err := tcpConn.SetKeepAlivePeriod(o.keepAlive); err != nil {
+			return err
+		}
+	}
+
+	if o.receiveBufferSize > 0 {
+		if err := tcpConn.SetReadBuffer(o.receiveBufferSize); err != nil {
+			return err
+		}
+	}
+
+	if o.sendBufferSize > 0 {
+		if err := tcpConn.SetWriteBuffer(o.sendBufferSize); err != nil {
+			return err
+		}
+	}
+
+	if o.trafficClass >= 0 {
+		if addr, ok := tcpConn.LocalAddr().(*net.TCPAddr); ok && addr.IP.To4() == nil {
+			return ipv6.NewConn(tcpConn).SetTrafficClass(o.trafficClass)
+		}
+
+		return ipv4.NewConn(tcpConn).SetTOS(o.trafficClass)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"golang.org/x/net/ipv4"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNewSocketOptions(t *testing.T) {
+	if options, err := newSocketOptions(NewSessionSettings()); err != nil || options != nil {
+		t.Errorf("Expected no options without settings, got %v %v", options, err)
+	}
+
+	settings := NewSessionSettings()
+	settings.Set(config.SocketNodelay, "N")
+	settings.Set(config.SocketKeepAliveInterval, "15")
+	settings.Set(config.SocketReceiveBufferSize, "65536")
+	settings.Set(config.SocketSendBufferSize, "131072")
+	settings.Set(config.SocketTrafficClass, "184")
+
+	options, err := newSocketOptions(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := socketOptions{hasNoDelay: true, keepAlive: 15 * time.Second, receiveBufferSize: 65536, sendBufferSize: 131072, trafficClass: 184}
+	if *options != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *options)
+	}
+
+	settings.Set(config.SocketKeepAliveInterval, "0")
+	if options, err = newSocketOptions(settings); err != nil || options.keepAlive >= 0 {
+		t.Errorf("Expected keepalive disabled, got %v %v", options, err)
+	}
+
+	for key, value := range map[string]string{
+		config.SocketNodelay:           "maybe",
+		config.SocketKeepAliveInterval: "-1",
+		config.SocketReceiveBufferSize: "0",
+		config.SocketSendBufferSize:    "large",
+		config.SocketTrafficClass:      "256",
+	} {
+		invalid := settings.clone()
+		invalid.Set(key, value)
+		if _, err := newSocketOptions(invalid); err == nil {
+			t.Errorf("Expected error for %v=%v", key, value)
+		}
+	}
+}
+
+func TestSocketOptions_Apply(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	options := &socketOptions{hasNoDelay: true, keepAlive: 30 * time.Second, receiveBufferSize: 65536, sendBufferSize: 65536, trafficClass: 184}
+	if err := options.apply(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	if tos, err := ipv4.NewConn(conn).TOS(); err != nil || tos != 184 {
+		t.Errorf("Expected TOS 184, got %v %v", tos, err)
+	}
+
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+	if err := options.apply(local); err != nil {
+		t.Errorf("Expected connections other than TCP left as they are, got %v", err)
+	}
+
+	var none *socketOptions
+	if err := none.apply(conn); err != nil {
+		t.Errorf("Expected no options applied, got %v", err)
+	}
+}
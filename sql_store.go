@@ -0,0 +1,556 @@
+package quickfix
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//sqlDialect adapts the statements of the sql store to a database.
+type sqlDialect struct {
+	//numberedParams is true for databases with numbered placeholders, $1, $2, rather than ?
+	numberedParams bool
+	blobType       string
+}
+
+//dialectFor returns the sqlDialect of the database/sql driver named driver.
+func dialectFor(driver string) sqlDialect {
+	switch driver {
+	case "postgres", "pgx":
+		return sqlDialect{numberedParams: true, blobType: "BYTEA"}
+	case "mysql":
+		return sqlDialect{blobType: "LONGBLOB"}
+	}
+
+	return sqlDialect{blobType: "BLOB"}
+}
+
+//rebind replaces the ? placeholders of query for the dialect.
+func (d sqlDialect) rebind(query string) string {
+	if !d.numberedParams {
+		return query
+	}
+
+	var rebound strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			rebound.WriteRune(r)
+			continue
+		}
+
+		n++
+		rebound.WriteString("$" + strconv.Itoa(n))
+	}
+
+	return rebound.String()
+}
+
+//sqlMigrations create the schema of the sql store, sqlMigrations[i] upgrades the schema from version i to i+1.
+//Migrations are only ever appended, the schema version of a database is recorded in quickfix_schema.
+var sqlMigrations = []func(d sqlDialect) []string{
+	func(d sqlDialect) []string {
+		return []string{
+			`CREATE TABLE sessions (
+				beginstring VARCHAR(8) NOT NULL,
+				sendercompid VARCHAR(64) NOT NULL,
+				targetcompid VARCHAR(64) NOT NULL,
+				session_qualifier VARCHAR(64) NOT NULL,
+				creation_time BIGINT NOT NULL,
+				incoming_seqnum INTEGER NOT NULL,
+				outgoing_seqnum INTEGER NOT NULL,
+				logged_on INTEGER NOT NULL,
+				resend_begin_seqnum INTEGER NOT NULL,
+				resend_end_seqnum INTEGER NOT NULL,
+				PRIMARY KEY (beginstring, sendercompid, targetcompid, session_qualifier))`,
+			`CREATE TABLE messages (
+				beginstring VARCHAR(8) NOT NULL,
+				sendercompid VARCHAR(64) NOT NULL,
+				targetcompid VARCHAR(64) NOT NULL,
+				session_qualifier VARCHAR(64) NOT NULL,
+				msgseqnum INTEGER NOT NULL,
+				message ` + d.blobType + ` NOT NULL,
+				PRIMARY KEY (beginstring, sendercompid, targetcompid, session_qualifier, msgseqnum))`,
+		}
+	},
+}
+
+//migrateSQLStore upgrades the schema of db to the latest of sqlMigrations.
+func migrateSQLStore(db *sql.DB, d sqlDialect) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS quickfix_schema (version INTEGER NOT NULL)`); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	version := 0
+	switch err := tx.QueryRow(`SELECT version FROM quickfix_schema`).Scan(&version); err {
+	case nil:
+	case sql.ErrNoRows:
+		if _, err := tx.Exec(d.rebind(`INSERT INTO quickfix_schema (version) VALUES (?)`), 0); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	for ; version < len(sqlMigrations); version++ {
+		for _, statement := range sqlMigrations[version](d) {
+			if _, err := tx.Exec(statement); err != nil {
+				return fmt.Errorf("schema migration %d failed: %v", version+1, err)
+			}
+		}
+
+		if _, err := tx.Exec(d.rebind(`UPDATE quickfix_schema SET version = ?`), version+1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+//sqlPoolSettings configure the connection pool of a database.
+type sqlPoolSettings struct {
+	maxOpenConns, maxIdleConns int
+	connMaxLifetime            time.Duration
+}
+
+func (p sqlPoolSettings) apply(db *sql.DB) {
+	if p.maxOpenConns > 0 {
+		db.SetMaxOpenConns(p.maxOpenConns)
+	}
+
+	if p.maxIdleConns > 0 {
+		db.SetMaxIdleConns(p.maxIdleConns)
+	}
+
+	if p.connMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.connMaxLifetime)
+	}
+}
+
+type sqlStoreFactory struct {
+	settings *Settings
+
+	//dbs are shared by the sessions using the same driver and data source
+	dbLock sync.Mutex
+	dbs    map[string]*sql.DB
+}
+
+//NewSQLStoreFactory returns a MessageStoreFactory that creates MessageStores persisting messages and sequence numbers with database/sql.
+//The database is configured via SQLStoreDriver and SQLStoreDataSourceName, the driver must be imported by the application.
+//The schema is created, or upgraded, when the database is first opened.
+//
+//With SQLStoreBatchSize above 1, 1 by default, messages are inserted in batches while the sequence numbers are persisted as they
+//change, ahead of the messages of an incomplete batch. Messages of a batch not inserted when the process stops are lost, resends
+//after a crash do not find them and gap fill them instead.
+func NewSQLStoreFactory(settings *Settings) (MessageStoreFactory, error) {
+	for _, sessionSettings := range settings.SessionSettings() {
+		for _, setting := range []string{config.SQLStoreDriver, config.SQLStoreDataSourceName} {
+			if !sessionSettings.HasSetting(setting) {
+				return nil, requiredConfigurationMissing(setting)
+			}
+		}
+	}
+
+	return &sqlStoreFactory{settings: settings, dbs: make(map[string]*sql.DB)}, nil
+}
+
+func (f *sqlStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
+	if _, ok := f.settings.sessionSettings[sessionID]; !ok {
+		return nil, fmt.Errorf("sql store not defined for %v", sessionID)
+	}
+
+	settings := f.settings.sessionSettingsFor(sessionID)
+	driver, err := settings.Setting(config.SQLStoreDriver)
+	if err != nil {
+		return nil, requiredConfigurationMissing(config.SQLStoreDriver)
+	}
+
+	dataSourceName, err := settings.Setting(config.SQLStoreDataSourceName)
+	if err != nil {
+		return nil, requiredConfigurationMissing(config.SQLStoreDataSourceName)
+	}
+
+	pool, err := sqlPoolSettingsFor(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := 1
+	if settings.HasSetting(config.SQLStoreBatchSize) {
+		if batchSize, err = settings.IntSetting(config.SQLStoreBatchSize); err != nil {
+			return nil, err
+		}
+		if batchSize < 1 {
+			return nil, fmt.Errorf("invalid SQLStoreBatchSize %v, expected at least 1", batchSize)
+		}
+	}
+
+	dialect := dialectFor(driver)
+	db, err := f.open(driver, dataSourceName, dialect, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSQLStore(sessionID, db, dialect, batchSize)
+}
+
+//open returns the database for driver and dataSourceName, opening and migrating it if not already open.
+func (f *sqlStoreFactory) open(driver, dataSourceName string, dialect sqlDialect, pool sqlPoolSettings) (*sql.DB, error) {
+	f.dbLock.Lock()
+	defer f.dbLock.Unlock()
+
+	key := driver + " " + dataSourceName
+	if db, ok := f.dbs[key]; ok {
+		return db, nil
+	}
+
+	db, err := sql.Open(driver, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	pool.apply(db)
+
+	if err := migrateSQLStore(db, dialect); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	f.dbs[key] = db
+	return db, nil
+}
+
+//sqlPoolSettingsFor parses the connection pool settings, SQLStoreConnMaxLifetime is in seconds.
+func sqlPoolSettingsFor(settings *SessionSettings) (pool sqlPoolSettings, err error) {
+	if settings.HasSetting(config.SQLStoreMaxOpenConns) {
+		if pool.maxOpenConns, err = settings.IntSetting(config.SQLStoreMaxOpenConns); err != nil {
+			return
+		}
+	}
+
+	if settings.HasSetting(config.SQLStoreMaxIdleConns) {
+		if pool.maxIdleConns, err = settings.IntSetting(config.SQLStoreMaxIdleConns); err != nil {
+			return
+		}
+	}
+
+	if settings.HasSetting(config.SQLStoreConnMaxLifetime) {
+		var seconds int
+		if seconds, err = settings.IntSetting(config.SQLStoreConnMaxLifetime); err != nil {
+			return
+		}
+		pool.connMaxLifetime = time.Duration(seconds) * time.Second
+	}
+
+	return
+}
+
+//sqlStore is a MessageStore persisting a session as a row of the sessions table, and its messages as rows of the messages table.
+type sqlStore struct {
+	sessionID SessionID
+	db        *sql.DB
+	dialect   sqlDialect
+
+	//seqNumLock guards the sequence numbers read outside the session goroutine
+	seqNumLock                       sync.RWMutex
+	senderMsgSeqNum, targetMsgSeqNum int
+	recoveryState                    RecoveryState
+	creationTime                     time.Time
+
+	//pending messages are inserted together once batchSize are saved
+	batchLock sync.Mutex
+	batchSize int
+	pending   []pendingMessage
+
+	//writeErr keeps the error of the first failed statement, an update of the session row is only reported by the next TrySaveMessage and Health
+	writeErr ErrorLatch
+}
+
+type pendingMessage struct {
+	seqNum int
+	msg    []byte
+}
+
+func newSQLStore(sessionID SessionID, db *sql.DB, dialect sqlDialect, batchSize int) (*sqlStore, error) {
+	store := &sqlStore{sessionID: sessionID, db: db, dialect: dialect, batchSize: batchSize}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+//sessionKey is the parameters of the key columns of the session.
+func (store *sqlStore) sessionKey() []interface{} {
+	return []interface{}{store.sessionID.BeginString, store.sessionID.SenderCompID, store.sessionID.TargetCompID, store.sessionID.Qualifier}
+}
+
+const sqlSessionKeyClause = `beginstring = ? AND sendercompid = ? AND targetcompid = ? AND session_qualifier = ?`
+
+//load reads the session row, inserting it if the session is new.
+func (store *sqlStore) load() error {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+
+	var creationTime int64
+	var loggedOn int
+	state := &store.recoveryState
+	err := store.db.QueryRow(store.dialect.rebind(`SELECT creation_time, incoming_seqnum, outgoing_seqnum, logged_on, resend_begin_seqnum, resend_end_seqnum
+		FROM sessions WHERE `+sqlSessionKeyClause), store.sessionKey()...).Scan(&creationTime, &store.targetMsgSeqNum, &store.senderMsgSeqNum, &loggedOn, &state.ResendBeginSeqNo, &state.ResendEndSeqNo)
+
+	switch err {
+	case nil:
+		store.creationTime = time.Unix(0, creationTime).UTC()
+		state.LoggedOn = loggedOn == 1
+		return nil
+
+	case sql.ErrNoRows:
+		store.creationTime = time.Now().UTC()
+		args := append(store.sessionKey(), store.creationTime.UnixNano())
+		_, err = store.db.Exec(store.dialect.rebind(`INSERT INTO sessions (beginstring, sendercompid, targetcompid, session_qualifier,
+			creation_time, incoming_seqnum, outgoing_seqnum, logged_on, resend_begin_seqnum, resend_end_seqnum)
+			VALUES (?, ?, ?, ?, ?, 0, 0, 0, 0, 0)`), args...)
+		return err
+	}
+
+	return err
+}
+
+//saveSessionRow updates the session row with the sequence numbers and RecoveryState, with seqNumLock held.
+func (store *sqlStore) saveSessionRow() {
+	loggedOn := 0
+	if store.recoveryState.LoggedOn {
+		loggedOn = 1
+	}
+
+	args := []interface{}{store.creationTime.UnixNano(), store.targetMsgSeqNum, store.senderMsgSeqNum, loggedOn, store.recoveryState.ResendBeginSeqNo, store.recoveryState.ResendEndSeqNo}
+	_, err := store.db.Exec(store.dialect.rebind(`UPDATE sessions SET creation_time = ?, incoming_seqnum = ?, outgoing_seqnum = ?,
+		logged_on = ?, resend_begin_seqnum = ?, resend_end_seqnum = ? WHERE `+sqlSessionKeyClause), append(args, store.sessionKey()...)...)
+	store.writeErr.Set(err)
+}
+
+func (store *sqlStore) NextSenderMsgSeqNum() int {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
+	return store.senderMsgSeqNum + 1
+}
+
+func (store *sqlStore) NextTargetMsgSeqNum() int {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
+	return store.targetMsgSeqNum + 1
+}
+
+func (store *sqlStore) IncrNextSenderMsgSeqNum() {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.senderMsgSeqNum++
+	store.saveSessionRow()
+}
+
+func (store *sqlStore) IncrNextTargetMsgSeqNum() {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.targetMsgSeqNum++
+	store.saveSessionRow()
+}
+
+func (store *sqlStore) SetNextSenderMsgSeqNum(nextSeqNum int) {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.senderMsgSeqNum = nextSeqNum - 1
+	store.saveSessionRow()
+}
+
+func (store *sqlStore) SetNextTargetMsgSeqNum(nextSeqNum int) {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.targetMsgSeqNum = nextSeqNum - 1
+	store.saveSessionRow()
+}
+
+func (store *sqlStore) CreationTime() time.Time {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
+	return store.creationTime
+}
+
+func (store *sqlStore) SaveRecoveryState(state RecoveryState) error {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.recoveryState = state
+	store.saveSessionRow()
+	return store.writeErr.Err()
+}
+
+func (store *sqlStore) RecoveryState() (RecoveryState, error) {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
+	return store.recoveryState, nil
+}
+
+//Reset deletes the messages of the session and resets the sequence numbers, starting a new session.
+func (store *sqlStore) Reset() {
+	store.batchLock.Lock()
+	store.pending = nil
+	_, err := store.db.Exec(store.dialect.rebind(`DELETE FROM messages WHERE `+sqlSessionKeyClause), store.sessionKey()...)
+	store.writeErr.Set(err)
+	store.batchLock.Unlock()
+
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.creationTime = time.Now().UTC()
+	store.senderMsgSeqNum, store.targetMsgSeqNum = 0, 0
+	store.recoveryState.ResendBeginSeqNo, store.recoveryState.ResendEndSeqNo = 0, 0
+	store.saveSessionRow()
+}
+
+//Refresh reloads the session row, clearing any write error.
+func (store *sqlStore) Refresh() {
+	store.writeErr.Clear()
+
+	store.writeErr.Set(store.load())
+}
+
+//TrySaveMessage inserts msg, or with SQLStoreBatchSize queues msg to be inserted with the rest of its batch.
+//Messages of an incomplete batch are inserted by Flush and before messages are read for resend, not before the sequence numbers are
+//persisted.
+func (store *sqlStore) TrySaveMessage(seqNum int, msg []byte) error {
+	if err := store.writeErr.Err(); err != nil {
+		return err
+	}
+
+	store.batchLock.Lock()
+	defer store.batchLock.Unlock()
+
+	store.pending = append(store.pending, pendingMessage{seqNum: seqNum, msg: msg})
+	if len(store.pending) < store.batchSize {
+		return nil
+	}
+
+	return store.flushLocked()
+}
+
+//SaveMessage inserts or queues msg as TrySaveMessage, a failed insert kept as the error reported by Health until Refresh.
+func (store *sqlStore) SaveMessage(seqNum int, msg []byte) {
+	store.writeErr.Set(store.TrySaveMessage(seqNum, msg))
+}
+
+//flushLocked inserts the pending messages in a single transaction, with batchLock held.
+func (store *sqlStore) flushLocked() error {
+	if len(store.pending) == 0 {
+		return nil
+	}
+
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deleteStmt, err := tx.Prepare(store.dialect.rebind(`DELETE FROM messages WHERE ` + sqlSessionKeyClause + ` AND msgseqnum = ?`))
+	if err != nil {
+		return err
+	}
+	defer deleteStmt.Close()
+
+	insertStmt, err := tx.Prepare(store.dialect.rebind(`INSERT INTO messages (beginstring, sendercompid, targetcompid, session_qualifier, msgseqnum, message)
+		VALUES (?, ?, ?, ?, ?, ?)`))
+	if err != nil {
+		return err
+	}
+	defer insertStmt.Close()
+
+	for _, pending := range store.pending {
+		//a message saved again with the same seqnum replaces the first
+		if _, err := deleteStmt.Exec(append(store.sessionKey(), pending.seqNum)...); err != nil {
+			return err
+		}
+
+		if _, err := insertStmt.Exec(append(store.sessionKey(), pending.seqNum, pending.msg)...); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	store.pending = nil
+	return nil
+}
+
+//Health returns the first write error since the store was refreshed, or the failure to reach the database.
+func (store *sqlStore) Health() error {
+	if err := store.writeErr.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeHealthTimeout)
+	defer cancel()
+	return store.db.PingContext(ctx)
+}
+
+//Flush inserts the messages of an incomplete batch.
+func (store *sqlStore) Flush() error {
+	store.batchLock.Lock()
+	defer store.batchLock.Unlock()
+
+	if err := store.flushLocked(); err != nil {
+		return err
+	}
+
+	return store.writeErr.Err()
+}
+
+//Compact deletes the messages with seqnums below seqNum.
+func (store *sqlStore) Compact(seqNum int) error {
+	if err := store.Flush(); err != nil {
+		return err
+	}
+
+	_, err := store.db.Exec(store.dialect.rebind(`DELETE FROM messages WHERE `+sqlSessionKeyClause+` AND msgseqnum < ?`), append(store.sessionKey(), seqNum)...)
+	return err
+}
+
+//GetMessages returns the messages saved with seqnums from beginSeqNum to endSeqNum, in order.
+func (store *sqlStore) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
+	msgs := make(chan []byte)
+
+	go func() {
+		defer close(msgs)
+
+		if err := store.Flush(); err != nil {
+			return
+		}
+
+		args := append(store.sessionKey(), beginSeqNum, endSeqNum)
+		rows, err := store.db.Query(store.dialect.rebind(`SELECT message FROM messages WHERE `+sqlSessionKeyClause+`
+			AND msgseqnum >= ? AND msgseqnum <= ? ORDER BY msgseqnum`), args...)
+		if err != nil {
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var msg []byte
+			if err := rows.Scan(&msg); err != nil {
+				return
+			}
+
+			msgs <- msg
+		}
+	}()
+
+	return msgs
+}
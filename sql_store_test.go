@@ -0,0 +1,64 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLDialect_Rebind(t *testing.T) {
+	query := `SELECT message FROM messages WHERE beginstring = ? AND msgseqnum >= ?`
+
+	if rebound := dialectFor("mysql").rebind(query); rebound != query {
+		t.Errorf("Expected mysql query unchanged got %v", rebound)
+	}
+
+	expected := `SELECT message FROM messages WHERE beginstring = $1 AND msgseqnum >= $2`
+	if rebound := dialectFor("postgres").rebind(query); rebound != expected {
+		t.Errorf("Expected %v got %v", expected, rebound)
+	}
+}
+
+func TestSQLStore_NewSQLStoreFactory(t *testing.T) {
+	cfg := `
+[DEFAULT]
+SenderCompID=TW
+SQLStoreDriver=postgres
+
+[SESSION]
+BeginString=FIX.4.1
+TargetCompID=ARCA
+`
+	settings, _ := ParseSettings(strings.NewReader(cfg))
+	if _, err := NewSQLStoreFactory(settings); err == nil {
+		t.Error("Should expect error when settings have no data source name")
+	}
+
+	settings.GlobalSettings().Set(config.SQLStoreDataSourceName, "postgres://localhost/quickfix")
+	if _, err := NewSQLStoreFactory(settings); err != nil {
+		t.Error("Did not expect error", err)
+	}
+}
+
+func TestSQLStore_PoolSettings(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set(config.SQLStoreMaxOpenConns, "10")
+	settings.Set(config.SQLStoreMaxIdleConns, "2")
+	settings.Set(config.SQLStoreConnMaxLifetime, "300")
+
+	pool, err := sqlPoolSettingsFor(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := sqlPoolSettings{maxOpenConns: 10, maxIdleConns: 2, connMaxLifetime: 5 * time.Minute}
+	if pool != expected {
+		t.Errorf("Expected %+v got %+v", expected, pool)
+	}
+
+	settings.Set(config.SQLStoreConnMaxLifetime, "forever")
+	if _, err := sqlPoolSettingsFor(settings); err == nil {
+		t.Error("Expected error for invalid SQLStoreConnMaxLifetime")
+	}
+}
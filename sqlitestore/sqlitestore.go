@@ -0,0 +1,101 @@
+//Package sqlitestore registers the QuickFIX/Go SQL store over a SQLite database file, with the cgo free modernc.org/sqlite driver.
+//The database is opened in WAL mode, readers such as resends do not block the session saving messages.
+package sqlitestore
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"os"
+	"path/filepath"
+	"time"
+
+	//registers the "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+//StoreType is the MessageStoreType of the store, registered with quickfix.RegisterStoreFactory when the package is imported.
+const StoreType = "sqlite"
+
+//driver is the database/sql driver of modernc.org/sqlite
+const driver = "sqlite"
+
+func init() {
+	quickfix.RegisterStoreFactory(StoreType, NewStoreFactory)
+}
+
+const (
+	defaultSynchronous = "FULL"
+	defaultBusyTimeout = 5 * time.Second
+)
+
+//connectionSettings configure the connections of a session to its database file.
+type connectionSettings struct {
+	path        string
+	synchronous string
+	busyTimeout time.Duration
+}
+
+//NewStoreFactory returns the MessageStoreFactory of quickfix.NewSQLStoreFactory, persisting sessions to the SQLite database file SQLiteStorePath.
+//Sessions may share a file, and its connections, waiting up to SQLiteStoreBusyTimeout milliseconds for each other to write.
+//SQLiteStoreSynchronous is FULL by default, syncing each write, or NORMAL, syncing at WAL checkpoints and risking the last writes on power loss.
+//The SQLStore settings other than the driver and data source, such as SQLStoreBatchSize, apply as to the SQL store.
+func NewStoreFactory(settings *quickfix.Settings) (quickfix.MessageStoreFactory, error) {
+	sqlSettings := quickfix.NewSettings()
+	for _, sessionSettings := range settings.SessionSettings() {
+		conn, err := connectionSettingsFor(sessionSettings)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(conn.path), os.ModePerm); err != nil {
+			return nil, err
+		}
+
+		//the session settings are merged with those of their profile, which is added empty
+		if profile, err := sessionSettings.Setting(config.Profile); err == nil {
+			if _, ok := sqlSettings.Profile(profile); !ok {
+				sqlSettings.AddProfile(profile, quickfix.NewSessionSettings())
+			}
+		}
+
+		sessionSettings.Set(config.SQLStoreDriver, driver)
+		sessionSettings.Set(config.SQLStoreDataSourceName, conn.dataSourceName())
+		if _, err := sqlSettings.AddSession(sessionSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	return quickfix.NewSQLStoreFactory(sqlSettings)
+}
+
+//connectionSettingsFor parses SQLiteStorePath, SQLiteStoreSynchronous, and SQLiteStoreBusyTimeout.
+func connectionSettingsFor(settings *quickfix.SessionSettings) (conn connectionSettings, err error) {
+	if conn.path, err = settings.Setting(config.SQLiteStorePath); err != nil {
+		return conn, fmt.Errorf("missing configuration: %v", config.SQLiteStorePath)
+	}
+
+	conn.synchronous, conn.busyTimeout = defaultSynchronous, defaultBusyTimeout
+	if settings.HasSetting(config.SQLiteStoreSynchronous) {
+		conn.synchronous, _ = settings.Setting(config.SQLiteStoreSynchronous)
+		if conn.synchronous != "FULL" && conn.synchronous != "NORMAL" {
+			return conn, fmt.Errorf("invalid SQLiteStoreSynchronous %v, expected FULL or NORMAL", conn.synchronous)
+		}
+	}
+
+	if settings.HasSetting(config.SQLiteStoreBusyTimeout) {
+		var millis int
+		if millis, err = settings.IntSetting(config.SQLiteStoreBusyTimeout); err != nil {
+			return
+		}
+		conn.busyTimeout = time.Duration(millis) * time.Millisecond
+	}
+
+	return
+}
+
+//dataSourceName returns the data source of the database file of conn, the pragmas applied by the driver to each connection it opens.
+func (conn connectionSettings) dataSourceName() string {
+	return fmt.Sprintf("%v?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=synchronous(%v)",
+		conn.path, conn.busyTimeout/time.Millisecond, conn.synchronous)
+}
@@ -0,0 +1,375 @@
+package sqlitestore
+
+import (
+	"database/sql"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/storetest"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func collectMessages(s quickfix.MessageStore, beginSeqNum, endSeqNum int) (msgs []string) {
+	for msg := range s.GetMessages(beginSeqNum, endSeqNum) {
+		msgs = append(msgs, string(msg))
+	}
+
+	return
+}
+
+func newTestStoreFactory(t *testing.T, dirname string) quickfix.MessageStoreFactory {
+	cfg := `
+[DEFAULT]
+SenderCompID=TW
+SQLiteStorePath=` + filepath.Join(dirname, "quickfix.db") + `
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=ISLD
+
+[PROFILE normal]
+SQLiteStoreSynchronous=NORMAL
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=ARCA
+Profile=normal
+`
+	settings, _ := quickfix.ParseSettings(strings.NewReader(cfg))
+	factory, err := NewStoreFactory(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return factory
+}
+
+func TestConnectionSettingsFor(t *testing.T) {
+	settings := quickfix.NewSessionSettings()
+	if _, err := connectionSettingsFor(settings); err == nil {
+		t.Error("Expected error without SQLiteStorePath")
+	}
+
+	settings.Set(config.SQLiteStorePath, "quickfix.db")
+	settings.Set(config.SQLiteStoreSynchronous, "OFF")
+	if _, err := connectionSettingsFor(settings); err == nil {
+		t.Error("Expected error for SQLiteStoreSynchronous OFF")
+	}
+
+	settings.Set(config.SQLiteStoreSynchronous, "NORMAL")
+	settings.Set(config.SQLiteStoreBusyTimeout, "250")
+	conn, err := connectionSettingsFor(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conn.synchronous != "NORMAL" || conn.busyTimeout.Nanoseconds() != 250e6 {
+		t.Errorf("Unexpected settings %+v", conn)
+	}
+
+	if dataSourceName := conn.dataSourceName(); dataSourceName != "quickfix.db?_pragma=busy_timeout(250)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)" {
+		t.Errorf("Unexpected data source %v", dataSourceName)
+	}
+}
+
+func TestStore_Persistence(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "sqlitestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	factory := newTestStoreFactory(t, dirname)
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+	sessionStore, err := factory.Create(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//sessions sharing the file have a connection and rows of their own
+	other, err := factory.Create(quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ARCA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.SaveMessage(2, []byte("other"))
+
+	sessionStore.SetNextSenderMsgSeqNum(4)
+	sessionStore.IncrNextTargetMsgSeqNum()
+	sessionStore.(quickfix.RecoveryStore).SaveRecoveryState(quickfix.RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2})
+	for seqNum, msg := range []string{"hello", "cruel", "world"} {
+		if err := sessionStore.(quickfix.ErrorSavingMessageStore).TrySaveMessage(seqNum+1, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open(driver, filepath.Join(dirname, "quickfix.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var journalMode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil || strings.ToLower(journalMode) != "wal" {
+		t.Errorf("Expected WAL journal mode, got %v %v", journalMode, err)
+	}
+
+	restarted, err := newTestStoreFactory(t, dirname).Create(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restarted.NextSenderMsgSeqNum() != 4 || restarted.NextTargetMsgSeqNum() != 2 {
+		t.Errorf("Expected seqnums 4 and 2 got %v and %v", restarted.NextSenderMsgSeqNum(), restarted.NextTargetMsgSeqNum())
+	}
+
+	if state, _ := restarted.(quickfix.RecoveryStore).RecoveryState(); state != (quickfix.RecoveryState{LoggedOn: true, ResendBeginSeqNo: 2}) {
+		t.Errorf("Unexpected recovery state %+v", state)
+	}
+
+	if !restarted.CreationTime().Equal(sessionStore.CreationTime()) {
+		t.Errorf("Expected creation time %v got %v", sessionStore.CreationTime(), restarted.CreationTime())
+	}
+
+	if msgs := collectMessages(restarted, 2, 5); len(msgs) != 2 || msgs[0] != "cruel" || msgs[1] != "world" {
+		t.Errorf("Unexpected messages %v", msgs)
+	}
+
+	if err := restarted.(quickfix.CompactableStore).Compact(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if msgs := collectMessages(restarted, 1, 5); len(msgs) != 1 || msgs[0] != "world" {
+		t.Errorf("Expected messages before 3 compacted, got %v", msgs)
+	}
+
+	restarted.Reset()
+	if restarted.NextSenderMsgSeqNum() != 1 || len(collectMessages(restarted, 1, 5)) != 0 {
+		t.Error("Expected empty store after reset")
+	}
+
+	if msgs := collectMessages(other, 1, 5); len(msgs) != 1 || msgs[0] != "other" {
+		t.Errorf("Expected messages of other session kept, got %v", msgs)
+	}
+
+	if err := restarted.(quickfix.HealthStore).Health(); err != nil {
+		t.Error("Did not expect error", err)
+	}
+}
+
+func TestStore_Conformance(t *testing.T) {
+	var dirnames []string
+	defer func() {
+		for _, dirname := range dirnames {
+			os.RemoveAll(dirname)
+		}
+	}()
+
+	storetest.Run(t, storetest.Harness{
+		NewBackend: func(t *testing.T) func([]quickfix.SessionID) quickfix.MessageStoreFactory {
+			dirname, err := ioutil.TempDir("", "sqlitestore")
+			if err != nil {
+				t.Fatal(err)
+			}
+			dirnames = append(dirnames, dirname)
+
+			return func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory {
+				factory, err := NewStoreFactory(storetest.Settings(sessionIDs, map[string]string{config.SQLiteStorePath: filepath.Join(dirname, "quickfix.db")}))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return factory
+			}
+		},
+		Persistent: true,
+	})
+}
+
+//newBatchingStoreFactory returns a factory of stores of sessionID over the database file of dirname, with SQLStoreBatchSize batchSize,
+//and the database opened for the test to read.
+func newBatchingStoreFactory(t *testing.T, dirname string, sessionID quickfix.SessionID, batchSize string) (quickfix.MessageStoreFactory, *sql.DB) {
+	path := filepath.Join(dirname, "quickfix.db")
+	factory, err := NewStoreFactory(storetest.Settings([]quickfix.SessionID{sessionID}, map[string]string{
+		config.SQLiteStorePath:   path,
+		config.SQLStoreBatchSize: batchSize,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open(driver, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return factory, db
+}
+
+//storedSeqNums returns the seqnums of the rows of the messages table, in order.
+func storedSeqNums(t *testing.T, db *sql.DB) []int {
+	rows, err := db.Query(`SELECT msgseqnum FROM messages ORDER BY msgseqnum`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var seqNums []int
+	for rows.Next() {
+		var seqNum int
+		if err := rows.Scan(&seqNum); err != nil {
+			t.Fatal(err)
+		}
+		seqNums = append(seqNums, seqNum)
+	}
+
+	return seqNums
+}
+
+func expectStoredSeqNums(t *testing.T, db *sql.DB, expected ...int) {
+	t.Helper()
+
+	stored := storedSeqNums(t, db)
+	if len(stored) != len(expected) {
+		t.Fatalf("Expected messages %v stored, got %v", expected, stored)
+	}
+
+	for i := range expected {
+		if stored[i] != expected[i] {
+			t.Errorf("Expected messages %v stored, got %v", expected, stored)
+		}
+	}
+}
+
+func TestStore_Batching(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+	dirname, err := ioutil.TempDir("", "sqlitestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	factory, db := newBatchingStoreFactory(t, dirname, sessionID, "3")
+	defer db.Close()
+
+	s, err := factory.Create(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := s.(quickfix.ErrorSavingMessageStore)
+
+	//messages are inserted once a batch is saved, a message saved again replacing the first within the batch
+	for _, seqNum := range []int{1, 2} {
+		if err := store.TrySaveMessage(seqNum, []byte("first")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectStoredSeqNums(t, db)
+
+	if err := store.TrySaveMessage(2, []byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	expectStoredSeqNums(t, db, 1, 2)
+
+	var msg string
+	if err := db.QueryRow(`SELECT message FROM messages WHERE msgseqnum = 2`).Scan(&msg); err != nil || msg != "second" {
+		t.Errorf("Expected the message saved last, got %v %v", msg, err)
+	}
+
+	//an incomplete batch is inserted by Flush, and before messages are read for resend
+	if err := store.TrySaveMessage(3, []byte("third")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.(interface{ Flush() error }).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	expectStoredSeqNums(t, db, 1, 2, 3)
+
+	if err := store.TrySaveMessage(4, []byte("fourth")); err != nil {
+		t.Fatal(err)
+	}
+
+	if resent := collectMessages(s, 3, 4); len(resent) != 2 || resent[0] != "third" || resent[1] != "fourth" {
+		t.Errorf("Expected the pending message read for resend, got %v", resent)
+	}
+
+	//Reset drops the pending messages with those stored
+	if err := store.TrySaveMessage(5, []byte("fifth")); err != nil {
+		t.Fatal(err)
+	}
+	s.Reset()
+	if err := s.(interface{ Flush() error }).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	expectStoredSeqNums(t, db)
+}
+
+func TestStore_BatchedMessagesLostOnRestart(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+	dirname, err := ioutil.TempDir("", "sqlitestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	factory, db := newBatchingStoreFactory(t, dirname, sessionID, "10")
+	defer db.Close()
+
+	s, err := factory.Create(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.(quickfix.ErrorSavingMessageStore).TrySaveMessage(1, []byte("pending")); err != nil {
+		t.Fatal(err)
+	}
+	s.IncrNextSenderMsgSeqNum()
+
+	//the process restarts without flushing, the seqnum was persisted ahead of the message of the incomplete batch
+	restarted, err := factory.Create(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next := restarted.NextSenderMsgSeqNum(); next != 2 {
+		t.Errorf("Expected next sender seqnum 2, got %v", next)
+	}
+
+	if msgs := collectMessages(restarted, 1, 1); len(msgs) != 0 {
+		t.Errorf("Expected the message of the batch not stored, got %q", msgs)
+	}
+}
+
+func TestStore_Migration(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+	dirname, err := ioutil.TempDir("", "sqlitestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	factory, db := newBatchingStoreFactory(t, dirname, sessionID, "1")
+	defer db.Close()
+
+	if _, err := factory.Create(sessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT version FROM quickfix_schema`).Scan(&version); err != nil || version < 1 {
+		t.Errorf("Expected a schema version, got %v %v", version, err)
+	}
+
+	//migrating a database at the latest version is a no-op, the tables are not created again
+	restarted, otherDB := newBatchingStoreFactory(t, dirname, sessionID, "1")
+	defer otherDB.Close()
+	if _, err := restarted.Create(sessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	var versions, latest int
+	if err := db.QueryRow(`SELECT COUNT(*), MAX(version) FROM quickfix_schema`).Scan(&versions, &latest); err != nil || versions != 1 || latest != version {
+		t.Errorf("Expected the single schema version %v, got %v versions to %v %v", version, versions, latest, err)
+	}
+}
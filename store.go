@@ -1,8 +1,12 @@
 package quickfix
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 //The MessageStore interface provides methods to record and retrieve messages for resend purposes
+//The sequence numbers may be read outside the session goroutine, for example by Session.Info.
 type MessageStore interface {
 	NextSenderMsgSeqNum() int
 	NextTargetMsgSeqNum() int
@@ -22,65 +26,211 @@ type MessageStore interface {
 	Reset()
 }
 
+//ErrorSavingMessageStore may be implemented by a MessageStore to report the failure to save a message for resend.
+//The session saves each message sent with TrySaveMessage, a message that is not saved is not sent.
+type ErrorSavingMessageStore interface {
+	TrySaveMessage(seqNum int, msg []byte) error
+}
+
+//saveMessage saves msg to store, returning the failure to save it if store is an ErrorSavingMessageStore.
+func saveMessage(store MessageStore, seqNum int, msg []byte) error {
+	if errorSaving, ok := store.(ErrorSavingMessageStore); ok {
+		return errorSaving.TrySaveMessage(seqNum, msg)
+	}
+
+	store.SaveMessage(seqNum, msg)
+	return nil
+}
+
+//ErrorLatch keeps the first error of the writes of a MessageStore, such as the writes of the sequence numbers that the MessageStore
+//interface does not report, until cleared. A store failing TrySaveMessage and Health with the error kept does not send messages
+//it may not have persisted. Safe for concurrent use, the zero value keeps no error.
+type ErrorLatch struct {
+	lock sync.Mutex
+	err  error
+}
+
+//Set keeps err, unless nil or an error is already kept.
+func (l *ErrorLatch) Set(err error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+//Err returns the error kept, nil if none.
+func (l *ErrorLatch) Err() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.err
+}
+
+//Clear discards the error kept.
+func (l *ErrorLatch) Clear() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.err = nil
+}
+
+//FlushableStore may be implemented by a MessageStore that buffers writes, it is flushed each time the session disconnects.
+type FlushableStore interface {
+	Flush() error
+}
+
 //The MessageStoreFactory interface is used by session to create a session specific message store
 type MessageStoreFactory interface {
 	Create(sessionID SessionID) (MessageStore, error)
 }
 
 type memoryStore struct {
+	//seqNumLock guards the sequence numbers, creation time and RecoveryState read outside the session goroutine
+	seqNumLock                       sync.RWMutex
 	senderMsgSeqNum, targetMsgSeqNum int
 	creationTime                     time.Time
-	messageMap                       map[int][]byte
+	recoveryState                    RecoveryState
+
+	//messageLock guards the messages and dead letters, read by GetMessages and DeadLetters and compacted outside the session goroutine
+	messageLock sync.RWMutex
+	messageMap  map[int][]byte
+	receivedMap map[int][]byte
+	deadLetters []DeadLetter
 }
 
-func (store memoryStore) NextSenderMsgSeqNum() int {
+func (store *memoryStore) NextSenderMsgSeqNum() int {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
 	return store.senderMsgSeqNum + 1
 }
 
-func (store memoryStore) NextTargetMsgSeqNum() int {
+func (store *memoryStore) NextTargetMsgSeqNum() int {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
 	return store.targetMsgSeqNum + 1
 }
 
 func (store *memoryStore) IncrNextSenderMsgSeqNum() {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
 	store.senderMsgSeqNum++
 }
 
 func (store *memoryStore) IncrNextTargetMsgSeqNum() {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
 	store.targetMsgSeqNum++
 }
 
 func (store *memoryStore) SetNextSenderMsgSeqNum(nextSeqNum int) {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
 	store.senderMsgSeqNum = nextSeqNum - 1
 }
 func (store *memoryStore) SetNextTargetMsgSeqNum(nextSeqNum int) {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
 	store.targetMsgSeqNum = nextSeqNum - 1
 }
 
-func (store memoryStore) CreationTime() time.Time {
+func (store *memoryStore) CreationTime() time.Time {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
 	return store.creationTime
 }
 
 func (store *memoryStore) Reset() {
+	store.seqNumLock.Lock()
 	store.senderMsgSeqNum = 0
 	store.targetMsgSeqNum = 0
 	store.creationTime = time.Now()
+	store.recoveryState.ResendBeginSeqNo, store.recoveryState.ResendEndSeqNo = 0, 0
+	store.seqNumLock.Unlock()
+
+	store.messageLock.Lock()
 	store.messageMap = make(map[int][]byte)
+	store.receivedMap = make(map[int][]byte)
+	store.messageLock.Unlock()
+}
+
+func (store *memoryStore) SaveRecoveryState(state RecoveryState) error {
+	store.seqNumLock.Lock()
+	defer store.seqNumLock.Unlock()
+	store.recoveryState = state
+	return nil
+}
+
+func (store *memoryStore) RecoveryState() (RecoveryState, error) {
+	store.seqNumLock.RLock()
+	defer store.seqNumLock.RUnlock()
+	return store.recoveryState, nil
+}
+
+func (store *memoryStore) SaveDeadLetter(deadLetter DeadLetter) error {
+	store.messageLock.Lock()
+	defer store.messageLock.Unlock()
+	store.deadLetters = append(store.deadLetters, deadLetter)
+	return nil
+}
+
+func (store *memoryStore) DeadLetters() ([]DeadLetter, error) {
+	store.messageLock.RLock()
+	defer store.messageLock.RUnlock()
+	return append([]DeadLetter(nil), store.deadLetters...), nil
 }
 
 func (store *memoryStore) Refresh() {
 	//nop, nothing to refresh
 }
 
-func (store memoryStore) SaveMessage(seqNum int, msg []byte) {
+func (store *memoryStore) SaveMessage(seqNum int, msg []byte) {
+	store.messageLock.Lock()
+	defer store.messageLock.Unlock()
 	store.messageMap[seqNum] = msg
 }
 
-func (store memoryStore) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
+func (store *memoryStore) SaveReceivedMessage(seqNum int, msg []byte) error {
+	store.messageLock.Lock()
+	defer store.messageLock.Unlock()
+	store.receivedMap[seqNum] = msg
+	return nil
+}
+
+func (store *memoryStore) Compact(seqNum int) error {
+	store.messageLock.Lock()
+	defer store.messageLock.Unlock()
+
+	for saved := range store.messageMap {
+		if saved < seqNum {
+			delete(store.messageMap, saved)
+		}
+	}
+
+	return nil
+}
+
+func (store *memoryStore) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
+	return store.readMessages(beginSeqNum, endSeqNum, false)
+}
+
+func (store *memoryStore) GetReceivedMessages(beginSeqNum, endSeqNum int) chan []byte {
+	return store.readMessages(beginSeqNum, endSeqNum, true)
+}
+
+func (store *memoryStore) readMessages(beginSeqNum, endSeqNum int, received bool) chan []byte {
 	msgs := make(chan []byte)
 
 	go func() {
 		for seqNum := beginSeqNum; seqNum <= endSeqNum; seqNum++ {
-			if msg, ok := store.messageMap[seqNum]; ok {
+			store.messageLock.RLock()
+			messages := store.messageMap
+			if received {
+				messages = store.receivedMap
+			}
+			msg, ok := messages[seqNum]
+			store.messageLock.RUnlock()
+
+			if ok {
 				msgs <- msg
 			}
 		}
@@ -93,7 +243,7 @@ func (store memoryStore) GetMessages(beginSeqNum, endSeqNum int) chan []byte {
 type memoryStoreFactory struct{}
 
 func (f memoryStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
-	return &memoryStore{messageMap: make(map[int][]byte)}, nil
+	return &memoryStore{messageMap: make(map[int][]byte), receivedMap: make(map[int][]byte), creationTime: time.Now()}, nil
 }
 
 //NewMemoryStoreFactory returns a MessageStoreFactory instance that created in-memory MessageStores
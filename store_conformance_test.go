@@ -0,0 +1,60 @@
+package quickfix_test
+
+import (
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/storetest"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMemoryStore_Conformance(t *testing.T) {
+	storetest.Run(t, storetest.Harness{
+		NewBackend: func(t *testing.T) func([]quickfix.SessionID) quickfix.MessageStoreFactory {
+			return func([]quickfix.SessionID) quickfix.MessageStoreFactory { return quickfix.NewMemoryStoreFactory() }
+		},
+	})
+}
+
+func TestFileStore_Conformance(t *testing.T) {
+	var dirs []string
+	defer func() {
+		for _, dir := range dirs {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	storetest.Run(t, storetest.Harness{
+		NewBackend: func(t *testing.T) func([]quickfix.SessionID) quickfix.MessageStoreFactory {
+			dir, err := ioutil.TempDir("", "filestore")
+			if err != nil {
+				t.Fatal(err)
+			}
+			dirs = append(dirs, dir)
+
+			return func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory {
+				factory, err := quickfix.NewFileStoreFactory(storetest.Settings(sessionIDs, map[string]string{config.FileStorePath: dir}))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return factory
+			}
+		},
+		Persistent: true,
+	})
+}
+
+func TestAsyncStore_Conformance(t *testing.T) {
+	storetest.Run(t, storetest.Harness{
+		NewBackend: func(t *testing.T) func([]quickfix.SessionID) quickfix.MessageStoreFactory {
+			return func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory {
+				factory, err := quickfix.NewAsyncStoreFactory(quickfix.NewMemoryStoreFactory(), storetest.Settings(sessionIDs, nil))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return factory
+			}
+		},
+	})
+}
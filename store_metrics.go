@@ -0,0 +1,153 @@
+package quickfix
+
+import (
+	"sync"
+	"time"
+)
+
+//storeHealthTimeout limits the checks of the connection of a store by Health.
+const storeHealthTimeout = 5 * time.Second
+
+//HealthStore may be implemented by a MessageStore to report whether it is able to store messages, e.g. by checking its connection.
+//Health may be called outside the session goroutine.
+type HealthStore interface {
+	//Health returns nil if the store is healthy, otherwise the reason it is not.
+	Health() error
+}
+
+//QueuedStore may be implemented by a MessageStore acknowledging saves before writing them.
+type QueuedStore interface {
+	//QueueDepth returns the number of messages saved but not yet written.
+	QueueDepth() int
+}
+
+//StoreLatencyBounds are the upper bounds of the buckets of StoreMetrics.SaveLatency.
+var StoreLatencyBounds = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+//StoreMetrics are the operation metrics of the MessageStore of a session, since the session was created.
+type StoreMetrics struct {
+	//Saves is the number of messages saved, SaveErrors the number of saves failing.
+	Saves, SaveErrors int
+
+	//SaveLatency is a histogram of save durations, SaveLatency[i] counts the saves taking at most StoreLatencyBounds[i], the last element those taking longer.
+	SaveLatency []int
+
+	//SaveTime is the total duration of saves.
+	SaveTime time.Duration
+
+	//QueueDepth is the number of messages saved but not yet written, for a QueuedStore.
+	QueueDepth int
+
+	//LastError is the last error of a store operation, LastErrorTime when it occurred.
+	LastError     error
+	LastErrorTime time.Time
+
+	//Health is the error returned by a HealthStore, nil if healthy or the store does not implement HealthStore.
+	Health error
+}
+
+//storeMetrics records the operations of the store of a session.
+type storeMetrics struct {
+	lock          sync.Mutex
+	saves, errors int
+	latency       []int
+	saveTime      time.Duration
+	lastErr       error
+	lastErrTime   time.Time
+}
+
+//recordSave records a save taking elapsed, failing with err.
+func (m *storeMetrics) recordSave(elapsed time.Duration, err error, now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.latency == nil {
+		m.latency = make([]int, len(StoreLatencyBounds)+1)
+	}
+
+	bucket := len(StoreLatencyBounds)
+	for i, bound := range StoreLatencyBounds {
+		if elapsed <= bound {
+			bucket = i
+			break
+		}
+	}
+
+	m.saves++
+	m.latency[bucket]++
+	m.saveTime += elapsed
+
+	if err != nil {
+		m.errors++
+		m.lastErr, m.lastErrTime = err, now
+	}
+}
+
+//recordError records the failure of a store operation other than a save.
+func (m *storeMetrics) recordError(err error, now time.Time) {
+	if err == nil {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.lastErr, m.lastErrTime = err, now
+}
+
+//saveMessage saves msgBytes to the store, recording the save in the store metrics.
+func (s *Session) saveMessage(seqNum int, msgBytes []byte) error {
+	start := time.Now()
+	err := saveMessage(s.store, seqNum, msgBytes)
+	s.storeMetrics.recordSave(time.Since(start), err, s.now())
+	if err != nil {
+		s.onAnomaly(AnomalyStoreWriteFailure, 1, err)
+	}
+
+	return err
+}
+
+//StoreMetrics returns the operation metrics of the store of the session, checking its health if a HealthStore.
+//Safe to call outside the session goroutine.
+func (s *Session) StoreMetrics() StoreMetrics {
+	s.storeMetrics.lock.Lock()
+	metrics := StoreMetrics{
+		Saves:         s.storeMetrics.saves,
+		SaveErrors:    s.storeMetrics.errors,
+		SaveLatency:   make([]int, len(StoreLatencyBounds)+1),
+		SaveTime:      s.storeMetrics.saveTime,
+		LastError:     s.storeMetrics.lastErr,
+		LastErrorTime: s.storeMetrics.lastErrTime,
+	}
+	copy(metrics.SaveLatency, s.storeMetrics.latency)
+	s.storeMetrics.lock.Unlock()
+
+	if store, ok := s.store.(QueuedStore); ok {
+		metrics.QueueDepth = store.QueueDepth()
+	}
+
+	if store, ok := s.store.(HealthStore); ok {
+		metrics.Health = store.Health()
+	}
+
+	return metrics
+}
+
+//LookupStoreMetrics returns the operation metrics of the store of the session with sessionID.
+func LookupStoreMetrics(sessionID SessionID) (StoreMetrics, error) {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return StoreMetrics{}, err
+	}
+
+	return session.StoreMetrics(), nil
+}
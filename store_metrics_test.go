@@ -0,0 +1,84 @@
+package quickfix
+
+import (
+	"errors"
+	"testing"
+)
+
+//unhealthyStore is a memoryStore failing saves and health checks with err.
+type unhealthyStore struct {
+	*memoryStore
+	err error
+}
+
+func (s *unhealthyStore) TrySaveMessage(seqNum int, msg []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.memoryStore.SaveMessage(seqNum, msg)
+	return nil
+}
+
+func (s *unhealthyStore) Health() error {
+	return s.err
+}
+
+func TestSession_StoreMetrics(t *testing.T) {
+	memory, _ := NewMemoryStoreFactory().Create(SessionID{})
+	store := &unhealthyStore{memoryStore: memory.(*memoryStore)}
+	session := &Session{store: store}
+
+	if err := session.saveMessage(1, []byte("msg")); err != nil {
+		t.Fatal(err)
+	}
+
+	store.err = errors.New("disk full")
+	if err := session.saveMessage(2, []byte("msg")); err != store.err {
+		t.Fatalf("Expected %v, got %v", store.err, err)
+	}
+
+	metrics := session.StoreMetrics()
+	if metrics.Saves != 2 || metrics.SaveErrors != 1 {
+		t.Errorf("Expected 2 saves, 1 error, got %+v", metrics)
+	}
+
+	if metrics.LastError != store.err || metrics.LastErrorTime.IsZero() || metrics.Health != store.err {
+		t.Errorf("Expected last error and health %v, got %+v", store.err, metrics)
+	}
+
+	saves := 0
+	for _, count := range metrics.SaveLatency {
+		saves += count
+	}
+
+	if len(metrics.SaveLatency) != len(StoreLatencyBounds)+1 || saves != 2 {
+		t.Errorf("Expected 2 saves in latency histogram, got %v", metrics.SaveLatency)
+	}
+
+	if info := session.Info(); info.StoreHealth != store.err {
+		t.Errorf("Expected store health in session info, got %v", info.StoreHealth)
+	}
+}
+
+func TestAsyncStore_Metrics(t *testing.T) {
+	underlying := newBlockingStore()
+	store := newAsyncStore(underlying, 10, 1, 0)
+	session := &Session{store: store}
+
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		session.saveMessage(seqNum, []byte("msg"))
+	}
+
+	if metrics := session.StoreMetrics(); metrics.QueueDepth < 2 || metrics.Health != nil {
+		t.Errorf("Expected queued writes, got %+v", metrics)
+	}
+
+	underlying.saveErr = errors.New("disk full")
+	close(underlying.release)
+	store.Flush()
+
+	if metrics := session.StoreMetrics(); metrics.QueueDepth != 0 || metrics.Health == nil {
+		t.Errorf("Expected write error reported by health, got %+v", metrics)
+	}
+}
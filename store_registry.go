@@ -0,0 +1,145 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"sort"
+	"sync"
+)
+
+//defaultMessageStoreType is the MessageStoreType of sessions without the setting.
+const defaultMessageStoreType = "memory"
+
+//StoreFactoryConstructor creates the MessageStoreFactory of a store backend for settings.
+type StoreFactoryConstructor func(settings *Settings) (MessageStoreFactory, error)
+
+var storeFactories = struct {
+	sync.RWMutex
+	constructors map[string]StoreFactoryConstructor
+}{
+	constructors: map[string]StoreFactoryConstructor{
+		"memory": func(*Settings) (MessageStoreFactory, error) { return NewMemoryStoreFactory(), nil },
+		"file":   NewFileStoreFactory,
+		"sql":    NewSQLStoreFactory,
+	},
+}
+
+//RegisterStoreFactory makes a store backend available as MessageStoreType name to NewStoreFactoryFromSettings.
+//Packages providing a store typically register it in an init function. Panics if name is already registered.
+func RegisterStoreFactory(name string, constructor StoreFactoryConstructor) {
+	storeFactories.Lock()
+	defer storeFactories.Unlock()
+
+	if _, dup := storeFactories.constructors[name]; dup {
+		panic(fmt.Sprintf("quickfix: store factory %v registered twice", name))
+	}
+
+	storeFactories.constructors[name] = constructor
+}
+
+//StoreFactories returns the names of the registered store backends, sorted.
+func StoreFactories() []string {
+	storeFactories.RLock()
+	defer storeFactories.RUnlock()
+
+	var names []string
+	for name := range storeFactories.constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+//settingsStoreFactory creates the store of each session with the backend named by its MessageStoreType.
+type settingsStoreFactory struct {
+	settings *Settings
+
+	lock      sync.Mutex
+	factories map[string]MessageStoreFactory
+
+	//views are the settings passed to the backend of each MessageStoreType, holding only the sessions of the backend
+	views map[string]*Settings
+}
+
+//NewStoreFactoryFromSettings returns a MessageStoreFactory creating the store of each session with the backend registered as its MessageStoreType.
+//Sessions without MessageStoreType use the memory store. The backends file and sql are registered by this package.
+func NewStoreFactoryFromSettings(settings *Settings) (MessageStoreFactory, error) {
+	f := &settingsStoreFactory{settings: settings, factories: make(map[string]MessageStoreFactory), views: make(map[string]*Settings)}
+
+	for sessionID, sessionSettings := range settings.SessionSettings() {
+		f.addToView(storeTypeOf(sessionSettings), sessionID)
+	}
+
+	for storeType := range f.views {
+		if _, err := f.factoryFor(storeType); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func storeTypeOf(settings *SessionSettings) string {
+	if storeType, err := settings.Setting(config.MessageStoreType); err == nil {
+		return storeType
+	}
+
+	return defaultMessageStoreType
+}
+
+//addToView adds the settings of sessionID to the view of storeType, with lock held.
+func (f *settingsStoreFactory) addToView(storeType string, sessionID SessionID) {
+	view, ok := f.views[storeType]
+	if !ok {
+		view = &Settings{globalSettings: f.settings.GlobalSettings(), sessionSettings: make(map[SessionID]*SessionSettings)}
+		f.views[storeType] = view
+	}
+
+	view.sessionSettings[sessionID] = f.settings.sessionSettings[sessionID]
+}
+
+//factoryFor returns the MessageStoreFactory of storeType, constructing it on first use.
+func (f *settingsStoreFactory) factoryFor(storeType string) (MessageStoreFactory, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if factory, ok := f.factories[storeType]; ok {
+		return factory, nil
+	}
+
+	storeFactories.RLock()
+	constructor, ok := storeFactories.constructors[storeType]
+	storeFactories.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown MessageStoreType %v, expected one of %v", storeType, StoreFactories())
+	}
+
+	factory, err := constructor(f.views[storeType])
+	if err != nil {
+		return nil, err
+	}
+
+	f.factories[storeType] = factory
+	return factory, nil
+}
+
+//Create creates the store of sessionID, adding sessions created after the factory, for example from an acceptor template, to the view of their backend.
+func (f *settingsStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
+	if _, ok := f.settings.sessionSettings[sessionID]; !ok {
+		return nil, fmt.Errorf("store not defined for %v", sessionID)
+	}
+
+	storeType := storeTypeOf(f.settings.sessionSettingsFor(sessionID))
+	f.lock.Lock()
+	f.addToView(storeType, sessionID)
+	f.lock.Unlock()
+
+	factory, err := f.factoryFor(storeType)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.Create(sessionID)
+}
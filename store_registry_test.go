@@ -0,0 +1,81 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+//countingStoreFactory counts the stores it creates.
+type countingStoreFactory struct {
+	created int
+}
+
+func (f *countingStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
+	f.created++
+	return NewMemoryStoreFactory().Create(sessionID)
+}
+
+func TestRegisterStoreFactory(t *testing.T) {
+	custom := new(countingStoreFactory)
+	RegisterStoreFactory("counting", func(*Settings) (MessageStoreFactory, error) { return custom, nil })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic registering a name twice")
+		}
+	}()
+	RegisterStoreFactory("counting", func(*Settings) (MessageStoreFactory, error) { return custom, nil })
+}
+
+func TestNewStoreFactoryFromSettings(t *testing.T) {
+	dirname, err := ioutil.TempDir("", "filestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	cfg := `
+[DEFAULT]
+SenderCompID=TW
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=FILE
+MessageStoreType=file
+FileStorePath=` + dirname + `
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=MEMORY
+`
+	settings, _ := ParseSettings(strings.NewReader(cfg))
+	factory, err := NewStoreFactoryFromSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := factory.Create(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "FILE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.(*fileStore); !ok {
+		t.Errorf("Expected file store got %T", store)
+	}
+	store.(*fileStore).close()
+
+	store, err = factory.Create(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "MEMORY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.(*memoryStore); !ok {
+		t.Errorf("Expected memory store got %T", store)
+	}
+
+	settings.GlobalSettings().Set(config.MessageStoreType, "carrier-pigeon")
+	if _, err := NewStoreFactoryFromSettings(settings); err == nil {
+		t.Error("Expected error for unknown MessageStoreType")
+	}
+}
@@ -0,0 +1,108 @@
+package quickfix
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+//memoryStoreSnapshotVersion is the version of the snapshot format written by the memory store.
+const memoryStoreSnapshotVersion = 1
+
+//SnapshotStore may be implemented by a MessageStore to checkpoint its state, for example to seed a warm standby from a primary.
+type SnapshotStore interface {
+	//Snapshot returns the sequence numbers, creation time, RecoveryState, and messages of the store.
+	Snapshot() ([]byte, error)
+
+	//Restore replaces the state of the store with a snapshot returned by Snapshot.
+	Restore(snapshot []byte) error
+}
+
+//SnapshotSession returns a snapshot of the store of the session with sessionID.
+func SnapshotSession(sessionID SessionID) ([]byte, error) {
+	store, err := lookupSnapshotStore(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Snapshot()
+}
+
+//RestoreSession restores the store of the session with sessionID from snapshot.
+//The session should not be logged on, the counterparty is not informed of the change of sequence numbers.
+func RestoreSession(sessionID SessionID, snapshot []byte) error {
+	store, err := lookupSnapshotStore(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return store.Restore(snapshot)
+}
+
+func lookupSnapshotStore(sessionID SessionID) (SnapshotStore, error) {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	store, ok := session.store.(SnapshotStore)
+	if !ok {
+		return nil, fmt.Errorf("store of %v does not support snapshots", sessionID)
+	}
+
+	return store, nil
+}
+
+//memoryStoreSnapshot is the snapshot of a memory store, encoded as JSON.
+type memoryStoreSnapshot struct {
+	Version         int            `json:"version"`
+	SenderMsgSeqNum int            `json:"sender_msg_seq_num"`
+	TargetMsgSeqNum int            `json:"target_msg_seq_num"`
+	CreationTime    time.Time      `json:"creation_time"`
+	RecoveryState   RecoveryState  `json:"recovery_state"`
+	Messages        map[int][]byte `json:"messages"`
+}
+
+func (store *memoryStore) Snapshot() ([]byte, error) {
+	snapshot := memoryStoreSnapshot{Version: memoryStoreSnapshotVersion, CreationTime: store.creationTime, RecoveryState: store.recoveryState}
+
+	store.seqNumLock.RLock()
+	snapshot.SenderMsgSeqNum, snapshot.TargetMsgSeqNum = store.senderMsgSeqNum, store.targetMsgSeqNum
+	store.seqNumLock.RUnlock()
+
+	store.messageLock.RLock()
+	snapshot.Messages = make(map[int][]byte, len(store.messageMap))
+	for seqNum, msg := range store.messageMap {
+		snapshot.Messages[seqNum] = msg
+	}
+	store.messageLock.RUnlock()
+
+	return json.Marshal(snapshot)
+}
+
+func (store *memoryStore) Restore(data []byte) error {
+	var snapshot memoryStoreSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	if snapshot.Version != memoryStoreSnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %v, expected %v", snapshot.Version, memoryStoreSnapshotVersion)
+	}
+
+	if snapshot.Messages == nil {
+		snapshot.Messages = make(map[int][]byte)
+	}
+
+	store.seqNumLock.Lock()
+	store.senderMsgSeqNum, store.targetMsgSeqNum = snapshot.SenderMsgSeqNum, snapshot.TargetMsgSeqNum
+	store.seqNumLock.Unlock()
+
+	store.messageLock.Lock()
+	store.messageMap = snapshot.Messages
+	store.messageLock.Unlock()
+
+	store.creationTime = snapshot.CreationTime
+	store.recoveryState = snapshot.RecoveryState
+	return nil
+}
@@ -0,0 +1,54 @@
+package quickfix
+
+import (
+	"testing"
+)
+
+func TestMemoryStore_SnapshotRestore(t *testing.T) {
+	primary, _ := NewMemoryStoreFactory().Create(SessionID{})
+	primary.SetNextSenderMsgSeqNum(3)
+	primary.SetNextTargetMsgSeqNum(7)
+	primary.SaveMessage(1, []byte("hello"))
+	primary.SaveMessage(2, []byte("world"))
+	primary.(RecoveryStore).SaveRecoveryState(RecoveryState{LoggedOn: true})
+
+	snapshot, err := primary.(SnapshotStore).Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//changes after the checkpoint are discarded by restoring it
+	primary.SaveMessage(3, []byte("later"))
+	primary.IncrNextSenderMsgSeqNum()
+
+	standby, _ := NewMemoryStoreFactory().Create(SessionID{})
+	for _, store := range []MessageStore{primary, standby} {
+		if err := store.(SnapshotStore).Restore(snapshot); err != nil {
+			t.Fatal(err)
+		}
+
+		if store.NextSenderMsgSeqNum() != 3 || store.NextTargetMsgSeqNum() != 7 {
+			t.Errorf("Expected seqnums 3 and 7 got %v and %v", store.NextSenderMsgSeqNum(), store.NextTargetMsgSeqNum())
+		}
+
+		if !store.CreationTime().Equal(primary.CreationTime()) {
+			t.Errorf("Expected creation time %v got %v", primary.CreationTime(), store.CreationTime())
+		}
+
+		if state, _ := store.(RecoveryStore).RecoveryState(); !state.LoggedOn {
+			t.Error("Expected recovery state restored")
+		}
+
+		var msgs []string
+		for msg := range store.GetMessages(1, 3) {
+			msgs = append(msgs, string(msg))
+		}
+		if len(msgs) != 2 || msgs[0] != "hello" || msgs[1] != "world" {
+			t.Errorf("Unexpected messages %v", msgs)
+		}
+	}
+
+	if err := standby.(SnapshotStore).Restore([]byte(`{"version":2}`)); err == nil {
+		t.Error("Expected error for unsupported snapshot version")
+	}
+}
@@ -2,6 +2,7 @@ package quickfix
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -126,3 +127,92 @@ func TestMemoryStore_Reset(t *testing.T) {
 		t.Error("TargetMsgSeqNum should reset")
 	}
 }
+
+func TestMemoryStore_RecoveryState(t *testing.T) {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	recoveryStore := store.(RecoveryStore)
+
+	state := RecoveryState{LoggedOn: true, ResendBeginSeqNo: 5, ResendEndSeqNo: 10}
+	if err := recoveryStore.SaveRecoveryState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	if saved, _ := recoveryStore.RecoveryState(); saved != state {
+		t.Errorf("Expected %+v, got %+v", state, saved)
+	}
+
+	store.Reset()
+
+	if saved, _ := recoveryStore.RecoveryState(); saved != (RecoveryState{LoggedOn: true}) {
+		t.Errorf("Expected resend range to reset, got %+v", saved)
+	}
+}
+
+func TestMemoryStore_DeadLetters(t *testing.T) {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	deadLetterStore := store.(DeadLetterStore)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 100; i++ {
+			if err := deadLetterStore.SaveDeadLetter(DeadLetter{SeqNum: i}); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if _, err := deadLetterStore.DeadLetters(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+
+	deadLetters, _ := deadLetterStore.DeadLetters()
+	if len(deadLetters) != 100 {
+		t.Fatalf("Expected 100 dead letters, got %v", len(deadLetters))
+	}
+
+	for i, deadLetter := range deadLetters {
+		if deadLetter.SeqNum != i+1 {
+			t.Errorf("Expected dead letter %v to be seqnum %v, got %v", i, i+1, deadLetter.SeqNum)
+		}
+	}
+}
+
+func TestSaveMessage(t *testing.T) {
+	store, _ := NewMemoryStoreFactory().Create(SessionID{})
+	if err := saveMessage(store, 1, []byte("hello")); err != nil {
+		t.Error("Unexpected error", err)
+	}
+
+	if msg := <-store.GetMessages(1, 1); string(msg) != "hello" {
+		t.Errorf("Expected message saved, got %q", msg)
+	}
+
+	if err := saveMessage(failingStore{store}, 2, []byte("world")); err == nil {
+		t.Error("Expected the error of the ErrorSavingMessageStore")
+	}
+}
+
+func TestErrorLatch(t *testing.T) {
+	var latch ErrorLatch
+	latch.Set(nil)
+	if err := latch.Err(); err != nil {
+		t.Error("Expected no error kept, got", err)
+	}
+
+	first, second := errors.New("disk full"), errors.New("closed")
+	latch.Set(first)
+	latch.Set(second)
+	if err := latch.Err(); err != first {
+		t.Errorf("Expected the first error kept, got %v", err)
+	}
+
+	latch.Clear()
+	latch.Set(second)
+	if err := latch.Err(); err != second {
+		t.Errorf("Expected the error set once cleared kept, got %v", err)
+	}
+}
@@ -0,0 +1,431 @@
+//Package storetest provides conformance tests for implementations of quickfix.MessageStore.
+//The tests are run by the built-in stores and may be run by any other store against itself:
+//
+//	func TestConformance(t *testing.T) {
+//		storetest.Run(t, storetest.Harness{
+//			NewBackend: func(t *testing.T) func([]quickfix.SessionID) quickfix.MessageStoreFactory {
+//				dir, _ := ioutil.TempDir("", "store")
+//				return func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory {
+//					factory, err := NewStoreFactory(storetest.Settings(sessionIDs, map[string]string{config.FileStorePath: dir}))
+//					if err != nil {
+//						t.Fatal(err)
+//					}
+//					return factory
+//				}
+//			},
+//			Persistent: true,
+//		})
+//	}
+package storetest
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"sync"
+	"testing"
+	"time"
+)
+
+//Harness provides the stores under test.
+type Harness struct {
+	//NewBackend is called by each test for a new, empty, backend such as a directory or database, cleaned up by the harness.
+	//It returns a constructor of MessageStoreFactories creating stores of sessionIDs over the backend. The constructor is
+	//called again within a test to simulate a restart of the process, stores created by the new factory must read the state
+	//persisted by the stores of the previous factories, which are abandoned without being flushed.
+	NewBackend func(t *testing.T) func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory
+
+	//Persistent is true for stores keeping their state across restarts. Restart tests are skipped otherwise.
+	Persistent bool
+}
+
+//Session IDs of the stores created by the conformance tests.
+var (
+	SessionID      = quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "STORE", TargetCompID: "TEST"}
+	OtherSessionID = quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "STORE", TargetCompID: "TEST", Qualifier: "OTHER"}
+)
+
+//Settings returns Settings defining sessionIDs, each with the session settings and those of global.
+func Settings(sessionIDs []quickfix.SessionID, global map[string]string) *quickfix.Settings {
+	settings := quickfix.NewSettings()
+	for setting, value := range global {
+		settings.GlobalSettings().Set(setting, value)
+	}
+
+	for _, sessionID := range sessionIDs {
+		sessionSettings := quickfix.NewSessionSettings()
+		sessionSettings.Set(config.BeginString, sessionID.BeginString)
+		sessionSettings.Set(config.SenderCompID, sessionID.SenderCompID)
+		sessionSettings.Set(config.TargetCompID, sessionID.TargetCompID)
+		if sessionID.Qualifier != "" {
+			sessionSettings.Set(config.SessionQualifier, sessionID.Qualifier)
+		}
+
+		if _, err := settings.AddSession(sessionSettings); err != nil {
+			panic(err)
+		}
+	}
+
+	return settings
+}
+
+//Run runs the conformance tests as subtests of t.
+func Run(t *testing.T, h Harness) {
+	for _, test := range []struct {
+		name string
+		run  func(t *testing.T, b *backend)
+	}{
+		{"SeqNums", testSeqNums},
+		{"Reset", testReset},
+		{"GetMessages", testGetMessages},
+		{"ReplaceMessage", testReplaceMessage},
+		{"SessionIsolation", testSessionIsolation},
+		{"Restart", testRestart},
+		{"RecoveryState", testRecoveryState},
+		{"Compact", testCompact},
+		{"ConcurrentAccess", testConcurrentAccess},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, &backend{newFactory: h.NewBackend(t), persistent: h.Persistent})
+		})
+	}
+}
+
+var sessionIDs = []quickfix.SessionID{SessionID, OtherSessionID}
+
+//backend is the backend of a test.
+type backend struct {
+	newFactory func(sessionIDs []quickfix.SessionID) quickfix.MessageStoreFactory
+	persistent bool
+}
+
+//create returns the stores of SessionID and OtherSessionID created by a new factory.
+func (b *backend) create(t *testing.T) (quickfix.MessageStore, quickfix.MessageStore) {
+	factory := b.newFactory(sessionIDs)
+
+	store, err := factory.Create(SessionID)
+	if err != nil {
+		t.Fatalf("cannot create store of %v: %v", SessionID, err)
+	}
+
+	other, err := factory.Create(OtherSessionID)
+	if err != nil {
+		t.Fatalf("cannot create store of %v: %v", OtherSessionID, err)
+	}
+
+	return store, other
+}
+
+//restart returns the store of SessionID created by a new factory, skipping the test if the store is not persistent.
+func (b *backend) restart(t *testing.T) quickfix.MessageStore {
+	if !b.persistent {
+		t.Skip("store is not persistent")
+	}
+
+	store, _ := b.create(t)
+	return store
+}
+
+func message(seqNum int) []byte {
+	return []byte(fmt.Sprintf("8=FIX.4.2\x0135=D\x0134=%d\x0110=000\x01", seqNum))
+}
+
+func collect(store quickfix.MessageStore, beginSeqNum, endSeqNum int) [][]byte {
+	var msgs [][]byte
+	for msg := range store.GetMessages(beginSeqNum, endSeqNum) {
+		msgs = append(msgs, msg)
+	}
+
+	return msgs
+}
+
+func save(t *testing.T, store quickfix.MessageStore, seqNums ...int) {
+	for _, seqNum := range seqNums {
+		if err := saveMessage(store, seqNum, message(seqNum)); err != nil {
+			t.Fatalf("cannot save message %v: %v", seqNum, err)
+		}
+	}
+}
+
+//saveMessage saves msg, returning the failure to save it if store is a quickfix.ErrorSavingMessageStore.
+func saveMessage(store quickfix.MessageStore, seqNum int, msg []byte) error {
+	if errorSaving, ok := store.(quickfix.ErrorSavingMessageStore); ok {
+		return errorSaving.TrySaveMessage(seqNum, msg)
+	}
+
+	store.SaveMessage(seqNum, msg)
+	return nil
+}
+
+//expectMessages fails t unless GetMessages returns the messages of seqNums, in order.
+func expectMessages(t *testing.T, store quickfix.MessageStore, beginSeqNum, endSeqNum int, seqNums ...int) {
+	t.Helper()
+
+	msgs := collect(store, beginSeqNum, endSeqNum)
+	if len(msgs) != len(seqNums) {
+		t.Errorf("GetMessages(%v, %v) returned %d messages, expected %d", beginSeqNum, endSeqNum, len(msgs), len(seqNums))
+		return
+	}
+
+	for i, seqNum := range seqNums {
+		if !bytes.Equal(msgs[i], message(seqNum)) {
+			t.Errorf("GetMessages(%v, %v) returned %q at %d, expected message %d", beginSeqNum, endSeqNum, msgs[i], i, seqNum)
+		}
+	}
+}
+
+func expectSeqNums(t *testing.T, store quickfix.MessageStore, sender, target int) {
+	t.Helper()
+
+	if store.NextSenderMsgSeqNum() != sender || store.NextTargetMsgSeqNum() != target {
+		t.Errorf("Expected next seqnums %v and %v, got %v and %v", sender, target, store.NextSenderMsgSeqNum(), store.NextTargetMsgSeqNum())
+	}
+}
+
+func testSeqNums(t *testing.T, b *backend) {
+	store, _ := b.create(t)
+	expectSeqNums(t, store, 1, 1)
+
+	if store.CreationTime().IsZero() {
+		t.Error("Expected creation time of new session")
+	}
+
+	store.IncrNextSenderMsgSeqNum()
+	store.IncrNextSenderMsgSeqNum()
+	store.IncrNextTargetMsgSeqNum()
+	expectSeqNums(t, store, 3, 2)
+
+	store.SetNextSenderMsgSeqNum(100)
+	store.SetNextTargetMsgSeqNum(200)
+	expectSeqNums(t, store, 100, 200)
+
+	store.IncrNextSenderMsgSeqNum()
+	expectSeqNums(t, store, 101, 200)
+
+	store.Refresh()
+	expectSeqNums(t, store, 101, 200)
+}
+
+func testReset(t *testing.T, b *backend) {
+	store, _ := b.create(t)
+	save(t, store, 1, 2, 3)
+	store.SetNextSenderMsgSeqNum(4)
+	store.SetNextTargetMsgSeqNum(10)
+	created := store.CreationTime()
+
+	time.Sleep(time.Millisecond)
+	store.Reset()
+
+	expectSeqNums(t, store, 1, 1)
+	expectMessages(t, store, 1, 10)
+	if !store.CreationTime().After(created) {
+		t.Errorf("Expected creation time after %v on reset, got %v", created, store.CreationTime())
+	}
+
+	//a reset session is used as a new session
+	save(t, store, 1)
+	store.IncrNextSenderMsgSeqNum()
+	expectMessages(t, store, 1, 10, 1)
+	expectSeqNums(t, store, 2, 1)
+
+	if !b.persistent {
+		return
+	}
+
+	restarted := b.restart(t)
+	expectSeqNums(t, restarted, 2, 1)
+	expectMessages(t, restarted, 1, 10, 1)
+	if !restarted.CreationTime().Equal(store.CreationTime()) {
+		t.Errorf("Expected creation time %v of reset after restart, got %v", store.CreationTime(), restarted.CreationTime())
+	}
+}
+
+func testGetMessages(t *testing.T, b *backend) {
+	store, _ := b.create(t)
+
+	//saved out of order and with gaps, returned in seqnum order without the gaps
+	save(t, store, 5, 1, 3, 2, 4, 8)
+
+	expectMessages(t, store, 1, 8, 1, 2, 3, 4, 5, 8)
+	expectMessages(t, store, 2, 4, 2, 3, 4)
+	expectMessages(t, store, 4, 4, 4)
+	expectMessages(t, store, 6, 7)
+	expectMessages(t, store, 7, 100, 8)
+	expectMessages(t, store, 9, 100)
+	expectMessages(t, store, 5, 2)
+
+	//ranges spanning the pages read by stores
+	var seqNums []int
+	for seqNum := 9; seqNum <= 1100; seqNum++ {
+		seqNums = append(seqNums, seqNum)
+	}
+	save(t, store, seqNums...)
+	expectMessages(t, store, 9, 1100, seqNums...)
+	expectMessages(t, store, 1000, 1001, 1000, 1001)
+}
+
+func testReplaceMessage(t *testing.T, b *backend) {
+	store, _ := b.create(t)
+	save(t, store, 1, 2)
+
+	//a message resent with a seqnum replaces the message saved
+	if err := saveMessage(store, 2, []byte("replaced")); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := collect(store, 1, 2)
+	if len(msgs) != 2 || string(msgs[1]) != "replaced" {
+		t.Errorf("Expected message 2 replaced, got %q", msgs)
+	}
+}
+
+func testSessionIsolation(t *testing.T, b *backend) {
+	store, other := b.create(t)
+
+	save(t, store, 1, 2)
+	save(t, other, 2, 3)
+	store.SetNextSenderMsgSeqNum(3)
+	other.SetNextSenderMsgSeqNum(10)
+
+	expectMessages(t, store, 1, 5, 1, 2)
+	expectMessages(t, other, 1, 5, 2, 3)
+
+	store.Reset()
+	expectMessages(t, other, 1, 5, 2, 3)
+	expectSeqNums(t, other, 10, 1)
+}
+
+//testRestart simulates a crash, the stores of the first factory are abandoned without being flushed.
+func testRestart(t *testing.T, b *backend) {
+	if !b.persistent {
+		t.Skip("store is not persistent")
+	}
+
+	store, other := b.create(t)
+	save(t, store, 1, 2, 3)
+	store.SetNextSenderMsgSeqNum(4)
+	store.IncrNextTargetMsgSeqNum()
+	store.IncrNextTargetMsgSeqNum()
+	save(t, other, 7)
+
+	restarted := b.restart(t)
+	expectSeqNums(t, restarted, 4, 3)
+	expectMessages(t, restarted, 1, 3, 1, 2, 3)
+	if !restarted.CreationTime().Equal(store.CreationTime()) {
+		t.Errorf("Expected creation time %v after restart, got %v", store.CreationTime(), restarted.CreationTime())
+	}
+
+	//the restarted store continues the session
+	save(t, restarted, 4)
+	restarted.IncrNextSenderMsgSeqNum()
+	expectMessages(t, restarted, 1, 4, 1, 2, 3, 4)
+
+	again := b.restart(t)
+	expectSeqNums(t, again, 5, 3)
+	expectMessages(t, again, 1, 4, 1, 2, 3, 4)
+}
+
+func testRecoveryState(t *testing.T, b *backend) {
+	store, _ := b.create(t)
+	recovery, ok := store.(quickfix.RecoveryStore)
+	if !ok {
+		t.Skip("store is not a RecoveryStore")
+	}
+
+	state := quickfix.RecoveryState{LoggedOn: true, ResendBeginSeqNo: 3, ResendEndSeqNo: 7}
+	if err := recovery.SaveRecoveryState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	if saved, err := recovery.RecoveryState(); err != nil || saved != state {
+		t.Errorf("Expected recovery state %+v, got %+v %v", state, saved, err)
+	}
+
+	if b.persistent {
+		restarted := b.restart(t).(quickfix.RecoveryStore)
+		if saved, err := restarted.RecoveryState(); err != nil || saved != state {
+			t.Errorf("Expected recovery state %+v after restart, got %+v %v", state, saved, err)
+		}
+	}
+
+	//a reset abandons the resend, not the logon
+	store.Reset()
+	if saved, _ := recovery.RecoveryState(); saved.ResendBeginSeqNo != 0 || saved.ResendEndSeqNo != 0 {
+		t.Errorf("Expected resend cleared by reset, got %+v", saved)
+	}
+}
+
+func testCompact(t *testing.T, b *backend) {
+	store, _ := b.create(t)
+	compactable, ok := store.(quickfix.CompactableStore)
+	if !ok {
+		t.Skip("store is not a CompactableStore")
+	}
+
+	save(t, store, 1, 2, 3, 4, 5)
+	store.SetNextSenderMsgSeqNum(6)
+
+	if err := compactable.Compact(4); err != nil {
+		t.Fatal(err)
+	}
+
+	expectMessages(t, store, 1, 5, 4, 5)
+	expectSeqNums(t, store, 6, 1)
+
+	save(t, store, 6)
+	expectMessages(t, store, 1, 6, 4, 5, 6)
+
+	if b.persistent {
+		expectMessages(t, b.restart(t), 1, 6, 4, 5, 6)
+	}
+}
+
+//testConcurrentAccess reads the store outside the session goroutine while the session saves messages, run with -race.
+func testConcurrentAccess(t *testing.T, b *backend) {
+	store, _ := b.create(t)
+
+	const messages = 200
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	for reader := 0; reader < 4; reader++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				next := store.NextSenderMsgSeqNum()
+				store.NextTargetMsgSeqNum()
+				store.CreationTime()
+
+				//messages below the next seqnum are saved, each returned whole
+				for msg := range store.GetMessages(1, next-1) {
+					if !bytes.HasPrefix(msg, []byte("8=FIX.4.2\x01")) {
+						t.Errorf("Read partial message %q", msg)
+					}
+				}
+			}
+		}()
+	}
+
+	for seqNum := 1; seqNum <= messages; seqNum++ {
+		save(t, store, seqNum)
+		store.IncrNextSenderMsgSeqNum()
+		store.IncrNextTargetMsgSeqNum()
+	}
+
+	close(done)
+	wg.Wait()
+
+	expectSeqNums(t, store, messages+1, messages+1)
+	if msgs := collect(store, 1, messages); len(msgs) != messages {
+		t.Errorf("Expected %d messages, got %d", messages, len(msgs))
+	}
+}
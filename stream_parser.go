@@ -0,0 +1,309 @@
+package quickfix
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"io"
+)
+
+//GroupEntryHandler is called by a StreamParser with each entry of a repeating group as soon as the entry is read.
+//index is the zero based position of the entry in the group. Returning an error stops delivery of the remaining entries of the message.
+type GroupEntryHandler func(numInGroupTag fix.Tag, index int, entry FieldMap) error
+
+//StreamParser reads FIX messages from a reader one field at a time.
+//Entries of the repeating groups declared in the data dictionary are passed to a GroupEntryHandler and are not retained,
+//so messages with very large groups, such as full market data snapshots, can be processed without buffering the message body.
+//
+//Entries are delivered before the BodyLength and CheckSum of the message are verified.
+//Nested repeating groups are delivered as part of the enclosing entry.
+type StreamParser struct {
+	reader       *bufio.Reader
+	dict         *datadictionary.DataDictionary
+	onGroupEntry GroupEntryHandler
+
+	//MaxMessageSize is the largest BodyLength accepted, 0 for no limit.
+	//Larger messages are skipped and reported with a MessageTooLargeError.
+	MaxMessageSize int
+
+	//MaxFieldLength is the longest field read, tag and SOH included, DefaultMaxFieldLength if 0.
+	//A longer field is reported with a parse error once MaxFieldLength bytes are read without a SOH, bounding the memory of a field.
+	MaxFieldLength int
+}
+
+//DefaultMaxFieldLength is the MaxFieldLength of a StreamParser that does not set it.
+const DefaultMaxFieldLength = 1 << 20
+
+//NewStreamParser returns a StreamParser reading from reader.
+//Repeating groups are recognized using dict, onGroupEntry is called with each group entry.
+func NewStreamParser(reader io.Reader, dict *datadictionary.DataDictionary, onGroupEntry GroupEntryHandler) *StreamParser {
+	return &StreamParser{reader: bufio.NewReader(reader), dict: dict, onGroupEntry: onGroupEntry}
+}
+
+//streamedMessage tracks the state of the message being read.
+type streamedMessage struct {
+	msg        *Message
+	header     fieldMap
+	body       fieldMap
+	trailer    fieldMap
+	length     int
+	checkSum   int
+	bodyLength int
+	err        error
+}
+
+//ReadMessage reads the next message from the stream.
+//The returned message holds all fields outside of repeating groups, including the NumInGroup fields.
+//On a MessageTooLargeError or a group entry handler error, the remainder of the message is consumed so that the next call reads the following message.
+func (p *StreamParser) ReadMessage() (*Message, error) {
+	beginString, err := p.findStart()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &streamedMessage{}
+	m.header.init(headerFieldOrder)
+	m.body.init(normalFieldOrder)
+	m.trailer.init(trailerFieldOrder)
+	m.msg = &Message{Header: m.header, Body: m.body, Trailer: m.trailer}
+	m.add(beginString)
+
+	bodyLength, err := p.readField()
+	if err != nil {
+		return nil, err
+	}
+	if bodyLength.Tag != tag.BodyLength {
+		return nil, parseError{OrigError: fmt.Sprintf("StreamParser: Fields out of order, expected %d, got %d", tag.BodyLength, bodyLength.Tag)}
+	}
+	if m.bodyLength, err = fix.Atoi(bodyLength.Value); err != nil {
+		return nil, parseError{OrigError: fmt.Sprintf("StreamParser: invalid BodyLength %q", bodyLength.Value)}
+	}
+	m.add(bodyLength)
+
+	if p.MaxMessageSize > 0 && m.bodyLength > p.MaxMessageSize {
+		return nil, p.skip(m)
+	}
+
+	var msgDef *datadictionary.MessageDef
+	var pending *fieldBytes
+	for {
+		f := pending
+		pending = nil
+
+		if f == nil {
+			if f, err = p.readField(); err != nil {
+				return nil, err
+			}
+		}
+
+		if f.Tag == tag.CheckSum {
+			m.add(f)
+			break
+		}
+
+		m.length += f.Length()
+		if f.Tag == tag.MsgType && p.dict != nil {
+			msgDef = p.dict.Messages[string(f.Value)]
+		}
+
+		groupDef := p.groupDef(msgDef, f.Tag)
+		m.add(f)
+
+		if groupDef != nil {
+			if pending, err = p.readGroup(m, groupDef, f); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if m.err != nil {
+		return m.msg, m.err
+	}
+
+	if m.length != m.bodyLength {
+		return m.msg, parseError{OrigError: fmt.Sprintf("Incorrect Message Length, expected %d, got %d", m.bodyLength, m.length)}
+	}
+
+	checkSum := new(fix.IntValue)
+	if err := m.trailer.GetField(tag.CheckSum, checkSum); err != nil || checkSum.Value != m.checkSum%256 {
+		return m.msg, parseError{OrigError: fmt.Sprintf("Incorrect CheckSum, expected %03d", m.checkSum%256)}
+	}
+
+	return m.msg, nil
+}
+
+//add places a field outside of a repeating group in the message.
+func (m *streamedMessage) add(f *fieldBytes) {
+	switch {
+	case tag.IsHeader(f.Tag):
+		m.header.fieldLookup[f.Tag] = f
+	case tag.IsTrailer(f.Tag):
+		m.trailer.fieldLookup[f.Tag] = f
+	default:
+		m.body.fieldLookup[f.Tag] = f
+	}
+
+	m.msg.fields = append(m.msg.fields, *f)
+	if f.Tag != tag.CheckSum {
+		m.checkSum += f.Total()
+	}
+}
+
+//groupDef returns the definition of t if it is a repeating group field of the header or message.
+func (p *StreamParser) groupDef(msgDef *datadictionary.MessageDef, t fix.Tag) *datadictionary.FieldDef {
+	if p.dict == nil {
+		return nil
+	}
+
+	def := msgDef
+	if tag.IsHeader(t) {
+		def = p.dict.Header
+	}
+
+	if def == nil {
+		return nil
+	}
+
+	if fieldDef, ok := def.Fields[t]; ok && fieldDef.IsGroup() {
+		return fieldDef
+	}
+
+	return nil
+}
+
+//readGroup reads the entries of the group started by numInGroup, delivering each to the group entry handler.
+//Returns the first field read that follows the group.
+func (p *StreamParser) readGroup(m *streamedMessage, groupDef *datadictionary.FieldDef, numInGroup *fieldBytes) (*fieldBytes, error) {
+	expected, err := fix.Atoi(numInGroup.Value)
+	if err != nil && m.err == nil {
+		m.err = incorrectDataFormatForValue(numInGroup.Tag)
+	}
+
+	memberTags := make(datadictionary.TagSet)
+	addGroupMembers(memberTags, groupDef)
+	delim := groupDef.ChildFields[0].Tag
+
+	var entry fieldMap
+	count := 0
+	deliver := func() {
+		if entry.fieldLookup == nil {
+			return
+		}
+
+		if m.err == nil && p.onGroupEntry != nil {
+			m.err = p.onGroupEntry(numInGroup.Tag, count, entry)
+		}
+		count++
+	}
+
+	for {
+		f, err := p.readField()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, member := memberTags[f.Tag]; !member || f.Tag == tag.CheckSum {
+			deliver()
+			if count != expected && m.err == nil {
+				m.err = incorrectNumInGroupCountForRepeatingGroup(numInGroup.Tag)
+			}
+
+			return f, nil
+		}
+
+		m.length += f.Length()
+		m.checkSum += f.Total()
+
+		if f.Tag == delim {
+			deliver()
+			entry.init(normalFieldOrder)
+		}
+
+		if entry.fieldLookup == nil {
+			if m.err == nil {
+				m.err = tagSpecifiedOutOfRequiredOrder(f.Tag)
+			}
+			continue
+		}
+
+		entry.fieldLookup[f.Tag] = f
+	}
+}
+
+//addGroupMembers adds the tags of all fields nested in groupDef to tags.
+func addGroupMembers(tags datadictionary.TagSet, groupDef *datadictionary.FieldDef) {
+	for _, child := range groupDef.ChildFields {
+		tags.Add(child.Tag)
+		addGroupMembers(tags, child)
+	}
+}
+
+//skip consumes the remainder of a message exceeding MaxMessageSize, retaining its standard header.
+func (p *StreamParser) skip(m *streamedMessage) error {
+	for {
+		f, err := p.readField()
+		if err != nil {
+			return err
+		}
+
+		if f.Tag == tag.CheckSum {
+			break
+		}
+
+		if tag.IsHeader(f.Tag) {
+			m.header.fieldLookup[f.Tag] = f
+		}
+	}
+
+	return MessageTooLargeError{Header: m.header, BodyLength: m.bodyLength, MaxMessageSize: p.MaxMessageSize}
+}
+
+//findStart discards input up to the next BeginString field and returns it.
+func (p *StreamParser) findStart() (*fieldBytes, error) {
+	for {
+		f, err := p.readField()
+		switch {
+		case err == nil && f.Tag == tag.BeginString:
+			return f, nil
+		case err == nil:
+			continue
+		}
+
+		if _, isParseError := err.(parseError); !isParseError {
+			return nil, err
+		}
+	}
+}
+
+//readField reads a single field from the stream, of at most MaxFieldLength bytes.
+func (p *StreamParser) readField() (*fieldBytes, error) {
+	maxFieldLength := p.MaxFieldLength
+	if maxFieldLength <= 0 {
+		maxFieldLength = DefaultMaxFieldLength
+	}
+
+	var raw []byte
+	for {
+		chunk, err := p.reader.ReadSlice('\001')
+		if len(raw)+len(chunk) > maxFieldLength {
+			return nil, parseError{OrigError: fmt.Sprintf("StreamParser: field longer than %d bytes", maxFieldLength)}
+		}
+		raw = append(raw, chunk...)
+
+		if err == nil {
+			break
+		}
+		if err != bufio.ErrBufferFull {
+			return nil, err
+		}
+	}
+
+	f := new(fieldBytes)
+	if err := f.parseField(raw); err != nil {
+		return nil, parseError{OrigError: err.Error()}
+	}
+
+	return f, nil
+}
@@ -0,0 +1,164 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix/datadictionary"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func marketDataSnapshot(seqNum, entries int) []byte {
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "35=W\00134=%d\00149=TW\00152=20140511-23:10:34\00156=ISLD\00155=TSLA\001268=%d\001", seqNum, entries)
+	for i := 0; i < entries; i++ {
+		fmt.Fprintf(&body, "269=0\001270=%d\001271=100\001", i)
+	}
+
+	return rawMessage("FIX.4.4", body.String())
+}
+
+func TestStreamParser_ReadMessage(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX44.xml")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var stream bytes.Buffer
+	stream.Write(marketDataSnapshot(1, 1000))
+	stream.Write(marketDataSnapshot(2, 3))
+
+	var prices []int
+	onGroupEntry := func(numInGroupTag fix.Tag, index int, entry FieldMap) error {
+		if numInGroupTag != tag.NoMDEntries {
+			t.Errorf("expected group %v got %v", tag.NoMDEntries, numInGroupTag)
+		}
+
+		if index != len(prices) {
+			t.Errorf("expected index %v got %v", len(prices), index)
+		}
+
+		price := new(fix.IntValue)
+		if err := entry.GetField(tag.MDEntryPx, price); err != nil {
+			t.Error("unexpected error", err)
+		}
+		prices = append(prices, price.Value)
+		return nil
+	}
+
+	parser := NewStreamParser(iotest.HalfReader(&stream), dict, onGroupEntry)
+
+	for _, expectedEntries := range []int{1000, 3} {
+		prices = nil
+		msg, err := parser.ReadMessage()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if len(prices) != expectedEntries {
+			t.Fatalf("expected %v entries got %v", expectedEntries, len(prices))
+		}
+
+		if prices[expectedEntries-1] != expectedEntries-1 {
+			t.Errorf("expected last price %v got %v", expectedEntries-1, prices[expectedEntries-1])
+		}
+
+		symbol := new(fix.StringValue)
+		if err := msg.Body.GetField(tag.Symbol, symbol); err != nil || symbol.Value != "TSLA" {
+			t.Errorf("expected Symbol TSLA, got %v %v", symbol.Value, err)
+		}
+
+		if msg.Body.Has(tag.MDEntryPx) {
+			t.Error("group entries should not be retained in the message body")
+		}
+	}
+
+	if _, err := parser.ReadMessage(); err != io.EOF {
+		t.Error("expected EOF, got", err)
+	}
+}
+
+func TestStreamParser_IncorrectNumInGroup(t *testing.T) {
+	dict, _ := datadictionary.Parse("spec/FIX44.xml")
+
+	raw := rawMessage("FIX.4.4", "35=W\00134=1\00149=TW\00152=20140511-23:10:34\00156=ISLD\00155=TSLA\001268=3\001269=0\001270=1\001")
+	parser := NewStreamParser(bytes.NewReader(raw), dict, nil)
+
+	_, err := parser.ReadMessage()
+	reject, ok := err.(MessageRejectError)
+	if !ok {
+		t.Fatal("expected MessageRejectError, got", err)
+	}
+
+	if reject.RejectReason() != rejectReasonIncorrectNumInGroupCountForRepeatingGroup {
+		t.Errorf("expected reject reason %v got %v", rejectReasonIncorrectNumInGroupCountForRepeatingGroup, reject.RejectReason())
+	}
+}
+
+func TestStreamParser_MaxMessageSize(t *testing.T) {
+	dict, _ := datadictionary.Parse("spec/FIX44.xml")
+
+	var stream bytes.Buffer
+	stream.Write(marketDataSnapshot(1, 1000))
+	stream.Write(marketDataSnapshot(2, 3))
+
+	entries := 0
+	parser := NewStreamParser(&stream, dict, func(fix.Tag, int, FieldMap) error {
+		entries++
+		return nil
+	})
+	parser.MaxMessageSize = 1024
+
+	_, err := parser.ReadMessage()
+	tooLarge, ok := err.(MessageTooLargeError)
+	if !ok {
+		t.Fatal("expected MessageTooLargeError, got", err)
+	}
+
+	seqNum := new(fix.IntValue)
+	if err := tooLarge.Header.GetField(tag.MsgSeqNum, seqNum); err != nil || seqNum.Value != 1 {
+		t.Errorf("expected MsgSeqNum 1 in discarded header, got %v %v", seqNum.Value, err)
+	}
+
+	if entries != 0 {
+		t.Errorf("expected no entries from discarded message, got %v", entries)
+	}
+
+	if _, err := parser.ReadMessage(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if entries != 3 {
+		t.Errorf("expected 3 entries got %v", entries)
+	}
+}
+
+func TestStreamParser_MaxFieldLength(t *testing.T) {
+	var stream bytes.Buffer
+	stream.WriteString("8=FIX.4.4\0019=100000\00135=")
+	stream.Write(bytes.Repeat([]byte("A"), 100000))
+	stream.WriteString("\00110=000\001")
+	stream.Write(marketDataSnapshot(2, 3))
+
+	parser := NewStreamParser(&stream, nil, nil)
+	parser.MaxFieldLength = 1024
+
+	if _, err := parser.ReadMessage(); err == nil {
+		t.Fatal("expected error for field longer than MaxFieldLength")
+	} else if _, ok := err.(parseError); !ok {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+
+	msg, err := parser.ReadMessage()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	seqNum := new(fix.IntValue)
+	if err := msg.Header.GetField(tag.MsgSeqNum, seqNum); err != nil || seqNum.Value != 2 {
+		t.Errorf("expected the following message read, got MsgSeqNum %v %v", seqNum.Value, err)
+	}
+}
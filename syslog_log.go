@@ -0,0 +1,240 @@
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSyslogAppName  = "quickfix"
+	defaultSyslogFacility = 16 //local0
+
+	//syslogSDID is the SD-ID of the structured data of records, under the enterprise number reserved for documentation by RFC 5612
+	syslogSDID = "fix@32473"
+
+	syslogTimestampLayout = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+//syslog severities of RFC 5424
+const (
+	syslogError         = 3
+	syslogWarning       = 4
+	syslogInformational = 6
+	syslogDebug         = 7
+)
+
+//syslogFacilities name the facilities of SyslogFacility.
+var syslogFacilities = map[string]int{
+	"user": 1, "daemon": 3, "local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+//SyslogLogFactory is a LogFactory writing the messages and events of sessions to syslog as RFC 5424 records.
+//Each record carries structured data fix@32473 with the session, and the direction, MsgType and MsgSeqNum of messages.
+type SyslogLogFactory struct {
+	network, address string
+	facility         int
+	hostname         string
+	appName          string
+
+	lock sync.Mutex
+	conn net.Conn
+
+	//OnError, if set, is called each time a record cannot be written. The record is discarded.
+	OnError func(err error)
+}
+
+//NewSyslogLogFactory returns a SyslogLogFactory writing to SyslogAddress over SyslogNetwork, tcp, udp, unix or unixgram,
+//or to the local syslog daemon at /dev/log without SyslogAddress. Records over tcp and unix are framed by octet counting, RFC 6587.
+//SyslogFacility, a number or user, daemon, local0 through local7, defaults to local0. SyslogAppName defaults to quickfix.
+//The connection is made when the first record is written and remade once a write fails.
+func NewSyslogLogFactory(settings *Settings) (*SyslogLogFactory, error) {
+	globalSettings := settings.GlobalSettings()
+	f := &SyslogLogFactory{network: "unixgram", address: "/dev/log", facility: defaultSyslogFacility, appName: defaultSyslogAppName, hostname: "-"}
+
+	if globalSettings.HasSetting(config.SyslogAddress) {
+		f.address, _ = globalSettings.Setting(config.SyslogAddress)
+
+		network, err := globalSettings.Setting(config.SyslogNetwork)
+		if err != nil {
+			return nil, requiredConfigurationMissing(config.SyslogNetwork)
+		}
+
+		switch network {
+		case "tcp", "udp", "unix", "unixgram":
+			f.network = network
+		default:
+			return nil, fmt.Errorf("invalid %v %v, expected tcp, udp, unix, or unixgram", config.SyslogNetwork, network)
+		}
+	}
+
+	if globalSettings.HasSetting(config.SyslogFacility) {
+		setting, _ := globalSettings.Setting(config.SyslogFacility)
+
+		facility, ok := syslogFacilities[setting]
+		if !ok {
+			var err error
+			if facility, err = strconv.Atoi(setting); err != nil || facility < 0 || facility > 23 {
+				return nil, fmt.Errorf("invalid %v %v, expected 0 to 23, user, daemon, or local0 to local7", config.SyslogFacility, setting)
+			}
+		}
+		f.facility = facility
+	}
+
+	if globalSettings.HasSetting(config.SyslogAppName) {
+		f.appName, _ = globalSettings.Setting(config.SyslogAppName)
+	}
+
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		f.hostname = hostname
+	}
+
+	return f, nil
+}
+
+//Create returns the global log, its records without a session.
+func (f *SyslogLogFactory) Create() (Log, error) {
+	return syslogLog{factory: f}, nil
+}
+
+//CreateSessionLog returns the log of sessionID.
+func (f *SyslogLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	return syslogLog{factory: f, session: sessionID.String()}, nil
+}
+
+//Close closes the connection to syslog.
+func (f *SyslogLogFactory) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.conn == nil {
+		return nil
+	}
+
+	err := f.conn.Close()
+	f.conn = nil
+	return err
+}
+
+//write writes record, redialing once if the connection fails.
+func (f *SyslogLogFactory) write(record []byte) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.network == "tcp" || f.network == "unix" {
+		framed := append(strconv.AppendInt(getBuffer(len(record)+8), int64(len(record)), 10), ' ')
+		record = append(framed, record...)
+		defer putBuffer(record)
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if f.conn == nil {
+			if f.conn, err = net.Dial(f.network, f.address); err != nil {
+				continue
+			}
+		}
+
+		if _, err = f.conn.Write(record); err == nil {
+			return
+		}
+
+		f.conn.Close()
+		f.conn = nil
+	}
+
+	if f.OnError != nil {
+		f.OnError(fmt.Errorf("cannot write to syslog %v %v: %v", f.network, f.address, err))
+	}
+}
+
+type syslogLog struct {
+	factory *SyslogLogFactory
+	session string
+}
+
+func (l syslogLog) OnIncoming(msg string) {
+	l.onMessage("incoming", msg)
+}
+
+func (l syslogLog) OnOutgoing(msg string) {
+	l.onMessage("outgoing", msg)
+}
+
+func (l syslogLog) onMessage(direction, msg string) {
+	params := []string{"direction", direction}
+
+	msgType, seqNum := logHeader(msg)
+	if msgType != nil {
+		params = append(params, "msgType", msgType.Value)
+	}
+
+	if seqNum != nil {
+		params = append(params, "seqNum", strconv.Itoa(seqNum.Value))
+	}
+
+	l.write(syslogInformational, "MSG", params, msg)
+}
+
+func (l syslogLog) OnEvent(msg string) {
+	l.write(syslogInformational, "EVENT", nil, msg)
+}
+
+func (l syslogLog) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+//OnLeveledEvent writes msg at the syslog severity of level, with its category as a parameter.
+func (l syslogLog) OnLeveledEvent(level LogLevel, category LogCategory, msg string) {
+	l.write(syslogSeverity(level), "EVENT", []string{"category", string(category)}, msg)
+}
+
+//syslogSeverity maps level to a syslog severity.
+func syslogSeverity(level LogLevel) int {
+	switch level {
+	case LogLevelDebug:
+		return syslogDebug
+	case LogLevelWarn:
+		return syslogWarning
+	case LogLevelError:
+		return syslogError
+	}
+
+	return syslogInformational
+}
+
+//write formats an RFC 5424 record with the session and params, name value pairs, as structured data.
+func (l syslogLog) write(severity int, msgID string, params []string, msg string) {
+	f := l.factory
+
+	record := bytes.NewBuffer(getBuffer(len(msg) + 256))
+	fmt.Fprintf(record, "<%d>1 %v %v %v %d %v ", f.facility*8+severity, time.Now().UTC().Format(syslogTimestampLayout), f.hostname, f.appName, os.Getpid(), msgID)
+
+	if l.session != "" {
+		params = append([]string{"session", l.session}, params...)
+	}
+
+	if len(params) == 0 {
+		record.WriteString("-")
+	} else {
+		record.WriteString("[" + syslogSDID)
+		for i := 0; i+1 < len(params); i += 2 {
+			fmt.Fprintf(record, " %v=\"%v\"", params[i], syslogParamEscaper.Replace(params[i+1]))
+		}
+		record.WriteString("]")
+	}
+
+	record.WriteString(" " + msg)
+	f.write(record.Bytes())
+	putBuffer(record.Bytes())
+}
+
+//syslogParamEscaper escapes the characters of PARAM-VALUE required by RFC 5424.
+var syslogParamEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
@@ -0,0 +1,122 @@
+package quickfix
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"strings"
+	"testing"
+)
+
+func syslogSettings(global map[string]string) *Settings {
+	settings := NewSettings()
+	for key, value := range global {
+		settings.GlobalSettings().Set(key, value)
+	}
+
+	return settings
+}
+
+func TestNewSyslogLogFactory_Settings(t *testing.T) {
+	for _, invalid := range []map[string]string{
+		{config.SyslogAddress: "localhost:514"},
+		{config.SyslogAddress: "localhost:514", config.SyslogNetwork: "sctp"},
+		{config.SyslogFacility: "24"},
+		{config.SyslogFacility: "kern0"},
+	} {
+		if _, err := NewSyslogLogFactory(syslogSettings(invalid)); err == nil {
+			t.Errorf("Expected error for %v", invalid)
+		}
+	}
+
+	factory, err := NewSyslogLogFactory(syslogSettings(map[string]string{config.SyslogFacility: "local3"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if factory.network != "unixgram" || factory.address != "/dev/log" || factory.facility != 19 {
+		t.Errorf("Unexpected factory %+v", factory)
+	}
+}
+
+func TestSyslogLog_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	factory, err := NewSyslogLogFactory(syslogSettings(map[string]string{
+		config.SyslogNetwork: "udp", config.SyslogAddress: conn.LocalAddr().String(), config.SyslogAppName: "gateway",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer factory.Close()
+
+	log, _ := factory.CreateSessionLog(SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"})
+	log.OnIncoming("8=FIX.4.2\x019=20\x0135=D\x0134=7\x01")
+	logEventf(log, LogLevelWarn, LogCategoryValidation, "Message Rejected: \"bad\"")
+
+	buf := make([]byte, 1024)
+	var records []string
+	for i := 0; i < 2; i++ {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, string(buf[:n]))
+	}
+
+	if !strings.HasPrefix(records[0], "<134>1 ") || !strings.Contains(records[0], " gateway ") ||
+		!strings.Contains(records[0], `MSG [fix@32473 session="FIX.4.2:TW->ISLD" direction="incoming" msgType="D" seqNum="7"] 8=FIX.4.2`) {
+		t.Errorf("Unexpected message record %q", records[0])
+	}
+
+	if !strings.HasPrefix(records[1], "<132>1 ") ||
+		!strings.HasSuffix(records[1], `EVENT [fix@32473 session="FIX.4.2:TW->ISLD" category="Validation"] Message Rejected: "bad"`) {
+		t.Errorf("Unexpected event record %q", records[1])
+	}
+}
+
+func TestSyslogLog_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	factory, err := NewSyslogLogFactory(syslogSettings(map[string]string{config.SyslogNetwork: "tcp", config.SyslogAddress: listener.Addr().String()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer factory.Close()
+
+	var errs []error
+	factory.OnError = func(err error) { errs = append(errs, err) }
+
+	log, _ := factory.Create()
+	log.OnEvent("Listening")
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var length int
+	reader := bufio.NewReader(conn)
+	if _, err := fmt.Fscanf(reader, "%d ", &length); err != nil {
+		t.Fatal(err)
+	}
+
+	record := make([]byte, length)
+	if _, err := reader.Read(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(string(record), " EVENT - Listening") || len(errs) != 0 {
+		t.Errorf("Unexpected record %q, errors %v", record, errs)
+	}
+}
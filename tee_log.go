@@ -0,0 +1,77 @@
+package quickfix
+
+import "fmt"
+
+//teeLog writes each record to all of its logs, in order.
+type teeLog []Log
+
+func (l teeLog) OnIncoming(msg string) {
+	for _, log := range l {
+		log.OnIncoming(msg)
+	}
+}
+
+func (l teeLog) OnOutgoing(msg string) {
+	for _, log := range l {
+		log.OnOutgoing(msg)
+	}
+}
+
+func (l teeLog) OnEvent(msg string) {
+	for _, log := range l {
+		log.OnEvent(msg)
+	}
+}
+
+func (l teeLog) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+func (l teeLog) OnLeveledEvent(level LogLevel, category LogCategory, msg string) {
+	for _, log := range l {
+		logEventf(log, level, category, "%s", msg)
+	}
+}
+
+type teeLogFactory []LogFactory
+
+//NewTeeLogFactory returns a LogFactory whose logs write each record to the logs of all of factories, e.g. to local files and a central collector.
+//A record is written to the logs in the order of factories, wrap slow factories with NewAsyncLogFactory.
+func NewTeeLogFactory(factories ...LogFactory) LogFactory {
+	return teeLogFactory(factories)
+}
+
+func (f teeLogFactory) Create() (Log, error) {
+	var logs teeLog
+	for _, factory := range f {
+		log, err := factory.Create()
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, nil
+}
+
+func (f teeLogFactory) CreateSessionLog(sessionID SessionID) (Log, error) {
+	var logs teeLog
+	for _, factory := range f {
+		log, err := factory.CreateSessionLog(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, nil
+}
+
+//Flush flushes the factories implementing FlushableLogFactory.
+func (f teeLogFactory) Flush() {
+	for _, factory := range f {
+		if flushable, ok := factory.(FlushableLogFactory); ok {
+			flushable.Flush()
+		}
+	}
+}
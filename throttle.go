@@ -0,0 +1,124 @@
+package quickfix
+
+import (
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"math"
+	"time"
+)
+
+//throttlePolicy determines how a session handles an application message sent over the ThrottleRate.
+type throttlePolicy int
+
+const (
+	//throttleDelay queues the message to be sent once the rate allows, the send returns immediately.
+	throttleDelay throttlePolicy = iota
+
+	//throttleBlock waits until the rate allows the message to be sent.
+	//Sending from an Application callback blocks the session.
+	throttleBlock
+
+	//throttleReject fails the send with ErrThrottled.
+	throttleReject
+)
+
+//parseThrottlePolicy maps the ThrottlePolicy setting to a throttlePolicy.
+func parseThrottlePolicy(setting string) (throttlePolicy, error) {
+	switch setting {
+	case "Delay":
+		return throttleDelay, nil
+	case "Block":
+		return throttleBlock, nil
+	case "Reject":
+		return throttleReject, nil
+	}
+
+	return throttleDelay, fmt.Errorf("invalid ThrottlePolicy %v, expected Delay, Block, or Reject", setting)
+}
+
+//throttle is a token bucket limiting the rate application messages are sent.
+type throttle struct {
+	//rate is the number of messages per second, burst the number that may be sent at once.
+	rate  float64
+	burst float64
+
+	tokens float64
+	last   time.Time
+	policy throttlePolicy
+}
+
+//newThrottle returns the throttle configured in settings, nil if ThrottleRate is not set.
+func newThrottle(settings *SessionSettings) (*throttle, error) {
+	if !settings.HasSetting(config.ThrottleRate) {
+		return nil, nil
+	}
+
+	rate, err := settings.FloatSetting(config.ThrottleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	if rate <= 0 {
+		return nil, fmt.Errorf("ThrottleRate must be a positive number of messages per second")
+	}
+
+	t := &throttle{rate: rate, burst: math.Max(1, math.Floor(rate))}
+
+	if settings.HasSetting(config.ThrottleBurst) {
+		burst, err := settings.IntSetting(config.ThrottleBurst)
+		if err != nil {
+			return nil, err
+		}
+
+		if burst <= 0 {
+			return nil, fmt.Errorf("ThrottleBurst must be a positive number")
+		}
+		t.burst = float64(burst)
+	}
+
+	if settings.HasSetting(config.ThrottlePolicy) {
+		policy, err := settings.Setting(config.ThrottlePolicy)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.policy, err = parseThrottlePolicy(policy); err != nil {
+			return nil, err
+		}
+	}
+
+	t.tokens = t.burst
+	return t, nil
+}
+
+//reserve takes a token at now and returns zero if one is available, otherwise returns the time until one is.
+func (t *throttle) reserve(now time.Time) time.Duration {
+	if now.After(t.last) {
+		if !t.last.IsZero() {
+			t.tokens = math.Min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+		}
+		t.last = now
+	}
+
+	if t.tokens >= 1 {
+		t.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - t.tokens) / t.rate * float64(time.Second))
+}
+
+//ThrottledMessages returns the number of application messages sent over the ThrottleRate since the session was created.
+func (s *Session) ThrottledMessages() int {
+	s.trafficLock.RLock()
+	defer s.trafficLock.RUnlock()
+
+	return s.traffic.throttled
+}
+
+func (s *Session) onThrottled() {
+	s.trafficLock.Lock()
+	defer s.trafficLock.Unlock()
+
+	s.traffic.throttled++
+}
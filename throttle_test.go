@@ -0,0 +1,122 @@
+package quickfix
+
+import (
+	"github.com/quickfixgo/quickfix/config"
+	"testing"
+	"time"
+)
+
+func TestThrottle_Reserve(t *testing.T) {
+	settings := NewSessionSettings()
+	settings.Set(config.ThrottleRate, "10")
+	settings.Set(config.ThrottleBurst, "2")
+
+	throttle, err := newThrottle(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	var tests = []struct {
+		at       time.Duration
+		expected time.Duration
+	}{
+		{0, 0},
+		{0, 0},
+		{0, 100 * time.Millisecond},
+		{50 * time.Millisecond, 50 * time.Millisecond},
+		{100 * time.Millisecond, 0},
+		{time.Second, 0},
+		{time.Second, 0},
+		{time.Second, 100 * time.Millisecond},
+	}
+
+	for n, test := range tests {
+		if wait := throttle.reserve(now.Add(test.at)); wait != test.expected {
+			t.Errorf("%v: Expected wait %v got %v", n, test.expected, wait)
+		}
+	}
+}
+
+func TestThrottle_InvalidSettings(t *testing.T) {
+	var tests = []struct {
+		setting, value string
+	}{
+		{config.ThrottleRate, "0"},
+		{config.ThrottleBurst, "0"},
+		{config.ThrottlePolicy, "Drop"},
+	}
+
+	for _, test := range tests {
+		settings := NewSessionSettings()
+		settings.Set(config.ThrottleRate, "10")
+		settings.Set(test.setting, test.value)
+
+		if _, err := newThrottle(settings); err == nil {
+			t.Errorf("Expected error for %v=%v", test.setting, test.value)
+		}
+	}
+}
+
+func newTestThrottledSession(policy throttlePolicy) *Session {
+	s := newTestSendQueueSession(0, sendQueueOverflowError)
+	s.throttle = &throttle{rate: 20, burst: 1, tokens: 1, policy: policy}
+	s.updateSendQueue(StateLoggedOn)
+
+	return s
+}
+
+func TestSession_ThrottleReject(t *testing.T) {
+	s := newTestThrottledSession(throttleReject)
+
+	if err := s.sendOrQueue(newSendQueueTestMessage("1")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := s.sendOrQueue(newSendQueueTestMessage("2")); err != ErrThrottled {
+		t.Errorf("Expected ErrThrottled got %v", err)
+	}
+
+	checkSent(t, s, "1")
+	if s.ThrottledMessages() != 1 {
+		t.Errorf("Expected 1 throttled message got %v", s.ThrottledMessages())
+	}
+}
+
+func TestSession_ThrottleDelay(t *testing.T) {
+	s := newTestThrottledSession(throttleDelay)
+
+	for _, clOrdID := range []string{"1", "2", "3"} {
+		if err := s.sendOrQueue(newSendQueueTestMessage(clOrdID)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if len(s.messageOut) != 1 {
+		t.Fatalf("Expected 1 message sent before the throttle delay got %v", len(s.messageOut))
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	checkSent(t, s, "1", "2", "3")
+
+	if s.ThrottledMessages() != 2 {
+		t.Errorf("Expected 2 throttled messages got %v", s.ThrottledMessages())
+	}
+}
+
+func TestSession_ThrottleBlock(t *testing.T) {
+	s := newTestThrottledSession(throttleBlock)
+
+	start := time.Now()
+	for _, clOrdID := range []string{"1", "2"} {
+		if err := s.sendOrQueue(newSendQueueTestMessage(clOrdID)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected send to block for the throttle, returned after %v", elapsed)
+	}
+
+	checkSent(t, s, "1", "2")
+}
@@ -0,0 +1,211 @@
+package quickfix
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+)
+
+//tlsHandshakeTimeout bounds the TLS handshake of an initiated connection.
+const tlsHandshakeTimeout = 10 * time.Second
+
+//tlsSettings are the settings of the TLS configuration of a session.
+var tlsSettings = []string{
+	config.SocketUseSSL,
+	config.SocketCertificateFile,
+	config.SocketPrivateKeyFile,
+	config.SocketCAFile,
+	config.SocketMinimumTLSVersion,
+	config.SocketCipherSuites,
+	config.SocketServerName,
+	config.SocketInsecureSkipVerify,
+	config.SocketClientAuth,
+	config.SocketPinnedCertificates,
+	config.SocketCRLFile,
+	config.SocketOCSPPolicy,
+}
+
+var tlsVersions = map[string]uint16{
+	"TLSv1.0": tls.VersionTLS10,
+	"TLSv1.1": tls.VersionTLS11,
+	"TLSv1.2": tls.VersionTLS12,
+	"TLSv1.3": tls.VersionTLS13,
+}
+
+var tlsClientAuthTypes = map[string]tls.ClientAuthType{
+	"NoClientCert":               tls.NoClientCert,
+	"RequestClientCert":          tls.RequestClientCert,
+	"RequireAnyClientCert":       tls.RequireAnyClientCert,
+	"VerifyClientCertIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+}
+
+//loadTLSConfig returns the TLS configuration of a session, nil unless SocketUseSSL=Y or SocketCertificateFile is set.
+//SocketCertificateFile and SocketPrivateKeyFile are the PEM certificate chain and key presented, required by acceptors.
+//Either may reference a secret of the SecretsProvider of the session holding the PEM content, read again on handshake once the secret expires.
+//SocketCAFile is a PEM bundle verifying the counterparty, the roots of the system if not set.
+//SocketMinimumTLSVersion is TLSv1.0, TLSv1.1, TLSv1.2, the default, or TLSv1.3.
+//SocketCipherSuites is a comma separated list of the cipher suites allowed below TLS 1.3, by their names in crypto/tls.
+//Initiators verify the SocketServerName of the acceptor, the host connected to if not set, unless SocketInsecureSkipVerify=Y.
+//Acceptors require client certificates according to SocketClientAuth, verified against SocketCAFile, NoClientCert by default.
+//The certificate of the counterparty must then match one of the SHA-256 fingerprints of SocketPinnedCertificates, if set, must not be
+//revoked by the lists of SocketCRLFile, and its revocation status is checked with its OCSP responder according to SocketOCSPPolicy.
+func loadTLSConfig(settings *SessionSettings) (*tls.Config, error) {
+	useSSL := false
+	if settings.HasSetting(config.SocketUseSSL) {
+		var err error
+		if useSSL, err = settings.BoolSetting(config.SocketUseSSL); err != nil {
+			return nil, err
+		}
+	}
+
+	if !useSSL && !settings.HasSetting(config.SocketCertificateFile) {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if settings.HasSetting(config.SocketCertificateFile) {
+		certificateFile, _ := settings.Setting(config.SocketCertificateFile)
+		privateKeyFile, err := settings.Setting(config.SocketPrivateKeyFile)
+		if err != nil {
+			return nil, requiredConfigurationMissing(config.SocketPrivateKeyFile)
+		}
+
+		if isSecretReference(certificateFile) || isSecretReference(privateKeyFile) {
+			secret, err := loadSecretCertificate(settings, certificateFile, privateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			certificate, _ := secret.load()
+			tlsConfig.Certificates = []tls.Certificate{*certificate}
+			tlsConfig.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return secret.load() }
+			tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) { return secret.load() }
+		} else {
+			certificate, err := tls.LoadX509KeyPair(certificateFile, privateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("cannot load %v: %v", config.SocketCertificateFile, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{certificate}
+		}
+	}
+
+	if settings.HasSetting(config.SocketCAFile) {
+		caFile, _ := settings.Setting(config.SocketCAFile)
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load %v: %v", config.SocketCAFile, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("cannot load %v: no certificates found in %v", config.SocketCAFile, caFile)
+		}
+		tlsConfig.RootCAs, tlsConfig.ClientCAs = pool, pool
+	}
+
+	if settings.HasSetting(config.SocketMinimumTLSVersion) {
+		setting, _ := settings.Setting(config.SocketMinimumTLSVersion)
+
+		version, ok := tlsVersions[setting]
+		if !ok {
+			return nil, fmt.Errorf("invalid %v %v, expected TLSv1.0, TLSv1.1, TLSv1.2, or TLSv1.3", config.SocketMinimumTLSVersion, setting)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if settings.HasSetting(config.SocketCipherSuites) {
+		setting, _ := settings.Setting(config.SocketCipherSuites)
+
+		var err error
+		if tlsConfig.CipherSuites, err = parseCipherSuites(setting); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.HasSetting(config.SocketServerName) {
+		tlsConfig.ServerName, _ = settings.Setting(config.SocketServerName)
+	}
+
+	if settings.HasSetting(config.SocketInsecureSkipVerify) {
+		var err error
+		if tlsConfig.InsecureSkipVerify, err = settings.BoolSetting(config.SocketInsecureSkipVerify); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.HasSetting(config.SocketClientAuth) {
+		setting, _ := settings.Setting(config.SocketClientAuth)
+
+		clientAuth, ok := tlsClientAuthTypes[setting]
+		if !ok {
+			return nil, fmt.Errorf("invalid %v %v, expected NoClientCert, RequestClientCert, RequireAnyClientCert, VerifyClientCertIfGiven, or RequireAndVerifyClientCert", config.SocketClientAuth, setting)
+		}
+		tlsConfig.ClientAuth = clientAuth
+	}
+
+	verifier, err := newPeerVerifier(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if verifier != nil {
+		tlsConfig.VerifyConnection = verifier.verify
+	}
+
+	return tlsConfig, nil
+}
+
+//parseCipherSuites maps the SocketCipherSuites setting to the IDs of the cipher suites.
+func parseCipherSuites(setting string) ([]uint16, error) {
+	ids := make(map[string]uint16)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		ids[suite.Name] = suite.ID
+	}
+
+	var suites []uint16
+	for _, name := range strings.Split(setting, ",") {
+		id, ok := ids[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid %v %v, unknown cipher suite %v", config.SocketCipherSuites, setting, name)
+		}
+		suites = append(suites, id)
+	}
+
+	return suites, nil
+}
+
+//settingsKey returns the values of the named settings of a session, equal for sessions with the same values.
+func settingsKey(settings *SessionSettings, names []string) string {
+	var values []string
+	for _, setting := range names {
+		value, _ := settings.Setting(setting)
+		values = append(values, setting+"="+value)
+	}
+
+	return strings.Join(values, "\n")
+}
+
+//tlsClient returns conn secured by tlsConfig once the handshake with the acceptor on host completes.
+func tlsClient(conn net.Conn, tlsConfig *tls.Config, host string) (net.Conn, error) {
+	if tlsConfig.ServerName == "" && !tlsConfig.InsecureSkipVerify {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	tlsConn.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake failed: %v", err)
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	return tlsConn, nil
+}
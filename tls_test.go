@@ -0,0 +1,264 @@
+package quickfix
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//writeTestCertificate writes a self-signed certificate for 127.0.0.1 and its key to dir, returning their files.
+func writeTestCertificate(t *testing.T, dir, name string) (certificateFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificateFile, keyFile = path.Join(dir, name+".crt"), path.Join(dir, name+".key")
+	if err := ioutil.WriteFile(certificateFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+//peerCertificateClient reports the subject of the certificate presented on each connection accepted.
+type peerCertificateClient struct {
+	shutdownClient
+	subjects chan string
+}
+
+func (c *peerCertificateClient) AuthenticatePeerCertificate(sessionID SessionID, logon Message, certificates []*x509.Certificate) error {
+	c.subjects <- certificates[0].Subject.CommonName
+	return nil
+}
+
+//clientCertificateClient presents certificate to acceptors requesting a client certificate.
+type clientCertificateClient struct {
+	shutdownClient
+	certificate *tls.Certificate
+}
+
+func (c *clientCertificateClient) ClientCertificate(sessionID SessionID, info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return c.certificate, nil
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certificateFile, keyFile := writeTestCertificate(t, dir, "acceptor")
+
+	if tlsConfig, err := loadTLSConfig(NewSessionSettings()); err != nil || tlsConfig != nil {
+		t.Errorf("Expected no TLS without settings, got %v %v", tlsConfig, err)
+	}
+
+	settings := NewSessionSettings()
+	settings.Set(config.SocketCertificateFile, certificateFile)
+	settings.Set(config.SocketPrivateKeyFile, keyFile)
+	settings.Set(config.SocketCAFile, certificateFile)
+	settings.Set(config.SocketMinimumTLSVersion, "TLSv1.3")
+	settings.Set(config.SocketCipherSuites, "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384")
+	settings.Set(config.SocketServerName, "fix.example.com")
+	settings.Set(config.SocketClientAuth, "RequireAndVerifyClientCert")
+
+	tlsConfig, err := loadTLSConfig(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tlsConfig.Certificates) != 1 || tlsConfig.RootCAs == nil || tlsConfig.ClientCAs == nil || tlsConfig.MinVersion != tls.VersionTLS13 ||
+		len(tlsConfig.CipherSuites) != 2 || tlsConfig.ServerName != "fix.example.com" || tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("Unexpected TLS configuration %+v", tlsConfig)
+	}
+
+	for key, value := range map[string]string{
+		config.SocketPrivateKeyFile:     path.Join(dir, "missing.key"),
+		config.SocketCAFile:             keyFile,
+		config.SocketMinimumTLSVersion:  "SSLv3",
+		config.SocketCipherSuites:       "TLS_NULL",
+		config.SocketInsecureSkipVerify: "maybe",
+		config.SocketClientAuth:         "Always",
+	} {
+		invalid := settings.clone()
+		invalid.Set(key, value)
+		if _, err := loadTLSConfig(invalid); err == nil {
+			t.Errorf("Expected error for %v=%v", key, value)
+		}
+	}
+}
+
+func TestTLS_MutualLogon(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	acceptorCertificate, acceptorKey := writeTestCertificate(t, dir, "acceptor")
+	initiatorCertificate, initiatorKey := writeTestCertificate(t, dir, "initiator")
+	port := strconv.Itoa(freePort(t))
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, port)
+	acceptorSettings.GlobalSettings().Set(config.SocketCertificateFile, acceptorCertificate)
+	acceptorSettings.GlobalSettings().Set(config.SocketPrivateKeyFile, acceptorKey)
+	acceptorSettings.GlobalSettings().Set(config.SocketCAFile, initiatorCertificate)
+	acceptorSettings.GlobalSettings().Set(config.SocketClientAuth, "RequireAndVerifyClientCert")
+	if _, err := acceptorSettings.AddSession(newTestAcceptorSessionSettings("TLS")); err != nil {
+		t.Fatal(err)
+	}
+
+	acceptorApp := &peerCertificateClient{shutdownClient: shutdownClient{states: make(chan SessionState, 20)}, subjects: make(chan string, 1)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionSettings := newTestAcceptorSessionSettings("TLS_PLAIN")
+	sessionSettings.Set(config.SocketCertificateFile, "")
+	if _, err := acceptor.AddSession(sessionSettings); err == nil {
+		t.Error("Expected error adding a session with different TLS settings on the same address")
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	initiatorSettings := NewSettings()
+	sessionSettings = NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "TLS")
+	sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+	sessionSettings.Set(config.HeartBtInt, "30")
+	sessionSettings.Set(config.SocketConnectHost, "127.0.0.1")
+	sessionSettings.Set(config.SocketConnectPort, port)
+	sessionSettings.Set(config.SocketUseSSL, "Y")
+	sessionSettings.Set(config.SocketCAFile, acceptorCertificate)
+	if _, err := initiatorSettings.AddSession(sessionSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	certificate, err := tls.LoadX509KeyPair(initiatorCertificate, initiatorKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorApp := &clientCertificateClient{shutdownClient: shutdownClient{states: make(chan SessionState, 20)}, certificate: &certificate}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer initiator.Stop()
+
+	awaitState(t, initiatorApp.states, StateLoggedOn)
+	awaitState(t, acceptorApp.states, StateLoggedOn)
+
+	if subject := <-acceptorApp.subjects; subject != "initiator" {
+		t.Errorf("Expected certificate of initiator presented, got %v", subject)
+	}
+}
+
+func TestTLSClient_VerifiesServerName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certificateFile, keyFile := writeTestCertificate(t, dir, "acceptor")
+	certificate, err := tls.LoadX509KeyPair(certificateFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{certificate}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	settings := NewSessionSettings()
+	settings.Set(config.SocketUseSSL, "Y")
+	settings.Set(config.SocketCAFile, certificateFile)
+	tlsConfig, err := loadTLSConfig(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conn, err = tlsClient(conn, tlsConfig, "127.0.0.1"); err != nil {
+		t.Fatalf("Expected handshake with 127.0.0.1 to succeed: %v", err)
+	}
+	conn.Close()
+
+	if conn, err = net.Dial("tcp", listener.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tlsClient(conn, tlsConfig, "fix.example.com"); err == nil {
+		t.Error("Expected handshake with a host not named by the certificate to fail")
+	}
+}
@@ -0,0 +1,234 @@
+package quickfix
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"golang.org/x/crypto/ocsp"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//ocspTimeout bounds each request to an OCSP responder.
+const ocspTimeout = 5 * time.Second
+
+//ocspPolicy determines how the revocation status of the certificate of the counterparty is checked with its OCSP responder.
+type ocspPolicy int
+
+const (
+	//ocspOff does not check the revocation status.
+	ocspOff ocspPolicy = iota
+
+	//ocspSoftFail refuses revoked certificates, certificates whose status cannot be determined are accepted.
+	ocspSoftFail
+
+	//ocspHardFail refuses certificates unless the responder reports them good.
+	ocspHardFail
+)
+
+//parseOCSPPolicy maps the SocketOCSPPolicy setting to an ocspPolicy.
+func parseOCSPPolicy(setting string) (ocspPolicy, error) {
+	switch setting {
+	case "Off":
+		return ocspOff, nil
+	case "SoftFail":
+		return ocspSoftFail, nil
+	case "HardFail":
+		return ocspHardFail, nil
+	}
+
+	return ocspOff, fmt.Errorf("invalid SocketOCSPPolicy %v, expected Off, SoftFail, or HardFail", setting)
+}
+
+//peerVerifier checks the certificate of the counterparty once the TLS handshake has verified it against SocketCAFile.
+type peerVerifier struct {
+	//pins are the SHA-256 fingerprints, in lower case hex, the certificate of the counterparty must match one of, if any
+	pins map[string]bool
+
+	//crls are the revocation lists of SocketCRLFile
+	crls []*x509.RevocationList
+
+	ocsp       ocspPolicy
+	httpClient *http.Client
+}
+
+//newPeerVerifier returns the verifier configured by SocketPinnedCertificates, SocketCRLFile and SocketOCSPPolicy, nil if none are set.
+func newPeerVerifier(settings *SessionSettings) (*peerVerifier, error) {
+	v := &peerVerifier{httpClient: &http.Client{Timeout: ocspTimeout}}
+
+	if settings.HasSetting(config.SocketPinnedCertificates) {
+		setting, _ := settings.Setting(config.SocketPinnedCertificates)
+
+		v.pins = make(map[string]bool)
+		for _, pin := range strings.Split(setting, ",") {
+			pin = strings.ToLower(strings.Replace(strings.TrimSpace(pin), ":", "", -1))
+			if fingerprint, err := hex.DecodeString(pin); err != nil || len(fingerprint) != sha256.Size {
+				return nil, fmt.Errorf("invalid %v %v, expected SHA-256 fingerprints", config.SocketPinnedCertificates, setting)
+			}
+			v.pins[pin] = true
+		}
+	}
+
+	if settings.HasSetting(config.SocketCRLFile) {
+		crlFile, _ := settings.Setting(config.SocketCRLFile)
+
+		var err error
+		if v.crls, err = loadCRLs(crlFile); err != nil {
+			return nil, fmt.Errorf("cannot load %v: %v", config.SocketCRLFile, err)
+		}
+	}
+
+	if settings.HasSetting(config.SocketOCSPPolicy) {
+		setting, _ := settings.Setting(config.SocketOCSPPolicy)
+
+		var err error
+		if v.ocsp, err = parseOCSPPolicy(setting); err != nil {
+			return nil, err
+		}
+	}
+
+	if v.pins == nil && v.crls == nil && v.ocsp == ocspOff {
+		return nil, nil
+	}
+
+	return v, nil
+}
+
+//loadCRLs reads the PEM encoded revocation lists of name, or the single DER encoded list.
+func loadCRLs(name string) ([]*x509.RevocationList, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		crl, err := x509.ParseRevocationList(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*x509.RevocationList{crl}, nil
+	}
+
+	var crls []*x509.RevocationList
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "X509 CRL" {
+			continue
+		}
+
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		crls = append(crls, crl)
+	}
+
+	if len(crls) == 0 {
+		return nil, fmt.Errorf("no revocation lists found in %v", name)
+	}
+
+	return crls, nil
+}
+
+//verify checks the certificates presented by the counterparty, set as the VerifyConnection of the TLS configuration.
+func (v *peerVerifier) verify(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("counterparty presented no certificate")
+	}
+
+	chain := state.PeerCertificates
+	if len(state.VerifiedChains) > 0 {
+		chain = state.VerifiedChains[0]
+	}
+	leaf := chain[0]
+
+	if v.pins != nil {
+		fingerprint := sha256.Sum256(leaf.Raw)
+		if !v.pins[hex.EncodeToString(fingerprint[:])] {
+			return fmt.Errorf("certificate %v does not match a pinned fingerprint", leaf.Subject)
+		}
+	}
+
+	for _, cert := range chain {
+		for _, crl := range v.crls {
+			if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) {
+				continue
+			}
+
+			for _, revoked := range crl.RevokedCertificateEntries {
+				if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+					return fmt.Errorf("certificate %v is revoked", cert.Subject)
+				}
+			}
+		}
+	}
+
+	if v.ocsp == ocspOff {
+		return nil
+	}
+
+	status, err := v.ocspStatus(leaf, chain, state.OCSPResponse)
+	switch {
+	case status == ocsp.Revoked:
+		return fmt.Errorf("certificate %v is revoked", leaf.Subject)
+	case err != nil && v.ocsp == ocspHardFail:
+		return fmt.Errorf("cannot check revocation of certificate %v: %v", leaf.Subject, err)
+	case err == nil && status != ocsp.Good && v.ocsp == ocspHardFail:
+		return fmt.Errorf("revocation of certificate %v unknown to responder", leaf.Subject)
+	}
+
+	return nil
+}
+
+//ocspStatus returns the status of leaf in the stapled response, otherwise from the responder of leaf.
+func (v *peerVerifier) ocspStatus(leaf *x509.Certificate, chain []*x509.Certificate, stapled []byte) (int, error) {
+	if len(chain) < 2 {
+		return ocsp.Unknown, fmt.Errorf("issuer not presented")
+	}
+	issuer := chain[1]
+
+	if len(stapled) > 0 {
+		response, err := ocsp.ParseResponseForCert(stapled, leaf, issuer)
+		if err != nil {
+			return ocsp.Unknown, err
+		}
+		return response.Status, nil
+	}
+
+	if len(leaf.OCSPServer) == 0 {
+		return ocsp.Unknown, fmt.Errorf("no OCSP responder")
+	}
+
+	request, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return ocsp.Unknown, err
+	}
+
+	httpResponse, err := v.httpClient.Post(leaf.OCSPServer[0], "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return ocsp.Unknown, err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return ocsp.Unknown, fmt.Errorf("OCSP responder returned %v", httpResponse.Status)
+	}
+
+	body, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return ocsp.Unknown, err
+	}
+
+	response, err := ocsp.ParseResponseForCert(body, leaf, issuer)
+	if err != nil {
+		return ocsp.Unknown, err
+	}
+
+	return response.Status, nil
+}
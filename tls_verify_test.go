@@ -0,0 +1,239 @@
+package quickfix
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"github.com/quickfixgo/quickfix/config"
+	"golang.org/x/crypto/ocsp"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+//newTestCA returns a CA certificate, and issue returns leaf certificates signed by it with ocspServer as their responder.
+func newTestCA(t *testing.T) (ca testCertificate, issue func(serial int64, ocspServer string) testCertificate) {
+	create := func(template *x509.Certificate, parent testCertificate) testCertificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signer := crypto.Signer(key)
+		if parent.cert == nil {
+			parent = testCertificate{cert: template, key: key}
+		} else {
+			signer = parent.key
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, parent.cert, &key.PublicKey, signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return testCertificate{cert: cert, key: key}
+	}
+
+	ca = create(&x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, testCertificate{})
+
+	issue = func(serial int64, ocspServer string) testCertificate {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "counterparty"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+		}
+		if ocspServer != "" {
+			template.OCSPServer = []string{ocspServer}
+		}
+
+		return create(template, ca)
+	}
+
+	return
+}
+
+func TestParseOCSPPolicy(t *testing.T) {
+	for setting, expected := range map[string]ocspPolicy{"Off": ocspOff, "SoftFail": ocspSoftFail, "HardFail": ocspHardFail} {
+		if policy, err := parseOCSPPolicy(setting); err != nil || policy != expected {
+			t.Errorf("Expected %v for %v, got %v %v", expected, setting, policy, err)
+		}
+	}
+
+	if _, err := parseOCSPPolicy("Strict"); err == nil {
+		t.Error("Expected error for Strict")
+	}
+}
+
+func TestNewPeerVerifier_Settings(t *testing.T) {
+	if verifier, err := newPeerVerifier(NewSessionSettings()); err != nil || verifier != nil {
+		t.Errorf("Expected no verifier without settings, got %v %v", verifier, err)
+	}
+
+	for key, value := range map[string]string{
+		config.SocketPinnedCertificates: "AB:CD",
+		config.SocketCRLFile:            "/nonexistent.crl",
+		config.SocketOCSPPolicy:         "Always",
+	} {
+		settings := NewSessionSettings()
+		settings.Set(key, value)
+		if _, err := newPeerVerifier(settings); err == nil {
+			t.Errorf("Expected error for %v=%v", key, value)
+		}
+	}
+}
+
+func TestPeerVerifier_Pins(t *testing.T) {
+	ca, issue := newTestCA(t)
+	leaf := issue(2, "")
+
+	fingerprint := sha256.Sum256(leaf.cert.Raw)
+	settings := NewSessionSettings()
+	settings.Set(config.SocketPinnedCertificates, "00, "+hex.EncodeToString(fingerprint[:]))
+	if _, err := newPeerVerifier(settings); err == nil {
+		t.Error("Expected error for a fingerprint that is not SHA-256")
+	}
+
+	settings.Set(config.SocketPinnedCertificates, strings.ToUpper(hex.EncodeToString(fingerprint[:])))
+	verifier, err := newPeerVerifier(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifier.verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf.cert, ca.cert}}); err != nil {
+		t.Errorf("Expected pinned certificate accepted: %v", err)
+	}
+
+	if err := verifier.verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{issue(3, "").cert}}); err == nil {
+		t.Error("Expected certificate not pinned refused")
+	}
+
+	if err := verifier.verify(tls.ConnectionState{}); err == nil {
+		t.Error("Expected connection without certificate refused")
+	}
+}
+
+func TestPeerVerifier_CRL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "crl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, issue := newTestCA(t)
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now(),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{{SerialNumber: big.NewInt(3), RevocationTime: time.Now()}},
+	}, ca.cert, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crlFile := path.Join(dir, "ca.crl")
+	if err := ioutil.WriteFile(crlFile, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := NewSessionSettings()
+	settings.Set(config.SocketCRLFile, crlFile)
+	verifier, err := newPeerVerifier(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifier.verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{issue(2, "").cert, ca.cert}}); err != nil {
+		t.Errorf("Expected certificate not revoked accepted: %v", err)
+	}
+
+	if err := verifier.verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{issue(3, "").cert, ca.cert}}); err == nil {
+		t.Error("Expected revoked certificate refused")
+	}
+}
+
+func TestPeerVerifier_OCSP(t *testing.T) {
+	ca, issue := newTestCA(t)
+
+	statuses := map[int64]int{2: ocsp.Good, 3: ocsp.Revoked, 4: ocsp.Unknown}
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		request, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		response, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       statuses[request.SerialNumber.Int64()],
+			SerialNumber: request.SerialNumber,
+			ThisUpdate:   time.Now(),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}, ca.key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(response)
+	}))
+	defer responder.Close()
+
+	for _, test := range []struct {
+		policy   string
+		serial   int64
+		server   string
+		accepted bool
+	}{
+		{"HardFail", 2, responder.URL, true},
+		{"HardFail", 3, responder.URL, false},
+		{"HardFail", 4, responder.URL, false},
+		{"HardFail", 2, "", false},
+		{"SoftFail", 3, responder.URL, false},
+		{"SoftFail", 4, responder.URL, true},
+		{"SoftFail", 2, "", true},
+	} {
+		settings := NewSessionSettings()
+		settings.Set(config.SocketOCSPPolicy, test.policy)
+		verifier, err := newPeerVerifier(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = verifier.verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{issue(test.serial, test.server).cert, ca.cert}})
+		if accepted := err == nil; accepted != test.accepted {
+			t.Errorf("%v serial %v responder %q: expected accepted %v, got %v", test.policy, test.serial, test.server, test.accepted, err)
+		}
+	}
+}
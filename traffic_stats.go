@@ -0,0 +1,131 @@
+package quickfix
+
+import (
+	"sync"
+	"time"
+)
+
+//TrafficRateWindow is the period TrafficStats.Rates are averaged over.
+const TrafficRateWindow = 10 * time.Second
+
+//trafficRateBuckets is the number of one second buckets of TrafficRateWindow.
+const trafficRateBuckets = int(TrafficRateWindow / time.Second)
+
+//TrafficCounters count the FIX messages received from and sent to the counterparty, and their bytes.
+type TrafficCounters struct {
+	BytesIn, BytesOut       int64
+	MessagesIn, MessagesOut int64
+}
+
+func (c *TrafficCounters) add(bytesIn, bytesOut, messagesIn, messagesOut int64) {
+	c.BytesIn += bytesIn
+	c.BytesOut += bytesOut
+	c.MessagesIn += messagesIn
+	c.MessagesOut += messagesOut
+}
+
+//TrafficRates are the bytes and messages per second received and sent over the last TrafficRateWindow.
+type TrafficRates struct {
+	BytesIn, BytesOut       float64
+	MessagesIn, MessagesOut float64
+}
+
+//TrafficStats are the traffic of a session with its counterparty.
+type TrafficStats struct {
+	//ConnectedTime is the time the current or last connection was established, zero if never connected.
+	ConnectedTime time.Time
+
+	//Connection counts the traffic of the current or last connection, Total the traffic since the session was created.
+	Connection, Total TrafficCounters
+
+	//Rates are the rates of the traffic over the last TrafficRateWindow.
+	Rates TrafficRates
+}
+
+//trafficStats records the traffic of a session.
+type trafficStats struct {
+	lock              sync.Mutex
+	connectedTime     time.Time
+	connection, total TrafficCounters
+
+	//buckets count the traffic of each of the last seconds, by the unix time of the second modulo trafficRateBuckets
+	buckets [trafficRateBuckets]struct {
+		second int64
+		TrafficCounters
+	}
+}
+
+//connect starts counting the traffic of a connection established at now.
+func (t *trafficStats) connect(now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.connectedTime = now
+	t.connection = TrafficCounters{}
+}
+
+//recordIn records a message of size bytes received at now.
+func (t *trafficStats) recordIn(size int, now time.Time) {
+	t.record(int64(size), 0, 1, 0, now)
+}
+
+//recordOut records a message of size bytes sent at now.
+func (t *trafficStats) recordOut(size int, now time.Time) {
+	t.record(0, int64(size), 0, 1, now)
+}
+
+func (t *trafficStats) record(bytesIn, bytesOut, messagesIn, messagesOut int64, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.connection.add(bytesIn, bytesOut, messagesIn, messagesOut)
+	t.total.add(bytesIn, bytesOut, messagesIn, messagesOut)
+
+	second := now.Unix()
+	bucket := &t.buckets[second%int64(trafficRateBuckets)]
+	if bucket.second != second {
+		bucket.second, bucket.TrafficCounters = second, TrafficCounters{}
+	}
+	bucket.add(bytesIn, bytesOut, messagesIn, messagesOut)
+}
+
+//snapshot returns the stats at now.
+func (t *trafficStats) snapshot(now time.Time) TrafficStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	stats := TrafficStats{ConnectedTime: t.connectedTime, Connection: t.connection, Total: t.total}
+
+	var window TrafficCounters
+	for _, bucket := range t.buckets {
+		if age := now.Unix() - bucket.second; age >= 0 && age < int64(trafficRateBuckets) {
+			window.add(bucket.BytesIn, bucket.BytesOut, bucket.MessagesIn, bucket.MessagesOut)
+		}
+	}
+
+	seconds := TrafficRateWindow.Seconds()
+	stats.Rates = TrafficRates{
+		BytesIn:     float64(window.BytesIn) / seconds,
+		BytesOut:    float64(window.BytesOut) / seconds,
+		MessagesIn:  float64(window.MessagesIn) / seconds,
+		MessagesOut: float64(window.MessagesOut) / seconds,
+	}
+
+	return stats
+}
+
+//TrafficStats returns the traffic of the session with its counterparty.
+//Safe to call outside the session goroutine.
+func (s *Session) TrafficStats() TrafficStats {
+	return s.trafficStats.snapshot(s.now())
+}
+
+//LookupTrafficStats returns the traffic of the session with sessionID.
+func LookupTrafficStats(sessionID SessionID) (TrafficStats, error) {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return TrafficStats{}, err
+	}
+
+	return session.TrafficStats(), nil
+}
@@ -0,0 +1,58 @@
+package quickfix
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTrafficStats(t *testing.T) {
+	var stats trafficStats
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	stats.connect(start)
+	stats.recordIn(100, start)
+	stats.recordOut(200, start.Add(time.Second))
+	stats.recordOut(300, start.Add(5*time.Second))
+
+	snapshot := stats.snapshot(start.Add(5 * time.Second))
+	expected := TrafficCounters{BytesIn: 100, BytesOut: 500, MessagesIn: 1, MessagesOut: 2}
+	if snapshot.ConnectedTime != start || snapshot.Connection != expected || snapshot.Total != expected {
+		t.Errorf("Expected %+v since %v, got %+v", expected, start, snapshot)
+	}
+
+	if rates := (TrafficRates{BytesIn: 10, BytesOut: 50, MessagesIn: 0.1, MessagesOut: 0.2}); snapshot.Rates != rates {
+		t.Errorf("Expected rates %+v, got %+v", rates, snapshot.Rates)
+	}
+
+	//the traffic of the first second is outside the window
+	if rates := stats.snapshot(start.Add(TrafficRateWindow)).Rates; rates != (TrafficRates{BytesOut: 50, MessagesOut: 0.2}) {
+		t.Errorf("Expected traffic older than the window excluded, got %+v", rates)
+	}
+
+	reconnected := start.Add(time.Minute)
+	stats.connect(reconnected)
+	stats.recordIn(50, reconnected)
+
+	snapshot = stats.snapshot(reconnected)
+	if snapshot.Connection != (TrafficCounters{BytesIn: 50, MessagesIn: 1}) || snapshot.Total != (TrafficCounters{BytesIn: 150, BytesOut: 500, MessagesIn: 2, MessagesOut: 2}) {
+		t.Errorf("Expected connection counters reset on connect, got %+v", snapshot)
+	}
+
+	if snapshot.Rates != (TrafficRates{BytesIn: 5, MessagesIn: 0.1}) {
+		t.Errorf("Expected bucket of an earlier minute replaced, got %+v", snapshot.Rates)
+	}
+}
+
+func TestSession_TrafficStats(t *testing.T) {
+	session := &Session{log: nullLog{}, messageOut: make(chan []byte, 1), stateTimer: eventTimer{Task: func() {}}}
+	session.trafficStats.connect(time.Now())
+
+	if err := session.sendBytesCtx(context.Background(), []byte("8=FIX.4.2|35=0|")); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := session.TrafficStats(); stats.Connection.MessagesOut != 1 || stats.Connection.BytesOut != 15 {
+		t.Errorf("Expected message sent counted, got %+v", stats)
+	}
+}
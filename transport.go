@@ -0,0 +1,148 @@
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+//Dialer opens the connections of initiated sessions to address on network, such as net.Dialer.DialContext.
+//Custom dialers layer sessions over other transports, tunnels or in-memory pipes.
+type Dialer func(ctx context.Context, network, address string) (net.Conn, error)
+
+//ListenerFactory opens the listeners of an Acceptor on address on network, such as net.Listen.
+type ListenerFactory func(network, address string) (net.Listener, error)
+
+//defaultDialer connects over the network of the host.
+func defaultDialer(ctx context.Context, network, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, address)
+}
+
+//SetDialer sets the Dialer connecting the sessions of the Initiator started from now on, the host and port are passed to dialer unresolved.
+//A nil dialer restores connecting over the network of the host. Connections are secured by TLS on top of dialer if configured.
+func (i *Initiator) SetDialer(dialer Dialer) {
+	i.sessionLock.Lock()
+	defer i.sessionLock.Unlock()
+
+	i.dialer = dialer
+}
+
+//SetListenerFactory sets the ListenerFactory opening the listeners of the Acceptor when started.
+//A nil factory restores listening on the network of the host. Listeners are secured by TLS on top of factory if configured.
+func (a *Acceptor) SetListenerFactory(factory ListenerFactory) {
+	a.sessionLock.Lock()
+	defer a.sessionLock.Unlock()
+
+	a.listenerFactory = factory
+}
+
+//unixSocketPrefix prefixes the path of a unix domain socket given as SocketConnectHost or SocketAcceptHost.
+const unixSocketPrefix = "unix:"
+
+func isUnixSocket(host string) bool {
+	return strings.HasPrefix(host, unixSocketPrefix)
+}
+
+//transportAddress returns the network and address of a host and port, or of the path of a unix domain socket.
+func transportAddress(address string) (network, addr string) {
+	if isUnixSocket(address) {
+		return "unix", strings.TrimPrefix(address, unixSocketPrefix)
+	}
+
+	return "tcp", address
+}
+
+//PipeTransport connects initiators and acceptors within a process over in-memory pipes, without a network.
+//Its Listen is the ListenerFactory of acceptors and its Dial the Dialer of initiators, any address may be listened on.
+type PipeTransport struct {
+	lock      sync.Mutex
+	listeners map[string]*pipeListener
+}
+
+//NewPipeTransport returns a PipeTransport without listeners.
+func NewPipeTransport() *PipeTransport {
+	return &PipeTransport{listeners: make(map[string]*pipeListener)}
+}
+
+//Listen returns a listener accepting the connections dialed to address, until closed.
+func (t *PipeTransport) Listen(network, address string) (net.Listener, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.listeners[address]; ok {
+		return nil, fmt.Errorf("listen %v %v: address already in use", network, address)
+	}
+
+	listener := &pipeListener{transport: t, addr: pipeAddr(address), conns: make(chan net.Conn), closed: make(chan struct{})}
+	t.listeners[address] = listener
+	return listener, nil
+}
+
+//Dial connects to the listener on address, once accepted.
+func (t *PipeTransport) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	t.lock.Lock()
+	listener, ok := t.listeners[address]
+	t.lock.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("dial %v %v: connection refused", network, address)
+	}
+
+	client, server := net.Pipe()
+	select {
+	case listener.conns <- server:
+		return client, nil
+	case <-listener.closed:
+		return nil, fmt.Errorf("dial %v %v: connection refused", network, address)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+//pipeListener accepts the connections dialed to its address on a PipeTransport.
+type pipeListener struct {
+	transport *PipeTransport
+	addr      pipeAddr
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, fmt.Errorf("accept %v: listener closed", l.addr)
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.closeOnce.Do(func() {
+		l.transport.lock.Lock()
+		delete(l.transport.listeners, string(l.addr))
+		l.transport.lock.Unlock()
+
+		close(l.closed)
+	})
+
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return l.addr
+}
+
+//pipeAddr is the address of a pipeListener.
+type pipeAddr string
+
+func (a pipeAddr) Network() string {
+	return "pipe"
+}
+
+func (a pipeAddr) String() string {
+	return string(a)
+}
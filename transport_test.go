@@ -0,0 +1,165 @@
+package quickfix
+
+import (
+	"context"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestTransport_InMemoryLogon(t *testing.T) {
+	transport := NewPipeTransport()
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptHost, "fix.internal")
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, "5001")
+	if _, err := acceptorSettings.AddSession(newTestAcceptorSessionSettings("PIPE")); err != nil {
+		t.Fatal(err)
+	}
+
+	acceptorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listenedOn string
+	acceptor.SetListenerFactory(func(network, address string) (net.Listener, error) {
+		listenedOn = network + " " + address
+		return transport.Listen(network, address)
+	})
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	if listenedOn != "tcp fix.internal:5001" {
+		t.Errorf("Expected listener opened for tcp fix.internal:5001, got %v", listenedOn)
+	}
+
+	initiatorSettings := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "PIPE")
+	sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+	sessionSettings.Set(config.HeartBtInt, "30")
+	sessionSettings.Set(config.SocketConnectHost, "fix.internal")
+	sessionSettings.Set(config.SocketConnectPort, "5001")
+	if _, err := initiatorSettings.AddSession(sessionSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	initiator.SetDialer(transport.Dial)
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer initiator.Stop()
+
+	awaitState(t, initiatorApp.states, StateLoggedOn)
+	awaitState(t, acceptorApp.states, StateLoggedOn)
+}
+
+func TestPipeTransport(t *testing.T) {
+	transport := NewPipeTransport()
+	if _, err := transport.Dial(context.Background(), "tcp", "fix.internal:5001"); err == nil {
+		t.Error("Expected dial without listener refused")
+	}
+
+	listener, err := transport.Listen("tcp", "fix.internal:5001")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := transport.Listen("tcp", "fix.internal:5001"); err == nil {
+		t.Error("Expected error listening twice on an address")
+	}
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("8=FIX.4.2"))
+		conn.Close()
+	}()
+
+	conn, err := transport.Dial(context.Background(), "tcp", "fix.internal:5001")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ := ioutil.ReadAll(conn); string(b) != "8=FIX.4.2" {
+		t.Errorf("Expected bytes written by the acceptor, got %q", b)
+	}
+
+	listener.Close()
+	if _, err := transport.Dial(context.Background(), "tcp", "fix.internal:5001"); err == nil {
+		t.Error("Expected dial after close refused")
+	}
+
+	if _, err := transport.Listen("tcp", "fix.internal:5001"); err != nil {
+		t.Errorf("Expected address listened on again after close: %v", err)
+	}
+}
+
+func TestTransport_UnixSocketLogon(t *testing.T) {
+	dir, err := ioutil.TempDir("", "unix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := "unix:" + path.Join(dir, "fix.sock")
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptHost, socket)
+	if _, err := acceptorSettings.AddSession(newTestAcceptorSessionSettings("UNIX")); err != nil {
+		t.Fatal(err)
+	}
+
+	acceptorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	initiatorSettings := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, "FIX.4.2")
+	sessionSettings.Set(config.SenderCompID, "UNIX")
+	sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+	sessionSettings.Set(config.HeartBtInt, "30")
+	sessionSettings.Set(config.SocketConnectHost, socket)
+	if _, err := initiatorSettings.AddSession(sessionSettings); err != nil {
+		t.Fatal(err)
+	}
+
+	initiatorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer initiator.Stop()
+
+	awaitState(t, initiatorApp.states, StateLoggedOn)
+	awaitState(t, acceptorApp.states, StateLoggedOn)
+}
@@ -6,6 +6,12 @@ import (
 	"github.com/quickfixgo/quickfix/fix/tag"
 )
 
+//Validate tests the message against dict, as the messages received by a session with a DataDictionary, returning the reject of the
+//first field failing validation.
+func (m *Message) Validate(dict *datadictionary.DataDictionary) MessageRejectError {
+	return validate(dict, *m)
+}
+
 //validate tests the message against the provided data dictionary.
 func validate(d *datadictionary.DataDictionary, msg Message) MessageRejectError {
 	msgType := new(fix.StringField)
@@ -129,9 +135,12 @@ func validateVisitField(fieldDef *datadictionary.FieldDef, fields []fieldBytes)
 	var err MessageRejectError
 
 	if fieldDef.IsGroup() {
+		//the group is visited to the field following it
 		if fields, err = validateVisitGroupField(fieldDef, fields); err != nil {
 			return nil, err
 		}
+
+		return fields, nil
 	}
 
 	return fields[1:], nil
@@ -5,6 +5,7 @@ import (
 	"github.com/quickfixgo/quickfix/fix"
 	"github.com/quickfixgo/quickfix/fix/tag"
 	. "gopkg.in/check.v1"
+	"testing"
 	"time"
 )
 
@@ -333,3 +334,44 @@ func (s *ValidationTests) TestValidateVisitFieldGroup(c *C) {
 	c.Check(reject, NotNil)
 	c.Check(reject.RejectReason(), Equals, rejectReasonIncorrectNumInGroupCountForRepeatingGroup)
 }
+
+func TestMessage_Validate(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX43.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgBytes, _ := (&ValidationTests{}).createFIX43NewOrderSingle().Build()
+	msg, _ := parseMessage(msgBytes)
+	if reject := msg.Validate(dict); reject != nil {
+		t.Errorf("Expected valid, got %v", reject)
+	}
+
+	builder := (&ValidationTests{}).createFIX43NewOrderSingle()
+	builder.Body().Set(fix.NewStringField(tag.Side, ""))
+	msgBytes, _ = builder.Build()
+	msg, _ = parseMessage(msgBytes)
+
+	if reject := msg.Validate(dict); reject == nil || *reject.RefTagID() != tag.Side {
+		t.Errorf("Expected Side rejected, got %v", reject)
+	}
+}
+
+func TestMessage_ValidateNestedGroupFollowedByField(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX44.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//the NoPartySubIDs group ends the NoPartyIDs entry, followed by the fields of the NoOrders entry
+	msg, err := parseMessage(rawMessage("FIX.4.4", "35=E\00149=TW\00156=ISLD\00134=1\00152=20160803-12:30:15.123\001"+
+		"66=LIST-1\001394=3\00168=1\00173=1\001"+
+		"11=ORDER-1\00167=1\001453=1\001448=PARTY-1\001447=D\001452=1\001802=1\001523=SUB-1\001803=1\00155=MSFT\00154=1\00138=100\001"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reject := msg.Validate(dict); reject != nil {
+		t.Errorf("Expected valid, got %v", reject)
+	}
+}
@@ -0,0 +1,150 @@
+//Package vaultsecrets provides a QuickFIX/Go SecretsProvider reading secrets from the KV version 2 secrets engine of HashiCorp Vault.
+//A reference secret://<path>/<key> is the key of the secret at path, Password=secret://fix/venueA/password being the password key
+//of the secret fix/venueA.
+package vaultsecrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+//ProviderType is the SecretsProviderType of the provider, registered with quickfix.RegisterSecretsProvider when the package is imported.
+const ProviderType = "vault"
+
+//defaultMount is the path the KV secrets engine is mounted at without VaultMount, as in a Vault dev server.
+const defaultMount = "secret"
+
+func init() {
+	quickfix.RegisterSecretsProvider(ProviderType, NewProvider)
+}
+
+//Provider is a quickfix.SecretsProvider reading secrets from the KV version 2 secrets engine of a Vault.
+type Provider struct {
+	address, mount, namespace string
+
+	//token returns the token authenticating each request
+	token func() (string, error)
+
+	//Client makes the requests to Vault, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+//NewProvider returns a Provider reading secrets from the Vault at VaultAddress, or VAULT_ADDR if not set, from the secrets engine
+//mounted at VaultMount, secret by default, in the namespace VaultNamespace if set. Requests are authenticated by the token read from
+//VaultTokenFile, read again for each secret so a token renewed by Vault Agent is used, or VAULT_TOKEN if not set.
+func NewProvider(settings *quickfix.SessionSettings) (quickfix.SecretsProvider, error) {
+	p := &Provider{address: os.Getenv("VAULT_ADDR"), mount: defaultMount}
+
+	if settings.HasSetting(config.VaultAddress) {
+		p.address, _ = settings.Setting(config.VaultAddress)
+	}
+
+	if p.address == "" {
+		return nil, fmt.Errorf("missing configuration: %v", config.VaultAddress)
+	}
+	p.address = strings.TrimSuffix(p.address, "/")
+
+	if settings.HasSetting(config.VaultMount) {
+		p.mount, _ = settings.Setting(config.VaultMount)
+	}
+
+	if settings.HasSetting(config.VaultNamespace) {
+		p.namespace, _ = settings.Setting(config.VaultNamespace)
+	}
+
+	if settings.HasSetting(config.VaultTokenFile) {
+		tokenFile, _ := settings.Setting(config.VaultTokenFile)
+		p.token = func() (string, error) {
+			token, err := ioutil.ReadFile(tokenFile)
+			if err != nil {
+				return "", fmt.Errorf("cannot read %v: %v", config.VaultTokenFile, err)
+			}
+			return strings.TrimSpace(string(token)), nil
+		}
+	} else {
+		token := os.Getenv("VAULT_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("missing configuration: %v or VAULT_TOKEN", config.VaultTokenFile)
+		}
+		p.token = func() (string, error) { return token, nil }
+	}
+
+	return p, nil
+}
+
+//secretResponse is the response of Vault reading a secret of a KV version 2 secrets engine.
+type secretResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+//Secret returns the value of the key of the secret of a reference <path>/<key>.
+func (p *Provider) Secret(ctx context.Context, reference string) (string, error) {
+	i := strings.LastIndex(reference, "/")
+	if i <= 0 || i == len(reference)-1 {
+		return "", fmt.Errorf("invalid reference %v, expected <path>/<key>", reference)
+	}
+	path, key := reference[:i], reference[i+1:]
+
+	token, err := p.token()
+	if err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/v1/%v/data/%v", p.address, p.mount, strings.Join(segments, "/")), nil)
+	if err != nil {
+		return "", err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("X-Vault-Token", token)
+	if p.namespace != "" {
+		request.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var secret secretResponse
+	if err := json.NewDecoder(response.Body).Decode(&secret); err != nil && response.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("invalid response reading %v: %v", path, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot read %v: %v %v", path, response.Status, strings.Join(secret.Errors, ", "))
+	}
+
+	value, ok := secret.Data.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %v has no key %v", path, key)
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("key %v of secret %v is not a string", key, path)
+	}
+
+	return s, nil
+}
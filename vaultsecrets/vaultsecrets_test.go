@@ -0,0 +1,115 @@
+package vaultsecrets
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//fakeVault serves the secrets of a KV version 2 secrets engine mounted at secret, to requests with token.
+func fakeVault(token string, secrets map[string]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+
+		data, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {}})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": data}})
+	}))
+}
+
+func TestProvider_Secret(t *testing.T) {
+	server := fakeVault("s.token", map[string]map[string]interface{}{
+		"/v1/secret/data/fix/venueA": {"password": "hunter2", "attempts": 3},
+	})
+	defer server.Close()
+
+	os.Setenv("VAULT_TOKEN", "s.token")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	settings := quickfix.NewSessionSettings()
+	settings.Set(config.VaultAddress, server.URL)
+	provider, err := NewProvider(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret, err := provider.Secret(context.Background(), "fix/venueA/password"); err != nil || secret != "hunter2" {
+		t.Errorf("Expected hunter2, got %v %v", secret, err)
+	}
+
+	for _, reference := range []string{"fix/venueA/username", "fix/venueA/attempts", "fix/venueB/password", "password", "fix/venueA/"} {
+		if _, err := provider.Secret(context.Background(), reference); err == nil {
+			t.Errorf("Expected error reading %v", reference)
+		}
+	}
+}
+
+func TestProvider_TokenFile(t *testing.T) {
+	server := fakeVault("s.renewed", map[string]map[string]interface{}{
+		"/v1/kv/data/fix/venueA": {"password": "hunter2"},
+	})
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "vaultsecrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("s.expired\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := quickfix.NewSessionSettings()
+	settings.Set(config.VaultAddress, server.URL)
+	settings.Set(config.VaultMount, "kv")
+	settings.Set(config.VaultTokenFile, tokenFile)
+	provider, err := NewProvider(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Secret(context.Background(), "fix/venueA/password"); err == nil {
+		t.Error("Expected error with expired token")
+	}
+
+	if err := ioutil.WriteFile(tokenFile, []byte("s.renewed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if secret, err := provider.Secret(context.Background(), "fix/venueA/password"); err != nil || secret != "hunter2" {
+		t.Errorf("Expected renewed token used, got %v %v", secret, err)
+	}
+}
+
+func TestNewProvider_Settings(t *testing.T) {
+	os.Unsetenv("VAULT_ADDR")
+	os.Unsetenv("VAULT_TOKEN")
+
+	if _, err := NewProvider(quickfix.NewSessionSettings()); err == nil {
+		t.Error("Expected error without VaultAddress")
+	}
+
+	settings := quickfix.NewSessionSettings()
+	settings.Set(config.VaultAddress, "https://vault.example.com:8200")
+	if _, err := NewProvider(settings); err == nil {
+		t.Error("Expected error without token")
+	}
+}
@@ -0,0 +1,378 @@
+//Package webhook provides a publisher POSTing the lifecycle events and anomalies of QuickFIX/Go sessions as JSON to webhook URLs,
+//for alerting stacks ingesting webhooks rather than metrics.
+//
+//	publisher, err := webhook.NewPublisher(settings)
+//	...
+//	publisher.Start()
+//	defer publisher.Stop(context.Background())
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxRetries    = 3
+	defaultRetryInterval = time.Second
+	defaultQueueDepth    = 1000
+	defaultTimeout       = 10 * time.Second
+
+	//HeaderSignature is the header of the HMAC-SHA256 of the body keyed by WebhookSecret, as sha256=<hex>.
+	HeaderSignature = "X-Quickfix-Signature"
+
+	//HeaderEvent is the header of the Type of the event.
+	HeaderEvent = "X-Quickfix-Event"
+)
+
+//Types of the events published.
+const (
+	//EventLogon is a session logged on.
+	EventLogon = "logon"
+
+	//EventLogout is a session sending or answering a Logout.
+	EventLogout = "logout"
+
+	//EventDisconnect is a session disconnected, Reason is the cause of a disconnect not following a Logout.
+	EventDisconnect = "disconnect"
+
+	//EventAnomaly is a quickfix.Anomaly of a session, such as a sequence gap or logons rejected, of the kind Anomaly.
+	EventAnomaly = "anomaly"
+)
+
+//Event is the JSON body POSTed for each event.
+type Event struct {
+	Type      string    `json:"type"`
+	SessionID string    `json:"sessionID"`
+	Time      time.Time `json:"time"`
+
+	//From and To are the SessionStates of the session before and after a logon, logout or disconnect.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	//Anomaly and Count are the Kind and Count of the quickfix.Anomaly of an anomaly event.
+	Anomaly string `json:"anomaly,omitempty"`
+	Count   int    `json:"count,omitempty"`
+
+	//Reason is the cause of a disconnect, or the error of an anomaly.
+	Reason string `json:"reason,omitempty"`
+}
+
+//Publisher POSTs the events of every session to the URLs of WebhookURLs. Events are queued, and POSTed in order by a goroutine,
+//so the sessions are not blocked by the webhooks.
+type Publisher struct {
+	urls          []string
+	secret        []byte
+	maxRetries    int
+	retryInterval time.Duration
+
+	//Client makes the requests, http.DefaultClient if nil.
+	Client *http.Client
+
+	//Timeout limits each request, 10 seconds if zero.
+	Timeout time.Duration
+
+	//OnError, if set, is called each time an event cannot be POSTed to a URL after its retries, or is dropped with the queue full.
+	OnError func(err error)
+
+	//Now returns the time of events, time.Now if nil.
+	Now func() time.Time
+
+	lock    sync.Mutex
+	started bool
+	stopped bool
+	queue   chan Event
+
+	//quit is closed once Stop gives up on the events queued
+	quit chan struct{}
+	done chan struct{}
+}
+
+//NewPublisher returns a Publisher POSTing to the comma separated WebhookURLs of the global settings, each request signed with an
+//HMAC-SHA256 of its body keyed by WebhookSecret if set. A request failing, or answered with other than 2xx, is retried WebhookMaxRetries
+//times, 3 by default, after WebhookRetryInterval seconds, 1 by default, doubling on each retry. Up to WebhookQueueDepth events are queued,
+//1000 by default, those over it are dropped.
+func NewPublisher(settings *quickfix.Settings) (*Publisher, error) {
+	globalSettings := settings.GlobalSettings()
+
+	urls, err := globalSettings.Setting(config.WebhookURLs)
+	if err != nil {
+		return nil, fmt.Errorf("missing configuration: %v", config.WebhookURLs)
+	}
+
+	p := &Publisher{maxRetries: defaultMaxRetries, retryInterval: defaultRetryInterval}
+	for _, url := range strings.Split(urls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			p.urls = append(p.urls, url)
+		}
+	}
+
+	if len(p.urls) == 0 {
+		return nil, fmt.Errorf("missing configuration: %v", config.WebhookURLs)
+	}
+
+	if globalSettings.HasSetting(config.WebhookSecret) {
+		secret, _ := globalSettings.Setting(config.WebhookSecret)
+		p.secret = []byte(secret)
+	}
+
+	if globalSettings.HasSetting(config.WebhookMaxRetries) {
+		if p.maxRetries, err = globalSettings.IntSetting(config.WebhookMaxRetries); err != nil {
+			return nil, err
+		}
+
+		if p.maxRetries < 0 {
+			return nil, fmt.Errorf("%v must not be negative", config.WebhookMaxRetries)
+		}
+	}
+
+	if globalSettings.HasSetting(config.WebhookRetryInterval) {
+		seconds, err := globalSettings.IntSetting(config.WebhookRetryInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		if seconds <= 0 {
+			return nil, fmt.Errorf("%v must be a positive number", config.WebhookRetryInterval)
+		}
+		p.retryInterval = time.Duration(seconds) * time.Second
+	}
+
+	queueDepth := defaultQueueDepth
+	if globalSettings.HasSetting(config.WebhookQueueDepth) {
+		if queueDepth, err = globalSettings.IntSetting(config.WebhookQueueDepth); err != nil {
+			return nil, err
+		}
+
+		if queueDepth <= 0 {
+			return nil, fmt.Errorf("%v must be a positive number", config.WebhookQueueDepth)
+		}
+	}
+
+	p.queue = make(chan Event, queueDepth)
+	p.quit = make(chan struct{})
+	p.done = make(chan struct{})
+
+	return p, nil
+}
+
+//Start registers the publisher as a quickfix.SessionStateListener and quickfix.AnomalyListener of every session, and starts POSTing events.
+//Listeners cannot be removed, the events of a stopped publisher are discarded.
+func (p *Publisher) Start() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.started {
+		return
+	}
+	p.started = true
+
+	quickfix.AddSessionStateListener(p)
+	quickfix.AddAnomalyListener(p)
+
+	go p.run()
+}
+
+//Stop stops the publisher once the events queued are POSTed, abandoning them once ctx is done.
+func (p *Publisher) Stop(ctx context.Context) error {
+	p.lock.Lock()
+	if p.stopped || !p.started {
+		p.stopped = true
+		p.lock.Unlock()
+		return nil
+	}
+	p.stopped = true
+	close(p.queue)
+	p.lock.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		close(p.quit)
+		<-p.done
+		return ctx.Err()
+	}
+}
+
+//OnSessionStateChange implements quickfix.SessionStateListener, publishing logons, logouts and disconnects.
+func (p *Publisher) OnSessionStateChange(sessionID quickfix.SessionID, from, to quickfix.SessionState, reason error) {
+	event := Event{SessionID: sessionID.String(), From: from.String(), To: to.String()}
+
+	switch {
+	case to == quickfix.StateLoggedOn && (from == quickfix.StateLogonSent || from == quickfix.StateAwaitingLogon):
+		event.Type = EventLogon
+	case to == quickfix.StateLogoutSent:
+		event.Type = EventLogout
+	case to == quickfix.StateDisconnected:
+		event.Type = EventDisconnect
+	default:
+		return
+	}
+
+	if reason != nil {
+		event.Reason = reason.Error()
+	}
+
+	p.publish(event)
+}
+
+//OnAnomaly implements quickfix.AnomalyListener, publishing the anomaly.
+func (p *Publisher) OnAnomaly(anomaly quickfix.Anomaly) {
+	event := Event{
+		Type:      EventAnomaly,
+		SessionID: anomaly.SessionID.String(),
+		Time:      anomaly.Time,
+		Anomaly:   string(anomaly.Kind),
+		Count:     anomaly.Count,
+	}
+
+	if anomaly.Err != nil {
+		event.Reason = anomaly.Err.Error()
+	}
+
+	p.publish(event)
+}
+
+//publish queues event, dropping it if the queue is full.
+func (p *Publisher) publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = p.now()
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.stopped {
+		return
+	}
+
+	select {
+	case p.queue <- event:
+	default:
+		p.onError(fmt.Errorf("webhook queue full, dropped %v event of %v", event.Type, event.SessionID))
+	}
+}
+
+func (p *Publisher) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+
+	return time.Now()
+}
+
+func (p *Publisher) onError(err error) {
+	if p.OnError != nil {
+		p.OnError(err)
+	}
+}
+
+//run POSTs the events queued until stopped.
+func (p *Publisher) run() {
+	defer close(p.done)
+
+	for event := range p.queue {
+		body, err := json.Marshal(event)
+		if err != nil {
+			p.onError(err)
+			continue
+		}
+
+		for _, url := range p.urls {
+			if err := p.deliver(url, event.Type, body); err != nil {
+				p.onError(err)
+			}
+		}
+
+		select {
+		case <-p.quit:
+			return
+		default:
+		}
+	}
+}
+
+//deliver POSTs body to url, retrying with backoff, until accepted or the retries are exhausted.
+func (p *Publisher) deliver(url, eventType string, body []byte) error {
+	interval := p.retryInterval
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = p.post(url, eventType, body); err == nil {
+			return nil
+		}
+
+		if attempt >= p.maxRetries {
+			return fmt.Errorf("cannot POST %v event to %v after %v attempts: %v", eventType, url, attempt+1, err)
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-p.quit:
+			return fmt.Errorf("cannot POST %v event to %v, stopped: %v", eventType, url, err)
+		}
+		interval *= 2
+	}
+}
+
+//post POSTs body to url once, signed with the secret.
+func (p *Publisher) post(url, eventType string, body []byte) error {
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, eventType)
+	if len(p.secret) > 0 {
+		request.Header.Set(HeaderSignature, Sign(p.secret, body))
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("status %v", response.Status)
+	}
+
+	return nil
+}
+
+//Sign returns the signature of body keyed by secret, as sent in the HeaderSignature header, for receivers to verify with hmac.Equal.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testSessionID = quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+
+//receiver records the events POSTed, failing the first failures requests.
+type receiver struct {
+	lock       sync.Mutex
+	failures   int
+	events     []Event
+	signatures []string
+	bodies     [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(req.Body)
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil || req.Header.Get(HeaderEvent) != event.Type {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.events = append(r.events, event)
+	r.signatures = append(r.signatures, req.Header.Get(HeaderSignature))
+	r.bodies = append(r.bodies, body)
+}
+
+func newTestPublisher(t *testing.T, urls string, secret string) *Publisher {
+	settings := quickfix.NewSettings()
+	settings.GlobalSettings().Set(config.WebhookURLs, urls)
+	if secret != "" {
+		settings.GlobalSettings().Set(config.WebhookSecret, secret)
+	}
+
+	p, err := NewPublisher(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.retryInterval = time.Millisecond
+
+	return p
+}
+
+func TestNewPublisher(t *testing.T) {
+	settings := quickfix.NewSettings()
+	if _, err := NewPublisher(settings); err == nil {
+		t.Error("Expected error without WebhookURLs")
+	}
+
+	settings.GlobalSettings().Set(config.WebhookURLs, "http://a/hook, http://b/hook")
+	settings.GlobalSettings().Set(config.WebhookMaxRetries, "-1")
+	if _, err := NewPublisher(settings); err == nil {
+		t.Error("Expected error for negative WebhookMaxRetries")
+	}
+
+	settings.GlobalSettings().Set(config.WebhookMaxRetries, "5")
+	settings.GlobalSettings().Set(config.WebhookRetryInterval, "2")
+	p, err := NewPublisher(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.urls) != 2 || p.urls[1] != "http://b/hook" || p.maxRetries != 5 || p.retryInterval != 2*time.Second || cap(p.queue) != defaultQueueDepth {
+		t.Errorf("Unexpected publisher %+v", p)
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	r := &receiver{failures: 2}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	p := newTestPublisher(t, server.URL, "s3cret")
+	p.Start()
+
+	p.OnSessionStateChange(testSessionID, quickfix.StateLogonSent, quickfix.StateLoggedOn, nil)
+	p.OnSessionStateChange(testSessionID, quickfix.StateLoggedOn, quickfix.StateAwaitingResend, nil)
+	p.OnAnomaly(quickfix.Anomaly{Kind: quickfix.AnomalySeqNumGap, SessionID: testSessionID, Time: time.Now(), Count: 6})
+	p.OnSessionStateChange(testSessionID, quickfix.StateTestRequestSent, quickfix.StateDisconnected, errors.New("heartbeat timeout"))
+
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.events) != 3 {
+		t.Fatalf("Expected logon, gap and disconnect delivered after retries, got %v", r.events)
+	}
+
+	if r.events[0].Type != EventLogon || r.events[0].SessionID != testSessionID.String() || r.events[0].Time.IsZero() {
+		t.Errorf("Unexpected logon %+v", r.events[0])
+	}
+
+	if r.events[1].Type != EventAnomaly || r.events[1].Anomaly != string(quickfix.AnomalySeqNumGap) || r.events[1].Count != 6 {
+		t.Errorf("Unexpected gap %+v", r.events[1])
+	}
+
+	if r.events[2].Type != EventDisconnect || r.events[2].Reason != "heartbeat timeout" {
+		t.Errorf("Unexpected disconnect %+v", r.events[2])
+	}
+
+	for i, body := range r.bodies {
+		if r.signatures[i] != Sign([]byte("s3cret"), body) {
+			t.Errorf("Expected body %s signed, got %v", body, r.signatures[i])
+		}
+	}
+
+	p.OnSessionStateChange(testSessionID, quickfix.StateLogonSent, quickfix.StateLoggedOn, nil)
+	if len(p.queue) != 0 {
+		t.Error("Expected events of a stopped publisher discarded")
+	}
+}
+
+func TestPublisher_RetriesExhausted(t *testing.T) {
+	r := &receiver{failures: defaultMaxRetries + 1}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	p := newTestPublisher(t, server.URL, "")
+	var errs []error
+	p.OnError = func(err error) { errs = append(errs, err) }
+	p.Start()
+
+	p.OnAnomaly(quickfix.Anomaly{Kind: quickfix.AnomalyLogonRejects, SessionID: testSessionID, Count: 3, Err: errors.New("unknown user")})
+	if err := p.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(errs) != 1 || len(r.events) != 0 || r.failures != 0 {
+		t.Errorf("Expected the event abandoned after %v attempts, got %v", defaultMaxRetries+1, errs)
+	}
+}
+
+func TestPublisher_QueueFull(t *testing.T) {
+	settings := quickfix.NewSettings()
+	settings.GlobalSettings().Set(config.WebhookURLs, "http://127.0.0.1:1/hook")
+	settings.GlobalSettings().Set(config.WebhookQueueDepth, "1")
+	p, err := NewPublisher(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errs []error
+	p.OnError = func(err error) { errs = append(errs, err) }
+
+	//not started, the queue is not drained
+	p.OnSessionStateChange(testSessionID, quickfix.StateLoggedOn, quickfix.StateLogoutSent, nil)
+	p.OnSessionStateChange(testSessionID, quickfix.StateLogoutSent, quickfix.StateDisconnected, nil)
+
+	if len(p.queue) != 1 || len(errs) != 1 {
+		t.Errorf("Expected the second event dropped, got %v", errs)
+	}
+}
@@ -0,0 +1,286 @@
+package quickfix
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"io"
+	"net"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+//wireCaptureMagic starts each capture file.
+const wireCaptureMagic = "QFXCAP01"
+
+//WireDirection is the direction of the bytes of a WireCaptureRecord.
+type WireDirection byte
+
+const (
+	//WireRead records bytes read from the socket.
+	WireRead WireDirection = iota
+
+	//WireWritten records bytes written to the socket.
+	WireWritten
+)
+
+func (d WireDirection) String() string {
+	switch d {
+	case WireRead:
+		return "read"
+	case WireWritten:
+		return "written"
+	}
+
+	return fmt.Sprintf("WireDirection(%d)", byte(d))
+}
+
+//WireCaptureRecord is the bytes of one read from or write to the socket of a session.
+type WireCaptureRecord struct {
+	Time      time.Time
+	Direction WireDirection
+	Bytes     []byte
+}
+
+//wireCapture records the bytes read and written on the connections of a session to a capture file while started.
+//A capture file starts with QFXCAP01, followed by records of the UTC time in nanoseconds as a big endian int64,
+//the direction as a byte, the length as a big endian uint32 and the bytes. See NewWireCaptureReader.
+type wireCapture struct {
+	dir, prefix string
+
+	lock sync.Mutex
+	file *os.File
+}
+
+//newWireCapture returns the capture of a session writing to WireCapturePath, nil if not set. WireCapture=Y starts capturing.
+func newWireCapture(sessionID SessionID, settings *SessionSettings) (*wireCapture, error) {
+	if !settings.HasSetting(config.WireCapturePath) {
+		return nil, nil
+	}
+
+	dir, _ := settings.Setting(config.WireCapturePath)
+	c := &wireCapture{dir: dir, prefix: sessionFilePrefix(sessionID)}
+
+	if settings.HasSetting(config.WireCapture) {
+		start, err := settings.BoolSetting(config.WireCapture)
+		if err != nil {
+			return nil, err
+		}
+
+		if start {
+			if err := c.start(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return c, nil
+}
+
+//start opens a new capture file, unless capturing.
+func (c *wireCapture) start() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.file != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	name := path.Join(c.dir, c.prefix+"."+time.Now().UTC().Format(fileLogRotatedLayout)+".cap")
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.WriteString(wireCaptureMagic); err != nil {
+		file.Close()
+		return err
+	}
+
+	c.file = file
+	return nil
+}
+
+//stop closes the capture file, if capturing.
+func (c *wireCapture) stop() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.file == nil {
+		return nil
+	}
+
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+//record writes p to the capture file, if capturing, in a single write. Capturing stops if the file cannot be written.
+func (c *wireCapture) record(t time.Time, direction WireDirection, p []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.file == nil {
+		return
+	}
+
+	record := getBuffer(13 + len(p))[:13]
+	binary.BigEndian.PutUint64(record, uint64(t.UnixNano()))
+	record[8] = byte(direction)
+	binary.BigEndian.PutUint32(record[9:], uint32(len(p)))
+	record = append(record, p...)
+
+	_, err := c.file.Write(record)
+	putBuffer(record)
+
+	if err != nil {
+		c.file.Close()
+		c.file = nil
+	}
+}
+
+//captureConn is a connection recording the bytes read and written to the capture of its session.
+//The bytes read before the session is known are held until attached.
+type captureConn struct {
+	net.Conn
+
+	lock     sync.Mutex
+	attached bool
+	capture  *wireCapture
+	pending  []WireCaptureRecord
+}
+
+//newCaptureConn returns conn recording to capture, or holding the bytes read until attached if capture is nil.
+func newCaptureConn(conn net.Conn, capture *wireCapture) *captureConn {
+	return &captureConn{Conn: conn, capture: capture, attached: capture != nil}
+}
+
+//attach records the bytes held and those read and written from now on to capture, which may be nil.
+func (c *captureConn) attach(capture *wireCapture) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if capture != nil {
+		for _, record := range c.pending {
+			capture.record(record.Time, record.Direction, record.Bytes)
+		}
+	}
+
+	c.capture, c.attached, c.pending = capture, true, nil
+}
+
+func (c *captureConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.record(WireRead, p[:n])
+	}
+
+	return n, err
+}
+
+func (c *captureConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.record(WireWritten, p[:n])
+	}
+
+	return n, err
+}
+
+func (c *captureConn) record(direction WireDirection, p []byte) {
+	now := time.Now()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.attached {
+		c.pending = append(c.pending, WireCaptureRecord{Time: now, Direction: direction, Bytes: append([]byte(nil), p...)})
+		return
+	}
+
+	if c.capture != nil {
+		c.capture.record(now, direction, p)
+	}
+}
+
+//StartWireCapture starts recording the bytes read and written on the connections of the session with sessionID
+//to a new file under its WireCapturePath, named <BeginString>-<SenderCompID>-<TargetCompID>.<time>.cap. Recording continues
+//across reconnects until StopWireCapture. Captures the bytes as sent and received, decrypted on TLS connections.
+func StartWireCapture(sessionID SessionID) error {
+	capture, err := lookupWireCapture(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return capture.start()
+}
+
+//StopWireCapture stops recording the connections of the session with sessionID, closing its capture file.
+func StopWireCapture(sessionID SessionID) error {
+	capture, err := lookupWireCapture(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return capture.stop()
+}
+
+func lookupWireCapture(sessionID SessionID) (*wireCapture, error) {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.wireCapture == nil {
+		return nil, requiredConfigurationMissing(config.WireCapturePath)
+	}
+
+	return session.wireCapture, nil
+}
+
+//WireCaptureReader reads the records of a capture file.
+type WireCaptureReader struct {
+	reader *bufio.Reader
+}
+
+//NewWireCaptureReader returns a reader of the capture file read from r, an error if r is not a capture file.
+func NewWireCaptureReader(r io.Reader) (*WireCaptureReader, error) {
+	reader := bufio.NewReader(r)
+
+	magic := make([]byte, len(wireCaptureMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != wireCaptureMagic {
+		return nil, fmt.Errorf("not a wire capture")
+	}
+
+	return &WireCaptureReader{reader: reader}, nil
+}
+
+//Next returns the next record, io.EOF at the end of the capture and io.ErrUnexpectedEOF if the last record is incomplete.
+func (r *WireCaptureReader) Next() (WireCaptureRecord, error) {
+	header := make([]byte, 13)
+	if _, err := io.ReadFull(r.reader, header); err != nil {
+		return WireCaptureRecord{}, err
+	}
+
+	record := WireCaptureRecord{
+		Time:      time.Unix(0, int64(binary.BigEndian.Uint64(header))).UTC(),
+		Direction: WireDirection(header[8]),
+		Bytes:     make([]byte, binary.BigEndian.Uint32(header[9:])),
+	}
+
+	if _, err := io.ReadFull(r.reader, record.Bytes); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return WireCaptureRecord{}, err
+	}
+
+	return record, nil
+}
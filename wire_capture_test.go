@@ -0,0 +1,146 @@
+package quickfix
+
+import (
+	"bytes"
+	"github.com/quickfixgo/quickfix/config"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+)
+
+func readWireCapture(t *testing.T, name string) (records []WireCaptureRecord) {
+	file, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reader, err := NewWireCaptureReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestNewWireCapture(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.2", SenderCompID: "TW", TargetCompID: "ISLD"}
+	settings := NewSessionSettings()
+
+	if capture, err := newWireCapture(sessionID, settings); err != nil || capture != nil {
+		t.Errorf("Expected no capture without WireCapturePath, got %v %v", capture, err)
+	}
+
+	dir, err := ioutil.TempDir("", "capture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	settings.Set(config.WireCapturePath, dir)
+	settings.Set(config.WireCapture, "maybe")
+	if _, err := newWireCapture(sessionID, settings); err == nil {
+		t.Error("Expected error for WireCapture=maybe")
+	}
+
+	settings.Set(config.WireCapture, "Y")
+	capture, err := newWireCapture(sessionID, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer capture.stop()
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 || path.Ext(files[0].Name()) != ".cap" {
+		t.Errorf("Expected capture file started, got %v", files)
+	}
+}
+
+func TestCaptureConn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "capture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	capture := &wireCapture{dir: dir, prefix: "FIX.4.2-ISLD-TW"}
+	if err := capture.start(); err != nil {
+		t.Fatal(err)
+	}
+
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	conn := newCaptureConn(local, nil)
+	go func() {
+		remote.Write([]byte("8=FIX.4.2\x01"))
+		io.Copy(ioutil.Discard, remote)
+	}()
+
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "8=FIX.4.2\x01" {
+		t.Fatalf("Unexpected read %q %v", buf[:n], err)
+	}
+
+	conn.attach(capture)
+	if _, err := conn.Write([]byte("35=A\x01")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := capture.stop(); err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("not captured"))
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 capture file, got %v", files)
+	}
+
+	records := readWireCapture(t, path.Join(dir, files[0].Name()))
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %v", records)
+	}
+
+	if records[0].Direction != WireRead || !bytes.Equal(records[0].Bytes, []byte("8=FIX.4.2\x01")) {
+		t.Errorf("Expected bytes read before attach, got %v", records[0])
+	}
+
+	if records[1].Direction != WireWritten || !bytes.Equal(records[1].Bytes, []byte("35=A\x01")) || records[1].Time.Before(records[0].Time) {
+		t.Errorf("Unexpected record %v", records[1])
+	}
+}
+
+func TestWireCaptureReader_Truncated(t *testing.T) {
+	if _, err := NewWireCaptureReader(bytes.NewBufferString("8=FIX.4.2")); err == nil {
+		t.Error("Expected error for a file that is not a capture")
+	}
+
+	reader, err := NewWireCaptureReader(bytes.NewBufferString(wireCaptureMagic + "\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x05abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reader.Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestStartWireCapture_NotConfigured(t *testing.T) {
+	if err := StartWireCapture(SessionID{BeginString: "FIX.4.2", SenderCompID: "NO", TargetCompID: "SESSION"}); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+}
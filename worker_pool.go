@@ -0,0 +1,534 @@
+package quickfix
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"net"
+	"runtime"
+	"sync"
+	"time"
+)
+
+//threadingModel determines how the sessions are run.
+type threadingModel int
+
+const (
+	//threadingModelSession runs each connected session on a goroutine of its own, reading its connection on another.
+	threadingModelSession threadingModel = iota
+
+	//threadingModelPool runs the sessions on a shared pool of WorkerPoolSize goroutines, which also read and write the
+	//connections polled for readiness.
+	threadingModelPool
+)
+
+//pooledTurnTasks is the number of tasks of a session run by a worker before the sessions scheduled after it.
+const pooledTurnTasks = 64
+
+//pooledTickInterval is the interval the timers of the sessions run by a pool are checked at.
+const pooledTickInterval = 100 * time.Millisecond
+
+//errWouldBlock is returned reading or writing a polled connection that is not ready.
+var errWouldBlock = errors.New("connection not ready")
+
+//parseThreadingModel maps the ThreadingModel setting to a threadingModel.
+func parseThreadingModel(setting string) (threadingModel, error) {
+	switch setting {
+	case "Session":
+		return threadingModelSession, nil
+	case "Pool":
+		return threadingModelPool, nil
+	}
+
+	return threadingModelSession, fmt.Errorf("invalid ThreadingModel %v, expected Session or Pool", setting)
+}
+
+//newSessionWorkers returns the pool of WorkerPoolSize workers, the number of CPUs by default, running the session with
+//ThreadingModel=Pool, nil with ThreadingModel=Session, the default.
+func newSessionWorkers(settings *SessionSettings) (*workerPool, error) {
+	if !settings.HasSetting(config.ThreadingModel) {
+		return nil, nil
+	}
+
+	setting, err := settings.Setting(config.ThreadingModel)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := parseThreadingModel(setting)
+	if err != nil || model == threadingModelSession {
+		return nil, err
+	}
+
+	size := runtime.NumCPU()
+	if settings.HasSetting(config.WorkerPoolSize) {
+		if size, err = settings.IntSetting(config.WorkerPoolSize); err != nil {
+			return nil, err
+		}
+
+		if size <= 0 {
+			return nil, fmt.Errorf("%v must be a positive number", config.WorkerPoolSize)
+		}
+	}
+
+	return sharedWorkerPool(size), nil
+}
+
+//workerPools are the worker pools started, shared by the sessions with the same WorkerPoolSize.
+var workerPools struct {
+	lock  sync.Mutex
+	pools map[int]*workerPool
+}
+
+//sharedWorkerPool returns the pool of size workers, started the first time it is needed.
+func sharedWorkerPool(size int) *workerPool {
+	workerPools.lock.Lock()
+	defer workerPools.lock.Unlock()
+
+	if pool, ok := workerPools.pools[size]; ok {
+		return pool
+	}
+
+	if workerPools.pools == nil {
+		workerPools.pools = make(map[int]*workerPool)
+	}
+
+	pool := newWorkerPool(size)
+	workerPools.pools[size] = pool
+	return pool
+}
+
+//workerPool runs the connected sessions of ThreadingModel=Pool on a bounded number of goroutines, rather than goroutines for
+//each session, for processes running thousands of mostly idle sessions. The connections are polled for readiness by a single
+//goroutine for the pool, then read and written by the workers without blocking. TLS connections, and those without a file
+//descriptor, are read and written on goroutines of their own. The timers and schedules of the sessions are checked by one ticker
+//for the pool. Messages are stored by the worker sending them, NewAsyncStoreFactory keeps a slow store off the workers.
+type workerPool struct {
+	lock  sync.Mutex
+	ready *sync.Cond
+
+	//queue holds the runs and writers scheduled, in the order they are run
+	queue []poolTurn
+
+	//runs are the runs attached, checked by the ticker
+	runs map[*pooledRun]struct{}
+
+	//poller polls the connections, nil where not supported
+	poller *poller
+}
+
+//poolTurn is a run or writer scheduled on a worker pool.
+type poolTurn interface {
+	turn()
+}
+
+//newWorkerPool returns a pool running size workers.
+func newWorkerPool(size int) *workerPool {
+	p := &workerPool{runs: make(map[*pooledRun]struct{})}
+	p.ready = sync.NewCond(&p.lock)
+
+	//connections are read and written on goroutines of their own without a poller
+	p.poller, _ = newPoller()
+
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+	go p.checkSessionTimes()
+
+	return p
+}
+
+//work runs the turns of the runs and writers scheduled.
+func (p *workerPool) work() {
+	for {
+		p.lock.Lock()
+		for len(p.queue) == 0 {
+			p.ready.Wait()
+		}
+
+		t := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		p.lock.Unlock()
+
+		t.turn()
+	}
+}
+
+//schedule queues t to be run by a worker.
+func (p *workerPool) schedule(t poolTurn) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.queue = append(p.queue, t)
+	p.ready.Signal()
+}
+
+//checkSessionTimes fires the timers of the sessions of the runs due each pooledTickInterval, resumes the writers stalled past
+//their write timeout, and checks the schedules of the sessions every second.
+func (p *workerPool) checkSessionTimes() {
+	ticker := time.NewTicker(pooledTickInterval)
+	defer ticker.Stop()
+
+	var lastScheduleCheck time.Time
+	for tick := range ticker.C {
+		checkSchedules := tick.Sub(lastScheduleCheck) >= time.Second
+		if checkSchedules {
+			lastScheduleCheck = tick
+		}
+
+		p.lock.Lock()
+		runs := make([]*pooledRun, 0, len(p.runs))
+		for r := range p.runs {
+			runs = append(runs, r)
+		}
+		p.lock.Unlock()
+
+		for _, r := range runs {
+			s := r.session
+			now := s.now()
+
+			for _, timer := range []*eventTimer{&s.stateTimer, &s.peerTimer, &s.logoutTimer} {
+				if timer.expire(now) {
+					timer.Task()
+				}
+			}
+
+			r.writer.checkStalled(tick)
+
+			if checkSchedules && s.sessionSchedule() != nil {
+				r.post(func() { s.onSessionTimeCheck(now) })
+			}
+		}
+	}
+}
+
+//pooledRun is a connection of a session run by a workerPool. The tasks of the run are run in order, by one worker at a time.
+type pooledRun struct {
+	session *Session
+	pool    *workerPool
+
+	//conn is the connection of the run, read with parser and written with writer
+	conn   net.Conn
+	parser *parser
+	writer *connWriter
+
+	//polled is the registration of a connection read by the workers once readable, nil for a connection read on a
+	//goroutine of its own
+	polled *polledConn
+
+	lock      sync.Mutex
+	tasks     []func()
+	scheduled bool
+	stopped   bool
+	ended     bool
+
+	//processed is signaled once each message read on the goroutine of the connection is handled, so that a connection is read
+	//no faster than its session
+	processed chan struct{}
+
+	//done is closed once the run ends
+	done chan struct{}
+}
+
+//pollReader reads the bytes buffered by the reader of the logon, then the connection without blocking.
+type pollReader struct {
+	buffered *bufio.Reader
+	conn     *pollConn
+}
+
+func (r *pollReader) Read(p []byte) (int, error) {
+	if r.buffered != nil && r.buffered.Buffered() > 0 {
+		return r.buffered.Read(p)
+	}
+
+	return r.conn.Read(p)
+}
+
+//attach starts running s on the pool, for its connection conn, polled if polled is not nil.
+func (p *workerPool) attach(s *Session, conn net.Conn, parser *parser, writer *connWriter, polled *polledConn) *pooledRun {
+	r := &pooledRun{session: s, pool: p, conn: conn, parser: parser, writer: writer, polled: polled, processed: make(chan struct{}, 1),
+		done: make(chan struct{})}
+
+	//timers set on a previous connection are not carried over
+	for _, timer := range []*eventTimer{&s.stateTimer, &s.peerTimer, &s.logoutTimer} {
+		timer.clear()
+	}
+
+	p.lock.Lock()
+	p.runs[r] = struct{}{}
+	p.lock.Unlock()
+
+	s.pooledLock.Lock()
+	s.pooled = r
+	s.pooledLock.Unlock()
+
+	r.post(s.startRun)
+	return r
+}
+
+//post queues task to be run, scheduling the run if idle. Tasks posted once the run ended are dropped.
+func (r *pooledRun) post(task func()) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.ended {
+		return
+	}
+
+	r.tasks = append(r.tasks, task)
+	r.scheduleLocked()
+}
+
+//wake schedules the run if idle, to handle the shutdown or stop of its session.
+func (r *pooledRun) wake() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.ended {
+		r.scheduleLocked()
+	}
+}
+
+func (r *pooledRun) scheduleLocked() {
+	if !r.scheduled {
+		r.scheduled = true
+		r.pool.schedule(r)
+	}
+}
+
+//next returns the next task of the run, nil once idle. The shutdown and stop of the session come first.
+func (r *pooledRun) next() func() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s := r.session
+	if !r.stopped {
+		select {
+		case <-s.stop:
+			//stop is closed, only handle once
+			r.stopped = true
+			return s.onStop
+		default:
+		}
+	}
+
+	select {
+	case <-s.shutdown:
+		return s.onShutdown
+	default:
+	}
+
+	if len(r.tasks) == 0 {
+		r.scheduled = false
+		return nil
+	}
+
+	task := r.tasks[0]
+	r.tasks[0] = nil
+	r.tasks = r.tasks[1:]
+	return task
+}
+
+//turn runs the tasks of the run, rescheduling it after pooledTurnTasks so that a busy session does not hold back the others.
+func (r *pooledRun) turn() {
+	for i := 0; i < pooledTurnTasks; i++ {
+		task := r.next()
+		if task == nil {
+			return
+		}
+
+		task()
+
+		if _, latent := r.session.currentState.(latentState); latent {
+			r.end()
+			return
+		}
+	}
+
+	r.pool.schedule(r)
+}
+
+//end ends the run once its session is latent, ending the writer and unblocking the goroutine reading a connection not polled.
+func (r *pooledRun) end() {
+	r.session.endRun()
+
+	r.lock.Lock()
+	r.ended = true
+	r.tasks = nil
+	r.lock.Unlock()
+
+	r.pool.lock.Lock()
+	delete(r.pool.runs, r)
+	r.pool.lock.Unlock()
+
+	r.session.pooledLock.Lock()
+	r.session.pooled = nil
+	r.session.pooledLock.Unlock()
+
+	close(r.done)
+
+	if r.polled != nil {
+		//no read is running, the reads are tasks of the run
+		r.parser.release()
+	} else if err := r.conn.SetReadDeadline(time.Now()); err != nil {
+		r.conn.Close()
+	}
+}
+
+//read reads and handles the messages of a polled connection, up to pooledTurnTasks before reading on in a later task,
+//until a read would block, then arms the connection to be read once readable.
+func (r *pooledRun) read() {
+	for i := 0; i < pooledTurnTasks; i++ {
+		msg, err := r.parser.ReadMessage()
+
+		if err != nil {
+			switch typedErr := err.(type) {
+			//ignore message parser errors
+			case parseError:
+				continue
+			case MessageTooLargeError:
+				r.session.onReceived(fixIn{receiveTime: r.parser.lastRead, tooLarge: &typedErr}, true)
+			default:
+				if err == errWouldBlock {
+					r.polled.arm(pollReadable)
+				} else {
+					r.session.onReceived(fixIn{}, false)
+				}
+				return
+			}
+		} else {
+			r.session.onReceived(fixIn{msg, r.parser.lastRead, nil}, true)
+		}
+
+		if _, latent := r.session.currentState.(latentState); latent {
+			return
+		}
+	}
+
+	r.post(r.read)
+}
+
+//receive posts fixIn read on the goroutine of the connection, ok false once the connection is closed, returning once handled.
+//Returns false once the run ended.
+func (r *pooledRun) receive(in fixIn, ok bool) bool {
+	r.post(func() {
+		r.session.onReceived(in, ok)
+		r.processed <- struct{}{}
+	})
+
+	select {
+	case <-r.processed:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+//readConn reads the messages of a connection not polled, on a goroutine of its own, until the run ends.
+func (r *pooledRun) readConn(first *fixIn) {
+	defer r.session.debug.goroutine()()
+	defer r.parser.release()
+
+	if first != nil && !r.receive(*first, true) {
+		return
+	}
+
+	for {
+		msg, err := r.parser.ReadMessage()
+
+		if err != nil {
+			switch typedErr := err.(type) {
+			//ignore message parser errors
+			case parseError:
+				continue
+			case MessageTooLargeError:
+				if !r.receive(fixIn{receiveTime: r.parser.lastRead, tooLarge: &typedErr}, true) {
+					return
+				}
+				continue
+			default:
+				r.receive(fixIn{}, false)
+				return
+			}
+		}
+
+		if !r.receive(fixIn{msg, r.parser.lastRead, nil}, true) {
+			return
+		}
+	}
+}
+
+//runPooled hands the connection conn to the worker pool of the session, returning at once. A connection the pool polls is read
+//and written by its workers, others on goroutines of their own. reader is the reader of the parser, holding the bytes read past
+//first, if not nil, the message read before the session was known. end is run once the session ends and the messages sent are
+//written, the returned channel closed after.
+func (s *Session) runPooled(parser *parser, reader *bufio.Reader, conn net.Conn, writer *connWriter, first *fixIn, end *connEnd) <-chan struct{} {
+	var r *pooledRun
+	var polled *polledConn
+	var nonblocking *pollConn
+
+	if s.workers.poller != nil {
+		if c, ok := newPollConn(conn); ok {
+			var err error
+			if polled, err = s.workers.poller.add(c.fd, func() { r.post(r.read) }, writer.resume); err == nil {
+				nonblocking = c
+				parser.reader = &pollReader{buffered: reader, conn: c}
+			}
+		}
+	}
+
+	ended, closed := end.handOff(), make(chan struct{})
+	writer.startPooled(s.workers, polled, nonblocking, func() {
+		if polled != nil {
+			polled.remove()
+		}
+
+		ended()
+		close(closed)
+	})
+
+	r = s.workers.attach(s, conn, parser, writer, polled)
+	if polled == nil {
+		go r.readConn(first)
+		return closed
+	}
+
+	if first != nil {
+		in := *first
+		r.post(func() { s.onReceived(in, true) })
+	}
+
+	//the bytes buffered past the logon are read at once
+	r.post(r.read)
+	return closed
+}
+
+//postEvent delivers evt, fired by a timer, to the goroutine or worker running the session. With ThreadingModel=Pool, events fired
+//while the session is not connected are dropped.
+func (s *Session) postEvent(evt event) {
+	if s.workers == nil {
+		s.sessionEvent <- evt
+		return
+	}
+
+	if r := s.pooledRun(); r != nil {
+		r.post(func() { s.onSessionEvent(evt) })
+	}
+}
+
+//wake schedules the session on its worker pool to handle its shutdown or stop, if connected with ThreadingModel=Pool.
+func (s *Session) wake() {
+	if r := s.pooledRun(); r != nil {
+		r.wake()
+	}
+}
+
+//pooledRun returns the run of the session connected with ThreadingModel=Pool, nil otherwise.
+func (s *Session) pooledRun() *pooledRun {
+	s.pooledLock.Lock()
+	defer s.pooledLock.Unlock()
+
+	return s.pooled
+}
@@ -0,0 +1,322 @@
+package quickfix
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/quickfixgo/quickfix/config"
+	"github.com/quickfixgo/quickfix/fix"
+	"github.com/quickfixgo/quickfix/fix/field"
+	"github.com/quickfixgo/quickfix/fix/tag"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseThreadingModel(t *testing.T) {
+	if model, err := parseThreadingModel("Pool"); err != nil || model != threadingModelPool {
+		t.Errorf("Expected Pool, got %v %v", model, err)
+	}
+
+	if _, err := parseThreadingModel("Thread"); err == nil {
+		t.Error("Expected error for Thread")
+	}
+}
+
+func TestNewSessionWorkers(t *testing.T) {
+	settings := NewSessionSettings()
+	if workers, err := newSessionWorkers(settings); err != nil || workers != nil {
+		t.Errorf("Expected a goroutine per session by default, got %v %v", workers, err)
+	}
+
+	settings.Set(config.ThreadingModel, "Pool")
+	settings.Set(config.WorkerPoolSize, "3")
+	workers, err := newSessionWorkers(settings)
+	if err != nil || workers == nil {
+		t.Fatalf("Expected a worker pool, got %v", err)
+	}
+
+	if shared, _ := newSessionWorkers(settings); shared != workers {
+		t.Error("Expected the pool of the same size shared")
+	}
+
+	settings.Set(config.WorkerPoolSize, "0")
+	if _, err := newSessionWorkers(settings); err == nil {
+		t.Error("Expected error for WorkerPoolSize=0")
+	}
+}
+
+//pooledClient records the ClOrdIDs of the messages received.
+type pooledClient struct {
+	shutdownClient
+	clOrdIDs chan string
+}
+
+func (c *pooledClient) FromApp(msg Message, sessionID SessionID) MessageRejectError {
+	clOrdID := new(fix.StringValue)
+	msg.Body.GetField(tag.ClOrdID, clOrdID)
+	c.clOrdIDs <- sessionID.TargetCompID + " " + clOrdID.Value
+	return nil
+}
+
+func TestWorkerPool_Sessions(t *testing.T) {
+	transport := NewPipeTransport()
+	counterparties := []string{"POOL1", "POOL2", "POOL3"}
+
+	acceptorSettings := NewSettings()
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptHost, "fix.internal")
+	acceptorSettings.GlobalSettings().Set(config.SocketAcceptPort, "5001")
+	acceptorSettings.GlobalSettings().Set(config.ThreadingModel, "Pool")
+	acceptorSettings.GlobalSettings().Set(config.WorkerPoolSize, "1")
+
+	initiatorSettings := NewSettings()
+	initiatorSettings.GlobalSettings().Set(config.ThreadingModel, "Pool")
+	initiatorSettings.GlobalSettings().Set(config.WorkerPoolSize, "1")
+
+	var initiatorIDs []SessionID
+	for _, counterparty := range counterparties {
+		if _, err := acceptorSettings.AddSession(newTestAcceptorSessionSettings(counterparty)); err != nil {
+			t.Fatal(err)
+		}
+
+		sessionSettings := NewSessionSettings()
+		sessionSettings.Set(config.BeginString, "FIX.4.2")
+		sessionSettings.Set(config.SenderCompID, counterparty)
+		sessionSettings.Set(config.TargetCompID, "ACCEPTOR")
+		sessionSettings.Set(config.HeartBtInt, "30")
+		sessionSettings.Set(config.SocketConnectHost, "fix.internal")
+		sessionSettings.Set(config.SocketConnectPort, "5001")
+		sessionID, err := initiatorSettings.AddSession(sessionSettings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		initiatorIDs = append(initiatorIDs, sessionID)
+	}
+
+	acceptorApp := &pooledClient{shutdownClient{states: make(chan SessionState, 20)}, make(chan string, 10)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	acceptor.SetListenerFactory(transport.Listen)
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Shutdown(context.Background())
+
+	initiatorApp := &shutdownClient{states: make(chan SessionState, 20)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	initiator.SetDialer(transport.Dial)
+
+	if err := initiator.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	for range counterparties {
+		awaitState(t, initiatorApp.states, StateLoggedOn)
+		awaitState(t, acceptorApp.states, StateLoggedOn)
+	}
+
+	//pipes are not polled, the connection is read and written on goroutines of its own, the session run by the pool
+	session, err := LookupSession(initiatorIDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := session.DebugState(); state.Goroutines != 2 {
+		t.Errorf("Expected 2 goroutines, the reader and writer of the connection, got %v", state.Goroutines)
+	}
+
+	for _, sessionID := range initiatorIDs {
+		order := NewMessageBuilder()
+		order.Header().Set(field.NewMsgType("D"))
+		order.Body().Set(field.NewClOrdID("1"))
+		if err := SendToTarget(order, sessionID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	received := make(map[string]bool)
+	for range counterparties {
+		select {
+		case clOrdID := <-acceptorApp.clOrdIDs:
+			received[clOrdID] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected an order from each session, got %v", received)
+		}
+	}
+
+	for _, counterparty := range counterparties {
+		if !received[counterparty+" 1"] {
+			t.Errorf("Expected an order from %v, got %v", counterparty, received)
+		}
+	}
+
+	//a session removed is woken to log out
+	if err := acceptor.RemoveSession(SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "POOL3"}); err != nil {
+		t.Fatal(err)
+	}
+	awaitState(t, acceptorApp.states, StateDisconnected)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := initiator.Shutdown(ctx); err != nil {
+		t.Errorf("Expected counterparty to reply to Logout: %v", err)
+	}
+
+	for range counterparties[1:] {
+		awaitState(t, acceptorApp.states, StateDisconnected)
+	}
+}
+
+//polledCounterparty is a connection logged on to a pooled acceptor, with HeartBtInt=1.
+type polledCounterparty struct {
+	targetCompID string
+	conn         net.Conn
+	parser       *parser
+	seqNum       int
+}
+
+func newPolledCounterparty(t *testing.T, port, targetCompID string) *polledCounterparty {
+	conn, err := net.Dial("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &polledCounterparty{targetCompID: targetCompID, conn: conn, parser: newParser(bufio.NewReader(conn))}
+	c.send(t, "35=A", "98=0\001108=1\001")
+	if !c.await(t, "35=A") {
+		t.Fatalf("Expected %v logged on", targetCompID)
+	}
+	return c
+}
+
+//send sends a message of msgType, given as the field, with the fields of body.
+func (c *polledCounterparty) send(t *testing.T, msgType, body string) {
+	c.seqNum++
+	header := fmt.Sprintf("%v\00134=%v\00149=%v\00152=%v\00156=ACCEPTOR\001", msgType, c.seqNum, c.targetCompID, time.Now().UTC().Format("20060102-15:04:05.000"))
+	if _, err := c.conn.Write(rawMessage("FIX.4.2", header+body)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//await returns true once a message of msgType, given as the field, is read within 3 seconds. A heartbeat is sent first,
+//so that the acceptor does not time out the counterparty.
+func (c *polledCounterparty) await(t *testing.T, msgType string) bool {
+	c.send(t, "35=0", "")
+
+	c.conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for {
+		msg, err := c.parser.ReadMessage()
+		if err != nil {
+			return false
+		}
+
+		if bytes.Contains(msg, []byte("\001"+msgType+"\001")) {
+			return true
+		}
+	}
+}
+
+//awaitGoroutines returns the number of goroutines once no more than max, or after 2 seconds.
+func awaitGoroutines(max int) int {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		n := runtime.NumGoroutine()
+		if n <= max || time.Now().After(deadline) {
+			return n
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWorkerPool_PolledSessions(t *testing.T) {
+	const sessions = 50
+	port := strconv.Itoa(freePort(t))
+
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptHost, "127.0.0.1")
+	settings.GlobalSettings().Set(config.SocketAcceptPort, port)
+	settings.GlobalSettings().Set(config.ThreadingModel, "Pool")
+	settings.GlobalSettings().Set(config.WorkerPoolSize, "1")
+	for i := 0; i < sessions; i++ {
+		if _, err := settings.AddSession(newTestAcceptorSessionSettings(fmt.Sprintf("POLLED%v", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	acceptor, err := NewAcceptor(&TestClient{}, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acceptor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer acceptor.Stop()
+
+	//timers of earlier tests may fire meanwhile, a goroutine for each session would exceed the slack
+	const slack = sessions / 5
+	bound := runtime.NumGoroutine() + slack
+
+	counterparties := make([]*polledCounterparty, sessions)
+	for i := range counterparties {
+		counterparties[i] = newPolledCounterparty(t, port, fmt.Sprintf("POLLED%v", i))
+		defer counterparties[i].conn.Close()
+	}
+
+	//the connections are polled, read and written by the worker, rather than goroutines of their own
+	if n := awaitGoroutines(bound); n > bound {
+		t.Errorf("Expected no more than %v goroutines for %v sessions, got %v", bound, sessions, n)
+	}
+
+	sessionID := SessionID{BeginString: "FIX.4.2", SenderCompID: "ACCEPTOR", TargetCompID: "POLLED0"}
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := session.DebugState(); state.Goroutines != 0 {
+		t.Errorf("Expected no goroutines of the connection, got %v", state.Goroutines)
+	}
+
+	//the heartbeats are sent by the timers of the pool
+	for i, counterparty := range counterparties {
+		if !counterparty.await(t, "35=0") {
+			t.Fatalf("Expected a heartbeat to POLLED%v", i)
+		}
+	}
+
+	//POLLED0 reads nothing more, the messages sent to it wait for the connection without holding the worker
+	text := strings.Repeat("x", 4096)
+	for i := 0; i < 2000; i++ {
+		news := NewMessageBuilder()
+		news.Header().Set(field.NewMsgType("B"))
+		news.Body().Set(field.NewHeadline("flood"))
+		news.Body().Set(field.NewText(text))
+		if err := SendToTarget(news, sessionID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if state := session.DebugState(); state.WriteQueueDepth == 0 {
+		t.Error("Expected messages queued for the connection not read")
+	}
+
+	for i, counterparty := range counterparties[1:] {
+		if !counterparty.await(t, "35=0") {
+			t.Fatalf("Expected a heartbeat to POLLED%v while POLLED0 is not read", i+1)
+		}
+	}
+
+	if n := awaitGoroutines(bound); n > bound {
+		t.Errorf("Expected no more than %v goroutines for %v sessions, got %v", bound, sessions, n)
+	}
+}